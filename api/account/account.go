@@ -8,6 +8,7 @@ import (
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
 	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
 	sv "github.com/ChokeGuy/simple-bank/server/http"
 	"github.com/ChokeGuy/simple-bank/util"
@@ -15,6 +16,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// maxAccountNumberAttempts bounds how often a colliding account number is regenerated
+const maxAccountNumberAttempts = 3
+
 type AccountHandler struct {
 	*sv.Server
 }
@@ -25,22 +29,28 @@ func NewAccountHandler(server *sv.Server) *AccountHandler {
 
 func RandomAccount(owner string) db.Account {
 	return db.Account{
-		ID:       util.RandomInt(1, 1000),
-		Owner:    owner,
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		ID:            util.RandomInt(1, 1000),
+		Owner:         owner,
+		Balance:       util.RandomMoney(),
+		Currency:      util.RandomCurrency(),
+		AccountNumber: util.RandomAccountNumber(),
 	}
 }
 
 func (h *AccountHandler) MapRoutes() {
 	router := h.Router
 
-	authRoutes := router.Group("/").Use(auth.AuthMiddleWare(h.TokenMaker))
-
-	authRoutes.POST("/account", h.createAccount)
-	authRoutes.GET("/account/:id", h.getAccount)
-	authRoutes.GET("/accounts", h.listAccounts)
-	authRoutes.DELETE("/account/:id", h.deleteAccount)
+	authRoutes := router.Group("/").Use(auth.AuthMiddleWare(h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier))
+
+	authRoutes.POST(
+		"/account",
+		auth.RequirePermission(rbac.AccountCreate),
+		auth.RequireVerifiedEmail(h.Store, h.Config.RequireVerifiedEmail),
+		h.createAccount,
+	)
+	authRoutes.GET("/account/:accountNumber", auth.RequirePermission(rbac.AccountRead), h.getAccount)
+	authRoutes.GET("/accounts", auth.RequirePermission(rbac.AccountRead), h.listAccounts)
+	authRoutes.DELETE("/account/:accountNumber", auth.RequirePermission(rbac.AccountDelete), h.deleteAccount)
 }
 
 func (h *AccountHandler) createAccount(ctx *gin.Context) {
@@ -59,7 +69,7 @@ func (h *AccountHandler) createAccount(ctx *gin.Context) {
 		Currency: req.Currency,
 	}
 
-	account, err := h.Store.CreateAccount(ctx, arg)
+	account, err := h.createAccountWithNumber(ctx, arg)
 
 	if err != nil {
 		errCode := db.ErrorCode(err)
@@ -72,7 +82,27 @@ func (h *AccountHandler) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(account, "Account created successfully"))
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewAccountResponse(account), "Account created successfully"))
+}
+
+// createAccountWithNumber assigns a fresh account number, regenerating it on the rare collision
+func (h *AccountHandler) createAccountWithNumber(ctx *gin.Context, arg db.CreateAccountParams) (db.Account, error) {
+	var account db.Account
+	var err error
+
+	for attempt := 0; attempt < maxAccountNumberAttempts; attempt++ {
+		arg.AccountNumber, err = util.GenerateAccountNumber()
+		if err != nil {
+			return db.Account{}, err
+		}
+
+		account, err = h.Store.CreateAccount(ctx, arg)
+		if db.ConstraintName(err) != db.AccountNumberConstraint {
+			break
+		}
+	}
+
+	return account, err
 }
 
 func (h *AccountHandler) getAccount(ctx *gin.Context) {
@@ -83,7 +113,14 @@ func (h *AccountHandler) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := h.Store.GetAccount(ctx, req.ID)
+	accountNumber := util.NormalizeAccountNumber(req.AccountNumber)
+
+	if err := util.ValidateAccountNumber(accountNumber); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	account, err := h.Store.GetAccountByNumber(ctx, accountNumber)
 
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
@@ -96,12 +133,12 @@ func (h *AccountHandler) getAccount(ctx *gin.Context) {
 	}
 	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
 
-	if account.Owner != authPayload.UserName {
+	if !rbac.CanAccess(authPayload, account.Owner, rbac.AccountReadAny) {
 		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Account does not belong to the authenticated user"))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(account, "Account retrieved successfully"))
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewAccountResponse(account), "Account retrieved successfully"))
 }
 
 func (h *AccountHandler) listAccounts(ctx *gin.Context) {
@@ -127,10 +164,7 @@ func (h *AccountHandler) listAccounts(ctx *gin.Context) {
 		return
 	}
 
-	response := dto.ListAccountResponse{
-		Accounts: accounts,
-		Length:   len(accounts),
-	}
+	response := dto.NewListAccountResponse(accounts)
 
 	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Accounts retrieved successfully"))
 }
@@ -143,23 +177,33 @@ func (h *AccountHandler) deleteAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := h.Store.GetAccount(ctx, req.ID)
+	accountNumber := util.NormalizeAccountNumber(req.AccountNumber)
+
+	if err := util.ValidateAccountNumber(accountNumber); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	account, err := h.Store.GetAccountByNumber(ctx, accountNumber)
 
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
 			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Account not found"))
 			return
 		}
+
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
 	}
 
 	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
 
-	if account.Owner != authPayload.UserName {
+	if !rbac.CanAccess(authPayload, account.Owner) {
 		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Account does not belong to the authenticated user"))
 		return
 	}
 
-	err = h.Store.DeleteAccount(ctx, req.ID)
+	err = h.Store.DeleteAccount(ctx, account.ID)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
@@ -19,6 +19,7 @@ import (
 	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
 	server "github.com/ChokeGuy/simple-bank/server/http"
+	"github.com/ChokeGuy/simple-bank/util"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
@@ -30,20 +31,20 @@ func TestGetAccountApi(t *testing.T) {
 
 	testCases := []struct {
 		name          string
-		accountID     int64
+		accountNumber string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name:      "OK",
-			accountID: account.ID,
+			name:          "OK",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
 					Times(1).
 					Return(account, nil)
 			},
@@ -53,14 +54,14 @@ func TestGetAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "UnAuthorizedUser",
-			accountID: account.ID,
+			name:          "UnAuthorizedUser",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "unauthorized_user", user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
 					Times(1).
 					Return(account, nil)
 			},
@@ -69,13 +70,45 @@ func TestGetAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "NoAuthorization",
-			accountID: account.ID,
+			name:          "BankerReadsAnyAccount",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "banker", util.BankerRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Any()).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:          "UnknownRole",
+			accountNumber: account.AccountNumber,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, "unknown", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:          "NoAuthorization",
+			accountNumber: account.AccountNumber,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -83,14 +116,14 @@ func TestGetAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "NotFound",
-			accountID: account.ID,
+			name:          "NotFound",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, 15*time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 			},
@@ -99,14 +132,14 @@ func TestGetAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "InternalError",
-			accountID: account.ID,
+			name:          "InternalError",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, 15*time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
@@ -115,14 +148,14 @@ func TestGetAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "BadRequest",
-			accountID: 0,
+			name:          "BadRequest",
+			accountNumber: "123",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, 15*time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Any()).
+					GetAccountByNumber(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -153,7 +186,7 @@ func TestGetAccountApi(t *testing.T) {
 
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/account/%d", tc.accountID)
+			url := fmt.Sprintf("/account/%s", tc.accountNumber)
 
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
@@ -193,7 +226,7 @@ func TestCreateccountApi(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccount(gomock.Any(), EqCreateAccountParams(arg)).
 					Times(1).
 					Return(account, nil)
 			},
@@ -218,7 +251,7 @@ func TestCreateccountApi(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccount(gomock.Any(), EqCreateAccountParams(arg)).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
@@ -226,6 +259,28 @@ func TestCreateccountApi(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "EmailNotVerified",
+			body: req.CreateAccountRequest{
+				Currency: account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "BadRequest",
 			body: req.CreateAccountRequest{},
@@ -254,6 +309,12 @@ func TestCreateccountApi(t *testing.T) {
 			//build stubs
 			tc.buildStubs(store)
 
+			// Owners are verified unless the case stubs the lookup itself
+			store.EXPECT().
+				GetUserByUserName(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return(db.GetUserByUserNameRow{IsEmailVerified: true}, nil)
+
 			//start new server
 			cfg, err := pkg.LoadConfig("../../")
 			require.NoError(t, err)
@@ -408,19 +469,19 @@ func TestDeleteAccountApi(t *testing.T) {
 
 	testCases := []struct {
 		name          string
-		accountID     int64
+		accountNumber string
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name:      "OK",
-			accountID: account.ID,
+			name:          "OK",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
 					Times(1).
 					Return(account, nil)
 
@@ -433,14 +494,34 @@ func TestDeleteAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "UnAuthorizedUser",
-			accountID: account.ID,
+			name:          "UnAuthorizedUser",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "unauthorized_user", user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					DeleteAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:          "BankerCannotDeleteOthersAccount",
+			accountNumber: account.AccountNumber,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
 					Times(1).
 					Return(account, nil)
 
@@ -453,13 +534,13 @@ func TestDeleteAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "NoAuthorization",
-			accountID: account.ID,
+			name:          "NoAuthorization",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -467,13 +548,13 @@ func TestDeleteAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "NotFound",
-			accountID: account.ID,
+			name:          "NotFound",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 			},
@@ -482,13 +563,13 @@ func TestDeleteAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "InternalError",
-			accountID: account.ID,
+			name:          "InternalError",
+			accountNumber: account.AccountNumber,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).
 					Times(1).
 					Return(account, nil)
 
@@ -502,8 +583,8 @@ func TestDeleteAccountApi(t *testing.T) {
 			},
 		},
 		{
-			name:      "BadRequest",
-			accountID: 0,
+			name:          "BadRequest",
+			accountNumber: "123",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
 			},
@@ -538,7 +619,7 @@ func TestDeleteAccountApi(t *testing.T) {
 			accountHandler.MapRoutes()
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/account/%d", tc.accountID)
+			url := fmt.Sprintf("/account/%s", tc.accountNumber)
 
 			request, err := http.NewRequest(http.MethodDelete, url, nil)
 			require.NoError(t, err)
@@ -556,15 +637,15 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	require.NoError(t, err)
 
 	var response struct {
-		Data       db.Account `json:"data"`
-		Message    string     `json:"message"`
-		StatusCode int        `json:"statusCode"`
+		Data       req.AccountResponse `json:"data"`
+		Message    string              `json:"message"`
+		StatusCode int                 `json:"statusCode"`
 	}
 
 	err = json.Unmarshal(data, &response)
 	require.NoError(t, err)
 
-	require.Equal(t, account, response.Data)
+	require.Equal(t, req.NewAccountResponse(account), response.Data)
 }
 
 // requireBodyMatchAccounts checks if the response body matches accounts
@@ -0,0 +1,37 @@
+package account
+
+import (
+	"fmt"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang/mock/gomock"
+)
+
+// Custom matcher for CreateAccountParams, the account number is generated by the handler
+type eqCreateAccountParamsMatcher struct {
+	arg db.CreateAccountParams
+}
+
+func (e eqCreateAccountParamsMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.CreateAccountParams)
+	if !ok {
+		return false
+	}
+
+	if err := util.ValidateAccountNumber(actualArg.AccountNumber); err != nil {
+		return false
+	}
+
+	return actualArg.Owner == e.arg.Owner &&
+		actualArg.Balance == e.arg.Balance &&
+		actualArg.Currency == e.arg.Currency
+}
+
+func (e eqCreateAccountParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v with a valid account number", e.arg)
+}
+
+func EqCreateAccountParams(arg db.CreateAccountParams) gomock.Matcher {
+	return eqCreateAccountParamsMatcher{arg}
+}
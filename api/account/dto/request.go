@@ -5,7 +5,7 @@ type CreateAccountRequest struct {
 }
 
 type GetAccountRequest struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
+	AccountNumber string `uri:"accountNumber" binding:"required"`
 }
 
 type ListAccountRequest struct {
@@ -15,5 +15,5 @@ type ListAccountRequest struct {
 }
 
 type DeleteAccountRequest struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
+	AccountNumber string `uri:"accountNumber" binding:"required"`
 }
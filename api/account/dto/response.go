@@ -1,8 +1,43 @@
 package account
 
-import db "github.com/ChokeGuy/simple-bank/db/sqlc"
+import (
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/util"
+)
+
+// AccountResponse is the client facing view of an account, it never exposes the internal ID
+type AccountResponse struct {
+	AccountNumber string     `json:"accountNumber"`
+	Owner         string     `json:"owner"`
+	Balance       util.Money `json:"balance"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
 
 type ListAccountResponse struct {
-	Accounts []db.Account `json:"accounts"`
-	Length   int          `json:"length"`
+	Accounts []AccountResponse `json:"accounts"`
+	Length   int               `json:"length"`
+}
+
+func NewAccountResponse(account db.Account) AccountResponse {
+	return AccountResponse{
+		AccountNumber: account.AccountNumber,
+		Owner:         account.Owner,
+		Balance:       util.Money{Amount: account.Balance, Currency: account.Currency},
+		CreatedAt:     account.CreatedAt,
+	}
+}
+
+func NewListAccountResponse(accounts []db.Account) ListAccountResponse {
+	response := ListAccountResponse{
+		Accounts: make([]AccountResponse, 0, len(accounts)),
+		Length:   len(accounts),
+	}
+
+	for _, account := range accounts {
+		response.Accounts = append(response.Accounts, NewAccountResponse(account))
+	}
+
+	return response
 }
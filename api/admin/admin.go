@@ -0,0 +1,704 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	accountDto "github.com/ChokeGuy/simple-bank/api/account/dto"
+	dto "github.com/ChokeGuy/simple-bank/api/admin/dto"
+	userDto "github.com/ChokeGuy/simple-bank/api/user/dto"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
+	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	sv "github.com/ChokeGuy/simple-bank/server/http"
+	"github.com/ChokeGuy/simple-bank/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AdminHandler serves the back-office API of bankers, every call is written to the audit log
+type AdminHandler struct {
+	*sv.Server
+}
+
+func NewAdminHandler(server *sv.Server) *AdminHandler {
+	return &AdminHandler{Server: server}
+}
+
+func (h *AdminHandler) MapRoutes() {
+	router := h.Router
+
+	adminRoutes := router.Group("/admin").Use(auth.AuthMiddleWare(h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier))
+
+	adminRoutes.GET("/users", auth.RequirePermission(rbac.UserReadAny), h.searchUsers)
+	adminRoutes.GET("/users/:username/accounts", auth.RequirePermission(rbac.AccountReadAny), h.listUserAccounts)
+	adminRoutes.GET("/users/:username/transfers", auth.RequirePermission(rbac.TransferReadAny), h.listUserTransfers)
+	adminRoutes.PATCH("/users/:username/role", auth.RequirePermission(rbac.UserRoleUpdate), auth.RequireStepUp(h.StepUpPolicy), h.updateUserRole)
+	adminRoutes.POST("/users/:username/verify-email", auth.RequirePermission(rbac.UserUpdateAny), h.forceVerifyEmail)
+	adminRoutes.GET("/users/:username/kyc", auth.RequirePermission(rbac.KycReview), h.getUserKyc)
+	adminRoutes.PATCH("/users/:username/kyc", auth.RequirePermission(rbac.KycReview), h.updateUserKyc)
+	adminRoutes.GET("/users/:username/kyc/documents", auth.RequirePermission(rbac.KycReview), h.listUserKycDocuments)
+	adminRoutes.GET("/kyc-documents/:id", auth.RequirePermission(rbac.KycReview), h.downloadKycDocument)
+	adminRoutes.PATCH("/kyc-documents/:id", auth.RequirePermission(rbac.KycReview), h.reviewKycDocument)
+	adminRoutes.GET("/users/:username/sessions", auth.RequirePermission(rbac.SessionBlock), h.listUserSessions)
+	adminRoutes.POST("/sessions/:id/block", auth.RequirePermission(rbac.SessionBlock), h.blockSession)
+	adminRoutes.DELETE("/sessions/:id/block", auth.RequirePermission(rbac.SessionBlock), h.unblockSession)
+	adminRoutes.GET("/audit-logs", auth.RequirePermission(rbac.AuditLogRead), h.listAuditLogs)
+
+	adminRoutes.POST("/service-accounts", auth.RequirePermission(rbac.ServiceAccountManage), h.createServiceAccount)
+	adminRoutes.GET("/service-accounts", auth.RequirePermission(rbac.ServiceAccountManage), h.listServiceAccounts)
+	adminRoutes.POST("/service-accounts/:name/keys", auth.RequirePermission(rbac.ServiceAccountManage), auth.RequireStepUp(h.StepUpPolicy), h.createApiKey)
+	adminRoutes.GET("/service-accounts/:name/keys", auth.RequirePermission(rbac.ServiceAccountManage), h.listApiKeys)
+	adminRoutes.DELETE("/service-accounts/:name/keys/:id", auth.RequirePermission(rbac.ServiceAccountManage), h.revokeApiKey)
+}
+
+// auditEntry builds the audit log of an action of the authenticated banker
+func auditEntry(ctx *gin.Context, action, target string, details map[string]any) db.CreateAuditLogParams {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	return audit.Entry(authPayload.UserName, action, target, ctx.ClientIP(), details)
+}
+
+// recordRead writes the audit log of a read, the data is not returned when it cannot be recorded
+func (h *AdminHandler) recordRead(ctx *gin.Context, action, target string, details map[string]any) bool {
+	if _, err := h.Store.CreateAuditLog(ctx, auditEntry(ctx, action, target, details)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return false
+	}
+
+	return true
+}
+
+// requireUser responds with 404 when the user does not exist
+func (h *AdminHandler) requireUser(ctx *gin.Context, username string) bool {
+	if _, err := h.Store.GetUserByUserName(ctx, username); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return false
+	}
+
+	return true
+}
+
+func (h *AdminHandler) searchUsers(ctx *gin.Context) {
+	var req dto.SearchUsersRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	users, err := h.Store.SearchUsers(ctx, db.SearchUsersParams{
+		Query:  req.Query,
+		Limit:  req.Size,
+		Offset: (req.Page - 1) * req.Size,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !h.recordRead(ctx, audit.SearchUsers, "", map[string]any{"query": req.Query, "page": req.Page}) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewSearchUsersResponse(users), "Users retrieved successfully"))
+}
+
+func (h *AdminHandler) listUserAccounts(ctx *gin.Context) {
+	var req dto.UserRequest
+	var page dto.PageRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if !h.requireUser(ctx, req.UserName) {
+		return
+	}
+
+	accounts, err := h.Store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  req.UserName,
+		Limit:  page.Size,
+		Offset: (page.Page - 1) * page.Size,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !h.recordRead(ctx, audit.ListUserAccounts, req.UserName, map[string]any{"page": page.Page}) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(accountDto.NewListAccountResponse(accounts), "Accounts retrieved successfully"))
+}
+
+func (h *AdminHandler) listUserTransfers(ctx *gin.Context) {
+	var req dto.UserRequest
+	var page dto.PageRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if !h.requireUser(ctx, req.UserName) {
+		return
+	}
+
+	transfers, err := h.Store.ListTransfersByOwner(ctx, db.ListTransfersByOwnerParams{
+		Owner:  req.UserName,
+		Limit:  page.Size,
+		Offset: (page.Page - 1) * page.Size,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !h.recordRead(ctx, audit.ListUserTransfers, req.UserName, map[string]any{"page": page.Page}) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewListUserTransfersResponse(transfers), "Transfers retrieved successfully"))
+}
+
+func (h *AdminHandler) updateUserRole(ctx *gin.Context) {
+	var req dto.UserRequest
+	var body dto.UpdateUserRoleRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	// Another banker has to do it so nobody can escalate or lock themselves out
+	if authPayload.UserName == req.UserName {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Bankers cannot change their own role"))
+		return
+	}
+
+	var user db.User
+	var revoked []uuid.UUID
+
+	_, err := h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, audit.UpdateUserRole, req.UserName, map[string]any{"role": body.Role}),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			user, err = q.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+				Username: req.UserName,
+				Role:     body.Role,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			// The role is part of the tokens, the user has to log in again to get the new one
+			revoked, err = q.RevokeSessionsByUserName(ctx, req.UserName)
+			return err
+		},
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	h.SessionChecker.Invalidate(revoked...)
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewAdminUserResponse(user), "User role updated successfully"))
+}
+
+func (h *AdminHandler) forceVerifyEmail(ctx *gin.Context) {
+	var req dto.UserRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	var user db.User
+
+	_, err := h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, audit.ForceVerifyEmail, req.UserName, nil),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			user, err = q.UpdateUser(ctx, db.UpdateUserParams{
+				Username:        req.UserName,
+				IsEmailVerified: pgtype.Bool{Bool: false, Valid: true},
+			})
+
+			if err != nil {
+				return err
+			}
+
+			if err := q.InvalidateVerifyEmails(ctx, req.UserName); err != nil {
+				return err
+			}
+
+			taskPayload := &worker.PayloadSendVerifyEmail{
+				UserName: req.UserName,
+			}
+
+			opts := worker.OutboxOptions{
+				MaxRetry: 10,
+				Queue:    worker.QueueCritical,
+			}
+
+			return worker.EnqueueTaskSendVerifyEmail(ctx, q, taskPayload, opts)
+		},
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewAdminUserResponse(user), "A verification email was sent to the user"))
+}
+
+func (h *AdminHandler) getUserKyc(ctx *gin.Context) {
+	var req dto.UserRequest
+	var page dto.PageRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, req.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	reviews, err := h.Store.ListKycReviews(ctx, db.ListKycReviewsParams{
+		Username: req.UserName,
+		Limit:    page.Size,
+		Offset:   (page.Page - 1) * page.Size,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !h.recordRead(ctx, audit.GetUserKyc, req.UserName, map[string]any{"page": page.Page}) {
+		return
+	}
+
+	response := dto.NewUserKycResponse(userDto.NewUserRowResponse(user), reviews)
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "User KYC retrieved successfully"))
+}
+
+func (h *AdminHandler) updateUserKyc(ctx *gin.Context) {
+	var req dto.UserRequest
+	var body dto.UpdateUserKycRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	if authPayload.UserName == req.UserName {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Bankers cannot review their own KYC"))
+		return
+	}
+
+	current, err := h.Store.GetUserByUserName(ctx, req.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !kyc.CanReview(current.KycStatus, body.Status) {
+		message := fmt.Sprintf("KYC status cannot change from %s to %s", current.KycStatus, body.Status)
+		ctx.JSON(http.StatusConflict, res.ErrorResponse(http.StatusConflict, message))
+		return
+	}
+
+	var user db.User
+	var review db.KycReview
+
+	details := map[string]any{"from": current.KycStatus, "to": body.Status, "note": body.Note}
+
+	_, err = h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, audit.UpdateUserKyc, req.UserName, details),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			user, err = q.UpdateUserKycStatus(ctx, db.UpdateUserKycStatusParams{
+				Username:   req.UserName,
+				FromStatus: current.KycStatus,
+				ToStatus:   body.Status,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			review, err = q.CreateKycReview(ctx, db.CreateKycReviewParams{
+				Username:   req.UserName,
+				FromStatus: current.KycStatus,
+				ToStatus:   body.Status,
+				Reviewer:   authPayload.UserName,
+				Note:       body.Note,
+			})
+
+			return err
+		},
+	})
+
+	if err != nil {
+		// The user submitted or changed their profile, or another banker reviewed it in the meantime
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusConflict, res.ErrorResponse(http.StatusConflict, "KYC status changed, please review it again"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := dto.NewUserKycResponse(userDto.NewUserResponse(user), []db.KycReview{review})
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "User KYC updated successfully"))
+}
+
+func (h *AdminHandler) listUserKycDocuments(ctx *gin.Context) {
+	var req dto.UserRequest
+	var page dto.PageRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if !h.requireUser(ctx, req.UserName) {
+		return
+	}
+
+	documents, err := h.Store.ListKycDocuments(ctx, db.ListKycDocumentsParams{
+		Username: req.UserName,
+		Limit:    page.Size,
+		Offset:   (page.Page - 1) * page.Size,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !h.recordRead(ctx, audit.ListKycDocuments, req.UserName, map[string]any{"page": page.Page}) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(userDto.NewListKycDocumentsResponse(documents), "Documents retrieved successfully"))
+}
+
+// downloadKycDocument responds with the decrypted content of a document
+func (h *AdminHandler) downloadKycDocument(ctx *gin.Context) {
+	var req dto.KycDocumentRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	document, err := h.Store.GetKycDocument(ctx, req.ID)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Document not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	content, err := h.KycDocuments.Content(ctx, document)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !h.recordRead(ctx, audit.GetKycDocument, document.Username, map[string]any{"document": document.ID}) {
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", document.FileName))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(http.StatusOK, document.ContentType, content)
+}
+
+func (h *AdminHandler) reviewKycDocument(ctx *gin.Context) {
+	var req dto.KycDocumentRequest
+	var body dto.ReviewKycDocumentRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	document, err := h.Store.GetKycDocument(ctx, req.ID)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Document not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	if authPayload.UserName == document.Username {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Bankers cannot review their own documents"))
+		return
+	}
+
+	if err := kyc.CanReviewDocument(document); err != nil {
+		ctx.JSON(http.StatusConflict, res.ErrorResponse(http.StatusConflict, err.Error()))
+		return
+	}
+
+	details := map[string]any{"document": document.ID, "status": body.Status, "note": body.Note}
+
+	_, err = h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, audit.ReviewKycDocument, document.Username, details),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			document, err = q.ReviewKycDocument(ctx, db.ReviewKycDocumentParams{
+				ID:         document.ID,
+				FromStatus: kyc.DocumentPending,
+				ToStatus:   body.Status,
+				Reviewer:   authPayload.UserName,
+				Note:       body.Note,
+			})
+
+			return err
+		},
+	})
+
+	if err != nil {
+		// Another banker reviewed the document in the meantime
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusConflict, res.ErrorResponse(http.StatusConflict, "Document was already reviewed"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(userDto.NewKycDocumentResponse(document), "Document reviewed successfully"))
+}
+
+// listUserSessions lists the active sessions of a user, bankers need their IDs to block them
+func (h *AdminHandler) listUserSessions(ctx *gin.Context) {
+	var req dto.UserRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if !h.requireUser(ctx, req.UserName) {
+		return
+	}
+
+	sessions, err := h.Store.ListSessionsByUserName(ctx, req.UserName)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !h.recordRead(ctx, audit.ListUserSessions, req.UserName, nil) {
+		return
+	}
+
+	response := userDto.ListSessionsResponse{
+		Sessions: make([]userDto.SessionResponse, 0, len(sessions)),
+	}
+
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, userDto.NewSessionResponse(session))
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Sessions retrieved successfully"))
+}
+
+func (h *AdminHandler) blockSession(ctx *gin.Context) {
+	var req dto.SessionRequest
+	var body dto.BlockSessionRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	// The reason is optional so an empty body is accepted
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&body); err != nil {
+			ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+			return
+		}
+	}
+
+	h.setSessionBlocked(ctx, uuid.MustParse(req.ID), true, auditEntry(ctx, audit.BlockSession, req.ID, map[string]any{"reason": body.Reason}))
+}
+
+func (h *AdminHandler) unblockSession(ctx *gin.Context) {
+	var req dto.SessionRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	h.setSessionBlocked(ctx, uuid.MustParse(req.ID), false, auditEntry(ctx, audit.UnblockSession, req.ID, nil))
+}
+
+func (h *AdminHandler) setSessionBlocked(ctx *gin.Context, sessionID uuid.UUID, blocked bool, entry db.CreateAuditLogParams) {
+	var session db.Session
+
+	_, err := h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: entry,
+		Execute: func(q db.Querier) error {
+			var err error
+
+			session, err = q.SetSessionBlocked(ctx, db.SetSessionBlockedParams{
+				ID:        sessionID,
+				IsBlocked: blocked,
+			})
+
+			return err
+		},
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Session not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	// Drop the cached state so the change applies to the next request of the session
+	h.SessionChecker.Invalidate(session.ID)
+
+	response := dto.SessionBlockResponse{
+		ID:        session.ID,
+		UserName:  session.Username,
+		IsBlocked: session.IsBlocked,
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Session updated successfully"))
+}
+
+func (h *AdminHandler) listAuditLogs(ctx *gin.Context) {
+	var req dto.ListAuditLogsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	logs, err := h.Store.ListAuditLogs(ctx, db.ListAuditLogsParams{
+		Actor:  pgtype.Text{String: req.Actor, Valid: req.Actor != ""},
+		Target: pgtype.Text{String: req.Target, Valid: req.Target != ""},
+		Limit:  req.Size,
+		Offset: (req.Page - 1) * req.Size,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	details := map[string]any{"actor": req.Actor, "target": req.Target, "page": req.Page}
+	if !h.recordRead(ctx, audit.ListAuditLogs, "", details) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewListAuditLogsResponse(logs), "Audit logs retrieved successfully"))
+}
@@ -0,0 +1,1320 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	req "github.com/ChokeGuy/simple-bank/api/admin/dto"
+	"github.com/ChokeGuy/simple-bank/api/user"
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
+	"github.com/ChokeGuy/simple-bank/pkg/blob"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	server "github.com/ChokeGuy/simple-bank/server/http"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+const bankerName = "banker"
+
+// runAuditTx runs the action of the audit transaction against the mock store
+func runAuditTx(t *testing.T, store *mockdb.MockStore, action, target string) func(context.Context, db.AuditTxParams, ...db.TxOption) (db.AuditTxResult, error) {
+	return func(_ context.Context, arg db.AuditTxParams, _ ...db.TxOption) (db.AuditTxResult, error) {
+		require.Equal(t, bankerName, arg.Actor)
+		require.Equal(t, action, arg.Action)
+		require.Equal(t, target, arg.Target)
+
+		if err := arg.Execute(store); err != nil {
+			return db.AuditTxResult{}, err
+		}
+
+		return db.AuditTxResult{AuditLog: db.AuditLog{Actor: arg.Actor, Action: arg.Action, Target: arg.Target}}, nil
+	}
+}
+
+// serveAdminRequest sends a request to a test server with the admin routes
+func serveAdminRequest(t *testing.T, store *mockdb.MockStore, method, url string, body gin.H, setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)) *httptest.ResponseRecorder {
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	server := server.NewTestServer(t, store, &cfg, nil)
+
+	adminHandler := NewAdminHandler(server)
+	adminHandler.MapRoutes()
+
+	recorder := httptest.NewRecorder()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+
+	setupAuth(t, request, server.TokenMaker)
+	server.Router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func asBanker(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+	auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, bankerName, util.BankerRole, time.Minute)
+}
+
+// asStaleBanker is a banker who logged in an hour ago and did not step up since
+func asStaleBanker(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+	auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, bankerName, util.BankerRole, time.Minute,
+		token.WithAuth(time.Now().Add(-time.Hour), token.AuthStrengthPassword))
+}
+
+func asDepositor(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+	auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "depositor", util.DepositorRole, time.Minute)
+}
+
+// TestSearchUsersApi tests the SearchUsers API handler
+func TestSearchUsersApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+
+	rows := []db.SearchUsersRow{{
+		Username:        user.Username,
+		Role:            user.Role,
+		FullName:        user.FullName,
+		Email:           user.Email,
+		IsEmailVerified: user.IsEmailVerified,
+		CreatedAt:       user.CreatedAt,
+	}}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			query:     "?query=" + user.Username + "&page=2&size=5",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Eq(db.SearchUsersParams{Query: user.Username, Limit: 5, Offset: 5})).
+					Times(1).
+					Return(rows, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, bankerName, arg.Actor)
+						require.Equal(t, audit.SearchUsers, arg.Action)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUsers(t, recorder.Body, rows)
+			},
+		},
+		{
+			name:      "NotBanker",
+			setupAuth: asDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidSize",
+			query:     "?size=100",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "AuditLogFailed",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(rows, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditLog{}, fmt.Errorf("connection refused"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			recorder := serveAdminRequest(t, store, http.MethodGet, "/admin/users"+tc.query, nil, tc.setupAuth)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestListUserAccountsApi tests the ListUserAccounts API handler
+func TestListUserAccountsApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+
+	testCases := []struct {
+		name          string
+		userName      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			userName: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(db.ListAccountsParams{Owner: user.Username, Limit: 10, Offset: 0})).
+					Times(1).
+					Return([]db.Account{}, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditLog{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			userName: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/users/%s/accounts", tc.userName)
+			recorder := serveAdminRequest(t, store, http.MethodGet, url, nil, asBanker)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestListUserSessionsApi tests the ListUserSessions API handler
+func TestListUserSessionsApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+	session := db.Session{
+		ID:         uuid.New(),
+		Username:   user.Username,
+		ExpiresAt:  time.Now().Add(time.Hour),
+		LastSeenAt: time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+				store.EXPECT().
+					ListSessionsByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return([]db.Session{session}, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, bankerName, arg.Actor)
+						require.Equal(t, audit.ListUserSessions, arg.Action)
+						require.Equal(t, user.Username, arg.Target)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), session.ID.String())
+			},
+		},
+		{
+			name:      "UserNotFound",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					ListSessionsByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NotBanker",
+			setupAuth: asDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSessionsByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/users/%s/sessions", user.Username)
+			recorder := serveAdminRequest(t, store, http.MethodGet, url, nil, tc.setupAuth)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestUpdateUserRoleApi tests the UpdateUserRole API handler
+func TestUpdateUserRoleApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+	revoked := []uuid.UUID{uuid.New()}
+
+	testCases := []struct {
+		name          string
+		userName      string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			userName:  user.Username,
+			body:      gin.H{"role": util.BankerRole},
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.UpdateUserRole, user.Username))
+				store.EXPECT().
+					UpdateUserRole(gomock.Any(), gomock.Eq(db.UpdateUserRoleParams{Username: user.Username, Role: util.BankerRole})).
+					Times(1).
+					Return(db.User{Username: user.Username, Role: util.BankerRole}, nil)
+				// Sessions carry the old role in their tokens
+				store.EXPECT().
+					RevokeSessionsByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(revoked, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "OwnRole",
+			userName:  bankerName,
+			body:      gin.H{"role": util.DepositorRole},
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "StepUpRequired",
+			userName:  user.Username,
+			body:      gin.H{"role": util.BankerRole},
+			setupAuth: asStaleBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "UnknownRole",
+			userName:  user.Username,
+			body:      gin.H{"role": "admin"},
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "UserNotFound",
+			userName:  user.Username,
+			body:      gin.H{"role": util.BankerRole},
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.UpdateUserRole, user.Username))
+				store.EXPECT().
+					UpdateUserRole(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					RevokeSessionsByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NotBanker",
+			userName:  user.Username,
+			body:      gin.H{"role": util.BankerRole},
+			setupAuth: asDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/users/%s/role", tc.userName)
+			recorder := serveAdminRequest(t, store, http.MethodPatch, url, tc.body, tc.setupAuth)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestForceVerifyEmailApi tests the ForceVerifyEmail API handler
+func TestForceVerifyEmailApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.ForceVerifyEmail, user.Username))
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserParams) (db.User, error) {
+						require.True(t, arg.IsEmailVerified.Valid)
+						require.False(t, arg.IsEmailVerified.Bool)
+						return user, nil
+					})
+				store.EXPECT().
+					InvalidateVerifyEmails(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OutboxMessage{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.ForceVerifyEmail, user.Username))
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/users/%s/verify-email", user.Username)
+			recorder := serveAdminRequest(t, store, http.MethodPost, url, nil, asBanker)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestBlockSessionApi tests the BlockSession and UnblockSession API handlers
+func TestBlockSessionApi(t *testing.T) {
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		method        string
+		sessionID     string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Block",
+			method:    http.MethodPost,
+			sessionID: sessionID.String(),
+			body:      gin.H{"reason": "suspicious activity"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.BlockSession, sessionID.String()))
+				store.EXPECT().
+					SetSessionBlocked(gomock.Any(), gomock.Eq(db.SetSessionBlockedParams{ID: sessionID, IsBlocked: true})).
+					Times(1).
+					Return(db.Session{ID: sessionID, IsBlocked: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "BlockWithoutReason",
+			method:    http.MethodPost,
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.BlockSession, sessionID.String()))
+				store.EXPECT().
+					SetSessionBlocked(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{ID: sessionID, IsBlocked: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Unblock",
+			method:    http.MethodDelete,
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.UnblockSession, sessionID.String()))
+				store.EXPECT().
+					SetSessionBlocked(gomock.Any(), gomock.Eq(db.SetSessionBlockedParams{ID: sessionID, IsBlocked: false})).
+					Times(1).
+					Return(db.Session{ID: sessionID}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "SessionNotFound",
+			method:    http.MethodPost,
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.BlockSession, sessionID.String()))
+				store.EXPECT().
+					SetSessionBlocked(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidSessionID",
+			method:    http.MethodPost,
+			sessionID: "not-a-uuid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/sessions/%s/block", tc.sessionID)
+			recorder := serveAdminRequest(t, store, tc.method, url, tc.body, asBanker)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestListAuditLogsApi tests the ListAuditLogs API handler
+func TestListAuditLogsApi(t *testing.T) {
+	logs := []db.AuditLog{{
+		ID:       1,
+		Actor:    bankerName,
+		Action:   audit.UpdateUserRole,
+		Target:   util.RandomOwner(),
+		Metadata: []byte(`{"role":"banker"}`),
+	}}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?actor=" + bankerName,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ListAuditLogsParams) ([]db.AuditLog, error) {
+						require.Equal(t, bankerName, arg.Actor.String)
+						require.True(t, arg.Actor.Valid)
+						require.False(t, arg.Target.Valid)
+						return logs, nil
+					})
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditLog{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, fmt.Errorf("connection refused"))
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			recorder := serveAdminRequest(t, store, http.MethodGet, "/admin/audit-logs"+tc.query, nil, asBanker)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// requireBodyMatchUsers checks if the response body matches the users found
+func requireBodyMatchUsers(t *testing.T, body *bytes.Buffer, rows []db.SearchUsersRow) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var response struct {
+		Data       req.SearchUsersResponse `json:"data"`
+		Message    string                  `json:"message"`
+		StatusCode int                     `json:"statusCode"`
+	}
+
+	err = json.Unmarshal(data, &response)
+	require.NoError(t, err)
+	require.Len(t, response.Data.Users, len(rows))
+
+	for i, row := range rows {
+		require.Equal(t, row.Username, response.Data.Users[i].UserName)
+		require.Equal(t, row.Role, response.Data.Users[i].Role)
+	}
+}
+
+// TestGetUserKycApi tests the GetUserKyc API handler
+func TestGetUserKycApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+	row := db.GetUserByUserNameRow{Username: user.Username, FullName: user.FullName, Email: user.Email, KycStatus: kyc.Pending}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					ListKycReviews(gomock.Any(), gomock.Eq(db.ListKycReviewsParams{Username: user.Username, Limit: 10, Offset: 0})).
+					Times(1).
+					Return([]db.KycReview{{ID: 1, Username: user.Username, FromStatus: kyc.Unverified, ToStatus: kyc.Pending, Reviewer: user.Username}}, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, audit.GetUserKyc, arg.Action)
+						require.Equal(t, user.Username, arg.Target)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "NotBanker",
+			setupAuth: asDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "UserNotFound",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					ListKycReviews(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/users/%s/kyc?page=1&size=10", user.Username)
+			recorder := serveAdminRequest(t, store, http.MethodGet, url, nil, tc.setupAuth)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestUpdateUserKycApi tests the UpdateUserKyc API handler
+func TestUpdateUserKycApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+
+	testCases := []struct {
+		name          string
+		userName      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			userName: user.Username,
+			body:     gin.H{"status": kyc.Rejected, "note": "document expired"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, KycStatus: kyc.Pending}, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.UpdateUserKyc, user.Username))
+				store.EXPECT().
+					UpdateUserKycStatus(gomock.Any(), gomock.Eq(db.UpdateUserKycStatusParams{
+						Username:   user.Username,
+						FromStatus: kyc.Pending,
+						ToStatus:   kyc.Rejected,
+					})).
+					Times(1).
+					Return(db.User{Username: user.Username, KycStatus: kyc.Rejected}, nil)
+				store.EXPECT().
+					CreateKycReview(gomock.Any(), gomock.Eq(db.CreateKycReviewParams{
+						Username:   user.Username,
+						FromStatus: kyc.Pending,
+						ToStatus:   kyc.Rejected,
+						Reviewer:   bankerName,
+						Note:       "document expired",
+					})).
+					Times(1).
+					Return(db.KycReview{ID: 1, Username: user.Username, ToStatus: kyc.Rejected}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OwnKyc",
+			userName: bankerName,
+			body:     gin.H{"status": kyc.Verified, "note": "looks fine"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "MissingNote",
+			userName: user.Username,
+			body:     gin.H{"status": kyc.Verified},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidTransition",
+			userName: user.Username,
+			body:     gin.H{"status": kyc.Verified, "note": "documents match"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, KycStatus: kyc.Unverified}, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "StatusChanged",
+			userName: user.Username,
+			body:     gin.H{"status": kyc.Verified, "note": "documents match"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, KycStatus: kyc.Pending}, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.UpdateUserKyc, user.Username))
+				store.EXPECT().
+					UpdateUserKycStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateKycReview(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/users/%s/kyc", tc.userName)
+			recorder := serveAdminRequest(t, store, http.MethodPatch, url, tc.body, asBanker)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestListUserKycDocumentsApi tests the ListUserKycDocuments API handler
+func TestListUserKycDocumentsApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+				store.EXPECT().
+					ListKycDocuments(gomock.Any(), gomock.Eq(db.ListKycDocumentsParams{Username: user.Username, Limit: 10, Offset: 0})).
+					Times(1).
+					Return([]db.KycDocument{{ID: 1, Username: user.Username, Kind: kyc.DocumentPassport, Status: kyc.DocumentPending}}, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, audit.ListKycDocuments, arg.Action)
+						require.Equal(t, user.Username, arg.Target)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "NotBanker",
+			setupAuth: asDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListKycDocuments(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "UserNotFound",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					ListKycDocuments(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/users/%s/kyc/documents?page=1&size=10", user.Username)
+			recorder := serveAdminRequest(t, store, http.MethodGet, url, nil, tc.setupAuth)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestDownloadKycDocumentApi tests the DownloadKycDocument API handler
+func TestDownloadKycDocumentApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+	content := []byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+	checksum := sha256.Sum256(content)
+	document := db.KycDocument{
+		ID:          7,
+		Username:    user.Username,
+		Kind:        kyc.DocumentProofOfAddress,
+		FileName:    "bill.pdf",
+		ContentType: "application/pdf",
+		Size:        int64(len(content)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+		BlobKey:     "kyc/" + user.Username + "/bill",
+		Status:      kyc.DocumentPending,
+	}
+
+	testCases := []struct {
+		name          string
+		blob          []byte
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			blob: content,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(document, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, audit.GetKycDocument, arg.Action)
+						require.Equal(t, user.Username, arg.Target)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+				require.Equal(t, content, recorder.Body.Bytes())
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(db.KycDocument{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Corrupted",
+			blob: []byte("%PDF-1.7\ntampered"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(document, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+
+			blobs := blob.NewMemoryStore()
+			if tc.blob != nil {
+				require.NoError(t, blobs.Put(context.Background(), document.BlobKey, tc.blob))
+			}
+			server.KycDocuments = kyc.NewDocumentsWithStore(blobs, cfg.KycDocumentMaxSize)
+
+			adminHandler := NewAdminHandler(server)
+			adminHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/admin/kyc-documents/%d", document.ID), nil)
+			require.NoError(t, err)
+
+			asBanker(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestReviewKycDocumentApi tests the ReviewKycDocument API handler
+func TestReviewKycDocumentApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+	document := db.KycDocument{
+		ID:       3,
+		Username: user.Username,
+		Kind:     kyc.DocumentPassport,
+		ReviewID: pgtype.Int8{Int64: 1, Valid: true},
+		Status:   kyc.DocumentPending,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"status": kyc.DocumentRejected, "note": "photo page is cut off"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(document, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.ReviewKycDocument, user.Username))
+				store.EXPECT().
+					ReviewKycDocument(gomock.Any(), gomock.Eq(db.ReviewKycDocumentParams{
+						ID:         document.ID,
+						FromStatus: kyc.DocumentPending,
+						ToStatus:   kyc.DocumentRejected,
+						Reviewer:   bankerName,
+						Note:       "photo page is cut off",
+					})).
+					Times(1).
+					Return(db.KycDocument{ID: document.ID, Username: user.Username, Status: kyc.DocumentRejected}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MissingNote",
+			body: gin.H{"status": kyc.DocumentRejected},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotSubmitted",
+			body: gin.H{"status": kyc.DocumentApproved},
+			buildStubs: func(store *mockdb.MockStore) {
+				unattached := document
+				unattached.ReviewID = pgtype.Int8{}
+
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(unattached, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "OwnDocument",
+			body: gin.H{"status": kyc.DocumentApproved},
+			buildStubs: func(store *mockdb.MockStore) {
+				own := document
+				own.Username = bankerName
+
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(own, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyReviewed",
+			body: gin.H{"status": kyc.DocumentApproved},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(document, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.ReviewKycDocument, user.Username))
+				store.EXPECT().
+					ReviewKycDocument(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.KycDocument{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/kyc-documents/%d", document.ID)
+			recorder := serveAdminRequest(t, store, http.MethodPatch, url, tc.body, asBanker)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package admin
+
+import "time"
+
+type SearchUsersRequest struct {
+	Query string `form:"query" binding:"max=100"`
+	Page  int32  `form:"page,default=1" binding:"min=1"`
+	Size  int32  `form:"size,default=10" binding:"min=5,max=50"`
+}
+
+type UserRequest struct {
+	UserName string `uri:"username" binding:"required,alphanum"`
+}
+
+type PageRequest struct {
+	Page int32 `form:"page,default=1" binding:"min=1"`
+	Size int32 `form:"size,default=10" binding:"min=5,max=50"`
+}
+
+type SessionRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type BlockSessionRequest struct {
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,role"`
+}
+
+type UpdateUserKycRequest struct {
+	Status string `json:"status" binding:"required,kyc_status"`
+	Note   string `json:"note" binding:"required,max=500"`
+}
+
+type KycDocumentRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// ReviewKycDocumentRequest approves or rejects a document, rejections have to tell the user why
+type ReviewKycDocumentRequest struct {
+	Status string `json:"status" binding:"required,kyc_document_decision"`
+	Note   string `json:"note" binding:"required_if=Status rejected,max=500"`
+}
+
+type ListAuditLogsRequest struct {
+	Actor  string `form:"actor" binding:"max=100"`
+	Target string `form:"target" binding:"max=64"`
+	Page   int32  `form:"page,default=1" binding:"min=1"`
+	Size   int32  `form:"size,default=10" binding:"min=5,max=50"`
+}
+
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name" binding:"required,alphanum,min=3,max=64"`
+	Role        string `json:"role" binding:"required,role"`
+	Description string `json:"description" binding:"max=200"`
+}
+
+type ServiceAccountRequest struct {
+	Name string `uri:"name" binding:"required,alphanum"`
+}
+
+type CreateApiKeyRequest struct {
+	Scopes     []string   `json:"scopes" binding:"required,min=1,dive,required"`
+	AllowedIps []string   `json:"allowedIps" binding:"max=20"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+}
+
+type ApiKeyRequest struct {
+	Name string `uri:"name" binding:"required,alphanum"`
+	ID   string `uri:"id" binding:"required,uuid"`
+}
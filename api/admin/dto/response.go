@@ -0,0 +1,228 @@
+package admin
+
+import (
+	"encoding/json"
+	"time"
+
+	transferDto "github.com/ChokeGuy/simple-bank/api/transfer/dto"
+	userDto "github.com/ChokeGuy/simple-bank/api/user/dto"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/google/uuid"
+)
+
+type AdminUserResponse struct {
+	UserName        string    `json:"userName"`
+	Role            string    `json:"role"`
+	FullName        string    `json:"fullName"`
+	Email           string    `json:"email"`
+	IsEmailVerified bool      `json:"isEmailVerified"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+type SearchUsersResponse struct {
+	Users []AdminUserResponse `json:"users"`
+}
+
+func NewSearchUsersResponse(users []db.SearchUsersRow) SearchUsersResponse {
+	response := SearchUsersResponse{
+		Users: make([]AdminUserResponse, 0, len(users)),
+	}
+
+	for _, user := range users {
+		response.Users = append(response.Users, AdminUserResponse{
+			UserName:        user.Username,
+			Role:            user.Role,
+			FullName:        user.FullName,
+			Email:           user.Email,
+			IsEmailVerified: user.IsEmailVerified,
+			CreatedAt:       user.CreatedAt,
+		})
+	}
+
+	return response
+}
+
+func NewAdminUserResponse(user db.User) AdminUserResponse {
+	return AdminUserResponse{
+		UserName:        user.Username,
+		Role:            user.Role,
+		FullName:        user.FullName,
+		Email:           user.Email,
+		IsEmailVerified: user.IsEmailVerified,
+		CreatedAt:       user.CreatedAt,
+	}
+}
+
+// UserKycResponse is the profile of a user with its KYC review history, newest first
+type UserKycResponse struct {
+	User    userDto.UserResponse        `json:"user"`
+	Reviews []userDto.KycReviewResponse `json:"reviews"`
+}
+
+func NewUserKycResponse(user userDto.UserResponse, reviews []db.KycReview) UserKycResponse {
+	response := UserKycResponse{
+		User:    user,
+		Reviews: make([]userDto.KycReviewResponse, 0, len(reviews)),
+	}
+
+	for _, review := range reviews {
+		response.Reviews = append(response.Reviews, userDto.NewKycReviewResponse(review))
+	}
+
+	return response
+}
+
+type ListUserTransfersResponse struct {
+	Transfers []transferDto.TransferResponse `json:"transfers"`
+}
+
+// NewListUserTransfersResponse converts the transfers of a user, each one keeps the currency of its accounts
+func NewListUserTransfersResponse(rows []db.ListTransfersByOwnerRow) ListUserTransfersResponse {
+	response := ListUserTransfersResponse{
+		Transfers: make([]transferDto.TransferResponse, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		response.Transfers = append(response.Transfers, transferDto.TransferResponse{
+			ID:                row.PublicID,
+			FromAccountNumber: row.FromAccountNumber,
+			ToAccountNumber:   row.ToAccountNumber,
+			Amount:            util.Money{Amount: row.Amount, Currency: row.Currency},
+			CreatedAt:         row.CreatedAt,
+		})
+	}
+
+	return response
+}
+
+type SessionBlockResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserName  string    `json:"userName"`
+	IsBlocked bool      `json:"isBlocked"`
+}
+
+type AuditLogResponse struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	Metadata  json.RawMessage `json:"metadata"`
+	ClientIp  string          `json:"clientIp"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type ListAuditLogsResponse struct {
+	AuditLogs []AuditLogResponse `json:"auditLogs"`
+}
+
+func NewListAuditLogsResponse(logs []db.AuditLog) ListAuditLogsResponse {
+	response := ListAuditLogsResponse{
+		AuditLogs: make([]AuditLogResponse, 0, len(logs)),
+	}
+
+	for _, log := range logs {
+		response.AuditLogs = append(response.AuditLogs, AuditLogResponse{
+			ID:        log.ID,
+			Actor:     log.Actor,
+			Action:    log.Action,
+			Target:    log.Target,
+			Metadata:  json.RawMessage(log.Metadata),
+			ClientIp:  log.ClientIp,
+			CreatedAt: log.CreatedAt,
+		})
+	}
+
+	return response
+}
+
+type ServiceAccountResponse struct {
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	Description string    `json:"description"`
+	CreatedBy   string    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type ListServiceAccountsResponse struct {
+	ServiceAccounts []ServiceAccountResponse `json:"serviceAccounts"`
+}
+
+func NewServiceAccountResponse(account db.ServiceAccount) ServiceAccountResponse {
+	return ServiceAccountResponse{
+		Name:        account.Name,
+		Role:        account.Role,
+		Description: account.Description,
+		CreatedBy:   account.CreatedBy,
+		CreatedAt:   account.CreatedAt,
+	}
+}
+
+func NewListServiceAccountsResponse(accounts []db.ServiceAccount) ListServiceAccountsResponse {
+	response := ListServiceAccountsResponse{
+		ServiceAccounts: make([]ServiceAccountResponse, 0, len(accounts)),
+	}
+
+	for _, account := range accounts {
+		response.ServiceAccounts = append(response.ServiceAccounts, NewServiceAccountResponse(account))
+	}
+
+	return response
+}
+
+// ApiKeyResponse never includes the key, only its prefix so it can be told apart from the others
+type ApiKeyResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	ServiceAccount string     `json:"serviceAccount"`
+	KeyPrefix      string     `json:"keyPrefix"`
+	Scopes         []string   `json:"scopes"`
+	AllowedIps     []string   `json:"allowedIps"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	CreatedBy      string     `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// CreateApiKeyResponse is the only response carrying the key
+type CreateApiKeyResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
+
+type ListApiKeysResponse struct {
+	ApiKeys []ApiKeyResponse `json:"apiKeys"`
+}
+
+func NewApiKeyResponse(key db.ApiKey) ApiKeyResponse {
+	response := ApiKeyResponse{
+		ID:             key.ID,
+		ServiceAccount: key.ServiceAccount,
+		KeyPrefix:      key.KeyPrefix,
+		Scopes:         key.Scopes,
+		AllowedIps:     key.AllowedIps,
+		CreatedBy:      key.CreatedBy,
+		CreatedAt:      key.CreatedAt,
+	}
+
+	if key.ExpiresAt.Valid {
+		response.ExpiresAt = &key.ExpiresAt.Time
+	}
+
+	if key.RevokedAt.Valid {
+		response.RevokedAt = &key.RevokedAt.Time
+	}
+
+	return response
+}
+
+func NewListApiKeysResponse(keys []db.ApiKey) ListApiKeysResponse {
+	response := ListApiKeysResponse{
+		ApiKeys: make([]ApiKeyResponse, 0, len(keys)),
+	}
+
+	for _, key := range keys {
+		response.ApiKeys = append(response.ApiKeys, NewApiKeyResponse(key))
+	}
+
+	return response
+}
@@ -0,0 +1,270 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	dto "github.com/ChokeGuy/simple-bank/api/admin/dto"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/apikey"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
+	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (h *AdminHandler) createServiceAccount(ctx *gin.Context) {
+	var req dto.CreateServiceAccountRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	var account db.ServiceAccount
+
+	_, err := h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, audit.CreateServiceAccount, req.Name, map[string]any{"role": req.Role}),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			account, err = q.CreateServiceAccount(ctx, db.CreateServiceAccountParams{
+				Name:        req.Name,
+				Role:        req.Role,
+				Description: req.Description,
+				CreatedBy:   authPayload.UserName,
+			})
+
+			return err
+		},
+	})
+
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			ctx.JSON(http.StatusForbidden, res.ErrorResponse(http.StatusForbidden, "Service account already exists"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewServiceAccountResponse(account), "Service account created successfully"))
+}
+
+func (h *AdminHandler) listServiceAccounts(ctx *gin.Context) {
+	var page dto.PageRequest
+
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	accounts, err := h.Store.ListServiceAccounts(ctx, db.ListServiceAccountsParams{
+		Limit:  page.Size,
+		Offset: (page.Page - 1) * page.Size,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !h.recordRead(ctx, audit.ListServiceAccounts, "", map[string]any{"page": page.Page}) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewListServiceAccountsResponse(accounts), "Service accounts retrieved successfully"))
+}
+
+// requireServiceAccount responds with 404 when the service account does not exist
+func (h *AdminHandler) requireServiceAccount(ctx *gin.Context, name string) (db.ServiceAccount, bool) {
+	account, err := h.Store.GetServiceAccount(ctx, name)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Service account not found"))
+			return db.ServiceAccount{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return db.ServiceAccount{}, false
+	}
+
+	return account, true
+}
+
+// validateScopes checks that every scope is a permission the role of the service account is granted
+func validateScopes(role string, scopes []string) error {
+	for _, scope := range scopes {
+		if !rbac.Can(role, rbac.Permission(scope)) {
+			return fmt.Errorf("scope %q is not granted to role %q", scope, role)
+		}
+	}
+
+	return nil
+}
+
+func (h *AdminHandler) createApiKey(ctx *gin.Context) {
+	var req dto.ServiceAccountRequest
+	var body dto.CreateApiKeyRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if err := apikey.ValidateAllowedIPs(body.AllowedIps); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	expiresAt := pgtype.Timestamptz{}
+
+	if body.ExpiresAt != nil {
+		if !body.ExpiresAt.After(time.Now()) {
+			ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Expiry must be in the future"))
+			return
+		}
+		expiresAt = pgtype.Timestamptz{Time: *body.ExpiresAt, Valid: true}
+	}
+
+	account, ok := h.requireServiceAccount(ctx, req.Name)
+	if !ok {
+		return
+	}
+
+	scopes := slices.Clone(body.Scopes)
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	if err := validateScopes(account.Role, scopes); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	key, keyPrefix, err := apikey.Generate()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	keyID := uuid.New()
+
+	if body.AllowedIps == nil {
+		body.AllowedIps = []string{}
+	}
+
+	details := map[string]any{"keyId": keyID, "scopes": scopes, "allowedIps": body.AllowedIps, "expiresAt": body.ExpiresAt}
+
+	var apiKey db.ApiKey
+
+	_, err = h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, audit.CreateApiKey, req.Name, details),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			apiKey, err = q.CreateApiKey(ctx, db.CreateApiKeyParams{
+				ID:             keyID,
+				ServiceAccount: req.Name,
+				KeyPrefix:      keyPrefix,
+				KeyHash:        token.HashToken(key),
+				Scopes:         scopes,
+				AllowedIps:     body.AllowedIps,
+				ExpiresAt:      expiresAt,
+				CreatedBy:      authPayload.UserName,
+			})
+
+			return err
+		},
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := dto.CreateApiKeyResponse{
+		ApiKeyResponse: dto.NewApiKeyResponse(apiKey),
+		Key:            key,
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "API key created, store it now as it will not be shown again"))
+}
+
+func (h *AdminHandler) listApiKeys(ctx *gin.Context) {
+	var req dto.ServiceAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if _, ok := h.requireServiceAccount(ctx, req.Name); !ok {
+		return
+	}
+
+	keys, err := h.Store.ListApiKeys(ctx, req.Name)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if !h.recordRead(ctx, audit.ListApiKeys, req.Name, nil) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewListApiKeysResponse(keys), "API keys retrieved successfully"))
+}
+
+func (h *AdminHandler) revokeApiKey(ctx *gin.Context) {
+	var req dto.ApiKeyRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	var apiKey db.ApiKey
+
+	_, err := h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, audit.RevokeApiKey, req.Name, map[string]any{"keyId": req.ID}),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			apiKey, err = q.RevokeApiKey(ctx, db.RevokeApiKeyParams{
+				ID:             uuid.MustParse(req.ID),
+				ServiceAccount: req.Name,
+			})
+
+			return err
+		},
+	})
+
+	if err != nil {
+		// Keys of another service account and keys already revoked are not found either
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "API key not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewApiKeyResponse(apiKey), "API key revoked successfully"))
+}
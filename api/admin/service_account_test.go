@@ -0,0 +1,365 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	req "github.com/ChokeGuy/simple-bank/api/admin/dto"
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/apikey"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateServiceAccountApi tests the CreateServiceAccount API handler
+func TestCreateServiceAccountApi(t *testing.T) {
+	account := db.ServiceAccount{
+		Name:      "batch",
+		Role:      util.BankerRole,
+		CreatedBy: bankerName,
+		CreatedAt: time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": account.Name, "role": account.Role},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.CreateServiceAccount, account.Name))
+				store.EXPECT().
+					CreateServiceAccount(gomock.Any(), gomock.Eq(db.CreateServiceAccountParams{
+						Name:      account.Name,
+						Role:      account.Role,
+						CreatedBy: bankerName,
+					})).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyExists",
+			body: gin.H{"name": account.Name, "role": account.Role},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditTxResult{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRole",
+			body: gin.H{"name": account.Name, "role": "robot"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			recorder := serveAdminRequest(t, store, http.MethodPost, "/admin/service-accounts", tc.body, asBanker)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestCreateApiKeyApi tests the CreateApiKey API handler
+func TestCreateApiKeyApi(t *testing.T) {
+	account := db.ServiceAccount{Name: "batch", Role: util.BankerRole}
+	scopes := []string{string(rbac.TransferReadAny), string(rbac.AccountReadAny)}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"scopes": scopes, "allowedIps": []string{"10.0.0.0/8"}, "expiresAt": time.Now().Add(time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetServiceAccount(gomock.Any(), gomock.Eq(account.Name)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.CreateApiKey, account.Name))
+				store.EXPECT().
+					CreateApiKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateApiKeyParams) (db.ApiKey, error) {
+						// Scopes are stored sorted and the key itself never reaches the store
+						require.Equal(t, []string{string(rbac.AccountReadAny), string(rbac.TransferReadAny)}, arg.Scopes)
+						require.True(t, strings.HasPrefix(arg.KeyPrefix, apikey.KeyPrefix))
+						require.NotContains(t, arg.KeyHash, apikey.KeyPrefix)
+						require.True(t, arg.ExpiresAt.Valid)
+						require.Equal(t, bankerName, arg.CreatedBy)
+						return db.ApiKey{
+							ID:             arg.ID,
+							ServiceAccount: arg.ServiceAccount,
+							KeyPrefix:      arg.KeyPrefix,
+							KeyHash:        arg.KeyHash,
+							Scopes:         arg.Scopes,
+							AllowedIps:     arg.AllowedIps,
+							ExpiresAt:      arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchCreatedKey(t, recorder)
+			},
+		},
+		{
+			name: "ScopeNotGranted",
+			body: gin.H{"scopes": []string{string(rbac.UserRoleUpdate)}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetServiceAccount(gomock.Any(), gomock.Eq(account.Name)).
+					Times(1).
+					Return(db.ServiceAccount{Name: account.Name, Role: util.DepositorRole}, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownScope",
+			body: gin.H{"scopes": []string{"everything"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetServiceAccount(gomock.Any(), gomock.Eq(account.Name)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoScopes",
+			body: gin.H{"scopes": []string{}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetServiceAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAllowedIp",
+			body: gin.H{"scopes": scopes, "allowedIps": []string{"intranet"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetServiceAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiryInThePast",
+			body: gin.H{"scopes": scopes, "expiresAt": time.Now().Add(-time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetServiceAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ServiceAccountNotFound",
+			body: gin.H{"scopes": scopes},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetServiceAccount(gomock.Any(), gomock.Eq(account.Name)).
+					Times(1).
+					Return(db.ServiceAccount{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/service-accounts/%s/keys", account.Name)
+			recorder := serveAdminRequest(t, store, http.MethodPost, url, tc.body, asBanker)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestCreateApiKeyApiStepUp tests that a key is only created within the step-up max age of the banker
+func TestCreateApiKeyApiStepUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetServiceAccount(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	recorder := serveAdminRequest(t, store, http.MethodPost, "/admin/service-accounts/batch/keys",
+		gin.H{"scopes": []string{string(rbac.TransferReadAny)}}, asStaleBanker)
+
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Contains(t, recorder.Header().Get("WWW-Authenticate"), "insufficient_user_authentication")
+}
+
+// TestRevokeApiKeyApi tests the RevokeApiKey API handler
+func TestRevokeApiKeyApi(t *testing.T) {
+	keyID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.RevokeApiKey, "batch"))
+				store.EXPECT().
+					RevokeApiKey(gomock.Any(), gomock.Eq(db.RevokeApiKeyParams{ID: keyID, ServiceAccount: "batch"})).
+					Times(1).
+					Return(db.ApiKey{ID: keyID, ServiceAccount: "batch"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			setupAuth: asBanker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(runAuditTx(t, store, audit.RevokeApiKey, "batch"))
+				store.EXPECT().
+					RevokeApiKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "NotBanker",
+			setupAuth: asDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			url := fmt.Sprintf("/admin/service-accounts/batch/keys/%s", keyID)
+			recorder := serveAdminRequest(t, store, http.MethodDelete, url, nil, tc.setupAuth)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// requireBodyMatchCreatedKey checks that the key is returned once and matches the stored hash
+func requireBodyMatchCreatedKey(t *testing.T, recorder *httptest.ResponseRecorder) {
+	data, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	var response struct {
+		Data       req.CreateApiKeyResponse `json:"data"`
+		Message    string                   `json:"message"`
+		StatusCode int                      `json:"statusCode"`
+	}
+
+	err = json.Unmarshal(data, &response)
+	require.NoError(t, err)
+
+	require.True(t, strings.HasPrefix(response.Data.Key, response.Data.KeyPrefix))
+	require.NotNil(t, response.Data.ExpiresAt)
+	require.Equal(t, []string{"10.0.0.0/8"}, response.Data.AllowedIps)
+}
@@ -1,21 +1,25 @@
 package transfer
 
+import "github.com/ChokeGuy/simple-bank/util"
+
 type TransferRequest struct {
-	FromAccountID int64  `json:"fromAccountId" binding:"required,min=1"`
-	ToAccountID   int64  `json:"toAccountId" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountNumber string `json:"fromAccountNumber" binding:"required"`
+	ToAccountNumber   string `json:"toAccountNumber" binding:"required_without=ToPhone,excluded_with=ToPhone"`
+	// Amount is in the minor units of its currency, both accounts must hold that currency
+	Amount util.Money `json:"amount" binding:"money"`
+	// ToPhone is the verified phone number of the recipient, it is used instead of ToAccountNumber
+	ToPhone string `json:"toPhone,omitempty" binding:"omitempty,e164"`
 }
 
 type GetTransferRequest struct {
-	FromAccountID int64 `form:"fromAccountId" binding:"required,min=1"`
-	ToAccountID   int64 `form:"toAccountId" binding:"required,min=1"`
+	FromAccountNumber string `form:"fromAccountNumber" binding:"required"`
+	ToAccountNumber   string `form:"toAccountNumber" binding:"required"`
 }
 
 type GetFromAccountTransferRequest struct {
-	FromAccountID int64 `form:"fromAccountId" binding:"required,min=1"`
+	FromAccountNumber string `form:"fromAccountNumber" binding:"required"`
 }
 
 type GetToAccountTransferRequest struct {
-	ToAccountID int64 `form:"toAccountId" binding:"required,min=1"`
+	ToAccountNumber string `form:"toAccountNumber" binding:"required"`
 }
@@ -1 +1,86 @@
 package transfer
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	accountDto "github.com/ChokeGuy/simple-bank/api/account/dto"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/util"
+)
+
+type TransferResponse struct {
+	ID                uuid.UUID  `json:"id"`
+	FromAccountNumber string     `json:"fromAccountNumber"`
+	ToAccountNumber   string     `json:"toAccountNumber"`
+	Amount            util.Money `json:"amount"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+type EntryResponse struct {
+	ID            int64      `json:"id"`
+	AccountNumber string     `json:"accountNumber"`
+	Amount        util.Money `json:"amount"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type TransferTxResponse struct {
+	Transfer    TransferResponse           `json:"transfer"`
+	FromAccount accountDto.AccountResponse `json:"fromAccount"`
+	ToAccount   accountDto.AccountResponse `json:"toAccount"`
+	FromEntry   EntryResponse              `json:"fromEntry"`
+	ToEntry     EntryResponse              `json:"toEntry"`
+}
+
+// NewTransferTxResponse replaces the internal account IDs of a transfer result with account numbers
+func NewTransferTxResponse(result db.TransferTxResult) TransferTxResponse {
+	currency := result.FromAccount.Currency
+
+	return TransferTxResponse{
+		Transfer: TransferResponse{
+			ID:                result.Transfer.PublicID,
+			FromAccountNumber: result.FromAccount.AccountNumber,
+			ToAccountNumber:   result.ToAccount.AccountNumber,
+			Amount:            util.Money{Amount: result.Transfer.Amount, Currency: currency},
+			CreatedAt:         result.Transfer.CreatedAt,
+		},
+		FromAccount: accountDto.NewAccountResponse(result.FromAccount),
+		ToAccount:   accountDto.NewAccountResponse(result.ToAccount),
+		FromEntry: EntryResponse{
+			ID:            result.FromEntry.ID,
+			AccountNumber: result.FromAccount.AccountNumber,
+			Amount:        util.Money{Amount: result.FromEntry.Amount, Currency: currency},
+			CreatedAt:     result.FromEntry.CreatedAt,
+		},
+		ToEntry: EntryResponse{
+			ID:            result.ToEntry.ID,
+			AccountNumber: result.ToAccount.AccountNumber,
+			Amount:        util.Money{Amount: result.ToEntry.Amount, Currency: currency},
+			CreatedAt:     result.ToEntry.CreatedAt,
+		},
+	}
+}
+
+// transferRow lists the transfer history rows, they all share the same columns
+type transferRow interface {
+	db.GetTransfersRow | db.GetTransfersByFromAccountIdRow | db.GetTransfersByToAccountIdRow
+}
+
+// NewTransferResponses converts transfer history rows, transfers only happen between accounts of the same currency
+func NewTransferResponses[T transferRow](rows []T, currency string) []TransferResponse {
+	transfers := make([]TransferResponse, 0, len(rows))
+
+	for _, r := range rows {
+		row := db.GetTransfersRow(r)
+		transfers = append(transfers, TransferResponse{
+			ID:                row.PublicID,
+			FromAccountNumber: row.FromAccountNumber,
+			ToAccountNumber:   row.ToAccountNumber,
+			Amount:            util.Money{Amount: row.Amount, Currency: currency},
+			CreatedAt:         row.CreatedAt,
+		})
+	}
+
+	return transfers
+}
@@ -8,9 +8,13 @@ import (
 	dto "github.com/ChokeGuy/simple-bank/api/transfer/dto"
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
 	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
 	sv "github.com/ChokeGuy/simple-bank/server/http"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/ChokeGuy/simple-bank/worker"
 	"github.com/gin-gonic/gin"
 )
 
@@ -25,12 +29,17 @@ func NewTransferHandler(server *sv.Server) *TransferHandler {
 func (h *TransferHandler) MapRoutes() {
 	router := h.Router
 
-	authRoutes := router.Group("/").Use(auth.AuthMiddleWare(h.TokenMaker))
-
-	authRoutes.POST("/transfer", h.createTransfer)
-	authRoutes.GET("/transfers", h.getTransfers)
-	authRoutes.GET("/transfers/from", h.getFromAccountTransfers)
-	authRoutes.GET("/transfers/to", h.getToAccountTransfers)
+	authRoutes := router.Group("/").Use(auth.AuthMiddleWare(h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier))
+
+	authRoutes.POST(
+		"/transfer",
+		auth.RequirePermission(rbac.TransferCreate),
+		auth.RequireVerifiedEmail(h.Store, h.Config.RequireVerifiedEmail),
+		h.createTransfer,
+	)
+	authRoutes.GET("/transfers", auth.RequirePermission(rbac.TransferRead), h.getTransfers)
+	authRoutes.GET("/transfers/from", auth.RequirePermission(rbac.TransferRead), h.getFromAccountTransfers)
+	authRoutes.GET("/transfers/to", auth.RequirePermission(rbac.TransferRead), h.getToAccountTransfers)
 }
 
 func (h *TransferHandler) createTransfer(ctx *gin.Context) {
@@ -41,27 +50,62 @@ func (h *TransferHandler) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	statusCode, err := h.validTx(ctx, req)
+	fromAccount, toAccount, statusCode, err := h.validTx(ctx, req)
 
 	if err != nil {
 		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
 		return
 	}
 
+	// Checked once the request is known to be valid so clients are only sent to step up for a transfer that can happen
+	if h.StepUpPolicy.TransferRequiresStepUp(req.Amount.Amount, req.Amount.Currency) {
+		if err := h.StepUpPolicy.Check(ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)); err != nil {
+			auth.AbortStepUpRequired(ctx, h.StepUpPolicy)
+			return
+		}
+	}
+
 	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
 		Amount:        req.Amount,
+		AfterTransfer: func(q db.Querier, result db.TransferTxResult) error {
+			return worker.EnqueueTransferCompletedEvents(ctx, q, result)
+		},
+	}
+
+	// Moving money between accounts of the same owner never counts towards the KYC limits
+	if fromAccount.Owner != toAccount.Owner {
+		arg.BeforeTransfer = func(q db.Querier) error {
+			return h.KycPolicy.CheckTransfer(ctx, q, fromAccount.Owner, req.Amount.Currency, req.Amount.Amount)
+		}
 	}
 
-	result, err := h.Store.TransferTx(ctx, arg)
+	// The balance is checked again on the locked row, conflicts are retried by the store
+	result, err := h.Store.TransferTx(ctx, arg, db.WithSerializable())
 
 	if err != nil {
+		if errors.Is(err, kyc.ErrTransferLimitExceeded) {
+			ctx.JSON(http.StatusForbidden, res.ErrorResponse(http.StatusForbidden, err.Error()))
+			return
+		}
+
+		// Another transfer spent the balance after it was checked
+		if errors.Is(err, db.ErrInsufficientBalance) {
+			ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		if errors.Is(err, db.ErrTxRetriesExhausted) {
+			ctx.JSON(http.StatusServiceUnavailable, res.ErrorResponse(http.StatusServiceUnavailable, "transfer conflicted with concurrent transfers, please try again"))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(result, "Transfer created successfully"))
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewTransferTxResponse(result), "Transfer created successfully"))
 }
 
 func (h *TransferHandler) getTransfers(ctx *gin.Context) {
@@ -72,25 +116,27 @@ func (h *TransferHandler) getTransfers(ctx *gin.Context) {
 		return
 	}
 
-	if fromAccount, statusCode, err := h.getValidAccount(ctx, req.FromAccountID); err != nil {
+	fromAccount, statusCode, err := h.getValidAccount(ctx, req.FromAccountNumber)
+	if err != nil {
 		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
 		return
-	} else {
-		authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
-		if fromAccount.Owner != authPayload.UserName {
-			ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "account does not belong to user"))
-			return
-		}
 	}
 
-	if _, statusCode, err := h.getValidAccount(ctx, req.ToAccountID); err != nil {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	if !rbac.CanAccess(authPayload, fromAccount.Owner, rbac.TransferReadAny) {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "account does not belong to user"))
+		return
+	}
+
+	toAccount, statusCode, err := h.getValidAccount(ctx, req.ToAccountNumber)
+	if err != nil {
 		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
 		return
 	}
 
 	arg := db.GetTransfersParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
 	}
 
 	result, err := h.Store.GetTransfers(ctx, arg)
@@ -100,7 +146,7 @@ func (h *TransferHandler) getTransfers(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(result, "Transfer history retrieved successfully"))
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewTransferResponses(result, fromAccount.Currency), "Transfer history retrieved successfully"))
 }
 
 func (h *TransferHandler) getFromAccountTransfers(ctx *gin.Context) {
@@ -111,25 +157,26 @@ func (h *TransferHandler) getFromAccountTransfers(ctx *gin.Context) {
 		return
 	}
 
-	if fromAccount, statusCode, err := h.getValidAccount(ctx, req.FromAccountID); err != nil {
+	fromAccount, statusCode, err := h.getValidAccount(ctx, req.FromAccountNumber)
+	if err != nil {
 		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
 		return
-	} else {
-		authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
-		if fromAccount.Owner != authPayload.UserName {
-			ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "account does not belong to user"))
-			return
-		}
 	}
 
-	result, err := h.Store.GetTransfersByFromAccountId(ctx, req.FromAccountID)
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	if !rbac.CanAccess(authPayload, fromAccount.Owner, rbac.TransferReadAny) {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "account does not belong to user"))
+		return
+	}
+
+	result, err := h.Store.GetTransfersByFromAccountId(ctx, fromAccount.ID)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(result, "Transfer history retrieved successfully"))
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewTransferResponses(result, fromAccount.Currency), "Transfer history retrieved successfully"))
 }
 
 func (h *TransferHandler) getToAccountTransfers(ctx *gin.Context) {
@@ -140,63 +187,95 @@ func (h *TransferHandler) getToAccountTransfers(ctx *gin.Context) {
 		return
 	}
 
-	if toAccount, statusCode, err := h.getValidAccount(ctx, req.ToAccountID); err != nil {
+	toAccount, statusCode, err := h.getValidAccount(ctx, req.ToAccountNumber)
+	if err != nil {
 		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
 		return
-	} else {
-		authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
-		if toAccount.Owner != authPayload.UserName {
-			ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "account does not belong to user"))
-			return
-		}
 	}
 
-	result, err := h.Store.GetTransfersByToAccountId(ctx, req.ToAccountID)
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	if !rbac.CanAccess(authPayload, toAccount.Owner, rbac.TransferReadAny) {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "account does not belong to user"))
+		return
+	}
+
+	result, err := h.Store.GetTransfersByToAccountId(ctx, toAccount.ID)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(result, "Transfer history retrieved successfully"))
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewTransferResponses(result, toAccount.Currency), "Transfer history retrieved successfully"))
 }
 
-func (h *TransferHandler) getValidAccount(ctx *gin.Context, id int64) (db.Account, int, error) {
-	account, err := h.Store.GetAccount(ctx, id)
-	if errors.Is(err, db.ErrRecordNotFound) {
-		return db.Account{}, http.StatusBadRequest, fmt.Errorf("account with id %d not found", id)
+func (h *TransferHandler) getValidAccount(ctx *gin.Context, accountNumber string) (db.Account, int, error) {
+	accountNumber = util.NormalizeAccountNumber(accountNumber)
+
+	if err := util.ValidateAccountNumber(accountNumber); err != nil {
+		return db.Account{}, http.StatusBadRequest, err
+	}
+
+	account, err := h.Store.GetAccountByNumber(ctx, accountNumber)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.Account{}, http.StatusBadRequest, fmt.Errorf("account %s not found", accountNumber)
+		}
+		return db.Account{}, http.StatusInternalServerError, err
+	}
+
+	return account, http.StatusOK, nil
+}
+
+// getAccountByPhone resolves a phone alias, only numbers verified by their owner are aliases
+func (h *TransferHandler) getAccountByPhone(ctx *gin.Context, phone string, currency string) (db.Account, int, error) {
+	account, err := h.Store.GetAccountByVerifiedPhone(ctx, db.GetAccountByVerifiedPhoneParams{
+		Phone:    phone,
+		Currency: currency,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.Account{}, http.StatusBadRequest, fmt.Errorf("no %s account found for phone number %s", currency, phone)
+		}
+		return db.Account{}, http.StatusInternalServerError, err
 	}
 
 	return account, http.StatusOK, nil
 }
 
-func (h *TransferHandler) validTx(ctx *gin.Context, req dto.TransferRequest) (int, error) {
+func (h *TransferHandler) validTx(ctx *gin.Context, req dto.TransferRequest) (db.Account, db.Account, int, error) {
 	// Validate "From" account
-	fromAccount, statusCode, err := h.getValidAccount(ctx, req.FromAccountID)
+	fromAccount, statusCode, err := h.getValidAccount(ctx, req.FromAccountNumber)
 	if err != nil {
-		return statusCode, err
+		return db.Account{}, db.Account{}, statusCode, err
 	}
 
 	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.UserName {
-		return http.StatusUnauthorized, fmt.Errorf("account does not belong to user")
+	// Money only moves out of accounts of the caller, whatever their role
+	if !rbac.CanAccess(authPayload, fromAccount.Owner) {
+		return db.Account{}, db.Account{}, http.StatusUnauthorized, fmt.Errorf("account does not belong to user")
 	}
 
-	// Validate "To" account
-	toAccount, statusCode, err := h.getValidAccount(ctx, req.ToAccountID)
+	// Validate "To" account, a phone alias stands for the account of its owner in the currency of the transfer
+	var toAccount db.Account
+	if req.ToPhone != "" {
+		toAccount, statusCode, err = h.getAccountByPhone(ctx, req.ToPhone, req.Amount.Currency)
+	} else {
+		toAccount, statusCode, err = h.getValidAccount(ctx, req.ToAccountNumber)
+	}
 	if err != nil {
-		return statusCode, err
+		return db.Account{}, db.Account{}, statusCode, err
 	}
 
 	// Check for currency mismatch
-	if fromAccount.Currency != req.Currency || toAccount.Currency != req.Currency {
-		return http.StatusBadRequest, fmt.Errorf("account currency mismatch")
+	if fromAccount.Currency != req.Amount.Currency || toAccount.Currency != req.Amount.Currency {
+		return db.Account{}, db.Account{}, http.StatusBadRequest, fmt.Errorf("account currency mismatch")
 	}
 
-	// Check for sufficient balance
-	if fromAccount.Balance < req.Amount {
-		return http.StatusBadRequest, fmt.Errorf("insufficient account balance")
+	// Check for sufficient balance early, TransferTx still refuses to overdraw the account
+	if fromAccount.Balance < req.Amount.Amount {
+		return db.Account{}, db.Account{}, http.StatusBadRequest, fmt.Errorf("insufficient account balance")
 	}
 
-	return http.StatusOK, nil
+	return fromAccount, toAccount, http.StatusOK, nil
 }
@@ -16,11 +16,13 @@ import (
 	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
 	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
 	server "github.com/ChokeGuy/simple-bank/server/http"
 	"github.com/ChokeGuy/simple-bank/util"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,10 +36,11 @@ func RandomEntry(accountID int64, amount int64) db.Entry {
 
 func RandomAccountWithParams(ID int64, currency, owner string) db.Account {
 	return db.Account{
-		ID:       ID,
-		Owner:    owner,
-		Balance:  util.RandomMoney(),
-		Currency: currency,
+		ID:            ID,
+		Owner:         owner,
+		Balance:       util.RandomMoney(),
+		Currency:      currency,
+		AccountNumber: util.RandomAccountNumber(),
 	}
 }
 
@@ -55,8 +58,9 @@ func RandomTxResult(t *testing.T) db.TransferTxResult {
 
 	transfer := db.Transfer{
 		ID:            util.RandomInt(1, 1000),
-		FromAccountID: fromAccount.Balance,
-		ToAccountID:   toAccount.Balance,
+		PublicID:      uuid.New(),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
 		Amount:        randBalance,
 	}
 
@@ -76,6 +80,11 @@ func TestCreateTransfer(t *testing.T) {
 	// Create a new transferResult
 	result := RandomTxResult(t)
 
+	// Above the default CAD threshold, such a transfer needs a recent step-up
+	largeResult := RandomTxResult(t)
+	largeResult.FromAccount.Balance = 500_000
+	largeResult.Transfer.Amount = 200_000
+
 	testCases := []struct {
 		name          string
 		body          req.TransferRequest
@@ -86,10 +95,9 @@ func TestCreateTransfer(t *testing.T) {
 		{
 			name: "OK",
 			body: req.TransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
-				Amount:        result.Transfer.Amount,
-				Currency:      result.FromAccount.Currency,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
@@ -98,19 +106,19 @@ func TestCreateTransfer(t *testing.T) {
 				arg := db.TransferTxParams{
 					FromAccountID: result.Transfer.FromAccountID,
 					ToAccountID:   result.Transfer.ToAccountID,
-					Amount:        result.Transfer.Amount,
+					Amount:        util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 				}
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.ToAccountID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(result.ToAccount, nil)
 
 				store.EXPECT().
-					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					TransferTx(gomock.Any(), EqTransferTxParams(arg, result), gomock.Any()).
 					Times(1).
 					Return(result, nil)
 			},
@@ -122,28 +130,49 @@ func TestCreateTransfer(t *testing.T) {
 				requireBodyMatchTxResult(t, recorder.Body, result)
 			},
 		},
+		{
+			name: "EmailNotVerified",
+			body: req.TransferRequest{
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(result.FromAccount.Owner)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: result.FromAccount.Owner}, nil)
+
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "UnAuthorizedUser",
 			body: req.TransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
-				Amount:        result.Transfer.Amount,
-				Currency:      result.FromAccount.Currency,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "unauthorized_user", util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.TransferTxParams{
-					FromAccountID: result.Transfer.FromAccountID,
-					ToAccountID:   result.Transfer.ToAccountID,
-				}
-
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.ToAccountID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -156,19 +185,14 @@ func TestCreateTransfer(t *testing.T) {
 		{
 			name: "NoAuthorization",
 			body: req.TransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
-				Amount:        result.Transfer.Amount,
-				Currency:      result.FromAccount.Currency,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.TransferTxParams{
-					FromAccountID: result.Transfer.FromAccountID,
-				}
-
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -178,23 +202,62 @@ func TestCreateTransfer(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "InvalidAccountNumber",
+			body: req.TransferRequest{
+				FromAccountNumber: "123456789012",
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "FromAccountNotFound",
 			body: req.TransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
-				Amount:        result.Transfer.Amount,
-				Currency:      result.FromAccount.Currency,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.TransferTxParams{
-					FromAccountID: result.Transfer.FromAccountID,
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
+					Times(1).
+					Return(db.Account{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				if recorder.Code != http.StatusOK {
+					t.Log("Response body: ", recorder.Body.String())
 				}
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ToAccountError",
+			body: req.TransferRequest{
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
+					Times(1).
+					Return(result.FromAccount, nil)
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 			},
@@ -206,12 +269,11 @@ func TestCreateTransfer(t *testing.T) {
 			},
 		},
 		{
-			name: "ToAccountError",
+			name: "ToPhoneAlias",
 			body: req.TransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
-				Amount:        result.Transfer.Amount,
-				Currency:      result.FromAccount.Currency,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToPhone:           "+84912345678",
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
@@ -220,30 +282,84 @@ func TestCreateTransfer(t *testing.T) {
 				arg := db.TransferTxParams{
 					FromAccountID: result.Transfer.FromAccountID,
 					ToAccountID:   result.Transfer.ToAccountID,
+					Amount:        util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 				}
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.ToAccountID)).
+				store.EXPECT().
+					GetAccountByVerifiedPhone(gomock.Any(), gomock.Eq(db.GetAccountByVerifiedPhoneParams{
+						Phone:    "+84912345678",
+						Currency: result.FromAccount.Currency,
+					})).
+					Times(1).
+					Return(result.ToAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), EqTransferTxParams(arg, result), gomock.Any()).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTxResult(t, recorder.Body, result)
+			},
+		},
+		{
+			name: "ToPhoneNotFound",
+			body: req.TransferRequest{
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToPhone:           "+84912345678",
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
+					Times(1).
+					Return(result.FromAccount, nil)
+
+				store.EXPECT().
+					GetAccountByVerifiedPhone(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BothRecipients",
+			body: req.TransferRequest{
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				ToPhone:           "+84912345678",
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				if recorder.Code != http.StatusOK {
-					t.Log("Response body: ", recorder.Body.String())
-				}
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
 			name: "AmountBadRequest",
 			body: req.TransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
-				Amount:        -1,
-				Currency:      result.FromAccount.Currency,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: -1, Currency: result.FromAccount.Currency},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
@@ -252,11 +368,11 @@ func TestCreateTransfer(t *testing.T) {
 				arg := db.TransferTxParams{
 					FromAccountID: result.Transfer.FromAccountID,
 					ToAccountID:   result.Transfer.ToAccountID,
-					Amount:        result.Transfer.Amount,
+					Amount:        util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 				}
 
 				store.EXPECT().
-					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					TransferTx(gomock.Any(), EqTransferTxParams(arg, result), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -269,10 +385,9 @@ func TestCreateTransfer(t *testing.T) {
 		{
 			name: "CurrencyBadRequest",
 			body: req.TransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
-				Amount:        result.Transfer.Amount,
-				Currency:      "CAD1",
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: "CAD1"},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
@@ -281,11 +396,11 @@ func TestCreateTransfer(t *testing.T) {
 				arg := db.TransferTxParams{
 					FromAccountID: result.Transfer.FromAccountID,
 					ToAccountID:   result.Transfer.ToAccountID,
-					Amount:        result.Transfer.Amount,
+					Amount:        util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 				}
 
 				store.EXPECT().
-					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					TransferTx(gomock.Any(), EqTransferTxParams(arg, result), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -298,10 +413,9 @@ func TestCreateTransfer(t *testing.T) {
 		{
 			name: "InternalError",
 			body: req.TransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
-				Amount:        result.Transfer.Amount,
-				Currency:      result.FromAccount.Currency,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
@@ -310,19 +424,19 @@ func TestCreateTransfer(t *testing.T) {
 				arg := db.TransferTxParams{
 					FromAccountID: result.Transfer.FromAccountID,
 					ToAccountID:   result.Transfer.ToAccountID,
-					Amount:        result.Transfer.Amount,
+					Amount:        util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
 				}
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(arg.ToAccountID)).
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(result.ToAccount, nil)
 
 				store.EXPECT().
-					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					TransferTx(gomock.Any(), EqTransferTxParams(arg, result), gomock.Any()).
 					Times(1).
 					Return(db.TransferTxResult{}, sql.ErrConnDone)
 			},
@@ -333,6 +447,149 @@ func TestCreateTransfer(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "TxConflict",
+			body: req.TransferRequest{
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
+					Times(1).
+					Return(result.FromAccount, nil)
+
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
+					Times(1).
+					Return(result.ToAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrTxRetriesExhausted)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+			},
+		},
+		{
+			name: "BalanceSpentConcurrently",
+			body: req.TransferRequest{
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
+					Times(1).
+					Return(result.FromAccount, nil)
+
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
+					Times(1).
+					Return(result.ToAccount, nil)
+
+				// The balance read before the transaction no longer covers the amount
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientBalance)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "KycLimitExceeded",
+			body: req.TransferRequest{
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
+					Times(1).
+					Return(result.FromAccount, nil)
+
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
+					Times(1).
+					Return(result.ToAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, kyc.ErrTransferLimitExceeded)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "LargeTransferNeedsStepUp",
+			body: req.TransferRequest{
+				FromAccountNumber: largeResult.FromAccount.AccountNumber,
+				ToAccountNumber:   largeResult.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: largeResult.Transfer.Amount, Currency: largeResult.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, largeResult.FromAccount.Owner, util.DepositorRole, time.Minute,
+					token.WithAuth(time.Now().Add(-time.Hour), token.AuthStrengthPassword))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(largeResult.FromAccount.AccountNumber)).
+					Times(1).
+					Return(largeResult.FromAccount, nil)
+
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(largeResult.ToAccount.AccountNumber)).
+					Times(1).
+					Return(largeResult.ToAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Header().Get("WWW-Authenticate"), "insufficient_user_authentication")
+			},
+		},
+		{
+			name: "LargeTransferAfterStepUp",
+			body: req.TransferRequest{
+				FromAccountNumber: largeResult.FromAccount.AccountNumber,
+				ToAccountNumber:   largeResult.ToAccount.AccountNumber,
+				Amount:            util.Money{Amount: largeResult.Transfer.Amount, Currency: largeResult.FromAccount.Currency},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, largeResult.FromAccount.Owner, util.DepositorRole, time.Minute,
+					token.WithAuth(time.Now().Add(-time.Minute), token.AuthStrengthMfa))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(largeResult.FromAccount.AccountNumber)).
+					Times(1).
+					Return(largeResult.FromAccount, nil)
+
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(largeResult.ToAccount.AccountNumber)).
+					Times(1).
+					Return(largeResult.ToAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(largeResult, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -346,6 +603,12 @@ func TestCreateTransfer(t *testing.T) {
 			//build stubs
 			tc.buildStubs(store)
 
+			// Owners are verified unless the case stubs the lookup itself
+			store.EXPECT().
+				GetUserByUserName(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return(db.GetUserByUserNameRow{IsEmailVerified: true}, nil)
+
 			//start new server
 			cfg, err := pkg.LoadConfig("../../")
 			require.NoError(t, err)
@@ -385,8 +648,8 @@ func TestGetTransfers(t *testing.T) {
 		{
 			name: "OK",
 			body: req.GetTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
@@ -398,12 +661,12 @@ func TestGetTransfers(t *testing.T) {
 				}
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.ToAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(result.ToAccount, nil)
 
@@ -412,10 +675,11 @@ func TestGetTransfers(t *testing.T) {
 					Times(1).
 					Return([]db.GetTransfersRow{
 						{
-							FromAccountID: result.Transfer.FromAccountID,
-							ToAccountID:   result.Transfer.ToAccountID,
-							Amount:        result.Transfer.Amount,
-							CreatedAt:     result.Transfer.CreatedAt,
+							PublicID:          result.Transfer.PublicID,
+							FromAccountNumber: result.FromAccount.AccountNumber,
+							ToAccountNumber:   result.ToAccount.AccountNumber,
+							Amount:            result.Transfer.Amount,
+							CreatedAt:         result.Transfer.CreatedAt,
 						},
 					}, nil)
 			},
@@ -424,31 +688,26 @@ func TestGetTransfers(t *testing.T) {
 					t.Log("Response body: ", recorder.Body.String())
 				}
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMathTransfers(t, recorder.Body, []db.Transfer{result.Transfer})
+				requireBodyMathTransfers(t, recorder.Body, result)
 			},
 		},
 		{
 			name: "UnauthorizedUser",
 			body: req.GetTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "unauthorized_user", util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.GetTransfersParams{
-					FromAccountID: result.Transfer.FromAccountID,
-					ToAccountID:   result.Transfer.ToAccountID,
-				}
-
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.ToAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -461,18 +720,14 @@ func TestGetTransfers(t *testing.T) {
 		{
 			name: "NoAuthorization",
 			body: req.GetTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.GetTransfersParams{
-					FromAccountID: result.Transfer.FromAccountID,
-				}
-
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -485,19 +740,15 @@ func TestGetTransfers(t *testing.T) {
 		{
 			name: "FromAccountNotFound",
 			body: req.GetTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.GetTransfersParams{
-					FromAccountID: result.Transfer.FromAccountID,
-				}
-
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 			},
@@ -511,25 +762,20 @@ func TestGetTransfers(t *testing.T) {
 		{
 			name: "ToAccountNotFound",
 			body: req.GetTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.GetTransfersParams{
-					FromAccountID: result.Transfer.FromAccountID,
-					ToAccountID:   result.Transfer.ToAccountID,
-				}
-
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.ToAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 			},
@@ -543,8 +789,8 @@ func TestGetTransfers(t *testing.T) {
 		{
 			name: "InternalError",
 			body: req.GetTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
-				ToAccountID:   result.Transfer.ToAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
+				ToAccountNumber:   result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
@@ -556,12 +802,12 @@ func TestGetTransfers(t *testing.T) {
 				}
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.FromAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg.ToAccountID)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(result.ToAccount, nil)
 
@@ -620,9 +866,9 @@ func TestGetTransfers(t *testing.T) {
 			transferHandler.MapRoutes()
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/transfers?fromAccountId=%d&toAccountId=%d",
-				tc.body.FromAccountID,
-				tc.body.ToAccountID)
+			url := fmt.Sprintf("/transfers?fromAccountNumber=%s&toAccountNumber=%s",
+				tc.body.FromAccountNumber,
+				tc.body.ToAccountNumber)
 
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
@@ -648,7 +894,7 @@ func TestGetFromAccountTransfers(t *testing.T) {
 		{
 			name: "OK",
 			body: req.GetFromAccountTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
@@ -657,7 +903,7 @@ func TestGetFromAccountTransfers(t *testing.T) {
 				arg := result.Transfer.FromAccountID
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
@@ -666,10 +912,11 @@ func TestGetFromAccountTransfers(t *testing.T) {
 					Times(1).
 					Return([]db.GetTransfersByFromAccountIdRow{
 						{
-							FromAccountID: result.Transfer.FromAccountID,
-							ToAccountID:   result.Transfer.ToAccountID,
-							Amount:        result.Transfer.Amount,
-							CreatedAt:     result.Transfer.CreatedAt,
+							PublicID:          result.Transfer.PublicID,
+							FromAccountNumber: result.FromAccount.AccountNumber,
+							ToAccountNumber:   result.ToAccount.AccountNumber,
+							Amount:            result.Transfer.Amount,
+							CreatedAt:         result.Transfer.CreatedAt,
 						},
 					}, nil)
 			},
@@ -678,13 +925,13 @@ func TestGetFromAccountTransfers(t *testing.T) {
 					t.Log("Response body: ", recorder.Body.String())
 				}
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMathTransfers(t, recorder.Body, []db.Transfer{result.Transfer})
+				requireBodyMathTransfers(t, recorder.Body, result)
 			},
 		},
 		{
 			name: "UnAuthorizedUser",
 			body: req.GetFromAccountTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "unauthorized_user", util.DepositorRole, time.Minute)
@@ -693,7 +940,7 @@ func TestGetFromAccountTransfers(t *testing.T) {
 				arg := result.Transfer.FromAccountID
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
@@ -711,15 +958,13 @@ func TestGetFromAccountTransfers(t *testing.T) {
 		{
 			name: "NoAuthorization",
 			body: req.GetFromAccountTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := result.Transfer.FromAccountID
-
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -732,16 +977,14 @@ func TestGetFromAccountTransfers(t *testing.T) {
 		{
 			name: "FromAccountNotFound",
 			body: req.GetFromAccountTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := result.Transfer.FromAccountID
-
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 			},
@@ -755,7 +998,7 @@ func TestGetFromAccountTransfers(t *testing.T) {
 		{
 			name: "InternalError",
 			body: req.GetFromAccountTransferRequest{
-				FromAccountID: result.Transfer.FromAccountID,
+				FromAccountNumber: result.FromAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.FromAccount.Owner, util.DepositorRole, time.Minute)
@@ -764,7 +1007,7 @@ func TestGetFromAccountTransfers(t *testing.T) {
 				arg := result.Transfer.FromAccountID
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.FromAccount.AccountNumber)).
 					Times(1).
 					Return(result.FromAccount, nil)
 
@@ -823,8 +1066,8 @@ func TestGetFromAccountTransfers(t *testing.T) {
 			transferHandler.MapRoutes()
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/transfers/from?fromAccountId=%d",
-				tc.body.FromAccountID)
+			url := fmt.Sprintf("/transfers/from?fromAccountNumber=%s",
+				tc.body.FromAccountNumber)
 
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
@@ -850,7 +1093,7 @@ func TestGetToAccountTransfers(t *testing.T) {
 		{
 			name: "OK",
 			body: req.GetToAccountTransferRequest{
-				ToAccountID: result.Transfer.ToAccountID,
+				ToAccountNumber: result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.ToAccount.Owner, util.DepositorRole, time.Minute)
@@ -859,7 +1102,7 @@ func TestGetToAccountTransfers(t *testing.T) {
 				arg := result.Transfer.ToAccountID
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(result.ToAccount, nil)
 
@@ -868,10 +1111,11 @@ func TestGetToAccountTransfers(t *testing.T) {
 					Times(1).
 					Return([]db.GetTransfersByToAccountIdRow{
 						{
-							FromAccountID: result.Transfer.FromAccountID,
-							ToAccountID:   result.Transfer.ToAccountID,
-							Amount:        result.Transfer.Amount,
-							CreatedAt:     result.Transfer.CreatedAt,
+							PublicID:          result.Transfer.PublicID,
+							FromAccountNumber: result.FromAccount.AccountNumber,
+							ToAccountNumber:   result.ToAccount.AccountNumber,
+							Amount:            result.Transfer.Amount,
+							CreatedAt:         result.Transfer.CreatedAt,
 						},
 					}, nil)
 			},
@@ -880,13 +1124,13 @@ func TestGetToAccountTransfers(t *testing.T) {
 					t.Log("Response body: ", recorder.Body.String())
 				}
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMathTransfers(t, recorder.Body, []db.Transfer{result.Transfer})
+				requireBodyMathTransfers(t, recorder.Body, result)
 			},
 		},
 		{
 			name: "UnAuthorizedUser",
 			body: req.GetToAccountTransferRequest{
-				ToAccountID: result.Transfer.ToAccountID,
+				ToAccountNumber: result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "unauthorized_user", util.DepositorRole, time.Minute)
@@ -895,7 +1139,7 @@ func TestGetToAccountTransfers(t *testing.T) {
 				arg := result.Transfer.ToAccountID
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(result.ToAccount, nil)
 
@@ -913,15 +1157,13 @@ func TestGetToAccountTransfers(t *testing.T) {
 		{
 			name: "NoAuthorization",
 			body: req.GetToAccountTransferRequest{
-				ToAccountID: result.Transfer.ToAccountID,
+				ToAccountNumber: result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := result.Transfer.ToAccountID
-
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -934,16 +1176,14 @@ func TestGetToAccountTransfers(t *testing.T) {
 		{
 			name: "FromAccountNotFound",
 			body: req.GetToAccountTransferRequest{
-				ToAccountID: result.Transfer.ToAccountID,
+				ToAccountNumber: result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.ToAccount.Owner, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := result.Transfer.ToAccountID
-
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(db.Account{}, db.ErrRecordNotFound)
 			},
@@ -957,7 +1197,7 @@ func TestGetToAccountTransfers(t *testing.T) {
 		{
 			name: "InternalError",
 			body: req.GetToAccountTransferRequest{
-				ToAccountID: result.Transfer.ToAccountID,
+				ToAccountNumber: result.ToAccount.AccountNumber,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, result.ToAccount.Owner, util.DepositorRole, time.Minute)
@@ -966,7 +1206,7 @@ func TestGetToAccountTransfers(t *testing.T) {
 				arg := result.Transfer.ToAccountID
 
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(arg)).
+					GetAccountByNumber(gomock.Any(), gomock.Eq(result.ToAccount.AccountNumber)).
 					Times(1).
 					Return(result.ToAccount, nil)
 
@@ -1025,8 +1265,8 @@ func TestGetToAccountTransfers(t *testing.T) {
 			transferHandler.MapRoutes()
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/transfers/to?toAccountId=%d",
-				tc.body.ToAccountID)
+			url := fmt.Sprintf("/transfers/to?toAccountNumber=%s",
+				tc.body.ToAccountNumber)
 
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
@@ -1043,38 +1283,37 @@ func requireBodyMatchTxResult(t *testing.T, body *bytes.Buffer, txResult db.Tran
 	require.NoError(t, err)
 
 	var response struct {
-		Data       db.TransferTxResult `json:"data"`
-		Message    string              `json:"message"`
-		StatusCode int                 `json:"statusCode"`
+		Data       req.TransferTxResponse `json:"data"`
+		Message    string                 `json:"message"`
+		StatusCode int                    `json:"statusCode"`
 	}
 
 	err = json.Unmarshal(data, &response)
 
 	require.NoError(t, err)
-	require.Equal(t, txResult, response.Data)
+	require.Equal(t, req.NewTransferTxResponse(txResult), response.Data)
 
 }
 
-func requireBodyMathTransfers(t *testing.T, body *bytes.Buffer, transfers []db.Transfer) {
+func requireBodyMathTransfers(t *testing.T, body *bytes.Buffer, txResult db.TransferTxResult) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
 
 	var response struct {
-		Data       []db.Transfer `json:"data"`
-		Message    string        `json:"message"`
-		StatusCode int           `json:"statusCode"`
+		Data       []req.TransferResponse `json:"data"`
+		Message    string                 `json:"message"`
+		StatusCode int                    `json:"statusCode"`
 	}
 
 	err = json.Unmarshal(data, &response)
 
 	require.NoError(t, err)
-
-	for i, transfer := range transfers {
-		require.Equal(t, transfer.Amount, response.Data[i].Amount)
-		require.Equal(t, transfer.FromAccountID, response.Data[i].FromAccountID)
-		require.Equal(t, transfer.ToAccountID, response.Data[i].ToAccountID)
-
-		require.NotEmpty(t, transfer.ID)
-		require.WithinDuration(t, transfer.CreatedAt, response.Data[i].CreatedAt, time.Second)
-	}
+	require.Len(t, response.Data, 1)
+
+	transfer := response.Data[0]
+	require.Equal(t, txResult.Transfer.PublicID, transfer.ID)
+	require.Equal(t, util.Money{Amount: txResult.Transfer.Amount, Currency: txResult.FromAccount.Currency}, transfer.Amount)
+	require.Equal(t, txResult.FromAccount.AccountNumber, transfer.FromAccountNumber)
+	require.Equal(t, txResult.ToAccount.AccountNumber, transfer.ToAccountNumber)
+	require.WithinDuration(t, txResult.Transfer.CreatedAt, transfer.CreatedAt, time.Second)
 }
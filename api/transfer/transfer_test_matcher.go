@@ -0,0 +1,94 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/webhook"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/ChokeGuy/simple-bank/worker"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+)
+
+type eqTransferTxParamsMatcher struct {
+	arg    db.TransferTxParams
+	result db.TransferTxResult
+}
+
+func (e eqTransferTxParamsMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.TransferTxParams)
+	if !ok {
+		return false
+	}
+
+	if actualArg.FromAccountID != e.arg.FromAccountID ||
+		actualArg.ToAccountID != e.arg.ToAccountID ||
+		actualArg.Amount != e.arg.Amount {
+		return false
+	}
+
+	// Transfers to another user count towards the KYC limit of the sender
+	if (actualArg.BeforeTransfer != nil) != (e.result.FromAccount.Owner != e.result.ToAccount.Owner) {
+		return false
+	}
+
+	// AfterTransfer must notify the owners of both accounts through the outbox of the transaction
+	outbox := &outboxRecorder{}
+	if err := actualArg.AfterTransfer(outbox, e.result); err != nil {
+		return false
+	}
+
+	return outbox.hasTransferCompleted(e.result.FromAccount.Owner, e.result.ToAccount.Owner, e.result.Transfer.PublicID, e.arg.Amount)
+}
+
+func (e eqTransferTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v", e.arg)
+}
+
+func EqTransferTxParams(arg db.TransferTxParams, result db.TransferTxResult) gomock.Matcher {
+	return eqTransferTxParamsMatcher{arg, result}
+}
+
+// outboxRecorder stands in for the transaction Querier and records outbox messages
+type outboxRecorder struct {
+	db.Querier
+	messages []db.CreateOutboxMessageParams
+}
+
+func (r *outboxRecorder) CreateOutboxMessage(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+	r.messages = append(r.messages, arg)
+	return db.OutboxMessage{}, nil
+}
+
+func (r *outboxRecorder) hasTransferCompleted(fromOwner string, toOwner string, transferID uuid.UUID, amount util.Money) bool {
+	if len(r.messages) != 2 {
+		return false
+	}
+
+	owners := []string{fromOwner, toOwner}
+	for i, message := range r.messages {
+		if message.TaskType != worker.TaskDispatchWebhookEvent {
+			return false
+		}
+
+		var payload worker.PayloadDispatchWebhookEvent
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return false
+		}
+
+		var data webhook.TransferCompletedData
+		if err := json.Unmarshal(payload.Event.Data, &data); err != nil {
+			return false
+		}
+
+		if payload.Owner != owners[i] || payload.Event.Type != webhook.EventTransferCompleted ||
+			data.TransferID != transferID || data.Amount != amount {
+			return false
+		}
+	}
+
+	return true
+}
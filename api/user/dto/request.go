@@ -12,21 +12,116 @@ type GetUserByUserNameRequest struct {
 }
 
 type LoginUserRequest struct {
-	UserName string `json:"userName" binding:"required,alphanum"`
-	Password string `json:"password" binding:"required,password"`
+	UserName    string `json:"userName" binding:"required,alphanum"`
+	Password    string `json:"password" binding:"required,max=100"`
+	DeviceLabel string `json:"deviceLabel" binding:"max=64"`
+}
+
+type VerifyLoginMfaRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required,max=32"`
+}
+
+// SendLoginSmsCodeRequest asks for the login code of a pending challenge to be texted to the verified phone
+type SendLoginSmsCodeRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+}
+
+// BeginOidcLoginRequest starts a login at the corporate identity provider
+type BeginOidcLoginRequest struct {
+	DeviceLabel string `form:"deviceLabel" binding:"max=64"`
+}
+
+// OidcCallbackRequest carries the parameters the identity provider redirected the browser back with,
+// error is set instead of code when the user could not sign in
+type OidcCallbackRequest struct {
+	Code             string `form:"code" binding:"required_without=Error,max=2048"`
+	State            string `form:"state" binding:"required,max=256"`
+	Error            string `form:"error" binding:"max=256"`
+	ErrorDescription string `form:"error_description" binding:"max=1024"`
+}
+
+// StepUpRequest needs the code of a second factor when 2FA is on and the password otherwise. Users
+// created by an SSO login have no password until they reset one, without 2FA they sign in at the
+// provider again instead.
+type StepUpRequest struct {
+	Password string `json:"password" binding:"max=100"`
+	Code     string `json:"code" binding:"max=32"`
 }
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
+type RevokeSessionRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type ListLoginEventsRequest struct {
+	Page int32 `form:"page,default=1" binding:"min=1"`
+	Size int32 `form:"size" binding:"required,min=5,max=50"`
+}
+
+type GetDenyLoginRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+type DenyLoginRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 type UpdateUserRequest struct {
-	UserName string `json:"userName" binding:"alphanum"`
-	FullName string `json:"fullName" binding:"omitempty,min=3,max=100"`
-	Email    string `json:"email" binding:"omitempty,email"`
+	UserName    string `json:"userName" binding:"alphanum"`
+	FullName    string `json:"fullName" binding:"omitempty,min=3,max=100"`
+	Email       string `json:"email" binding:"omitempty,email"`
+	DateOfBirth string `json:"dateOfBirth" binding:"omitempty,date_of_birth"`
+	Phone       string `json:"phone" binding:"omitempty,e164"`
+	Address     string `json:"address" binding:"omitempty,min=5,max=200"`
+	Nationality string `json:"nationality" binding:"omitempty,iso3166_1_alpha2"`
+}
+
+// UploadKycDocumentRequest is the form of a document upload, the file itself is sent in the "file" field
+type UploadKycDocumentRequest struct {
+	Kind string `form:"kind" binding:"required,kyc_document_kind"`
+}
+
+type ListKycDocumentsRequest struct {
+	Page int32 `form:"page,default=1" binding:"min=1"`
+	Size int32 `form:"size" binding:"required,min=5,max=50"`
+}
+
+type UserLockoutRequest struct {
+	UserName string `uri:"username" binding:"required,alphanum"`
 }
 
 type VerifyUserEmailRequest struct {
 	EmailId    int64  `form:"emailId" binding:"required"`
 	SecretCode string `form:"secretCode" binding:"required"`
 }
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword    string `json:"currentPassword" binding:"required,max=100"`
+	NewPassword        string `json:"newPassword" binding:"required,password"`
+	KeepCurrentSession bool   `json:"keepCurrentSession"`
+}
+
+type ConfirmTotpRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type DisableTotpRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"time"
 
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	"github.com/google/uuid"
 )
 
@@ -12,6 +13,53 @@ type UserResponse struct {
 	Email             string `json:"email"`
 	PasswordChangedAt string `json:"passwordChangedAt"`
 	CreatedAt         string `json:"createdAt"`
+	// The profile is only returned to the user themselves and to bankers
+	DateOfBirth string `json:"dateOfBirth,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Nationality string `json:"nationality,omitempty"`
+	KycStatus   string `json:"kycStatus,omitempty"`
+	// IsPhoneVerified tells whether the phone can be used as a transfer alias and to receive login codes
+	IsPhoneVerified bool `json:"isPhoneVerified"`
+}
+
+// NewUserResponse converts a user with its profile, it must not be used for views of other users
+func NewUserResponse(user db.User) UserResponse {
+	response := UserResponse{
+		UserName:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt.String(),
+		CreatedAt:         user.CreatedAt.String(),
+		Phone:             user.Phone,
+		Address:           user.Address,
+		Nationality:       user.Nationality,
+		KycStatus:         user.KycStatus,
+		IsPhoneVerified:   user.IsPhoneVerified,
+	}
+
+	if user.DateOfBirth.Valid {
+		response.DateOfBirth = user.DateOfBirth.Time.Format(time.DateOnly)
+	}
+
+	return response
+}
+
+// NewUserRowResponse converts a user row with its profile, it must not be used for views of other users
+func NewUserRowResponse(user db.GetUserByUserNameRow) UserResponse {
+	return NewUserResponse(db.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+		DateOfBirth:       user.DateOfBirth,
+		Phone:             user.Phone,
+		Address:           user.Address,
+		Nationality:       user.Nationality,
+		KycStatus:         user.KycStatus,
+		IsPhoneVerified:   user.IsPhoneVerified,
+	})
 }
 
 type GetUserByUserNameResponse = UserResponse
@@ -25,11 +73,211 @@ type LoginUserResponse struct {
 	User                  UserResponse `json:"user"`
 }
 
+type MfaChallengeResponse struct {
+	MfaRequired    bool      `json:"mfaRequired"`
+	ChallengeToken string    `json:"challengeToken"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+// BeginOidcLoginResponse is where the browser is sent to sign in, state comes back with the callback
+type BeginOidcLoginResponse struct {
+	AuthorizationUrl string    `json:"authorizationUrl"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
+
 type RefreshTokenResponse struct {
+	AccessToken           string    `json:"accessToken"`
+	AccessTokenExpiresAt  time.Time `json:"accessTokenExpiresAt"`
+	RefreshToken          string    `json:"refreshToken"`
+	RefreshTokenExpiresAt time.Time `json:"refreshTokenExpiresAt"`
+}
+
+// StepUpResponse is a short-lived access token of the current session for sensitive operations
+type StepUpResponse struct {
 	AccessToken          string    `json:"accessToken"`
 	AccessTokenExpiresAt time.Time `json:"accessTokenExpiresAt"`
+	AuthTime             time.Time `json:"authTime"`
+	AuthStrength         string    `json:"authStrength"`
+}
+
+type ChangePasswordResponse struct {
+	Revoked int64 `json:"revoked"`
+	// Tokens replace the ones of the current session when it is kept
+	Tokens *RefreshTokenResponse `json:"tokens,omitempty"`
+}
+
+type UserLockoutResponse struct {
+	UserName string `json:"userName"`
+	Failures int64  `json:"failures"`
+	Locked   bool   `json:"locked"`
+	// LockedUntil is set while the user is locked out or has to wait before the next attempt
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
 }
 
 type VerifyUserEmailResponse struct {
 	IsVerified bool `json:"isVerified"`
 }
+
+type SessionResponse struct {
+	ID          uuid.UUID `json:"id"`
+	DeviceLabel string    `json:"deviceLabel"`
+	UserAgent   string    `json:"userAgent"`
+	ClientIp    string    `json:"clientIp"`
+	IsBlocked   bool      `json:"isBlocked"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	LastSeenAt  time.Time `json:"lastSeenAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func NewSessionResponse(session db.Session) SessionResponse {
+	return SessionResponse{
+		ID:          session.ID,
+		DeviceLabel: session.DeviceLabel,
+		UserAgent:   session.UserAgent,
+		ClientIp:    session.ClientIp,
+		IsBlocked:   session.IsBlocked,
+		ExpiresAt:   session.ExpiresAt,
+		LastSeenAt:  session.LastSeenAt,
+		CreatedAt:   session.CreatedAt,
+	}
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+type LoginEventResponse struct {
+	ID            int64      `json:"id"`
+	Success       bool       `json:"success"`
+	FailureReason string     `json:"failureReason,omitempty"`
+	UserAgent     string     `json:"userAgent"`
+	ClientIp      string     `json:"clientIp"`
+	NewDevice     bool       `json:"newDevice"`
+	SessionID     *uuid.UUID `json:"sessionId,omitempty"`
+	DeniedAt      *time.Time `json:"deniedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+func NewLoginEventResponse(event db.LoginEvent) LoginEventResponse {
+	response := LoginEventResponse{
+		ID:            event.ID,
+		Success:       event.Success,
+		FailureReason: event.FailureReason,
+		UserAgent:     event.UserAgent,
+		ClientIp:      event.ClientIp,
+		NewDevice:     event.NewDevice,
+		CreatedAt:     event.CreatedAt,
+	}
+
+	if event.SessionID.Valid {
+		sessionID := uuid.UUID(event.SessionID.Bytes)
+		response.SessionID = &sessionID
+	}
+
+	if event.DeniedAt.Valid {
+		response.DeniedAt = &event.DeniedAt.Time
+	}
+
+	return response
+}
+
+type ListLoginEventsResponse struct {
+	LoginEvents []LoginEventResponse `json:"loginEvents"`
+}
+
+type GetDenyLoginResponse struct {
+	LoginEvent LoginEventResponse `json:"loginEvent"`
+}
+
+type DenyLoginResponse struct {
+	// SessionRevoked is false when the session had already ended
+	SessionRevoked bool `json:"sessionRevoked"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+type EnrollTotpResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauthUri"`
+}
+
+type ConfirmTotpResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type KycReviewResponse struct {
+	ID         int64     `json:"id"`
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+	Reviewer   string    `json:"reviewer"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func NewKycReviewResponse(review db.KycReview) KycReviewResponse {
+	return KycReviewResponse{
+		ID:         review.ID,
+		FromStatus: review.FromStatus,
+		ToStatus:   review.ToStatus,
+		Reviewer:   review.Reviewer,
+		Note:       review.Note,
+		CreatedAt:  review.CreatedAt,
+	}
+}
+
+type KycDocumentResponse struct {
+	ID          int64      `json:"id"`
+	Kind        string     `json:"kind"`
+	FileName    string     `json:"fileName"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size"`
+	Status      string     `json:"status"`
+	ReviewID    *int64     `json:"reviewId,omitempty"`
+	Reviewer    string     `json:"reviewer,omitempty"`
+	Note        string     `json:"note,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func NewKycDocumentResponse(document db.KycDocument) KycDocumentResponse {
+	response := KycDocumentResponse{
+		ID:          document.ID,
+		Kind:        document.Kind,
+		FileName:    document.FileName,
+		ContentType: document.ContentType,
+		Size:        document.Size,
+		Status:      document.Status,
+		Reviewer:    document.Reviewer,
+		Note:        document.Note,
+		CreatedAt:   document.CreatedAt,
+	}
+
+	if document.ReviewID.Valid {
+		response.ReviewID = &document.ReviewID.Int64
+	}
+
+	if document.ReviewedAt.Valid {
+		response.ReviewedAt = &document.ReviewedAt.Time
+	}
+
+	return response
+}
+
+type ListKycDocumentsResponse struct {
+	Documents []KycDocumentResponse `json:"documents"`
+}
+
+func NewListKycDocumentsResponse(documents []db.KycDocument) ListKycDocumentsResponse {
+	response := ListKycDocumentsResponse{
+		Documents: make([]KycDocumentResponse, 0, len(documents)),
+	}
+
+	for _, document := range documents {
+		response.Documents = append(response.Documents, NewKycDocumentResponse(document))
+	}
+
+	return response
+}
@@ -1,25 +1,41 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"io"
+	"math"
+	"mime/multipart"
 	"net/http"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	dto "github.com/ChokeGuy/simple-bank/api/user/dto"
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
 	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/encryption"
 	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/ChokeGuy/simple-bank/pkg/loginhistory"
+	"github.com/ChokeGuy/simple-bank/pkg/mfa"
 	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/oidc"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/throttle"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
 	"github.com/ChokeGuy/simple-bank/pkg/token/paseto"
+	"github.com/ChokeGuy/simple-bank/pkg/totp"
 	sv "github.com/ChokeGuy/simple-bank/server/http"
 	"github.com/ChokeGuy/simple-bank/util"
 	pw "github.com/ChokeGuy/simple-bank/util/password"
 	"github.com/ChokeGuy/simple-bank/worker"
 	"github.com/google/uuid"
-	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
 
 	"github.com/gin-gonic/gin"
@@ -38,12 +54,39 @@ func (h *UserHandler) MapRoutes() {
 
 	router.POST("/user", h.createUser)
 	router.POST("/auth/login", h.loginUser)
+	router.POST("/auth/login/mfa", h.verifyLoginMfa)
+	router.POST("/auth/login/mfa/sms", h.sendLoginSmsCode)
+	router.GET("/auth/oidc/login", h.beginOidcLogin)
+	router.GET("/auth/oidc/callback", h.oidcCallback)
 	router.POST("/auth/refresh-token", h.refreshNewToken)
 	router.GET("/user", h.getUserByUserName)
 	router.GET("/user/verify-email", h.verifyUserEmail)
-
-	authRoutes := router.Group("/").Use(auth.AuthMiddleWare(h.TokenMaker))
-	authRoutes.PATCH("/user/update", h.updateUser)
+	router.POST("/auth/forgot-password", h.forgotPassword)
+	router.POST("/auth/reset-password", h.resetPassword)
+	router.GET("/auth/deny-login", h.getDenyLogin)
+	router.POST("/auth/deny-login", h.denyLogin)
+
+	authRoutes := router.Group("/").Use(auth.AuthMiddleWare(h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier))
+	authRoutes.POST("/auth/logout", auth.RequirePermission(rbac.SessionManage), h.logoutUser)
+	authRoutes.POST("/auth/step-up", auth.RequirePermission(rbac.SessionManage), h.stepUp)
+	authRoutes.GET("/user/profile", auth.RequirePermission(rbac.UserRead), h.getProfile)
+	authRoutes.PATCH("/user/update", auth.RequirePermission(rbac.UserUpdate), h.updateUser)
+	authRoutes.POST("/user/kyc/submit", auth.RequirePermission(rbac.UserUpdate), h.submitKyc)
+	authRoutes.POST("/user/kyc/documents", auth.RequirePermission(rbac.UserUpdate), h.uploadKycDocument)
+	authRoutes.GET("/user/kyc/documents", auth.RequirePermission(rbac.UserRead), h.listKycDocuments)
+	authRoutes.POST("/user/verify-email/resend", auth.RequirePermission(rbac.UserUpdate), h.resendVerifyEmail)
+	authRoutes.POST("/user/phone/send-code", auth.RequirePermission(rbac.UserUpdate), h.sendPhoneCode)
+	authRoutes.POST("/user/phone/verify", auth.RequirePermission(rbac.UserUpdate), h.verifyPhone)
+	authRoutes.POST("/user/change-password", auth.RequirePermission(rbac.UserUpdate), h.changePassword)
+	authRoutes.GET("/user/sessions", auth.RequirePermission(rbac.SessionManage), h.listSessions)
+	authRoutes.DELETE("/user/sessions", auth.RequirePermission(rbac.SessionManage), h.revokeAllSessions)
+	authRoutes.DELETE("/user/sessions/:id", auth.RequirePermission(rbac.SessionManage), h.revokeSession)
+	authRoutes.GET("/user/login-events", auth.RequirePermission(rbac.SessionManage), h.listLoginEvents)
+	authRoutes.POST("/user/mfa/totp", auth.RequirePermission(rbac.MfaManage), h.enrollTotp)
+	authRoutes.POST("/user/mfa/totp/confirm", auth.RequirePermission(rbac.MfaManage), h.confirmTotp)
+	authRoutes.POST("/user/mfa/totp/disable", auth.RequirePermission(rbac.MfaManage), h.disableTotp)
+	authRoutes.GET("/admin/users/:username/lockout", auth.RequirePermission(rbac.UserLockoutRead), h.getUserLockout)
+	authRoutes.DELETE("/admin/users/:username/lockout", auth.RequirePermission(rbac.UserLockoutReset), h.unlockUser)
 }
 
 // Create radom user
@@ -74,23 +117,24 @@ func RandomVerifyEmail(t *testing.T, user db.User) db.VerifyEmail {
 	}
 }
 
-func RandomToken(t *testing.T, userName string) string {
+func RandomToken(t *testing.T, userName string, sessionID uuid.UUID) string {
 	cfg, err := pkg.LoadConfig("../..")
 	require.NoError(t, err)
 
 	paseto, err := paseto.NewPasetoMaker(cfg.SymetricKey)
 	require.NoError(t, err)
 
-	token, _, err := paseto.CreateToken(userName, util.DepositorRole, time.Hour)
+	token, _, err := paseto.CreateToken(userName, util.DepositorRole, sessionID, time.Hour, token.WithTokenType(token.TokenTypeRefresh))
 	require.NoError(t, err)
 
 	return token
 }
 
 func RandomSession(t *testing.T, userName string) (db.Session, string) {
-	token := RandomToken(t, userName)
+	sessionID := uuid.New()
+	token := RandomToken(t, userName, sessionID)
 	return db.Session{
-		ID:           uuid.New(),
+		ID:           sessionID,
 		Username:     userName,
 		RefreshToken: token,
 		UserAgent:    util.RandomString(6),
@@ -100,6 +144,28 @@ func RandomSession(t *testing.T, userName string) (db.Session, string) {
 	}, token
 }
 
+// Create random TOTP enrolment, the secret is encrypted with the configured key
+func RandomUserTotp(t *testing.T, userName string, confirmed bool) (db.UserTotp, string) {
+	cfg, err := pkg.LoadConfig("../..")
+	require.NoError(t, err)
+
+	cipher, err := encryption.NewAESCipher(cfg.TotpEncryptionKey)
+	require.NoError(t, err)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	ciphertext, err := cipher.Encrypt([]byte(secret))
+	require.NoError(t, err)
+
+	return db.UserTotp{
+		Username:         userName,
+		SecretCiphertext: ciphertext,
+		ConfirmedAt:      pgtype.Timestamptz{Time: time.Now(), Valid: confirmed},
+		CreatedAt:        time.Now(),
+	}, secret
+}
+
 func (h *UserHandler) createUser(ctx *gin.Context) {
 	var req dto.CreateUserRequest
 
@@ -108,7 +174,12 @@ func (h *UserHandler) createUser(ctx *gin.Context) {
 		return
 	}
 
-	hashedPassword, err := pw.HashPassword(req.Password)
+	if err := h.PasswordPolicy.ValidateFor(req.Password, req.UserName, req.Email); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	hashedPassword, err := h.PasswordHasher.Hash(req.Password)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
@@ -122,19 +193,19 @@ func (h *UserHandler) createUser(ctx *gin.Context) {
 			Email:          req.Email,
 			HashedPassword: hashedPassword,
 		},
-		AfterCreate: func(user db.User) error {
+		AfterCreate: func(q db.Querier, user db.User) error {
 			//Send verification email to user
 			taskPayload := &worker.PayloadSendVerifyEmail{
 				UserName: user.Username,
 			}
 
-			opts := []asynq.Option{
-				asynq.MaxRetry(10),
-				asynq.ProcessIn(10 * time.Second),
-				asynq.Queue(worker.QueueCritical),
+			opts := worker.OutboxOptions{
+				MaxRetry:  10,
+				ProcessIn: 10 * time.Second,
+				Queue:     worker.QueueCritical,
 			}
 
-			return h.TaskDistributor.DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...)
+			return worker.EnqueueTaskSendVerifyEmail(ctx, q, taskPayload, opts)
 		},
 	}
 
@@ -150,15 +221,7 @@ func (h *UserHandler) createUser(ctx *gin.Context) {
 		return
 	}
 
-	response := dto.UserResponse{
-		UserName:          user.User.Username,
-		FullName:          user.User.FullName,
-		Email:             user.User.Email,
-		PasswordChangedAt: user.User.PasswordChangedAt.String(),
-		CreatedAt:         user.User.CreatedAt.String(),
-	}
-
-	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "User created successfully"))
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewUserResponse(user.User), "User created successfully"))
 }
 
 func (h *UserHandler) getUserByUserName(ctx *gin.Context) {
@@ -199,10 +262,22 @@ func (h *UserHandler) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: req.UserName}
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: ctx.ClientIP()}
+
+	// The attempt is counted before the password is checked so parallel guesses cannot all get past the lockout
+	attempt, err := throttle.Reserve(ctx, h.Limiter, userKey, ipKey)
+	if err != nil {
+		throttleError(ctx, err)
+		return
+	}
+	defer attempt.Release(ctx)
+
 	user, err := h.Store.GetUserByUserName(ctx, req.UserName)
 
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
+			attempt.Fail()
 			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
 			return
 		}
@@ -210,218 +285,1697 @@ func (h *UserHandler) loginUser(ctx *gin.Context) {
 		return
 	}
 
-	if err := pw.CheckPassword(req.Password, user.HashedPassword); err != nil {
+	if err := h.PasswordHasher.Check(req.Password, user.HashedPassword); err != nil {
+		loginhistory.RecordFailure(ctx, h.Store, user.Username, loginhistory.ReasonInvalidPassword, ctx.Request.UserAgent(), ctx.ClientIP())
+
+		attempt.Fail()
 		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Invalid password"))
 		return
 	}
 
-	if session, _ := h.Store.GetSessionByUserName(ctx, req.UserName); session.ID != uuid.Nil {
-		// Check if session is expired
-		if time.Now().After(session.ExpiresAt) {
-			// Delete expired session
-			err := h.Store.DeleteSession(ctx, session.ID)
-			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, "Failed to delete expired session"))
-				return
-			}
-		} else if session.IsBlocked {
-			// Session exists and is not blocked
-			ctx.JSON(http.StatusForbidden, res.ErrorResponse(http.StatusForbidden, "Session is blocked"))
-			return
-		} else {
-			// Session exists and is not blocked
-			ctx.JSON(http.StatusForbidden, res.ErrorResponse(http.StatusForbidden, "User session already exists"))
+	h.rehashPassword(ctx, user.Username, req.Password, user.HashedPassword)
+
+	mfaEnabled, err := h.MFA.Enabled(ctx, user.Username)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	// With 2FA on the password only buys a challenge, the session is created by verifyLoginMfa
+	if mfaEnabled {
+		challenge, err := h.MFA.CreateChallenge(ctx, user.Username, req.DeviceLabel)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 			return
 		}
+
+		response := dto.MfaChallengeResponse{
+			MfaRequired:    true,
+			ChallengeToken: challenge.Token,
+			ExpiresAt:      challenge.ExpiresAt,
+		}
+
+		ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Two-factor authentication required"))
+		return
+	}
+
+	// The address is not reset so a valid login of its own does not buy an attacker new guesses.
+	// With 2FA on the user is reset once the second factor passed, wrong codes count like passwords.
+	if err := h.Limiter.Reset(ctx, userKey); err != nil {
+		throttleError(ctx, err)
+		return
 	}
 
-	accessToken, aTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, h.Config.AccessTokenDuration)
+	response, err := h.createLoginSession(ctx, user, req.DeviceLabel, token.AuthStrengthPassword)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	refreshToken, rTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, h.Config.RefreshTokenDuration)
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "User logged in successfully"))
+}
 
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+// rehashPassword upgrades a hash made with another algorithm or weaker parameters. The login
+// does not fail when the new hash cannot be stored, the old one keeps verifying until next time.
+func (h *UserHandler) rehashPassword(ctx context.Context, username string, password string, hashedPassword string) {
+	if !h.PasswordHasher.NeedsRehash(hashedPassword) {
 		return
 	}
 
-	arg := db.CreateSessionParams{
-		ID:           uuid.MustParse(rTkPayload.ID),
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    ctx.Request.UserAgent(),
-		ClientIp:     ctx.ClientIP(),
-		IsBlocked:    false,
-		ExpiresAt:    rTkPayload.ExpiresAt.Time,
+	newHashedPassword, err := h.PasswordHasher.Hash(password)
+
+	if err == nil {
+		_, err = h.Store.UpdateUser(ctx, db.UpdateUserParams{
+			Username:       username,
+			HashedPassword: pgtype.Text{String: newHashedPassword, Valid: true},
+		})
+	}
+
+	if err != nil {
+		log.Warn().Err(err).Str("username", username).Msg("cannot upgrade password hash")
+	}
+}
+
+// passwordReused reports whether the password is the current one or one of the last replaced ones
+func (h *UserHandler) passwordReused(ctx context.Context, username string, hashedPassword string, password string) (bool, error) {
+	if h.PasswordPolicy.HistorySize <= 0 {
+		return false, nil
+	}
+
+	hashedPasswords := []string{hashedPassword}
+
+	if keep := h.PasswordPolicy.HistoryKeep(); keep > 0 {
+		history, err := h.Store.ListPasswordHistory(ctx, db.ListPasswordHistoryParams{Username: username, Limit: keep})
+
+		if err != nil {
+			return false, err
+		}
+
+		hashedPasswords = append(hashedPasswords, history...)
 	}
 
-	session, err := h.Store.CreateSession(ctx, arg)
+	return pw.Reused(h.PasswordHasher, password, hashedPasswords), nil
+}
+
+// createLoginSession creates the session of a successful login and the token pair bound to it,
+// strength is how the user proved their identity
+func (h *UserHandler) createLoginSession(ctx *gin.Context, user db.GetUserByUserNameRow, deviceLabel string, strength string) (dto.LoginUserResponse, error) {
+	sessionID := uuid.New()
+	authenticated := token.WithAuth(time.Now(), strength)
+
+	accessToken, aTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, sessionID, h.Config.AccessTokenDuration, authenticated)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
-		return
+		return dto.LoginUserResponse{}, err
 	}
 
+	refreshToken, rTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, sessionID, h.Config.RefreshTokenDuration, authenticated, token.WithTokenType(token.TokenTypeRefresh))
+
+	if err != nil {
+		return dto.LoginUserResponse{}, err
+	}
+
+	arg := db.CreateSessionTxParams{
+		CreateSessionParams: db.CreateSessionParams{
+			ID:           sessionID,
+			Username:     user.Username,
+			RefreshToken: token.HashToken(refreshToken),
+			UserAgent:    ctx.Request.UserAgent(),
+			ClientIp:     ctx.ClientIP(),
+			IsBlocked:    false,
+			ExpiresAt:    rTkPayload.ExpiresAt.Time,
+			DeviceLabel:  deviceLabel,
+		},
+		MaxSessions: h.Config.MaxSessionsPerUser,
+		AfterCreate: func(q db.Querier, session db.Session) error {
+			return loginhistory.RecordSuccess(ctx, q, session)
+		},
+	}
+
+	result, err := h.Store.CreateSessionTx(ctx, arg)
+
+	if err != nil {
+		return dto.LoginUserResponse{}, err
+	}
+
+	h.SessionChecker.Invalidate(result.Evicted...)
+
 	response := dto.LoginUserResponse{
-		SessionID:             session.ID,
+		SessionID:             result.Session.ID,
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  aTkPayload.ExpiresAt.Time,
 		RefreshToken:          refreshToken,
 		RefreshTokenExpiresAt: rTkPayload.ExpiresAt.Time,
-		User: dto.UserResponse{
-			UserName:          user.Username,
-			FullName:          user.FullName,
-			Email:             user.Email,
-			PasswordChangedAt: user.PasswordChangedAt.String(),
-			CreatedAt:         user.CreatedAt.String(),
-		},
+		User:                  dto.NewUserRowResponse(user),
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "User logged in successfully"))
+	return response, nil
 }
 
-func (h *UserHandler) updateUser(ctx *gin.Context) {
-	var req dto.UpdateUserRequest
+func (h *UserHandler) verifyLoginMfa(ctx *gin.Context) {
+	var req dto.VerifyLoginMfaRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
 		return
 	}
 
-	user, err := h.Store.GetUserByUserName(ctx, req.UserName)
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: ctx.ClientIP()}
 
+	ipAttempt, err := throttle.Reserve(ctx, h.Limiter, ipKey)
 	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
-			return
+		throttleError(ctx, err)
+		return
+	}
+	defer ipAttempt.Release(ctx)
+
+	challenge, err := h.MFA.PendingChallenge(ctx, req.ChallengeToken)
+
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidChallenge) {
+			ipAttempt.Fail()
 		}
-		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+
+		status := mfaErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
 		return
 	}
 
-	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	// Codes are guessed against the same budget as passwords, a new challenge does not renew it
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: challenge.Username}
 
-	if authPayload.UserName != user.Username {
-		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Unauthorized user"))
+	userAttempt, err := throttle.Reserve(ctx, h.Limiter, userKey)
+	if err != nil {
+		throttleError(ctx, err)
 		return
 	}
+	defer userAttempt.Release(ctx)
 
-	arg := db.UpdateUserParams{
-		Username: req.UserName,
-		FullName: pgtype.Text{String: req.FullName, Valid: req.FullName != ""},
-		Email:    pgtype.Text{String: req.Email, Valid: req.Email != ""},
+	challenge, err = h.MFA.CompleteChallenge(ctx, challenge, req.Code)
+
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			loginhistory.RecordFailure(ctx, h.Store, challenge.Username, loginhistory.ReasonInvalidMfaCode, ctx.Request.UserAgent(), ctx.ClientIP())
+
+			userAttempt.Fail()
+			ipAttempt.Fail()
+		}
+
+		status := mfaErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
+	}
+
+	if err := h.Limiter.Reset(ctx, userKey); err != nil {
+		throttleError(ctx, err)
+		return
 	}
 
-	updateUser, err := h.Store.UpdateUser(ctx, arg)
+	user, err := h.Store.GetUserByUserName(ctx, challenge.Username)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	response := dto.UserResponse{
-		UserName:          updateUser.Username,
-		FullName:          updateUser.FullName,
-		Email:             updateUser.Email,
-		PasswordChangedAt: updateUser.PasswordChangedAt.String(),
-		CreatedAt:         updateUser.CreatedAt.String(),
+	response, err := h.createLoginSession(ctx, user, challenge.DeviceLabel, token.AuthStrengthMfa)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "User updated successfully"))
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "User logged in successfully"))
 }
 
-func (h *UserHandler) verifyUserEmail(ctx *gin.Context) {
-	var req dto.VerifyUserEmailRequest
+// beginOidcLogin returns the URL of the corporate identity provider the browser is sent to, the
+// login cookie set here has to come back with the callback
+func (h *UserHandler) beginOidcLogin(ctx *gin.Context) {
+	if h.OIDC == nil {
+		ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "SSO login is not configured"))
+		return
+	}
+
+	var req dto.BeginOidcLoginRequest
 
 	if err := ctx.ShouldBindQuery(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
 		return
 	}
 
-	arg := db.VerifyUserEmailTxParams{
-		EmailId:    req.EmailId,
-		SecretCode: req.SecretCode,
+	authRequest, err := h.OIDC.Begin(ctx, req.DeviceLabel)
+
+	if err != nil {
+		status := oidcErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
 	}
 
-	verifyEmail, err := h.Store.VerifyUserEmailTx(ctx, arg)
+	http.SetCookie(ctx.Writer, h.OIDC.Cookie(authRequest))
+
+	response := dto.BeginOidcLoginResponse{
+		AuthorizationUrl: authRequest.URL,
+		State:            authRequest.State,
+		ExpiresAt:        authRequest.ExpiresAt,
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "SSO login started"))
+}
+
+// oidcCallback completes a SSO login, the user is created on their first login. With 2FA on the
+// login only buys a challenge like a password login does.
+func (h *UserHandler) oidcCallback(ctx *gin.Context) {
+	if h.OIDC == nil {
+		ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "SSO login is not configured"))
+		return
+	}
+
+	var req dto.OidcCallbackRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if req.Error != "" {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "SSO login failed: "+req.Error))
+		return
+	}
 
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: ctx.ClientIP()}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, ipKey)
 	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Email verification not found"))
-			return
+		throttleError(ctx, err)
+		return
+	}
+	defer attempt.Release(ctx)
+
+	// A missing cookie is refused by Complete
+	browser, _ := ctx.Cookie(oidc.CookieName)
+
+	identity, err := h.OIDC.Complete(ctx, req.State, browser, req.Code, func(q db.Querier, user db.User) error {
+		// Addresses the provider did not verify are checked like those of a sign up
+		if user.IsEmailVerified {
+			return nil
+		}
+		return enqueueVerifyEmail(ctx, q, user.Username)
+	})
+
+	http.SetCookie(ctx.Writer, h.OIDC.ExpiredCookie())
+
+	if err != nil {
+		status := oidcErrorStatus(err)
+
+		if status == http.StatusUnauthorized {
+			attempt.Fail()
 		}
+
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
+	}
+
+	mfaEnabled, err := h.MFA.Enabled(ctx, identity.User.Username)
+
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	response := dto.VerifyUserEmailResponse{
-		IsVerified: verifyEmail.User.IsEmailVerified,
+	if mfaEnabled {
+		challenge, err := h.MFA.CreateChallenge(ctx, identity.User.Username, identity.DeviceLabel)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+			return
+		}
+
+		response := dto.MfaChallengeResponse{
+			MfaRequired:    true,
+			ChallengeToken: challenge.Token,
+			ExpiresAt:      challenge.ExpiresAt,
+		}
+
+		ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Two-factor authentication required"))
+		return
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Email verified successfully"))
+	response, err := h.createLoginSession(ctx, identity.User, identity.DeviceLabel, token.AuthStrengthSso)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "User logged in successfully"))
 }
 
-func (h *UserHandler) refreshNewToken(ctx *gin.Context) {
-	var req dto.RefreshTokenRequest
+// stepUp checks the identity of the caller again, with a second factor when 2FA is on and the password
+// otherwise, and issues a short-lived access token of the same session carrying a fresh auth_time
+func (h *UserHandler) stepUp(ctx *gin.Context) {
+	var req dto.StepUpRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
 		return
 	}
 
-	claims, err := h.TokenMaker.VerifyToken(req.RefreshToken)
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	// API keys have no session and no password to prove
+	if authPayload.SessionID == uuid.Nil {
+		ctx.JSON(http.StatusForbidden, res.ErrorResponse(http.StatusForbidden, "Only users can step up"))
+		return
+	}
+
+	// Failures count against the login lockout so the endpoint is no way around it
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: authPayload.UserName}
 
+	attempt, err := throttle.Reserve(ctx, h.Limiter, userKey)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Invalid refresh token"))
+		throttleError(ctx, err)
 		return
 	}
+	defer attempt.Release(ctx)
 
-	session, err := h.Store.GetSessionById(ctx, uuid.MustParse(claims.ID))
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
 
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Session not found"))
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
 			return
 		}
-		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Invalid session"))
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	if session.IsBlocked {
-		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Session is blocked"))
+	mfaEnabled, err := h.MFA.Enabled(ctx, user.Username)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	if session.Username != claims.UserName {
-		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Incorrect session user"))
+	strength := token.AuthStrengthPassword
+	if mfaEnabled {
+		strength = token.AuthStrengthMfa
+	}
+
+	// The token of a weaker step-up would still be refused by every sensitive operation
+	if !h.StepUpPolicy.Satisfies(strength) {
+		ctx.JSON(http.StatusForbidden, res.ErrorResponse(http.StatusForbidden, "Two-factor authentication must be enabled to step up"))
 		return
 	}
 
-	if session.RefreshToken != req.RefreshToken {
-		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Mismatched session token"))
+	// With 2FA on the password alone would be weaker than the login, so the second factor is required
+	if mfaEnabled {
+		if req.Code == "" {
+			ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Two-factor code is required"))
+			return
+		}
+
+		err = h.MFA.Verify(ctx, user.Username, req.Code)
+	} else {
+		// Users created by an SSO login have a random password until they reset one
+		if req.Password == "" {
+			ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Password is required"))
+			return
+		}
+
+		err = h.PasswordHasher.Check(req.Password, user.HashedPassword)
+	}
+
+	if err != nil {
+		if !errors.Is(err, mfa.ErrInvalidCode) && !errors.Is(err, pw.ErrMismatchedHashAndPassword) {
+			ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+			return
+		}
+
+		attempt.Fail()
+
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Invalid credentials"))
 		return
 	}
 
-	if time.Now().After(session.ExpiresAt) {
-		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Session expired"))
+	if err := h.Limiter.Reset(ctx, userKey); err != nil {
+		throttleError(ctx, err)
 		return
 	}
 
-	accessToken, payload, err := h.TokenMaker.CreateToken(claims.UserName, claims.Role, h.Config.AccessTokenDuration)
+	accessToken, aTkPayload, err := h.TokenMaker.CreateToken(
+		user.Username, user.Role, authPayload.SessionID, h.StepUpPolicy.TokenDuration, token.WithAuth(time.Now(), strength),
+	)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
 		return
 	}
 
-	response := dto.RefreshTokenResponse{
+	response := dto.StepUpResponse{
 		AccessToken:          accessToken,
-		AccessTokenExpiresAt: payload.ExpiresAt.Time,
+		AccessTokenExpiresAt: aTkPayload.ExpiresAt.Time,
+		AuthTime:             aTkPayload.AuthTime.Time,
+		AuthStrength:         aTkPayload.AuthStrength,
 	}
 
-	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Token refreshed successfully"))
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Step-up authentication succeeded"))
+}
+
+func (h *UserHandler) updateUser(ctx *gin.Context) {
+	var req dto.UpdateUserRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, req.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	if !rbac.CanAccess(authPayload, user.Username, rbac.UserUpdateAny) {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Unauthorized user"))
+		return
+	}
+
+	// Whoever controls the email can reset the password, so changing it needs a fresh proof of identity
+	if req.Email != "" && req.Email != user.Email {
+		if err := h.StepUpPolicy.Check(authPayload); err != nil {
+			auth.AbortStepUpRequired(ctx, h.StepUpPolicy)
+			return
+		}
+	}
+
+	// The binding already checked the date
+	dateOfBirth, _ := time.Parse(time.DateOnly, req.DateOfBirth)
+
+	arg := db.UpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			Username:    req.UserName,
+			FullName:    pgtype.Text{String: req.FullName, Valid: req.FullName != ""},
+			Email:       pgtype.Text{String: req.Email, Valid: req.Email != ""},
+			DateOfBirth: pgtype.Date{Time: dateOfBirth, Valid: req.DateOfBirth != ""},
+			Phone:       pgtype.Text{String: req.Phone, Valid: req.Phone != ""},
+			Address:     pgtype.Text{String: req.Address, Valid: req.Address != ""},
+			Nationality: pgtype.Text{String: req.Nationality, Valid: req.Nationality != ""},
+		},
+		AfterEmailChange: func(q db.Querier, user db.User) error {
+			return enqueueVerifyEmail(ctx, q, user.Username)
+		},
+	}
+
+	kyc.ResetOnProfileChange(user, &arg)
+
+	// Bankers can edit any profile, including the email the password is reset with
+	if authPayload.UserName != user.Username {
+		entry := audit.Entry(authPayload.UserName, audit.UpdateUser, user.Username, ctx.ClientIP(), audit.ProfileUpdate(arg.UpdateUserParams))
+		arg.AuditLog = &entry
+	}
+
+	result, err := h.Store.UpdateUserTx(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	message := "User updated successfully"
+	if result.EmailChanged {
+		message = "User updated successfully, a verification email was sent to the new address"
+	}
+
+	if arg.KycReview != nil {
+		message += ", the profile has to be submitted for KYC review again"
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewUserResponse(result.User), message))
+}
+
+func (h *UserHandler) getProfile(ctx *gin.Context) {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewUserRowResponse(user), "Profile retrieved successfully"))
+}
+
+func (h *UserHandler) submitKyc(ctx *gin.Context) {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	arg, err := kyc.NewSubmission(user)
+
+	if err != nil {
+		if errors.Is(err, kyc.ErrIncompleteProfile) {
+			ctx.JSON(http.StatusUnprocessableEntity, res.ErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+			return
+		}
+		ctx.JSON(http.StatusConflict, res.ErrorResponse(http.StatusConflict, "KYC is already pending or verified"))
+		return
+	}
+
+	result, err := h.Store.UpdateKycStatusTx(ctx, arg)
+
+	if err != nil {
+		// A banker or another request changed the status in the meantime
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusConflict, res.ErrorResponse(http.StatusConflict, "KYC status changed, please try again"))
+			return
+		}
+		if errors.Is(err, db.ErrNoKycDocuments) {
+			ctx.JSON(http.StatusUnprocessableEntity, res.ErrorResponse(http.StatusUnprocessableEntity, "At least one document has to be uploaded before submitting for KYC review"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewUserResponse(result.User), "Profile submitted for KYC review"))
+}
+
+// uploadKycDocument stores an identity document sent as a multipart form for the next KYC submission
+func (h *UserHandler) uploadKycDocument(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, h.KycDocuments.MaxSize()+multipartOverhead)
+
+	var req dto.UploadKycDocumentRequest
+
+	if err := ctx.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, res.ErrorResponse(http.StatusRequestEntityTooLarge, kyc.ErrDocumentTooLarge.Error()))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "file is required"))
+		return
+	}
+
+	data, err := readFormFile(header, h.KycDocuments.MaxSize())
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	document, err := h.KycDocuments.Upload(ctx, h.Store, user, kyc.Upload{
+		Kind:        req.Kind,
+		FileName:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Data:        data,
+	})
+
+	if err != nil {
+		status := kycDocumentErrorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			message = "Failed to store document"
+			log.Error().Err(err).Str("username", user.Username).Msg("cannot store KYC document")
+		}
+		ctx.JSON(status, res.ErrorResponse(status, message))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewKycDocumentResponse(document), "Document uploaded successfully"))
+}
+
+func (h *UserHandler) listKycDocuments(ctx *gin.Context) {
+	var req dto.ListKycDocumentsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	documents, err := h.Store.ListKycDocuments(ctx, db.ListKycDocumentsParams{
+		Username: authPayload.UserName,
+		Limit:    req.Size,
+		Offset:   (req.Page - 1) * req.Size,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewListKycDocumentsResponse(documents), "Documents retrieved successfully"))
+}
+
+func (h *UserHandler) resendVerifyEmail(ctx *gin.Context) {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	resendKey := throttle.Key{Scope: throttle.ResendVerifyEmail, ID: authPayload.UserName}
+
+	if err := h.Limiter.Check(ctx, resendKey); err != nil {
+		throttleError(ctx, err)
+		return
+	}
+
+	arg := db.ResendVerifyEmailTxParams{
+		Username: authPayload.UserName,
+		AfterInvalidate: func(q db.Querier, user db.GetUserByUserNameRow) error {
+			return enqueueVerifyEmail(ctx, q, user.Username)
+		},
+	}
+
+	if _, err := h.Store.ResendVerifyEmailTx(ctx, arg); err != nil {
+		if errors.Is(err, db.ErrEmailAlreadyVerified) {
+			ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Email is already verified"))
+			return
+		}
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	// Every resend counts so the next one has to wait longer
+	if err := h.Limiter.Fail(ctx, resendKey); err != nil {
+		throttleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, "Verification email sent"))
+}
+
+// enqueueVerifyEmail writes the task that sends a new verification code into the outbox of q
+func enqueueVerifyEmail(ctx *gin.Context, q db.Querier, username string) error {
+	taskPayload := &worker.PayloadSendVerifyEmail{
+		UserName: username,
+	}
+
+	opts := worker.OutboxOptions{
+		MaxRetry: 10,
+		Queue:    worker.QueueCritical,
+	}
+
+	return worker.EnqueueTaskSendVerifyEmail(ctx, q, taskPayload, opts)
+}
+
+// sendPhoneCode texts a verification code to the phone number of the profile
+func (h *UserHandler) sendPhoneCode(ctx *gin.Context) {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	sendKey := throttle.Key{Scope: throttle.SendPhoneCode, ID: authPayload.UserName}
+
+	if err := h.Limiter.Check(ctx, sendKey); err != nil {
+		throttleError(ctx, err)
+		return
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if err := phone.CanReceive(user, phone.PurposeVerify); err != nil {
+		status := phoneErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
+	}
+
+	if err := h.enqueuePhoneCode(ctx, user, phone.PurposeVerify, sendKey); err != nil {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, "Verification code sent"))
+}
+
+func (h *UserHandler) verifyPhone(ctx *gin.Context) {
+	var req dto.VerifyPhoneRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	verifyKey := throttle.Key{Scope: throttle.VerifyPhone, ID: authPayload.UserName}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, verifyKey)
+	if err != nil {
+		throttleError(ctx, err)
+		return
+	}
+	defer attempt.Release(ctx)
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	verified, err := h.PhoneCodes.VerifyPhone(ctx, user, req.Code)
+
+	if err != nil {
+		if errors.Is(err, phone.ErrInvalidCode) {
+			attempt.Fail()
+		}
+
+		status := phoneErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
+	}
+
+	if err := h.Limiter.Reset(ctx, verifyKey); err != nil {
+		throttleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewUserResponse(verified), "Phone number verified successfully"))
+}
+
+// sendLoginSmsCode texts a login code for a pending challenge, the code is then sent to verifyLoginMfa
+// like a TOTP code. Only users with a verified phone number can receive one.
+func (h *UserHandler) sendLoginSmsCode(ctx *gin.Context) {
+	var req dto.SendLoginSmsCodeRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: ctx.ClientIP()}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, ipKey)
+	if err != nil {
+		throttleError(ctx, err)
+		return
+	}
+	defer attempt.Release(ctx)
+
+	challenge, err := h.MFA.PendingChallenge(ctx, req.ChallengeToken)
+
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidChallenge) {
+			attempt.Fail()
+		}
+
+		status := mfaErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
+	}
+
+	sendKey := throttle.Key{Scope: throttle.SendPhoneCode, ID: challenge.Username}
+
+	if err := h.Limiter.Check(ctx, sendKey); err != nil {
+		throttleError(ctx, err)
+		return
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, challenge.Username)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if err := phone.CanReceive(user, phone.PurposeLogin); err != nil {
+		status := phoneErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
+	}
+
+	if err := h.enqueuePhoneCode(ctx, user, phone.PurposeLogin, sendKey); err != nil {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, "Login code sent"))
+}
+
+// enqueuePhoneCode writes the task that texts a new code to user and counts it against sendKey.
+// The response is already written when an error is returned.
+func (h *UserHandler) enqueuePhoneCode(ctx *gin.Context, user db.GetUserByUserNameRow, purpose string, sendKey throttle.Key) error {
+	taskPayload := &worker.PayloadSendPhoneCode{
+		UserName: user.Username,
+		Phone:    user.Phone,
+		Purpose:  purpose,
+	}
+
+	opts := worker.OutboxOptions{
+		MaxRetry: 5,
+		Queue:    worker.QueueCritical,
+	}
+
+	// Nothing else changes with the request, so the outbox message is written on its own
+	if err := worker.EnqueueTaskSendPhoneCode(ctx, h.Store, taskPayload, opts); err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return err
+	}
+
+	// Every code counts so the next one has to wait longer
+	if err := h.Limiter.Fail(ctx, sendKey); err != nil {
+		throttleError(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (h *UserHandler) changePassword(ctx *gin.Context) {
+	var req dto.ChangePasswordRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if err := h.PasswordHasher.Check(req.CurrentPassword, user.HashedPassword); err != nil {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Incorrect current password"))
+		return
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "New password must differ from the current password"))
+		return
+	}
+
+	if err := h.PasswordPolicy.ValidateFor(req.NewPassword, user.Username, user.Email); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	reused, err := h.passwordReused(ctx, user.Username, user.HashedPassword, req.NewPassword)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if reused {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "New password must differ from your recent passwords"))
+		return
+	}
+
+	hashedPassword, err := h.PasswordHasher.Hash(req.NewPassword)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	arg := db.ChangePasswordTxParams{
+		Username:          user.Username,
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: time.Now(),
+		ClientIp:          ctx.ClientIP(),
+		HistoryKeep:       h.PasswordPolicy.HistoryKeep(),
+	}
+
+	var tokens *dto.RefreshTokenResponse
+
+	if req.KeepCurrentSession {
+		session, err := h.Store.GetSessionById(ctx, authPayload.SessionID)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+			return
+		}
+
+		// Created after PasswordChangedAt, so the new tokens outlive the change
+		refreshToken, rTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, session.ID, time.Until(session.ExpiresAt), token.WithAuthOf(authPayload), token.WithTokenType(token.TokenTypeRefresh))
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+			return
+		}
+
+		accessToken, aTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, session.ID, h.Config.AccessTokenDuration, token.WithAuthOf(authPayload))
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+			return
+		}
+
+		arg.KeepSessionID = session.ID
+		arg.KeepSessionRefreshToken = token.HashToken(refreshToken)
+
+		tokens = &dto.RefreshTokenResponse{
+			AccessToken:           accessToken,
+			AccessTokenExpiresAt:  aTkPayload.ExpiresAt.Time,
+			RefreshToken:          refreshToken,
+			RefreshTokenExpiresAt: rTkPayload.ExpiresAt.Time,
+		}
+	}
+
+	result, err := h.Store.ChangePasswordTx(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	// The kept session is dropped from the cache too, its old tokens must be checked against the new password_changed_at
+	h.SessionChecker.Invalidate(append(result.RevokedSessions, authPayload.SessionID)...)
+
+	response := dto.ChangePasswordResponse{
+		Revoked: int64(len(result.RevokedSessions)),
+		Tokens:  tokens,
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Password changed successfully"))
+}
+
+func (h *UserHandler) verifyUserEmail(ctx *gin.Context) {
+	var req dto.VerifyUserEmailRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	emailKey := throttle.Key{Scope: throttle.VerifyEmail, ID: strconv.FormatInt(req.EmailId, 10)}
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: ctx.ClientIP()}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, emailKey, ipKey)
+	if err != nil {
+		throttleError(ctx, err)
+		return
+	}
+	defer attempt.Release(ctx)
+
+	arg := db.VerifyUserEmailTxParams{
+		EmailId:    req.EmailId,
+		SecretCode: req.SecretCode,
+		AfterVerify: func(q db.Querier, user db.User) error {
+			return worker.EnqueueUserVerifiedEvent(ctx, q, user)
+		},
+	}
+
+	verifyEmail, err := h.Store.VerifyUserEmailTx(ctx, arg)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			attempt.Fail()
+
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Email verification not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if err := h.Limiter.Reset(ctx, emailKey); err != nil {
+		throttleError(ctx, err)
+		return
+	}
+
+	response := dto.VerifyUserEmailResponse{
+		IsVerified: verifyEmail.User.IsEmailVerified,
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Email verified successfully"))
+}
+
+func (h *UserHandler) forgotPassword(ctx *gin.Context) {
+	var req dto.ForgotPasswordRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	// The response is the same whether or not the email belongs to a user
+	const message = "If the email belongs to an account, a password reset link has been sent"
+
+	// Every request counts for the address and the client so nobody can flood a mailbox
+	emailKey := throttle.Key{Scope: throttle.ForgotPassword, ID: strings.ToLower(req.Email)}
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: ctx.ClientIP()}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, emailKey, ipKey)
+	if err != nil {
+		throttleError(ctx, err)
+		return
+	}
+	defer attempt.Release(ctx)
+
+	// The user is looked up by the worker, so the request does the same work whether or not the email is known
+	taskPayload := &worker.PayloadSendResetPasswordEmail{
+		Email:     req.Email,
+		RequestID: uuid.New(),
+	}
+
+	opts := worker.OutboxOptions{
+		MaxRetry: 10,
+		Queue:    worker.QueueCritical,
+	}
+
+	// Nothing else changes with the request, so the outbox message is written on its own
+	if err := worker.EnqueueTaskSendResetPasswordEmail(ctx, h.Store, taskPayload, opts); err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	attempt.Fail()
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, message))
+}
+
+func (h *UserHandler) resetPassword(ctx *gin.Context) {
+	var req dto.ResetPasswordRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	// The token is only used by the transaction, it is looked up first to check the password against its user
+	reset, err := h.Store.GetPasswordResetByTokenHash(ctx, token.HashToken(req.Token))
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Invalid or expired password reset token"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, reset.Username)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if err := h.PasswordPolicy.ValidateFor(req.Password, user.Username, user.Email); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	reused, err := h.passwordReused(ctx, user.Username, user.HashedPassword, req.Password)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if reused {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "New password must differ from your recent passwords"))
+		return
+	}
+
+	hashedPassword, err := h.PasswordHasher.Hash(req.Password)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	result, err := h.Store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash:      token.HashToken(req.Token),
+		HashedPassword: hashedPassword,
+		HistoryKeep:    h.PasswordPolicy.HistoryKeep(),
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "Invalid or expired password reset token"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	h.SessionChecker.Invalidate(result.RevokedSessions...)
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, "Password reset successfully"))
+}
+
+func (h *UserHandler) refreshNewToken(ctx *gin.Context) {
+	var req dto.RefreshTokenRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	claims, err := h.TokenMaker.VerifyToken(req.RefreshToken)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Invalid refresh token"))
+		return
+	}
+
+	// Checked before the rotation, an access token would be taken for a reused refresh token and revoke the session
+	if err := claims.CheckType(token.TokenTypeRefresh); err != nil {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Invalid refresh token"))
+		return
+	}
+
+	session, err := h.Store.GetSessionById(ctx, claims.SessionID)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Session not found"))
+			return
+		}
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Invalid session"))
+		return
+	}
+
+	if session.RevokedAt.Valid {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Session is revoked"))
+		return
+	}
+
+	if session.IsBlocked {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Session is blocked"))
+		return
+	}
+
+	if session.Username != claims.UserName {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Incorrect session user"))
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Session expired"))
+		return
+	}
+
+	// Checked before the rotation so a token from before a password change is not taken for a reused one
+	if claims.IssuedBefore(session.PasswordChangedAt) {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Refresh token was issued before the last password change"))
+		return
+	}
+
+	// The rotated refresh token keeps the expiry of the session so a session cannot be extended forever,
+	// both tokens keep the auth_time of the login so a refresh never passes for a step-up
+	refreshToken, rTkPayload, err := h.TokenMaker.CreateToken(claims.UserName, claims.Role, session.ID, time.Until(session.ExpiresAt), token.WithAuthOf(claims), token.WithTokenType(token.TokenTypeRefresh))
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	arg := db.RotateRefreshTokenTxParams{
+		SessionID:       session.ID,
+		RefreshToken:    token.HashToken(req.RefreshToken),
+		NewRefreshToken: token.HashToken(refreshToken),
+		ClientIp:        ctx.ClientIP(),
+	}
+
+	result, err := h.Store.RotateRefreshTokenTx(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if result.Reused {
+		h.SessionChecker.Invalidate(session.ID)
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Refresh token reuse detected, session is blocked"))
+		return
+	}
+
+	accessToken, aTkPayload, err := h.TokenMaker.CreateToken(claims.UserName, claims.Role, session.ID, h.Config.AccessTokenDuration, token.WithAuthOf(claims))
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := dto.RefreshTokenResponse{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  aTkPayload.ExpiresAt.Time,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: rTkPayload.ExpiresAt.Time,
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Token refreshed successfully"))
+}
+
+func (h *UserHandler) logoutUser(ctx *gin.Context) {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	if err := h.Store.RevokeSession(ctx, authPayload.SessionID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	h.SessionChecker.Invalidate(authPayload.SessionID)
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, "User logged out successfully"))
+}
+
+func (h *UserHandler) listSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	sessions, err := h.Store.ListSessionsByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := dto.ListSessionsResponse{
+		Sessions: make([]dto.SessionResponse, 0, len(sessions)),
+	}
+
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, dto.NewSessionResponse(session))
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Sessions retrieved successfully"))
+}
+
+func (h *UserHandler) revokeSession(ctx *gin.Context) {
+	var req dto.RevokeSessionRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	session, err := h.Store.GetSessionById(ctx, uuid.MustParse(req.ID))
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Session not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	if !rbac.CanAccess(authPayload, session.Username) {
+		ctx.JSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "Session does not belong to the authenticated user"))
+		return
+	}
+
+	// The row is kept so the history of the session stays, an already revoked session is left as it is
+	if err := h.Store.RevokeSession(ctx, session.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	h.SessionChecker.Invalidate(session.ID)
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, "Session revoked successfully"))
+}
+
+func (h *UserHandler) listLoginEvents(ctx *gin.Context) {
+	var req dto.ListLoginEventsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	arg := db.ListLoginEventsParams{
+		Username: authPayload.UserName,
+		Limit:    req.Size,
+		Offset:   (req.Page - 1) * req.Size,
+	}
+
+	events, err := h.Store.ListLoginEvents(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := dto.ListLoginEventsResponse{
+		LoginEvents: make([]dto.LoginEventResponse, 0, len(events)),
+	}
+
+	for _, event := range events {
+		response.LoginEvents = append(response.LoginEvents, dto.NewLoginEventResponse(event))
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Login events retrieved successfully"))
+}
+
+// getDenyLogin shows the login of a "this wasn't me" link without changing it, so link prefetchers
+// cannot deny a login. The page of the link posts the token to denyLogin once the user confirms.
+func (h *UserHandler) getDenyLogin(ctx *gin.Context) {
+	var req dto.GetDenyLoginRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	event, err := h.Store.GetLoginEventByDenyToken(ctx, pgtype.Text{String: token.HashToken(req.Token), Valid: true})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Link is invalid or was already used"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := dto.GetDenyLoginResponse{
+		LoginEvent: dto.NewLoginEventResponse(event),
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Confirm to sign this login out"))
+}
+
+// denyLogin is the confirmation of a "this wasn't me" link of a new device alert, it blocks the session of the login
+func (h *UserHandler) denyLogin(ctx *gin.Context) {
+	var req dto.DenyLoginRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	result, err := h.Store.DenyLoginTx(ctx, token.HashToken(req.Token))
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Link is invalid or was already used"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if result.Blocked {
+		h.SessionChecker.Invalidate(result.LoginEvent.SessionID.Bytes)
+	}
+
+	response := dto.DenyLoginResponse{
+		SessionRevoked: result.Blocked,
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Login denied, please change your password"))
+}
+
+func (h *UserHandler) revokeAllSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	revoked, err := h.Store.RevokeSessionsByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	h.SessionChecker.Invalidate(revoked...)
+
+	response := dto.RevokeSessionsResponse{
+		Revoked: int64(len(revoked)),
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Sessions revoked successfully"))
+}
+
+func (h *UserHandler) enrollTotp(ctx *gin.Context) {
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	enrolment, err := h.MFA.Enroll(ctx, authPayload.UserName)
+
+	if err != nil {
+		status := mfaErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
+	}
+
+	response := dto.EnrollTotpResponse{
+		Secret:     enrolment.Secret,
+		OtpauthUri: enrolment.URI,
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Two-factor authentication enrolment started"))
+}
+
+func (h *UserHandler) confirmTotp(ctx *gin.Context) {
+	var req dto.ConfirmTotpRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	recoveryCodes, err := h.MFA.Confirm(ctx, authPayload.UserName, req.Code)
+
+	if err != nil {
+		status := mfaErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
+	}
+
+	response := dto.ConfirmTotpResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "Two-factor authentication enabled"))
+}
+
+func (h *UserHandler) disableTotp(ctx *gin.Context) {
+	var req dto.DisableTotpRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	if err := h.MFA.Disable(ctx, authPayload.UserName, req.Code); err != nil {
+		status := mfaErrorStatus(err)
+		ctx.JSON(status, res.ErrorResponse(status, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, "Two-factor authentication disabled"))
+}
+
+func (h *UserHandler) getUserLockout(ctx *gin.Context) {
+	var req dto.UserLockoutRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if _, err := h.Store.GetUserByUserName(ctx, req.UserName); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	lockout, err := h.Limiter.Status(ctx, throttle.Key{Scope: throttle.LoginUser, ID: req.UserName})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	response := dto.UserLockoutResponse{
+		UserName: req.UserName,
+		Failures: lockout.Failures,
+		Locked:   lockout.Locked,
+	}
+
+	if lockout.RetryAfter > 0 {
+		lockedUntil := time.Now().Add(lockout.RetryAfter)
+		response.LockedUntil = &lockedUntil
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(response, "User lockout retrieved successfully"))
+}
+
+func (h *UserHandler) unlockUser(ctx *gin.Context) {
+	var req dto.UserLockoutRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if _, err := h.Store.GetUserByUserName(ctx, req.UserName); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "User not found"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if err := h.Limiter.Reset(ctx, throttle.Key{Scope: throttle.LoginUser, ID: req.UserName}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, "User unlocked successfully"))
+}
+
+// throttleError responds to an error of the limiter, a blocked client gets 429 and a Retry-After header
+func throttleError(ctx *gin.Context, err error) {
+	var blocked *throttle.BlockedError
+
+	if errors.As(err, &blocked) {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+		ctx.JSON(http.StatusTooManyRequests, res.ErrorResponse(http.StatusTooManyRequests, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+}
+
+// mfaErrorStatus maps an error of the MFA authenticator to a HTTP status
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, mfa.ErrAlreadyEnabled):
+		return http.StatusForbidden
+	case errors.Is(err, mfa.ErrNotEnrolled), errors.Is(err, mfa.ErrNotEnabled):
+		return http.StatusNotFound
+	case errors.Is(err, mfa.ErrInvalidCode), errors.Is(err, mfa.ErrInvalidChallenge), errors.Is(err, mfa.ErrTooManyAttempts):
+		return http.StatusUnauthorized
+	}
+
+	return http.StatusInternalServerError
+}
+
+// phoneErrorStatus maps an error of phone verification to a HTTP status
+func phoneErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, phone.ErrNoPhone):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, phone.ErrAlreadyVerified), errors.Is(err, phone.ErrNotVerified), errors.Is(err, phone.ErrInvalidCode):
+		return http.StatusBadRequest
+	case errors.Is(err, phone.ErrTooManyAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, phone.ErrPhoneAlreadyInUse):
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+// oidcErrorStatus maps an error of a SSO login to a HTTP status
+func oidcErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, oidc.ErrInvalidState), errors.Is(err, oidc.ErrInvalidIDToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, oidc.ErrEmailRequired):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, oidc.ErrEmailInUse):
+		return http.StatusConflict
+	case errors.Is(err, oidc.ErrProvider):
+		return http.StatusBadGateway
+	}
+
+	return http.StatusInternalServerError
+}
+
+// multipartOverhead leaves room for the other form fields and the multipart framing of an upload
+const multipartOverhead = 64 * 1024
+
+// kycDocumentErrorStatus maps an error of a document upload to a HTTP status
+func kycDocumentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, kyc.ErrDocumentsLocked):
+		return http.StatusConflict
+	case errors.Is(err, kyc.ErrDocumentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, kyc.ErrUnsupportedDocumentType), errors.Is(err, kyc.ErrDocumentTypeMismatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, kyc.ErrEmptyDocument):
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
+
+// readFormFile reads at most one byte more than maxSize so oversized files are still detected
+func readFormFile(header *multipart.FileHeader, maxSize int64) ([]byte, error) {
+	file, err := header.Open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, maxSize+1))
 }
@@ -2,29 +2,50 @@ package user
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	req "github.com/ChokeGuy/simple-bank/api/user/dto"
 	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
 	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/ChokeGuy/simple-bank/pkg/loginhistory"
+	"github.com/ChokeGuy/simple-bank/pkg/mfa"
 	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/oidc"
+	"github.com/ChokeGuy/simple-bank/pkg/oidc/oidctest"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/session"
+	"github.com/ChokeGuy/simple-bank/pkg/throttle"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/pkg/token/paseto"
+	"github.com/ChokeGuy/simple-bank/pkg/totp"
 	server "github.com/ChokeGuy/simple-bank/server/http"
 	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/ChokeGuy/simple-bank/util/password"
 	"github.com/ChokeGuy/simple-bank/worker"
 	mockwk "github.com/ChokeGuy/simple-bank/worker/mock"
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // TestGetUserByUserNameApi tests the GetUserByUserName API handler
@@ -163,25 +184,50 @@ func TestCreateUserApi(t *testing.T) {
 					Times(1).
 					Return(db.CreateUserTxResult{User: user}, nil)
 
-				//Send verification email to user
-				taskPayload := &worker.PayloadSendVerifyEmail{
-					UserName: user.Username,
-				}
-
+				// the task is published by the outbox relay, never by the handler
 				taskDistributor.EXPECT().
-					DistributeTaskSendVerifyEmail(
-						gomock.Any(),
-						EqPayloadSendVerifyEmail(taskPayload),
-						gomock.Any(),
-					).
-					Times(1).
-					Return(nil)
+					DistributeTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchCreateUser(t, recorder.Body, user)
 			},
 		},
+		{
+			name: "PasswordContainsUsername",
+			body: req.CreateUserRequest{
+				UserName: user.Username,
+				FullName: user.FullName,
+				Password: "Aa1!" + user.Username,
+				Email:    user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CommonPassword",
+			body: req.CreateUserRequest{
+				UserName: user.Username,
+				FullName: user.FullName,
+				Password: "P@ssw0rd2024!",
+				Email:    user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "InternalError",
 			body: req.CreateUserRequest{
@@ -197,11 +243,8 @@ func TestCreateUserApi(t *testing.T) {
 					Return(db.CreateUserTxResult{}, sql.ErrConnDone)
 
 				taskDistributor.EXPECT().
-					DistributeTaskSendVerifyEmail(
-						gomock.Any(),
-						gomock.Any(),
-						gomock.Any(),
-					).Times(0)
+					DistributeTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
 
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -213,11 +256,8 @@ func TestCreateUserApi(t *testing.T) {
 			body: req.CreateUserRequest{},
 			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
 				taskDistributor.EXPECT().
-					DistributeTaskSendVerifyEmail(
-						gomock.Any(),
-						gomock.Any(),
-						gomock.Any(),
-					).Times(0)
+					DistributeTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -264,9 +304,22 @@ func TestCreateUserApi(t *testing.T) {
 }
 
 // TestLoginUserApi tests the LoginUser API handler
+// requireArgon2idHash checks that a password was rehashed with argon2id
+func requireArgon2idHash(t *testing.T, plainPassword string, hashedPassword string) {
+	require.True(t, strings.HasPrefix(hashedPassword, "$argon2id$"))
+	require.NoError(t, password.CheckPassword(plainPassword, hashedPassword))
+}
+
 func TestLoginUserApi(t *testing.T) {
 	user, password := RandomUser(t)
 
+	// Hashes from before argon2id are upgraded on login, the login succeeds even if that fails
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
 	testCases := []struct {
 		name          string
 		body          req.LoginUserRequest
@@ -276,8 +329,9 @@ func TestLoginUserApi(t *testing.T) {
 		{
 			name: "OK",
 			body: req.LoginUserRequest{
-				UserName: user.Username,
-				Password: password,
+				UserName:    user.Username,
+				Password:    password,
+				DeviceLabel: "Work laptop",
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -292,21 +346,20 @@ func TestLoginUserApi(t *testing.T) {
 					}, nil)
 
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetSessionByUserNameRow{}, db.ErrRecordNotFound)
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), EqCreateSessionTxParams(user.Username, "Work laptop", cfg.MaxSessionsPerUser)).
 					Times(1).
-					Return(db.Session{
-						ID:           uuid.New(),
-						Username:     user.Username,
-						RefreshToken: gomock.Any().String(),
-						UserAgent:    gomock.Any().String(),
-						ClientIp:     gomock.Any().String(),
-						IsBlocked:    false,
-						ExpiresAt:    time.Now().Add(24 * time.Hour),
+					Return(db.CreateSessionTxResult{
+						Session: db.Session{
+							ID:          uuid.New(),
+							Username:    user.Username,
+							DeviceLabel: "Work laptop",
+							ExpiresAt:   time.Now().Add(24 * time.Hour),
+						},
 					}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -314,7 +367,7 @@ func TestLoginUserApi(t *testing.T) {
 			},
 		},
 		{
-			name: "ExpiredSession",
+			name: "RehashBcryptPassword",
 			body: req.LoginUserRequest{
 				UserName: user.Username,
 				Password: password,
@@ -323,47 +376,33 @@ func TestLoginUserApi(t *testing.T) {
 				store.EXPECT().
 					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetUserByUserNameRow{
-						Username:       user.Username,
-						FullName:       user.FullName,
-						Email:          user.Email,
-						HashedPassword: user.HashedPassword,
-						CreatedAt:      user.CreatedAt,
-					}, nil)
+					Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: string(bcryptHash)}, nil)
 
-				sessionID := uuid.New()
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					UpdateUser(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.GetSessionByUserNameRow{
-						ID:        sessionID,
-						ExpiresAt: time.Now().Add(-24 * time.Hour), // Expired session
-					}, nil)
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						requireArgon2idHash(t, password, arg.HashedPassword.String)
+						return db.User{}, nil
+					})
 
 				store.EXPECT().
-					DeleteSession(gomock.Any(), gomock.Eq(sessionID)).
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(nil)
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Session{
-						ID:           uuid.New(),
-						Username:     user.Username,
-						RefreshToken: gomock.Any().String(),
-						UserAgent:    gomock.Any().String(),
-						ClientIp:     gomock.Any().String(),
-						IsBlocked:    false,
-						ExpiresAt:    time.Now().Add(24 * time.Hour),
-					}, nil)
+					Return(db.CreateSessionTxResult{Session: db.Session{ID: uuid.New(), Username: user.Username}}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 		{
-			name: "DeleteExpiredSessionError",
+			name: "RehashFailed",
 			body: req.LoginUserRequest{
 				UserName: user.Username,
 				Password: password,
@@ -372,34 +411,33 @@ func TestLoginUserApi(t *testing.T) {
 				store.EXPECT().
 					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetUserByUserNameRow{
-						Username:       user.Username,
-						FullName:       user.FullName,
-						Email:          user.Email,
-						HashedPassword: user.HashedPassword,
-						CreatedAt:      user.CreatedAt,
-					}, nil)
+					Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: string(bcryptHash)}, nil)
 
-				sessionID := uuid.New()
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					UpdateUser(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.GetSessionByUserNameRow{
-						ID:        sessionID,
-						ExpiresAt: time.Now().Add(-24 * time.Hour), // Expired session
-					}, nil)
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						requireArgon2idHash(t, password, arg.HashedPassword.String)
+						return db.User{}, sql.ErrConnDone
+					})
 
 				store.EXPECT().
-					DeleteSession(gomock.Any(), gomock.Eq(sessionID)).
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(sql.ErrConnDone)
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateSessionTxResult{Session: db.Session{ID: uuid.New(), Username: user.Username}}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 		{
-			name: "BlockedSession",
+			name: "ExistingSessionEvicted",
 			body: req.LoginUserRequest{
 				UserName: user.Username,
 				Password: password,
@@ -417,47 +455,40 @@ func TestLoginUserApi(t *testing.T) {
 					}, nil)
 
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetSessionByUserNameRow{
-						ID:        uuid.New(),
-						IsBlocked: true,
-						ExpiresAt: time.Now().Add(24 * time.Hour), // Add non-expired time
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), EqCreateSessionTxParams(user.Username, "", cfg.MaxSessionsPerUser)).
+					Times(1).
+					Return(db.CreateSessionTxResult{
+						Session: db.Session{
+							ID:        uuid.New(),
+							Username:  user.Username,
+							ExpiresAt: time.Now().Add(24 * time.Hour),
+						},
+						Evicted: []uuid.UUID{uuid.New()},
 					}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 		{
-			name: "ActiveSession",
+			name: "DeviceLabelTooLong",
 			body: req.LoginUserRequest{
-				UserName: user.Username,
-				Password: password,
+				UserName:    user.Username,
+				Password:    password,
+				DeviceLabel: util.RandomString(65),
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
-					Times(1).
-					Return(db.GetUserByUserNameRow{
-						Username:       user.Username,
-						FullName:       user.FullName,
-						Email:          user.Email,
-						HashedPassword: user.HashedPassword,
-						CreatedAt:      user.CreatedAt,
-					}, nil)
-
-				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
-					Times(1).
-					Return(db.GetSessionByUserNameRow{
-						ID:        uuid.New(),
-						IsBlocked: false,
-						ExpiresAt: time.Now().Add(24 * time.Hour),
-					}, nil)
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
@@ -492,13 +523,13 @@ func TestLoginUserApi(t *testing.T) {
 					}, nil)
 
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Any()).
-					Times(0)
+					CreateLoginEvent(gomock.Any(), EqLoginFailure(user.Username, loginhistory.ReasonInvalidPassword)).
+					Times(1).
+					Return(db.LoginEvent{}, nil)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), gomock.Any()).
 					Times(0)
-
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -539,14 +570,91 @@ func TestLoginUserApi(t *testing.T) {
 					}, nil)
 
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateSessionTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "MfaRequired",
+			body: req.LoginUserRequest{
+				UserName:    user.Username,
+				Password:    password,
+				DeviceLabel: "Work laptop",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{
+						Username:       user.Username,
+						HashedPassword: user.HashedPassword,
+					}, nil)
+
+				userTotp, _ := RandomUserTotp(t, user.Username, true)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					CreateMfaChallengeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateMfaChallengeParams, _ ...db.TxOption) (db.CreateMfaChallengeTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, "Work laptop", arg.DeviceLabel)
+						return db.CreateMfaChallengeTxResult{}, nil
+					})
+
+				// No session is created before the second factor is checked
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data map[string]interface{} `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, true, body.Data["mfaRequired"])
+				require.NotEmpty(t, body.Data["challengeToken"])
+				require.NotContains(t, body.Data, "accessToken")
+			},
+		},
+		{
+			name: "MfaInternalError",
+			body: req.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetSessionByUserNameRow{}, db.ErrRecordNotFound)
+					Return(db.GetUserByUserNameRow{
+						Username:       user.Username,
+						HashedPassword: user.HashedPassword,
+					}, nil)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.Session{}, sql.ErrConnDone)
+					Return(db.UserTotp{}, sql.ErrConnDone)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -591,6 +699,15 @@ func TestRefreshTokenApi(t *testing.T) {
 
 	session, refreshToken := RandomSession(t, user.Username)
 
+	cfg, err := pkg.LoadConfig("../..")
+	require.NoError(t, err)
+
+	tokenMaker, err := paseto.NewPasetoMaker(cfg.SymetricKey)
+	require.NoError(t, err)
+
+	accessToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, session.ID, time.Hour)
+	require.NoError(t, err)
+
 	testCases := []struct {
 		name          string
 		body          req.RefreshTokenRequest
@@ -611,7 +728,7 @@ func TestRefreshTokenApi(t *testing.T) {
 					ExpiresAt:    time.Now().Add(24 * time.Hour),
 				}
 				store.EXPECT().
-					GetSessionById(gomock.Any(), gomock.Any()).
+					GetSessionById(gomock.Any(), gomock.Eq(session.ID)).
 					Times(1).
 					Return(db.GetSessionByIdRow{
 						ID:           session.ID,
@@ -622,9 +739,82 @@ func TestRefreshTokenApi(t *testing.T) {
 						IsBlocked:    session.IsBlocked,
 						ExpiresAt:    session.ExpiresAt,
 					}, nil)
+
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), EqRotateRefreshTokenTxParams(session.ID, refreshToken)).
+					Times(1).
+					Return(db.RotateRefreshTokenTxResult{Session: session}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.RefreshTokenResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.NotEmpty(t, body.Data.AccessToken)
+				require.NotEmpty(t, body.Data.RefreshToken)
+				require.NotEqual(t, refreshToken, body.Data.RefreshToken)
+			},
+		},
+		{
+			name: "IssuedBeforePasswordChange",
+			body: req.RefreshTokenRequest{
+				RefreshToken: refreshToken,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{
+						ID:                session.ID,
+						Username:          user.Username,
+						ExpiresAt:         time.Now().Add(24 * time.Hour),
+						PasswordChangedAt: time.Now().Add(2 * time.Second),
+					}, nil)
+
+				// A token from before the change is rejected, not treated as reused
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RotateError",
+			body: req.RefreshTokenRequest{
+				RefreshToken: refreshToken,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				session := db.Session{
+					ID:           session.ID,
+					Username:     user.Username,
+					RefreshToken: refreshToken,
+					IsBlocked:    false,
+					ExpiresAt:    time.Now().Add(24 * time.Hour),
+				}
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetSessionByIdRow{
+						ID:           session.ID,
+						Username:     session.Username,
+						RefreshToken: session.RefreshToken,
+						UserAgent:    session.UserAgent,
+						ClientIp:     session.ClientIp,
+						IsBlocked:    session.IsBlocked,
+						ExpiresAt:    session.ExpiresAt,
+					}, nil)
+
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), EqRotateRefreshTokenTxParams(session.ID, refreshToken)).
+					Times(1).
+					Return(db.RotateRefreshTokenTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
@@ -639,6 +829,23 @@ func TestRefreshTokenApi(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "AccessToken",
+			body: req.RefreshTokenRequest{
+				RefreshToken: accessToken,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "InvalidRefreshToken",
 			body: req.RefreshTokenRequest{
@@ -713,6 +920,31 @@ func TestRefreshTokenApi(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "RevokedSession",
+			body: req.RefreshTokenRequest{
+				RefreshToken: refreshToken,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{
+						ID:           session.ID,
+						Username:     user.Username,
+						RefreshToken: refreshToken,
+						ExpiresAt:    time.Now().Add(24 * time.Hour),
+						RevokedAt:    pgtype.Timestamptz{Time: time.Now(), Valid: true},
+					}, nil)
+
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "SessionNotFound",
 			body: req.RefreshTokenRequest{
@@ -759,7 +991,7 @@ func TestRefreshTokenApi(t *testing.T) {
 			},
 		},
 		{
-			name: "MismatchedSessionToken",
+			name: "ReusedRefreshToken",
 			body: req.RefreshTokenRequest{
 				RefreshToken: refreshToken,
 			},
@@ -767,7 +999,7 @@ func TestRefreshTokenApi(t *testing.T) {
 				session := db.Session{
 					ID:           session.ID,
 					Username:     user.Username,
-					RefreshToken: "different_token", // Different refresh token
+					RefreshToken: token.HashToken("rotated_token"), // The token was already rotated
 					IsBlocked:    false,
 					ExpiresAt:    time.Now().Add(24 * time.Hour),
 				}
@@ -783,6 +1015,11 @@ func TestRefreshTokenApi(t *testing.T) {
 						IsBlocked:    session.IsBlocked,
 						ExpiresAt:    session.ExpiresAt,
 					}, nil)
+
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), EqRotateRefreshTokenTxParams(session.ID, refreshToken)).
+					Times(1).
+					Return(db.RotateRefreshTokenTxResult{Session: session, Reused: true}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -873,9 +1110,9 @@ func TestUpdateUserApi(t *testing.T) {
 						CreatedAt:         user.CreatedAt,
 					}, nil)
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					UpdateUserTx(gomock.Any(), EqUpdateUserTxParams(arg)).
 					Times(1).
-					Return(user, nil)
+					Return(db.UpdateUserTxResult{User: user}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -908,9 +1145,9 @@ func TestUpdateUserApi(t *testing.T) {
 						CreatedAt:         user.CreatedAt,
 					}, nil)
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					UpdateUserTx(gomock.Any(), EqUpdateUserTxParams(arg)).
 					Times(1).
-					Return(user, nil)
+					Return(db.UpdateUserTxResult{User: user}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -918,7 +1155,30 @@ func TestUpdateUserApi(t *testing.T) {
 			},
 		},
 		{
-			name: "UpdateAllFields",
+			name: "UpdateEmailNeedsStepUp",
+			body: req.UpdateUserRequest{
+				UserName: user.Username,
+				Email:    util.RandomEmail(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute,
+					token.WithAuth(time.Now().Add(-time.Hour), token.AuthStrengthPassword))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Email: user.Email}, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Header().Get("WWW-Authenticate"), "insufficient_user_authentication")
+			},
+		},
+		{
+			name: "UpdateAllFields",
 			body: req.UpdateUserRequest{
 				UserName: user.Username,
 				Email:    user.Email,
@@ -945,15 +1205,72 @@ func TestUpdateUserApi(t *testing.T) {
 						CreatedAt:         user.CreatedAt,
 					}, nil)
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					UpdateUserTx(gomock.Any(), EqUpdateUserTxParams(arg)).
 					Times(1).
-					Return(user, nil)
+					Return(db.UpdateUserTxResult{User: user}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchUser(t, recorder.Body, user)
 			},
 		},
+		{
+			name: "EmailChanged",
+			body: req.UpdateUserRequest{
+				UserName: user.Username,
+				Email:    "new" + user.Email,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateUserParams{
+					Username: user.Username,
+					Email:    pgtype.Text{String: "new" + user.Email, Valid: true},
+				}
+
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Email: user.Email}, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), EqUpdateUserTxParams(arg)).
+					Times(1).
+					Return(db.UpdateUserTxResult{User: user, EmailChanged: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "verification email was sent")
+			},
+		},
+		{
+			name: "BankerUpdateIsAudited",
+			body: req.UpdateUserRequest{
+				UserName: user.Username,
+				Email:    "new" + user.Email,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Email: user.Email}, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserTxParams, _ ...db.TxOption) (db.UpdateUserTxResult, error) {
+						require.NotNil(t, arg.AuditLog)
+						require.Equal(t, "banker", arg.AuditLog.Actor)
+						require.Equal(t, audit.UpdateUser, arg.AuditLog.Action)
+						require.Equal(t, user.Username, arg.AuditLog.Target)
+						require.JSONEq(t, fmt.Sprintf(`{"fields":["email"],"email":"new%s"}`, user.Email), string(arg.AuditLog.Metadata))
+						return db.UpdateUserTxResult{User: user, EmailChanged: true}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
 		{
 			name: "UserNotFound",
 			body: req.UpdateUserRequest{
@@ -985,7 +1302,7 @@ func TestUpdateUserApi(t *testing.T) {
 					HashedPassword:    user.HashedPassword,
 					PasswordChangedAt: user.PasswordChangedAt,
 				}, nil)
-				store.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -1052,12 +1369,46 @@ func TestUpdateUserApi(t *testing.T) {
 					PasswordChangedAt: user.PasswordChangedAt,
 				}, nil)
 
-				store.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(1).Return(db.UpdateUserTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "ProfileChangeResetsKyc",
+			body: req.UpdateUserRequest{
+				UserName: user.Username,
+				Address:  "2 Nguyen Hue, District 1",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{
+						Username:  user.Username,
+						FullName:  user.FullName,
+						Email:     user.Email,
+						Address:   "1 Le Loi, District 1",
+						KycStatus: kyc.Verified,
+					}, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserTxParams, _ ...db.TxOption) (db.UpdateUserTxResult, error) {
+						require.Equal(t, "2 Nguyen Hue, District 1", arg.Address.String)
+						require.Equal(t, kyc.Unverified, arg.KycStatus.String)
+						require.NotNil(t, arg.KycReview)
+						require.Equal(t, kyc.Verified, arg.KycReview.FromStatus)
+						return db.UpdateUserTxResult{User: user}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -1092,103 +1443,201 @@ func TestUpdateUserApi(t *testing.T) {
 	}
 }
 
-func TestVerifyUserEmailApi(t *testing.T) {
+func TestGetProfileApi(t *testing.T) {
 	user, _ := RandomUser(t)
-	verifyEmail := RandomVerifyEmail(t, user)
+	row := db.GetUserByUserNameRow{
+		Username:    user.Username,
+		FullName:    user.FullName,
+		Email:       user.Email,
+		Role:        user.Role,
+		DateOfBirth: pgtype.Date{Time: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+		Phone:       "+84901234567",
+		Address:     "1 Le Loi, District 1",
+		Nationality: "VN",
+		KycStatus:   kyc.Verified,
+	}
 
 	testCases := []struct {
 		name          string
-		query         req.VerifyUserEmailRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
-			query: req.VerifyUserEmailRequest{
-				EmailId:    verifyEmail.ID,
-				SecretCode: verifyEmail.SecretCode,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.VerifyUserEmailTxParams{
-					EmailId:    verifyEmail.ID,
-					SecretCode: verifyEmail.SecretCode,
-				}
-
 				store.EXPECT().
-					VerifyUserEmailTx(gomock.Any(), gomock.Eq(arg)).
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.VerifyUserEmailTxResult{
-						User: db.User{
-							Username:          user.Username,
-							FullName:          user.FullName,
-							Email:             user.Email,
-							HashedPassword:    user.HashedPassword,
-							IsEmailVerified:   true,
-							PasswordChangedAt: user.PasswordChangedAt,
-							CreatedAt:         user.CreatedAt,
-						},
-						VerifyEmail: db.VerifyEmail{
-							ID:         verifyEmail.ID,
-							Username:   user.Username,
-							Email:      user.Email,
-							SecretCode: verifyEmail.SecretCode,
-							ExpiredAt:  verifyEmail.ExpiredAt,
-							IsUsed:     true,
-							CreatedAt:  verifyEmail.CreatedAt,
-						},
-					}, nil)
+					Return(row, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				if recorder.Code == http.StatusOK {
-					t.Log("Response body: ", recorder.Body.String())
-				}
 				require.Equal(t, http.StatusOK, recorder.Code)
 
-				requireBodyMatchVerifyEmail(t, recorder.Body, true)
+				var response struct {
+					Data req.UserResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, "1990-01-02", response.Data.DateOfBirth)
+				require.Equal(t, row.Phone, response.Data.Phone)
+				require.Equal(t, row.Nationality, response.Data.Nationality)
+				require.Equal(t, kyc.Verified, response.Data.KycStatus)
 			},
 		},
 		{
-			name:  "BadRequest",
-			query: req.VerifyUserEmailRequest{},
+			name:      "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					VerifyUserEmailTx(gomock.Any(), gomock.Any()).
+					GetUserByUserName(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/user/profile", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSubmitKycApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	row := db.GetUserByUserNameRow{
+		Username:    user.Username,
+		DateOfBirth: pgtype.Date{Time: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+		Phone:       "+84901234567",
+		Address:     "1 Le Loi, District 1",
+		Nationality: "VN",
+		KycStatus:   kyc.Rejected,
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+
+				arg := db.UpdateKycStatusTxParams{
+					UpdateUserKycStatusParams: db.UpdateUserKycStatusParams{
+						Username:   user.Username,
+						FromStatus: kyc.Rejected,
+						ToStatus:   kyc.Pending,
+					},
+					Reviewer:        user.Username,
+					AttachDocuments: true,
+				}
+
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.UpdateKycStatusTxResult{User: user}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 		{
-			name: "NotFound",
-			query: req.VerifyUserEmailRequest{
-				EmailId:    verifyEmail.ID,
-				SecretCode: verifyEmail.SecretCode,
+			name: "IncompleteProfile",
+			buildStubs: func(store *mockdb.MockStore) {
+				incomplete := row
+				incomplete.DateOfBirth = pgtype.Date{}
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(incomplete, nil)
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 			},
+		},
+		{
+			name: "AlreadyPending",
 			buildStubs: func(store *mockdb.MockStore) {
+				pending := row
+				pending.KycStatus = kyc.Pending
+
 				store.EXPECT().
-					VerifyUserEmailTx(gomock.Any(), gomock.Any()).
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.VerifyUserEmailTxResult{}, db.ErrRecordNotFound)
+					Return(pending, nil)
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
 			},
 		},
 		{
-			name: "InternalError",
-			query: req.VerifyUserEmailRequest{
-				EmailId:    verifyEmail.ID,
-				SecretCode: verifyEmail.SecretCode,
+			name: "NoDocuments",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateKycStatusTxResult{}, db.ErrNoKycDocuments)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 			},
+		},
+		{
+			name: "StatusChanged",
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					VerifyUserEmailTx(gomock.Any(), gomock.Any()).
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.VerifyUserEmailTxResult{}, sql.ErrConnDone)
+					Return(row, nil)
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateKycStatusTxResult{}, db.ErrRecordNotFound)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
 			},
 		},
 	}
@@ -1203,91 +1652,4117 @@ func TestVerifyUserEmailApi(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			// start test server and send request
 			cfg, err := pkg.LoadConfig("../../")
 			require.NoError(t, err)
 
 			server := server.NewTestServer(t, store, &cfg, nil)
 			userHandler := NewUserHandler(server)
 			userHandler.MapRoutes()
-
 			recorder := httptest.NewRecorder()
 
-			url := "/user/verify-email"
-			request, err := http.NewRequest(http.MethodGet, url, nil)
+			request, err := http.NewRequest(http.MethodPost, "/user/kyc/submit", nil)
 			require.NoError(t, err)
 
-			// Add query parameters
-			q := request.URL.Query()
-			q.Add("emailId", fmt.Sprint(tc.query.EmailId))
-			q.Add("secretCode", tc.query.SecretCode)
-			request.URL.RawQuery = q.Encode()
-
+			auth.AddAuthorization(t, request, server.TokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
 			server.Router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
 	}
 }
 
-// Helper function to check response body
-func requireBodyMatchVerifyEmail(t *testing.T, body *bytes.Buffer, isVerified bool) {
-	data, err := io.ReadAll(body)
-	require.NoError(t, err)
-
-	var response struct {
-		Data struct {
-			IsVerified bool `json:"isVerified"`
-		} `json:"data"`
-		Message    string `json:"message"`
-		StatusCode int    `json:"statusCode"`
-	}
-
-	err = json.Unmarshal(data, &response)
-	require.NoError(t, err)
-	require.Equal(t, isVerified, response.Data.IsVerified)
-}
-
-// requireBodyMatchUser checks if the response body matches user
-func requireBodyMatchUser(t *testing.T, body *bytes.Buffer, user db.User) {
-	data, err := io.ReadAll(body)
-	require.NoError(t, err)
-
-	var response struct {
-		Data       req.UserResponse `json:"data"`
-		Message    string           `json:"message"`
-		StatusCode int              `json:"statusCode"`
-	}
+func TestUploadKycDocumentApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	row := db.GetUserByUserNameRow{Username: user.Username, KycStatus: kyc.Unverified}
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
 
-	err = json.Unmarshal(data, &response)
+	testCases := []struct {
+		name          string
+		kind          string
+		fileName      string
+		contentType   string
+		data          []byte
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "OK",
+			kind:        kyc.DocumentPassport,
+			fileName:    "passport.png",
+			contentType: "image/png",
+			data:        png,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					CreateKycDocument(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateKycDocumentParams) (db.KycDocument, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, kyc.DocumentPassport, arg.Kind)
+						require.Equal(t, "image/png", arg.ContentType)
+						require.Equal(t, int64(len(png)), arg.Size)
+						return db.KycDocument{ID: 1, Username: arg.Username, Kind: arg.Kind, Status: kyc.DocumentPending}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "InvalidKind",
+			kind:        "selfie",
+			fileName:    "passport.png",
+			contentType: "image/png",
+			data:        png,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingFile",
+			kind: kyc.DocumentPassport,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "ContentMismatch",
+			kind:        kyc.DocumentPassport,
+			fileName:    "passport.png",
+			contentType: "image/png",
+			data:        []byte("MZ\x90\x00 not an image"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					CreateKycDocument(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+		{
+			name:        "TooLarge",
+			kind:        kyc.DocumentPassport,
+			fileName:    "passport.png",
+			contentType: "image/png",
+			data:        append(png, make([]byte, 6<<20)...),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateKycDocument(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+		{
+			name:        "UnderReview",
+			kind:        kyc.DocumentPassport,
+			fileName:    "passport.png",
+			contentType: "image/png",
+			data:        png,
+			buildStubs: func(store *mockdb.MockStore) {
+				pending := row
+				pending.KycStatus = kyc.Pending
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					CreateKycDocument(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			require.NoError(t, writer.WriteField("kind", tc.kind))
+
+			if tc.data != nil {
+				header := textproto.MIMEHeader{}
+				header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, tc.fileName))
+				header.Set("Content-Type", tc.contentType)
+
+				part, err := writer.CreatePart(header)
+				require.NoError(t, err)
+				_, err = part.Write(tc.data)
+				require.NoError(t, err)
+			}
+			require.NoError(t, writer.Close())
+
+			request, err := http.NewRequest(http.MethodPost, "/user/kyc/documents", body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+
+			auth.AddAuthorization(t, request, server.TokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListKycDocumentsApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	documents := []db.KycDocument{
+		{ID: 1, Username: user.Username, Kind: kyc.DocumentPassport, Status: kyc.DocumentPending},
+		{ID: 2, Username: user.Username, Kind: kyc.DocumentProofOfAddress, Status: kyc.DocumentRejected, Note: "Blurry scan"},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?page=2&size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListKycDocumentsParams{Username: user.Username, Limit: 5, Offset: 5}
+
+				store.EXPECT().
+					ListKycDocuments(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(documents, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "Blurry scan")
+			},
+		},
+		{
+			name:  "InvalidPage",
+			query: "?page=0&size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListKycDocuments(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/user/kyc/documents"+tc.query, nil)
+			require.NoError(t, err)
+
+			auth.AddAuthorization(t, request, server.TokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyUserEmailApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	verifyEmail := RandomVerifyEmail(t, user)
+
+	testCases := []struct {
+		name          string
+		query         req.VerifyUserEmailRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			query: req.VerifyUserEmailRequest{
+				EmailId:    verifyEmail.ID,
+				SecretCode: verifyEmail.SecretCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.VerifyUserEmailTxParams{
+					EmailId:    verifyEmail.ID,
+					SecretCode: verifyEmail.SecretCode,
+				}
+
+				store.EXPECT().
+					VerifyUserEmailTx(gomock.Any(), EqVerifyUserEmailTxParams(arg, user)).
+					Times(1).
+					Return(db.VerifyUserEmailTxResult{
+						User: db.User{
+							Username:          user.Username,
+							FullName:          user.FullName,
+							Email:             user.Email,
+							HashedPassword:    user.HashedPassword,
+							IsEmailVerified:   true,
+							PasswordChangedAt: user.PasswordChangedAt,
+							CreatedAt:         user.CreatedAt,
+						},
+						VerifyEmail: db.VerifyEmail{
+							ID:         verifyEmail.ID,
+							Username:   user.Username,
+							Email:      user.Email,
+							SecretCode: verifyEmail.SecretCode,
+							ExpiredAt:  verifyEmail.ExpiredAt,
+							IsUsed:     true,
+							CreatedAt:  verifyEmail.CreatedAt,
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				if recorder.Code == http.StatusOK {
+					t.Log("Response body: ", recorder.Body.String())
+				}
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				requireBodyMatchVerifyEmail(t, recorder.Body, true)
+			},
+		},
+		{
+			name:  "BadRequest",
+			query: req.VerifyUserEmailRequest{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyUserEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			query: req.VerifyUserEmailRequest{
+				EmailId:    verifyEmail.ID,
+				SecretCode: verifyEmail.SecretCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyUserEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyUserEmailTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: req.VerifyUserEmailRequest{
+				EmailId:    verifyEmail.ID,
+				SecretCode: verifyEmail.SecretCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					VerifyUserEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyUserEmailTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			// start test server and send request
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+
+			recorder := httptest.NewRecorder()
+
+			url := "/user/verify-email"
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			// Add query parameters
+			q := request.URL.Query()
+			q.Add("emailId", fmt.Sprint(tc.query.EmailId))
+			q.Add("secretCode", tc.query.SecretCode)
+			request.URL.RawQuery = q.Encode()
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// Helper function to check response body
+func requireBodyMatchVerifyEmail(t *testing.T, body *bytes.Buffer, isVerified bool) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var response struct {
+		Data struct {
+			IsVerified bool `json:"isVerified"`
+		} `json:"data"`
+		Message    string `json:"message"`
+		StatusCode int    `json:"statusCode"`
+	}
+
+	err = json.Unmarshal(data, &response)
+	require.NoError(t, err)
+	require.Equal(t, isVerified, response.Data.IsVerified)
+}
+
+// requireBodyMatchUser checks if the response body matches user
+func requireBodyMatchUser(t *testing.T, body *bytes.Buffer, user db.User) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var response struct {
+		Data       req.UserResponse `json:"data"`
+		Message    string           `json:"message"`
+		StatusCode int              `json:"statusCode"`
+	}
+
+	err = json.Unmarshal(data, &response)
+	require.NoError(t, err)
+
+	require.Equal(t, req.UserResponse{
+		UserName:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt.String(),
+		CreatedAt:         user.CreatedAt.String(),
+	}, response.Data)
+}
+
+func requireBodyMatchCreateUser(t *testing.T, body *bytes.Buffer, user db.User) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var response struct {
+		Data       req.UserResponse `json:"data"`
+		Message    string           `json:"message"`
+		StatusCode int              `json:"statusCode"`
+	}
+
+	err = json.Unmarshal(data, &response)
+	require.NoError(t, err)
+
+	require.Equal(t, req.UserResponse{
+		UserName:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt.String(),
+		CreatedAt:         user.CreatedAt.String(),
+	}, response.Data)
+}
+
+// TestSessionsApi tests the session management API handlers
+func TestSessionsApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	session, _ := RandomSession(t, user.Username)
+	other, _ := RandomSession(t, util.RandomOwner())
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ListOK",
+			method: http.MethodGet,
+			url:    "/user/sessions",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSessionsByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return([]db.Session{session}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.ListSessionsResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Len(t, body.Data.Sessions, 1)
+				require.Equal(t, session.ID, body.Data.Sessions[0].ID)
+			},
+		},
+		{
+			name:   "ListNoAuthorization",
+			method: http.MethodGet,
+			url:    "/user/sessions",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSessionsByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeOK",
+			method: http.MethodDelete,
+			url:    "/user/sessions/" + session.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{ID: session.ID, Username: session.Username}, nil)
+
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeInvalidID",
+			method: http.MethodDelete,
+			url:    "/user/sessions/not-a-uuid",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeNotFound",
+			method: http.MethodDelete,
+			url:    "/user/sessions/" + session.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeOtherUserSession",
+			method: http.MethodDelete,
+			url:    "/user/sessions/" + other.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(other.ID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{ID: other.ID, Username: other.Username}, nil)
+
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "LogoutOK",
+			method: http.MethodPost,
+			url:    "/auth/logout",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "LogoutNoAuthorization",
+			method: http.MethodPost,
+			url:    "/auth/logout",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "LogoutInternalError",
+			method: http.MethodPost,
+			url:    "/auth/logout",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeAllOK",
+			method: http.MethodDelete,
+			url:    "/user/sessions",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeSessionsByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return([]uuid.UUID{uuid.New(), uuid.New(), uuid.New()}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "RevokeAllInternalError",
+			method: http.MethodDelete,
+			url:    "/user/sessions",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeSessionsByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestVerifyLoginMfaApi tests the second step of a login with two-factor authentication
+func TestVerifyLoginMfaApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	userTotp, secret := RandomUserTotp(t, user.Username, true)
+	challengeToken := util.RandomString(43)
+
+	challenge := db.MfaChallenge{
+		ID:          util.RandomInt(1, 1000),
+		Username:    user.Username,
+		TokenHash:   token.HashToken(challengeToken),
+		DeviceLabel: "Work laptop",
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	validCode := func(t *testing.T) string {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+		return code
+	}
+
+	testCases := []struct {
+		name          string
+		body          func(t *testing.T) gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challengeToken": challengeToken, "code": validCode(t)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Eq(db.IncrementMfaChallengeAttemptsParams{ID: challenge.ID, MaxAttempts: mfa.MaxChallengeAttempts})).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+
+				store.EXPECT().
+					ConsumeMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Role: user.Role}, nil)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), EqCreateSessionTxParams(user.Username, "Work laptop", cfg.MaxSessionsPerUser)).
+					Times(1).
+					Return(db.CreateSessionTxResult{Session: db.Session{ID: uuid.New(), Username: user.Username}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.LoginUserResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.NotEmpty(t, body.Data.AccessToken)
+				require.NotEmpty(t, body.Data.RefreshToken)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challengeToken": challengeToken, "code": "abcde-fghij"}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Eq(db.IncrementMfaChallengeAttemptsParams{ID: challenge.ID, MaxAttempts: mfa.MaxChallengeAttempts})).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), EqLoginFailure(user.Username, loginhistory.ReasonInvalidMfaCode)).
+					Times(1).
+					Return(db.LoginEvent{}, nil)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeNotFound",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challengeToken": challengeToken, "code": validCode(t)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": validCode(t)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challengeToken": challengeToken, "code": validCode(t)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body(t))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/auth/login/mfa", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestVerifyLoginMfaThrottleApi tests that codes of the second step count against the login throttle of the user
+func TestVerifyLoginMfaThrottleApi(t *testing.T) {
+	user, password := RandomUser(t)
+	userTotp, secret := RandomUserTotp(t, user.Username, true)
+	challengeToken := util.RandomString(43)
+	clientIP := "10.0.0.1"
+
+	challenge := db.MfaChallenge{
+		ID:          util.RandomInt(1, 1000),
+		Username:    user.Username,
+		TokenHash:   token.HashToken(challengeToken),
+		DeviceLabel: "Work laptop",
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+	// Without progressive delays a few failures can be counted without blocking the next request
+	cfg.LoginBaseDelay = 0
+
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: user.Username}
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: clientIP}
+
+	failTimes := func(t *testing.T, limiter throttle.Limiter, key throttle.Key, times int64) {
+		for i := int64(0); i < times; i++ {
+			require.NoError(t, limiter.Fail(context.Background(), key))
+		}
+	}
+
+	failures := func(t *testing.T, limiter throttle.Limiter, key throttle.Key) int64 {
+		status, err := limiter.Status(context.Background(), key)
+		require.NoError(t, err)
+		return status.Failures
+	}
+
+	expectChallenge := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+			Times(1).
+			Return(challenge, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		body          func(t *testing.T) gin.H
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter)
+	}{
+		{
+			name: "PasswordKeepsUserFailures",
+			path: "/auth/login",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"userName": user.Username, "password": password, "deviceLabel": "Work laptop"}
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				failTimes(t, limiter, userKey, 2)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: user.HashedPassword}, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					CreateMfaChallengeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateMfaChallengeTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// A new challenge does not buy new guesses, only the second factor resets the user
+				require.Equal(t, int64(2), failures(t, limiter, userKey))
+			},
+		},
+		{
+			name: "InvalidCodeCounted",
+			path: "/auth/login/mfa",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"challengeToken": challengeToken, "code": "abcde-fghij"}
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChallenge(store)
+
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), EqLoginFailure(user.Username, loginhistory.ReasonInvalidMfaCode)).
+					Times(1).
+					Return(db.LoginEvent{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, int64(1), failures(t, limiter, userKey))
+				require.Equal(t, int64(1), failures(t, limiter, ipKey))
+			},
+		},
+		{
+			name: "UserLocked",
+			path: "/auth/login/mfa",
+			body: func(t *testing.T) gin.H {
+				code, err := totp.GenerateCode(secret, time.Now())
+				require.NoError(t, err)
+				return gin.H{"challengeToken": challengeToken, "code": code}
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				failTimes(t, limiter, userKey, cfg.LoginMaxFailures)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChallenge(store)
+
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "SuccessResetsUserFailures",
+			path: "/auth/login/mfa",
+			body: func(t *testing.T) gin.H {
+				code, err := totp.GenerateCode(secret, time.Now())
+				require.NoError(t, err)
+				return gin.H{"challengeToken": challengeToken, "code": code}
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				failTimes(t, limiter, userKey, 2)
+				failTimes(t, limiter, ipKey, 1)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChallenge(store)
+
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+
+				store.EXPECT().
+					ConsumeMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Role: user.Role}, nil)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateSessionTxResult{Session: db.Session{ID: uuid.New(), Username: user.Username}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Zero(t, failures(t, limiter, userKey))
+
+				// The address keeps its failures
+				require.Equal(t, int64(1), failures(t, limiter, ipKey))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			tc.setupLimiter(t, server.Limiter)
+
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body(t))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.path, bytes.NewReader(body))
+			require.NoError(t, err)
+			request.RemoteAddr = clientIP + ":4321"
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.Limiter)
+		})
+	}
+}
+
+// TestTotpApi tests the TOTP enrolment API handlers
+func TestTotpApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	pendingTotp, pendingSecret := RandomUserTotp(t, user.Username, false)
+	enabledTotp, enabledSecret := RandomUserTotp(t, user.Username, true)
+
+	codeOf := func(t *testing.T, secret string) string {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+		return code
+	}
+
+	testCases := []struct {
+		name          string
+		url           string
+		body          func(t *testing.T) gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "EnrollOK",
+			url:  "/user/mfa/totp",
+			body: func(t *testing.T) gin.H {
+				return gin.H{}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertPendingUserTotp(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pendingTotp, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.EnrollTotpResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.NotEmpty(t, body.Data.Secret)
+				require.Contains(t, body.Data.OtpauthUri, body.Data.Secret)
+			},
+		},
+		{
+			name: "EnrollAlreadyEnabled",
+			url:  "/user/mfa/totp",
+			body: func(t *testing.T) gin.H {
+				return gin.H{}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertPendingUserTotp(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "EnrollNoAuthorization",
+			url:  "/user/mfa/totp",
+			body: func(t *testing.T) gin.H {
+				return gin.H{}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertPendingUserTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ConfirmOK",
+			url:  "/user/mfa/totp/confirm",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": codeOf(t, pendingSecret)}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pendingTotp, nil)
+
+				store.EXPECT().
+					ConfirmTotpTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConfirmTotpTxResult{UserTotp: enabledTotp}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.ConfirmTotpResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Len(t, body.Data.RecoveryCodes, mfa.RecoveryCodeCount)
+			},
+		},
+		{
+			name: "ConfirmInvalidCode",
+			url:  "/user/mfa/totp/confirm",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": codeOf(t, enabledSecret)}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pendingTotp, nil)
+
+				store.EXPECT().
+					ConfirmTotpTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ConfirmNotEnrolled",
+			url:  "/user/mfa/totp/confirm",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": codeOf(t, pendingSecret)}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "ConfirmBadRequest",
+			url:  "/user/mfa/totp/confirm",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": "12ab"}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DisableOK",
+			url:  "/user/mfa/totp/disable",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": codeOf(t, enabledSecret)}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(enabledTotp, nil)
+
+				store.EXPECT().
+					UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+
+				store.EXPECT().
+					DisableTotpTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DisableNotEnabled",
+			url:  "/user/mfa/totp/disable",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": codeOf(t, pendingSecret)}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pendingTotp, nil)
+
+				store.EXPECT().
+					DisableTotpTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "DisableInternalError",
+			url:  "/user/mfa/totp/disable",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": codeOf(t, enabledSecret)}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(enabledTotp, nil)
+
+				store.EXPECT().
+					UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+
+				store.EXPECT().
+					DisableTotpTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body(t))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.url, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestStepUpApi tests the StepUp API handler
+func TestStepUpApi(t *testing.T) {
+	user, password := RandomUser(t)
+	enabledTotp, enabledSecret := RandomUserTotp(t, user.Username, true)
+
+	userRow := db.GetUserByUserNameRow{
+		Username:       user.Username,
+		Role:           user.Role,
+		HashedPassword: user.HashedPassword,
+	}
+
+	codeOf := func(t *testing.T, secret string) string {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+		return code
+	}
+
+	requireElevatedToken := func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, strength string) {
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var body struct {
+			Data req.StepUpResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		require.Equal(t, strength, body.Data.AuthStrength)
+
+		payload, err := tokenMaker.VerifyToken(body.Data.AccessToken)
+		require.NoError(t, err)
+		require.Equal(t, user.Username, payload.UserName)
+		require.Equal(t, strength, payload.AuthStrength)
+		require.True(t, payload.AuthenticatedWithin(time.Minute))
+	}
+
+	staleLogin := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute,
+			token.WithAuth(time.Now().Add(-time.Hour), token.AuthStrengthPassword))
+	}
+
+	testCases := []struct {
+		name          string
+		body          func(t *testing.T) gin.H
+		minStrength   string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker)
+	}{
+		{
+			name: "PasswordOK",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"password": password}
+			},
+			setupAuth: staleLogin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireElevatedToken(t, recorder, tokenMaker, token.AuthStrengthPassword)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"password": "Wrong" + password}
+			},
+			setupAuth: staleLogin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "PasswordRequired",
+			body: func(t *testing.T) gin.H {
+				return gin.H{}
+			},
+			setupAuth: staleLogin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PasswordBelowPolicy",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"password": password}
+			},
+			minStrength: token.AuthStrengthMfa,
+			setupAuth:   staleLogin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "MfaCodeOK",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": codeOf(t, enabledSecret)}
+			},
+			setupAuth: staleLogin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(2).
+					Return(enabledTotp, nil)
+				store.EXPECT().UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				requireElevatedToken(t, recorder, tokenMaker, token.AuthStrengthMfa)
+			},
+		},
+		{
+			name: "MfaPasswordOnly",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"password": password}
+			},
+			setupAuth: staleLogin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(enabledTotp, nil)
+				store.EXPECT().UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MfaInvalidCode",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"code": "not-a-code"}
+			},
+			setupAuth: staleLogin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(2).
+					Return(enabledTotp, nil)
+				// Not a TOTP code, so it is tried as a recovery code
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: func(t *testing.T) gin.H {
+				return gin.H{"password": password}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			if tc.minStrength != "" {
+				cfg.StepUpMinStrength = tc.minStrength
+			}
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body(t))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/auth/step-up", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.TokenMaker)
+		})
+	}
+}
+
+// TestForgotPasswordApi tests the ForgotPassword API handler
+func TestForgotPasswordApi(t *testing.T) {
+	user, _ := RandomUser(t)
+
+	// Known and unknown emails both only write the outbox message, the worker looks the user up
+	expectResetEmail := func(store *mockdb.MockStore, email string, err error) {
+		store.EXPECT().
+			GetUserByEmail(gomock.Any(), gomock.Any()).
+			Times(0)
+
+		store.EXPECT().
+			CreateOutboxMessage(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+				require.Equal(t, worker.TaskSendResetPasswordEmail, arg.TaskType)
+				require.Equal(t, worker.AggregatePasswordReset, arg.AggregateType)
+
+				var payload worker.PayloadSendResetPasswordEmail
+				require.NoError(t, json.Unmarshal(arg.Payload, &payload))
+				require.Equal(t, email, payload.Email)
+				require.Equal(t, payload.RequestID.String(), arg.AggregateID)
+
+				return db.OutboxMessage{}, err
+			})
+	}
+
+	emailKey := throttle.Key{Scope: throttle.ForgotPassword, ID: strings.ToLower(user.Email)}
+	unknownEmail := util.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter)
+	}{
+		{
+			name:         "OK",
+			body:         gin.H{"email": user.Email},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetEmail(store, user.Email, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// Every request counts so the next one has to wait
+				status, err := limiter.Status(context.Background(), emailKey)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), status.Failures)
+			},
+		},
+		{
+			name:         "UnknownEmail",
+			body:         gin.H{"email": unknownEmail},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetEmail(store, unknownEmail, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				// Unknown emails get the same answer as known ones
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "EmailThrottled",
+			body: gin.H{"email": strings.ToUpper(user.Email)},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				require.NoError(t, limiter.Fail(context.Background(), emailKey))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidEmail",
+			body:         gin.H{"email": "invalid-email"},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InternalError",
+			body:         gin.H{"email": user.Email},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetEmail(store, user.Email, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+				// No email goes out, so the request does not count
+				status, err := limiter.Status(context.Background(), emailKey)
+				require.NoError(t, err)
+				require.Zero(t, status.Failures)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			tc.setupLimiter(t, server.Limiter)
+
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/auth/forgot-password", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.Limiter)
+		})
+	}
+}
+
+// TestResetPasswordApi tests the ResetPassword API handler
+func TestResetPasswordApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	resetToken := util.RandomString(43)
+	newPassword := util.RandomPassword()
+	revoked := []uuid.UUID{uuid.New(), uuid.New()}
+
+	// The reset is looked up before the transaction to check the password against the user and its history
+	expectResetUser := func(store *mockdb.MockStore, history []string) {
+		store.EXPECT().
+			GetPasswordResetByTokenHash(gomock.Any(), gomock.Eq(token.HashToken(resetToken))).
+			Times(1).
+			Return(db.PasswordReset{Username: user.Username, Email: user.Email}, nil)
+
+		store.EXPECT().
+			GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return(db.GetUserByUserNameRow{Username: user.Username, Email: user.Email, HashedPassword: user.HashedPassword}, nil)
+
+		store.EXPECT().
+			ListPasswordHistory(gomock.Any(), gomock.Eq(db.ListPasswordHistoryParams{Username: user.Username, Limit: 4})).
+			Times(1).
+			Return(history, nil)
+	}
+
+	newPasswordHash, err := password.HashPassword(newPassword)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": resetToken, "password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetUser(store, []string{})
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ResetPasswordTxParams, _ ...db.TxOption) (db.ResetPasswordTxResult, error) {
+						// Only the hash of the reset token may reach the store
+						require.Equal(t, token.HashToken(resetToken), arg.TokenHash)
+						require.NoError(t, password.CheckPassword(newPassword, arg.HashedPassword))
+						require.Equal(t, int32(4), arg.HistoryKeep)
+						return db.ResetPasswordTxResult{User: user, RevokedSessions: revoked}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: gin.H{"token": resetToken, "password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordResetByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordReset{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TokenUsedMeanwhile",
+			body: gin.H{"token": resetToken, "password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetUser(store, []string{})
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "WeakPassword",
+			body: gin.H{"token": resetToken, "password": "password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ReusedPassword",
+			body: gin.H{"token": resetToken, "password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetUser(store, []string{newPasswordHash})
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ContainsUsername",
+			body: gin.H{"token": resetToken, "password": "Aa1!" + user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordResetByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordReset{Username: user.Username, Email: user.Email}, nil)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Email: user.Email, HashedPassword: user.HashedPassword}, nil)
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"token": resetToken, "password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetUser(store, []string{})
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/auth/reset-password", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestChangePasswordApi tests the ChangePassword API handler
+func TestChangePasswordApi(t *testing.T) {
+	user, currentPassword := RandomUser(t)
+	session, _ := RandomSession(t, user.Username)
+	newPassword := util.RandomPassword()
+	revoked := []uuid.UUID{uuid.New(), uuid.New()}
+
+	newPasswordHash, err := password.HashPassword(newPassword)
+	require.NoError(t, err)
+
+	userRow := db.GetUserByUserNameRow{
+		Username:       user.Username,
+		Role:           user.Role,
+		HashedPassword: user.HashedPassword,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "RevokeAllSessions",
+			body: gin.H{"currentPassword": currentPassword, "newPassword": newPassword},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					ListPasswordHistory(gomock.Any(), gomock.Eq(db.ListPasswordHistoryParams{Username: user.Username, Limit: 4})).
+					Times(1).
+					Return([]string{}, nil)
+
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams, _ ...db.TxOption) (db.ChangePasswordTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NoError(t, password.CheckPassword(newPassword, arg.HashedPassword))
+						require.Equal(t, uuid.Nil, arg.KeepSessionID)
+						require.Equal(t, int32(4), arg.HistoryKeep)
+						return db.ChangePasswordTxResult{User: user, RevokedSessions: revoked}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.ChangePasswordResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, int64(len(revoked)), body.Data.Revoked)
+				require.Nil(t, body.Data.Tokens)
+			},
+		},
+		{
+			name: "KeepCurrentSession",
+			body: gin.H{"currentPassword": currentPassword, "newPassword": newPassword, "keepCurrentSession": true},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				accessToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, session.ID, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(auth.AuthHeaderKey, fmt.Sprintf("%s %s", auth.AuthTypeBearer, accessToken))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{ID: session.ID, Username: user.Username, ExpiresAt: session.ExpiresAt, LastSeenAt: time.Now()}, nil)
+
+				store.EXPECT().
+					ListPasswordHistory(gomock.Any(), gomock.Eq(db.ListPasswordHistoryParams{Username: user.Username, Limit: 4})).
+					Times(1).
+					Return([]string{}, nil)
+
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams, _ ...db.TxOption) (db.ChangePasswordTxResult, error) {
+						require.Equal(t, session.ID, arg.KeepSessionID)
+						require.NotEmpty(t, arg.KeepSessionRefreshToken)
+						return db.ChangePasswordTxResult{User: user}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.ChangePasswordResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.NotNil(t, body.Data.Tokens)
+				require.NotEmpty(t, body.Data.Tokens.AccessToken)
+				require.NotEmpty(t, body.Data.Tokens.RefreshToken)
+			},
+		},
+		{
+			name: "IncorrectCurrentPassword",
+			body: gin.H{"currentPassword": util.RandomPassword(), "newPassword": newPassword},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ReusedPassword",
+			body: gin.H{"currentPassword": currentPassword, "newPassword": newPassword},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					ListPasswordHistory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]string{newPasswordHash}, nil)
+
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SamePassword",
+			body: gin.H{"currentPassword": currentPassword, "newPassword": currentPassword},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "WeakNewPassword",
+			body: gin.H{"currentPassword": currentPassword, "newPassword": "password"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{"currentPassword": currentPassword, "newPassword": newPassword},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"currentPassword": currentPassword, "newPassword": newPassword},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					ListPasswordHistory(gomock.Any(), gomock.Eq(db.ListPasswordHistoryParams{Username: user.Username, Limit: 4})).
+					Times(1).
+					Return([]string{}, nil)
+
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ChangePasswordTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/user/change-password", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestLoginUserEvictedSessionApi tests that a session evicted by a login is no longer served from the session cache
+func TestLoginUserEvictedSessionApi(t *testing.T) {
+	user, password := RandomUser(t)
+	evictedID := uuid.New()
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	active := db.GetSessionByIdRow{ID: evictedID, Username: user.Username, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now()}
+	gomock.InOrder(
+		store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(evictedID)).Return(active, nil),
+		store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(evictedID)).Return(db.GetSessionByIdRow{}, db.ErrRecordNotFound),
+	)
+	store.EXPECT().
+		GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: user.HashedPassword}, nil)
+	store.EXPECT().
+		GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(db.UserTotp{}, db.ErrRecordNotFound)
+	store.EXPECT().
+		CreateSessionTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.CreateSessionTxResult{
+			Session: db.Session{ID: uuid.New(), Username: user.Username},
+			Evicted: []uuid.UUID{evictedID},
+		}, nil)
+
+	server := server.NewTestServer(t, store, &cfg, nil)
+	server.SessionChecker = session.NewCachedChecker(store, time.Minute)
+
+	evicted, err := token.NewPayload(user.Username, user.Role, evictedID, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, server.SessionChecker.CheckSession(context.Background(), evicted))
+
+	userHandler := NewUserHandler(server)
+	userHandler.MapRoutes()
+	recorder := httptest.NewRecorder()
+
+	body, err := json.Marshal(req.LoginUserRequest{UserName: user.Username, Password: password})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// The cached answer is dropped, the evicted session is looked up again and rejected
+	require.Error(t, server.SessionChecker.CheckSession(context.Background(), evicted))
+}
+
+// TestLoginUserThrottleApi tests the failed attempt tracking of the LoginUser API handler
+func TestLoginUserThrottleApi(t *testing.T) {
+	user, password := RandomUser(t)
+	clientIP := "10.0.0.1"
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: user.Username}
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: clientIP}
+
+	failTimes := func(t *testing.T, limiter throttle.Limiter, key throttle.Key, times int64) {
+		for i := int64(0); i < times; i++ {
+			require.NoError(t, limiter.Fail(context.Background(), key))
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          req.LoginUserRequest
+		forwardedFor  string
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter)
+	}{
+		{
+			name: "IncorrectPasswordCounted",
+			body: req.LoginUserRequest{
+				UserName: user.Username,
+				Password: "Wrong@123",
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: user.HashedPassword}, nil)
+
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), EqLoginFailure(user.Username, loginhistory.ReasonInvalidPassword)).
+					Times(1).
+					Return(db.LoginEvent{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				status, err := limiter.Status(context.Background(), userKey)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), status.Failures)
+
+				status, err = limiter.Status(context.Background(), ipKey)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), status.Failures)
+			},
+		},
+		{
+			name: "UserNotFoundCounted",
+			body: req.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+
+				status, err := limiter.Status(context.Background(), ipKey)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), status.Failures)
+			},
+		},
+		{
+			name: "UserLocked",
+			body: req.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				failTimes(t, limiter, userKey, cfg.LoginMaxFailures)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "ClientIPLocked",
+			body: req.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				failTimes(t, limiter, ipKey, cfg.LoginIPMaxFailures)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			// No proxy is trusted, a client cannot pick the address it is throttled by
+			name: "ForgedForwardedForStillLocked",
+			body: req.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			forwardedFor: "198.51.100.23",
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				failTimes(t, limiter, ipKey, cfg.LoginIPMaxFailures)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "ForgedForwardedForCountedOnRemoteAddress",
+			body: req.LoginUserRequest{
+				UserName: user.Username,
+				Password: "Wrong@123",
+			},
+			forwardedFor: "198.51.100.23",
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: user.HashedPassword}, nil)
+
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), EqLoginFailure(user.Username, loginhistory.ReasonInvalidPassword)).
+					Times(1).
+					Return(db.LoginEvent{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				status, err := limiter.Status(context.Background(), ipKey)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), status.Failures)
+
+				status, err = limiter.Status(context.Background(), throttle.Key{Scope: throttle.ClientIP, ID: "198.51.100.23"})
+				require.NoError(t, err)
+				require.Zero(t, status.Failures)
+			},
+		},
+		{
+			name: "InternalErrorNotCounted",
+			body: req.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+				// The reserved attempt is given back
+				for _, key := range []throttle.Key{userKey, ipKey} {
+					status, err := limiter.Status(context.Background(), key)
+					require.NoError(t, err)
+					require.Zero(t, status.Failures)
+					require.Zero(t, status.RetryAfter)
+				}
+			},
+		},
+		{
+			name: "SuccessResetsUserFailures",
+			body: req.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				// Failures on the IP only, the user has no delay to wait
+				failTimes(t, limiter, ipKey, 1)
+				require.NoError(t, limiter.Fail(context.Background(), throttle.Key{Scope: throttle.VerifyEmail, ID: "1"}))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: user.HashedPassword}, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateSessionTxResult{Session: db.Session{ID: uuid.New(), Username: user.Username}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// The address keeps its failures
+				status, err := limiter.Status(context.Background(), ipKey)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), status.Failures)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			tc.setupLimiter(t, server.Limiter)
+
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+			require.NoError(t, err)
+			request.RemoteAddr = clientIP + ":4321"
+			if tc.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			}
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.Limiter)
+		})
+	}
+}
+
+// TestLoginUserConcurrentGuessesApi tests that parallel guesses cannot all pass the throttle before one of them fails
+func TestLoginUserConcurrentGuessesApi(t *testing.T) {
+	user, _ := RandomUser(t)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	// Only the first guess of the burst reaches the password check
+	store.EXPECT().
+		GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: user.HashedPassword}, nil)
+
+	store.EXPECT().
+		CreateLoginEvent(gomock.Any(), EqLoginFailure(user.Username, loginhistory.ReasonInvalidPassword)).
+		Times(1).
+		Return(db.LoginEvent{}, nil)
+
+	server := server.NewTestServer(t, store, &cfg, nil)
+	userHandler := NewUserHandler(server)
+	userHandler.MapRoutes()
+
+	body, err := json.Marshal(req.LoginUserRequest{UserName: user.Username, Password: "Wrong@123"})
+	require.NoError(t, err)
+
+	n := 10
+	codes := make(chan int, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+
+			server.Router.ServeHTTP(recorder, request)
+			codes <- recorder.Code
+		}()
+	}
+
+	wg.Wait()
+	close(codes)
+
+	counts := make(map[int]int)
+	for code := range codes {
+		counts[code]++
+	}
+
+	require.Equal(t, map[int]int{http.StatusBadRequest: 1, http.StatusTooManyRequests: n - 1}, counts)
+}
+
+// TestVerifyUserEmailThrottleApi tests the failed attempt tracking of the VerifyUserEmail API handler
+func TestVerifyUserEmailThrottleApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	verifyEmail := RandomVerifyEmail(t, user)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	emailKey := throttle.Key{Scope: throttle.VerifyEmail, ID: fmt.Sprint(verifyEmail.ID)}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	// Only the first guess reaches the store, the next one has to wait
+	store.EXPECT().
+		VerifyUserEmailTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.VerifyUserEmailTxResult{}, db.ErrRecordNotFound)
+
+	server := server.NewTestServer(t, store, &cfg, nil)
+	userHandler := NewUserHandler(server)
+	userHandler.MapRoutes()
+
+	for _, code := range []int{http.StatusNotFound, http.StatusTooManyRequests} {
+		recorder := httptest.NewRecorder()
+
+		request, err := http.NewRequest(http.MethodGet, "/user/verify-email", nil)
+		require.NoError(t, err)
+
+		q := request.URL.Query()
+		q.Add("emailId", fmt.Sprint(verifyEmail.ID))
+		q.Add("secretCode", util.RandomString(32))
+		request.URL.RawQuery = q.Encode()
+
+		server.Router.ServeHTTP(recorder, request)
+		require.Equal(t, code, recorder.Code)
+	}
+
+	status, err := server.Limiter.Status(context.Background(), emailKey)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status.Failures)
+}
+
+// TestUserLockoutApi tests the banker API handlers to view and lift a lockout
+func TestUserLockoutApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	banker, _ := RandomUser(t)
+	banker.Role = util.BankerRole
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: user.Username}
+
+	lockUser := func(t *testing.T, limiter throttle.Limiter) {
+		for i := int64(0); i < cfg.LoginMaxFailures; i++ {
+			require.NoError(t, limiter.Fail(context.Background(), userKey))
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		method        string
+		userName      string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter)
+	}{
+		{
+			name:     "GetLocked",
+			method:   http.MethodGet,
+			userName: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response struct {
+					Data req.UserLockoutResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, user.Username, response.Data.UserName)
+				require.Equal(t, cfg.LoginMaxFailures, response.Data.Failures)
+				require.True(t, response.Data.Locked)
+				require.NotNil(t, response.Data.LockedUntil)
+				require.WithinDuration(t, time.Now().Add(cfg.LoginLockoutDuration), *response.Data.LockedUntil, time.Minute)
+			},
+		},
+		{
+			name:     "Unlock",
+			method:   http.MethodDelete,
+			userName: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NoError(t, limiter.Check(context.Background(), userKey))
+			},
+		},
+		{
+			name:     "NotBanker",
+			method:   http.MethodDelete,
+			userName: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.ErrorIs(t, limiter.Check(context.Background(), userKey), throttle.ErrTooManyAttempts)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			method:   http.MethodGet,
+			userName: "nonexistent",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq("nonexistent")).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidUserName",
+			method:   http.MethodGet,
+			userName: "invalid-user",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, banker.Username, banker.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NoAuthorization",
+			method:   http.MethodDelete,
+			userName: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			lockUser(t, server.Limiter)
+
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/users/%s/lockout", tc.userName)
+			request, err := http.NewRequest(tc.method, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.Limiter)
+		})
+	}
+}
+
+// TestResendVerifyEmailApi tests the ResendVerifyEmail API handler
+func TestResendVerifyEmailApi(t *testing.T) {
+	user, _ := RandomUser(t)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	resendKey := throttle.Key{Scope: throttle.ResendVerifyEmail, ID: user.Username}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), EqResendVerifyEmailTxParams(user.Username)).
+					Times(1).
+					Return(db.ResendVerifyEmailTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// The next resend has to wait
+				require.ErrorIs(t, limiter.Check(context.Background(), resendKey), throttle.ErrTooManyAttempts)
+			},
+		},
+		{
+			name: "TooSoon",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				require.NoError(t, limiter.Fail(context.Background(), resendKey))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResendVerifyEmailTxResult{}, db.ErrEmailAlreadyVerified)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				// A rejected resend does not count
+				require.NoError(t, limiter.Check(context.Background(), resendKey))
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResendVerifyEmailTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			tc.setupLimiter(t, server.Limiter)
+
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/user/verify-email/resend", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.Limiter)
+		})
+	}
+}
+
+func TestLoginEventsApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	denyToken := util.RandomString(43)
+	sessionID := uuid.New()
+
+	event := db.LoginEvent{
+		ID:        util.RandomInt(1, 1000),
+		Username:  user.Username,
+		SessionID: pgtype.UUID{Bytes: sessionID, Valid: true},
+		Success:   true,
+		UserAgent: "Mozilla/5.0",
+		ClientIp:  "203.0.113.7",
+		NewDevice: true,
+		CreatedAt: time.Now(),
+	}
+
+	failure := db.LoginEvent{
+		ID:            event.ID - 1,
+		Username:      user.Username,
+		FailureReason: loginhistory.ReasonInvalidPassword,
+		UserAgent:     "curl/8.0",
+		ClientIp:      "198.51.100.2",
+		CreatedAt:     time.Now().Add(-time.Minute),
+	}
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ListOK",
+			url:  "/user/login-events?page=2&size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginEvents(gomock.Any(), gomock.Eq(db.ListLoginEventsParams{Username: user.Username, Limit: 5, Offset: 5})).
+					Times(1).
+					Return([]db.LoginEvent{event, failure}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.ListLoginEventsResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Len(t, body.Data.LoginEvents, 2)
+
+				require.True(t, body.Data.LoginEvents[0].Success)
+				require.True(t, body.Data.LoginEvents[0].NewDevice)
+				require.Equal(t, &sessionID, body.Data.LoginEvents[0].SessionID)
+
+				require.False(t, body.Data.LoginEvents[1].Success)
+				require.Equal(t, loginhistory.ReasonInvalidPassword, body.Data.LoginEvents[1].FailureReason)
+				require.Nil(t, body.Data.LoginEvents[1].SessionID)
+			},
+		},
+		{
+			name: "ListInvalidSize",
+			url:  "/user/login-events?page=1&size=500",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ListNoAuthorization",
+			url:  "/user/login-events?page=1&size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ListInternalError",
+			url:  "/user/login-events?page=1&size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "GetDenyOK",
+			method: http.MethodGet,
+			url:    "/auth/deny-login?token=" + denyToken,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				// Opening the link only looks the login up, prefetchers cannot deny it
+				store.EXPECT().
+					GetLoginEventByDenyToken(gomock.Any(), gomock.Eq(pgtype.Text{String: token.HashToken(denyToken), Valid: true})).
+					Times(1).
+					Return(event, nil)
+
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.GetDenyLoginResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, event.ID, body.Data.LoginEvent.ID)
+				require.Equal(t, event.ClientIp, body.Data.LoginEvent.ClientIp)
+			},
+		},
+		{
+			name:   "GetDenyUnknownOrUsedLink",
+			method: http.MethodGet,
+			url:    "/auth/deny-login?token=" + denyToken,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginEventByDenyToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginEvent{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "GetDenyMissingToken",
+			method: http.MethodGet,
+			url:    "/auth/deny-login",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginEventByDenyToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "DenyOK",
+			method: http.MethodPost,
+			url:    "/auth/deny-login",
+			body:   gin.H{"token": denyToken},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				// Only the hash of the token may reach the store
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Eq(token.HashToken(denyToken))).
+					Times(1).
+					Return(db.DenyLoginTxResult{LoginEvent: event, Blocked: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.DenyLoginResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.True(t, body.Data.SessionRevoked)
+			},
+		},
+		{
+			name:   "DenySessionEnded",
+			method: http.MethodPost,
+			url:    "/auth/deny-login",
+			body:   gin.H{"token": denyToken},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Eq(token.HashToken(denyToken))).
+					Times(1).
+					Return(db.DenyLoginTxResult{LoginEvent: db.LoginEvent{ID: event.ID, Username: user.Username}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.DenyLoginResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.False(t, body.Data.SessionRevoked)
+			},
+		},
+		{
+			name:   "DenyUnknownOrUsedLink",
+			method: http.MethodPost,
+			url:    "/auth/deny-login",
+			body:   gin.H{"token": denyToken},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DenyLoginTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "DenyTokenInQuery",
+			method: http.MethodPost,
+			url:    "/auth/deny-login?token=" + denyToken,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "DenyInternalError",
+			method: http.MethodPost,
+			url:    "/auth/deny-login",
+			body:   gin.H{"token": denyToken},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DenyLoginTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			var body io.Reader
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = bytes.NewReader(data)
+			}
+
+			request, err := http.NewRequest(method, tc.url, body)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSendPhoneCodeApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	user.Phone = "+84912345678"
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	sendKey := throttle.Key{Scope: throttle.SendPhoneCode, ID: user.Username}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Phone: user.Phone}, nil)
+
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+						require.Equal(t, worker.TaskSendPhoneCode, arg.TaskType)
+
+						var payload worker.PayloadSendPhoneCode
+						require.NoError(t, json.Unmarshal(arg.Payload, &payload))
+						require.Equal(t, user.Phone, payload.Phone)
+						require.Equal(t, phone.PurposeVerify, payload.Purpose)
+						return db.OutboxMessage{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// The next code has to wait
+				require.ErrorIs(t, limiter.Check(context.Background(), sendKey), throttle.ErrTooManyAttempts)
+			},
+		},
+		{
+			name: "TooSoon",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				require.NoError(t, limiter.Fail(context.Background(), sendKey))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "NoPhone",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				// A rejected request does not count
+				require.NoError(t, limiter.Check(context.Background(), sendKey))
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Phone: user.Phone, IsPhoneVerified: true}, nil)
+
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:         "NoAuthorization",
+			setupAuth:    func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			tc.setupLimiter(t, server.Limiter)
+
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/user/phone/send-code", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.TokenMaker)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.Limiter)
+		})
+	}
+}
+
+func TestVerifyPhoneApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	user.Phone = "+84912345678"
+	code := "482913"
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	userRow := db.GetUserByUserNameRow{Username: user.Username, Phone: user.Phone}
+	phoneCode := db.PhoneCode{
+		ID:        util.RandomInt(1, 1000),
+		Username:  user.Username,
+		Phone:     user.Phone,
+		Purpose:   phone.PurposeVerify,
+		CodeHash:  phone.HashCode([]byte(cfg.PhoneCodeKey), code),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	attemptArg := db.IncrementPhoneCodeAttemptsParams{ID: phoneCode.ID, MaxAttempts: phone.MaxCodeAttempts}
+
+	verifyKey := throttle.Key{Scope: throttle.VerifyPhone, ID: user.Username}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					GetLatestPhoneCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(phoneCode, nil)
+
+				verified := user
+				verified.IsPhoneVerified = true
+
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Eq(db.VerifyPhoneTxParams{CodeID: phoneCode.ID, Username: user.Username, Phone: user.Phone})).
+					Times(1).
+					Return(db.VerifyPhoneTxResult{User: verified}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.UserResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.True(t, body.Data.IsPhoneVerified)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: gin.H{"code": "000000"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					GetLatestPhoneCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				status, err := limiter.Status(context.Background(), verifyKey)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), status.Failures)
+			},
+		},
+		{
+			name: "TooManyAttempts",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					GetLatestPhoneCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(db.PhoneCode{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "PhoneAlreadyInUse",
+			body: gin.H{"code": code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					GetLatestPhoneCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyPhoneTxResult{}, &pgconn.PgError{Code: db.UniqueViolation, ConstraintName: db.VerifiedPhoneConstraint})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidBody",
+			body: gin.H{"code": "12ab56"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, limiter throttle.Limiter) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/user/phone/verify", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			auth.AddAuthorization(t, request, server.TokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.Limiter)
+		})
+	}
+}
+
+func TestSendLoginSmsCodeApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	user.Phone = "+84912345678"
+	challengeToken := util.RandomString(43)
+
+	challenge := db.MfaChallenge{
+		ID:        util.RandomInt(1, 1000),
+		Username:  user.Username,
+		TokenHash: token.HashToken(challengeToken),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	cfg, err := pkg.LoadConfig("../../")
 	require.NoError(t, err)
 
-	require.Equal(t, req.UserResponse{
-		UserName:          user.Username,
-		FullName:          user.FullName,
-		Email:             user.Email,
-		PasswordChangedAt: user.PasswordChangedAt.String(),
-		CreatedAt:         user.CreatedAt.String(),
-	}, response.Data)
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"challengeToken": challengeToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Phone: user.Phone, IsPhoneVerified: true}, nil)
+
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+						require.Equal(t, worker.TaskSendPhoneCode, arg.TaskType)
+
+						var payload worker.PayloadSendPhoneCode
+						require.NoError(t, json.Unmarshal(arg.Payload, &payload))
+						require.Equal(t, phone.PurposeLogin, payload.Purpose)
+						return db.OutboxMessage{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "PhoneNotVerified",
+			body: gin.H{"challengeToken": challengeToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Phone: user.Phone}, nil)
+
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeNotFound",
+			body: gin.H{"challengeToken": challengeToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/auth/login/mfa/sms", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
 }
 
-func requireBodyMatchCreateUser(t *testing.T, body *bytes.Buffer, user db.User) {
-	data, err := io.ReadAll(body)
+func TestOidcLoginApi(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	user, _ := RandomUser(t)
+	subject := util.RandomString(12)
+
+	cfg, err := pkg.LoadConfig("../../")
 	require.NoError(t, err)
 
-	var response struct {
-		Data       req.UserResponse `json:"data"`
-		Message    string           `json:"message"`
-		StatusCode int              `json:"statusCode"`
+	cfg.OidcIssuerUrl = idp.Issuer()
+	cfg.OidcClientID = oidctest.ClientID
+	cfg.OidcClientSecret = oidctest.ClientSecret
+	cfg.OidcRedirectUrl = oidctest.RedirectURL
+	cfg.OidcRoleMapping = "bank-tellers:banker"
+
+	userRow := db.GetUserByUserNameRow{
+		Username: user.Username,
+		Role:     util.BankerRole,
+		FullName: user.FullName,
+		Email:    user.Email,
 	}
 
-	err = json.Unmarshal(data, &response)
+	callbackQuery := func(code, state string) url.Values {
+		return url.Values{"code": {code}, "state": {state}}
+	}
+
+	testCases := []struct {
+		name          string
+		claims        map[string]any
+		query         func(code, state string) url.Values
+		withoutCookie bool
+		buildStubs    func(store *mockdb.MockStore, loginState *db.OidcLoginState)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker)
+	}{
+		{
+			name: "NewUser",
+			claims: map[string]any{
+				"sub":                subject,
+				"email":              user.Email,
+				"preferred_username": user.Username,
+				"groups":             []string{"bank-tellers"},
+			},
+			query: callbackQuery,
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ConsumeOidcLoginStateParams) (db.OidcLoginState, error) {
+						require.Equal(t, loginState.StateHash, arg.StateHash)
+						require.Equal(t, loginState.BrowserHash, arg.BrowserHash)
+						return *loginState, nil
+					})
+
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Eq(db.GetUserIdentityParams{Issuer: idp.Issuer(), Subject: subject})).
+					Times(1).
+					Return(db.UserIdentity{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateOidcUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOidcUserTxParams, _ ...db.TxOption) (db.CreateOidcUserTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, util.BankerRole, arg.Role)
+						require.False(t, arg.IsEmailVerified)
+
+						newUser := db.User{Username: arg.Username, Email: arg.Email, Role: arg.Role}
+						// The provider did not verify the address, a verification email is sent
+						require.NoError(t, arg.AfterCreate(store, newUser))
+						return db.CreateOidcUserTxResult{User: newUser}, nil
+					})
+
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+						require.Equal(t, worker.TaskSendVerifyEmail, arg.TaskType)
+						return db.OutboxMessage{}, nil
+					})
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), EqCreateSessionTxParams(user.Username, "Work laptop", cfg.MaxSessionsPerUser)).
+					Times(1).
+					Return(db.CreateSessionTxResult{Session: db.Session{ID: uuid.New(), Username: user.Username}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.LoginUserResponse `json:"data"`
+				}
+				require.NoError(t, json.NewDecoder(recorder.Body).Decode(&body))
+				require.Equal(t, user.Username, body.Data.User.UserName)
+
+				payload, err := tokenMaker.VerifyToken(body.Data.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, util.BankerRole, payload.Role)
+				require.Equal(t, token.AuthStrengthSso, payload.AuthStrength)
+			},
+		},
+		{
+			name:   "LinkedUserMfaRequired",
+			claims: map[string]any{"sub": subject, "email": user.Email},
+			query:  callbackQuery,
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, _ db.ConsumeOidcLoginStateParams) (db.OidcLoginState, error) {
+						return *loginState, nil
+					})
+
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{ID: 1, Username: user.Username, Issuer: idp.Issuer(), Subject: subject}, nil)
+
+				store.EXPECT().
+					TouchUserIdentity(gomock.Any(), gomock.Eq(db.TouchUserIdentityParams{ID: 1, Email: user.Email})).
+					Times(1).
+					Return(nil)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				userTotp, _ := RandomUserTotp(t, user.Username, true)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					CreateMfaChallengeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateMfaChallengeParams, _ ...db.TxOption) (db.CreateMfaChallengeTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, "Work laptop", arg.DeviceLabel)
+						return db.CreateMfaChallengeTxResult{}, nil
+					})
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body struct {
+					Data req.MfaChallengeResponse `json:"data"`
+				}
+				require.NoError(t, json.NewDecoder(recorder.Body).Decode(&body))
+				require.True(t, body.Data.MfaRequired)
+				require.NotEmpty(t, body.Data.ChallengeToken)
+			},
+		},
+		{
+			name:   "EmailInUse",
+			claims: map[string]any{"sub": subject, "email": user.Email},
+			query:  callbackQuery,
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, _ db.ConsumeOidcLoginStateParams) (db.OidcLoginState, error) {
+						return *loginState, nil
+					})
+
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateOidcUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateOidcUserTxResult{}, &pgconn.PgError{Code: db.UniqueViolation, ConstraintName: db.UserEmailConstraint})
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidState",
+			claims: map[string]any{"sub": subject, "email": user.Email},
+			query: func(code, state string) url.Values {
+				return url.Values{"code": {code}, "state": {util.RandomString(43)}}
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OidcLoginState{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:          "MissingCookie",
+			claims:        map[string]any{"sub": subject, "email": user.Email},
+			query:         callbackQuery,
+			withoutCookie: true,
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				// The callback of a login begun in another browser never reaches the state
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "AccessDenied",
+			claims: map[string]any{"sub": subject},
+			query: func(code, state string) url.Values {
+				return url.Values{"error": {"access_denied"}, "state": {state}}
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "MissingCode",
+			claims: map[string]any{"sub": subject},
+			query: func(code, state string) url.Values {
+				return url.Values{"state": {state}}
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			var loginState db.OidcLoginState
+
+			store.EXPECT().
+				DeleteExpiredOidcLoginStates(gomock.Any()).
+				Times(1).
+				Return(int64(0), nil)
+
+			store.EXPECT().
+				CreateOidcLoginState(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateOidcLoginStateParams) (db.OidcLoginState, error) {
+					loginState = db.OidcLoginState{
+						ID:           1,
+						StateHash:    arg.StateHash,
+						Nonce:        arg.Nonce,
+						CodeVerifier: arg.CodeVerifier,
+						DeviceLabel:  arg.DeviceLabel,
+						ExpiresAt:    arg.ExpiresAt,
+						BrowserHash:  arg.BrowserHash,
+					}
+					return loginState, nil
+				})
+
+			tc.buildStubs(store, &loginState)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			userHandler.MapRoutes()
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/auth/oidc/login?deviceLabel=Work+laptop", nil)
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var begin struct {
+				Data req.BeginOidcLoginResponse `json:"data"`
+			}
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&begin))
+			require.Equal(t, token.HashToken(begin.Data.State), loginState.StateHash)
+
+			cookies := recorder.Result().Cookies()
+			require.Len(t, cookies, 1)
+			require.Equal(t, oidc.CookieName, cookies[0].Name)
+			require.Equal(t, token.HashToken(cookies[0].Value), loginState.BrowserHash)
+			require.True(t, cookies[0].HttpOnly)
+			require.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+
+			code, err := idp.Authorize(begin.Data.AuthorizationUrl, tc.claims)
+			require.NoError(t, err)
+
+			recorder = httptest.NewRecorder()
+			request, err = http.NewRequest(http.MethodGet, "/auth/oidc/callback?"+tc.query(code, begin.Data.State).Encode(), nil)
+			require.NoError(t, err)
+
+			if !tc.withoutCookie {
+				request.AddCookie(cookies[0])
+			}
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.TokenMaker)
+		})
+	}
+}
+
+func TestOidcLoginNotConfiguredApi(t *testing.T) {
+	cfg, err := pkg.LoadConfig("../../")
 	require.NoError(t, err)
 
-	require.Equal(t, req.UserResponse{
-		UserName:          user.Username,
-		FullName:          user.FullName,
-		Email:             user.Email,
-		PasswordChangedAt: user.PasswordChangedAt.String(),
-		CreatedAt:         user.CreatedAt.String(),
-	}, response.Data)
+	cfg.OidcIssuerUrl = ""
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := server.NewTestServer(t, store, &cfg, nil)
+	userHandler := NewUserHandler(server)
+	userHandler.MapRoutes()
+
+	for _, path := range []string{"/auth/oidc/login", "/auth/oidc/callback?code=code&state=state"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+
+		server.Router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+	}
 }
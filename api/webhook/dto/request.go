@@ -0,0 +1,31 @@
+package webhook
+
+type CreateWebhookRequest struct {
+	Url        string   `json:"url" binding:"required,webhook_url"`
+	EventTypes []string `json:"eventTypes" binding:"required,min=1,dive,webhook_event"`
+}
+
+type WebhookUriRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type ListWebhookRequest struct {
+	Page int32 `form:"page,default=1" binding:"min=1"`
+	Size int32 `form:"size" binding:"required,min=5,max=10"`
+}
+
+type UpdateWebhookRequest struct {
+	Url        *string  `json:"url" binding:"omitempty,webhook_url"`
+	EventTypes []string `json:"eventTypes" binding:"omitempty,min=1,dive,webhook_event"`
+	IsActive   *bool    `json:"isActive"`
+}
+
+type ListWebhookDeliveryRequest struct {
+	Page int32 `form:"page,default=1" binding:"min=1"`
+	Size int32 `form:"size" binding:"required,min=5,max=10"`
+}
+
+type WebhookDeliveryUriRequest struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"deliveryId" binding:"required,min=1"`
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/google/uuid"
+)
+
+// WebhookResponse never includes the signing secret, it is only shown when created or rotated
+type WebhookResponse struct {
+	ID         int64     `json:"id"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	IsActive   bool      `json:"isActive"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type WebhookSecretResponse struct {
+	WebhookResponse
+	Secret                  string     `json:"secret"`
+	PreviousSecretExpiresAt *time.Time `json:"previousSecretExpiresAt,omitempty"`
+}
+
+type ListWebhookResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+	Length   int               `json:"length"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	EventID        uuid.UUID  `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	ResponseStatus *int32     `json:"responseStatus,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type ListWebhookDeliveryResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Length     int                       `json:"length"`
+}
+
+func NewWebhookResponse(endpoint db.WebhookEndpoint) WebhookResponse {
+	return WebhookResponse{
+		ID:         endpoint.ID,
+		Url:        endpoint.Url,
+		EventTypes: endpoint.EventTypes,
+		IsActive:   endpoint.IsActive,
+		CreatedAt:  endpoint.CreatedAt,
+	}
+}
+
+func NewWebhookSecretResponse(endpoint db.WebhookEndpoint) WebhookSecretResponse {
+	response := WebhookSecretResponse{
+		WebhookResponse: NewWebhookResponse(endpoint),
+		Secret:          endpoint.Secret,
+	}
+
+	if endpoint.PreviousSecretExpiresAt.Valid {
+		response.PreviousSecretExpiresAt = &endpoint.PreviousSecretExpiresAt.Time
+	}
+
+	return response
+}
+
+func NewListWebhookResponse(endpoints []db.WebhookEndpoint) ListWebhookResponse {
+	response := ListWebhookResponse{
+		Webhooks: make([]WebhookResponse, 0, len(endpoints)),
+		Length:   len(endpoints),
+	}
+
+	for _, endpoint := range endpoints {
+		response.Webhooks = append(response.Webhooks, NewWebhookResponse(endpoint))
+	}
+
+	return response
+}
+
+func NewWebhookDeliveryResponse(delivery db.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:        delivery.ID,
+		EventID:   delivery.EventID,
+		EventType: delivery.EventType,
+		Status:    delivery.Status,
+		Attempts:  delivery.Attempts,
+		LastError: delivery.LastError.String,
+		CreatedAt: delivery.CreatedAt,
+	}
+
+	if delivery.ResponseStatus.Valid {
+		response.ResponseStatus = &delivery.ResponseStatus.Int32
+	}
+
+	if delivery.DeliveredAt.Valid {
+		response.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+
+	return response
+}
+
+func NewListWebhookDeliveryResponse(deliveries []db.WebhookDelivery) ListWebhookDeliveryResponse {
+	response := ListWebhookDeliveryResponse{
+		Deliveries: make([]WebhookDeliveryResponse, 0, len(deliveries)),
+		Length:     len(deliveries),
+	}
+
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, NewWebhookDeliveryResponse(delivery))
+	}
+
+	return response
+}
@@ -0,0 +1,327 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	dto "github.com/ChokeGuy/simple-bank/api/webhook/dto"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/pkg/webhook"
+	sv "github.com/ChokeGuy/simple-bank/server/http"
+	"github.com/ChokeGuy/simple-bank/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type WebhookHandler struct {
+	*sv.Server
+}
+
+func NewWebhookHandler(server *sv.Server) *WebhookHandler {
+	return &WebhookHandler{Server: server}
+}
+
+func (h *WebhookHandler) MapRoutes() {
+	router := h.Router
+
+	authRoutes := router.Group("/").Use(
+		auth.AuthMiddleWare(h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier),
+		auth.RequirePermission(rbac.WebhookManage),
+	)
+
+	authRoutes.POST("/webhooks", h.createWebhook)
+	authRoutes.GET("/webhooks", h.listWebhooks)
+	authRoutes.GET("/webhooks/:id", h.getWebhook)
+	authRoutes.PATCH("/webhooks/:id", h.updateWebhook)
+	authRoutes.DELETE("/webhooks/:id", h.deleteWebhook)
+	authRoutes.POST("/webhooks/:id/rotate-secret", h.rotateWebhookSecret)
+	authRoutes.GET("/webhooks/:id/deliveries", h.listWebhookDeliveries)
+	authRoutes.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", h.redeliverWebhook)
+}
+
+func (h *WebhookHandler) createWebhook(ctx *gin.Context) {
+	var req dto.CreateWebhookRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	arg := db.CreateWebhookEndpointParams{
+		Owner:      authPayload.UserName,
+		Url:        req.Url,
+		Secret:     secret,
+		EventTypes: normalizeEventTypes(req.EventTypes),
+	}
+
+	endpoint, err := h.Store.CreateWebhookEndpoint(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewWebhookSecretResponse(endpoint), "Webhook created successfully"))
+}
+
+func (h *WebhookHandler) listWebhooks(ctx *gin.Context) {
+	var req dto.ListWebhookRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+
+	arg := db.ListWebhookEndpointsParams{
+		Owner:  authPayload.UserName,
+		Limit:  req.Size,
+		Offset: (req.Page - 1) * req.Size,
+	}
+
+	endpoints, err := h.Store.ListWebhookEndpoints(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewListWebhookResponse(endpoints), "Webhooks retrieved successfully"))
+}
+
+func (h *WebhookHandler) getWebhook(ctx *gin.Context) {
+	endpoint, statusCode, err := h.getOwnedEndpoint(ctx)
+	if err != nil {
+		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewWebhookResponse(endpoint), "Webhook retrieved successfully"))
+}
+
+func (h *WebhookHandler) updateWebhook(ctx *gin.Context) {
+	var req dto.UpdateWebhookRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	endpoint, statusCode, err := h.getOwnedEndpoint(ctx)
+	if err != nil {
+		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
+		return
+	}
+
+	arg := db.UpdateWebhookEndpointParams{
+		ID: endpoint.ID,
+	}
+
+	if req.Url != nil {
+		arg.Url = pgtype.Text{String: *req.Url, Valid: true}
+	}
+
+	if req.EventTypes != nil {
+		arg.EventTypes = normalizeEventTypes(req.EventTypes)
+	}
+
+	if req.IsActive != nil {
+		arg.IsActive = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+	}
+
+	endpoint, err = h.Store.UpdateWebhookEndpoint(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewWebhookResponse(endpoint), "Webhook updated successfully"))
+}
+
+func (h *WebhookHandler) deleteWebhook(ctx *gin.Context) {
+	endpoint, statusCode, err := h.getOwnedEndpoint(ctx)
+	if err != nil {
+		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
+		return
+	}
+
+	err = h.Store.DeleteWebhookEndpoint(ctx, endpoint.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(nil, "Webhook deleted successfully"))
+}
+
+// rotateWebhookSecret issues a new secret, deliveries are signed with the old one as well until the grace period ends
+func (h *WebhookHandler) rotateWebhookSecret(ctx *gin.Context) {
+	endpoint, statusCode, err := h.getOwnedEndpoint(ctx)
+	if err != nil {
+		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
+		return
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	arg := db.RotateWebhookEndpointSecretParams{
+		ID:     endpoint.ID,
+		Secret: secret,
+		PreviousSecretExpiresAt: pgtype.Timestamptz{
+			Time:  time.Now().Add(h.Config.WebhookSecretGrace),
+			Valid: true,
+		},
+	}
+
+	endpoint, err = h.Store.RotateWebhookEndpointSecret(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewWebhookSecretResponse(endpoint), "Webhook secret rotated successfully"))
+}
+
+func (h *WebhookHandler) listWebhookDeliveries(ctx *gin.Context) {
+	var req dto.ListWebhookDeliveryRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	endpoint, statusCode, err := h.getOwnedEndpoint(ctx)
+	if err != nil {
+		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
+		return
+	}
+
+	arg := db.ListWebhookDeliveriesParams{
+		EndpointID: endpoint.ID,
+		Limit:      req.Size,
+		Offset:     (req.Page - 1) * req.Size,
+	}
+
+	deliveries, err := h.Store.ListWebhookDeliveries(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewListWebhookDeliveryResponse(deliveries), "Webhook deliveries retrieved successfully"))
+}
+
+func (h *WebhookHandler) redeliverWebhook(ctx *gin.Context) {
+	var req dto.WebhookDeliveryUriRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	endpoint, statusCode, err := h.getOwnedEndpoint(ctx)
+	if err != nil {
+		ctx.JSON(statusCode, res.ErrorResponse(statusCode, err.Error()))
+		return
+	}
+
+	if !endpoint.IsActive {
+		ctx.JSON(http.StatusBadRequest, res.ErrorResponse(http.StatusBadRequest, "webhook is disabled"))
+		return
+	}
+
+	delivery, err := h.Store.GetWebhookDelivery(ctx, req.DeliveryID)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Webhook delivery not found"))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	if delivery.EndpointID != endpoint.ID {
+		ctx.JSON(http.StatusNotFound, res.ErrorResponse(http.StatusNotFound, "Webhook delivery not found"))
+		return
+	}
+
+	if delivery.Status == worker.WebhookDeliveryPending {
+		ctx.JSON(http.StatusConflict, res.ErrorResponse(http.StatusConflict, "webhook delivery is still in progress"))
+		return
+	}
+
+	arg := db.RedeliverWebhookTxParams{
+		DeliveryID: delivery.ID,
+		AfterReset: func(q db.Querier, reset db.WebhookDelivery) error {
+			return worker.EnqueueTaskDeliverWebhook(ctx, q, &worker.PayloadDeliverWebhook{DeliveryID: reset.ID}, worker.OutboxOptions{
+				MaxRetry: h.Config.WebhookMaxRetry,
+			})
+		},
+	}
+
+	result, err := h.Store.RedeliverWebhookTx(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res.SuccessResponse(dto.NewWebhookDeliveryResponse(result.Delivery), "Webhook redelivery scheduled successfully"))
+}
+
+// getOwnedEndpoint loads the endpoint of the :id path parameter, it must belong to the authenticated user
+func (h *WebhookHandler) getOwnedEndpoint(ctx *gin.Context) (db.WebhookEndpoint, int, error) {
+	var req dto.WebhookUriRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		return db.WebhookEndpoint{}, http.StatusBadRequest, err
+	}
+
+	endpoint, err := h.Store.GetWebhookEndpoint(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.WebhookEndpoint{}, http.StatusNotFound, fmt.Errorf("webhook %d not found", req.ID)
+		}
+		return db.WebhookEndpoint{}, http.StatusInternalServerError, err
+	}
+
+	authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	if !rbac.CanAccess(authPayload, endpoint.Owner) {
+		return db.WebhookEndpoint{}, http.StatusUnauthorized, fmt.Errorf("webhook does not belong to the authenticated user")
+	}
+
+	return endpoint, http.StatusOK, nil
+}
+
+// normalizeEventTypes drops duplicate subscriptions
+func normalizeEventTypes(eventTypes []string) []string {
+	eventTypes = slices.Clone(eventTypes)
+	slices.Sort(eventTypes)
+	return slices.Compact(eventTypes)
+}
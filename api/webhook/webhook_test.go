@@ -0,0 +1,537 @@
+package webhook
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/api/user"
+	req "github.com/ChokeGuy/simple-bank/api/webhook/dto"
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/pkg/webhook"
+	server "github.com/ChokeGuy/simple-bank/server/http"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/ChokeGuy/simple-bank/worker"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func RandomWebhookEndpoint(t *testing.T, owner string) db.WebhookEndpoint {
+	secret, err := webhook.GenerateSecret()
+	require.NoError(t, err)
+
+	return db.WebhookEndpoint{
+		ID:         util.RandomInt(1, 1000),
+		Owner:      owner,
+		Url:        "https://example.com/" + util.RandomString(6),
+		Secret:     secret,
+		EventTypes: []string{webhook.EventTransferCompleted},
+		IsActive:   true,
+	}
+}
+
+func RandomWebhookDelivery(endpoint db.WebhookEndpoint, status string) db.WebhookDelivery {
+	return db.WebhookDelivery{
+		ID:         util.RandomInt(1, 1000),
+		EndpointID: endpoint.ID,
+		EventID:    uuid.New(),
+		EventType:  webhook.EventTransferCompleted,
+		Payload:    []byte(`{}`),
+		Status:     status,
+		Attempts:   1,
+	}
+}
+
+// TestCreateWebhookApi tests the CreateWebhook API handler
+func TestCreateWebhookApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+	endpoint := RandomWebhookEndpoint(t, user.Username)
+
+	testCases := []struct {
+		name          string
+		body          req.CreateWebhookRequest
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: req.CreateWebhookRequest{
+				Url:        endpoint.Url,
+				EventTypes: []string{webhook.EventTransferCompleted, webhook.EventTransferCompleted},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateWebhookEndpointParams{
+					Owner:      user.Username,
+					Url:        endpoint.Url,
+					EventTypes: []string{webhook.EventTransferCompleted},
+				}
+
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), EqCreateWebhookEndpointParams(arg)).
+					Times(1).
+					Return(endpoint, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchWebhookSecret(t, recorder.Body, endpoint)
+			},
+		},
+		{
+			name: "InvalidEventType",
+			body: req.CreateWebhookRequest{
+				Url:        endpoint.Url,
+				EventTypes: []string{"account.created"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidUrl",
+			body: req.CreateWebhookRequest{
+				Url:        "ftp://example.com/hook",
+				EventTypes: []string{webhook.EventTransferCompleted},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PlainHttpUrl",
+			body: req.CreateWebhookRequest{
+				Url:        "http://example.com/hook",
+				EventTypes: []string{webhook.EventTransferCompleted},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PrivateAddressUrl",
+			body: req.CreateWebhookRequest{
+				Url:        "https://169.254.169.254/latest/meta-data",
+				EventTypes: []string{webhook.EventTransferCompleted},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LocalhostUrl",
+			body: req.CreateWebhookRequest{
+				Url:        "https://localhost:8080/hook",
+				EventTypes: []string{webhook.EventTransferCompleted},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: req.CreateWebhookRequest{
+				Url:        endpoint.Url,
+				EventTypes: []string{webhook.EventTransferCompleted},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: req.CreateWebhookRequest{
+				Url:        endpoint.Url,
+				EventTypes: []string{webhook.EventTransferCompleted},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.WebhookEndpoint{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serveWebhookRequest(t, http.MethodPost, "/webhooks", tc.body, tc.setupAuth, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestRotateWebhookSecretApi tests the RotateWebhookSecret API handler
+func TestRotateWebhookSecretApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+	endpoint := RandomWebhookEndpoint(t, user.Username)
+
+	rotated := endpoint
+	rotated.Secret = "whsec_" + util.RandomString(64)
+	rotated.PreviousSecret.String, rotated.PreviousSecret.Valid = endpoint.Secret, true
+	rotated.PreviousSecretExpiresAt.Time, rotated.PreviousSecretExpiresAt.Valid = time.Now().Add(24*time.Hour).UTC(), true
+
+	testCases := []struct {
+		name          string
+		endpointID    int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			endpointID: endpoint.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(endpoint, nil)
+
+				store.EXPECT().
+					RotateWebhookEndpointSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RotateWebhookEndpointSecretParams) (db.WebhookEndpoint, error) {
+						require.Equal(t, endpoint.ID, arg.ID)
+						require.NotEqual(t, endpoint.Secret, arg.Secret)
+						require.True(t, arg.PreviousSecretExpiresAt.Time.After(time.Now()))
+						return rotated, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchWebhookSecret(t, recorder.Body, rotated)
+			},
+		},
+		{
+			name:       "UnAuthorizedUser",
+			endpointID: endpoint.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, "unauthorized_user", user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(endpoint, nil)
+
+				store.EXPECT().
+					RotateWebhookEndpointSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			endpointID: endpoint.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(db.WebhookEndpoint{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "BadRequest",
+			endpointID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			url := fmt.Sprintf("/webhooks/%d/rotate-secret", tc.endpointID)
+			recorder := serveWebhookRequest(t, http.MethodPost, url, nil, tc.setupAuth, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestRedeliverWebhookApi tests the RedeliverWebhook API handler
+func TestRedeliverWebhookApi(t *testing.T) {
+	user, _ := user.RandomUser(t)
+	endpoint := RandomWebhookEndpoint(t, user.Username)
+	failed := RandomWebhookDelivery(endpoint, worker.WebhookDeliveryFailed)
+	pending := RandomWebhookDelivery(endpoint, worker.WebhookDeliveryPending)
+
+	otherEndpoint := RandomWebhookEndpoint(t, user.Username)
+	otherEndpoint.ID = endpoint.ID + 1
+	foreign := RandomWebhookDelivery(otherEndpoint, worker.WebhookDeliveryFailed)
+
+	testCases := []struct {
+		name          string
+		delivery      db.WebhookDelivery
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			delivery: failed,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(endpoint, nil)
+
+				store.EXPECT().
+					GetWebhookDelivery(gomock.Any(), gomock.Eq(failed.ID)).
+					Times(1).
+					Return(failed, nil)
+
+				reset := failed
+				reset.Status = worker.WebhookDeliveryPending
+
+				store.EXPECT().
+					RedeliverWebhookTx(gomock.Any(), EqRedeliverWebhookTxParams(failed)).
+					Times(1).
+					Return(db.RedeliverWebhookTxResult{Delivery: reset}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "StillPending",
+			delivery: pending,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(endpoint, nil)
+
+				store.EXPECT().
+					GetWebhookDelivery(gomock.Any(), gomock.Eq(pending.ID)).
+					Times(1).
+					Return(pending, nil)
+
+				store.EXPECT().
+					RedeliverWebhookTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "DeliveryOfOtherWebhook",
+			delivery: foreign,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(endpoint, nil)
+
+				store.EXPECT().
+					GetWebhookDelivery(gomock.Any(), gomock.Eq(foreign.ID)).
+					Times(1).
+					Return(foreign, nil)
+
+				store.EXPECT().
+					RedeliverWebhookTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			delivery: failed,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				auth.AddAuthorization(t, request, tokenMaker, auth.AuthTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(endpoint, nil)
+
+				store.EXPECT().
+					GetWebhookDelivery(gomock.Any(), gomock.Eq(failed.ID)).
+					Times(1).
+					Return(failed, nil)
+
+				store.EXPECT().
+					RedeliverWebhookTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RedeliverWebhookTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			url := fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", endpoint.ID, tc.delivery.ID)
+			recorder := serveWebhookRequest(t, http.MethodPost, url, nil, tc.setupAuth, tc.buildStubs)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// serveWebhookRequest runs a single request against a test server with the webhook routes
+func serveWebhookRequest(
+	t *testing.T,
+	method string,
+	url string,
+	body any,
+	setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker),
+	buildStubs func(store *mockdb.MockStore),
+) *httptest.ResponseRecorder {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	//build stubs
+	buildStubs(store)
+
+	//start new server
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	server := server.NewTestServer(t, store, &cfg, nil)
+	webhookHandler := NewWebhookHandler(server)
+	webhookHandler.MapRoutes()
+	recorder := httptest.NewRecorder()
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reqBody = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, url, reqBody)
+	require.NoError(t, err)
+
+	setupAuth(t, request, server.TokenMaker)
+	server.Router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// requireBodyMatchWebhookSecret checks if the response body matches the endpoint and reveals its secret
+func requireBodyMatchWebhookSecret(t *testing.T, body *bytes.Buffer, endpoint db.WebhookEndpoint) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var response struct {
+		Data       req.WebhookSecretResponse `json:"data"`
+		Message    string                    `json:"message"`
+		StatusCode int                       `json:"statusCode"`
+	}
+
+	err = json.Unmarshal(data, &response)
+	require.NoError(t, err)
+
+	require.Equal(t, endpoint.ID, response.Data.ID)
+	require.Equal(t, endpoint.Url, response.Data.Url)
+	require.Equal(t, endpoint.EventTypes, response.Data.EventTypes)
+	require.Equal(t, endpoint.Secret, response.Data.Secret)
+	require.Equal(t, endpoint.PreviousSecretExpiresAt.Valid, response.Data.PreviousSecretExpiresAt != nil)
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/worker"
+	"github.com/golang/mock/gomock"
+)
+
+// Custom matcher for CreateWebhookEndpointParams, the secret is generated by the handler
+type eqCreateWebhookEndpointParamsMatcher struct {
+	arg db.CreateWebhookEndpointParams
+}
+
+func (e eqCreateWebhookEndpointParamsMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.CreateWebhookEndpointParams)
+	if !ok {
+		return false
+	}
+
+	if !strings.HasPrefix(actualArg.Secret, "whsec_") {
+		return false
+	}
+
+	return actualArg.Owner == e.arg.Owner &&
+		actualArg.Url == e.arg.Url &&
+		reflect.DeepEqual(actualArg.EventTypes, e.arg.EventTypes)
+}
+
+func (e eqCreateWebhookEndpointParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v with a generated secret", e.arg)
+}
+
+func EqCreateWebhookEndpointParams(arg db.CreateWebhookEndpointParams) gomock.Matcher {
+	return eqCreateWebhookEndpointParamsMatcher{arg}
+}
+
+type eqRedeliverWebhookTxParamsMatcher struct {
+	delivery db.WebhookDelivery
+}
+
+func (e eqRedeliverWebhookTxParamsMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.RedeliverWebhookTxParams)
+	if !ok || actualArg.DeliveryID != e.delivery.ID {
+		return false
+	}
+
+	// AfterReset must schedule the delivery through the outbox of the transaction
+	outbox := &outboxRecorder{}
+	if err := actualArg.AfterReset(outbox, e.delivery); err != nil {
+		return false
+	}
+
+	if len(outbox.messages) != 1 || outbox.messages[0].TaskType != worker.TaskDeliverWebhook {
+		return false
+	}
+
+	var payload worker.PayloadDeliverWebhook
+	if err := json.Unmarshal(outbox.messages[0].Payload, &payload); err != nil {
+		return false
+	}
+
+	return payload.DeliveryID == e.delivery.ID
+}
+
+func (e eqRedeliverWebhookTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches delivery id %v", e.delivery.ID)
+}
+
+func EqRedeliverWebhookTxParams(delivery db.WebhookDelivery) gomock.Matcher {
+	return eqRedeliverWebhookTxParamsMatcher{delivery}
+}
+
+// outboxRecorder stands in for the transaction Querier and records outbox messages
+type outboxRecorder struct {
+	db.Querier
+	messages []db.CreateOutboxMessageParams
+}
+
+func (r *outboxRecorder) CreateOutboxMessage(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+	r.messages = append(r.messages, arg)
+	return db.OutboxMessage{}, nil
+}
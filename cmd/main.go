@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"expvar"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
@@ -18,8 +20,10 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/ChokeGuy/simple-bank/api/account"
+	"github.com/ChokeGuy/simple-bank/api/admin"
 	"github.com/ChokeGuy/simple-bank/api/transfer"
 	"github.com/ChokeGuy/simple-bank/api/user"
+	"github.com/ChokeGuy/simple-bank/api/webhook"
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	_ "github.com/ChokeGuy/simple-bank/doc/statik"
 	grpcapi "github.com/ChokeGuy/simple-bank/grpc-api"
@@ -27,16 +31,21 @@ import (
 	cf "github.com/ChokeGuy/simple-bank/pkg/config"
 	dbmigrations "github.com/ChokeGuy/simple-bank/pkg/db-migrations"
 	"github.com/ChokeGuy/simple-bank/pkg/logger"
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/throttle"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/pkg/token/jwt"
 	"github.com/ChokeGuy/simple-bank/pkg/token/paseto"
 	grpcSv "github.com/ChokeGuy/simple-bank/server/grpc"
 	httpSv "github.com/ChokeGuy/simple-bank/server/http"
+	"github.com/ChokeGuy/simple-bank/util"
 	"github.com/ChokeGuy/simple-bank/worker"
 	"github.com/gin-gonic/gin"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -68,7 +77,9 @@ func main() {
 
 	dbmigrations.RunDBMigration(cf)
 	store := db.NewStore(conn)
-	tokenMaker, err := paseto.NewPasetoMaker(cf.SymetricKey)
+	loadCurrencies(ctx, store)
+
+	tokenMaker, err := newTokenMaker(cf)
 	if err != nil {
 		log.Fatal().Msgf("Token maker err: %v", err)
 	}
@@ -91,11 +102,18 @@ func main() {
 
 	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
 
+	// Failed login attempts are counted in Redis so every instance enforces the same lockout
+	redisClient := redisOpt.MakeRedisClient().(redis.UniversalClient)
+	defer redisClient.Close()
+	limiter := throttle.NewRedisLimiter(redisClient, throttle.NewPolicies(&cf))
+
 	waitGroup, ctx := errgroup.WithContext(ctx)
 
-	worker.RunTaskProcessor(ctx, waitGroup, redisOpt, store)
-	runHttpServer(ctx, waitGroup, cf, store, tokenMaker, taskDistributor)
-	runGrpcServer(ctx, waitGroup, cf, store, tokenMaker, taskDistributor)
+	worker.RunTaskProcessor(ctx, waitGroup, redisOpt, store, cf)
+	worker.RunOutboxRelay(ctx, waitGroup, cf, store, taskDistributor)
+	runHttpServer(ctx, waitGroup, cf, store, tokenMaker, taskDistributor, limiter)
+	runGrpcServer(ctx, waitGroup, cf, store, tokenMaker, taskDistributor, limiter)
+	runMetricsServer(ctx, waitGroup, cf)
 
 	err = waitGroup.Wait()
 	if err != nil {
@@ -103,12 +121,64 @@ func main() {
 	}
 }
 
+// newTokenMaker creates the token maker chosen by TOKEN_MAKER, the asymmetric makers sign with
+// the key TOKEN_SIGNING_KEY_ID of TOKEN_KEYS_DIR and verify with every key of that directory
+func newTokenMaker(cfg cf.Config) (token.Maker, error) {
+	switch cfg.TokenMaker {
+	case "paseto":
+		return paseto.NewPasetoMaker(cfg.SymetricKey)
+	case "paseto_public", "jwt":
+		keyring, err := token.LoadKeyring(cfg.TokenKeysDir, cfg.TokenSigningKeyID)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Info().Msgf("signing tokens with key %s, %d keys verify", cfg.TokenSigningKeyID, len(keyring.Keys()))
+
+		if cfg.TokenMaker == "jwt" {
+			return jwt.NewKeyringMaker(keyring)
+		}
+		return paseto.NewPasetoPublicMaker(keyring)
+	default:
+		return nil, fmt.Errorf("unknown token maker %q", cfg.TokenMaker)
+	}
+}
+
+// loadCurrencies replaces the built-in currency registry with the one stored in the database
+func loadCurrencies(ctx context.Context, store db.Store) {
+	currencies, err := store.ListCurrencies(ctx)
+	if err != nil {
+		log.Fatal().Msgf("cannot load currencies: %v", err)
+	}
+
+	if len(currencies) == 0 {
+		log.Warn().Msg("no currencies in the database, using the built-in defaults")
+		return
+	}
+
+	registry := make([]util.Currency, 0, len(currencies))
+	for _, currency := range currencies {
+		registry = append(registry, util.Currency{
+			Code:     currency.Code,
+			Exponent: currency.Exponent,
+			Symbol:   currency.Symbol,
+			Enabled:  currency.Enabled,
+		})
+	}
+
+	util.Currencies.Replace(registry)
+	log.Info().Msgf("loaded %d currencies", len(registry))
+}
+
 // setUpRouter set up all routes
 func setUpRouter(server *httpSv.Server) {
 	server.Router.GET("", func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, "Welcome to Simple Bank")
 	})
 
+	// Publish the keys access tokens are verified with
+	server.Router.GET("/.well-known/jwks.json", gin.WrapH(token.JWKSHandler(server.TokenMaker)))
+
 	// Setup CORS
 	corsConfig := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
@@ -140,6 +210,14 @@ func mapAPIRoutes(server *httpSv.Server) {
 	// Account routes
 	accountHandler := account.NewAccountHandler(server)
 	accountHandler.MapRoutes()
+
+	// Webhook routes
+	webhookHandler := webhook.NewWebhookHandler(server)
+	webhookHandler.MapRoutes()
+
+	// Back-office routes
+	adminHandler := admin.NewAdminHandler(server)
+	adminHandler.MapRoutes()
 }
 
 // runHttpServer run http server
@@ -150,8 +228,9 @@ func runHttpServer(
 	store db.Store,
 	tokenMaker token.Maker,
 	taskDistributor worker.TaskDistributor,
+	limiter throttle.Limiter,
 ) {
-	server, err := httpSv.NewServer(store, &cfg, tokenMaker, taskDistributor)
+	server, err := httpSv.NewServer(store, &cfg, tokenMaker, taskDistributor, limiter)
 	if err != nil {
 		log.Fatal().Msgf("cannot create HTTP server: %v", err)
 	}
@@ -184,24 +263,32 @@ func runGrpcServer(
 	store db.Store,
 	tokenMaker token.Maker,
 	taskDistributor worker.TaskDistributor,
+	limiter throttle.Limiter,
 ) {
-	server, err := grpcSv.NewServer(store, &cfg, tokenMaker, taskDistributor)
+	server, err := grpcSv.NewServer(store, &cfg, tokenMaker, taskDistributor, limiter)
 	if err != nil {
 		log.Fatal().Msgf("cannot create gRPC server: %v", err)
 	}
 
 	serviceHandler := grpcapi.NewServiceHandler(server)
+	adminHandler := grpcapi.NewAdminServiceHandler(server)
 
 	server.Listener, err = net.Listen("tcp", server.Config.GrpcServerAddress)
 	if err != nil {
 		log.Fatal().Msgf("cannot listen on %s: %v", server.Config.GrpcServerAddress, err)
 	}
 
-	grpcLogger := grpc.UnaryInterceptor(logger.GrpcLogger)
-	server.GrpcServer = grpc.NewServer(grpcLogger)
+	interceptors := grpc.ChainUnaryInterceptor(
+		logger.GrpcLogger,
+		auth.UnaryClientIPInterceptor(server.ClientIP),
+		auth.UnaryAuthInterceptor(server.TokenMaker, server.SessionChecker, server.ApiKeyVerifier, grpcapi.MethodPermissions),
+		auth.UnaryStepUpInterceptor(server.StepUpPolicy, grpcapi.MethodsRequiringStepUp),
+	)
+	server.GrpcServer = grpc.NewServer(interceptors)
 
 	// Register service handler
 	pb.RegisterSimpleBankServer(server.GrpcServer, serviceHandler)
+	pb.RegisterSimpleBankAdminServer(server.GrpcServer, adminHandler)
 	reflection.Register(server.GrpcServer)
 
 	waitGroup.Go(func() error {
@@ -214,6 +301,46 @@ func runGrpcServer(
 	})
 }
 
+// runMetricsServer serves the runtime and transaction retry metrics on their own listener, they
+// reveal internals and must not be reachable through the public router
+func runMetricsServer(ctx context.Context, waitGroup *errgroup.Group, cfg cf.Config) {
+	if cfg.MetricsServerAddress == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	metricsServer := &http.Server{
+		Handler:      mux,
+		Addr:         cfg.MetricsServerAddress,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+
+	waitGroup.Go(func() error {
+		log.Info().Msgf("starting metrics server on %s", cfg.MetricsServerAddress)
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("shutting down metrics server")
+		err := metricsServer.Shutdown(context.Background())
+
+		if err != nil {
+			log.Error().Err(err).Msg("Fail to shutdown metrics server")
+			return err
+		}
+
+		log.Info().Msg("metrics server shutdown is complete")
+		return nil
+	})
+}
+
 // runGatewayServer run grpc-gateway server
 func runGatewayServer(
 	ctx context.Context,
@@ -222,13 +349,15 @@ func runGatewayServer(
 	store db.Store,
 	tokenMaker token.Maker,
 	taskDistributor worker.TaskDistributor,
+	limiter throttle.Limiter,
 ) {
-	server, err := grpcSv.NewServer(store, &cfg, tokenMaker, taskDistributor)
+	server, err := grpcSv.NewServer(store, &cfg, tokenMaker, taskDistributor, limiter)
 	if err != nil {
 		log.Fatal().Msgf("cannot create gateway server: %v", err)
 	}
 
 	serviceHandler := grpcapi.NewServiceHandler(server)
+	adminHandler := grpcapi.NewAdminServiceHandler(server)
 
 	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
@@ -239,15 +368,21 @@ func runGatewayServer(
 		},
 	})
 
-	grpcMux := runtime.NewServeMux(jsonOption)
+	grpcMux := runtime.NewServeMux(jsonOption, runtime.WithOutgoingHeaderMatcher(grpcapi.OutgoingHeaderMatcher))
 
 	err = pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, serviceHandler)
 	if err != nil {
 		log.Fatal().Msgf("cannot register handler server: %v", err)
 	}
 
+	err = pb.RegisterSimpleBankAdminHandlerServer(ctx, grpcMux, adminHandler)
+	if err != nil {
+		log.Fatal().Msgf("cannot register admin handler server: %v", err)
+	}
+
 	mux := http.NewServeMux()
-	mux.Handle("/", grpcMux)
+	mux.Handle("/", server.ClientIP.Handler(grpcMux))
+	mux.Handle("/.well-known/jwks.json", token.JWKSHandler(server.TokenMaker))
 
 	statikFS, err := fs.New()
 	if err != nil {
@@ -1,6 +1,7 @@
 package consts
 
 const (
-	AuthorizationHeader = "authorization"
-	AuthorizationType   = "bearer"
+	AuthorizationHeader     = "authorization"
+	AuthorizationType       = "bearer"
+	AuthorizationTypeApiKey = "apikey"
 )
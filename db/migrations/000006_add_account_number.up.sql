@@ -0,0 +1,23 @@
+ALTER TABLE "accounts"
+ADD COLUMN "account_number" varchar UNIQUE;
+
+-- Backfill existing accounts with a 10 digit body followed by ISO 7064 MOD 97-10
+-- check digits (the scheme used by IBAN). The body is derived from the id so it
+-- cannot collide: multiplying by a number coprime to 10 is a bijection modulo
+-- 10^10, which also keeps neighbouring accounts from getting sequential numbers.
+UPDATE "accounts"
+SET
+    "account_number" = body || lpad((98 - (body::numeric * 100) % 97)::text, 2, '0')
+FROM
+    (
+        SELECT
+            id AS account_id,
+            lpad(((id::numeric * 3367900313) % 10000000000)::text, 10, '0') AS body
+        FROM
+            "accounts"
+    ) AS generated
+WHERE
+    "accounts"."id" = generated.account_id;
+
+ALTER TABLE "accounts"
+ALTER COLUMN "account_number" SET NOT NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE "currencies" (
+    "code" varchar(3) PRIMARY KEY,
+    "exponent" integer NOT NULL,
+    "symbol" varchar NOT NULL,
+    "enabled" boolean NOT NULL DEFAULT true,
+    CONSTRAINT "currencies_exponent_check" CHECK ("exponent" BETWEEN 0 AND 4)
+);
+
+INSERT INTO
+    "currencies" ("code", "exponent", "symbol")
+VALUES ('USD', 2, '$'),
+    ('EUR', 2, '€'),
+    ('CAD', 2, 'CA$'),
+    ('VND', 0, '₫');
+
+ALTER TABLE "accounts"
+ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
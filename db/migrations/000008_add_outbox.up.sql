@@ -0,0 +1,21 @@
+CREATE TABLE
+    "outbox_messages" (
+        "id" bigserial PRIMARY KEY,
+        "aggregate_type" varchar NOT NULL,
+        "aggregate_id" varchar NOT NULL,
+        "task_type" varchar NOT NULL,
+        "payload" jsonb NOT NULL,
+        "queue" varchar NOT NULL,
+        "max_retry" integer NOT NULL,
+        "process_at" timestamptz NOT NULL DEFAULT (now ()),
+        "attempts" integer NOT NULL DEFAULT 0,
+        "last_error" varchar,
+        "processed_at" timestamptz,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE INDEX ON "outbox_messages" ("id")
+WHERE
+    "processed_at" IS NULL;
+
+CREATE INDEX ON "outbox_messages" ("processed_at");
@@ -0,0 +1,36 @@
+CREATE TABLE
+    "webhook_endpoints" (
+        "id" bigserial PRIMARY KEY,
+        "owner" varchar NOT NULL,
+        "url" varchar NOT NULL,
+        "secret" varchar NOT NULL,
+        "previous_secret" varchar,
+        "previous_secret_expires_at" timestamptz,
+        "event_types" varchar[] NOT NULL,
+        "is_active" boolean NOT NULL DEFAULT true,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE TABLE
+    "webhook_deliveries" (
+        "id" bigserial PRIMARY KEY,
+        "endpoint_id" bigint NOT NULL,
+        "event_id" uuid NOT NULL,
+        "event_type" varchar NOT NULL,
+        "payload" jsonb NOT NULL,
+        "status" varchar NOT NULL DEFAULT 'pending',
+        "attempts" integer NOT NULL DEFAULT 0,
+        "response_status" integer,
+        "response_body" varchar,
+        "last_error" varchar,
+        "delivered_at" timestamptz,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE INDEX ON "webhook_endpoints" ("owner");
+
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("endpoint_id", "event_id");
+
+ALTER TABLE "webhook_endpoints" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("endpoint_id") REFERENCES "webhook_endpoints" ("id") ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "sessions_username_idx";
+
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "last_seen_at";
+
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "device_label";
@@ -0,0 +1,7 @@
+ALTER TABLE "sessions"
+ADD COLUMN "device_label" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "sessions"
+ADD COLUMN "last_seen_at" timestamptz NOT NULL DEFAULT (now ());
+
+CREATE INDEX ON "sessions" ("username");
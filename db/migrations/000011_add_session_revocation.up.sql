@@ -0,0 +1,2 @@
+ALTER TABLE "sessions"
+ADD COLUMN "revoked_at" timestamptz;
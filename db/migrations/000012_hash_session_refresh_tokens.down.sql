@@ -0,0 +1,4 @@
+-- Hashed refresh tokens cannot be restored, every user has to log in again
+DELETE FROM "sessions";
+
+COMMENT ON COLUMN "sessions"."refresh_token" IS NULL;
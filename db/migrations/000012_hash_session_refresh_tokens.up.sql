@@ -0,0 +1,6 @@
+-- Sessions store the SHA-256 hash of the current refresh token instead of the token itself
+UPDATE "sessions"
+SET
+    "refresh_token" = encode(sha256(convert_to("refresh_token", 'UTF8')), 'hex');
+
+COMMENT ON COLUMN "sessions"."refresh_token" IS 'SHA-256 hex digest of the current refresh token';
@@ -0,0 +1,43 @@
+CREATE TABLE
+    "user_totps" (
+        "username" varchar PRIMARY KEY,
+        "secret_ciphertext" bytea NOT NULL,
+        "last_used_step" bigint NOT NULL DEFAULT 0,
+        "confirmed_at" timestamptz,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE TABLE
+    "recovery_codes" (
+        "id" bigserial PRIMARY KEY,
+        "username" varchar NOT NULL,
+        "code_hash" varchar NOT NULL,
+        "used_at" timestamptz,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE TABLE
+    "mfa_challenges" (
+        "id" bigserial PRIMARY KEY,
+        "username" varchar NOT NULL,
+        "token_hash" varchar NOT NULL,
+        "device_label" varchar NOT NULL DEFAULT '',
+        "attempts" integer NOT NULL DEFAULT 0,
+        "expires_at" timestamptz NOT NULL,
+        "consumed_at" timestamptz,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("username", "code_hash");
+
+CREATE UNIQUE INDEX ON "mfa_challenges" ("token_hash");
+
+COMMENT ON COLUMN "user_totps"."secret_ciphertext" IS 'AES-GCM encrypted TOTP secret';
+
+COMMENT ON COLUMN "user_totps"."last_used_step" IS 'time step of the last accepted code, older or equal codes are rejected';
+
+ALTER TABLE "user_totps" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "mfa_challenges" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,16 @@
+CREATE TABLE
+    "password_resets" (
+        "id" bigserial PRIMARY KEY,
+        "username" varchar NOT NULL,
+        "email" varchar NOT NULL,
+        "token_hash" varchar NOT NULL,
+        "is_used" bool NOT NULL DEFAULT false,
+        "created_at" timestamptz NOT NULL DEFAULT (now ()),
+        "expired_at" timestamptz NOT NULL DEFAULT (now () + interval '15 minutes')
+    );
+
+CREATE UNIQUE INDEX ON "password_resets" ("token_hash");
+
+COMMENT ON COLUMN "password_resets"."token_hash" IS 'SHA-256 hex digest of the reset token';
+
+ALTER TABLE "password_resets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
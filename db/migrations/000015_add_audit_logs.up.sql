@@ -0,0 +1,22 @@
+CREATE TABLE
+    "audit_logs" (
+        "id" bigserial PRIMARY KEY,
+        "actor" varchar NOT NULL,
+        "action" varchar NOT NULL,
+        "target" varchar NOT NULL,
+        "metadata" jsonb NOT NULL DEFAULT '{}',
+        "client_ip" varchar NOT NULL DEFAULT '',
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE INDEX ON "audit_logs" ("actor");
+
+CREATE INDEX ON "audit_logs" ("target");
+
+CREATE INDEX ON "audit_logs" ("created_at");
+
+COMMENT ON COLUMN "audit_logs"."actor" IS 'username of the banker who performed the action';
+
+COMMENT ON COLUMN "audit_logs"."target" IS 'username or session id the action applies to';
+
+ALTER TABLE "audit_logs" ADD FOREIGN KEY ("actor") REFERENCES "users" ("username");
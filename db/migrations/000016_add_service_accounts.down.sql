@@ -0,0 +1,9 @@
+COMMENT ON COLUMN "audit_logs"."actor" IS 'username of the banker who performed the action';
+
+DELETE FROM "audit_logs" WHERE "actor" NOT IN (SELECT "username" FROM "users");
+
+ALTER TABLE "audit_logs" ADD FOREIGN KEY ("actor") REFERENCES "users" ("username");
+
+DROP TABLE IF EXISTS "api_keys";
+
+DROP TABLE IF EXISTS "service_accounts";
@@ -0,0 +1,35 @@
+CREATE TABLE
+    "service_accounts" (
+        "name" varchar PRIMARY KEY,
+        "role" varchar NOT NULL,
+        "description" varchar NOT NULL DEFAULT '',
+        "created_by" varchar NOT NULL,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE TABLE
+    "api_keys" (
+        "id" uuid PRIMARY KEY,
+        "service_account" varchar NOT NULL,
+        "key_prefix" varchar NOT NULL,
+        "key_hash" varchar UNIQUE NOT NULL,
+        "scopes" varchar[] NOT NULL,
+        "allowed_ips" varchar[] NOT NULL DEFAULT '{}',
+        "expires_at" timestamptz,
+        "revoked_at" timestamptz,
+        "created_by" varchar NOT NULL,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE INDEX ON "api_keys" ("service_account");
+
+COMMENT ON COLUMN "api_keys"."key_hash" IS 'SHA-256 of the key, the key itself is only shown once';
+
+COMMENT ON COLUMN "api_keys"."allowed_ips" IS 'IPs or CIDR ranges the key may be used from, empty allows any';
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("service_account") REFERENCES "service_accounts" ("name");
+
+-- Service accounts act through the back-office API too, so actors are no longer only users
+ALTER TABLE "audit_logs" DROP CONSTRAINT IF EXISTS "audit_logs_actor_fkey";
+
+COMMENT ON COLUMN "audit_logs"."actor" IS 'username of the banker or principal of the service account who performed the action';
@@ -0,0 +1,13 @@
+CREATE TABLE
+    "password_histories" (
+        "id" bigserial PRIMARY KEY,
+        "username" varchar NOT NULL,
+        "hashed_password" varchar NOT NULL,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE INDEX ON "password_histories" ("username", "id");
+
+COMMENT ON TABLE "password_histories" IS 'hashes of passwords replaced by a change or a reset, to block their reuse';
+
+ALTER TABLE "password_histories" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
@@ -0,0 +1,28 @@
+CREATE TABLE
+    "login_events" (
+        "id" bigserial PRIMARY KEY,
+        "username" varchar NOT NULL,
+        "session_id" uuid,
+        "success" bool NOT NULL,
+        "failure_reason" varchar NOT NULL DEFAULT '',
+        "user_agent" varchar NOT NULL,
+        "client_ip" varchar NOT NULL,
+        "new_device" bool NOT NULL DEFAULT false,
+        "deny_token_hash" varchar,
+        "denied_at" timestamptz,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE INDEX ON "login_events" ("username", "id");
+
+CREATE UNIQUE INDEX ON "login_events" ("deny_token_hash");
+
+COMMENT ON TABLE "login_events" IS 'successful and failed logins shown to their user';
+
+COMMENT ON COLUMN "login_events"."new_device" IS 'the login came from a user agent or an address the user never logged in from';
+
+COMMENT ON COLUMN "login_events"."deny_token_hash" IS 'SHA-256 hex digest of the token of the "this wasn''t me" link of the new device alert';
+
+ALTER TABLE "login_events" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "login_events" ADD FOREIGN KEY ("session_id") REFERENCES "sessions" ("id") ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "kyc_reviews";
+
+ALTER TABLE "users"
+DROP CONSTRAINT IF EXISTS "users_kyc_status_check",
+DROP COLUMN "date_of_birth",
+DROP COLUMN "phone",
+DROP COLUMN "address",
+DROP COLUMN "nationality",
+DROP COLUMN "kyc_status";
@@ -0,0 +1,32 @@
+ALTER TABLE "users"
+ADD COLUMN "date_of_birth" date,
+ADD COLUMN "phone" varchar NOT NULL DEFAULT '',
+ADD COLUMN "address" varchar NOT NULL DEFAULT '',
+ADD COLUMN "nationality" varchar NOT NULL DEFAULT '',
+ADD COLUMN "kyc_status" varchar NOT NULL DEFAULT 'unverified';
+
+ALTER TABLE "users"
+ADD CONSTRAINT "users_kyc_status_check" CHECK ("kyc_status" IN ('unverified', 'pending', 'verified', 'rejected'));
+
+COMMENT ON COLUMN "users"."phone" IS 'E.164 phone number';
+
+COMMENT ON COLUMN "users"."nationality" IS 'ISO 3166-1 alpha-2 country code';
+
+CREATE TABLE
+    "kyc_reviews" (
+        "id" bigserial PRIMARY KEY,
+        "username" varchar NOT NULL,
+        "from_status" varchar NOT NULL,
+        "to_status" varchar NOT NULL,
+        "reviewer" varchar NOT NULL,
+        "note" varchar NOT NULL DEFAULT '',
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE INDEX ON "kyc_reviews" ("username", "id");
+
+COMMENT ON TABLE "kyc_reviews" IS 'history of the KYC status changes of a user';
+
+COMMENT ON COLUMN "kyc_reviews"."reviewer" IS 'banker who changed the status, or the user when submitting their profile';
+
+ALTER TABLE "kyc_reviews" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
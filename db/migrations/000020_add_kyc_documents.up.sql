@@ -0,0 +1,36 @@
+CREATE TABLE
+    "kyc_documents" (
+        "id" bigserial PRIMARY KEY,
+        "username" varchar NOT NULL,
+        "review_id" bigint,
+        "kind" varchar NOT NULL,
+        "file_name" varchar NOT NULL,
+        "content_type" varchar NOT NULL,
+        "size" bigint NOT NULL,
+        "checksum" varchar NOT NULL,
+        "blob_key" varchar UNIQUE NOT NULL,
+        "status" varchar NOT NULL DEFAULT 'pending',
+        "reviewer" varchar NOT NULL DEFAULT '',
+        "note" varchar NOT NULL DEFAULT '',
+        "reviewed_at" timestamptz,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+ALTER TABLE "kyc_documents"
+ADD CONSTRAINT "kyc_documents_status_check" CHECK ("status" IN ('pending', 'approved', 'rejected'));
+
+CREATE INDEX ON "kyc_documents" ("username", "id");
+
+CREATE INDEX ON "kyc_documents" ("review_id");
+
+COMMENT ON TABLE "kyc_documents" IS 'identity documents uploaded by users for their KYC review';
+
+COMMENT ON COLUMN "kyc_documents"."review_id" IS 'submission the document was sent with, null until the user submits their profile';
+
+COMMENT ON COLUMN "kyc_documents"."checksum" IS 'hex encoded SHA-256 of the plaintext document';
+
+COMMENT ON COLUMN "kyc_documents"."blob_key" IS 'key of the encrypted document in the blob store';
+
+ALTER TABLE "kyc_documents" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "kyc_documents" ADD FOREIGN KEY ("review_id") REFERENCES "kyc_reviews" ("id") ON DELETE SET NULL;
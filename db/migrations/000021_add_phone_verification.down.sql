@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "phone_codes";
+
+DROP INDEX IF EXISTS "users_verified_phone_key";
+
+ALTER TABLE "users"
+DROP COLUMN "is_phone_verified";
@@ -0,0 +1,32 @@
+ALTER TABLE "users"
+ADD COLUMN "is_phone_verified" bool NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX "users_verified_phone_key" ON "users" ("phone") WHERE "is_phone_verified";
+
+CREATE TABLE
+    "phone_codes" (
+        "id" bigserial PRIMARY KEY,
+        "username" varchar NOT NULL,
+        "phone" varchar NOT NULL,
+        "purpose" varchar NOT NULL,
+        "code_hash" varchar NOT NULL,
+        "attempts" integer NOT NULL DEFAULT 0,
+        "expires_at" timestamptz NOT NULL,
+        "used_at" timestamptz,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+ALTER TABLE "phone_codes"
+ADD CONSTRAINT "phone_codes_purpose_check" CHECK ("purpose" IN ('verify_phone', 'login'));
+
+CREATE INDEX ON "phone_codes" ("username", "purpose", "id");
+
+COMMENT ON COLUMN "users"."is_phone_verified" IS 'a verified phone is unique and can be used as a transfer alias and for 2FA';
+
+COMMENT ON TABLE "phone_codes" IS 'one-time codes sent by SMS, only the latest unused code of a user and purpose is valid';
+
+COMMENT ON COLUMN "phone_codes"."phone" IS 'number the code was sent to, it only verifies this number';
+
+COMMENT ON COLUMN "phone_codes"."code_hash" IS 'SHA-256 hex digest of the code';
+
+ALTER TABLE "phone_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
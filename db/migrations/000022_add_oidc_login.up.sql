@@ -0,0 +1,44 @@
+CREATE TABLE
+    "user_identities" (
+        "id" bigserial PRIMARY KEY,
+        "username" varchar NOT NULL,
+        "issuer" varchar NOT NULL,
+        "subject" varchar NOT NULL,
+        "email" varchar NOT NULL DEFAULT '',
+        "last_login_at" timestamptz NOT NULL DEFAULT (now ()),
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE TABLE
+    "oidc_login_states" (
+        "id" bigserial PRIMARY KEY,
+        "state_hash" varchar NOT NULL,
+        "nonce" varchar NOT NULL,
+        "code_verifier" varchar NOT NULL,
+        "device_label" varchar NOT NULL DEFAULT '',
+        "expires_at" timestamptz NOT NULL,
+        "consumed_at" timestamptz,
+        "created_at" timestamptz NOT NULL DEFAULT (now ())
+    );
+
+CREATE UNIQUE INDEX "user_identities_issuer_subject_key" ON "user_identities" ("issuer", "subject");
+
+CREATE INDEX ON "user_identities" ("username");
+
+CREATE UNIQUE INDEX ON "oidc_login_states" ("state_hash");
+
+CREATE INDEX ON "oidc_login_states" ("expires_at");
+
+COMMENT ON TABLE "user_identities" IS 'accounts of external OpenID Connect providers linked to a user';
+
+COMMENT ON COLUMN "user_identities"."subject" IS 'sub claim of the ID token, unique and stable within the issuer';
+
+COMMENT ON COLUMN "user_identities"."email" IS 'email claim of the last login, informative only';
+
+COMMENT ON TABLE "oidc_login_states" IS 'pending authorization code logins, each state is consumed by a single callback';
+
+COMMENT ON COLUMN "oidc_login_states"."state_hash" IS 'SHA-256 hex digest of the state parameter';
+
+COMMENT ON COLUMN "oidc_login_states"."code_verifier" IS 'PKCE code verifier, only its S256 challenge is sent to the provider';
+
+ALTER TABLE "user_identities" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
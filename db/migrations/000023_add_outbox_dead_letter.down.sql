@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "outbox_messages_failed_at_idx";
+
+ALTER TABLE "outbox_messages"
+DROP COLUMN "failed_at";
@@ -0,0 +1,8 @@
+ALTER TABLE "outbox_messages"
+ADD COLUMN "failed_at" timestamptz;
+
+CREATE INDEX "outbox_messages_failed_at_idx" ON "outbox_messages" ("failed_at")
+WHERE
+    "failed_at" IS NOT NULL;
+
+COMMENT ON COLUMN "outbox_messages"."failed_at" IS 'set when the message ran out of publish attempts, it is no longer relayed';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "transfers_public_id_key";
+
+ALTER TABLE "transfers"
+DROP COLUMN "public_id";
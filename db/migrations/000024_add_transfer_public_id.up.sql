@@ -0,0 +1,6 @@
+ALTER TABLE "transfers"
+ADD COLUMN "public_id" uuid NOT NULL DEFAULT gen_random_uuid();
+
+CREATE UNIQUE INDEX "transfers_public_id_key" ON "transfers" ("public_id");
+
+COMMENT ON COLUMN "transfers"."public_id" IS 'opaque identifier returned to clients instead of the sequential id';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "password_resets_request_id_key";
+
+ALTER TABLE "password_resets"
+DROP COLUMN "request_id";
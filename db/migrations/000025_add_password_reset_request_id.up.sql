@@ -0,0 +1,10 @@
+ALTER TABLE "password_resets"
+ADD COLUMN "request_id" uuid NOT NULL DEFAULT gen_random_uuid();
+
+ALTER TABLE "password_resets"
+ALTER COLUMN "request_id"
+DROP DEFAULT;
+
+CREATE UNIQUE INDEX "password_resets_request_id_key" ON "password_resets" ("request_id");
+
+COMMENT ON COLUMN "password_resets"."request_id" IS 'forgot password request the token was mailed for, retries of the email replace its token';
@@ -0,0 +1,7 @@
+UPDATE "phone_codes"
+SET
+    "used_at" = now()
+WHERE
+    "used_at" IS NULL;
+
+COMMENT ON COLUMN "phone_codes"."code_hash" IS 'SHA-256 hex digest of the code';
@@ -0,0 +1,8 @@
+-- Pending codes were hashed without the server key and can no longer be checked
+UPDATE "phone_codes"
+SET
+    "used_at" = now()
+WHERE
+    "used_at" IS NULL;
+
+COMMENT ON COLUMN "phone_codes"."code_hash" IS 'HMAC-SHA256 hex digest of the code keyed with PHONE_CODE_KEY';
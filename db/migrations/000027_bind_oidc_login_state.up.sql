@@ -0,0 +1,9 @@
+-- Pending logins were not bound to a browser and can no longer be completed
+ALTER TABLE "oidc_login_states"
+ADD COLUMN "browser_hash" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "oidc_login_states"
+ALTER COLUMN "browser_hash"
+DROP DEFAULT;
+
+COMMENT ON COLUMN "oidc_login_states"."browser_hash" IS 'SHA-256 hex digest of the nonce of the login cookie, only the browser that began the login can complete it';
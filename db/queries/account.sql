@@ -1,7 +1,7 @@
 -- name: CreateAccount :one
 INSERT INTO
-    accounts (owner, balance, currency)
-VALUES ($1, $2, $3) RETURNING *;
+    accounts (owner, balance, currency, account_number)
+VALUES ($1, $2, $3, $4) RETURNING *;
 
 -- name: GetAccount :one
 SELECT
@@ -9,19 +9,51 @@ SELECT
     owner,
     balance,
     currency,
-    created_at
+    created_at,
+    account_number
 FROM
     accounts
 WHERE
     id = $1 LIMIT 1;
 
+-- name: GetAccountByNumber :one
+SELECT
+    id,
+    owner,
+    balance,
+    currency,
+    created_at,
+    account_number
+FROM
+    accounts
+WHERE
+    account_number = $1 LIMIT 1;
+
+-- name: GetAccountByVerifiedPhone :one
+-- Resolves a phone alias to the account of its owner in the currency of the transfer
+SELECT
+    a.id,
+    a.owner,
+    a.balance,
+    a.currency,
+    a.created_at,
+    a.account_number
+FROM
+    accounts a
+    JOIN users u ON u.username = a.owner
+WHERE
+    u.phone = $1
+    AND u.is_phone_verified
+    AND a.currency = $2 LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT
     id,
     owner,
     balance,
     currency,
-    created_at
+    created_at,
+    account_number
 FROM
     accounts
 WHERE
@@ -34,7 +66,8 @@ SELECT
     owner,
     balance,
     currency,
-    created_at
+    created_at,
+    account_number
 FROM
     accounts
 WHERE 
@@ -53,11 +86,13 @@ WHERE
 RETURNING *;
 
 -- name: AddAccountBalance :one
+-- The balance is checked on the locked row, no row is returned when it would become negative
 UPDATE accounts
 SET 
     balance = balance + sqlc.arg(amount)
 WHERE
     id = sqlc.arg(id)
+    AND balance + sqlc.arg(amount) >= 0
 RETURNING *;
 
 -- name: DeleteAccount :exec
@@ -0,0 +1,41 @@
+-- name: CreateApiKey :one
+INSERT INTO
+    api_keys (id, service_account, key_prefix, key_hash, scopes, allowed_ips, expires_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetApiKeyByHash :one
+SELECT
+    k.id,
+    k.service_account,
+    k.scopes,
+    k.allowed_ips,
+    k.expires_at,
+    k.revoked_at,
+    sa.role
+FROM
+    api_keys k
+    JOIN service_accounts sa ON sa.name = k.service_account
+WHERE
+    k.key_hash = $1 LIMIT 1;
+
+-- name: ListApiKeys :many
+SELECT
+    *
+FROM
+    api_keys
+WHERE
+    service_account = $1
+ORDER BY
+    created_at DESC;
+
+-- name: RevokeApiKey :one
+UPDATE
+    api_keys
+SET
+    revoked_at = now()
+WHERE
+    id = $1
+    AND service_account = $2
+    AND revoked_at IS NULL
+RETURNING *;
@@ -0,0 +1,20 @@
+-- name: CreateAuditLog :one
+INSERT INTO
+    audit_logs (actor, action, target, metadata, client_ip)
+VALUES
+    ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListAuditLogs :many
+SELECT
+    *
+FROM
+    audit_logs
+WHERE
+    (sqlc.narg(actor)::varchar IS NULL OR actor = sqlc.narg(actor))
+    AND (sqlc.narg(target)::varchar IS NULL OR target = sqlc.narg(target))
+ORDER BY
+    created_at DESC,
+    id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
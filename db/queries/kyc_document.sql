@@ -0,0 +1,57 @@
+-- name: CreateKycDocument :one
+INSERT INTO
+    kyc_documents (
+        username,
+        kind,
+        file_name,
+        content_type,
+        size,
+        checksum,
+        blob_key
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetKycDocument :one
+SELECT
+    *
+FROM
+    kyc_documents
+WHERE
+    id = $1
+LIMIT 1;
+
+-- name: ListKycDocuments :many
+SELECT
+    *
+FROM
+    kyc_documents
+WHERE
+    username = $1
+ORDER BY
+    id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: AttachKycDocuments :execrows
+-- Links the documents uploaded since the last submission to the review of a new one
+UPDATE kyc_documents
+SET
+    review_id = sqlc.arg(review_id)
+WHERE
+    username = sqlc.arg(username)
+    AND review_id IS NULL;
+
+-- name: ReviewKycDocument :one
+-- Only reviews the document when it still has the status the decision was made on
+UPDATE kyc_documents
+SET
+    status = sqlc.arg(to_status),
+    reviewer = sqlc.arg(reviewer),
+    note = sqlc.arg(note),
+    reviewed_at = now()
+WHERE
+    id = sqlc.arg(id)
+    AND status = sqlc.arg(from_status)
+RETURNING *;
@@ -0,0 +1,18 @@
+-- name: CreateKycReview :one
+INSERT INTO
+    kyc_reviews (username, from_status, to_status, reviewer, note)
+VALUES
+    ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListKycReviews :many
+SELECT
+    *
+FROM
+    kyc_reviews
+WHERE
+    username = $1
+ORDER BY
+    id DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,108 @@
+-- name: CreateLoginEvent :one
+-- Nothing is inserted for an unknown username so failed guesses do not fill the table
+INSERT INTO
+    login_events (
+        username,
+        session_id,
+        success,
+        failure_reason,
+        user_agent,
+        client_ip,
+        new_device
+    )
+SELECT
+    $1, $2, $3, $4, $5, $6, $7
+WHERE
+    EXISTS (
+        SELECT
+            1
+        FROM
+            users
+        WHERE
+            username = $1
+    )
+RETURNING *;
+
+-- name: GetLoginEvent :one
+SELECT
+    *
+FROM
+    login_events
+WHERE
+    id = $1
+LIMIT 1;
+
+-- name: GetLoginEventByDenyToken :one
+-- Only finds logins that were not denied yet, the link works once
+SELECT
+    *
+FROM
+    login_events
+WHERE
+    deny_token_hash = $1
+    AND denied_at IS NULL
+LIMIT 1;
+
+-- name: GetLoginSource :one
+-- Denied logins do not make their device or address known
+SELECT
+    EXISTS (
+        SELECT
+            1
+        FROM
+            login_events
+        WHERE
+            username = $1
+            AND success
+            AND denied_at IS NULL
+    ) AS has_logged_in,
+    EXISTS (
+        SELECT
+            1
+        FROM
+            login_events
+        WHERE
+            username = $1
+            AND success
+            AND denied_at IS NULL
+            AND user_agent = $2
+    ) AS known_device,
+    EXISTS (
+        SELECT
+            1
+        FROM
+            login_events
+        WHERE
+            username = $1
+            AND success
+            AND denied_at IS NULL
+            AND client_ip = $3
+    ) AS known_ip;
+
+-- name: ListLoginEvents :many
+SELECT
+    *
+FROM
+    login_events
+WHERE
+    username = $1
+ORDER BY
+    id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: SetLoginEventDenyToken :exec
+UPDATE login_events
+SET
+    deny_token_hash = $2
+WHERE
+    id = $1;
+
+-- name: DenyLoginEvent :one
+UPDATE login_events
+SET
+    denied_at = now()
+WHERE
+    deny_token_hash = $1
+    AND denied_at IS NULL
+RETURNING *;
@@ -0,0 +1,38 @@
+-- name: CreateMfaChallenge :one
+INSERT INTO
+    mfa_challenges (username, token_hash, device_label, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetMfaChallengeByTokenHash :one
+SELECT
+    *
+FROM
+    mfa_challenges
+WHERE
+    token_hash = $1 LIMIT 1;
+
+-- name: IncrementMfaChallengeAttempts :one
+UPDATE mfa_challenges
+SET
+    attempts = attempts + 1
+WHERE
+    id = sqlc.arg(id)
+    AND attempts < sqlc.arg(max_attempts)
+RETURNING *;
+
+-- name: ConsumeMfaChallenge :one
+UPDATE mfa_challenges
+SET
+    consumed_at = now()
+WHERE
+    id = $1
+    AND consumed_at IS NULL
+RETURNING *;
+
+-- name: DeleteExpiredMfaChallenges :execrows
+DELETE FROM
+    mfa_challenges
+WHERE
+    username = $1
+    AND (expires_at < now() OR consumed_at IS NOT NULL);
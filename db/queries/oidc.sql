@@ -0,0 +1,52 @@
+-- name: CreateOidcLoginState :one
+INSERT INTO
+    oidc_login_states (
+        state_hash,
+        nonce,
+        code_verifier,
+        device_label,
+        expires_at,
+        browser_hash
+    )
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ConsumeOidcLoginState :one
+-- A callback from another browser neither finds nor consumes the state
+UPDATE oidc_login_states
+SET
+    consumed_at = now()
+WHERE
+    state_hash = $1
+    AND browser_hash = $2
+    AND consumed_at IS NULL
+RETURNING *;
+
+-- name: DeleteExpiredOidcLoginStates :execrows
+DELETE FROM
+    oidc_login_states
+WHERE
+    expires_at < now();
+
+-- name: GetUserIdentity :one
+SELECT
+    *
+FROM
+    user_identities
+WHERE
+    issuer = $1
+    AND subject = $2 LIMIT 1;
+
+-- name: CreateUserIdentity :one
+INSERT INTO
+    user_identities (username, issuer, subject, email)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET
+    email = $2,
+    last_login_at = now()
+WHERE
+    id = $1;
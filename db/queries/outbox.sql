@@ -0,0 +1,56 @@
+-- name: CreateOutboxMessage :one
+INSERT INTO
+    outbox_messages (
+        aggregate_type,
+        aggregate_id,
+        task_type,
+        payload,
+        queue,
+        max_retry,
+        process_at
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListPendingOutboxMessages :many
+SELECT
+    *
+FROM
+    outbox_messages
+WHERE
+    processed_at IS NULL
+    AND failed_at IS NULL
+ORDER BY
+    id
+LIMIT $1
+FOR UPDATE;
+
+-- name: MarkOutboxMessageProcessed :exec
+UPDATE
+    outbox_messages
+SET
+    processed_at = now()
+WHERE
+    id = $1;
+
+-- name: MarkOutboxMessageFailed :one
+UPDATE
+    outbox_messages
+SET
+    attempts = attempts + 1,
+    last_error = $2,
+    failed_at = CASE
+        WHEN attempts + 1 >= sqlc.arg(max_attempts)::integer THEN now()
+    END
+WHERE
+    id = $1
+RETURNING *;
+
+-- name: DeleteProcessedOutboxMessages :execrows
+DELETE FROM
+    outbox_messages
+WHERE
+    processed_at < sqlc.arg(processed_before)::timestamptz;
+
+-- name: TryLockOutboxRelay :one
+SELECT pg_try_advisory_xact_lock(hashtext('outbox_relay'));
@@ -0,0 +1,32 @@
+-- name: CreatePasswordHistory :one
+INSERT INTO
+    password_histories (username, hashed_password)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: ListPasswordHistory :many
+SELECT
+    hashed_password
+FROM
+    password_histories
+WHERE
+    username = $1
+ORDER BY
+    id DESC
+LIMIT $2;
+
+-- name: PrunePasswordHistory :exec
+DELETE FROM password_histories
+WHERE
+    username = sqlc.arg(username)
+    AND id NOT IN (
+        SELECT
+            id
+        FROM
+            password_histories
+        WHERE
+            username = sqlc.arg(username)
+        ORDER BY
+            id DESC
+        LIMIT sqlc.arg(keep)
+    );
@@ -0,0 +1,40 @@
+-- name: CreatePasswordReset :one
+-- A retry of the same request replaces its token, a used token is left alone and no row is returned
+INSERT INTO
+    password_resets (username, email, token_hash, request_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (request_id) DO UPDATE
+SET
+    token_hash = EXCLUDED.token_hash,
+    expired_at = EXCLUDED.expired_at
+WHERE
+    password_resets.is_used = FALSE
+RETURNING *;
+
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET
+    is_used = TRUE
+WHERE
+    token_hash = $1
+    AND is_used = FALSE
+    AND expired_at > NOW()
+RETURNING *;
+
+-- name: InvalidatePasswordResets :exec
+UPDATE password_resets
+SET
+    is_used = TRUE
+WHERE
+    username = $1
+    AND is_used = FALSE;
+
+-- name: GetPasswordResetByTokenHash :one
+SELECT
+    *
+FROM
+    password_resets
+WHERE
+    token_hash = $1
+    AND is_used = FALSE
+    AND expired_at > NOW();
@@ -0,0 +1,45 @@
+-- name: CreatePhoneCode :one
+INSERT INTO
+    phone_codes (username, phone, purpose, code_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetLatestPhoneCode :one
+SELECT
+    *
+FROM
+    phone_codes
+WHERE
+    username = $1
+    AND purpose = $2
+ORDER BY
+    id DESC
+LIMIT 1;
+
+-- name: IncrementPhoneCodeAttempts :one
+-- Takes one attempt of the code before it is compared, no row is returned once max_attempts were taken
+UPDATE phone_codes
+SET
+    attempts = attempts + 1
+WHERE
+    id = sqlc.arg(id)
+    AND attempts < sqlc.arg(max_attempts)
+RETURNING *;
+
+-- name: UsePhoneCode :one
+UPDATE phone_codes
+SET
+    used_at = now()
+WHERE
+    id = $1
+    AND used_at IS NULL
+RETURNING *;
+
+-- name: InvalidatePhoneCodes :exec
+UPDATE phone_codes
+SET
+    used_at = now()
+WHERE
+    username = $1
+    AND purpose = $2
+    AND used_at IS NULL;
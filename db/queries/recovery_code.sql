@@ -0,0 +1,20 @@
+-- name: CreateRecoveryCode :exec
+INSERT INTO
+    recovery_codes (username, code_hash)
+VALUES ($1, $2);
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET
+    used_at = now()
+WHERE
+    username = $1
+    AND code_hash = $2
+    AND used_at IS NULL
+RETURNING *;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM
+    recovery_codes
+WHERE
+    username = $1;
@@ -0,0 +1,23 @@
+-- name: CreateServiceAccount :one
+INSERT INTO
+    service_accounts (name, role, description, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetServiceAccount :one
+SELECT
+    *
+FROM
+    service_accounts
+WHERE
+    name = $1 LIMIT 1;
+
+-- name: ListServiceAccounts :many
+SELECT
+    *
+FROM
+    service_accounts
+ORDER BY
+    name
+LIMIT $1
+OFFSET $2;
@@ -7,44 +7,147 @@ INSERT INTO
         user_agent,
         client_ip,
         is_blocked,
-        expires_at
+        expires_at,
+        device_label
     )
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: GetSessionById :one
 SELECT
-    id,
-    username,
-    refresh_token,
-    user_agent,
-    client_ip,
-    is_blocked,
-    expires_at
+    sessions.id,
+    sessions.username,
+    sessions.refresh_token,
+    sessions.user_agent,
+    sessions.client_ip,
+    sessions.is_blocked,
+    sessions.expires_at,
+    sessions.revoked_at,
+    sessions.last_seen_at,
+    users.password_changed_at
 FROM
     sessions
+    JOIN users ON users.username = sessions.username
 WHERE
-    id = $1
+    sessions.id = $1
 LIMIT 1;
 
+-- name: ListSessionsByUserName :many
+SELECT
+    *
+FROM
+    sessions
+WHERE
+    username = $1
+    AND expires_at > now()
+    AND revoked_at IS NULL
+ORDER BY
+    last_seen_at DESC;
 
--- name: GetSessionByUserName :one
+-- name: GetSessionForUpdate :one
 SELECT
-    id,
-    username,
-    refresh_token,
-    user_agent,
-    client_ip,
-    is_blocked,
-    expires_at
+    *
 FROM
     sessions
+WHERE
+    id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: RotateSessionRefreshToken :one
+UPDATE
+    sessions
+SET
+    refresh_token = $2,
+    client_ip = $3,
+    last_seen_at = now()
+WHERE
+    id = $1
+RETURNING *;
+
+-- name: BlockSession :exec
+UPDATE
+    sessions
+SET
+    is_blocked = true,
+    revoked_at = COALESCE(revoked_at, now())
+WHERE
+    id = $1;
+
+-- name: RevokeSession :exec
+UPDATE
+    sessions
+SET
+    revoked_at = now()
+WHERE
+    id = $1
+    AND revoked_at IS NULL;
+
+-- name: RevokeSessionsByUserName :many
+-- Sessions already revoked or expired keep their state
+UPDATE
+    sessions
+SET
+    revoked_at = now()
 WHERE
     username = $1
-LIMIT 1;
+    AND revoked_at IS NULL
+    AND expires_at > now()
+RETURNING id;
+
+-- name: RevokeOtherSessions :many
+-- Sessions already revoked or expired keep their state
+UPDATE
+    sessions
+SET
+    revoked_at = now()
+WHERE
+    username = $1
+    AND id <> $2
+    AND revoked_at IS NULL
+    AND expires_at > now()
+RETURNING id;
+
+-- name: TouchSession :exec
+UPDATE
+    sessions
+SET
+    last_seen_at = now()
+WHERE
+    id = $1;
+
+-- name: DeleteExpiredSessions :execrows
+-- Revoked and blocked sessions are kept until they expire so their history stays
+DELETE FROM
+    sessions
+WHERE
+    username = $1
+    AND expires_at <= now();
 
--- name: DeleteSession :exec
+-- name: DeleteOldestSessions :many
 DELETE FROM
     sessions
 WHERE
-    id = $1;
\ No newline at end of file
+    id IN (
+        SELECT
+            id
+        FROM
+            sessions
+        WHERE
+            username = sqlc.arg(username)
+            AND revoked_at IS NULL
+        ORDER BY
+            created_at DESC,
+            id
+        OFFSET sqlc.arg(keep)::int
+    )
+RETURNING id;
+
+-- name: SetSessionBlocked :one
+UPDATE
+    sessions
+SET
+    is_blocked = $2
+WHERE
+    id = $1
+RETURNING *;
@@ -7,16 +7,19 @@ RETURNING *;
 
 -- name: GetTransfers :many
 SELECT
-    from_account_id,
-    to_account_id,
-    amount,
-    created_at
+    t.public_id,
+    fa.account_number AS from_account_number,
+    ta.account_number AS to_account_number,
+    t.amount,
+    t.created_at
 FROM
-    transfers
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
 WHERE
-    from_account_id = $1 AND to_account_id = $2
+    t.from_account_id = $1 AND t.to_account_id = $2
 ORDER BY
-    created_at DESC;
+    t.created_at DESC;
 
 -- name: GetTransfer :one
 SELECT
@@ -24,7 +27,8 @@ SELECT
     from_account_id,
     to_account_id,
     amount,
-    created_at
+    created_at,
+    public_id
 FROM
     transfers
 WHERE
@@ -32,26 +36,66 @@ WHERE
 
 -- name: GetTransfersByFromAccountId :many
 SELECT
-    from_account_id,
-    to_account_id,
-    amount,
-    created_at
+    t.public_id,
+    fa.account_number AS from_account_number,
+    ta.account_number AS to_account_number,
+    t.amount,
+    t.created_at
 FROM
-    transfers
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
 WHERE
-    from_account_id = $1
+    t.from_account_id = $1
 ORDER BY
-    created_at DESC;
+    t.created_at DESC;
 
 -- name: GetTransfersByToAccountId :many
 SELECT
-    from_account_id,
-    to_account_id,
-    amount,
-    created_at
+    t.public_id,
+    fa.account_number AS from_account_number,
+    ta.account_number AS to_account_number,
+    t.amount,
+    t.created_at
 FROM
-    transfers
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+    t.to_account_id = $1
+ORDER BY
+    t.created_at DESC;
+-- name: ListTransfersByOwner :many
+SELECT
+    t.public_id,
+    fa.account_number AS from_account_number,
+    ta.account_number AS to_account_number,
+    t.amount,
+    fa.currency,
+    t.created_at
+FROM
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
 WHERE
-    to_account_id = $1
+    fa.owner = sqlc.arg(owner)
+    OR ta.owner = sqlc.arg(owner)
 ORDER BY
-    created_at DESC;
\ No newline at end of file
+    t.created_at DESC,
+    t.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SumOutgoingTransfersSince :one
+-- Transfers between accounts of the same owner are not counted
+SELECT
+    COALESCE(SUM(t.amount), 0)::bigint AS total
+FROM
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+    fa.owner = sqlc.arg(owner)
+    AND ta.owner <> sqlc.arg(owner)
+    AND fa.currency = sqlc.arg(currency)
+    AND t.created_at >= sqlc.arg(since);
@@ -12,7 +12,13 @@ SELECT
     email,
     is_email_verified,
     password_changed_at,
-    created_at
+    created_at,
+    date_of_birth,
+    phone,
+    address,
+    nationality,
+    kyc_status,
+    is_phone_verified
 FROM 
     users
 WHERE 
@@ -25,7 +31,88 @@ SET
     hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password),
     is_email_verified = COALESCE(sqlc.narg(is_email_verified), is_email_verified),
     full_name = COALESCE(sqlc.narg(full_name), full_name),
-    email = COALESCE(sqlc.narg(email), email)
+    email = COALESCE(sqlc.narg(email), email),
+    date_of_birth = COALESCE(sqlc.narg(date_of_birth), date_of_birth),
+    phone = COALESCE(sqlc.narg(phone), phone),
+    address = COALESCE(sqlc.narg(address), address),
+    nationality = COALESCE(sqlc.narg(nationality), nationality),
+    kyc_status = COALESCE(sqlc.narg(kyc_status), kyc_status),
+    is_phone_verified = COALESCE(sqlc.narg(is_phone_verified), is_phone_verified)
 WHERE
     username = sqlc.arg(username)
 RETURNING *;
+
+-- name: GetUserByEmail :one
+SELECT 
+    username,
+    hashed_password,
+    role,
+    full_name,
+    email,
+    is_email_verified,
+    password_changed_at,
+    created_at
+FROM 
+    users
+WHERE 
+    email = $1;
+
+-- name: UpdateUserPassword :one
+UPDATE 
+    users
+SET
+    hashed_password = $2,
+    password_changed_at = $3
+WHERE
+    username = $1
+RETURNING *;
+
+-- name: SearchUsers :many
+SELECT
+    username,
+    role,
+    full_name,
+    email,
+    is_email_verified,
+    created_at
+FROM
+    users
+WHERE
+    username ILIKE '%' || sqlc.arg(query)::varchar || '%'
+    OR email ILIKE '%' || sqlc.arg(query)::varchar || '%'
+    OR full_name ILIKE '%' || sqlc.arg(query)::varchar || '%'
+ORDER BY
+    username
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: UpdateUserRole :one
+UPDATE
+    users
+SET
+    role = $2
+WHERE
+    username = $1
+RETURNING *;
+
+-- name: UpdateUserKycStatus :one
+-- Only moves the status when it is still the one the change was decided on
+UPDATE
+    users
+SET
+    kyc_status = sqlc.arg(to_status)
+WHERE
+    username = sqlc.arg(username)
+    AND kyc_status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: MarkPhoneVerified :one
+-- Only verifies the number the code was sent to, a number changed in the meantime stays unverified
+UPDATE
+    users
+SET
+    is_phone_verified = TRUE
+WHERE
+    username = $1
+    AND phone = $2
+RETURNING *;
@@ -0,0 +1,44 @@
+-- name: UpsertPendingUserTotp :one
+INSERT INTO
+    user_totps (username, secret_ciphertext)
+VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+SET
+    secret_ciphertext = EXCLUDED.secret_ciphertext,
+    last_used_step = 0,
+    created_at = now()
+WHERE
+    user_totps.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetUserTotp :one
+SELECT
+    *
+FROM
+    user_totps
+WHERE
+    username = $1 LIMIT 1;
+
+-- name: ConfirmUserTotp :one
+UPDATE user_totps
+SET
+    confirmed_at = now(),
+    last_used_step = $2
+WHERE
+    username = $1
+    AND confirmed_at IS NULL
+RETURNING *;
+
+-- name: UseUserTotpStep :execrows
+UPDATE user_totps
+SET
+    last_used_step = $2
+WHERE
+    username = $1
+    AND last_used_step < $2;
+
+-- name: DeleteUserTotp :exec
+DELETE FROM
+    user_totps
+WHERE
+    username = $1;
@@ -4,6 +4,13 @@ INSERT INTO
 VALUES ($1, $2, $3)
 RETURNING *;
 
+-- name: InvalidateVerifyEmails :exec
+UPDATE verify_emails
+SET
+    is_used = TRUE
+WHERE
+    username = $1
+    AND is_used = FALSE;
 
 -- name: UpdateVerifyEmail :one
 UPDATE verify_emails
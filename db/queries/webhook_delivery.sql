@@ -0,0 +1,50 @@
+-- name: CreateWebhookDelivery :one
+INSERT INTO
+    webhook_deliveries (endpoint_id, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (endpoint_id, event_id) DO NOTHING
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT
+    *
+FROM
+    webhook_deliveries
+WHERE
+    id = $1 LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT
+    *
+FROM
+    webhook_deliveries
+WHERE
+    endpoint_id = $1
+ORDER BY
+    id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateWebhookDeliveryAttempt :one
+UPDATE
+    webhook_deliveries
+SET
+    attempts = attempts + 1,
+    status = sqlc.arg(status),
+    response_status = sqlc.narg(response_status),
+    response_body = sqlc.narg(response_body),
+    last_error = sqlc.narg(last_error),
+    delivered_at = COALESCE(sqlc.narg(delivered_at), delivered_at)
+WHERE
+    id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ResetWebhookDelivery :one
+UPDATE
+    webhook_deliveries
+SET
+    status = 'pending',
+    last_error = NULL
+WHERE
+    id = $1
+RETURNING *;
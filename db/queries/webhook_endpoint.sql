@@ -0,0 +1,65 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO
+    webhook_endpoints (owner, url, secret, event_types)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhookEndpoint :one
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    id = $1 LIMIT 1;
+
+-- name: ListWebhookEndpoints :many
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    owner = $1
+ORDER BY
+    id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListWebhookEndpointsForEvent :many
+SELECT
+    *
+FROM
+    webhook_endpoints
+WHERE
+    owner = sqlc.arg(owner)
+    AND is_active
+    AND sqlc.arg(event_type)::varchar = ANY (event_types)
+ORDER BY
+    id;
+
+-- name: UpdateWebhookEndpoint :one
+UPDATE
+    webhook_endpoints
+SET
+    url = COALESCE(sqlc.narg(url), url),
+    event_types = COALESCE(sqlc.narg(event_types)::varchar[], event_types),
+    is_active = COALESCE(sqlc.narg(is_active), is_active)
+WHERE
+    id = sqlc.arg(id)
+RETURNING *;
+
+-- name: RotateWebhookEndpointSecret :one
+UPDATE
+    webhook_endpoints
+SET
+    previous_secret = secret,
+    previous_secret_expires_at = sqlc.arg(previous_secret_expires_at),
+    secret = sqlc.arg(secret)
+WHERE
+    id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE FROM
+    webhook_endpoints
+WHERE
+    id = $1;
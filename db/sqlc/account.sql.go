@@ -15,7 +15,8 @@ SET
     balance = balance + $1
 WHERE
     id = $2
-RETURNING id, owner, balance, currency, created_at
+    AND balance + $1 >= 0
+RETURNING id, owner, balance, currency, created_at, account_number
 `
 
 type AddAccountBalanceParams struct {
@@ -23,6 +24,7 @@ type AddAccountBalanceParams struct {
 	ID     int64 `json:"id"`
 }
 
+// The balance is checked on the locked row, no row is returned when it would become negative
 func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
 	row := q.db.QueryRow(ctx, addAccountBalance, arg.Amount, arg.ID)
 	var i Account
@@ -32,6 +34,7 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
 	)
 	return i, err
 }
@@ -39,17 +42,23 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO
     accounts (owner, balance, currency)
-VALUES ($1, $2, $3) RETURNING id, owner, balance, currency, created_at
+VALUES ($1, $2, $3) RETURNING id, owner, balance, currency, created_at, account_number
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner         string `json:"owner"`
+	Balance       int64  `json:"balance"`
+	Currency      string `json:"currency"`
+	AccountNumber string `json:"account_number"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.AccountNumber,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -57,6 +66,7 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
 	)
 	return i, err
 }
@@ -79,7 +89,8 @@ SELECT
     owner,
     balance,
     currency,
-    created_at
+    created_at,
+    account_number
 FROM
     accounts
 WHERE
@@ -95,6 +106,72 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT
+    id,
+    owner,
+    balance,
+    currency,
+    created_at,
+    account_number
+FROM
+    accounts
+WHERE
+    account_number = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByNumber, accountNumber)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+	)
+	return i, err
+}
+
+const getAccountByVerifiedPhone = `-- name: GetAccountByVerifiedPhone :one
+SELECT
+    a.id,
+    a.owner,
+    a.balance,
+    a.currency,
+    a.created_at,
+    a.account_number
+FROM
+    accounts a
+    JOIN users u ON u.username = a.owner
+WHERE
+    u.phone = $1
+    AND u.is_phone_verified
+    AND a.currency = $2 LIMIT 1
+`
+
+type GetAccountByVerifiedPhoneParams struct {
+	Phone    string `json:"phone"`
+	Currency string `json:"currency"`
+}
+
+// Resolves a phone alias to the account of its owner in the currency of the transfer
+func (q *Queries) GetAccountByVerifiedPhone(ctx context.Context, arg GetAccountByVerifiedPhoneParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByVerifiedPhone, arg.Phone, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
 	)
 	return i, err
 }
@@ -105,7 +182,8 @@ SELECT
     owner,
     balance,
     currency,
-    created_at
+    created_at,
+    account_number
 FROM
     accounts
 WHERE
@@ -122,6 +200,7 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
 	)
 	return i, err
 }
@@ -132,7 +211,8 @@ SELECT
     owner,
     balance,
     currency,
-    created_at
+    created_at,
+    account_number
 FROM
     accounts
 WHERE 
@@ -164,6 +244,7 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.AccountNumber,
 		); err != nil {
 			return nil, err
 		}
@@ -181,7 +262,7 @@ SET
     balance = $2
 WHERE
     id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, account_number
 `
 
 type UpdateAccountParams struct {
@@ -198,6 +279,7 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountNumber,
 	)
 	return i, err
 }
@@ -12,11 +12,22 @@ const (
 	UniqueViolation     = "23505"
 )
 
+const (
+	AccountNumberConstraint = "accounts_account_number_key"
+	VerifiedPhoneConstraint = "users_verified_phone_key"
+	UserIdentityConstraint  = "user_identities_issuer_subject_key"
+	UsernameConstraint      = "users_pkey"
+	UserEmailConstraint     = "users_email_key"
+)
+
 var (
 	ErrRecordNotFound  = pgx.ErrNoRows
 	ErrUniqueViolation = &pgconn.PgError{
 		Code: UniqueViolation,
 	}
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrNoKycDocuments       = errors.New("no KYC document was uploaded since the last submission")
+	ErrInsufficientBalance  = errors.New("insufficient account balance")
 )
 
 func ErrorCode(err error) string {
@@ -26,3 +37,11 @@ func ErrorCode(err error) string {
 	}
 	return ""
 }
+
+func ConstraintName(err error) string {
+	var pgxErr *pgconn.PgError
+	if errors.As(err, &pgxErr) {
+		return pgxErr.ConstraintName
+	}
+	return ""
+}
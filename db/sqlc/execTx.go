@@ -2,12 +2,118 @@ package sqlc
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	SerializationFailure = "40001"
+	DeadlockDetected     = "40P01"
 )
 
-// execTx executes a function within a database transaction
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.connPool.Begin(ctx)
+const (
+	defaultTxMaxRetries  = 3
+	defaultTxBaseBackoff = 10 * time.Millisecond
+	defaultTxMaxBackoff  = 500 * time.Millisecond
+)
+
+// ErrTxRetriesExhausted is returned when a transaction kept failing with a retryable error
+var ErrTxRetriesExhausted = errors.New("transaction retries exhausted")
+
+// txMetrics is published on /debug/vars of the metrics server, retries are counted per SQLSTATE
+var txMetrics = expvar.NewMap("db_tx")
+
+// txConfig holds the settings of a single execTx call
+type txConfig struct {
+	options     pgx.TxOptions
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+}
+
+// TxOption configures how a transaction is run
+type TxOption func(*txConfig)
+
+// WithIsoLevel runs the transaction with the given isolation level
+func WithIsoLevel(level pgx.TxIsoLevel) TxOption {
+	return func(c *txConfig) {
+		c.options.IsoLevel = level
+	}
+}
+
+// WithSerializable runs the transaction with the SERIALIZABLE isolation level
+func WithSerializable() TxOption {
+	return WithIsoLevel(pgx.Serializable)
+}
+
+// WithReadOnly runs the transaction in read only mode
+func WithReadOnly() TxOption {
+	return func(c *txConfig) {
+		c.options.AccessMode = pgx.ReadOnly
+	}
+}
+
+// WithMaxRetries bounds how often a serialization failure or deadlock is retried, 0 disables retries
+func WithMaxRetries(n int) TxOption {
+	return func(c *txConfig) {
+		c.maxRetries = n
+	}
+}
+
+func newTxConfig(opts []TxOption) txConfig {
+	cfg := txConfig{
+		maxRetries:  defaultTxMaxRetries,
+		baseBackoff: defaultTxBaseBackoff,
+		maxBackoff:  defaultTxMaxBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// IsRetryableTxError reports whether Postgres aborted the transaction because of a concurrent one
+func IsRetryableTxError(err error) bool {
+	code := ErrorCode(err)
+	return code == SerializationFailure || code == DeadlockDetected
+}
+
+// execTx executes a function within a database transaction, retrying it on serialization failures and deadlocks
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error, opts ...TxOption) error {
+	cfg := newTxConfig(opts)
+
+	for attempt := 0; ; attempt++ {
+		err := store.runTx(ctx, cfg.options, fn)
+		if err == nil || !IsRetryableTxError(err) {
+			return err
+		}
+
+		if attempt >= cfg.maxRetries {
+			txMetrics.Add("retries_exhausted", 1)
+			return fmt.Errorf("%w: %w", ErrTxRetriesExhausted, err)
+		}
+
+		txMetrics.Add("retries", 1)
+		txMetrics.Add("retries_"+ErrorCode(err), 1)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cfg.backoff(attempt)):
+		}
+	}
+}
+
+// runTx runs fn once inside a transaction
+func (store *SQLStore) runTx(ctx context.Context, options pgx.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.connPool.BeginTx(ctx, options)
 	if err != nil {
 		return err
 	}
@@ -16,10 +122,20 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
 		}
 		return err
 	}
 
 	return tx.Commit(ctx)
 }
+
+// backoff returns a full jitter delay, capped at maxBackoff
+func (c txConfig) backoff(attempt int) time.Duration {
+	ceiling := c.maxBackoff
+	if attempt < 16 {
+		ceiling = min(c.baseBackoff<<attempt, c.maxBackoff)
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
@@ -0,0 +1,43 @@
+package sqlc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryableTxError(t *testing.T) {
+	require.True(t, IsRetryableTxError(&pgconn.PgError{Code: SerializationFailure}))
+	require.True(t, IsRetryableTxError(fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: DeadlockDetected})))
+	require.False(t, IsRetryableTxError(&pgconn.PgError{Code: UniqueViolation}))
+	require.False(t, IsRetryableTxError(ErrRecordNotFound))
+	require.False(t, IsRetryableTxError(nil))
+}
+
+func TestTxOptions(t *testing.T) {
+	cfg := newTxConfig(nil)
+	require.Equal(t, defaultTxMaxRetries, cfg.maxRetries)
+	require.Equal(t, pgx.TxOptions{}, cfg.options)
+
+	cfg = newTxConfig([]TxOption{WithSerializable(), WithReadOnly(), WithMaxRetries(0)})
+	require.Equal(t, pgx.Serializable, cfg.options.IsoLevel)
+	require.Equal(t, pgx.ReadOnly, cfg.options.AccessMode)
+	require.Zero(t, cfg.maxRetries)
+}
+
+func TestTxBackoff(t *testing.T) {
+	cfg := newTxConfig(nil)
+
+	for attempt := 0; attempt < 100; attempt++ {
+		delay := cfg.backoff(attempt)
+		require.GreaterOrEqual(t, delay, time.Duration(0))
+		require.LessOrEqual(t, delay, cfg.maxBackoff)
+		if attempt == 0 {
+			require.LessOrEqual(t, delay, cfg.baseBackoff)
+		}
+	}
+}
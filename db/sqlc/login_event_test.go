@@ -0,0 +1,107 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateLoginEventUnknownUser(t *testing.T) {
+	_, err := testStore.CreateLoginEvent(context.Background(), CreateLoginEventParams{
+		Username:      util.RandomOwner(),
+		FailureReason: "invalid_password",
+		UserAgent:     "curl/8.0",
+		ClientIp:      "198.51.100.2",
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestGetLoginSource(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := GetLoginSourceParams{Username: user.Username, UserAgent: "Mozilla/5.0", ClientIp: "203.0.113.7"}
+
+	source, err := testStore.GetLoginSource(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, GetLoginSourceRow{}, source)
+
+	// Failed logins do not make a device known
+	_, err = testStore.CreateLoginEvent(context.Background(), CreateLoginEventParams{
+		Username:      user.Username,
+		FailureReason: "invalid_password",
+		UserAgent:     arg.UserAgent,
+		ClientIp:      arg.ClientIp,
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.CreateLoginEvent(context.Background(), CreateLoginEventParams{
+		Username:  user.Username,
+		Success:   true,
+		UserAgent: arg.UserAgent,
+		ClientIp:  "198.51.100.2",
+	})
+	require.NoError(t, err)
+
+	source, err = testStore.GetLoginSource(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, GetLoginSourceRow{HasLoggedIn: true, KnownDevice: true, KnownIp: false}, source)
+
+	events, err := testStore.ListLoginEvents(context.Background(), ListLoginEventsParams{Username: user.Username, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.True(t, events[0].Success)
+	require.False(t, events[1].Success)
+}
+
+func TestDenyLoginTx(t *testing.T) {
+	user := createRandomUser(t)
+
+	result, err := testStore.CreateSessionTx(context.Background(), CreateSessionTxParams{
+		CreateSessionParams: randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+
+	event, err := testStore.CreateLoginEvent(context.Background(), CreateLoginEventParams{
+		Username:  user.Username,
+		SessionID: pgtype.UUID{Bytes: result.Session.ID, Valid: true},
+		Success:   true,
+		UserAgent: result.Session.UserAgent,
+		ClientIp:  result.Session.ClientIp,
+		NewDevice: true,
+	})
+	require.NoError(t, err)
+
+	tokenHash := util.RandomString(64)
+	err = testStore.SetLoginEventDenyToken(context.Background(), SetLoginEventDenyTokenParams{
+		ID:            event.ID,
+		DenyTokenHash: pgtype.Text{String: tokenHash, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// Looking the link up changes nothing
+	found, err := testStore.GetLoginEventByDenyToken(context.Background(), pgtype.Text{String: tokenHash, Valid: true})
+	require.NoError(t, err)
+	require.Equal(t, event.ID, found.ID)
+	require.False(t, found.DeniedAt.Valid)
+
+	denied, err := testStore.DenyLoginTx(context.Background(), tokenHash)
+	require.NoError(t, err)
+	require.True(t, denied.Blocked)
+	require.Equal(t, event.ID, denied.LoginEvent.ID)
+	require.True(t, denied.LoginEvent.DeniedAt.Valid)
+
+	session, err := testStore.GetSessionById(context.Background(), result.Session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+
+	// The link works once
+	_, err = testStore.DenyLoginTx(context.Background(), tokenHash)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = testStore.GetLoginEventByDenyToken(context.Background(), pgtype.Text{String: tokenHash, Valid: true})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
@@ -8,16 +8,52 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
+	ID            int64     `json:"id"`
+	Owner         string    `json:"owner"`
+	Balance       int64     `json:"balance"`
+	Currency      string    `json:"currency"`
+	CreatedAt     time.Time `json:"created_at"`
+	AccountNumber string    `json:"account_number"`
+}
+
+type ApiKey struct {
+	ID             uuid.UUID `json:"id"`
+	ServiceAccount string    `json:"service_account"`
+	KeyPrefix      string    `json:"key_prefix"`
+	// SHA-256 of the key, the key itself is only shown once
+	KeyHash string   `json:"key_hash"`
+	Scopes  []string `json:"scopes"`
+	// IPs or CIDR ranges the key may be used from, empty allows any
+	AllowedIps []string           `json:"allowed_ips"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+	CreatedBy  string             `json:"created_by"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID int64 `json:"id"`
+	// username of the banker or principal of the service account who performed the action
+	Actor  string `json:"actor"`
+	Action string `json:"action"`
+	// username or session id the action applies to
+	Target    string    `json:"target"`
+	Metadata  []byte    `json:"metadata"`
+	ClientIp  string    `json:"client_ip"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type Currency struct {
+	Code     string `json:"code"`
+	Exponent int32  `json:"exponent"`
+	Symbol   string `json:"symbol"`
+	Enabled  bool   `json:"enabled"`
+}
+
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
@@ -26,15 +62,165 @@ type Entry struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// identity documents uploaded by users for their KYC review
+type KycDocument struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// submission the document was sent with, null until the user submits their profile
+	ReviewID    pgtype.Int8 `json:"review_id"`
+	Kind        string      `json:"kind"`
+	FileName    string      `json:"file_name"`
+	ContentType string      `json:"content_type"`
+	Size        int64       `json:"size"`
+	// hex encoded SHA-256 of the plaintext document
+	Checksum string `json:"checksum"`
+	// key of the encrypted document in the blob store
+	BlobKey    string             `json:"blob_key"`
+	Status     string             `json:"status"`
+	Reviewer   string             `json:"reviewer"`
+	Note       string             `json:"note"`
+	ReviewedAt pgtype.Timestamptz `json:"reviewed_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// history of the KYC status changes of a user
+type KycReview struct {
+	ID         int64  `json:"id"`
+	Username   string `json:"username"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	// banker who changed the status, or the user when submitting their profile
+	Reviewer  string    `json:"reviewer"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// successful and failed logins shown to their user
+type LoginEvent struct {
+	ID            int64       `json:"id"`
+	Username      string      `json:"username"`
+	SessionID     pgtype.UUID `json:"session_id"`
+	Success       bool        `json:"success"`
+	FailureReason string      `json:"failure_reason"`
+	UserAgent     string      `json:"user_agent"`
+	ClientIp      string      `json:"client_ip"`
+	// the login came from a user agent or an address the user never logged in from
+	NewDevice bool `json:"new_device"`
+	// SHA-256 hex digest of the token of the "this wasn't me" link of the new device alert
+	DenyTokenHash pgtype.Text        `json:"deny_token_hash"`
+	DeniedAt      pgtype.Timestamptz `json:"denied_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type MfaChallenge struct {
+	ID          int64              `json:"id"`
+	Username    string             `json:"username"`
+	TokenHash   string             `json:"token_hash"`
+	DeviceLabel string             `json:"device_label"`
+	Attempts    int32              `json:"attempts"`
+	ExpiresAt   time.Time          `json:"expires_at"`
+	ConsumedAt  pgtype.Timestamptz `json:"consumed_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// pending authorization code logins, each state is consumed by a single callback
+type OidcLoginState struct {
+	ID int64 `json:"id"`
+	// SHA-256 hex digest of the state parameter
+	StateHash string `json:"state_hash"`
+	Nonce     string `json:"nonce"`
+	// PKCE code verifier, only its S256 challenge is sent to the provider
+	CodeVerifier string             `json:"code_verifier"`
+	DeviceLabel  string             `json:"device_label"`
+	ExpiresAt    time.Time          `json:"expires_at"`
+	ConsumedAt   pgtype.Timestamptz `json:"consumed_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+	// SHA-256 hex digest of the nonce of the login cookie, only the browser that began the login can complete it
+	BrowserHash string `json:"browser_hash"`
+}
+
+type OutboxMessage struct {
+	ID            int64              `json:"id"`
+	AggregateType string             `json:"aggregate_type"`
+	AggregateID   string             `json:"aggregate_id"`
+	TaskType      string             `json:"task_type"`
+	Payload       []byte             `json:"payload"`
+	Queue         string             `json:"queue"`
+	MaxRetry      int32              `json:"max_retry"`
+	ProcessAt     time.Time          `json:"process_at"`
+	Attempts      int32              `json:"attempts"`
+	LastError     pgtype.Text        `json:"last_error"`
+	ProcessedAt   pgtype.Timestamptz `json:"processed_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+	// set when the message ran out of publish attempts, it is no longer relayed
+	FailedAt pgtype.Timestamptz `json:"failed_at"`
+}
+
+// hashes of passwords replaced by a change or a reset, to block their reuse
+type PasswordHistory struct {
+	ID             int64     `json:"id"`
+	Username       string    `json:"username"`
+	HashedPassword string    `json:"hashed_password"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type PasswordReset struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	// SHA-256 hex digest of the reset token
+	TokenHash string    `json:"token_hash"`
+	IsUsed    bool      `json:"is_used"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+	// forgot password request the token was mailed for, retries of the email replace its token
+	RequestID uuid.UUID `json:"request_id"`
+}
+
+// one-time codes sent by SMS, only the latest unused code of a user and purpose is valid
+type PhoneCode struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// number the code was sent to, it only verifies this number
+	Phone   string `json:"phone"`
+	Purpose string `json:"purpose"`
+	// HMAC-SHA256 hex digest of the code keyed with PHONE_CODE_KEY
+	CodeHash  string             `json:"code_hash"`
+	Attempts  int32              `json:"attempts"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID        int64              `json:"id"`
+	Username  string             `json:"username"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
 type Session struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	// SHA-256 hex digest of the current refresh token
+	RefreshToken string             `json:"refresh_token"`
+	UserAgent    string             `json:"user_agent"`
+	ClientIp     string             `json:"client_ip"`
+	IsBlocked    bool               `json:"is_blocked"`
+	ExpiresAt    time.Time          `json:"expires_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+	DeviceLabel  string             `json:"device_label"`
+	LastSeenAt   time.Time          `json:"last_seen_at"`
+	RevokedAt    pgtype.Timestamptz `json:"revoked_at"`
+}
+
+type ServiceAccount struct {
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	Description string    `json:"description"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Transfer struct {
@@ -44,17 +230,51 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// opaque identifier returned to clients instead of the sequential id
+	PublicID uuid.UUID `json:"public_id"`
 }
 
 type User struct {
-	Username          string    `json:"username"`
-	HashedPassword    string    `json:"hashed_password"`
-	FullName          string    `json:"full_name"`
-	Email             string    `json:"email"`
-	CreatedAt         time.Time `json:"created_at"`
-	PasswordChangedAt time.Time `json:"password_changed_at"`
-	IsEmailVerified   bool      `json:"is_email_verified"`
-	Role              string    `json:"role"`
+	Username          string      `json:"username"`
+	HashedPassword    string      `json:"hashed_password"`
+	FullName          string      `json:"full_name"`
+	Email             string      `json:"email"`
+	CreatedAt         time.Time   `json:"created_at"`
+	PasswordChangedAt time.Time   `json:"password_changed_at"`
+	IsEmailVerified   bool        `json:"is_email_verified"`
+	Role              string      `json:"role"`
+	DateOfBirth       pgtype.Date `json:"date_of_birth"`
+	// E.164 phone number
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+	// ISO 3166-1 alpha-2 country code
+	Nationality string `json:"nationality"`
+	KycStatus   string `json:"kyc_status"`
+	// a verified phone is unique and can be used as a transfer alias and for 2FA
+	IsPhoneVerified bool `json:"is_phone_verified"`
+}
+
+// accounts of external OpenID Connect providers linked to a user
+type UserIdentity struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Issuer   string `json:"issuer"`
+	// sub claim of the ID token, unique and stable within the issuer
+	Subject string `json:"subject"`
+	// email claim of the last login, informative only
+	Email       string    `json:"email"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type UserTotp struct {
+	Username string `json:"username"`
+	// AES-GCM encrypted TOTP secret
+	SecretCiphertext []byte `json:"secret_ciphertext"`
+	// time step of the last accepted code, older or equal codes are rejected
+	LastUsedStep int64              `json:"last_used_step"`
+	ConfirmedAt  pgtype.Timestamptz `json:"confirmed_at"`
+	CreatedAt    time.Time          `json:"created_at"`
 }
 
 type VerifyEmail struct {
@@ -66,3 +286,30 @@ type VerifyEmail struct {
 	CreatedAt  time.Time `json:"created_at"`
 	ExpiredAt  time.Time `json:"expired_at"`
 }
+
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	EndpointID     int64              `json:"endpoint_id"`
+	EventID        uuid.UUID          `json:"event_id"`
+	EventType      string             `json:"event_type"`
+	Payload        []byte             `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	ResponseStatus pgtype.Int4        `json:"response_status"`
+	ResponseBody   pgtype.Text        `json:"response_body"`
+	LastError      pgtype.Text        `json:"last_error"`
+	DeliveredAt    pgtype.Timestamptz `json:"delivered_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+type WebhookEndpoint struct {
+	ID                      int64              `json:"id"`
+	Owner                   string             `json:"owner"`
+	Url                     string             `json:"url"`
+	Secret                  string             `json:"secret"`
+	PreviousSecret          pgtype.Text        `json:"previous_secret"`
+	PreviousSecretExpiresAt pgtype.Timestamptz `json:"previous_secret_expires_at"`
+	EventTypes              []string           `json:"event_types"`
+	IsActive                bool               `json:"is_active"`
+	CreatedAt               time.Time          `json:"created_at"`
+}
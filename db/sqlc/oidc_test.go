@@ -0,0 +1,142 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "https://idp.example.com"
+
+func createRandomOidcUser(t *testing.T) CreateOidcUserTxResult {
+	arg := CreateOidcUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.RandomOwner(),
+			HashedPassword: util.RandomString(32),
+			FullName:       util.RandomOwner(),
+			Email:          util.RandomEmail(),
+		},
+		Role:            util.BankerRole,
+		IsEmailVerified: true,
+		Issuer:          testIssuer,
+		Subject:         util.RandomString(16),
+	}
+
+	result, err := testStore.CreateOidcUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, result.User.Username)
+	require.Equal(t, util.BankerRole, result.User.Role)
+	require.True(t, result.User.IsEmailVerified)
+	require.Equal(t, arg.Username, result.Identity.Username)
+	require.Equal(t, arg.Subject, result.Identity.Subject)
+	require.Equal(t, arg.Email, result.Identity.Email)
+
+	return result
+}
+
+func TestCreateOidcUserTx(t *testing.T) {
+	created := createRandomOidcUser(t)
+
+	identity, err := testStore.GetUserIdentity(context.Background(), GetUserIdentityParams{
+		Issuer:  testIssuer,
+		Subject: created.Identity.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, created.Identity.ID, identity.ID)
+
+	err = testStore.TouchUserIdentity(context.Background(), TouchUserIdentityParams{
+		ID:    identity.ID,
+		Email: "renamed@example.com",
+	})
+	require.NoError(t, err)
+
+	touched, err := testStore.GetUserIdentity(context.Background(), GetUserIdentityParams{
+		Issuer:  testIssuer,
+		Subject: created.Identity.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "renamed@example.com", touched.Email)
+	require.False(t, touched.LastLoginAt.Before(identity.LastLoginAt))
+
+	// An account of the provider is linked to a single user
+	_, err = testStore.CreateOidcUserTx(context.Background(), CreateOidcUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.RandomOwner(),
+			HashedPassword: util.RandomString(32),
+			FullName:       util.RandomOwner(),
+			Email:          util.RandomEmail(),
+		},
+		Issuer:  testIssuer,
+		Subject: created.Identity.Subject,
+	})
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+	require.Equal(t, UserIdentityConstraint, ConstraintName(err))
+
+	// The email of a local user is never taken over
+	_, err = testStore.CreateOidcUserTx(context.Background(), CreateOidcUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.RandomOwner(),
+			HashedPassword: util.RandomString(32),
+			FullName:       util.RandomOwner(),
+			Email:          created.User.Email,
+		},
+		Issuer:  testIssuer,
+		Subject: util.RandomString(16),
+	})
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+	require.Equal(t, UserEmailConstraint, ConstraintName(err))
+}
+
+func TestConsumeOidcLoginState(t *testing.T) {
+	arg := CreateOidcLoginStateParams{
+		StateHash:    util.RandomString(64),
+		Nonce:        util.RandomString(43),
+		CodeVerifier: util.RandomString(43),
+		DeviceLabel:  "laptop",
+		ExpiresAt:    time.Now().Add(time.Minute),
+		BrowserHash:  util.RandomString(64),
+	}
+
+	loginState, err := testStore.CreateOidcLoginState(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, loginState.ConsumedAt.Valid)
+
+	// Another browser can neither complete nor burn the login
+	_, err = testStore.ConsumeOidcLoginState(context.Background(), ConsumeOidcLoginStateParams{
+		StateHash:   arg.StateHash,
+		BrowserHash: util.RandomString(64),
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	consumeArg := ConsumeOidcLoginStateParams{StateHash: arg.StateHash, BrowserHash: arg.BrowserHash}
+
+	consumed, err := testStore.ConsumeOidcLoginState(context.Background(), consumeArg)
+	require.NoError(t, err)
+	require.Equal(t, loginState.ID, consumed.ID)
+	require.Equal(t, arg.CodeVerifier, consumed.CodeVerifier)
+	require.True(t, consumed.ConsumedAt.Valid)
+
+	// A state is consumed once
+	_, err = testStore.ConsumeOidcLoginState(context.Background(), consumeArg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	expired, err := testStore.CreateOidcLoginState(context.Background(), CreateOidcLoginStateParams{
+		StateHash:   util.RandomString(64),
+		ExpiresAt:   time.Now().Add(-time.Minute),
+		BrowserHash: util.RandomString(64),
+	})
+	require.NoError(t, err)
+
+	deleted, err := testStore.DeleteExpiredOidcLoginStates(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	_, err = testStore.ConsumeOidcLoginState(context.Background(), ConsumeOidcLoginStateParams{
+		StateHash:   expired.StateHash,
+		BrowserHash: expired.BrowserHash,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
@@ -0,0 +1,147 @@
+package sqlc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOutboxMessage(t *testing.T, aggregateID string) OutboxMessage {
+	arg := CreateOutboxMessageParams{
+		AggregateType: "test",
+		AggregateID:   aggregateID,
+		TaskType:      "task:test",
+		Payload:       []byte(`{"value":"` + util.RandomString(6) + `"}`),
+		Queue:         "default",
+		MaxRetry:      5,
+		ProcessAt:     time.Now(),
+	}
+
+	message, err := testStore.CreateOutboxMessage(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, message.ID)
+
+	require.Equal(t, arg.AggregateID, message.AggregateID)
+	require.Equal(t, arg.TaskType, message.TaskType)
+	require.JSONEq(t, string(arg.Payload), string(message.Payload))
+	require.Zero(t, message.Attempts)
+	require.False(t, message.ProcessedAt.Valid)
+
+	return message
+}
+
+func TestCreateOutboxMessage(t *testing.T) {
+	createRandomOutboxMessage(t, util.RandomString(6))
+}
+
+func TestRelayOutboxTx(t *testing.T) {
+	aggregate1 := util.RandomString(8)
+	aggregate2 := util.RandomString(8)
+
+	failing := createRandomOutboxMessage(t, aggregate1)
+	heldBack := createRandomOutboxMessage(t, aggregate1)
+	other := createRandomOutboxMessage(t, aggregate2)
+
+	published := map[int64]bool{}
+	result, err := testStore.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+		BatchSize:   1000,
+		MaxAttempts: 5,
+		Publish: func(message OutboxMessage) error {
+			if message.ID == failing.ID {
+				return errors.New("queue is down")
+			}
+			published[message.ID] = true
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, result.Locked)
+	require.GreaterOrEqual(t, result.Failed, 1)
+	require.Empty(t, result.DeadLettered)
+
+	// a failed message holds back the rest of its aggregate, other aggregates go on
+	require.False(t, published[heldBack.ID])
+	require.True(t, published[other.ID])
+
+	// the next run publishes the aggregate in order
+	var order []int64
+	_, err = testStore.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+		BatchSize:   1000,
+		MaxAttempts: 5,
+		Publish: func(message OutboxMessage) error {
+			if message.AggregateID == aggregate1 {
+				order = append(order, message.ID)
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{failing.ID, heldBack.ID}, order)
+}
+
+func TestRelayOutboxTxDeadLetter(t *testing.T) {
+	aggregate := util.RandomString(8)
+
+	failing := createRandomOutboxMessage(t, aggregate)
+	heldBack := createRandomOutboxMessage(t, aggregate)
+
+	relay := func(t *testing.T) (RelayOutboxTxResult, map[int64]bool) {
+		published := map[int64]bool{}
+		result, err := testStore.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+			BatchSize:   1000,
+			MaxAttempts: 2,
+			Publish: func(message OutboxMessage) error {
+				if message.ID == failing.ID {
+					return errors.New("payload rejected")
+				}
+				published[message.ID] = true
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		return result, published
+	}
+
+	result, published := relay(t)
+	require.Empty(t, result.DeadLettered)
+	require.False(t, published[heldBack.ID])
+
+	// the last attempt gives up on the message and releases the rest of its aggregate
+	result, _ = relay(t)
+	require.Len(t, result.DeadLettered, 1)
+	require.Equal(t, failing.ID, result.DeadLettered[0].ID)
+	require.Equal(t, int32(2), result.DeadLettered[0].Attempts)
+	require.True(t, result.DeadLettered[0].FailedAt.Valid)
+	require.Equal(t, "payload rejected", result.DeadLettered[0].LastError.String)
+
+	_, published = relay(t)
+	require.False(t, published[failing.ID])
+	require.True(t, published[heldBack.ID])
+
+	pending, err := testStore.ListPendingOutboxMessages(context.Background(), 1000)
+	require.NoError(t, err)
+	for _, p := range pending {
+		require.NotEqual(t, failing.ID, p.ID)
+	}
+}
+
+func TestDeleteProcessedOutboxMessages(t *testing.T) {
+	message := createRandomOutboxMessage(t, util.RandomString(6))
+
+	err := testStore.MarkOutboxMessageProcessed(context.Background(), message.ID)
+	require.NoError(t, err)
+
+	deleted, err := testStore.DeleteProcessedOutboxMessages(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	pending, err := testStore.ListPendingOutboxMessages(context.Background(), 1000)
+	require.NoError(t, err)
+	for _, p := range pending {
+		require.NotEqual(t, message.ID, p.ID)
+	}
+}
@@ -0,0 +1,110 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordReset(t *testing.T, user User) PasswordReset {
+	arg := CreatePasswordResetParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		TokenHash: util.RandomString(64),
+		RequestID: uuid.New(),
+	}
+
+	reset, err := testStore.CreatePasswordReset(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.TokenHash, reset.TokenHash)
+	require.False(t, reset.IsUsed)
+	require.WithinDuration(t, time.Now().Add(15*time.Minute), reset.ExpiredAt, time.Minute)
+
+	return reset
+}
+
+func TestCreatePasswordResetRetry(t *testing.T) {
+	user := createRandomUser(t)
+	reset := createRandomPasswordReset(t, user)
+
+	// A retry of the request replaces its token instead of adding a second one
+	arg := CreatePasswordResetParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		TokenHash: util.RandomString(64),
+		RequestID: reset.RequestID,
+	}
+
+	retried, err := testStore.CreatePasswordReset(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, reset.ID, retried.ID)
+	require.Equal(t, arg.TokenHash, retried.TokenHash)
+
+	_, err = testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:      reset.TokenHash,
+		HashedPassword: util.RandomString(60),
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:      retried.TokenHash,
+		HashedPassword: util.RandomString(60),
+	})
+	require.NoError(t, err)
+
+	// Once used the token stays as it is
+	arg.TokenHash = util.RandomString(64)
+	_, err = testStore.CreatePasswordReset(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	user := createRandomUser(t)
+
+	found, err := testStore.GetUserByEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, found.Username)
+
+	_, err = testStore.GetUserByEmail(context.Background(), util.RandomEmail())
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	user := createRandomUser(t)
+	reset := createRandomPasswordReset(t, user)
+	pending := createRandomPasswordReset(t, user)
+
+	_, err := testStore.CreateSessionTx(context.Background(), CreateSessionTxParams{
+		CreateSessionParams: randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)),
+		MaxSessions:         5,
+	})
+	require.NoError(t, err)
+
+	arg := ResetPasswordTxParams{
+		TokenHash:      reset.TokenHash,
+		HashedPassword: util.RandomString(60),
+	}
+
+	result, err := testStore.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.HashedPassword, result.User.HashedPassword)
+	require.WithinDuration(t, time.Now(), result.User.PasswordChangedAt, time.Minute)
+	require.Len(t, result.RevokedSessions, 1)
+
+	sessions, err := testStore.ListSessionsByUserName(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	// Neither the used token nor other pending tokens of the user work afterwards
+	for _, tokenHash := range []string{reset.TokenHash, pending.TokenHash} {
+		_, err = testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+			TokenHash:      tokenHash,
+			HashedPassword: util.RandomString(60),
+		})
+		require.ErrorIs(t, err, ErrRecordNotFound)
+	}
+}
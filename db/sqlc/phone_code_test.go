@@ -0,0 +1,139 @@
+package sqlc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUserWithPhone(t *testing.T) User {
+	user := createRandomUser(t)
+
+	user, err := testStore.UpdateUser(context.Background(), UpdateUserParams{
+		Username: user.Username,
+		Phone:    pgtype.Text{String: fmt.Sprintf("+1555%07d", util.RandomInt(0, 9_999_999)), Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, user.IsPhoneVerified)
+
+	return user
+}
+
+func createRandomPhoneCode(t *testing.T, user User, purpose string) PhoneCode {
+	arg := CreatePhoneCodeParams{
+		Username:  user.Username,
+		Phone:     user.Phone,
+		Purpose:   purpose,
+		CodeHash:  util.RandomString(64),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	phoneCode, err := testStore.CreatePhoneCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Phone, phoneCode.Phone)
+	require.Equal(t, arg.CodeHash, phoneCode.CodeHash)
+	require.Zero(t, phoneCode.Attempts)
+	require.False(t, phoneCode.UsedAt.Valid)
+
+	return phoneCode
+}
+
+func TestGetLatestPhoneCode(t *testing.T) {
+	user := createRandomUserWithPhone(t)
+	createRandomPhoneCode(t, user, "verify_phone")
+	latest := createRandomPhoneCode(t, user, "verify_phone")
+	createRandomPhoneCode(t, user, "login")
+
+	phoneCode, err := testStore.GetLatestPhoneCode(context.Background(), GetLatestPhoneCodeParams{
+		Username: user.Username,
+		Purpose:  "verify_phone",
+	})
+	require.NoError(t, err)
+	require.Equal(t, latest.ID, phoneCode.ID)
+
+	attemptArg := IncrementPhoneCodeAttemptsParams{ID: latest.ID, MaxAttempts: 2}
+
+	attempted, err := testStore.IncrementPhoneCodeAttempts(context.Background(), attemptArg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempted.Attempts)
+
+	attempted, err = testStore.IncrementPhoneCodeAttempts(context.Background(), attemptArg)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempted.Attempts)
+
+	// Once max_attempts were taken no guess gets through
+	_, err = testStore.IncrementPhoneCodeAttempts(context.Background(), attemptArg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	// Invalidated codes can no longer be used
+	err = testStore.InvalidatePhoneCodes(context.Background(), InvalidatePhoneCodesParams{
+		Username: user.Username,
+		Purpose:  "verify_phone",
+	})
+	require.NoError(t, err)
+
+	phoneCode, err = testStore.GetLatestPhoneCode(context.Background(), GetLatestPhoneCodeParams{
+		Username: user.Username,
+		Purpose:  "verify_phone",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), phoneCode.Attempts)
+	require.True(t, phoneCode.UsedAt.Valid)
+}
+
+func TestVerifyPhoneTx(t *testing.T) {
+	user := createRandomUserWithPhone(t)
+	phoneCode := createRandomPhoneCode(t, user, "verify_phone")
+
+	arg := VerifyPhoneTxParams{
+		CodeID:   phoneCode.ID,
+		Username: user.Username,
+		Phone:    user.Phone,
+	}
+
+	result, err := testStore.VerifyPhoneTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.User.IsPhoneVerified)
+	require.True(t, result.PhoneCode.UsedAt.Valid)
+
+	// A code is used once
+	_, err = testStore.VerifyPhoneTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	account, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:         user.Username,
+		Currency:      util.RandomCurrency(),
+		AccountNumber: util.RandomAccountNumber(),
+	})
+	require.NoError(t, err)
+
+	aliased, err := testStore.GetAccountByVerifiedPhone(context.Background(), GetAccountByVerifiedPhoneParams{
+		Phone:    user.Phone,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, aliased.ID)
+
+	// A number verified by one user cannot be verified by another
+	other := createRandomUserWithPhone(t)
+	other, err = testStore.UpdateUser(context.Background(), UpdateUserParams{
+		Username: other.Username,
+		Phone:    pgtype.Text{String: user.Phone, Valid: true},
+	})
+	require.NoError(t, err)
+
+	otherCode := createRandomPhoneCode(t, other, "verify_phone")
+
+	_, err = testStore.VerifyPhoneTx(context.Background(), VerifyPhoneTxParams{
+		CodeID:   otherCode.ID,
+		Username: other.Username,
+		Phone:    other.Phone,
+	})
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+	require.Equal(t, VerifiedPhoneConstraint, ConstraintName(err))
+}
@@ -6,38 +6,148 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	// The balance is checked on the locked row, no row is returned when it would become negative
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	// Links the documents uploaded since the last submission to the review of a new one
+	AttachKycDocuments(ctx context.Context, arg AttachKycDocumentsParams) (int64, error)
+	BlockSession(ctx context.Context, id uuid.UUID) error
+	ConfirmUserTotp(ctx context.Context, arg ConfirmUserTotpParams) (UserTotp, error)
+	ConsumeMfaChallenge(ctx context.Context, id int64) (MfaChallenge, error)
+	// A callback from another browser neither finds nor consumes the state
+	ConsumeOidcLoginState(ctx context.Context, arg ConsumeOidcLoginStateParams) (OidcLoginState, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateKycDocument(ctx context.Context, arg CreateKycDocumentParams) (KycDocument, error)
+	CreateKycReview(ctx context.Context, arg CreateKycReviewParams) (KycReview, error)
+	// Nothing is inserted for an unknown username so failed guesses do not fill the table
+	CreateLoginEvent(ctx context.Context, arg CreateLoginEventParams) (LoginEvent, error)
+	CreateMfaChallenge(ctx context.Context, arg CreateMfaChallengeParams) (MfaChallenge, error)
+	CreateOidcLoginState(ctx context.Context, arg CreateOidcLoginStateParams) (OidcLoginState, error)
+	CreateOutboxMessage(ctx context.Context, arg CreateOutboxMessageParams) (OutboxMessage, error)
+	CreatePasswordHistory(ctx context.Context, arg CreatePasswordHistoryParams) (PasswordHistory, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	CreatePhoneCode(ctx context.Context, arg CreatePhoneCodeParams) (PhoneCode, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (ServiceAccount, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
 	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
 	DeleteAccount(ctx context.Context, id int64) error
 	DeleteEntry(ctx context.Context, id int64) error
-	DeleteSession(ctx context.Context, id uuid.UUID) error
+	DeleteExpiredMfaChallenges(ctx context.Context, username string) (int64, error)
+	DeleteExpiredOidcLoginStates(ctx context.Context) (int64, error)
+	// Revoked and blocked sessions are kept until they expire so their history stays
+	DeleteExpiredSessions(ctx context.Context, username string) (int64, error)
+	DeleteOldestSessions(ctx context.Context, arg DeleteOldestSessionsParams) ([]uuid.UUID, error)
+	DeleteProcessedOutboxMessages(ctx context.Context, processedBefore time.Time) (int64, error)
+	DeleteRecoveryCodes(ctx context.Context, username string) error
+	DeleteUserTotp(ctx context.Context, username string) error
+	DeleteWebhookEndpoint(ctx context.Context, id int64) error
+	DenyLoginEvent(ctx context.Context, denyTokenHash pgtype.Text) (LoginEvent, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error)
+	// Resolves a phone alias to the account of its owner in the currency of the transfer
+	GetAccountByVerifiedPhone(ctx context.Context, arg GetAccountByVerifiedPhoneParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetApiKeyByHash(ctx context.Context, keyHash string) (GetApiKeyByHashRow, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
 	GetEntryByAccountId(ctx context.Context, accountID int64) (Entry, error)
+	GetKycDocument(ctx context.Context, id int64) (KycDocument, error)
+	GetLatestPhoneCode(ctx context.Context, arg GetLatestPhoneCodeParams) (PhoneCode, error)
+	GetLoginEvent(ctx context.Context, id int64) (LoginEvent, error)
+	// Only finds logins that were not denied yet, the link works once
+	GetLoginEventByDenyToken(ctx context.Context, denyTokenHash pgtype.Text) (LoginEvent, error)
+	// Denied logins do not make their device or address known
+	GetLoginSource(ctx context.Context, arg GetLoginSourceParams) (GetLoginSourceRow, error)
+	GetMfaChallengeByTokenHash(ctx context.Context, tokenHash string) (MfaChallenge, error)
+	GetPasswordResetByTokenHash(ctx context.Context, tokenHash string) (PasswordReset, error)
+	GetServiceAccount(ctx context.Context, name string) (ServiceAccount, error)
 	GetSessionById(ctx context.Context, id uuid.UUID) (GetSessionByIdRow, error)
-	GetSessionByUserName(ctx context.Context, username string) (GetSessionByUserNameRow, error)
+	GetSessionForUpdate(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
 	GetTransfers(ctx context.Context, arg GetTransfersParams) ([]GetTransfersRow, error)
 	GetTransfersByFromAccountId(ctx context.Context, fromAccountID int64) ([]GetTransfersByFromAccountIdRow, error)
 	GetTransfersByToAccountId(ctx context.Context, toAccountID int64) ([]GetTransfersByToAccountIdRow, error)
+	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
 	GetUserByUserName(ctx context.Context, username string) (GetUserByUserNameRow, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	GetUserTotp(ctx context.Context, username string) (UserTotp, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error)
+	IncrementMfaChallengeAttempts(ctx context.Context, arg IncrementMfaChallengeAttemptsParams) (MfaChallenge, error)
+	// Takes one attempt of the code before it is compared, no row is returned once max_attempts were taken
+	IncrementPhoneCodeAttempts(ctx context.Context, arg IncrementPhoneCodeAttemptsParams) (PhoneCode, error)
+	InvalidatePasswordResets(ctx context.Context, username string) error
+	InvalidatePhoneCodes(ctx context.Context, arg InvalidatePhoneCodesParams) error
+	InvalidateVerifyEmails(ctx context.Context, username string) error
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListApiKeys(ctx context.Context, serviceAccount string) ([]ApiKey, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntriesByAccountId(ctx context.Context, arg ListEntriesByAccountIdParams) ([]Entry, error)
+	ListKycDocuments(ctx context.Context, arg ListKycDocumentsParams) ([]KycDocument, error)
+	ListKycReviews(ctx context.Context, arg ListKycReviewsParams) ([]KycReview, error)
+	ListLoginEvents(ctx context.Context, arg ListLoginEventsParams) ([]LoginEvent, error)
+	ListPasswordHistory(ctx context.Context, arg ListPasswordHistoryParams) ([]string, error)
+	ListPendingOutboxMessages(ctx context.Context, limit int32) ([]OutboxMessage, error)
+	ListServiceAccounts(ctx context.Context, arg ListServiceAccountsParams) ([]ServiceAccount, error)
+	ListSessionsByUserName(ctx context.Context, username string) ([]Session, error)
+	ListTransfersByOwner(ctx context.Context, arg ListTransfersByOwnerParams) ([]ListTransfersByOwnerRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookEndpoints(ctx context.Context, arg ListWebhookEndpointsParams) ([]WebhookEndpoint, error)
+	ListWebhookEndpointsForEvent(ctx context.Context, arg ListWebhookEndpointsForEventParams) ([]WebhookEndpoint, error)
+	MarkOutboxMessageFailed(ctx context.Context, arg MarkOutboxMessageFailedParams) (OutboxMessage, error)
+	MarkOutboxMessageProcessed(ctx context.Context, id int64) error
+	// Only verifies the number the code was sent to, a number changed in the meantime stays unverified
+	MarkPhoneVerified(ctx context.Context, arg MarkPhoneVerifiedParams) (User, error)
+	PrunePasswordHistory(ctx context.Context, arg PrunePasswordHistoryParams) error
+	ResetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	// Only reviews the document when it still has the status the decision was made on
+	ReviewKycDocument(ctx context.Context, arg ReviewKycDocumentParams) (KycDocument, error)
+	RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (ApiKey, error)
+	// Sessions already revoked or expired keep their state
+	RevokeOtherSessions(ctx context.Context, arg RevokeOtherSessionsParams) ([]uuid.UUID, error)
+	RevokeSession(ctx context.Context, id uuid.UUID) error
+	// Sessions already revoked or expired keep their state
+	RevokeSessionsByUserName(ctx context.Context, username string) ([]uuid.UUID, error)
+	RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (Session, error)
+	RotateWebhookEndpointSecret(ctx context.Context, arg RotateWebhookEndpointSecretParams) (WebhookEndpoint, error)
+	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error)
+	SetLoginEventDenyToken(ctx context.Context, arg SetLoginEventDenyTokenParams) error
+	SetSessionBlocked(ctx context.Context, arg SetSessionBlockedParams) (Session, error)
+	// Transfers between accounts of the same owner are not counted
+	SumOutgoingTransfersSince(ctx context.Context, arg SumOutgoingTransfersSinceParams) (int64, error)
+	TouchSession(ctx context.Context, id uuid.UUID) error
+	TouchUserIdentity(ctx context.Context, arg TouchUserIdentityParams) error
+	TryLockOutboxRelay(ctx context.Context) (bool, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
 	UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	// Only moves the status when it is still the one the change was decided on
+	UpdateUserKycStatus(ctx context.Context, arg UpdateUserKycStatusParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
 	UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (VerifyEmail, error)
+	UpdateWebhookDeliveryAttempt(ctx context.Context, arg UpdateWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	UpdateWebhookEndpoint(ctx context.Context, arg UpdateWebhookEndpointParams) (WebhookEndpoint, error)
+	UpsertPendingUserTotp(ctx context.Context, arg UpsertPendingUserTotpParams) (UserTotp, error)
+	UsePasswordReset(ctx context.Context, tokenHash string) (PasswordReset, error)
+	UsePhoneCode(ctx context.Context, id int64) (PhoneCode, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	UseUserTotpStep(ctx context.Context, arg UseUserTotpStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -10,8 +10,24 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const blockSession = `-- name: BlockSession :exec
+UPDATE
+    sessions
+SET
+    is_blocked = true,
+    revoked_at = COALESCE(revoked_at, now())
+WHERE
+    id = $1
+`
+
+func (q *Queries) BlockSession(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, blockSession, id)
+	return err
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO
     sessions (
@@ -21,10 +37,11 @@ INSERT INTO
         user_agent,
         client_ip,
         is_blocked,
-        expires_at
+        expires_at,
+        device_label
     )
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, device_label, last_seen_at, revoked_at
 `
 
 type CreateSessionParams struct {
@@ -35,6 +52,7 @@ type CreateSessionParams struct {
 	ClientIp     string    `json:"client_ip"`
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	DeviceLabel  string    `json:"device_label"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
@@ -46,6 +64,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		arg.ClientIp,
 		arg.IsBlocked,
 		arg.ExpiresAt,
+		arg.DeviceLabel,
 	)
 	var i Session
 	err := row.Scan(
@@ -57,46 +76,106 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.DeviceLabel,
+		&i.LastSeenAt,
+		&i.RevokedAt,
 	)
 	return i, err
 }
 
-const deleteSession = `-- name: DeleteSession :exec
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :execrows
 DELETE FROM
     sessions
 WHERE
-    id = $1
+    username = $1
+    AND expires_at <= now()
 `
 
-func (q *Queries) DeleteSession(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteSession, id)
-	return err
+// Revoked and blocked sessions are kept until they expire so their history stays
+func (q *Queries) DeleteExpiredSessions(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredSessions, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteOldestSessions = `-- name: DeleteOldestSessions :many
+DELETE FROM
+    sessions
+WHERE
+    id IN (
+        SELECT
+            id
+        FROM
+            sessions
+        WHERE
+            username = $1
+            AND revoked_at IS NULL
+        ORDER BY
+            created_at DESC,
+            id
+        OFFSET $2::int
+    )
+RETURNING id
+`
+
+type DeleteOldestSessionsParams struct {
+	Username string `json:"username"`
+	Keep     int32  `json:"keep"`
+}
+
+func (q *Queries) DeleteOldestSessions(ctx context.Context, arg DeleteOldestSessionsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, deleteOldestSessions, arg.Username, arg.Keep)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getSessionById = `-- name: GetSessionById :one
 SELECT
-    id,
-    username,
-    refresh_token,
-    user_agent,
-    client_ip,
-    is_blocked,
-    expires_at
+    sessions.id,
+    sessions.username,
+    sessions.refresh_token,
+    sessions.user_agent,
+    sessions.client_ip,
+    sessions.is_blocked,
+    sessions.expires_at,
+    sessions.revoked_at,
+    sessions.last_seen_at,
+    users.password_changed_at
 FROM
     sessions
+    JOIN users ON users.username = sessions.username
 WHERE
-    id = $1
+    sessions.id = $1
 LIMIT 1
 `
 
 type GetSessionByIdRow struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
+	ID                uuid.UUID          `json:"id"`
+	Username          string             `json:"username"`
+	RefreshToken      string             `json:"refresh_token"`
+	UserAgent         string             `json:"user_agent"`
+	ClientIp          string             `json:"client_ip"`
+	IsBlocked         bool               `json:"is_blocked"`
+	ExpiresAt         time.Time          `json:"expires_at"`
+	RevokedAt         pgtype.Timestamptz `json:"revoked_at"`
+	LastSeenAt        time.Time          `json:"last_seen_at"`
+	PasswordChangedAt time.Time          `json:"password_changed_at"`
 }
 
 func (q *Queries) GetSessionById(ctx context.Context, id uuid.UUID) (GetSessionByIdRow, error) {
@@ -110,39 +189,196 @@ func (q *Queries) GetSessionById(ctx context.Context, id uuid.UUID) (GetSessionB
 		&i.ClientIp,
 		&i.IsBlocked,
 		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.LastSeenAt,
+		&i.PasswordChangedAt,
 	)
 	return i, err
 }
 
-const getSessionByUserName = `-- name: GetSessionByUserName :one
+const getSessionForUpdate = `-- name: GetSessionForUpdate :one
 SELECT
-    id,
-    username,
-    refresh_token,
-    user_agent,
-    client_ip,
-    is_blocked,
-    expires_at
+    id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, device_label, last_seen_at, revoked_at
 FROM
     sessions
 WHERE
-    username = $1
+    id = $1
 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetSessionForUpdate(ctx context.Context, id uuid.UUID) (Session, error) {
+	row := q.db.QueryRow(ctx, getSessionForUpdate, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.DeviceLabel,
+		&i.LastSeenAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const listSessionsByUserName = `-- name: ListSessionsByUserName :many
+SELECT
+    id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, device_label, last_seen_at, revoked_at
+FROM
+    sessions
+WHERE
+    username = $1
+    AND expires_at > now()
+    AND revoked_at IS NULL
+ORDER BY
+    last_seen_at DESC
+`
+
+func (q *Queries) ListSessionsByUserName(ctx context.Context, username string) ([]Session, error) {
+	rows, err := q.db.Query(ctx, listSessionsByUserName, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.DeviceLabel,
+			&i.LastSeenAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeOtherSessions = `-- name: RevokeOtherSessions :many
+UPDATE
+    sessions
+SET
+    revoked_at = now()
+WHERE
+    username = $1
+    AND id <> $2
+    AND revoked_at IS NULL
+    AND expires_at > now()
+RETURNING id
 `
 
-type GetSessionByUserNameRow struct {
+type RevokeOtherSessionsParams struct {
+	Username string    `json:"username"`
+	ID       uuid.UUID `json:"id"`
+}
+
+// Sessions already revoked or expired keep their state
+func (q *Queries) RevokeOtherSessions(ctx context.Context, arg RevokeOtherSessionsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, revokeOtherSessions, arg.Username, arg.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeSession = `-- name: RevokeSession :exec
+UPDATE
+    sessions
+SET
+    revoked_at = now()
+WHERE
+    id = $1
+    AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeSession(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, revokeSession, id)
+	return err
+}
+
+const revokeSessionsByUserName = `-- name: RevokeSessionsByUserName :many
+UPDATE
+    sessions
+SET
+    revoked_at = now()
+WHERE
+    username = $1
+    AND revoked_at IS NULL
+    AND expires_at > now()
+RETURNING id
+`
+
+// Sessions already revoked or expired keep their state
+func (q *Queries) RevokeSessionsByUserName(ctx context.Context, username string) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, revokeSessionsByUserName, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateSessionRefreshToken = `-- name: RotateSessionRefreshToken :one
+UPDATE
+    sessions
+SET
+    refresh_token = $2,
+    client_ip = $3,
+    last_seen_at = now()
+WHERE
+    id = $1
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, device_label, last_seen_at, revoked_at
+`
+
+type RotateSessionRefreshTokenParams struct {
 	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
 	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
 	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
 }
 
-func (q *Queries) GetSessionByUserName(ctx context.Context, username string) (GetSessionByUserNameRow, error) {
-	row := q.db.QueryRow(ctx, getSessionByUserName, username)
-	var i GetSessionByUserNameRow
+func (q *Queries) RotateSessionRefreshToken(ctx context.Context, arg RotateSessionRefreshTokenParams) (Session, error) {
+	row := q.db.QueryRow(ctx, rotateSessionRefreshToken, arg.ID, arg.RefreshToken, arg.ClientIp)
+	var i Session
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
@@ -151,6 +387,58 @@ func (q *Queries) GetSessionByUserName(ctx context.Context, username string) (Ge
 		&i.ClientIp,
 		&i.IsBlocked,
 		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.DeviceLabel,
+		&i.LastSeenAt,
+		&i.RevokedAt,
 	)
 	return i, err
 }
+
+const setSessionBlocked = `-- name: SetSessionBlocked :one
+UPDATE
+    sessions
+SET
+    is_blocked = $2
+WHERE
+    id = $1
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at, device_label, last_seen_at, revoked_at
+`
+
+type SetSessionBlockedParams struct {
+	ID        uuid.UUID `json:"id"`
+	IsBlocked bool      `json:"is_blocked"`
+}
+
+func (q *Queries) SetSessionBlocked(ctx context.Context, arg SetSessionBlockedParams) (Session, error) {
+	row := q.db.QueryRow(ctx, setSessionBlocked, arg.ID, arg.IsBlocked)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.DeviceLabel,
+		&i.LastSeenAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const touchSession = `-- name: TouchSession :exec
+UPDATE
+    sessions
+SET
+    last_seen_at = now()
+WHERE
+    id = $1
+`
+
+func (q *Queries) TouchSession(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, touchSession, id)
+	return err
+}
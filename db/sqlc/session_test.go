@@ -0,0 +1,181 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func randomCreateSessionParams(username string, expiresAt time.Time) CreateSessionParams {
+	return CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(6),
+		ClientIp:     util.RandomString(6),
+		ExpiresAt:    expiresAt,
+		DeviceLabel:  util.RandomString(6),
+	}
+}
+
+func TestCreateSessionTxEvictsOldest(t *testing.T) {
+	user := createRandomUser(t)
+	maxSessions := int32(3)
+
+	var created []Session
+	for i := 0; i < 5; i++ {
+		arg := CreateSessionTxParams{
+			CreateSessionParams: randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)),
+			MaxSessions:         maxSessions,
+		}
+
+		result, err := testStore.CreateSessionTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, arg.ID, result.Session.ID)
+		require.Equal(t, arg.DeviceLabel, result.Session.DeviceLabel)
+
+		if i < int(maxSessions) {
+			require.Empty(t, result.Evicted)
+		} else {
+			require.Equal(t, []uuid.UUID{created[i-int(maxSessions)].ID}, result.Evicted)
+		}
+
+		created = append(created, result.Session)
+	}
+
+	sessions, err := testStore.ListSessionsByUserName(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, int(maxSessions))
+
+	for _, evicted := range created[:2] {
+		_, err := testStore.GetSessionById(context.Background(), evicted.ID)
+		require.ErrorIs(t, err, ErrRecordNotFound)
+	}
+}
+
+func TestCreateSessionTxDropsExpired(t *testing.T) {
+	user := createRandomUser(t)
+
+	expired, err := testStore.CreateSession(context.Background(), randomCreateSessionParams(user.Username, time.Now().Add(-time.Hour)))
+	require.NoError(t, err)
+
+	_, err = testStore.CreateSessionTx(context.Background(), CreateSessionTxParams{
+		CreateSessionParams: randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)),
+		MaxSessions:         1,
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.GetSessionById(context.Background(), expired.ID)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestCreateSessionTxKeepsRevoked(t *testing.T) {
+	user := createRandomUser(t)
+
+	revoked, err := testStore.CreateSession(context.Background(), randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	require.NoError(t, testStore.RevokeSession(context.Background(), revoked.ID))
+
+	// The revoked session neither counts towards the limit nor is dropped
+	result, err := testStore.CreateSessionTx(context.Background(), CreateSessionTxParams{
+		CreateSessionParams: randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)),
+		MaxSessions:         1,
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Evicted)
+
+	kept, err := testStore.GetSessionById(context.Background(), revoked.ID)
+	require.NoError(t, err)
+	require.True(t, kept.RevokedAt.Valid)
+}
+
+func TestRotateRefreshTokenTx(t *testing.T) {
+	user := createRandomUser(t)
+
+	session, err := testStore.CreateSession(context.Background(), randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	newRefreshToken := util.RandomString(32)
+
+	result, err := testStore.RotateRefreshTokenTx(context.Background(), RotateRefreshTokenTxParams{
+		SessionID:       session.ID,
+		RefreshToken:    session.RefreshToken,
+		NewRefreshToken: newRefreshToken,
+		ClientIp:        "10.0.0.1",
+	})
+	require.NoError(t, err)
+	require.False(t, result.Reused)
+	require.Equal(t, newRefreshToken, result.Session.RefreshToken)
+	require.Equal(t, "10.0.0.1", result.Session.ClientIp)
+	require.False(t, result.Session.LastSeenAt.Before(session.LastSeenAt))
+
+	// Presenting the rotated-out token again blocks the session
+	result, err = testStore.RotateRefreshTokenTx(context.Background(), RotateRefreshTokenTxParams{
+		SessionID:       session.ID,
+		RefreshToken:    session.RefreshToken,
+		NewRefreshToken: util.RandomString(32),
+		ClientIp:        "10.0.0.2",
+	})
+	require.NoError(t, err)
+	require.True(t, result.Reused)
+
+	blocked, err := testStore.GetSessionById(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+	require.True(t, blocked.RevokedAt.Valid)
+	require.Equal(t, newRefreshToken, blocked.RefreshToken)
+}
+
+func TestRevokeSessionsByUserName(t *testing.T) {
+	user := createRandomUser(t)
+
+	var sessions []Session
+	for i := 0; i < 2; i++ {
+		session, err := testStore.CreateSession(context.Background(), randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		sessions = append(sessions, session)
+	}
+
+	revoked, err := testStore.RevokeSessionsByUserName(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, revoked, 2)
+
+	active, err := testStore.ListSessionsByUserName(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, active)
+
+	// The rows are kept with the time they were revoked
+	for _, session := range sessions {
+		kept, err := testStore.GetSessionById(context.Background(), session.ID)
+		require.NoError(t, err)
+		require.True(t, kept.RevokedAt.Valid)
+	}
+
+	// Sessions that are already revoked are not counted again
+	revoked, err = testStore.RevokeSessionsByUserName(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, revoked)
+}
+
+func TestRevokeSession(t *testing.T) {
+	user := createRandomUser(t)
+
+	session, err := testStore.CreateSession(context.Background(), randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	require.False(t, session.RevokedAt.Valid)
+
+	err = testStore.RevokeSession(context.Background(), session.ID)
+	require.NoError(t, err)
+
+	revoked, err := testStore.GetSessionById(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	sessions, err := testStore.ListSessionsByUserName(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
@@ -8,9 +8,26 @@ import (
 
 type Store interface {
 	Querier
-	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
-	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
-	VerifyUserEmailTx(ctx context.Context, arg VerifyUserEmailTxParams) (VerifyUserEmailTxResult, error)
+	TransferTx(ctx context.Context, arg TransferTxParams, opts ...TxOption) (TransferTxResult, error)
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams, opts ...TxOption) (CreateUserTxResult, error)
+	VerifyUserEmailTx(ctx context.Context, arg VerifyUserEmailTxParams, opts ...TxOption) (VerifyUserEmailTxResult, error)
+	RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams, opts ...TxOption) (RelayOutboxTxResult, error)
+	CreateWebhookDeliveryTx(ctx context.Context, arg CreateWebhookDeliveryTxParams, opts ...TxOption) (CreateWebhookDeliveryTxResult, error)
+	RedeliverWebhookTx(ctx context.Context, arg RedeliverWebhookTxParams, opts ...TxOption) (RedeliverWebhookTxResult, error)
+	CreateSessionTx(ctx context.Context, arg CreateSessionTxParams, opts ...TxOption) (CreateSessionTxResult, error)
+	RotateRefreshTokenTx(ctx context.Context, arg RotateRefreshTokenTxParams, opts ...TxOption) (RotateRefreshTokenTxResult, error)
+	ConfirmTotpTx(ctx context.Context, arg ConfirmTotpTxParams, opts ...TxOption) (ConfirmTotpTxResult, error)
+	DisableTotpTx(ctx context.Context, username string, opts ...TxOption) error
+	CreateMfaChallengeTx(ctx context.Context, arg CreateMfaChallengeParams, opts ...TxOption) (CreateMfaChallengeTxResult, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams, opts ...TxOption) (ResetPasswordTxResult, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams, opts ...TxOption) (ChangePasswordTxResult, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams, opts ...TxOption) (UpdateUserTxResult, error)
+	ResendVerifyEmailTx(ctx context.Context, arg ResendVerifyEmailTxParams, opts ...TxOption) (ResendVerifyEmailTxResult, error)
+	AuditTx(ctx context.Context, arg AuditTxParams, opts ...TxOption) (AuditTxResult, error)
+	DenyLoginTx(ctx context.Context, denyTokenHash string, opts ...TxOption) (DenyLoginTxResult, error)
+	UpdateKycStatusTx(ctx context.Context, arg UpdateKycStatusTxParams, opts ...TxOption) (UpdateKycStatusTxResult, error)
+	VerifyPhoneTx(ctx context.Context, arg VerifyPhoneTxParams, opts ...TxOption) (VerifyPhoneTxResult, error)
+	CreateOidcUserTx(ctx context.Context, arg CreateOidcUserTxParams, opts ...TxOption) (CreateOidcUserTxResult, error)
 }
 
 // Store provides all functions to execute db queries and transactions
@@ -5,16 +5,29 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/ChokeGuy/simple-bank/util"
 	"github.com/stretchr/testify/require"
 )
 
+// fundAccount sets the balance of account, transfers cannot overdraw it
+func fundAccount(t *testing.T, account Account, balance int64) Account {
+	account, err := testStore.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: balance,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
 func TestTransferTx(t *testing.T) {
-	account1 := CreateRandomAccount(t)
-	account2 := CreateRandomAccount(t)
+	account1 := fundAccount(t, CreateRandomAccount(t), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, account1.Currency), 1000)
 
 	fmt.Println(">> Before:", account1.Balance, account2.Balance)
 	n := 5
 	amount := int64(10)
+	money := util.Money{Amount: amount, Currency: account1.Currency}
 
 	errors := make(chan error)
 	results := make(chan TransferTxResult)
@@ -25,7 +38,7 @@ func TestTransferTx(t *testing.T) {
 			result, err := testStore.TransferTx(ctx, TransferTxParams{
 				FromAccountID: account1.ID,
 				ToAccountID:   account2.ID,
-				Amount:        amount,
+				Amount:        money,
 			})
 
 			errors <- err
@@ -111,12 +124,13 @@ func TestTransferTx(t *testing.T) {
 }
 
 func TestTransferTxDeadLock(t *testing.T) {
-	account1 := CreateRandomAccount(t)
-	account2 := CreateRandomAccount(t)
+	account1 := fundAccount(t, CreateRandomAccount(t), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, account1.Currency), 1000)
 
 	fmt.Println(">> Before:", account1.Balance, account2.Balance)
 	n := 10
 	amount := int64(10)
+	money := util.Money{Amount: amount, Currency: account1.Currency}
 
 	errors := make(chan error)
 
@@ -134,7 +148,7 @@ func TestTransferTxDeadLock(t *testing.T) {
 			_, err := testStore.TransferTx(ctx, TransferTxParams{
 				FromAccountID: fromAccountId,
 				ToAccountID:   toAccountId,
-				Amount:        amount,
+				Amount:        money,
 			})
 
 			errors <- err
@@ -157,3 +171,100 @@ func TestTransferTxDeadLock(t *testing.T) {
 	require.Equal(t, account1.Balance, updateAccount1.Balance)
 	require.Equal(t, account2.Balance, updateAccount2.Balance)
 }
+
+func TestTransferTxSerializable(t *testing.T) {
+	account1 := fundAccount(t, CreateRandomAccount(t), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, account1.Currency), 1000)
+
+	n := 10
+	amount := int64(10)
+	money := util.Money{Amount: amount, Currency: account1.Currency}
+
+	errors := make(chan error)
+
+	for i := 0; i < n; i++ {
+		fromAccountId := account1.ID
+		toAccountId := account2.ID
+
+		if i%2 == 1 {
+			fromAccountId = account2.ID
+			toAccountId = account1.ID
+		}
+
+		go func() {
+			ctx := context.Background()
+			_, err := testStore.TransferTx(ctx, TransferTxParams{
+				FromAccountID: fromAccountId,
+				ToAccountID:   toAccountId,
+				Amount:        money,
+			}, WithSerializable(), WithMaxRetries(n))
+
+			errors <- err
+		}()
+	}
+
+	// serialization failures must be retried instead of surfacing
+	for i := 0; i < n; i++ {
+		err := <-errors
+		require.NoError(t, err)
+	}
+
+	updateAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+
+	updateAccount2, err := testStore.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Balance, updateAccount1.Balance)
+	require.Equal(t, account2.Balance, updateAccount2.Balance)
+}
+
+func TestTransferTxCurrencyMismatch(t *testing.T) {
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.Money{Amount: 10, Currency: util.EUR},
+	})
+	require.ErrorIs(t, err, util.ErrCurrencyMismatch)
+
+	// the transaction is rolled back
+	updateAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updateAccount1.Balance)
+
+	updateAccount2, err := testStore.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updateAccount2.Balance)
+}
+
+func TestTransferTxInsufficientBalance(t *testing.T) {
+	account1 := fundAccount(t, CreateRandomAccount(t), 5)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.Money{Amount: 10, Currency: account1.Currency},
+	})
+	require.ErrorIs(t, err, ErrInsufficientBalance)
+
+	// Nothing of the transfer is kept
+	updateAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updateAccount1.Balance)
+
+	updateAccount2, err := testStore.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updateAccount2.Balance)
+
+	entries, err := testStore.ListEntriesByAccountId(context.Background(), ListEntriesByAccountIdParams{
+		AccountID: account1.ID,
+		Limit:     5,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
@@ -8,6 +8,8 @@ package sqlc
 import (
 	"context"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
@@ -15,7 +17,7 @@ INSERT INTO
     transfers (from_account_id, to_account_id, amount)
 VALUES
     ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, public_id
 `
 
 type CreateTransferParams struct {
@@ -33,6 +35,7 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.PublicID,
 	)
 	return i, err
 }
@@ -43,7 +46,8 @@ SELECT
     from_account_id,
     to_account_id,
     amount,
-    created_at
+    created_at,
+    public_id
 FROM
     transfers
 WHERE
@@ -59,22 +63,26 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.PublicID,
 	)
 	return i, err
 }
 
 const getTransfers = `-- name: GetTransfers :many
 SELECT
-    from_account_id,
-    to_account_id,
-    amount,
-    created_at
+    t.public_id,
+    fa.account_number AS from_account_number,
+    ta.account_number AS to_account_number,
+    t.amount,
+    t.created_at
 FROM
-    transfers
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
 WHERE
-    from_account_id = $1 AND to_account_id = $2
+    t.from_account_id = $1 AND t.to_account_id = $2
 ORDER BY
-    created_at DESC
+    t.created_at DESC
 `
 
 type GetTransfersParams struct {
@@ -83,10 +91,11 @@ type GetTransfersParams struct {
 }
 
 type GetTransfersRow struct {
-	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
-	Amount        int64     `json:"amount"`
-	CreatedAt     time.Time `json:"created_at"`
+	PublicID          uuid.UUID `json:"public_id"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountNumber   string    `json:"to_account_number"`
+	Amount            int64     `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
 }
 
 func (q *Queries) GetTransfers(ctx context.Context, arg GetTransfersParams) ([]GetTransfersRow, error) {
@@ -99,8 +108,9 @@ func (q *Queries) GetTransfers(ctx context.Context, arg GetTransfersParams) ([]G
 	for rows.Next() {
 		var i GetTransfersRow
 		if err := rows.Scan(
-			&i.FromAccountID,
-			&i.ToAccountID,
+			&i.PublicID,
+			&i.FromAccountNumber,
+			&i.ToAccountNumber,
 			&i.Amount,
 			&i.CreatedAt,
 		); err != nil {
@@ -116,23 +126,27 @@ func (q *Queries) GetTransfers(ctx context.Context, arg GetTransfersParams) ([]G
 
 const getTransfersByFromAccountId = `-- name: GetTransfersByFromAccountId :many
 SELECT
-    from_account_id,
-    to_account_id,
-    amount,
-    created_at
+    t.public_id,
+    fa.account_number AS from_account_number,
+    ta.account_number AS to_account_number,
+    t.amount,
+    t.created_at
 FROM
-    transfers
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
 WHERE
-    from_account_id = $1
+    t.from_account_id = $1
 ORDER BY
-    created_at DESC
+    t.created_at DESC
 `
 
 type GetTransfersByFromAccountIdRow struct {
-	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
-	Amount        int64     `json:"amount"`
-	CreatedAt     time.Time `json:"created_at"`
+	PublicID          uuid.UUID `json:"public_id"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountNumber   string    `json:"to_account_number"`
+	Amount            int64     `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
 }
 
 func (q *Queries) GetTransfersByFromAccountId(ctx context.Context, fromAccountID int64) ([]GetTransfersByFromAccountIdRow, error) {
@@ -145,8 +159,9 @@ func (q *Queries) GetTransfersByFromAccountId(ctx context.Context, fromAccountID
 	for rows.Next() {
 		var i GetTransfersByFromAccountIdRow
 		if err := rows.Scan(
-			&i.FromAccountID,
-			&i.ToAccountID,
+			&i.PublicID,
+			&i.FromAccountNumber,
+			&i.ToAccountNumber,
 			&i.Amount,
 			&i.CreatedAt,
 		); err != nil {
@@ -162,23 +177,27 @@ func (q *Queries) GetTransfersByFromAccountId(ctx context.Context, fromAccountID
 
 const getTransfersByToAccountId = `-- name: GetTransfersByToAccountId :many
 SELECT
-    from_account_id,
-    to_account_id,
-    amount,
-    created_at
+    t.public_id,
+    fa.account_number AS from_account_number,
+    ta.account_number AS to_account_number,
+    t.amount,
+    t.created_at
 FROM
-    transfers
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
 WHERE
-    to_account_id = $1
+    t.to_account_id = $1
 ORDER BY
-    created_at DESC
+    t.created_at DESC
 `
 
 type GetTransfersByToAccountIdRow struct {
-	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
-	Amount        int64     `json:"amount"`
-	CreatedAt     time.Time `json:"created_at"`
+	PublicID          uuid.UUID `json:"public_id"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountNumber   string    `json:"to_account_number"`
+	Amount            int64     `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
 }
 
 func (q *Queries) GetTransfersByToAccountId(ctx context.Context, toAccountID int64) ([]GetTransfersByToAccountIdRow, error) {
@@ -191,8 +210,9 @@ func (q *Queries) GetTransfersByToAccountId(ctx context.Context, toAccountID int
 	for rows.Next() {
 		var i GetTransfersByToAccountIdRow
 		if err := rows.Scan(
-			&i.FromAccountID,
-			&i.ToAccountID,
+			&i.PublicID,
+			&i.FromAccountNumber,
+			&i.ToAccountNumber,
 			&i.Amount,
 			&i.CreatedAt,
 		); err != nil {
@@ -205,3 +225,95 @@ func (q *Queries) GetTransfersByToAccountId(ctx context.Context, toAccountID int
 	}
 	return items, nil
 }
+
+const listTransfersByOwner = `-- name: ListTransfersByOwner :many
+SELECT
+    t.public_id,
+    fa.account_number AS from_account_number,
+    ta.account_number AS to_account_number,
+    t.amount,
+    fa.currency,
+    t.created_at
+FROM
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+    fa.owner = $1
+    OR ta.owner = $1
+ORDER BY
+    t.created_at DESC,
+    t.id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListTransfersByOwnerParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+type ListTransfersByOwnerRow struct {
+	PublicID          uuid.UUID `json:"public_id"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountNumber   string    `json:"to_account_number"`
+	Amount            int64     `json:"amount"`
+	Currency          string    `json:"currency"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListTransfersByOwner(ctx context.Context, arg ListTransfersByOwnerParams) ([]ListTransfersByOwnerRow, error) {
+	rows, err := q.db.Query(ctx, listTransfersByOwner, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransfersByOwnerRow{}
+	for rows.Next() {
+		var i ListTransfersByOwnerRow
+		if err := rows.Scan(
+			&i.PublicID,
+			&i.FromAccountNumber,
+			&i.ToAccountNumber,
+			&i.Amount,
+			&i.Currency,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumOutgoingTransfersSince = `-- name: SumOutgoingTransfersSince :one
+SELECT
+    COALESCE(SUM(t.amount), 0)::bigint AS total
+FROM
+    transfers t
+    JOIN accounts fa ON fa.id = t.from_account_id
+    JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+    fa.owner = $1
+    AND ta.owner <> $1
+    AND fa.currency = $2
+    AND t.created_at >= $3
+`
+
+type SumOutgoingTransfersSinceParams struct {
+	Owner    string    `json:"owner"`
+	Currency string    `json:"currency"`
+	Since    time.Time `json:"since"`
+}
+
+// Transfers between accounts of the same owner are not counted
+func (q *Queries) SumOutgoingTransfersSince(ctx context.Context, arg SumOutgoingTransfersSinceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, sumOutgoingTransfersSince, arg.Owner, arg.Currency, arg.Since)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
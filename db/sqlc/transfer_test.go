@@ -28,6 +28,7 @@ func CreateRandomTransfer(t *testing.T) Transfer {
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 
 	require.NotZero(t, transfer.ID)
+	require.NotZero(t, transfer.PublicID)
 	require.NotZero(t, transfer.CreatedAt)
 
 	return transfer
@@ -70,14 +71,20 @@ func TestGetTransfers(t *testing.T) {
 		ToAccountID:   transfer.ToAccountID,
 	}
 
+	fromAccount, err := testStore.GetAccount(context.Background(), transfer.FromAccountID)
+	require.NoError(t, err)
+
+	toAccount, err := testStore.GetAccount(context.Background(), transfer.ToAccountID)
+	require.NoError(t, err)
+
 	transfers, err := testStore.GetTransfers(context.Background(), arg)
 	require.NoError(t, err)
 	require.Len(t, transfers, 11)
 
 	for _, transfer := range transfers {
 		require.NotEmpty(t, transfer)
-		require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
-		require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
+		require.Equal(t, fromAccount.AccountNumber, transfer.FromAccountNumber)
+		require.Equal(t, toAccount.AccountNumber, transfer.ToAccountNumber)
 	}
 }
 
@@ -103,6 +110,9 @@ func TestGetTransfersByFromAccountId(t *testing.T) {
 		CreateTransfer(t, transfer)
 	}
 
+	fromAccount, err := testStore.GetAccount(context.Background(), transfer.FromAccountID)
+	require.NoError(t, err)
+
 	transfers, err := testStore.GetTransfersByFromAccountId(context.Background(), transfer.FromAccountID)
 
 	require.NoError(t, err)
@@ -110,7 +120,7 @@ func TestGetTransfersByFromAccountId(t *testing.T) {
 
 	for _, transfer := range transfers {
 		require.NotEmpty(t, transfer)
-		require.Equal(t, transfer.FromAccountID, transfer.FromAccountID)
+		require.Equal(t, fromAccount.AccountNumber, transfer.FromAccountNumber)
 	}
 }
 
@@ -121,6 +131,9 @@ func TestGetTransfersByToAccountId(t *testing.T) {
 		CreateTransfer(t, transfer)
 	}
 
+	toAccount, err := testStore.GetAccount(context.Background(), transfer.ToAccountID)
+	require.NoError(t, err)
+
 	transfers, err := testStore.GetTransfersByToAccountId(context.Background(), transfer.ToAccountID)
 
 	require.NoError(t, err)
@@ -128,6 +141,6 @@ func TestGetTransfersByToAccountId(t *testing.T) {
 
 	for _, transfer := range transfers {
 		require.NotEmpty(t, transfer)
-		require.Equal(t, transfer.ToAccountID, transfer.ToAccountID)
+		require.Equal(t, toAccount.AccountNumber, transfer.ToAccountNumber)
 	}
 }
@@ -0,0 +1,34 @@
+package sqlc
+
+import "context"
+
+// AuditTxParams contains the input parameters of the audit transaction
+type AuditTxParams struct {
+	CreateAuditLogParams
+	// Execute runs inside the transaction before the audit log is written,
+	// the log is only kept when the action succeeds
+	Execute func(q Querier) error
+}
+
+// AuditTxResult contains the result of the audit transaction
+type AuditTxResult struct {
+	AuditLog AuditLog
+}
+
+// AuditTx runs an administrative action and records it in the audit log atomically
+func (store *SQLStore) AuditTx(ctx context.Context, arg AuditTxParams, opts ...TxOption) (AuditTxResult, error) {
+	var result AuditTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if err := arg.Execute(q); err != nil {
+			return err
+		}
+
+		var err error
+		result.AuditLog, err = q.CreateAuditLog(ctx, arg.CreateAuditLogParams)
+
+		return err
+	}, opts...)
+
+	return result, err
+}
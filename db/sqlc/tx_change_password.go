@@ -0,0 +1,105 @@
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChangePasswordTxParams contains the input parameters of the change password transaction
+type ChangePasswordTxParams struct {
+	Username       string
+	HashedPassword string
+	// PasswordChangedAt must not be after the issue time of tokens handed out with the change,
+	// tokens issued before it are rejected
+	PasswordChangedAt time.Time
+	// KeepSessionID is the session that stays signed in, uuid.Nil revokes every session
+	KeepSessionID uuid.UUID
+	// KeepSessionRefreshToken is the hash of the refresh token that replaces the one of the kept session
+	KeepSessionRefreshToken string
+	ClientIp                string
+	// HistoryKeep is how many replaced hashes are kept to block their reuse, 0 keeps none
+	HistoryKeep int32
+}
+
+// ChangePasswordTxResult contains the result of the change password transaction
+type ChangePasswordTxResult struct {
+	User User
+	// RevokedSessions are the sessions of the user that were revoked with the old password
+	RevokedSessions []uuid.UUID
+}
+
+// ChangePasswordTx sets a new password and signs the user out of every session but the kept one
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams, opts ...TxOption) (ChangePasswordTxResult, error) {
+	var result ChangePasswordTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := recordPasswordHistory(ctx, q, arg.Username, arg.HistoryKeep)
+
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username:          arg.Username,
+			HashedPassword:    arg.HashedPassword,
+			PasswordChangedAt: arg.PasswordChangedAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		// A reset link sent before the change must not undo it
+		if err := q.InvalidatePasswordResets(ctx, arg.Username); err != nil {
+			return err
+		}
+
+		result.RevokedSessions, err = q.RevokeOtherSessions(ctx, RevokeOtherSessionsParams{
+			Username: arg.Username,
+			ID:       arg.KeepSessionID,
+		})
+
+		if err != nil || arg.KeepSessionID == uuid.Nil {
+			return err
+		}
+
+		// The refresh token of the kept session was issued with the old password, it is replaced
+		_, err = q.RotateSessionRefreshToken(ctx, RotateSessionRefreshTokenParams{
+			ID:           arg.KeepSessionID,
+			RefreshToken: arg.KeepSessionRefreshToken,
+			ClientIp:     arg.ClientIp,
+		})
+		return err
+	}, opts...)
+
+	return result, err
+}
+
+// recordPasswordHistory saves the hash that is about to be replaced and drops all but the last keep hashes
+func recordPasswordHistory(ctx context.Context, q *Queries, username string, keep int32) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	user, err := q.GetUserByUserName(ctx, username)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreatePasswordHistory(ctx, CreatePasswordHistoryParams{
+		Username:       username,
+		HashedPassword: user.HashedPassword,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return q.PrunePasswordHistory(ctx, PrunePasswordHistoryParams{
+		Username: username,
+		Keep:     keep,
+	})
+}
@@ -0,0 +1,54 @@
+package sqlc
+
+import "context"
+
+// ConfirmTotpTxParams contains the input parameters of the confirm totp transaction
+type ConfirmTotpTxParams struct {
+	Username string
+	// Step is the time step of the code that confirmed the enrolment, it can not be used again
+	Step int64
+	// RecoveryCodeHashes replace every recovery code the user had before
+	RecoveryCodeHashes []string
+}
+
+// ConfirmTotpTxResult contains the result of the confirm totp transaction
+type ConfirmTotpTxResult struct {
+	UserTotp UserTotp
+}
+
+// ConfirmTotpTx turns on the pending TOTP enrolment of a user and stores a new set of recovery codes
+func (store *SQLStore) ConfirmTotpTx(ctx context.Context, arg ConfirmTotpTxParams, opts ...TxOption) (ConfirmTotpTxResult, error) {
+	var result ConfirmTotpTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.UserTotp, err = q.ConfirmUserTotp(ctx, ConfirmUserTotpParams{
+			Username:     arg.Username,
+			LastUsedStep: arg.Step,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteRecoveryCodes(ctx, arg.Username); err != nil {
+			return err
+		}
+
+		for _, codeHash := range arg.RecoveryCodeHashes {
+			err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username: arg.Username,
+				CodeHash: codeHash,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, opts...)
+
+	return result, err
+}
@@ -0,0 +1,28 @@
+package sqlc
+
+import "context"
+
+// CreateMfaChallengeTxResult contains the result of the create mfa challenge transaction
+type CreateMfaChallengeTxResult struct {
+	MfaChallenge MfaChallenge
+}
+
+// CreateMfaChallengeTx drops the user's expired and consumed challenges and creates a new one
+func (store *SQLStore) CreateMfaChallengeTx(ctx context.Context, arg CreateMfaChallengeParams, opts ...TxOption) (CreateMfaChallengeTxResult, error) {
+	var result CreateMfaChallengeTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		_, err = q.DeleteExpiredMfaChallenges(ctx, arg.Username)
+
+		if err != nil {
+			return err
+		}
+
+		result.MfaChallenge, err = q.CreateMfaChallenge(ctx, arg)
+		return err
+	}, opts...)
+
+	return result, err
+}
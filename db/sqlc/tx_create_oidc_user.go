@@ -0,0 +1,84 @@
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateOidcUserTxParams contains the input parameters of the create OIDC user transaction
+type CreateOidcUserTxParams struct {
+	CreateUserParams
+	// Role and IsEmailVerified are taken from the claims of the provider
+	Role            string
+	IsEmailVerified bool
+	// Issuer and Subject identify the account of the provider the user is linked to
+	Issuer  string
+	Subject string
+	// AfterCreate runs inside the transaction when set, q must be used for any write such as outbox messages
+	AfterCreate func(q Querier, user User) error
+}
+
+// CreateOidcUserTxResult contains the result of the create OIDC user transaction
+type CreateOidcUserTxResult struct {
+	User     User
+	Identity UserIdentity
+}
+
+// CreateOidcUserTx creates a user on its first login with an external provider and links it to the
+// account of the provider. A unique violation on UserIdentityConstraint means a concurrent login
+// linked the same account first.
+func (store *SQLStore) CreateOidcUserTx(ctx context.Context, arg CreateOidcUserTxParams, opts ...TxOption) (CreateOidcUserTxResult, error) {
+	var result CreateOidcUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
+
+		if err != nil {
+			return err
+		}
+
+		if arg.Role != "" && arg.Role != result.User.Role {
+			result.User, err = q.UpdateUserRole(ctx, UpdateUserRoleParams{
+				Username: result.User.Username,
+				Role:     arg.Role,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if arg.IsEmailVerified {
+			result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+				Username:        result.User.Username,
+				IsEmailVerified: pgtype.Bool{Bool: true, Valid: true},
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Identity, err = q.CreateUserIdentity(ctx, CreateUserIdentityParams{
+			Username: result.User.Username,
+			Issuer:   arg.Issuer,
+			Subject:  arg.Subject,
+			Email:    arg.Email,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterCreate == nil {
+			return nil
+		}
+
+		return arg.AfterCreate(q, result.User)
+	}, opts...)
+
+	return result, err
+}
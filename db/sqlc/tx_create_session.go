@@ -0,0 +1,65 @@
+package sqlc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// CreateSessionTxParams contains the input parameters of the create session transaction
+type CreateSessionTxParams struct {
+	CreateSessionParams
+	// MaxSessions caps the active sessions a user may hold, the oldest are evicted to make room.
+	// Revoked sessions do not count. Zero means unlimited
+	MaxSessions int32
+	// AfterCreate runs inside the transaction once the session is created, it is optional
+	AfterCreate func(q Querier, session Session) error
+}
+
+// CreateSessionTxResult contains the result of the create session transaction
+type CreateSessionTxResult struct {
+	Session Session
+	// Evicted are the active sessions removed to stay under MaxSessions, their cached state has
+	// to be invalidated once the transaction commits
+	Evicted []uuid.UUID
+}
+
+// CreateSessionTx drops the user's expired sessions, evicts the oldest active ones over the limit and creates the new session
+func (store *SQLStore) CreateSessionTx(ctx context.Context, arg CreateSessionTxParams, opts ...TxOption) (CreateSessionTxResult, error) {
+	var result CreateSessionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		_, err = q.DeleteExpiredSessions(ctx, arg.Username)
+
+		if err != nil {
+			return err
+		}
+
+		if arg.MaxSessions > 0 {
+			result.Evicted, err = q.DeleteOldestSessions(ctx, DeleteOldestSessionsParams{
+				Username: arg.Username,
+				Keep:     arg.MaxSessions - 1,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Session, err = q.CreateSession(ctx, arg.CreateSessionParams)
+
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterCreate != nil {
+			return arg.AfterCreate(q, result.Session)
+		}
+
+		return nil
+	}, opts...)
+
+	return result, err
+}
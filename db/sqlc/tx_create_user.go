@@ -5,7 +5,8 @@ import "context"
 // CreateUserTxParams contains the input parameters of the transfer transaction
 type CreateUserTxParams struct {
 	CreateUserParams
-	AfterCreate func(user User) error
+	// AfterCreate runs inside the transaction, q must be used for any write such as outbox messages
+	AfterCreate func(q Querier, user User) error
 }
 
 // CreateUserTxResult contains the result of the transfer transaction
@@ -14,7 +15,7 @@ type CreateUserTxResult struct {
 }
 
 // CreateUserTxParams contains the input parameters of the transfer transaction
-func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams, opts ...TxOption) (CreateUserTxResult, error) {
 	var result CreateUserTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
@@ -26,8 +27,8 @@ func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams)
 			return err
 		}
 
-		return arg.AfterCreate(result.User)
-	})
+		return arg.AfterCreate(q, result.User)
+	}, opts...)
 
 	return result, err
 }
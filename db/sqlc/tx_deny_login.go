@@ -0,0 +1,43 @@
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DenyLoginTxResult contains the result of the deny login transaction
+type DenyLoginTxResult struct {
+	LoginEvent LoginEvent
+	// Blocked is false when the session of the login was already deleted
+	Blocked bool
+}
+
+// DenyLoginTx marks the login of a "this wasn't me" link as denied and blocks its session.
+// A link works once, ErrRecordNotFound is returned for an unknown or already used one.
+func (store *SQLStore) DenyLoginTx(ctx context.Context, denyTokenHash string, opts ...TxOption) (DenyLoginTxResult, error) {
+	var result DenyLoginTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.LoginEvent, err = q.DenyLoginEvent(ctx, pgtype.Text{String: denyTokenHash, Valid: true})
+
+		if err != nil {
+			return err
+		}
+
+		if !result.LoginEvent.SessionID.Valid {
+			return nil
+		}
+
+		if err := q.BlockSession(ctx, result.LoginEvent.SessionID.Bytes); err != nil {
+			return err
+		}
+
+		result.Blocked = true
+		return nil
+	}, opts...)
+
+	return result, err
+}
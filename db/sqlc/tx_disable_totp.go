@@ -0,0 +1,14 @@
+package sqlc
+
+import "context"
+
+// DisableTotpTx removes the TOTP secret and the recovery codes of a user
+func (store *SQLStore) DisableTotpTx(ctx context.Context, username string, opts ...TxOption) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		if err := q.DeleteRecoveryCodes(ctx, username); err != nil {
+			return err
+		}
+
+		return q.DeleteUserTotp(ctx, username)
+	}, opts...)
+}
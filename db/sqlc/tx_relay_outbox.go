@@ -0,0 +1,84 @@
+package sqlc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RelayOutboxTxParams contains the input parameters of the outbox relay transaction
+type RelayOutboxTxParams struct {
+	BatchSize int32
+	// MaxAttempts is how many times a message is tried before it is marked as failed and no longer relayed
+	MaxAttempts int32
+	Publish     func(message OutboxMessage) error
+}
+
+// RelayOutboxTxResult contains the result of the outbox relay transaction
+type RelayOutboxTxResult struct {
+	// Locked is false when another relay is already running
+	Locked    bool
+	Published int
+	Failed    int
+	// DeadLettered are the messages that used their last attempt in this run
+	DeadLettered []OutboxMessage
+}
+
+// RelayOutboxTx publishes pending outbox messages in insertion order. Once a message fails, the
+// remaining messages of the same aggregate are held back so they are never published out of order.
+// A message that keeps failing is given up after MaxAttempts, which releases the rest of its aggregate.
+func (store *SQLStore) RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams, opts ...TxOption) (RelayOutboxTxResult, error) {
+	var result RelayOutboxTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = RelayOutboxTxResult{}
+
+		var err error
+		result.Locked, err = q.TryLockOutboxRelay(ctx)
+		if err != nil || !result.Locked {
+			return err
+		}
+
+		messages, err := q.ListPendingOutboxMessages(ctx, arg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		blocked := make(map[string]bool)
+		for _, message := range messages {
+			aggregate := message.AggregateType + ":" + message.AggregateID
+			if blocked[aggregate] {
+				continue
+			}
+
+			if publishErr := arg.Publish(message); publishErr != nil {
+				blocked[aggregate] = true
+				result.Failed++
+
+				failed, err := q.MarkOutboxMessageFailed(ctx, MarkOutboxMessageFailedParams{
+					ID:          message.ID,
+					LastError:   pgtype.Text{String: publishErr.Error(), Valid: true},
+					MaxAttempts: arg.MaxAttempts,
+				})
+				if err != nil {
+					return fmt.Errorf("fail to mark outbox message %d as failed: %w", message.ID, err)
+				}
+
+				if failed.FailedAt.Valid {
+					result.DeadLettered = append(result.DeadLettered, failed)
+				}
+				continue
+			}
+
+			if err = q.MarkOutboxMessageProcessed(ctx, message.ID); err != nil {
+				return fmt.Errorf("fail to mark outbox message %d as processed: %w", message.ID, err)
+			}
+			result.Published++
+		}
+
+		return nil
+	}, opts...)
+
+	return result, err
+}
@@ -0,0 +1,43 @@
+package sqlc
+
+import "context"
+
+// ResendVerifyEmailTxParams contains the input parameters of the resend verify email transaction
+type ResendVerifyEmailTxParams struct {
+	Username string
+	// AfterInvalidate runs inside the transaction once the pending codes are invalidated,
+	// q must be used to enqueue the task that sends the new code
+	AfterInvalidate func(q Querier, user GetUserByUserNameRow) error
+}
+
+// ResendVerifyEmailTxResult contains the result of the resend verify email transaction
+type ResendVerifyEmailTxResult struct {
+	User GetUserByUserNameRow
+}
+
+// ResendVerifyEmailTx invalidates the pending verification codes of a user that is not verified yet
+func (store *SQLStore) ResendVerifyEmailTx(ctx context.Context, arg ResendVerifyEmailTxParams, opts ...TxOption) (ResendVerifyEmailTxResult, error) {
+	var result ResendVerifyEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.GetUserByUserName(ctx, arg.Username)
+
+		if err != nil {
+			return err
+		}
+
+		if result.User.IsEmailVerified {
+			return ErrEmailAlreadyVerified
+		}
+
+		if err := q.InvalidateVerifyEmails(ctx, result.User.Username); err != nil {
+			return err
+		}
+
+		return arg.AfterInvalidate(q, result.User)
+	}, opts...)
+
+	return result, err
+}
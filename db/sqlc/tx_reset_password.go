@@ -0,0 +1,64 @@
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResetPasswordTxParams contains the input parameters of the reset password transaction
+type ResetPasswordTxParams struct {
+	// TokenHash is the hash of the reset token sent by email
+	TokenHash      string
+	HashedPassword string
+	// HistoryKeep is how many replaced hashes are kept to block their reuse, 0 keeps none
+	HistoryKeep int32
+}
+
+// ResetPasswordTxResult contains the result of the reset password transaction
+type ResetPasswordTxResult struct {
+	User          User
+	PasswordReset PasswordReset
+	// RevokedSessions are the sessions of the user that were revoked with the old password
+	RevokedSessions []uuid.UUID
+}
+
+// ResetPasswordTx uses a password reset token, sets the new password and signs the user out everywhere
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams, opts ...TxOption) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.PasswordReset, err = q.UsePasswordReset(ctx, arg.TokenHash)
+
+		if err != nil {
+			return err
+		}
+
+		if err := recordPasswordHistory(ctx, q, result.PasswordReset.Username, arg.HistoryKeep); err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username:          result.PasswordReset.Username,
+			HashedPassword:    arg.HashedPassword,
+			PasswordChangedAt: time.Now(),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		// Other links that are still pending must not be able to change the password again
+		if err := q.InvalidatePasswordResets(ctx, result.User.Username); err != nil {
+			return err
+		}
+
+		result.RevokedSessions, err = q.RevokeSessionsByUserName(ctx, result.User.Username)
+		return err
+	}, opts...)
+
+	return result, err
+}
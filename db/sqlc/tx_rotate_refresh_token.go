@@ -0,0 +1,54 @@
+package sqlc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RotateRefreshTokenTxParams contains the input parameters of the rotate refresh token transaction
+type RotateRefreshTokenTxParams struct {
+	SessionID uuid.UUID
+	// RefreshToken is the hash of the token presented by the client
+	RefreshToken string
+	// NewRefreshToken is the hash of the token replacing it
+	NewRefreshToken string
+	ClientIp        string
+}
+
+// RotateRefreshTokenTxResult contains the result of the rotate refresh token transaction
+type RotateRefreshTokenTxResult struct {
+	Session Session
+	// Reused is true when the presented token was already rotated, the session is then blocked
+	Reused bool
+}
+
+// RotateRefreshTokenTx replaces the refresh token of a session. Presenting a token that is no longer
+// the current one means it was stolen or replayed, so the whole session is blocked and revoked instead.
+func (store *SQLStore) RotateRefreshTokenTx(ctx context.Context, arg RotateRefreshTokenTxParams, opts ...TxOption) (RotateRefreshTokenTxResult, error) {
+	var result RotateRefreshTokenTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Session, err = q.GetSessionForUpdate(ctx, arg.SessionID)
+
+		if err != nil {
+			return err
+		}
+
+		if result.Session.RefreshToken != arg.RefreshToken {
+			result.Reused = true
+			return q.BlockSession(ctx, arg.SessionID)
+		}
+
+		result.Session, err = q.RotateSessionRefreshToken(ctx, RotateSessionRefreshTokenParams{
+			ID:           arg.SessionID,
+			RefreshToken: arg.NewRefreshToken,
+			ClientIp:     arg.ClientIp,
+		})
+		return err
+	}, opts...)
+
+	return result, err
+}
@@ -1,12 +1,24 @@
 package sqlc
 
-import "context"
+import (
+	"context"
+	"errors"
+
+	"github.com/ChokeGuy/simple-bank/util"
+)
 
 // TransferTxParams contains the input parameters of the transfer transaction
 type TransferTxParams struct {
 	FromAccountID int64 `json:"fromAccountId"`
 	ToAccountID   int64 `json:"toAccountId"`
-	Amount        int64 `json:"amount"`
+	// Amount must be in the currency of both accounts, ErrInsufficientBalance is returned when
+	// the from account cannot cover it
+	Amount util.Money `json:"amount"`
+	// BeforeTransfer runs inside the transaction before any row is written, it is optional.
+	// Returning an error rejects the transfer
+	BeforeTransfer func(q Querier) error `json:"-"`
+	// AfterTransfer runs inside the transaction once both balances are updated, it is optional
+	AfterTransfer func(q Querier, result TransferTxResult) error `json:"-"`
 }
 
 // TransferTxResult contains the result of the transfer transaction
@@ -19,13 +31,23 @@ type TransferTxResult struct {
 }
 
 // TransferTxParams contains the input parameters of the transfer transaction
-func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams, opts ...TxOption) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.BeforeTransfer != nil {
+			if err := arg.BeforeTransfer(q); err != nil {
+				return err
+			}
+		}
+
 		var err error
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount.Amount,
+		})
 
 		if err != nil {
 			return err
@@ -33,7 +55,7 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 
 		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
 			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+			Amount:    -arg.Amount.Amount,
 		})
 
 		if err != nil {
@@ -42,7 +64,7 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 
 		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
 			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+			Amount:    arg.Amount.Amount,
 		})
 
 		if err != nil {
@@ -50,17 +72,31 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 		}
 
 		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, arg.ToAccountID, -arg.Amount, arg.Amount)
+			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, arg.ToAccountID, -arg.Amount.Amount, arg.Amount.Amount)
 		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.FromAccountID, arg.Amount, -arg.Amount)
+			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.FromAccountID, arg.Amount.Amount, -arg.Amount.Amount)
+		}
+
+		// Both accounts exist once their entries are created, so a missing row is the balance guard
+		if errors.Is(err, ErrRecordNotFound) {
+			return ErrInsufficientBalance
 		}
 
 		if err != nil {
 			return err
 		}
 
+		// Returning an error rolls the transfer back
+		if result.FromAccount.Currency != arg.Amount.Currency || result.ToAccount.Currency != arg.Amount.Currency {
+			return util.ErrCurrencyMismatch
+		}
+
+		if arg.AfterTransfer != nil {
+			return arg.AfterTransfer(q, result)
+		}
+
 		return nil
-	})
+	}, opts...)
 
 	return result, err
 }
@@ -0,0 +1,61 @@
+package sqlc
+
+import "context"
+
+// UpdateKycStatusTxParams contains the input parameters of the update KYC status transaction
+type UpdateKycStatusTxParams struct {
+	UpdateUserKycStatusParams
+	Reviewer string
+	Note     string
+	// AttachDocuments links the documents uploaded since the last submission to the new review,
+	// ErrNoKycDocuments is returned when there is none
+	AttachDocuments bool
+}
+
+// UpdateKycStatusTxResult contains the result of the update KYC status transaction
+type UpdateKycStatusTxResult struct {
+	User      User
+	KycReview KycReview
+	Documents int64
+}
+
+// UpdateKycStatusTx moves the KYC status of a user and records the change in its review history.
+// ErrRecordNotFound is returned when the status is no longer FromStatus.
+func (store *SQLStore) UpdateKycStatusTx(ctx context.Context, arg UpdateKycStatusTxParams, opts ...TxOption) (UpdateKycStatusTxResult, error) {
+	var result UpdateKycStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.UpdateUserKycStatus(ctx, arg.UpdateUserKycStatusParams)
+
+		if err != nil {
+			return err
+		}
+
+		result.KycReview, err = q.CreateKycReview(ctx, CreateKycReviewParams{
+			Username:   arg.Username,
+			FromStatus: arg.FromStatus,
+			ToStatus:   arg.ToStatus,
+			Reviewer:   arg.Reviewer,
+			Note:       arg.Note,
+		})
+
+		if err != nil || !arg.AttachDocuments {
+			return err
+		}
+
+		result.Documents, err = q.AttachKycDocuments(ctx, AttachKycDocumentsParams{
+			ReviewID: result.KycReview.ID,
+			Username: arg.Username,
+		})
+
+		if err == nil && result.Documents == 0 {
+			return ErrNoKycDocuments
+		}
+
+		return err
+	}, opts...)
+
+	return result, err
+}
@@ -0,0 +1,89 @@
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UpdateUserTxParams contains the input parameters of the update user transaction
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	// AfterEmailChange runs inside the transaction when the email is changed, it is optional.
+	// The new address is already marked as unverified and the pending codes of the old one are invalidated.
+	AfterEmailChange func(q Querier, user User) error
+	// KycReview is recorded inside the transaction when the update also changes the KYC status, it is optional
+	KycReview *CreateKycReviewParams
+	// AuditLog is recorded inside the transaction when a banker updates the profile of someone else, it is optional
+	AuditLog *CreateAuditLogParams
+}
+
+// UpdateUserTxResult contains the result of the update user transaction
+type UpdateUserTxResult struct {
+	User         User
+	EmailChanged bool
+	PhoneChanged bool
+}
+
+// UpdateUserTx updates the user, a new email or phone has to be verified again
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams, opts ...TxOption) (UpdateUserTxResult, error) {
+	var result UpdateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.Email.Valid || arg.Phone.Valid {
+			current, err := q.GetUserByUserName(ctx, arg.Username)
+
+			if err != nil {
+				return err
+			}
+
+			result.EmailChanged = arg.Email.Valid && current.Email != arg.Email.String
+			result.PhoneChanged = arg.Phone.Valid && current.Phone != arg.Phone.String
+		}
+
+		params := arg.UpdateUserParams
+		if result.EmailChanged {
+			params.IsEmailVerified = pgtype.Bool{Bool: false, Valid: true}
+		}
+		// Codes already sent are bound to the old number, so they cannot verify the new one
+		if result.PhoneChanged {
+			params.IsPhoneVerified = pgtype.Bool{Bool: false, Valid: true}
+		}
+
+		var err error
+		result.User, err = q.UpdateUser(ctx, params)
+
+		if err != nil {
+			return err
+		}
+
+		if arg.KycReview != nil {
+			if _, err := q.CreateKycReview(ctx, *arg.KycReview); err != nil {
+				return err
+			}
+		}
+
+		if arg.AuditLog != nil {
+			if _, err := q.CreateAuditLog(ctx, *arg.AuditLog); err != nil {
+				return err
+			}
+		}
+
+		if !result.EmailChanged {
+			return nil
+		}
+
+		// A code sent to the old address must not verify the new one
+		if err := q.InvalidateVerifyEmails(ctx, result.User.Username); err != nil {
+			return err
+		}
+
+		if arg.AfterEmailChange != nil {
+			return arg.AfterEmailChange(q, result.User)
+		}
+
+		return nil
+	}, opts...)
+
+	return result, err
+}
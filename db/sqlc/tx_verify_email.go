@@ -10,6 +10,8 @@ import (
 type VerifyUserEmailTxParams struct {
 	EmailId    int64
 	SecretCode string
+	// AfterVerify runs inside the transaction once the user is marked as verified, it is optional
+	AfterVerify func(q Querier, user User) error
 }
 
 // VerifyUserEmailTxResult contains the result of the transfer transaction
@@ -19,7 +21,7 @@ type VerifyUserEmailTxResult struct {
 }
 
 // VerifyUserEmailTxParams contains the input parameters of the transfer transaction
-func (store *SQLStore) VerifyUserEmailTx(ctx context.Context, arg VerifyUserEmailTxParams) (VerifyUserEmailTxResult, error) {
+func (store *SQLStore) VerifyUserEmailTx(ctx context.Context, arg VerifyUserEmailTxParams, opts ...TxOption) (VerifyUserEmailTxResult, error) {
 	var result VerifyUserEmailTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
@@ -42,8 +44,16 @@ func (store *SQLStore) VerifyUserEmailTx(ctx context.Context, arg VerifyUserEmai
 			},
 		})
 
-		return err
-	})
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterVerify != nil {
+			return arg.AfterVerify(q, result.User)
+		}
+
+		return nil
+	}, opts...)
 
 	return result, err
 }
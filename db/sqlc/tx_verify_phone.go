@@ -0,0 +1,44 @@
+package sqlc
+
+import "context"
+
+// VerifyPhoneTxParams contains the input parameters of the verify phone transaction
+type VerifyPhoneTxParams struct {
+	// CodeID is the phone code that was entered correctly
+	CodeID int64
+	// Username and Phone must still match the user, a number changed since the code was sent is not verified
+	Username string
+	Phone    string
+}
+
+// VerifyPhoneTxResult contains the result of the verify phone transaction
+type VerifyPhoneTxResult struct {
+	User      User
+	PhoneCode PhoneCode
+}
+
+// VerifyPhoneTx consumes the phone code and marks the phone of the user as verified. ErrRecordNotFound is
+// returned when the code was consumed concurrently or the number changed, a unique violation on
+// VerifiedPhoneConstraint when another user verified the same number first.
+func (store *SQLStore) VerifyPhoneTx(ctx context.Context, arg VerifyPhoneTxParams, opts ...TxOption) (VerifyPhoneTxResult, error) {
+	var result VerifyPhoneTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.PhoneCode, err = q.UsePhoneCode(ctx, arg.CodeID)
+
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.MarkPhoneVerified(ctx, MarkPhoneVerifiedParams{
+			Username: arg.Username,
+			Phone:    arg.Phone,
+		})
+
+		return err
+	}, opts...)
+
+	return result, err
+}
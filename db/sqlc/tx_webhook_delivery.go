@@ -0,0 +1,76 @@
+package sqlc
+
+import (
+	"context"
+	"errors"
+)
+
+// CreateWebhookDeliveryTxParams contains the input parameters of the create webhook delivery transaction
+type CreateWebhookDeliveryTxParams struct {
+	CreateWebhookDeliveryParams
+	// AfterCreate runs inside the transaction, q must be used for any write such as outbox messages
+	AfterCreate func(q Querier, delivery WebhookDelivery) error
+}
+
+// CreateWebhookDeliveryTxResult contains the result of the create webhook delivery transaction
+type CreateWebhookDeliveryTxResult struct {
+	Delivery WebhookDelivery
+	// Created is false when the event was already fanned out to the endpoint
+	Created bool
+}
+
+// CreateWebhookDeliveryTx records a delivery of an event to an endpoint, at most once per endpoint and event
+func (store *SQLStore) CreateWebhookDeliveryTx(ctx context.Context, arg CreateWebhookDeliveryTxParams, opts ...TxOption) (CreateWebhookDeliveryTxResult, error) {
+	var result CreateWebhookDeliveryTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Delivery, err = q.CreateWebhookDelivery(ctx, arg.CreateWebhookDeliveryParams)
+
+		if errors.Is(err, ErrRecordNotFound) {
+			result.Created = false
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		result.Created = true
+		return arg.AfterCreate(q, result.Delivery)
+	}, opts...)
+
+	return result, err
+}
+
+// RedeliverWebhookTxParams contains the input parameters of the redeliver webhook transaction
+type RedeliverWebhookTxParams struct {
+	DeliveryID int64
+	// AfterReset runs inside the transaction, q must be used for any write such as outbox messages
+	AfterReset func(q Querier, delivery WebhookDelivery) error
+}
+
+// RedeliverWebhookTxResult contains the result of the redeliver webhook transaction
+type RedeliverWebhookTxResult struct {
+	Delivery WebhookDelivery
+}
+
+// RedeliverWebhookTx puts a delivery back into the pending state so it is sent again
+func (store *SQLStore) RedeliverWebhookTx(ctx context.Context, arg RedeliverWebhookTxParams, opts ...TxOption) (RedeliverWebhookTxResult, error) {
+	var result RedeliverWebhookTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Delivery, err = q.ResetWebhookDelivery(ctx, arg.DeliveryID)
+
+		if err != nil {
+			return err
+		}
+
+		return arg.AfterReset(q, result.Delivery)
+	}, opts...)
+
+	return result, err
+}
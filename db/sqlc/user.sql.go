@@ -15,7 +15,7 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO
     users (username,hashed_password,full_name,email)
-VALUES ($1, $2, $3, $4) RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role
+VALUES ($1, $2, $3, $4) RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, date_of_birth, phone, address, nationality, kyc_status, is_phone_verified
 `
 
 type CreateUserParams struct {
@@ -42,11 +42,17 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.IsEmailVerified,
 		&i.Role,
+		&i.DateOfBirth,
+		&i.Phone,
+		&i.Address,
+		&i.Nationality,
+		&i.KycStatus,
+		&i.IsPhoneVerified,
 	)
 	return i, err
 }
 
-const getUserByUserName = `-- name: GetUserByUserName :one
+const getUserByEmail = `-- name: GetUserByEmail :one
 SELECT 
     username,
     hashed_password,
@@ -59,10 +65,10 @@ SELECT
 FROM 
     users
 WHERE 
-    username = $1
+    email = $1
 `
 
-type GetUserByUserNameRow struct {
+type GetUserByEmailRow struct {
 	Username          string    `json:"username"`
 	HashedPassword    string    `json:"hashed_password"`
 	Role              string    `json:"role"`
@@ -73,6 +79,61 @@ type GetUserByUserNameRow struct {
 	CreatedAt         time.Time `json:"created_at"`
 }
 
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i GetUserByEmailRow
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.Role,
+		&i.FullName,
+		&i.Email,
+		&i.IsEmailVerified,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserByUserName = `-- name: GetUserByUserName :one
+SELECT 
+    username,
+    hashed_password,
+    role,
+    full_name,
+    email,
+    is_email_verified,
+    password_changed_at,
+    created_at,
+    date_of_birth,
+    phone,
+    address,
+    nationality,
+    kyc_status,
+    is_phone_verified
+FROM 
+    users
+WHERE 
+    username = $1
+`
+
+type GetUserByUserNameRow struct {
+	Username          string      `json:"username"`
+	HashedPassword    string      `json:"hashed_password"`
+	Role              string      `json:"role"`
+	FullName          string      `json:"full_name"`
+	Email             string      `json:"email"`
+	IsEmailVerified   bool        `json:"is_email_verified"`
+	PasswordChangedAt time.Time   `json:"password_changed_at"`
+	CreatedAt         time.Time   `json:"created_at"`
+	DateOfBirth       pgtype.Date `json:"date_of_birth"`
+	Phone             string      `json:"phone"`
+	Address           string      `json:"address"`
+	Nationality       string      `json:"nationality"`
+	KycStatus         string      `json:"kyc_status"`
+	IsPhoneVerified   bool        `json:"is_phone_verified"`
+}
+
 func (q *Queries) GetUserByUserName(ctx context.Context, username string) (GetUserByUserNameRow, error) {
 	row := q.db.QueryRow(ctx, getUserByUserName, username)
 	var i GetUserByUserNameRow
@@ -85,10 +146,117 @@ func (q *Queries) GetUserByUserName(ctx context.Context, username string) (GetUs
 		&i.IsEmailVerified,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.DateOfBirth,
+		&i.Phone,
+		&i.Address,
+		&i.Nationality,
+		&i.KycStatus,
+		&i.IsPhoneVerified,
 	)
 	return i, err
 }
 
+const markPhoneVerified = `-- name: MarkPhoneVerified :one
+UPDATE
+    users
+SET
+    is_phone_verified = TRUE
+WHERE
+    username = $1
+    AND phone = $2
+RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, date_of_birth, phone, address, nationality, kyc_status, is_phone_verified
+`
+
+type MarkPhoneVerifiedParams struct {
+	Username string `json:"username"`
+	Phone    string `json:"phone"`
+}
+
+// Only verifies the number the code was sent to, a number changed in the meantime stays unverified
+func (q *Queries) MarkPhoneVerified(ctx context.Context, arg MarkPhoneVerifiedParams) (User, error) {
+	row := q.db.QueryRow(ctx, markPhoneVerified, arg.Username, arg.Phone)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.CreatedAt,
+		&i.PasswordChangedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.DateOfBirth,
+		&i.Phone,
+		&i.Address,
+		&i.Nationality,
+		&i.KycStatus,
+		&i.IsPhoneVerified,
+	)
+	return i, err
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT
+    username,
+    role,
+    full_name,
+    email,
+    is_email_verified,
+    created_at
+FROM
+    users
+WHERE
+    username ILIKE '%' || $1::varchar || '%'
+    OR email ILIKE '%' || $1::varchar || '%'
+    OR full_name ILIKE '%' || $1::varchar || '%'
+ORDER BY
+    username
+LIMIT $2
+OFFSET $3
+`
+
+type SearchUsersParams struct {
+	Query  string `json:"query"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+type SearchUsersRow struct {
+	Username        string    `json:"username"`
+	Role            string    `json:"role"`
+	FullName        string    `json:"full_name"`
+	Email           string    `json:"email"`
+	IsEmailVerified bool      `json:"is_email_verified"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error) {
+	rows, err := q.db.Query(ctx, searchUsers, arg.Query, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchUsersRow{}
+	for rows.Next() {
+		var i SearchUsersRow
+		if err := rows.Scan(
+			&i.Username,
+			&i.Role,
+			&i.FullName,
+			&i.Email,
+			&i.IsEmailVerified,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE 
     users
@@ -96,10 +264,16 @@ SET
     hashed_password = COALESCE($1, hashed_password),
     is_email_verified = COALESCE($2, is_email_verified),
     full_name = COALESCE($3, full_name),
-    email = COALESCE($4, email)
+    email = COALESCE($4, email),
+    date_of_birth = COALESCE($5, date_of_birth),
+    phone = COALESCE($6, phone),
+    address = COALESCE($7, address),
+    nationality = COALESCE($8, nationality),
+    kyc_status = COALESCE($9, kyc_status),
+    is_phone_verified = COALESCE($10, is_phone_verified)
 WHERE
-    username = $5
-RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role
+    username = $11
+RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, date_of_birth, phone, address, nationality, kyc_status, is_phone_verified
 `
 
 type UpdateUserParams struct {
@@ -107,6 +281,12 @@ type UpdateUserParams struct {
 	IsEmailVerified pgtype.Bool `json:"is_email_verified"`
 	FullName        pgtype.Text `json:"full_name"`
 	Email           pgtype.Text `json:"email"`
+	DateOfBirth     pgtype.Date `json:"date_of_birth"`
+	Phone           pgtype.Text `json:"phone"`
+	Address         pgtype.Text `json:"address"`
+	Nationality     pgtype.Text `json:"nationality"`
+	KycStatus       pgtype.Text `json:"kyc_status"`
+	IsPhoneVerified pgtype.Bool `json:"is_phone_verified"`
 	Username        string      `json:"username"`
 }
 
@@ -116,6 +296,12 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.IsEmailVerified,
 		arg.FullName,
 		arg.Email,
+		arg.DateOfBirth,
+		arg.Phone,
+		arg.Address,
+		arg.Nationality,
+		arg.KycStatus,
+		arg.IsPhoneVerified,
 		arg.Username,
 	)
 	var i User
@@ -128,6 +314,128 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.IsEmailVerified,
 		&i.Role,
+		&i.DateOfBirth,
+		&i.Phone,
+		&i.Address,
+		&i.Nationality,
+		&i.KycStatus,
+		&i.IsPhoneVerified,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE 
+    users
+SET
+    hashed_password = $2,
+    password_changed_at = $3
+WHERE
+    username = $1
+RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, date_of_birth, phone, address, nationality, kyc_status, is_phone_verified
+`
+
+type UpdateUserPasswordParams struct {
+	Username          string    `json:"username"`
+	HashedPassword    string    `json:"hashed_password"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserPassword, arg.Username, arg.HashedPassword, arg.PasswordChangedAt)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.CreatedAt,
+		&i.PasswordChangedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.DateOfBirth,
+		&i.Phone,
+		&i.Address,
+		&i.Nationality,
+		&i.KycStatus,
+		&i.IsPhoneVerified,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE
+    users
+SET
+    role = $2
+WHERE
+    username = $1
+RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, date_of_birth, phone, address, nationality, kyc_status, is_phone_verified
+`
+
+type UpdateUserRoleParams struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.Username, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.CreatedAt,
+		&i.PasswordChangedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.DateOfBirth,
+		&i.Phone,
+		&i.Address,
+		&i.Nationality,
+		&i.KycStatus,
+		&i.IsPhoneVerified,
+	)
+	return i, err
+}
+
+const updateUserKycStatus = `-- name: UpdateUserKycStatus :one
+UPDATE
+    users
+SET
+    kyc_status = $1
+WHERE
+    username = $2
+    AND kyc_status = $3
+RETURNING username, hashed_password, full_name, email, created_at, password_changed_at, is_email_verified, role, date_of_birth, phone, address, nationality, kyc_status, is_phone_verified
+`
+
+type UpdateUserKycStatusParams struct {
+	ToStatus   string `json:"to_status"`
+	Username   string `json:"username"`
+	FromStatus string `json:"from_status"`
+}
+
+// Only moves the status when it is still the one the change was decided on
+func (q *Queries) UpdateUserKycStatus(ctx context.Context, arg UpdateUserKycStatusParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserKycStatus, arg.ToStatus, arg.Username, arg.FromStatus)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.CreatedAt,
+		&i.PasswordChangedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.DateOfBirth,
+		&i.Phone,
+		&i.Address,
+		&i.Nationality,
+		&i.KycStatus,
+		&i.IsPhoneVerified,
 	)
 	return i, err
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ChokeGuy/simple-bank/util"
 	pw "github.com/ChokeGuy/simple-bank/util/password"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
@@ -147,3 +148,52 @@ func TestUpdateUserAllFields(t *testing.T) {
 	require.Equal(t, newFullName, newUser.FullName)
 	require.Equal(t, newEmail, newUser.Email)
 }
+
+func TestChangePasswordTx(t *testing.T) {
+	user := createRandomUser(t)
+	reset := createRandomPasswordReset(t, user)
+
+	var sessions []Session
+	for i := 0; i < 3; i++ {
+		result, err := testStore.CreateSessionTx(context.Background(), CreateSessionTxParams{
+			CreateSessionParams: randomCreateSessionParams(user.Username, time.Now().Add(time.Hour)),
+			MaxSessions:         5,
+		})
+		require.NoError(t, err)
+		sessions = append(sessions, result.Session)
+	}
+
+	kept := sessions[0]
+	arg := ChangePasswordTxParams{
+		Username:                user.Username,
+		HashedPassword:          util.RandomString(60),
+		PasswordChangedAt:       time.Now(),
+		KeepSessionID:           kept.ID,
+		KeepSessionRefreshToken: util.RandomString(64),
+		ClientIp:                util.RandomString(6),
+	}
+
+	result, err := testStore.ChangePasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.HashedPassword, result.User.HashedPassword)
+	require.WithinDuration(t, arg.PasswordChangedAt, result.User.PasswordChangedAt, time.Millisecond)
+	require.ElementsMatch(t, []uuid.UUID{sessions[1].ID, sessions[2].ID}, result.RevokedSessions)
+
+	session, err := testStore.GetSessionById(context.Background(), kept.ID)
+	require.NoError(t, err)
+	require.Equal(t, arg.KeepSessionRefreshToken, session.RefreshToken)
+	require.WithinDuration(t, arg.PasswordChangedAt, session.PasswordChangedAt, time.Millisecond)
+
+	// A reset link sent before the change can not be used afterwards
+	_, err = testStore.UsePasswordReset(context.Background(), reset.TokenHash)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	// Without a kept session every session is revoked
+	result, err = testStore.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		Username:          user.Username,
+		HashedPassword:    util.RandomString(60),
+		PasswordChangedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{kept.ID}, result.RevokedSessions)
+}
@@ -0,0 +1,139 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUserTotp(t *testing.T, username string) UserTotp {
+	arg := UpsertPendingUserTotpParams{
+		Username:         username,
+		SecretCiphertext: []byte(util.RandomString(32)),
+	}
+
+	userTotp, err := testStore.UpsertPendingUserTotp(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, userTotp.Username)
+	require.Equal(t, arg.SecretCiphertext, userTotp.SecretCiphertext)
+	require.False(t, userTotp.ConfirmedAt.Valid)
+
+	return userTotp
+}
+
+func TestConfirmTotpTx(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomUserTotp(t, user.Username)
+
+	hashes := []string{util.RandomString(64), util.RandomString(64)}
+
+	result, err := testStore.ConfirmTotpTx(context.Background(), ConfirmTotpTxParams{
+		Username:           user.Username,
+		Step:               100,
+		RecoveryCodeHashes: hashes,
+	})
+	require.NoError(t, err)
+	require.True(t, result.UserTotp.ConfirmedAt.Valid)
+	require.Equal(t, int64(100), result.UserTotp.LastUsedStep)
+
+	// A confirmed secret can neither be confirmed again nor replaced by a new enrolment
+	_, err = testStore.ConfirmTotpTx(context.Background(), ConfirmTotpTxParams{Username: user.Username, Step: 101})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = testStore.UpsertPendingUserTotp(context.Background(), UpsertPendingUserTotpParams{
+		Username:         user.Username,
+		SecretCiphertext: []byte(util.RandomString(32)),
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	// Each recovery code is accepted once
+	arg := UseRecoveryCodeParams{Username: user.Username, CodeHash: hashes[0]}
+
+	code, err := testStore.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, code.UsedAt.Valid)
+
+	_, err = testStore.UseRecoveryCode(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	require.NoError(t, testStore.DisableTotpTx(context.Background(), user.Username))
+
+	_, err = testStore.GetUserTotp(context.Background(), user.Username)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	_, err = testStore.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{Username: user.Username, CodeHash: hashes[1]})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestUseUserTotpStep(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomUserTotp(t, user.Username)
+
+	_, err := testStore.ConfirmTotpTx(context.Background(), ConfirmTotpTxParams{Username: user.Username, Step: 10})
+	require.NoError(t, err)
+
+	used, err := testStore.UseUserTotpStep(context.Background(), UseUserTotpStepParams{Username: user.Username, LastUsedStep: 11})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+
+	// Replaying the same or an older step updates nothing
+	for _, step := range []int64{11, 10} {
+		used, err = testStore.UseUserTotpStep(context.Background(), UseUserTotpStepParams{Username: user.Username, LastUsedStep: step})
+		require.NoError(t, err)
+		require.Zero(t, used)
+	}
+}
+
+func TestMfaChallenge(t *testing.T) {
+	user := createRandomUser(t)
+
+	expired, err := testStore.CreateMfaChallengeTx(context.Background(), CreateMfaChallengeParams{
+		Username:  user.Username,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	arg := CreateMfaChallengeParams{
+		Username:    user.Username,
+		TokenHash:   util.RandomString(64),
+		DeviceLabel: util.RandomString(6),
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+
+	result, err := testStore.CreateMfaChallengeTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.DeviceLabel, result.MfaChallenge.DeviceLabel)
+
+	// Creating a challenge drops the expired ones of the user
+	_, err = testStore.GetMfaChallengeByTokenHash(context.Background(), expired.MfaChallenge.TokenHash)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	attempt := IncrementMfaChallengeAttemptsParams{ID: result.MfaChallenge.ID, MaxAttempts: 2}
+
+	challenge, err := testStore.IncrementMfaChallengeAttempts(context.Background(), attempt)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), challenge.Attempts)
+
+	challenge, err = testStore.IncrementMfaChallengeAttempts(context.Background(), attempt)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), challenge.Attempts)
+
+	// The limit is reached, the attempt is refused and not counted
+	_, err = testStore.IncrementMfaChallengeAttempts(context.Background(), attempt)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	challenge, err = testStore.GetMfaChallengeByTokenHash(context.Background(), arg.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), challenge.Attempts)
+
+	challenge, err = testStore.ConsumeMfaChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.True(t, challenge.ConsumedAt.Valid)
+
+	_, err = testStore.ConsumeMfaChallenge(context.Background(), challenge.ID)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
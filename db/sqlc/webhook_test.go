@@ -0,0 +1,155 @@
+package sqlc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhookEndpoint(t *testing.T, owner string, eventTypes ...string) WebhookEndpoint {
+	arg := CreateWebhookEndpointParams{
+		Owner:      owner,
+		Url:        "https://example.com/" + util.RandomString(6),
+		Secret:     util.RandomString(32),
+		EventTypes: eventTypes,
+	}
+
+	endpoint, err := testStore.CreateWebhookEndpoint(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, endpoint.ID)
+
+	require.Equal(t, arg.Owner, endpoint.Owner)
+	require.Equal(t, arg.Url, endpoint.Url)
+	require.Equal(t, arg.Secret, endpoint.Secret)
+	require.Equal(t, arg.EventTypes, endpoint.EventTypes)
+	require.True(t, endpoint.IsActive)
+	require.False(t, endpoint.PreviousSecret.Valid)
+
+	return endpoint
+}
+
+func TestCreateWebhookEndpoint(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomWebhookEndpoint(t, user.Username, "transfer.completed")
+}
+
+func TestListWebhookEndpointsForEvent(t *testing.T) {
+	user := createRandomUser(t)
+
+	transfers := createRandomWebhookEndpoint(t, user.Username, "transfer.completed", "user.verified")
+	createRandomWebhookEndpoint(t, user.Username, "user.verified")
+	disabled := createRandomWebhookEndpoint(t, user.Username, "transfer.completed")
+
+	_, err := testStore.UpdateWebhookEndpoint(context.Background(), UpdateWebhookEndpointParams{
+		ID:       disabled.ID,
+		IsActive: pgtype.Bool{Bool: false, Valid: true},
+	})
+	require.NoError(t, err)
+
+	endpoints, err := testStore.ListWebhookEndpointsForEvent(context.Background(), ListWebhookEndpointsForEventParams{
+		Owner:     user.Username,
+		EventType: "transfer.completed",
+	})
+	require.NoError(t, err)
+	require.Len(t, endpoints, 1)
+	require.Equal(t, transfers.ID, endpoints[0].ID)
+}
+
+func TestRotateWebhookEndpointSecret(t *testing.T) {
+	user := createRandomUser(t)
+	endpoint := createRandomWebhookEndpoint(t, user.Username, "transfer.completed")
+
+	expiresAt := time.Now().Add(time.Hour)
+	rotated, err := testStore.RotateWebhookEndpointSecret(context.Background(), RotateWebhookEndpointSecretParams{
+		ID:                      endpoint.ID,
+		Secret:                  util.RandomString(32),
+		PreviousSecretExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	require.NoError(t, err)
+
+	require.NotEqual(t, endpoint.Secret, rotated.Secret)
+	require.Equal(t, endpoint.Secret, rotated.PreviousSecret.String)
+	require.WithinDuration(t, expiresAt, rotated.PreviousSecretExpiresAt.Time, time.Second)
+}
+
+func TestCreateWebhookDeliveryTx(t *testing.T) {
+	user := createRandomUser(t)
+	endpoint := createRandomWebhookEndpoint(t, user.Username, "transfer.completed")
+
+	arg := CreateWebhookDeliveryTxParams{
+		CreateWebhookDeliveryParams: CreateWebhookDeliveryParams{
+			EndpointID: endpoint.ID,
+			EventID:    uuid.New(),
+			EventType:  "transfer.completed",
+			Payload:    []byte(`{"type":"transfer.completed"}`),
+		},
+	}
+
+	afterCreateCalls := 0
+	arg.AfterCreate = func(q Querier, delivery WebhookDelivery) error {
+		afterCreateCalls++
+		return nil
+	}
+
+	result, err := testStore.CreateWebhookDeliveryTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.Created)
+	require.Equal(t, "pending", result.Delivery.Status)
+
+	// a retried fan out must not create a second delivery of the same event
+	result, err = testStore.CreateWebhookDeliveryTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, result.Created)
+	require.Equal(t, 1, afterCreateCalls)
+}
+
+func TestUpdateWebhookDeliveryAttempt(t *testing.T) {
+	user := createRandomUser(t)
+	endpoint := createRandomWebhookEndpoint(t, user.Username, "user.verified")
+
+	delivery, err := testStore.CreateWebhookDelivery(context.Background(), CreateWebhookDeliveryParams{
+		EndpointID: endpoint.ID,
+		EventID:    uuid.New(),
+		EventType:  "user.verified",
+		Payload:    []byte(`{}`),
+	})
+	require.NoError(t, err)
+
+	failed, err := testStore.UpdateWebhookDeliveryAttempt(context.Background(), UpdateWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         "failed",
+		ResponseStatus: pgtype.Int4{Int32: 500, Valid: true},
+		LastError:      pgtype.Text{String: "endpoint responded with status 500", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failed.Attempts)
+	require.False(t, failed.DeliveredAt.Valid)
+
+	reset, err := testStore.ResetWebhookDelivery(context.Background(), delivery.ID)
+	require.NoError(t, err)
+	require.Equal(t, "pending", reset.Status)
+	require.False(t, reset.LastError.Valid)
+
+	succeeded, err := testStore.UpdateWebhookDeliveryAttempt(context.Background(), UpdateWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         "succeeded",
+		ResponseStatus: pgtype.Int4{Int32: 200, Valid: true},
+		DeliveredAt:    pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), succeeded.Attempts)
+	require.True(t, succeeded.DeliveredAt.Valid)
+
+	deliveries, err := testStore.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		EndpointID: endpoint.ID,
+		Limit:      5,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, "succeeded", deliveries[0].Status)
+}
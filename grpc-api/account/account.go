@@ -6,7 +6,10 @@ import (
 
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	"github.com/ChokeGuy/simple-bank/pb"
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
 	sv "github.com/ChokeGuy/simple-bank/server/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -19,10 +22,23 @@ func NewAccountHandler(server *sv.Server) *AccountHandler {
 }
 
 func (h *AccountHandler) GetListAccount(ctx context.Context, req *pb.ListAccountRequest) (*pb.ListAccountResponse, error) {
-	//authPayload := ctx.MustGet(auth.AuthPayloadKey).(*token.Payload)
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.AccountRead)
+
+	if err != nil {
+		return nil, err
+	}
+
+	owner := req.GetOwner()
+	if owner == "" {
+		owner = authPayload.UserName
+	}
+
+	if !rbac.CanAccess(authPayload, owner, rbac.AccountReadAny) {
+		return nil, status.Errorf(codes.PermissionDenied, "accounts do not belong to the authenticated user")
+	}
 
 	arg := db.ListAccountsParams{
-		Owner:  req.GetOwner(),
+		Owner:  owner,
 		Limit:  req.GetSize(),
 		Offset: (req.GetPage() - 1) * req.GetSize(),
 	}
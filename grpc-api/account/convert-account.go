@@ -3,6 +3,7 @@ package account
 import (
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	"github.com/ChokeGuy/simple-bank/pb"
+	"github.com/ChokeGuy/simple-bank/util"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -10,11 +11,12 @@ func convertListAccount(account []db.Account) []*pb.Account {
 	var accounts []*pb.Account
 	for _, v := range account {
 		accounts = append(accounts, &pb.Account{
-			Id:        v.ID,
-			Owner:     v.Owner,
-			Balance:   v.Balance,
-			Currency:  v.Currency,
-			CreatedAt: timestamppb.New(v.CreatedAt),
+			AccountNumber:    v.AccountNumber,
+			Owner:            v.Owner,
+			Balance:          v.Balance,
+			FormattedBalance: util.FormatAmount(v.Balance, v.Currency),
+			Currency:         v.Currency,
+			CreatedAt:        timestamppb.New(v.CreatedAt),
 		})
 	}
 
@@ -0,0 +1,779 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pb"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
+	myErr "github.com/ChokeGuy/simple-bank/pkg/errors"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	sv "github.com/ChokeGuy/simple-bank/server/grpc"
+	"github.com/ChokeGuy/simple-bank/validations"
+	"github.com/ChokeGuy/simple-bank/worker"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminHandler serves the back-office service of bankers, every call is written to the audit log
+type AdminHandler struct {
+	*sv.Server
+}
+
+func NewAdminHandler(server *sv.Server) *AdminHandler {
+	return &AdminHandler{Server: server}
+}
+
+// recordRead writes the audit log of a read, the data is not returned when it cannot be recorded
+func (h *AdminHandler) recordRead(ctx context.Context, entry db.CreateAuditLogParams) error {
+	if _, err := h.Store.CreateAuditLog(ctx, entry); err != nil {
+		return status.Errorf(codes.Internal, "failed to write audit log: %v", err)
+	}
+
+	return nil
+}
+
+// auditEntry builds the audit log of an action of the authenticated banker
+func auditEntry(ctx context.Context, authPayload *token.Payload, action, target string, details map[string]any) db.CreateAuditLogParams {
+	return audit.Entry(authPayload.UserName, action, target, auth.GrpcClientIP(ctx), details)
+}
+
+func (h *AdminHandler) requireUser(ctx context.Context, username string) error {
+	if _, err := h.Store.GetUserByUserName(ctx, username); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return status.Errorf(codes.NotFound, "user not found")
+		}
+		return status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	return nil
+}
+
+func (h *AdminHandler) SearchUsers(ctx context.Context, req *pb.SearchUsersRequest) (*pb.SearchUsersResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.UserReadAny)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateSearchUsersRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	users, err := h.Store.SearchUsers(ctx, db.SearchUsersParams{
+		Query:  req.GetQuery(),
+		Limit:  req.GetSize(),
+		Offset: (req.GetPage() - 1) * req.GetSize(),
+	})
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search users: %v", err)
+	}
+
+	entry := auditEntry(ctx, authPayload, audit.SearchUsers, "", map[string]any{"query": req.GetQuery(), "page": req.GetPage()})
+	if err := h.recordRead(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &pb.SearchUsersResponse{Users: convertSearchUsers(users)}, nil
+}
+
+func (h *AdminHandler) ListUserAccounts(ctx context.Context, req *pb.ListUserAccountsRequest) (*pb.ListUserAccountsResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.AccountReadAny)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUserPageRequest(req.GetUserName(), req.GetPage(), req.GetSize())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if err := h.requireUser(ctx, req.GetUserName()); err != nil {
+		return nil, err
+	}
+
+	accounts, err := h.Store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  req.GetUserName(),
+		Limit:  req.GetSize(),
+		Offset: (req.GetPage() - 1) * req.GetSize(),
+	})
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %v", err)
+	}
+
+	entry := auditEntry(ctx, authPayload, audit.ListUserAccounts, req.GetUserName(), map[string]any{"page": req.GetPage()})
+	if err := h.recordRead(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &pb.ListUserAccountsResponse{Accounts: convertAccounts(accounts)}, nil
+}
+
+func (h *AdminHandler) ListUserTransfers(ctx context.Context, req *pb.ListUserTransfersRequest) (*pb.ListUserTransfersResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.TransferReadAny)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUserPageRequest(req.GetUserName(), req.GetPage(), req.GetSize())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if err := h.requireUser(ctx, req.GetUserName()); err != nil {
+		return nil, err
+	}
+
+	transfers, err := h.Store.ListTransfersByOwner(ctx, db.ListTransfersByOwnerParams{
+		Owner:  req.GetUserName(),
+		Limit:  req.GetSize(),
+		Offset: (req.GetPage() - 1) * req.GetSize(),
+	})
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list transfers: %v", err)
+	}
+
+	entry := auditEntry(ctx, authPayload, audit.ListUserTransfers, req.GetUserName(), map[string]any{"page": req.GetPage()})
+	if err := h.recordRead(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &pb.ListUserTransfersResponse{Transfers: convertTransfers(transfers)}, nil
+}
+
+func (h *AdminHandler) UpdateUserRole(ctx context.Context, req *pb.UpdateUserRoleRequest) (*pb.UpdateUserRoleResponse, error) {
+	authPayload, err := auth.AuthorizeStepUpGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.UserRoleUpdate, h.StepUpPolicy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUpdateUserRoleRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	// Another banker has to do it so nobody can escalate or lock themselves out
+	if authPayload.UserName == req.GetUserName() {
+		return nil, status.Errorf(codes.FailedPrecondition, "bankers cannot change their own role")
+	}
+
+	var user db.User
+	var revoked []uuid.UUID
+
+	_, err = h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, authPayload, audit.UpdateUserRole, req.GetUserName(), map[string]any{"role": req.GetRole()}),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			user, err = q.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+				Username: req.GetUserName(),
+				Role:     req.GetRole(),
+			})
+
+			if err != nil {
+				return err
+			}
+
+			// The role is part of the tokens, the user has to log in again to get the new one
+			revoked, err = q.RevokeSessionsByUserName(ctx, req.GetUserName())
+			return err
+		},
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update user role: %v", err)
+	}
+
+	h.SessionChecker.Invalidate(revoked...)
+
+	return &pb.UpdateUserRoleResponse{User: convertUser(user)}, nil
+}
+
+func (h *AdminHandler) ForceVerifyEmail(ctx context.Context, req *pb.ForceVerifyEmailRequest) (*pb.ForceVerifyEmailResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.UserUpdateAny)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUserName(req.GetUserName())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	var user db.User
+
+	_, err = h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, authPayload, audit.ForceVerifyEmail, req.GetUserName(), nil),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			user, err = q.UpdateUser(ctx, db.UpdateUserParams{
+				Username:        req.GetUserName(),
+				IsEmailVerified: pgtype.Bool{Bool: false, Valid: true},
+			})
+
+			if err != nil {
+				return err
+			}
+
+			if err := q.InvalidateVerifyEmails(ctx, req.GetUserName()); err != nil {
+				return err
+			}
+
+			taskPayload := &worker.PayloadSendVerifyEmail{
+				UserName: req.GetUserName(),
+			}
+
+			opts := worker.OutboxOptions{
+				MaxRetry: 10,
+				Queue:    worker.QueueCritical,
+			}
+
+			return worker.EnqueueTaskSendVerifyEmail(ctx, q, taskPayload, opts)
+		},
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to force email verification: %v", err)
+	}
+
+	return &pb.ForceVerifyEmailResponse{User: convertUser(user)}, nil
+}
+
+func (h *AdminHandler) GetUserKyc(ctx context.Context, req *pb.GetUserKycRequest) (*pb.GetUserKycResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.KycReview)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUserPageRequest(req.GetUserName(), req.GetPage(), req.GetSize())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, req.GetUserName())
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	reviews, err := h.Store.ListKycReviews(ctx, db.ListKycReviewsParams{
+		Username: req.GetUserName(),
+		Limit:    req.GetSize(),
+		Offset:   (req.GetPage() - 1) * req.GetSize(),
+	})
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list KYC reviews: %v", err)
+	}
+
+	entry := auditEntry(ctx, authPayload, audit.GetUserKyc, req.GetUserName(), map[string]any{"page": req.GetPage()})
+	if err := h.recordRead(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetUserKycResponse{User: convertProfileRow(user), Reviews: convertKycReviews(reviews)}, nil
+}
+
+func (h *AdminHandler) UpdateUserKyc(ctx context.Context, req *pb.UpdateUserKycRequest) (*pb.UpdateUserKycResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.KycReview)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUpdateUserKycRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if authPayload.UserName == req.GetUserName() {
+		return nil, status.Errorf(codes.FailedPrecondition, "bankers cannot review their own KYC")
+	}
+
+	current, err := h.Store.GetUserByUserName(ctx, req.GetUserName())
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	if !kyc.CanReview(current.KycStatus, req.GetStatus()) {
+		return nil, status.Errorf(codes.FailedPrecondition, "KYC status cannot change from %s to %s", current.KycStatus, req.GetStatus())
+	}
+
+	var user db.User
+	var review db.KycReview
+
+	details := map[string]any{"from": current.KycStatus, "to": req.GetStatus(), "note": req.GetNote()}
+
+	_, err = h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, authPayload, audit.UpdateUserKyc, req.GetUserName(), details),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			user, err = q.UpdateUserKycStatus(ctx, db.UpdateUserKycStatusParams{
+				Username:   req.GetUserName(),
+				FromStatus: current.KycStatus,
+				ToStatus:   req.GetStatus(),
+			})
+
+			if err != nil {
+				return err
+			}
+
+			review, err = q.CreateKycReview(ctx, db.CreateKycReviewParams{
+				Username:   req.GetUserName(),
+				FromStatus: current.KycStatus,
+				ToStatus:   req.GetStatus(),
+				Reviewer:   authPayload.UserName,
+				Note:       req.GetNote(),
+			})
+
+			return err
+		},
+	})
+
+	if err != nil {
+		// The user submitted or changed their profile, or another banker reviewed it in the meantime
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.Aborted, "KYC status changed, please review it again")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update user KYC: %v", err)
+	}
+
+	return &pb.UpdateUserKycResponse{User: convertProfile(user), Review: convertKycReview(review)}, nil
+}
+
+func (h *AdminHandler) ListUserKycDocuments(ctx context.Context, req *pb.ListUserKycDocumentsRequest) (*pb.ListUserKycDocumentsResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.KycReview)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUserPageRequest(req.GetUserName(), req.GetPage(), req.GetSize())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if err := h.requireUser(ctx, req.GetUserName()); err != nil {
+		return nil, err
+	}
+
+	documents, err := h.Store.ListKycDocuments(ctx, db.ListKycDocumentsParams{
+		Username: req.GetUserName(),
+		Limit:    req.GetSize(),
+		Offset:   (req.GetPage() - 1) * req.GetSize(),
+	})
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list documents: %v", err)
+	}
+
+	entry := auditEntry(ctx, authPayload, audit.ListKycDocuments, req.GetUserName(), map[string]any{"page": req.GetPage()})
+	if err := h.recordRead(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &pb.ListUserKycDocumentsResponse{Documents: convertKycDocuments(documents)}, nil
+}
+
+// GetKycDocument returns a document with its decrypted content
+func (h *AdminHandler) GetKycDocument(ctx context.Context, req *pb.GetKycDocumentRequest) (*pb.GetKycDocumentResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.KycReview)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateKycDocumentID(req.GetId())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	document, err := h.getKycDocument(ctx, req.GetId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := h.KycDocuments.Content(ctx, document)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read document: %v", err)
+	}
+
+	entry := auditEntry(ctx, authPayload, audit.GetKycDocument, document.Username, map[string]any{"document": document.ID})
+	if err := h.recordRead(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetKycDocumentResponse{Document: convertKycDocument(document), Content: content}, nil
+}
+
+func (h *AdminHandler) ReviewKycDocument(ctx context.Context, req *pb.ReviewKycDocumentRequest) (*pb.ReviewKycDocumentResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.KycReview)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateReviewKycDocumentRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	document, err := h.getKycDocument(ctx, req.GetId())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if authPayload.UserName == document.Username {
+		return nil, status.Errorf(codes.FailedPrecondition, "bankers cannot review their own documents")
+	}
+
+	if err := kyc.CanReviewDocument(document); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	details := map[string]any{"document": document.ID, "status": req.GetStatus(), "note": req.GetNote()}
+
+	_, err = h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: auditEntry(ctx, authPayload, audit.ReviewKycDocument, document.Username, details),
+		Execute: func(q db.Querier) error {
+			var err error
+
+			document, err = q.ReviewKycDocument(ctx, db.ReviewKycDocumentParams{
+				ID:         document.ID,
+				FromStatus: kyc.DocumentPending,
+				ToStatus:   req.GetStatus(),
+				Reviewer:   authPayload.UserName,
+				Note:       req.GetNote(),
+			})
+
+			return err
+		},
+	})
+
+	if err != nil {
+		// Another banker reviewed the document in the meantime
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.Aborted, "document was already reviewed")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to review document: %v", err)
+	}
+
+	return &pb.ReviewKycDocumentResponse{Document: convertKycDocument(document)}, nil
+}
+
+func (h *AdminHandler) getKycDocument(ctx context.Context, id int64) (db.KycDocument, error) {
+	document, err := h.Store.GetKycDocument(ctx, id)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.KycDocument{}, status.Errorf(codes.NotFound, "document not found")
+		}
+		return db.KycDocument{}, status.Errorf(codes.Internal, "failed to get document: %v", err)
+	}
+
+	return document, nil
+}
+
+// ListUserSessions lists the active sessions of a user, bankers need their IDs to block them
+func (h *AdminHandler) ListUserSessions(ctx context.Context, req *pb.ListUserSessionsRequest) (*pb.ListUserSessionsResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.SessionBlock)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUserName(req.GetUserName())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if err := h.requireUser(ctx, req.GetUserName()); err != nil {
+		return nil, err
+	}
+
+	sessions, err := h.Store.ListSessionsByUserName(ctx, req.GetUserName())
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list sessions: %v", err)
+	}
+
+	entry := auditEntry(ctx, authPayload, audit.ListUserSessions, req.GetUserName(), nil)
+	if err := h.recordRead(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &pb.ListUserSessionsResponse{Sessions: convertSessions(sessions)}, nil
+}
+
+func (h *AdminHandler) BlockSession(ctx context.Context, req *pb.BlockSessionRequest) (*pb.BlockSessionResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.SessionBlock)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, violations := validateSessionID(req.GetSessionID())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	entry := auditEntry(ctx, authPayload, audit.BlockSession, req.GetSessionID(), map[string]any{"reason": req.GetReason()})
+	session, err := h.setSessionBlocked(ctx, sessionID, true, entry)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.BlockSessionResponse{
+		SessionID: session.ID.String(),
+		UserName:  session.Username,
+		IsBlocked: session.IsBlocked,
+	}, nil
+}
+
+func (h *AdminHandler) UnblockSession(ctx context.Context, req *pb.UnblockSessionRequest) (*pb.UnblockSessionResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.SessionBlock)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, violations := validateSessionID(req.GetSessionID())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	entry := auditEntry(ctx, authPayload, audit.UnblockSession, req.GetSessionID(), nil)
+	session, err := h.setSessionBlocked(ctx, sessionID, false, entry)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UnblockSessionResponse{
+		SessionID: session.ID.String(),
+		UserName:  session.Username,
+		IsBlocked: session.IsBlocked,
+	}, nil
+}
+
+func (h *AdminHandler) setSessionBlocked(ctx context.Context, sessionID uuid.UUID, blocked bool, entry db.CreateAuditLogParams) (db.Session, error) {
+	var session db.Session
+
+	_, err := h.Store.AuditTx(ctx, db.AuditTxParams{
+		CreateAuditLogParams: entry,
+		Execute: func(q db.Querier) error {
+			var err error
+
+			session, err = q.SetSessionBlocked(ctx, db.SetSessionBlockedParams{
+				ID:        sessionID,
+				IsBlocked: blocked,
+			})
+
+			return err
+		},
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.Session{}, status.Errorf(codes.NotFound, "session not found")
+		}
+		return db.Session{}, status.Errorf(codes.Internal, "failed to update session: %v", err)
+	}
+
+	// Drop the cached state so the change applies to the next request of the session
+	h.SessionChecker.Invalidate(session.ID)
+
+	return session, nil
+}
+
+func (h *AdminHandler) ListAuditLogs(ctx context.Context, req *pb.ListAuditLogsRequest) (*pb.ListAuditLogsResponse, error) {
+	authPayload, err := auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, rbac.AuditLogRead)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListAuditLogsRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	logs, err := h.Store.ListAuditLogs(ctx, db.ListAuditLogsParams{
+		Actor:  pgtype.Text{String: req.GetActor(), Valid: req.Actor != nil},
+		Target: pgtype.Text{String: req.GetTarget(), Valid: req.Target != nil},
+		Limit:  req.GetSize(),
+		Offset: (req.GetPage() - 1) * req.GetSize(),
+	})
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list audit logs: %v", err)
+	}
+
+	details := map[string]any{"actor": req.GetActor(), "target": req.GetTarget(), "page": req.GetPage()}
+	if err := h.recordRead(ctx, auditEntry(ctx, authPayload, audit.ListAuditLogs, "", details)); err != nil {
+		return nil, err
+	}
+
+	return &pb.ListAuditLogsResponse{AuditLogs: convertAuditLogs(logs)}, nil
+}
+
+func validatePage(page, size int32) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidatePage(page); err != nil {
+		violations = append(violations, myErr.FieldViolation("page", err))
+	}
+
+	if err := validations.ValidatePageSize(size); err != nil {
+		violations = append(violations, myErr.FieldViolation("size", err))
+	}
+
+	return violations
+}
+
+func validateSearchUsersRequest(req *pb.SearchUsersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateString(req.GetQuery(), 0, 100); err != nil {
+		violations = append(violations, myErr.FieldViolation("query", err))
+	}
+
+	return append(violations, validatePage(req.GetPage(), req.GetSize())...)
+}
+
+func validateUserName(userName string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateUsername(userName); err != nil {
+		violations = append(violations, myErr.FieldViolation("userName", err))
+	}
+
+	return violations
+}
+
+func validateUserPageRequest(userName string, page, size int32) (violations []*errdetails.BadRequest_FieldViolation) {
+	return append(validateUserName(userName), validatePage(page, size)...)
+}
+
+func validateUpdateUserRoleRequest(req *pb.UpdateUserRoleRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = validateUserName(req.GetUserName())
+
+	if err := validations.ValidateRole(req.GetRole()); err != nil {
+		violations = append(violations, myErr.FieldViolation("role", err))
+	}
+
+	return violations
+}
+
+func validateUpdateUserKycRequest(req *pb.UpdateUserKycRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = validateUserName(req.GetUserName())
+
+	if err := validations.ValidateKycStatus(req.GetStatus()); err != nil {
+		violations = append(violations, myErr.FieldViolation("status", err))
+	}
+
+	if err := validations.ValidateString(req.GetNote(), 1, 500); err != nil {
+		violations = append(violations, myErr.FieldViolation("note", err))
+	}
+
+	return violations
+}
+
+func validateKycDocumentID(id int64) (violations []*errdetails.BadRequest_FieldViolation) {
+	if id < 1 {
+		violations = append(violations, myErr.FieldViolation("id", fmt.Errorf("id must be a positive integer")))
+	}
+
+	return violations
+}
+
+func validateReviewKycDocumentRequest(req *pb.ReviewKycDocumentRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = validateKycDocumentID(req.GetId())
+
+	if err := validations.ValidateKycDocumentDecision(req.GetStatus()); err != nil {
+		violations = append(violations, myErr.FieldViolation("status", err))
+	}
+
+	// Rejections have to tell the user why
+	minNote := 0
+	if req.GetStatus() == kyc.DocumentRejected {
+		minNote = 1
+	}
+
+	if err := validations.ValidateString(req.GetNote(), minNote, 500); err != nil {
+		violations = append(violations, myErr.FieldViolation("note", err))
+	}
+
+	return violations
+}
+
+func validateSessionID(sessionID string) (uuid.UUID, []*errdetails.BadRequest_FieldViolation) {
+	id, err := uuid.Parse(sessionID)
+
+	if err != nil {
+		return uuid.Nil, []*errdetails.BadRequest_FieldViolation{myErr.FieldViolation("sessionID", err)}
+	}
+
+	return id, nil
+}
+
+func validateListAuditLogsRequest(req *pb.ListAuditLogsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.Actor != nil {
+		// Service accounts act under a principal that is not a username
+		if err := validations.ValidateString(req.GetActor(), 1, 100); err != nil {
+			violations = append(violations, myErr.FieldViolation("actor", err))
+		}
+	}
+
+	if req.Target != nil {
+		if err := validations.ValidateString(req.GetTarget(), 1, 64); err != nil {
+			violations = append(violations, myErr.FieldViolation("target", err))
+		}
+	}
+
+	return append(violations, validatePage(req.GetPage(), req.GetSize())...)
+}
@@ -0,0 +1,684 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/consts"
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pb"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	server "github.com/ChokeGuy/simple-bank/server/grpc"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const bankerName = "banker"
+
+// Helper function to add authorization metadata to context, the token is the one of a user
+// who just logged in with their password unless opts say otherwise
+func addAuthorizationMetadata(
+	ctx context.Context,
+	t *testing.T,
+	tokenMaker token.Maker,
+	username string,
+	role string,
+	duration time.Duration,
+	opts ...token.PayloadOption,
+) context.Context {
+	opts = append([]token.PayloadOption{token.WithAuth(time.Now(), token.AuthStrengthPassword)}, opts...)
+	token, payload, err := tokenMaker.CreateToken(username, role, uuid.New(), duration, opts...)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	md := metadata.New(map[string]string{
+		consts.AuthorizationHeader: fmt.Sprintf("%s %s", consts.AuthorizationType, token),
+	})
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+func TestUpdateUserRoleApi(t *testing.T) {
+	userName := util.RandomOwner()
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          *pb.UpdateUserRoleRequest
+		setupContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.UpdateUserRoleResponse, err error)
+	}{
+		{
+			name: "OK",
+			body: &pb.UpdateUserRoleRequest{UserName: userName, Role: util.BankerRole},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, bankerName, util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.AuditTxParams, _ ...db.TxOption) (db.AuditTxResult, error) {
+						require.Equal(t, bankerName, arg.Actor)
+						require.Equal(t, audit.UpdateUserRole, arg.Action)
+						require.Equal(t, userName, arg.Target)
+						return db.AuditTxResult{}, arg.Execute(store)
+					})
+				store.EXPECT().
+					UpdateUserRole(gomock.Any(), gomock.Eq(db.UpdateUserRoleParams{Username: userName, Role: util.BankerRole})).
+					Times(1).
+					Return(db.User{Username: userName, Role: util.BankerRole}, nil)
+				store.EXPECT().
+					RevokeSessionsByUserName(gomock.Any(), gomock.Eq(userName)).
+					Times(1).
+					Return([]uuid.UUID{uuid.New()}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserRoleResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, userName, res.GetUser().GetUserName())
+				require.Equal(t, util.BankerRole, res.GetUser().GetRole())
+			},
+		},
+		{
+			name: "OwnRole",
+			body: &pb.UpdateUserRoleRequest{UserName: bankerName, Role: util.DepositorRole},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, bankerName, util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserRoleResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidRole",
+			body: &pb.UpdateUserRoleRequest{UserName: userName, Role: "admin"},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, bankerName, util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserRoleResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "StepUpRequired",
+			body: &pb.UpdateUserRoleRequest{UserName: userName, Role: util.BankerRole},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, bankerName, util.BankerRole, time.Minute,
+					token.WithAuth(time.Now().Add(-time.Hour), token.AuthStrengthPassword))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserRoleResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "NotBanker",
+			body: &pb.UpdateUserRoleRequest{UserName: userName, Role: util.BankerRole},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, userName, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserRoleResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.UpdateUserRoleRequest{UserName: userName, Role: util.BankerRole},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserRoleResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			adminHandler := NewAdminHandler(server)
+
+			ctx := tc.setupContext(t, server.TokenMaker)
+			res, err := adminHandler.UpdateUserRole(ctx, tc.body)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestListAuditLogsApi(t *testing.T) {
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          *pb.ListAuditLogsRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ListAuditLogsResponse, err error)
+	}{
+		{
+			name: "OK",
+			body: &pb.ListAuditLogsRequest{Target: &[]string{"some_user"}[0], Page: 1, Size: 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ListAuditLogsParams) ([]db.AuditLog, error) {
+						require.False(t, arg.Actor.Valid)
+						require.Equal(t, "some_user", arg.Target.String)
+						return []db.AuditLog{{ID: 1, Actor: bankerName, Metadata: []byte(`{}`)}}, nil
+					})
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditLog{}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListAuditLogsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetAuditLogs(), 1)
+				require.Equal(t, "{}", res.GetAuditLogs()[0].GetMetadata())
+			},
+		},
+		{
+			name: "InvalidPage",
+			body: &pb.ListAuditLogsRequest{Page: 0, Size: 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListAuditLogsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "AuditLogFailed",
+			body: &pb.ListAuditLogsRequest{Page: 1, Size: 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.AuditLog{}, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditLog{}, fmt.Errorf("connection refused"))
+			},
+			checkResponse: func(t *testing.T, res *pb.ListAuditLogsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			adminHandler := NewAdminHandler(server)
+
+			ctx := addAuthorizationMetadata(context.Background(), t, server.TokenMaker, bankerName, util.BankerRole, time.Minute)
+			res, err := adminHandler.ListAuditLogs(ctx, tc.body)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestListUserSessionsApi(t *testing.T) {
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	session := db.Session{
+		ID:         uuid.New(),
+		Username:   "alice",
+		UserAgent:  "Mozilla/5.0",
+		ClientIp:   "203.0.113.7",
+		ExpiresAt:  time.Now().Add(time.Hour),
+		LastSeenAt: time.Now(),
+		CreatedAt:  time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		body          *pb.ListUserSessionsRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ListUserSessionsResponse, err error)
+	}{
+		{
+			name: "OK",
+			role: util.BankerRole,
+			body: &pb.ListUserSessionsRequest{UserName: session.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(session.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: session.Username}, nil)
+				store.EXPECT().
+					ListSessionsByUserName(gomock.Any(), gomock.Eq(session.Username)).
+					Times(1).
+					Return([]db.Session{session}, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, bankerName, arg.Actor)
+						require.Equal(t, audit.ListUserSessions, arg.Action)
+						require.Equal(t, session.Username, arg.Target)
+						return db.AuditLog{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.ListUserSessionsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetSessions(), 1)
+				require.Equal(t, session.ID.String(), res.GetSessions()[0].GetId())
+				require.Equal(t, session.ClientIp, res.GetSessions()[0].GetClientIp())
+			},
+		},
+		{
+			name: "UserNotFound",
+			role: util.BankerRole,
+			body: &pb.ListUserSessionsRequest{UserName: session.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(session.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					ListSessionsByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListUserSessionsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NotBanker",
+			role: util.DepositorRole,
+			body: &pb.ListUserSessionsRequest{UserName: session.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListSessionsByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListUserSessionsResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			adminHandler := NewAdminHandler(server)
+
+			ctx := addAuthorizationMetadata(context.Background(), t, server.TokenMaker, bankerName, tc.role, time.Minute)
+			res, err := adminHandler.ListUserSessions(ctx, tc.body)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestUpdateUserKycApi(t *testing.T) {
+	userName := util.RandomOwner()
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          *pb.UpdateUserKycRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.UpdateUserKycResponse, err error)
+	}{
+		{
+			name: "OK",
+			body: &pb.UpdateUserKycRequest{UserName: userName, Status: kyc.Verified, Note: "documents match"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(userName)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: userName, KycStatus: kyc.Pending}, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.AuditTxParams, _ ...db.TxOption) (db.AuditTxResult, error) {
+						require.Equal(t, bankerName, arg.Actor)
+						require.Equal(t, audit.UpdateUserKyc, arg.Action)
+						require.Equal(t, userName, arg.Target)
+						return db.AuditTxResult{}, arg.Execute(store)
+					})
+				store.EXPECT().
+					UpdateUserKycStatus(gomock.Any(), gomock.Eq(db.UpdateUserKycStatusParams{
+						Username:   userName,
+						FromStatus: kyc.Pending,
+						ToStatus:   kyc.Verified,
+					})).
+					Times(1).
+					Return(db.User{Username: userName, KycStatus: kyc.Verified}, nil)
+				store.EXPECT().
+					CreateKycReview(gomock.Any(), gomock.Eq(db.CreateKycReviewParams{
+						Username:   userName,
+						FromStatus: kyc.Pending,
+						ToStatus:   kyc.Verified,
+						Reviewer:   bankerName,
+						Note:       "documents match",
+					})).
+					Times(1).
+					Return(db.KycReview{ID: 1, Username: userName, FromStatus: kyc.Pending, ToStatus: kyc.Verified, Reviewer: bankerName}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserKycResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, kyc.Verified, res.GetUser().GetKycStatus())
+				require.Equal(t, bankerName, res.GetReview().GetReviewer())
+			},
+		},
+		{
+			name: "OwnKyc",
+			body: &pb.UpdateUserKycRequest{UserName: bankerName, Status: kyc.Verified, Note: "looks fine"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserKycResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidStatus",
+			body: &pb.UpdateUserKycRequest{UserName: userName, Status: "approved", Note: "documents match"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserKycResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidTransition",
+			body: &pb.UpdateUserKycRequest{UserName: userName, Status: kyc.Verified, Note: "documents match"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(userName)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: userName, KycStatus: kyc.Unverified}, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserKycResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: &pb.UpdateUserKycRequest{UserName: userName, Status: kyc.Verified, Note: "documents match"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(userName)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserKycResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "StatusChanged",
+			body: &pb.UpdateUserKycRequest{UserName: userName, Status: kyc.Verified, Note: "documents match"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(userName)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: userName, KycStatus: kyc.Pending}, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserKycResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Aborted, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			adminHandler := NewAdminHandler(server)
+
+			ctx := addAuthorizationMetadata(context.Background(), t, server.TokenMaker, bankerName, util.BankerRole, time.Minute)
+			res, err := adminHandler.UpdateUserKyc(ctx, tc.body)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestReviewKycDocumentApi(t *testing.T) {
+	userName := util.RandomOwner()
+	document := db.KycDocument{
+		ID:       3,
+		Username: userName,
+		Kind:     kyc.DocumentNationalID,
+		ReviewID: pgtype.Int8{Int64: 1, Valid: true},
+		Status:   kyc.DocumentPending,
+	}
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          *pb.ReviewKycDocumentRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ReviewKycDocumentResponse, err error)
+	}{
+		{
+			name: "OK",
+			body: &pb.ReviewKycDocumentRequest{Id: document.ID, Status: kyc.DocumentApproved},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(document, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.AuditTxParams, _ ...db.TxOption) (db.AuditTxResult, error) {
+						require.Equal(t, bankerName, arg.Actor)
+						require.Equal(t, audit.ReviewKycDocument, arg.Action)
+						require.Equal(t, userName, arg.Target)
+						return db.AuditTxResult{}, arg.Execute(store)
+					})
+				store.EXPECT().
+					ReviewKycDocument(gomock.Any(), gomock.Eq(db.ReviewKycDocumentParams{
+						ID:         document.ID,
+						FromStatus: kyc.DocumentPending,
+						ToStatus:   kyc.DocumentApproved,
+						Reviewer:   bankerName,
+					})).
+					Times(1).
+					Return(db.KycDocument{ID: document.ID, Username: userName, Status: kyc.DocumentApproved, Reviewer: bankerName}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKycDocumentResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, kyc.DocumentApproved, res.GetDocument().GetStatus())
+				require.Equal(t, bankerName, res.GetDocument().GetReviewer())
+			},
+		},
+		{
+			name: "MissingNote",
+			body: &pb.ReviewKycDocumentRequest{Id: document.ID, Status: kyc.DocumentRejected},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKycDocumentResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NotFound",
+			body: &pb.ReviewKycDocumentRequest{Id: document.ID, Status: kyc.DocumentApproved},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(db.KycDocument{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKycDocumentResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "AlreadyReviewed",
+			body: &pb.ReviewKycDocumentRequest{Id: document.ID, Status: kyc.DocumentApproved},
+			buildStubs: func(store *mockdb.MockStore) {
+				reviewed := document
+				reviewed.Status = kyc.DocumentRejected
+
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(reviewed, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKycDocumentResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "ReviewedConcurrently",
+			body: &pb.ReviewKycDocumentRequest{Id: document.ID, Status: kyc.DocumentApproved},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKycDocument(gomock.Any(), gomock.Eq(document.ID)).
+					Times(1).
+					Return(document, nil)
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKycDocumentResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Aborted, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			adminHandler := NewAdminHandler(server)
+
+			ctx := addAuthorizationMetadata(context.Background(), t, server.TokenMaker, bankerName, util.BankerRole, time.Minute)
+			res, err := adminHandler.ReviewKycDocument(ctx, tc.body)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package admin
+
+import (
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pb"
+	"github.com/ChokeGuy/simple-bank/util"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.AdminUser {
+	return &pb.AdminUser{
+		UserName:        user.Username,
+		Role:            user.Role,
+		FullName:        user.FullName,
+		Email:           user.Email,
+		IsEmailVerified: user.IsEmailVerified,
+		CreatedAt:       timestamppb.New(user.CreatedAt),
+	}
+}
+
+// convertProfile converts a user with its profile for the KYC review of bankers
+func convertProfile(user db.User) *pb.User {
+	profile := &pb.User{
+		UserName:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+		Phone:             user.Phone,
+		Address:           user.Address,
+		Nationality:       user.Nationality,
+		KycStatus:         user.KycStatus,
+		IsPhoneVerified:   user.IsPhoneVerified,
+	}
+
+	if user.DateOfBirth.Valid {
+		profile.DateOfBirth = user.DateOfBirth.Time.Format(time.DateOnly)
+	}
+
+	return profile
+}
+
+func convertProfileRow(user db.GetUserByUserNameRow) *pb.User {
+	return convertProfile(db.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+		DateOfBirth:       user.DateOfBirth,
+		Phone:             user.Phone,
+		Address:           user.Address,
+		Nationality:       user.Nationality,
+		KycStatus:         user.KycStatus,
+		IsPhoneVerified:   user.IsPhoneVerified,
+	})
+}
+
+func convertKycReview(review db.KycReview) *pb.KycReview {
+	return &pb.KycReview{
+		Id:         review.ID,
+		FromStatus: review.FromStatus,
+		ToStatus:   review.ToStatus,
+		Reviewer:   review.Reviewer,
+		Note:       review.Note,
+		CreatedAt:  timestamppb.New(review.CreatedAt),
+	}
+}
+
+func convertKycReviews(rows []db.KycReview) []*pb.KycReview {
+	var reviews []*pb.KycReview
+	for _, v := range rows {
+		reviews = append(reviews, convertKycReview(v))
+	}
+
+	return reviews
+}
+
+func convertSearchUsers(rows []db.SearchUsersRow) []*pb.AdminUser {
+	var users []*pb.AdminUser
+	for _, v := range rows {
+		users = append(users, &pb.AdminUser{
+			UserName:        v.Username,
+			Role:            v.Role,
+			FullName:        v.FullName,
+			Email:           v.Email,
+			IsEmailVerified: v.IsEmailVerified,
+			CreatedAt:       timestamppb.New(v.CreatedAt),
+		})
+	}
+
+	return users
+}
+
+func convertAccounts(rows []db.Account) []*pb.Account {
+	var accounts []*pb.Account
+	for _, v := range rows {
+		accounts = append(accounts, &pb.Account{
+			AccountNumber:    v.AccountNumber,
+			Owner:            v.Owner,
+			Balance:          v.Balance,
+			FormattedBalance: util.FormatAmount(v.Balance, v.Currency),
+			Currency:         v.Currency,
+			CreatedAt:        timestamppb.New(v.CreatedAt),
+		})
+	}
+
+	return accounts
+}
+
+func convertTransfers(rows []db.ListTransfersByOwnerRow) []*pb.AdminTransfer {
+	var transfers []*pb.AdminTransfer
+	for _, v := range rows {
+		transfers = append(transfers, &pb.AdminTransfer{
+			Id:                v.PublicID.String(),
+			FromAccountNumber: v.FromAccountNumber,
+			ToAccountNumber:   v.ToAccountNumber,
+			Amount:            v.Amount,
+			FormattedAmount:   util.FormatAmount(v.Amount, v.Currency),
+			Currency:          v.Currency,
+			CreatedAt:         timestamppb.New(v.CreatedAt),
+		})
+	}
+
+	return transfers
+}
+
+func convertSessions(rows []db.Session) []*pb.AdminSession {
+	var sessions []*pb.AdminSession
+	for _, v := range rows {
+		sessions = append(sessions, &pb.AdminSession{
+			Id:          v.ID.String(),
+			DeviceLabel: v.DeviceLabel,
+			UserAgent:   v.UserAgent,
+			ClientIp:    v.ClientIp,
+			IsBlocked:   v.IsBlocked,
+			ExpiresAt:   timestamppb.New(v.ExpiresAt),
+			LastSeenAt:  timestamppb.New(v.LastSeenAt),
+			CreatedAt:   timestamppb.New(v.CreatedAt),
+		})
+	}
+
+	return sessions
+}
+
+func convertAuditLogs(rows []db.AuditLog) []*pb.AuditLog {
+	var logs []*pb.AuditLog
+	for _, v := range rows {
+		logs = append(logs, &pb.AuditLog{
+			Id:        v.ID,
+			Actor:     v.Actor,
+			Action:    v.Action,
+			Target:    v.Target,
+			Metadata:  string(v.Metadata),
+			ClientIp:  v.ClientIp,
+			CreatedAt: timestamppb.New(v.CreatedAt),
+		})
+	}
+
+	return logs
+}
+
+func convertKycDocument(document db.KycDocument) *pb.KycDocument {
+	pbDocument := &pb.KycDocument{
+		Id:          document.ID,
+		UserName:    document.Username,
+		Kind:        document.Kind,
+		FileName:    document.FileName,
+		ContentType: document.ContentType,
+		Size:        document.Size,
+		Status:      document.Status,
+		Reviewer:    document.Reviewer,
+		Note:        document.Note,
+		CreatedAt:   timestamppb.New(document.CreatedAt),
+	}
+
+	if document.ReviewID.Valid {
+		pbDocument.ReviewId = &document.ReviewID.Int64
+	}
+
+	if document.ReviewedAt.Valid {
+		pbDocument.ReviewedAt = timestamppb.New(document.ReviewedAt.Time)
+	}
+
+	return pbDocument
+}
+
+func convertKycDocuments(rows []db.KycDocument) []*pb.KycDocument {
+	documents := make([]*pb.KycDocument, 0, len(rows))
+	for _, v := range rows {
+		documents = append(documents, convertKycDocument(v))
+	}
+
+	return documents
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	gAccount "github.com/ChokeGuy/simple-bank/grpc-api/account"
+	gAdmin "github.com/ChokeGuy/simple-bank/grpc-api/admin"
 	gUser "github.com/ChokeGuy/simple-bank/grpc-api/user"
 	"github.com/ChokeGuy/simple-bank/pb"
 	sv "github.com/ChokeGuy/simple-bank/server/grpc"
@@ -30,6 +31,34 @@ func (h *ServiceHandler) LoginUser(ctx context.Context, req *pb.LoginUserRequest
 	return h.UserHandler.LoginUser(ctx, req)
 }
 
+func (h *ServiceHandler) VerifyLoginMfa(ctx context.Context, req *pb.VerifyLoginMfaRequest) (*pb.LoginUserResponse, error) {
+	return h.UserHandler.VerifyLoginMfa(ctx, req)
+}
+
+func (h *ServiceHandler) BeginOidcLogin(ctx context.Context, req *pb.BeginOidcLoginRequest) (*pb.BeginOidcLoginResponse, error) {
+	return h.UserHandler.BeginOidcLogin(ctx, req)
+}
+
+func (h *ServiceHandler) OidcCallback(ctx context.Context, req *pb.OidcCallbackRequest) (*pb.LoginUserResponse, error) {
+	return h.UserHandler.OidcCallback(ctx, req)
+}
+
+func (h *ServiceHandler) SendLoginSmsCode(ctx context.Context, req *pb.SendLoginSmsCodeRequest) (*pb.SendLoginSmsCodeResponse, error) {
+	return h.UserHandler.SendLoginSmsCode(ctx, req)
+}
+
+func (h *ServiceHandler) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	return h.UserHandler.RefreshToken(ctx, req)
+}
+
+func (h *ServiceHandler) LogoutUser(ctx context.Context, req *pb.LogoutUserRequest) (*pb.LogoutUserResponse, error) {
+	return h.UserHandler.LogoutUser(ctx, req)
+}
+
+func (h *ServiceHandler) StepUp(ctx context.Context, req *pb.StepUpRequest) (*pb.StepUpResponse, error) {
+	return h.UserHandler.StepUp(ctx, req)
+}
+
 func (h *ServiceHandler) GetListAccount(ctx context.Context, req *pb.ListAccountRequest) (*pb.ListAccountResponse, error) {
 	return h.AccountHandler.GetListAccount(ctx, req)
 }
@@ -38,6 +67,144 @@ func (h *ServiceHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserReque
 	return h.UserHandler.UpdateUser(ctx, req)
 }
 
+func (h *ServiceHandler) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.GetProfileResponse, error) {
+	return h.UserHandler.GetProfile(ctx, req)
+}
+
+func (h *ServiceHandler) SubmitKyc(ctx context.Context, req *pb.SubmitKycRequest) (*pb.SubmitKycResponse, error) {
+	return h.UserHandler.SubmitKyc(ctx, req)
+}
+
+func (h *ServiceHandler) UploadKycDocument(ctx context.Context, req *pb.UploadKycDocumentRequest) (*pb.UploadKycDocumentResponse, error) {
+	return h.UserHandler.UploadKycDocument(ctx, req)
+}
+
+func (h *ServiceHandler) ListKycDocuments(ctx context.Context, req *pb.ListKycDocumentsRequest) (*pb.ListKycDocumentsResponse, error) {
+	return h.UserHandler.ListKycDocuments(ctx, req)
+}
+
+func (h *ServiceHandler) ListLoginEvents(ctx context.Context, req *pb.ListLoginEventsRequest) (*pb.ListLoginEventsResponse, error) {
+	return h.UserHandler.ListLoginEvents(ctx, req)
+}
+
+func (h *ServiceHandler) GetDenyLogin(ctx context.Context, req *pb.GetDenyLoginRequest) (*pb.GetDenyLoginResponse, error) {
+	return h.UserHandler.GetDenyLogin(ctx, req)
+}
+
+func (h *ServiceHandler) DenyLogin(ctx context.Context, req *pb.DenyLoginRequest) (*pb.DenyLoginResponse, error) {
+	return h.UserHandler.DenyLogin(ctx, req)
+}
+
 func (h *ServiceHandler) VerifyUserEmail(ctx context.Context, req *pb.VerifyUserEmailRequest) (*pb.VerifyUserEmailResponse, error) {
 	return h.UserHandler.VerifyUserEmail(ctx, req)
 }
+
+func (h *ServiceHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	return h.UserHandler.ChangePassword(ctx, req)
+}
+
+func (h *ServiceHandler) ResendVerifyEmail(ctx context.Context, req *pb.ResendVerifyEmailRequest) (*pb.ResendVerifyEmailResponse, error) {
+	return h.UserHandler.ResendVerifyEmail(ctx, req)
+}
+
+func (h *ServiceHandler) SendPhoneCode(ctx context.Context, req *pb.SendPhoneCodeRequest) (*pb.SendPhoneCodeResponse, error) {
+	return h.UserHandler.SendPhoneCode(ctx, req)
+}
+
+func (h *ServiceHandler) VerifyPhone(ctx context.Context, req *pb.VerifyPhoneRequest) (*pb.VerifyPhoneResponse, error) {
+	return h.UserHandler.VerifyPhone(ctx, req)
+}
+
+func (h *ServiceHandler) GetUserLockout(ctx context.Context, req *pb.GetUserLockoutRequest) (*pb.GetUserLockoutResponse, error) {
+	return h.UserHandler.GetUserLockout(ctx, req)
+}
+
+func (h *ServiceHandler) UnlockUser(ctx context.Context, req *pb.UnlockUserRequest) (*pb.UnlockUserResponse, error) {
+	return h.UserHandler.UnlockUser(ctx, req)
+}
+
+func (h *ServiceHandler) ForgotPassword(ctx context.Context, req *pb.ForgotPasswordRequest) (*pb.ForgotPasswordResponse, error) {
+	return h.UserHandler.ForgotPassword(ctx, req)
+}
+
+func (h *ServiceHandler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	return h.UserHandler.ResetPassword(ctx, req)
+}
+
+func (h *ServiceHandler) EnrollTotp(ctx context.Context, req *pb.EnrollTotpRequest) (*pb.EnrollTotpResponse, error) {
+	return h.UserHandler.EnrollTotp(ctx, req)
+}
+
+func (h *ServiceHandler) ConfirmTotp(ctx context.Context, req *pb.ConfirmTotpRequest) (*pb.ConfirmTotpResponse, error) {
+	return h.UserHandler.ConfirmTotp(ctx, req)
+}
+
+func (h *ServiceHandler) DisableTotp(ctx context.Context, req *pb.DisableTotpRequest) (*pb.DisableTotpResponse, error) {
+	return h.UserHandler.DisableTotp(ctx, req)
+}
+
+// AdminServiceHandler serves the back-office service of bankers
+type AdminServiceHandler struct {
+	pb.UnimplementedSimpleBankAdminServer
+	*gAdmin.AdminHandler
+}
+
+func NewAdminServiceHandler(server *sv.Server) *AdminServiceHandler {
+	return &AdminServiceHandler{AdminHandler: gAdmin.NewAdminHandler(server)}
+}
+
+func (h *AdminServiceHandler) SearchUsers(ctx context.Context, req *pb.SearchUsersRequest) (*pb.SearchUsersResponse, error) {
+	return h.AdminHandler.SearchUsers(ctx, req)
+}
+
+func (h *AdminServiceHandler) ListUserAccounts(ctx context.Context, req *pb.ListUserAccountsRequest) (*pb.ListUserAccountsResponse, error) {
+	return h.AdminHandler.ListUserAccounts(ctx, req)
+}
+
+func (h *AdminServiceHandler) ListUserTransfers(ctx context.Context, req *pb.ListUserTransfersRequest) (*pb.ListUserTransfersResponse, error) {
+	return h.AdminHandler.ListUserTransfers(ctx, req)
+}
+
+func (h *AdminServiceHandler) UpdateUserRole(ctx context.Context, req *pb.UpdateUserRoleRequest) (*pb.UpdateUserRoleResponse, error) {
+	return h.AdminHandler.UpdateUserRole(ctx, req)
+}
+
+func (h *AdminServiceHandler) ForceVerifyEmail(ctx context.Context, req *pb.ForceVerifyEmailRequest) (*pb.ForceVerifyEmailResponse, error) {
+	return h.AdminHandler.ForceVerifyEmail(ctx, req)
+}
+
+func (h *AdminServiceHandler) GetUserKyc(ctx context.Context, req *pb.GetUserKycRequest) (*pb.GetUserKycResponse, error) {
+	return h.AdminHandler.GetUserKyc(ctx, req)
+}
+
+func (h *AdminServiceHandler) UpdateUserKyc(ctx context.Context, req *pb.UpdateUserKycRequest) (*pb.UpdateUserKycResponse, error) {
+	return h.AdminHandler.UpdateUserKyc(ctx, req)
+}
+
+func (h *AdminServiceHandler) ListUserKycDocuments(ctx context.Context, req *pb.ListUserKycDocumentsRequest) (*pb.ListUserKycDocumentsResponse, error) {
+	return h.AdminHandler.ListUserKycDocuments(ctx, req)
+}
+
+func (h *AdminServiceHandler) GetKycDocument(ctx context.Context, req *pb.GetKycDocumentRequest) (*pb.GetKycDocumentResponse, error) {
+	return h.AdminHandler.GetKycDocument(ctx, req)
+}
+
+func (h *AdminServiceHandler) ReviewKycDocument(ctx context.Context, req *pb.ReviewKycDocumentRequest) (*pb.ReviewKycDocumentResponse, error) {
+	return h.AdminHandler.ReviewKycDocument(ctx, req)
+}
+
+func (h *AdminServiceHandler) ListUserSessions(ctx context.Context, req *pb.ListUserSessionsRequest) (*pb.ListUserSessionsResponse, error) {
+	return h.AdminHandler.ListUserSessions(ctx, req)
+}
+
+func (h *AdminServiceHandler) BlockSession(ctx context.Context, req *pb.BlockSessionRequest) (*pb.BlockSessionResponse, error) {
+	return h.AdminHandler.BlockSession(ctx, req)
+}
+
+func (h *AdminServiceHandler) UnblockSession(ctx context.Context, req *pb.UnblockSessionRequest) (*pb.UnblockSessionResponse, error) {
+	return h.AdminHandler.UnblockSession(ctx, req)
+}
+
+func (h *AdminServiceHandler) ListAuditLogs(ctx context.Context, req *pb.ListAuditLogsRequest) (*pb.ListAuditLogsResponse, error) {
+	return h.AdminHandler.ListAuditLogs(ctx, req)
+}
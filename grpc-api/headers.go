@@ -0,0 +1,16 @@
+package grpcapi
+
+import (
+	gUser "github.com/ChokeGuy/simple-bank/grpc-api/user"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// OutgoingHeaderMatcher lets the gateway set cookies of the handlers, other metadata keeps the
+// Grpc-Metadata- prefix of the default matcher
+func OutgoingHeaderMatcher(key string) (string, bool) {
+	if key == gUser.SetCookieHeader {
+		return "Set-Cookie", true
+	}
+
+	return runtime.MetadataHeaderPrefix + key, true
+}
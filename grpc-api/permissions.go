@@ -0,0 +1,49 @@
+package grpcapi
+
+import (
+	"github.com/ChokeGuy/simple-bank/pb"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+)
+
+// MethodPermissions is the permission each protected RPC requires, RPCs missing here are public
+var MethodPermissions = map[string]rbac.Permission{
+	pb.SimpleBank_GetProfile_FullMethodName:        rbac.UserRead,
+	pb.SimpleBank_UpdateUser_FullMethodName:        rbac.UserUpdate,
+	pb.SimpleBank_SubmitKyc_FullMethodName:         rbac.UserUpdate,
+	pb.SimpleBank_UploadKycDocument_FullMethodName: rbac.UserUpdate,
+	pb.SimpleBank_ListKycDocuments_FullMethodName:  rbac.UserRead,
+	pb.SimpleBank_ChangePassword_FullMethodName:    rbac.UserUpdate,
+	pb.SimpleBank_ResendVerifyEmail_FullMethodName: rbac.UserUpdate,
+	pb.SimpleBank_SendPhoneCode_FullMethodName:     rbac.UserUpdate,
+	pb.SimpleBank_VerifyPhone_FullMethodName:       rbac.UserUpdate,
+	pb.SimpleBank_LogoutUser_FullMethodName:        rbac.SessionManage,
+	pb.SimpleBank_StepUp_FullMethodName:            rbac.SessionManage,
+	pb.SimpleBank_ListLoginEvents_FullMethodName:   rbac.SessionManage,
+	pb.SimpleBank_EnrollTotp_FullMethodName:        rbac.MfaManage,
+	pb.SimpleBank_ConfirmTotp_FullMethodName:       rbac.MfaManage,
+	pb.SimpleBank_DisableTotp_FullMethodName:       rbac.MfaManage,
+	pb.SimpleBank_GetUserLockout_FullMethodName:    rbac.UserLockoutRead,
+	pb.SimpleBank_UnlockUser_FullMethodName:        rbac.UserLockoutReset,
+	pb.SimpleBank_GetListAccount_FullMethodName:    rbac.AccountRead,
+
+	pb.SimpleBankAdmin_SearchUsers_FullMethodName:          rbac.UserReadAny,
+	pb.SimpleBankAdmin_ListUserAccounts_FullMethodName:     rbac.AccountReadAny,
+	pb.SimpleBankAdmin_ListUserTransfers_FullMethodName:    rbac.TransferReadAny,
+	pb.SimpleBankAdmin_UpdateUserRole_FullMethodName:       rbac.UserRoleUpdate,
+	pb.SimpleBankAdmin_ForceVerifyEmail_FullMethodName:     rbac.UserUpdateAny,
+	pb.SimpleBankAdmin_GetUserKyc_FullMethodName:           rbac.KycReview,
+	pb.SimpleBankAdmin_UpdateUserKyc_FullMethodName:        rbac.KycReview,
+	pb.SimpleBankAdmin_ListUserKycDocuments_FullMethodName: rbac.KycReview,
+	pb.SimpleBankAdmin_GetKycDocument_FullMethodName:       rbac.KycReview,
+	pb.SimpleBankAdmin_ReviewKycDocument_FullMethodName:    rbac.KycReview,
+	pb.SimpleBankAdmin_ListUserSessions_FullMethodName:     rbac.SessionBlock,
+	pb.SimpleBankAdmin_BlockSession_FullMethodName:         rbac.SessionBlock,
+	pb.SimpleBankAdmin_UnblockSession_FullMethodName:       rbac.SessionBlock,
+	pb.SimpleBankAdmin_ListAuditLogs_FullMethodName:        rbac.AuditLogRead,
+}
+
+// MethodsRequiringStepUp need a recent step-up on top of their permission, operations that are
+// sensitive only for some requests, like changing the email in UpdateUser, are checked by their handler
+var MethodsRequiringStepUp = map[string]bool{
+	pb.SimpleBankAdmin_UpdateUserRole_FullMethodName: true,
+}
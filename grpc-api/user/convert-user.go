@@ -1,17 +1,104 @@
 package user
 
 import (
+	"time"
+
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	"github.com/ChokeGuy/simple-bank/pb"
+	"github.com/google/uuid"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// convertUser converts a user with its profile, it must not be used for views of other users
 func convertUser(user db.User) *pb.User {
-	return &pb.User{
+	pbUser := &pb.User{
 		UserName:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 		CreatedAt:         timestamppb.New(user.CreatedAt),
+		Phone:             user.Phone,
+		Address:           user.Address,
+		Nationality:       user.Nationality,
+		KycStatus:         user.KycStatus,
+		IsPhoneVerified:   user.IsPhoneVerified,
+	}
+
+	if user.DateOfBirth.Valid {
+		pbUser.DateOfBirth = user.DateOfBirth.Time.Format(time.DateOnly)
+	}
+
+	return pbUser
+}
+
+func convertUserRow(user db.GetUserByUserNameRow) *pb.User {
+	return convertUser(db.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt,
+		CreatedAt:         user.CreatedAt,
+		DateOfBirth:       user.DateOfBirth,
+		Phone:             user.Phone,
+		Address:           user.Address,
+		Nationality:       user.Nationality,
+		KycStatus:         user.KycStatus,
+		IsPhoneVerified:   user.IsPhoneVerified,
+	})
+}
+
+func convertLoginEvent(event db.LoginEvent) *pb.LoginEvent {
+	loginEvent := &pb.LoginEvent{
+		Id:            event.ID,
+		Success:       event.Success,
+		FailureReason: event.FailureReason,
+		UserAgent:     event.UserAgent,
+		ClientIp:      event.ClientIp,
+		NewDevice:     event.NewDevice,
+		CreatedAt:     timestamppb.New(event.CreatedAt),
+	}
+
+	if event.SessionID.Valid {
+		loginEvent.SessionId = uuid.UUID(event.SessionID.Bytes).String()
+	}
+
+	if event.DeniedAt.Valid {
+		loginEvent.DeniedAt = timestamppb.New(event.DeniedAt.Time)
+	}
+
+	return loginEvent
+}
+
+func convertKycDocument(document db.KycDocument) *pb.KycDocument {
+	pbDocument := &pb.KycDocument{
+		Id:          document.ID,
+		UserName:    document.Username,
+		Kind:        document.Kind,
+		FileName:    document.FileName,
+		ContentType: document.ContentType,
+		Size:        document.Size,
+		Status:      document.Status,
+		Reviewer:    document.Reviewer,
+		Note:        document.Note,
+		CreatedAt:   timestamppb.New(document.CreatedAt),
+	}
+
+	if document.ReviewID.Valid {
+		pbDocument.ReviewId = &document.ReviewID.Int64
 	}
+
+	if document.ReviewedAt.Valid {
+		pbDocument.ReviewedAt = timestamppb.New(document.ReviewedAt.Time)
+	}
+
+	return pbDocument
+}
+
+func convertKycDocuments(rows []db.KycDocument) []*pb.KycDocument {
+	documents := make([]*pb.KycDocument, 0, len(rows))
+	for _, v := range rows {
+		documents = append(documents, convertKycDocument(v))
+	}
+
+	return documents
 }
@@ -2,15 +2,21 @@ package user
 
 import (
 	"context"
+	"net/http"
 
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/peer"
 )
 
 const (
 	grpcUserAgentHeader = "grpcgateway-user-agent"
 	userAgentHeader     = "user-agent"
-	xForwardedForHeader = "x-forwarded-for"
+	grpcCookieHeader    = "grpcgateway-cookie"
+	cookieHeader        = "cookie"
+
+	// SetCookieHeader is the metadata the gateway has to pass on as a Set-Cookie header
+	SetCookieHeader = "set-cookie"
 )
 
 type Metadata struct {
@@ -30,14 +36,39 @@ func (u *UserHandler) extractMetadata(ctx context.Context) *Metadata {
 			_metadata.UserClient = userAgent[0]
 		}
 
-		if clientIP := md.Get(xForwardedForHeader); len(clientIP) > 0 {
-			_metadata.ClientIP = clientIP[0]
-		}
 	}
 
-	if p, ok := peer.FromContext(ctx); ok {
-		_metadata.ClientIP = p.Addr.String()
-	}
+	_metadata.ClientIP = auth.GrpcClientIP(ctx)
 
 	return _metadata
 }
+
+// clientAddress returns the IP of the client without the port of its connection
+func (u *UserHandler) clientAddress(ctx context.Context) string {
+	return auth.GrpcClientIP(ctx)
+}
+
+// cookie returns the value of the cookie name of the browser behind the gateway, or of a gRPC
+// client sending cookie metadata. It is empty when the cookie was not sent.
+func (u *UserHandler) cookie(ctx context.Context, name string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+
+	if !ok {
+		return ""
+	}
+
+	header := http.Header{"Cookie": append(md.Get(grpcCookieHeader), md.Get(cookieHeader)...)}
+
+	cookie, err := (&http.Request{Header: header}).Cookie(name)
+
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
+// setCookie sends cookie with the header of the response
+func (u *UserHandler) setCookie(ctx context.Context, cookie *http.Cookie) error {
+	return grpc.SetHeader(ctx, metadata.Pairs(SetCookieHeader, cookie.String()))
+}
@@ -4,27 +4,38 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/ChokeGuy/simple-bank/consts"
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	"github.com/ChokeGuy/simple-bank/pb"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/encryption"
 	myErr "github.com/ChokeGuy/simple-bank/pkg/errors"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/ChokeGuy/simple-bank/pkg/loginhistory"
+	"github.com/ChokeGuy/simple-bank/pkg/mfa"
+	"github.com/ChokeGuy/simple-bank/pkg/middlewares/auth"
+	"github.com/ChokeGuy/simple-bank/pkg/oidc"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/throttle"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/pkg/totp"
 	sv "github.com/ChokeGuy/simple-bank/server/grpc"
 	"github.com/ChokeGuy/simple-bank/util"
 	pw "github.com/ChokeGuy/simple-bank/util/password"
 	"github.com/ChokeGuy/simple-bank/validations"
 	"github.com/ChokeGuy/simple-bank/worker"
 	"github.com/google/uuid"
-	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -65,60 +76,41 @@ func RandomVerifyEmail(t *testing.T, user db.User) db.VerifyEmail {
 	}
 }
 
-func (h *UserHandler) authorizeUser(ctx context.Context, accessibleRoles []string) (*token.Payload, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-
-	if !ok {
-		return nil, fmt.Errorf("metadata not provided")
-	}
-
-	values := md.Get(consts.AuthorizationHeader)
-	if len(values) == 0 {
-		return nil, fmt.Errorf("missing authorization header")
-	}
-	authHeader := values[0]
-	fields := strings.Fields(authHeader)
-	if len(fields) != 2 {
-		return nil, fmt.Errorf("invalid authorization header format")
-	}
-
-	authType := strings.ToLower(fields[0])
+// Create random TOTP enrolment, the secret is encrypted with the configured key
+func RandomUserTotp(t *testing.T, userName string, confirmed bool) (db.UserTotp, string) {
+	cfg, err := pkg.LoadConfig("../..")
+	require.NoError(t, err)
 
-	if authType != consts.AuthorizationType {
-		return nil, fmt.Errorf("unsupported authorization type")
-	}
+	cipher, err := encryption.NewAESCipher(cfg.TotpEncryptionKey)
+	require.NoError(t, err)
 
-	accessToken := fields[1]
-	payload, err := h.Server.TokenMaker.VerifyToken(accessToken)
-	if err != nil {
-		return nil, fmt.Errorf("invalid access token")
-	}
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
 
-	if !hasPermission(payload.Role, accessibleRoles) {
-		return nil, fmt.Errorf("permission denied")
-	}
+	ciphertext, err := cipher.Encrypt([]byte(secret))
+	require.NoError(t, err)
 
-	return payload, nil
+	return db.UserTotp{
+		Username:         userName,
+		SecretCiphertext: ciphertext,
+		ConfirmedAt:      pgtype.Timestamptz{Time: time.Now(), Valid: confirmed},
+		CreatedAt:        time.Now(),
+	}, secret
 }
 
-func hasPermission(userRole string, accessibleRoles []string) bool {
-	for _, role := range accessibleRoles {
-		if userRole == role {
-			return true
-		}
-	}
-
-	return false
+// authorizeUser returns the caller once its role is granted permission, errors are already gRPC statuses
+func (h *UserHandler) authorizeUser(ctx context.Context, permission rbac.Permission) (*token.Payload, error) {
+	return auth.AuthorizeGrpc(ctx, h.TokenMaker, h.SessionChecker, h.ApiKeyVerifier, permission)
 }
 
 func (h *UserHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
-	violations := validateCreateUserRequest(req)
+	violations := validateCreateUserRequest(req, h.PasswordPolicy)
 
 	if violations != nil {
 		return nil, myErr.InvalidAgrumentError(violations)
 	}
 
-	hashedPassword, err := pw.HashPassword(req.GetPassword())
+	hashedPassword, err := h.PasswordHasher.Hash(req.GetPassword())
 
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
@@ -131,19 +123,19 @@ func (h *UserHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 			Email:          req.Email,
 			HashedPassword: hashedPassword,
 		},
-		AfterCreate: func(user db.User) error {
+		AfterCreate: func(q db.Querier, user db.User) error {
 			//Send verification email to user
 			taskPayload := &worker.PayloadSendVerifyEmail{
 				UserName: user.Username,
 			}
 
-			opts := []asynq.Option{
-				asynq.MaxRetry(10),
-				asynq.ProcessIn(10 * time.Second),
-				asynq.Queue(worker.QueueDefault),
+			opts := worker.OutboxOptions{
+				MaxRetry:  10,
+				ProcessIn: 10 * time.Second,
+				Queue:     worker.QueueDefault,
 			}
 
-			return h.TaskDistributor.DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...)
+			return worker.EnqueueTaskSendVerifyEmail(ctx, q, taskPayload, opts)
 		},
 	}
 
@@ -170,44 +162,133 @@ func (h *UserHandler) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		return nil, myErr.InvalidAgrumentError(violations)
 	}
 
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: req.GetUserName()}
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: h.clientAddress(ctx)}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, userKey, ipKey)
+	if err != nil {
+		return nil, throttleError(err)
+	}
+	defer attempt.Release(ctx)
+
 	user, err := h.Store.GetUserByUserName(ctx, req.GetUserName())
 
 	if err != nil {
 		if errors.Is(err, db.ErrRecordNotFound) {
+			attempt.Fail()
+
 			return nil, status.Errorf(codes.NotFound, "user not found")
 		}
 
 		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
 	}
 
-	if err := pw.CheckPassword(req.Password, user.HashedPassword); err != nil {
+	if err := h.PasswordHasher.Check(req.Password, user.HashedPassword); err != nil {
+		metadata := h.extractMetadata(ctx)
+		loginhistory.RecordFailure(ctx, h.Store, user.Username, loginhistory.ReasonInvalidPassword, metadata.UserClient, metadata.ClientIP)
+
+		attempt.Fail()
+
 		return nil, status.Errorf(codes.InvalidArgument, "invalid password")
 	}
 
-	if session, _ := h.Store.GetSessionByUserName(ctx, req.GetUserName()); session.ID != uuid.Nil {
-		// Check if session is expired
-		if time.Now().After(session.ExpiresAt) {
-			// Delete expired session
-			err := h.Store.DeleteSession(ctx, session.ID)
-			if err != nil {
-				return nil, status.Errorf(codes.Internal, "failed to delete expired session: %v", err)
-			}
-		} else if session.IsBlocked {
-			// Session exists and is not blocked
-			return nil, status.Errorf(codes.PermissionDenied, "session is blocked")
-		} else {
-			// Session exists and is not blocked
-			return nil, status.Errorf(codes.PermissionDenied, "user session already exists")
+	h.rehashPassword(ctx, user.Username, req.Password, user.HashedPassword)
+
+	mfaEnabled, err := h.MFA.Enabled(ctx, user.Username)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor authentication: %v", err)
+	}
+
+	// With 2FA on the password only buys a challenge, the session is created by VerifyLoginMfa
+	if mfaEnabled {
+		challenge, err := h.MFA.CreateChallenge(ctx, user.Username, req.GetDeviceLabel())
+
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create two-factor authentication challenge: %v", err)
+		}
+
+		response := &pb.LoginUserResponse{
+			MfaRequired:           true,
+			MfaChallengeToken:     challenge.Token,
+			MfaChallengeExpiresAt: timestamppb.New(challenge.ExpiresAt),
+		}
+
+		return response, nil
+	}
+
+	// The address is not reset so a valid login of its own does not buy an attacker new guesses.
+	// With 2FA on the user is reset once the second factor passed, wrong codes count like passwords.
+	if err := h.Limiter.Reset(ctx, userKey); err != nil {
+		return nil, throttleError(err)
+	}
+
+	return h.createLoginSession(ctx, user, req.GetDeviceLabel(), token.AuthStrengthPassword)
+}
+
+// rehashPassword upgrades a hash made with another algorithm or weaker parameters. The login
+// does not fail when the new hash cannot be stored, the old one keeps verifying until next time.
+func (h *UserHandler) rehashPassword(ctx context.Context, username string, password string, hashedPassword string) {
+	if !h.PasswordHasher.NeedsRehash(hashedPassword) {
+		return
+	}
+
+	newHashedPassword, err := h.PasswordHasher.Hash(password)
+
+	if err == nil {
+		_, err = h.Store.UpdateUser(ctx, db.UpdateUserParams{
+			Username:       username,
+			HashedPassword: pgtype.Text{String: newHashedPassword, Valid: true},
+		})
+	}
+
+	if err != nil {
+		log.Warn().Err(err).Str("username", username).Msg("cannot upgrade password hash")
+	}
+}
+
+// validateNewPassword checks the rules that need the user, its name and email and its recent passwords
+func (h *UserHandler) validateNewPassword(ctx context.Context, user db.GetUserByUserNameRow, field string, password string) error {
+	if err := h.PasswordPolicy.ValidateFor(password, user.Username, user.Email); err != nil {
+		return myErr.InvalidAgrumentError([]*errdetails.BadRequest_FieldViolation{myErr.FieldViolation(field, err)})
+	}
+
+	if h.PasswordPolicy.HistorySize <= 0 {
+		return nil
+	}
+
+	hashedPasswords := []string{user.HashedPassword}
+
+	if keep := h.PasswordPolicy.HistoryKeep(); keep > 0 {
+		history, err := h.Store.ListPasswordHistory(ctx, db.ListPasswordHistoryParams{Username: user.Username, Limit: keep})
+
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to get password history: %v", err)
 		}
+
+		hashedPasswords = append(hashedPasswords, history...)
+	}
+
+	if pw.Reused(h.PasswordHasher, password, hashedPasswords) {
+		return status.Errorf(codes.InvalidArgument, "new password must differ from your recent passwords")
 	}
 
-	accessToken, aTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, h.Config.AccessTokenDuration)
+	return nil
+}
+
+// createLoginSession creates the session of a successful login and the token pair bound to it,
+// strength is how the user proved their identity
+func (h *UserHandler) createLoginSession(ctx context.Context, user db.GetUserByUserNameRow, deviceLabel string, strength string) (*pb.LoginUserResponse, error) {
+	sessionID := uuid.New()
+	authenticated := token.WithAuth(time.Now(), strength)
+
+	accessToken, aTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, sessionID, h.Config.AccessTokenDuration, authenticated)
 
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create access token: %v", err)
 	}
 
-	refreshToken, rTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, h.Config.RefreshTokenDuration)
+	refreshToken, rTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, sessionID, h.Config.RefreshTokenDuration, authenticated, token.WithTokenType(token.TokenTypeRefresh))
 
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %v", err)
@@ -215,174 +296,1656 @@ func (h *UserHandler) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 
 	metadata := h.extractMetadata(ctx)
 
-	arg := db.CreateSessionParams{
-		ID:           uuid.MustParse(rTkPayload.ID),
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    metadata.UserClient,
-		ClientIp:     metadata.ClientIP,
-		IsBlocked:    false,
-		ExpiresAt:    rTkPayload.ExpiresAt.Time,
+	arg := db.CreateSessionTxParams{
+		CreateSessionParams: db.CreateSessionParams{
+			ID:           sessionID,
+			Username:     user.Username,
+			RefreshToken: token.HashToken(refreshToken),
+			UserAgent:    metadata.UserClient,
+			ClientIp:     metadata.ClientIP,
+			IsBlocked:    false,
+			ExpiresAt:    rTkPayload.ExpiresAt.Time,
+			DeviceLabel:  deviceLabel,
+		},
+		MaxSessions: h.Config.MaxSessionsPerUser,
+		AfterCreate: func(q db.Querier, session db.Session) error {
+			return loginhistory.RecordSuccess(ctx, q, session)
+		},
 	}
 
-	session, err := h.Store.CreateSession(ctx, arg)
+	result, err := h.Store.CreateSessionTx(ctx, arg)
 
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create session: %v", err)
 	}
 
+	h.SessionChecker.Invalidate(result.Evicted...)
+
 	response := &pb.LoginUserResponse{
-		SessionID:             session.ID.String(),
+		SessionID:             result.Session.ID.String(),
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  timestamppb.New(aTkPayload.ExpiresAt.Time),
 		RefreshToken:          refreshToken,
 		RefreshTokenExpiresAt: timestamppb.New(rTkPayload.ExpiresAt.Time),
-		User: convertUser(db.User{
-			Username:          user.Username,
-			FullName:          user.FullName,
-			Email:             user.Email,
-			PasswordChangedAt: user.PasswordChangedAt,
-			CreatedAt:         user.CreatedAt,
-		}),
+		User:                  convertUserRow(user),
 	}
 
 	return response, nil
 }
 
-func (h *UserHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	authPayload, err := h.authorizeUser(ctx, []string{
-		util.DepositorRole,
-		util.BankerRole,
-	})
+func (h *UserHandler) VerifyLoginMfa(ctx context.Context, req *pb.VerifyLoginMfaRequest) (*pb.LoginUserResponse, error) {
+	violations := validateVerifyLoginMfaRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: h.clientAddress(ctx)}
 
+	ipAttempt, err := throttle.Reserve(ctx, h.Limiter, ipKey)
 	if err != nil {
-		return nil, myErr.UnAuthorizedError(err)
+		return nil, throttleError(err)
 	}
+	defer ipAttempt.Release(ctx)
 
-	violations := validateUpdateUserRequest(req)
+	challenge, err := h.MFA.PendingChallenge(ctx, req.GetChallengeToken())
+
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidChallenge) {
+			ipAttempt.Fail()
+		}
+
+		return nil, mfaError(err)
+	}
+
+	// Codes are guessed against the same budget as passwords, a new challenge does not renew it
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: challenge.Username}
+
+	userAttempt, err := throttle.Reserve(ctx, h.Limiter, userKey)
+	if err != nil {
+		return nil, throttleError(err)
+	}
+	defer userAttempt.Release(ctx)
+
+	challenge, err = h.MFA.CompleteChallenge(ctx, challenge, req.GetCode())
+
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			metadata := h.extractMetadata(ctx)
+			loginhistory.RecordFailure(ctx, h.Store, challenge.Username, loginhistory.ReasonInvalidMfaCode, metadata.UserClient, metadata.ClientIP)
+
+			userAttempt.Fail()
+			ipAttempt.Fail()
+		}
+
+		return nil, mfaError(err)
+	}
+
+	if err := h.Limiter.Reset(ctx, userKey); err != nil {
+		return nil, throttleError(err)
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, challenge.Username)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	return h.createLoginSession(ctx, user, challenge.DeviceLabel, token.AuthStrengthMfa)
+}
+
+// BeginOidcLogin returns the URL of the corporate identity provider the browser is sent to, the
+// login cookie set here has to come back with the callback
+func (h *UserHandler) BeginOidcLogin(ctx context.Context, req *pb.BeginOidcLoginRequest) (*pb.BeginOidcLoginResponse, error) {
+	if h.OIDC == nil {
+		return nil, status.Errorf(codes.Unimplemented, "SSO login is not configured")
+	}
+
+	violations := validateBeginOidcLoginRequest(req)
 
 	if violations != nil {
 		return nil, myErr.InvalidAgrumentError(violations)
 	}
 
-	if authPayload.Role != util.BankerRole && authPayload.UserName != req.GetUserName() {
-		return nil, status.Errorf(codes.PermissionDenied, "unauthorized user")
+	authRequest, err := h.OIDC.Begin(ctx, req.GetDeviceLabel())
+
+	if err != nil {
+		return nil, oidcError(err)
 	}
 
-	arg := db.UpdateUserParams{
-		Username: req.GetUserName(),
-		FullName: pgtype.Text{
-			String: req.GetFullName(),
-			Valid:  req.FullName != nil,
-		},
-		Email: pgtype.Text{
-			String: req.GetEmail(),
-			Valid:  req.Email != nil,
-		},
+	if err := h.setCookie(ctx, h.OIDC.Cookie(authRequest)); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set login cookie: %v", err)
+	}
+
+	response := &pb.BeginOidcLoginResponse{
+		AuthorizationUrl: authRequest.URL,
+		State:            authRequest.State,
+		ExpiresAt:        timestamppb.New(authRequest.ExpiresAt),
+	}
+
+	return response, nil
+}
+
+// OidcCallback completes a SSO login, the user is created on their first login. With 2FA on the
+// login only buys a challenge like a password login does.
+func (h *UserHandler) OidcCallback(ctx context.Context, req *pb.OidcCallbackRequest) (*pb.LoginUserResponse, error) {
+	if h.OIDC == nil {
+		return nil, status.Errorf(codes.Unimplemented, "SSO login is not configured")
+	}
+
+	violations := validateOidcCallbackRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if req.GetError() != "" {
+		return nil, status.Errorf(codes.Unauthenticated, "SSO login failed: %s", req.GetError())
 	}
 
-	user, err := h.Store.UpdateUser(ctx, arg)
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: h.clientAddress(ctx)}
 
+	attempt, err := throttle.Reserve(ctx, h.Limiter, ipKey)
 	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
-			return nil, status.Errorf(codes.NotFound, "user not found")
+		return nil, throttleError(err)
+	}
+	defer attempt.Release(ctx)
+
+	// A missing cookie is refused by Complete
+	browser := h.cookie(ctx, oidc.CookieName)
+
+	identity, err := h.OIDC.Complete(ctx, req.GetState(), browser, req.GetCode(), func(q db.Querier, user db.User) error {
+		// Addresses the provider did not verify are checked like those of a sign up
+		if user.IsEmailVerified {
+			return nil
 		}
+		return enqueueVerifyEmail(ctx, q, user.Username)
+	})
 
-		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	if cookieErr := h.setCookie(ctx, h.OIDC.ExpiredCookie()); cookieErr != nil {
+		log.Warn().Err(cookieErr).Msg("cannot clear login cookie")
 	}
 
-	response := &pb.UpdateUserResponse{
-		User: convertUser(user),
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidState) || errors.Is(err, oidc.ErrInvalidIDToken) {
+			attempt.Fail()
+		}
+
+		return nil, oidcError(err)
 	}
-	return response, nil
+
+	mfaEnabled, err := h.MFA.Enabled(ctx, identity.User.Username)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor authentication: %v", err)
+	}
+
+	if mfaEnabled {
+		challenge, err := h.MFA.CreateChallenge(ctx, identity.User.Username, identity.DeviceLabel)
+
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create two-factor authentication challenge: %v", err)
+		}
+
+		response := &pb.LoginUserResponse{
+			MfaRequired:           true,
+			MfaChallengeToken:     challenge.Token,
+			MfaChallengeExpiresAt: timestamppb.New(challenge.ExpiresAt),
+		}
+
+		return response, nil
+	}
+
+	return h.createLoginSession(ctx, identity.User, identity.DeviceLabel, token.AuthStrengthSso)
 }
 
-func (h *UserHandler) VerifyUserEmail(ctx context.Context, req *pb.VerifyUserEmailRequest) (*pb.VerifyUserEmailResponse, error) {
-	violations := validateVerifyUserEmailRequest(req)
+// SendLoginSmsCode texts a login code for a pending challenge, the code is then sent to VerifyLoginMfa
+// like a TOTP code. Only users with a verified phone number can receive one.
+func (h *UserHandler) SendLoginSmsCode(ctx context.Context, req *pb.SendLoginSmsCodeRequest) (*pb.SendLoginSmsCodeResponse, error) {
+	violations := validateSendLoginSmsCodeRequest(req)
 
 	if violations != nil {
 		return nil, myErr.InvalidAgrumentError(violations)
 	}
 
-	arg := db.VerifyUserEmailTxParams{
-		EmailId:    req.GetEmailId(),
-		SecretCode: req.GetSecretCode(),
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: h.clientAddress(ctx)}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, ipKey)
+	if err != nil {
+		return nil, throttleError(err)
 	}
+	defer attempt.Release(ctx)
 
-	verifyEmail, err := h.Store.VerifyUserEmailTx(ctx, arg)
+	challenge, err := h.MFA.PendingChallenge(ctx, req.GetChallengeToken())
 
 	if err != nil {
-		if errors.Is(err, db.ErrRecordNotFound) {
-			return nil, status.Errorf(codes.NotFound, "email verification not found")
+		if errors.Is(err, mfa.ErrInvalidChallenge) {
+			attempt.Fail()
 		}
-		return nil, status.Errorf(codes.Internal, "failed to get email verification: %v", err)
+
+		return nil, mfaError(err)
 	}
 
-	response := &pb.VerifyUserEmailResponse{
-		IsVerified: verifyEmail.User.IsEmailVerified,
+	sendKey := throttle.Key{Scope: throttle.SendPhoneCode, ID: challenge.Username}
+
+	if err := h.Limiter.Check(ctx, sendKey); err != nil {
+		return nil, throttleError(err)
 	}
 
-	return response, nil
+	user, err := h.Store.GetUserByUserName(ctx, challenge.Username)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	if err := phone.CanReceive(user, phone.PurposeLogin); err != nil {
+		return nil, phoneError(err)
+	}
+
+	if err := h.enqueuePhoneCode(ctx, user, phone.PurposeLogin, sendKey); err != nil {
+		return nil, err
+	}
+
+	return &pb.SendLoginSmsCodeResponse{}, nil
 }
-func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
-	if err := validations.ValidateUsername(req.GetUserName()); err != nil {
-		violations = append(violations, myErr.FieldViolation("userName", err))
+
+func (h *UserHandler) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	violations := validateRefreshTokenRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
 	}
 
-	if err := validations.ValidatePassword(req.GetPassword()); err != nil {
-		violations = append(violations, myErr.FieldViolation("password", err))
+	claims, err := h.TokenMaker.VerifyToken(req.GetRefreshToken())
+
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
 	}
 
-	if err := validations.ValidateFullName(req.GetFullName()); err != nil {
-		violations = append(violations, myErr.FieldViolation("fullName", err))
+	// Checked before the rotation, an access token would be taken for a reused refresh token and revoke the session
+	if err := claims.CheckType(token.TokenTypeRefresh); err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
 	}
 
-	if err := validations.ValidateEmail(req.GetEmail()); err != nil {
-		violations = append(violations, myErr.FieldViolation("email", err))
+	session, err := h.Store.GetSessionById(ctx, claims.SessionID)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get session: %v", err)
 	}
 
-	return violations
-}
+	if session.RevokedAt.Valid {
+		return nil, status.Errorf(codes.Unauthenticated, "session is revoked")
+	}
 
-func validateLoginUserRequest(req *pb.LoginUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
-	if err := validations.ValidateUsername(req.GetUserName()); err != nil {
-		violations = append(violations, myErr.FieldViolation("userName", err))
+	if session.IsBlocked {
+		return nil, status.Errorf(codes.Unauthenticated, "session is blocked")
 	}
 
-	if err := validations.ValidatePassword(req.GetPassword()); err != nil {
-		violations = append(violations, myErr.FieldViolation("password", err))
+	if session.Username != claims.UserName {
+		return nil, status.Errorf(codes.Unauthenticated, "incorrect session user")
 	}
 
-	return violations
+	if time.Now().After(session.ExpiresAt) {
+		return nil, status.Errorf(codes.Unauthenticated, "session expired")
+	}
+
+	// Checked before the rotation so a token from before a password change is not taken for a reused one
+	if claims.IssuedBefore(session.PasswordChangedAt) {
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token was issued before the last password change")
+	}
+
+	// The rotated refresh token keeps the expiry of the session so a session cannot be extended forever,
+	// both tokens keep the auth_time of the login so a refresh never passes for a step-up
+	refreshToken, rTkPayload, err := h.TokenMaker.CreateToken(claims.UserName, claims.Role, session.ID, time.Until(session.ExpiresAt), token.WithAuthOf(claims), token.WithTokenType(token.TokenTypeRefresh))
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %v", err)
+	}
+
+	arg := db.RotateRefreshTokenTxParams{
+		SessionID:       session.ID,
+		RefreshToken:    token.HashToken(req.GetRefreshToken()),
+		NewRefreshToken: token.HashToken(refreshToken),
+		ClientIp:        h.extractMetadata(ctx).ClientIP,
+	}
+
+	result, err := h.Store.RotateRefreshTokenTx(ctx, arg)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to rotate refresh token: %v", err)
+	}
+
+	if result.Reused {
+		h.SessionChecker.Invalidate(session.ID)
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token reuse detected, session is blocked")
+	}
+
+	accessToken, aTkPayload, err := h.TokenMaker.CreateToken(claims.UserName, claims.Role, session.ID, h.Config.AccessTokenDuration, token.WithAuthOf(claims))
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %v", err)
+	}
+
+	response := &pb.RefreshTokenResponse{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(aTkPayload.ExpiresAt.Time),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(rTkPayload.ExpiresAt.Time),
+	}
+
+	return response, nil
 }
 
-func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
-	if err := validations.ValidateUsername(req.GetUserName()); err != nil {
-		violations = append(violations, myErr.FieldViolation("userName", err))
+func (h *UserHandler) LogoutUser(ctx context.Context, req *pb.LogoutUserRequest) (*pb.LogoutUserResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.SessionManage)
+
+	if err != nil {
+		return nil, err
 	}
 
-	if req.FullName != nil {
-		if err := validations.ValidateFullName(req.GetFullName()); err != nil {
-			violations = append(violations, myErr.FieldViolation("fullName", err))
-		}
+	if err := h.Store.RevokeSession(ctx, authPayload.SessionID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke session: %v", err)
 	}
 
-	if req.Email != nil {
-		if err := validations.ValidateEmail(req.GetEmail()); err != nil {
-			violations = append(violations, myErr.FieldViolation("email", err))
-		}
+	h.SessionChecker.Invalidate(authPayload.SessionID)
+
+	response := &pb.LogoutUserResponse{
+		SessionID: authPayload.SessionID.String(),
 	}
 
-	return violations
+	return response, nil
 }
 
-func validateVerifyUserEmailRequest(req *pb.VerifyUserEmailRequest) (violations []*errdetails.BadRequest_FieldViolation) {
-	if err := validations.ValidateEmailId(req.GetEmailId()); err != nil {
-		violations = append(violations, myErr.FieldViolation("emailId", err))
+func (h *UserHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.UserUpdate)
+
+	if err != nil {
+		return nil, err
 	}
 
-	if err := validations.ValidateSecretCode(req.GetSecretCode()); err != nil {
-		violations = append(violations, myErr.FieldViolation("secretCode", err))
+	violations := validateUpdateUserRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if !rbac.CanAccess(authPayload, req.GetUserName(), rbac.UserUpdateAny) {
+		return nil, status.Errorf(codes.PermissionDenied, "unauthorized user")
+	}
+
+	// Whoever controls the email can reset the password, so changing it needs a fresh proof of identity
+	if req.Email != nil {
+		if err := auth.StepUpGrpc(authPayload, h.StepUpPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	arg := db.UpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			Username: req.GetUserName(),
+			FullName: pgtype.Text{
+				String: req.GetFullName(),
+				Valid:  req.FullName != nil,
+			},
+			Email: pgtype.Text{
+				String: req.GetEmail(),
+				Valid:  req.Email != nil,
+			},
+			Phone: pgtype.Text{
+				String: req.GetPhone(),
+				Valid:  req.Phone != nil,
+			},
+			Address: pgtype.Text{
+				String: req.GetAddress(),
+				Valid:  req.Address != nil,
+			},
+			Nationality: pgtype.Text{
+				String: req.GetNationality(),
+				Valid:  req.Nationality != nil,
+			},
+		},
+		AfterEmailChange: func(q db.Querier, user db.User) error {
+			return enqueueVerifyEmail(ctx, q, user.Username)
+		},
+	}
+
+	if req.DateOfBirth != nil {
+		// The validation already checked the date
+		dateOfBirth, _ := time.Parse(time.DateOnly, req.GetDateOfBirth())
+		arg.DateOfBirth = pgtype.Date{Time: dateOfBirth, Valid: true}
+	}
+
+	// A change of a reviewed field sends the profile back to KYC review
+	if req.FullName != nil || req.DateOfBirth != nil || req.Phone != nil || req.Address != nil || req.Nationality != nil {
+		user, err := h.Store.GetUserByUserName(ctx, req.GetUserName())
+
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				return nil, status.Errorf(codes.NotFound, "user not found")
+			}
+
+			return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+		}
+
+		kyc.ResetOnProfileChange(user, &arg)
+	}
+
+	// Bankers can edit any profile, including the email the password is reset with
+	if authPayload.UserName != req.GetUserName() {
+		entry := audit.Entry(authPayload.UserName, audit.UpdateUser, req.GetUserName(), auth.GrpcClientIP(ctx), audit.ProfileUpdate(arg.UpdateUserParams))
+		arg.AuditLog = &entry
+	}
+
+	result, err := h.Store.UpdateUserTx(ctx, arg)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	response := &pb.UpdateUserResponse{
+		User: convertUser(result.User),
+	}
+	return response, nil
+}
+
+func (h *UserHandler) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.GetProfileResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.UserRead)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	return &pb.GetProfileResponse{User: convertUserRow(user)}, nil
+}
+
+func (h *UserHandler) SubmitKyc(ctx context.Context, req *pb.SubmitKycRequest) (*pb.SubmitKycResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.UserUpdate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	arg, err := kyc.NewSubmission(user)
+
+	if err != nil {
+		if errors.Is(err, kyc.ErrIncompleteProfile) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.FailedPrecondition, "KYC is already pending or verified")
+	}
+
+	result, err := h.Store.UpdateKycStatusTx(ctx, arg)
+
+	if err != nil {
+		// A banker or another request changed the status in the meantime
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.Aborted, "KYC status changed, please try again")
+		}
+
+		if errors.Is(err, db.ErrNoKycDocuments) {
+			return nil, status.Errorf(codes.FailedPrecondition, "at least one document has to be uploaded before submitting for KYC review")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to submit KYC: %v", err)
+	}
+
+	return &pb.SubmitKycResponse{User: convertUser(result.User)}, nil
+}
+
+// UploadKycDocument stores an identity document for the next KYC submission
+func (h *UserHandler) UploadKycDocument(ctx context.Context, req *pb.UploadKycDocumentRequest) (*pb.UploadKycDocumentResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.UserUpdate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUploadKycDocumentRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	document, err := h.KycDocuments.Upload(ctx, h.Store, user, kyc.Upload{
+		Kind:        req.GetKind(),
+		FileName:    req.GetFileName(),
+		ContentType: req.GetContentType(),
+		Data:        req.GetContent(),
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, kyc.ErrDocumentsLocked):
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		case errors.Is(err, kyc.ErrEmptyDocument), errors.Is(err, kyc.ErrDocumentTooLarge),
+			errors.Is(err, kyc.ErrUnsupportedDocumentType), errors.Is(err, kyc.ErrDocumentTypeMismatch):
+			return nil, myErr.InvalidAgrumentError([]*errdetails.BadRequest_FieldViolation{myErr.FieldViolation("content", err)})
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to store document: %v", err)
+	}
+
+	return &pb.UploadKycDocumentResponse{Document: convertKycDocument(document)}, nil
+}
+
+func (h *UserHandler) ListKycDocuments(ctx context.Context, req *pb.ListKycDocumentsRequest) (*pb.ListKycDocumentsResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.UserRead)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListKycDocumentsRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	documents, err := h.Store.ListKycDocuments(ctx, db.ListKycDocumentsParams{
+		Username: authPayload.UserName,
+		Limit:    req.GetSize(),
+		Offset:   (req.GetPage() - 1) * req.GetSize(),
+	})
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list documents: %v", err)
+	}
+
+	return &pb.ListKycDocumentsResponse{Documents: convertKycDocuments(documents)}, nil
+}
+
+func (h *UserHandler) VerifyUserEmail(ctx context.Context, req *pb.VerifyUserEmailRequest) (*pb.VerifyUserEmailResponse, error) {
+	violations := validateVerifyUserEmailRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	emailKey := throttle.Key{Scope: throttle.VerifyEmail, ID: strconv.FormatInt(req.GetEmailId(), 10)}
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: h.clientAddress(ctx)}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, emailKey, ipKey)
+	if err != nil {
+		return nil, throttleError(err)
+	}
+	defer attempt.Release(ctx)
+
+	arg := db.VerifyUserEmailTxParams{
+		EmailId:    req.GetEmailId(),
+		SecretCode: req.GetSecretCode(),
+		AfterVerify: func(q db.Querier, user db.User) error {
+			return worker.EnqueueUserVerifiedEvent(ctx, q, user)
+		},
+	}
+
+	verifyEmail, err := h.Store.VerifyUserEmailTx(ctx, arg)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			attempt.Fail()
+
+			return nil, status.Errorf(codes.NotFound, "email verification not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get email verification: %v", err)
+	}
+
+	if err := h.Limiter.Reset(ctx, emailKey); err != nil {
+		return nil, throttleError(err)
+	}
+
+	response := &pb.VerifyUserEmailResponse{
+		IsVerified: verifyEmail.User.IsEmailVerified,
+	}
+
+	return response, nil
+}
+func (h *UserHandler) ResendVerifyEmail(ctx context.Context, req *pb.ResendVerifyEmailRequest) (*pb.ResendVerifyEmailResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.UserUpdate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resendKey := throttle.Key{Scope: throttle.ResendVerifyEmail, ID: authPayload.UserName}
+
+	if err := h.Limiter.Check(ctx, resendKey); err != nil {
+		return nil, throttleError(err)
+	}
+
+	arg := db.ResendVerifyEmailTxParams{
+		Username: authPayload.UserName,
+		AfterInvalidate: func(q db.Querier, user db.GetUserByUserNameRow) error {
+			return enqueueVerifyEmail(ctx, q, user.Username)
+		},
+	}
+
+	if _, err := h.Store.ResendVerifyEmailTx(ctx, arg); err != nil {
+		if errors.Is(err, db.ErrEmailAlreadyVerified) {
+			return nil, status.Errorf(codes.FailedPrecondition, "email is already verified")
+		}
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to resend verification email: %v", err)
+	}
+
+	// Every resend counts so the next one has to wait longer
+	if err := h.Limiter.Fail(ctx, resendKey); err != nil {
+		return nil, throttleError(err)
+	}
+
+	return &pb.ResendVerifyEmailResponse{}, nil
+}
+
+// SendPhoneCode texts a verification code to the phone number of the profile
+func (h *UserHandler) SendPhoneCode(ctx context.Context, req *pb.SendPhoneCodeRequest) (*pb.SendPhoneCodeResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.UserUpdate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sendKey := throttle.Key{Scope: throttle.SendPhoneCode, ID: authPayload.UserName}
+
+	if err := h.Limiter.Check(ctx, sendKey); err != nil {
+		return nil, throttleError(err)
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	if err := phone.CanReceive(user, phone.PurposeVerify); err != nil {
+		return nil, phoneError(err)
+	}
+
+	if err := h.enqueuePhoneCode(ctx, user, phone.PurposeVerify, sendKey); err != nil {
+		return nil, err
+	}
+
+	return &pb.SendPhoneCodeResponse{}, nil
+}
+
+func (h *UserHandler) VerifyPhone(ctx context.Context, req *pb.VerifyPhoneRequest) (*pb.VerifyPhoneResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.UserUpdate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateVerifyPhoneRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	verifyKey := throttle.Key{Scope: throttle.VerifyPhone, ID: authPayload.UserName}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, verifyKey)
+	if err != nil {
+		return nil, throttleError(err)
+	}
+	defer attempt.Release(ctx)
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	verified, err := h.PhoneCodes.VerifyPhone(ctx, user, req.GetCode())
+
+	if err != nil {
+		if errors.Is(err, phone.ErrInvalidCode) {
+			attempt.Fail()
+		}
+
+		return nil, phoneError(err)
+	}
+
+	if err := h.Limiter.Reset(ctx, verifyKey); err != nil {
+		return nil, throttleError(err)
+	}
+
+	return &pb.VerifyPhoneResponse{User: convertUser(verified)}, nil
+}
+
+// enqueuePhoneCode writes the task that texts a new code to user and counts it against sendKey
+func (h *UserHandler) enqueuePhoneCode(ctx context.Context, user db.GetUserByUserNameRow, purpose string, sendKey throttle.Key) error {
+	taskPayload := &worker.PayloadSendPhoneCode{
+		UserName: user.Username,
+		Phone:    user.Phone,
+		Purpose:  purpose,
+	}
+
+	opts := worker.OutboxOptions{
+		MaxRetry: 5,
+		Queue:    worker.QueueCritical,
+	}
+
+	// Nothing else changes with the request, so the outbox message is written on its own
+	if err := worker.EnqueueTaskSendPhoneCode(ctx, h.Store, taskPayload, opts); err != nil {
+		return status.Errorf(codes.Internal, "failed to send phone code: %v", err)
+	}
+
+	// Every code counts so the next one has to wait longer
+	if err := h.Limiter.Fail(ctx, sendKey); err != nil {
+		return throttleError(err)
+	}
+
+	return nil
+}
+
+// enqueueVerifyEmail writes the task that sends a new verification code into the outbox of q
+func enqueueVerifyEmail(ctx context.Context, q db.Querier, username string) error {
+	taskPayload := &worker.PayloadSendVerifyEmail{
+		UserName: username,
+	}
+
+	opts := worker.OutboxOptions{
+		MaxRetry: 10,
+		Queue:    worker.QueueCritical,
+	}
+
+	return worker.EnqueueTaskSendVerifyEmail(ctx, q, taskPayload, opts)
+}
+
+func (h *UserHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.UserUpdate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateChangePasswordRequest(req, h.PasswordPolicy)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	if err := h.PasswordHasher.Check(req.GetCurrentPassword(), user.HashedPassword); err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "incorrect current password")
+	}
+
+	if req.GetNewPassword() == req.GetCurrentPassword() {
+		return nil, status.Errorf(codes.InvalidArgument, "new password must differ from the current password")
+	}
+
+	if err := h.validateNewPassword(ctx, user, "newPassword", req.GetNewPassword()); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := h.PasswordHasher.Hash(req.GetNewPassword())
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
+	}
+
+	arg := db.ChangePasswordTxParams{
+		Username:          user.Username,
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: time.Now(),
+		ClientIp:          h.extractMetadata(ctx).ClientIP,
+		HistoryKeep:       h.PasswordPolicy.HistoryKeep(),
+	}
+
+	response := &pb.ChangePasswordResponse{}
+
+	if req.GetKeepCurrentSession() {
+		session, err := h.Store.GetSessionById(ctx, authPayload.SessionID)
+
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get session: %v", err)
+		}
+
+		// Created after PasswordChangedAt, so the new tokens outlive the change
+		refreshToken, rTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, session.ID, time.Until(session.ExpiresAt), token.WithAuthOf(authPayload), token.WithTokenType(token.TokenTypeRefresh))
+
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create refresh token: %v", err)
+		}
+
+		accessToken, aTkPayload, err := h.TokenMaker.CreateToken(user.Username, user.Role, session.ID, h.Config.AccessTokenDuration, token.WithAuthOf(authPayload))
+
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create access token: %v", err)
+		}
+
+		arg.KeepSessionID = session.ID
+		arg.KeepSessionRefreshToken = token.HashToken(refreshToken)
+
+		response.Tokens = &pb.RefreshTokenResponse{
+			AccessToken:           accessToken,
+			AccessTokenExpiresAt:  timestamppb.New(aTkPayload.ExpiresAt.Time),
+			RefreshToken:          refreshToken,
+			RefreshTokenExpiresAt: timestamppb.New(rTkPayload.ExpiresAt.Time),
+		}
+	}
+
+	result, err := h.Store.ChangePasswordTx(ctx, arg)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to change password: %v", err)
+	}
+
+	// The kept session is dropped from the cache too, its old tokens must be checked against the new password_changed_at
+	h.SessionChecker.Invalidate(append(result.RevokedSessions, authPayload.SessionID)...)
+
+	response.Revoked = int64(len(result.RevokedSessions))
+
+	return response, nil
+}
+
+func (h *UserHandler) ForgotPassword(ctx context.Context, req *pb.ForgotPasswordRequest) (*pb.ForgotPasswordResponse, error) {
+	violations := validateForgotPasswordRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	// The response is the same whether or not the email belongs to a user
+	response := &pb.ForgotPasswordResponse{
+		Message: "if the email belongs to an account, a password reset link has been sent",
+	}
+
+	// Every request counts for the address and the client so nobody can flood a mailbox
+	emailKey := throttle.Key{Scope: throttle.ForgotPassword, ID: strings.ToLower(req.GetEmail())}
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: h.clientAddress(ctx)}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, emailKey, ipKey)
+	if err != nil {
+		return nil, throttleError(err)
+	}
+	defer attempt.Release(ctx)
+
+	// The user is looked up by the worker, so the request does the same work whether or not the email is known
+	taskPayload := &worker.PayloadSendResetPasswordEmail{
+		Email:     req.GetEmail(),
+		RequestID: uuid.New(),
+	}
+
+	opts := worker.OutboxOptions{
+		MaxRetry: 10,
+		Queue:    worker.QueueCritical,
+	}
+
+	// Nothing else changes with the request, so the outbox message is written on its own
+	if err := worker.EnqueueTaskSendResetPasswordEmail(ctx, h.Store, taskPayload, opts); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to enqueue password reset email: %v", err)
+	}
+
+	attempt.Fail()
+	return response, nil
+}
+
+func (h *UserHandler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	violations := validateResetPasswordRequest(req, h.PasswordPolicy)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	// The token is only used by the transaction, it is looked up first to check the password against its user
+	reset, err := h.Store.GetPasswordResetByTokenHash(ctx, token.HashToken(req.GetToken()))
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired password reset token")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get password reset: %v", err)
+	}
+
+	user, err := h.Store.GetUserByUserName(ctx, reset.Username)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	if err := h.validateNewPassword(ctx, user, "password", req.GetPassword()); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := h.PasswordHasher.Hash(req.GetPassword())
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", err)
+	}
+
+	result, err := h.Store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash:      token.HashToken(req.GetToken()),
+		HashedPassword: hashedPassword,
+		HistoryKeep:    h.PasswordPolicy.HistoryKeep(),
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid or expired password reset token")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reset password: %v", err)
+	}
+
+	h.SessionChecker.Invalidate(result.RevokedSessions...)
+
+	return &pb.ResetPasswordResponse{}, nil
+}
+
+func (h *UserHandler) ListLoginEvents(ctx context.Context, req *pb.ListLoginEventsRequest) (*pb.ListLoginEventsResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.SessionManage)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateListLoginEventsRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	arg := db.ListLoginEventsParams{
+		Username: authPayload.UserName,
+		Limit:    req.GetSize(),
+		Offset:   (req.GetPage() - 1) * req.GetSize(),
+	}
+
+	events, err := h.Store.ListLoginEvents(ctx, arg)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list login events: %v", err)
+	}
+
+	response := &pb.ListLoginEventsResponse{
+		LoginEvents: make([]*pb.LoginEvent, 0, len(events)),
+	}
+
+	for _, event := range events {
+		response.LoginEvents = append(response.LoginEvents, convertLoginEvent(event))
+	}
+
+	return response, nil
+}
+
+// GetDenyLogin shows the login of a "this wasn't me" link without changing it, so link prefetchers
+// cannot deny a login. The page of the link calls DenyLogin once the user confirms.
+func (h *UserHandler) GetDenyLogin(ctx context.Context, req *pb.GetDenyLoginRequest) (*pb.GetDenyLoginResponse, error) {
+	violations := validateGetDenyLoginRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	event, err := h.Store.GetLoginEventByDenyToken(ctx, pgtype.Text{String: token.HashToken(req.GetToken()), Valid: true})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "link is invalid or was already used")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get login: %v", err)
+	}
+
+	response := &pb.GetDenyLoginResponse{
+		LoginEvent: convertLoginEvent(event),
+	}
+
+	return response, nil
+}
+
+// DenyLogin is the confirmation of a "this wasn't me" link of a new device alert, it blocks the session of the login
+func (h *UserHandler) DenyLogin(ctx context.Context, req *pb.DenyLoginRequest) (*pb.DenyLoginResponse, error) {
+	violations := validateDenyLoginRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	result, err := h.Store.DenyLoginTx(ctx, token.HashToken(req.GetToken()))
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "link is invalid or was already used")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to deny login: %v", err)
+	}
+
+	if result.Blocked {
+		h.SessionChecker.Invalidate(result.LoginEvent.SessionID.Bytes)
+	}
+
+	response := &pb.DenyLoginResponse{
+		SessionRevoked: result.Blocked,
+	}
+
+	return response, nil
+}
+
+// StepUp checks the identity of the caller again, with a second factor when 2FA is on and the password
+// otherwise, and issues a short-lived access token of the same session carrying a fresh auth_time
+func (h *UserHandler) StepUp(ctx context.Context, req *pb.StepUpRequest) (*pb.StepUpResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.SessionManage)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateStepUpRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	// API keys have no session and no password to prove
+	if authPayload.SessionID == uuid.Nil {
+		return nil, status.Errorf(codes.PermissionDenied, "only users can step up")
+	}
+
+	// Failures count against the login lockout so the method is no way around it
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: authPayload.UserName}
+
+	attempt, err := throttle.Reserve(ctx, h.Limiter, userKey)
+	if err != nil {
+		return nil, throttleError(err)
+	}
+	defer attempt.Release(ctx)
+
+	user, err := h.Store.GetUserByUserName(ctx, authPayload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	mfaEnabled, err := h.MFA.Enabled(ctx, user.Username)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor authentication: %v", err)
+	}
+
+	strength := token.AuthStrengthPassword
+	if mfaEnabled {
+		strength = token.AuthStrengthMfa
+	}
+
+	// The token of a weaker step-up would still be refused by every sensitive operation
+	if !h.StepUpPolicy.Satisfies(strength) {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication must be enabled to step up")
+	}
+
+	// With 2FA on the password alone would be weaker than the login, so the second factor is required
+	if mfaEnabled {
+		if req.Code == nil {
+			return nil, myErr.InvalidAgrumentError([]*errdetails.BadRequest_FieldViolation{
+				myErr.FieldViolation("code", fmt.Errorf("two-factor code is required")),
+			})
+		}
+
+		err = h.MFA.Verify(ctx, user.Username, req.GetCode())
+	} else {
+		// Users created by an SSO login have a random password until they reset one
+		if req.Password == nil {
+			return nil, myErr.InvalidAgrumentError([]*errdetails.BadRequest_FieldViolation{
+				myErr.FieldViolation("password", fmt.Errorf("password is required")),
+			})
+		}
+
+		err = h.PasswordHasher.Check(req.GetPassword(), user.HashedPassword)
+	}
+
+	if err != nil {
+		if !errors.Is(err, mfa.ErrInvalidCode) && !errors.Is(err, pw.ErrMismatchedHashAndPassword) {
+			return nil, status.Errorf(codes.Internal, "failed to check credentials: %v", err)
+		}
+
+		attempt.Fail()
+
+		return nil, status.Errorf(codes.Unauthenticated, "invalid credentials")
+	}
+
+	if err := h.Limiter.Reset(ctx, userKey); err != nil {
+		return nil, throttleError(err)
+	}
+
+	accessToken, aTkPayload, err := h.TokenMaker.CreateToken(
+		user.Username, user.Role, authPayload.SessionID, h.StepUpPolicy.TokenDuration, token.WithAuth(time.Now(), strength),
+	)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %v", err)
+	}
+
+	response := &pb.StepUpResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: timestamppb.New(aTkPayload.ExpiresAt.Time),
+		AuthTime:             timestamppb.New(aTkPayload.AuthTime.Time),
+		AuthStrength:         aTkPayload.AuthStrength,
+	}
+
+	return response, nil
+}
+
+func (h *UserHandler) EnrollTotp(ctx context.Context, req *pb.EnrollTotpRequest) (*pb.EnrollTotpResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.MfaManage)
+
+	if err != nil {
+		return nil, err
+	}
+
+	enrolment, err := h.MFA.Enroll(ctx, authPayload.UserName)
+
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	response := &pb.EnrollTotpResponse{
+		Secret:     enrolment.Secret,
+		OtpauthUri: enrolment.URI,
+	}
+
+	return response, nil
+}
+
+func (h *UserHandler) ConfirmTotp(ctx context.Context, req *pb.ConfirmTotpRequest) (*pb.ConfirmTotpResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.MfaManage)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateConfirmTotpRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	recoveryCodes, err := h.MFA.Confirm(ctx, authPayload.UserName, req.GetCode())
+
+	if err != nil {
+		return nil, mfaError(err)
+	}
+
+	response := &pb.ConfirmTotpResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+
+	return response, nil
+}
+
+func (h *UserHandler) DisableTotp(ctx context.Context, req *pb.DisableTotpRequest) (*pb.DisableTotpResponse, error) {
+	authPayload, err := h.authorizeUser(ctx, rbac.MfaManage)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateDisableTotpRequest(req)
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if err := h.MFA.Disable(ctx, authPayload.UserName, req.GetCode()); err != nil {
+		return nil, mfaError(err)
+	}
+
+	return &pb.DisableTotpResponse{}, nil
+}
+
+func (h *UserHandler) GetUserLockout(ctx context.Context, req *pb.GetUserLockoutRequest) (*pb.GetUserLockoutResponse, error) {
+	_, err := h.authorizeUser(ctx, rbac.UserLockoutRead)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUserLockoutRequest(req.GetUserName())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if _, err := h.Store.GetUserByUserName(ctx, req.GetUserName()); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	lockout, err := h.Limiter.Status(ctx, throttle.Key{Scope: throttle.LoginUser, ID: req.GetUserName()})
+
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user lockout: %v", err)
+	}
+
+	response := &pb.GetUserLockoutResponse{
+		UserName: req.GetUserName(),
+		Failures: lockout.Failures,
+		Locked:   lockout.Locked,
+	}
+
+	if lockout.RetryAfter > 0 {
+		response.LockedUntil = timestamppb.New(time.Now().Add(lockout.RetryAfter))
+	}
+
+	return response, nil
+}
+
+func (h *UserHandler) UnlockUser(ctx context.Context, req *pb.UnlockUserRequest) (*pb.UnlockUserResponse, error) {
+	_, err := h.authorizeUser(ctx, rbac.UserLockoutReset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	violations := validateUserLockoutRequest(req.GetUserName())
+
+	if violations != nil {
+		return nil, myErr.InvalidAgrumentError(violations)
+	}
+
+	if _, err := h.Store.GetUserByUserName(ctx, req.GetUserName()); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+
+	if err := h.Limiter.Reset(ctx, throttle.Key{Scope: throttle.LoginUser, ID: req.GetUserName()}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to unlock user: %v", err)
+	}
+
+	return &pb.UnlockUserResponse{}, nil
+}
+
+// throttleError maps an error of the limiter to a gRPC status
+func throttleError(err error) error {
+	if errors.Is(err, throttle.ErrTooManyAttempts) {
+		return status.Errorf(codes.ResourceExhausted, "%s", err.Error())
+	}
+
+	return status.Errorf(codes.Internal, "failed to check failed attempts: %v", err)
+}
+
+// mfaError maps an error of the MFA authenticator to a gRPC status
+func mfaError(err error) error {
+	switch {
+	case errors.Is(err, mfa.ErrAlreadyEnabled):
+		return status.Errorf(codes.AlreadyExists, "%s", err.Error())
+	case errors.Is(err, mfa.ErrNotEnrolled), errors.Is(err, mfa.ErrNotEnabled):
+		return status.Errorf(codes.NotFound, "%s", err.Error())
+	case errors.Is(err, mfa.ErrInvalidCode), errors.Is(err, mfa.ErrInvalidChallenge), errors.Is(err, mfa.ErrTooManyAttempts):
+		return status.Errorf(codes.Unauthenticated, "%s", err.Error())
+	}
+
+	return status.Errorf(codes.Internal, "failed to check two-factor authentication: %v", err)
+}
+
+// oidcError maps an error of a SSO login to a gRPC status
+func oidcError(err error) error {
+	switch {
+	case errors.Is(err, oidc.ErrInvalidState), errors.Is(err, oidc.ErrInvalidIDToken):
+		return status.Errorf(codes.Unauthenticated, "%s", err.Error())
+	case errors.Is(err, oidc.ErrEmailRequired):
+		return status.Errorf(codes.FailedPrecondition, "%s", err.Error())
+	case errors.Is(err, oidc.ErrEmailInUse):
+		return status.Errorf(codes.AlreadyExists, "%s", err.Error())
+	case errors.Is(err, oidc.ErrProvider):
+		return status.Errorf(codes.Unavailable, "%s", err.Error())
+	}
+
+	return status.Errorf(codes.Internal, "failed to complete SSO login: %v", err)
+}
+
+// phoneError maps an error of phone verification to a gRPC status
+func phoneError(err error) error {
+	switch {
+	case errors.Is(err, phone.ErrNoPhone), errors.Is(err, phone.ErrAlreadyVerified), errors.Is(err, phone.ErrNotVerified):
+		return status.Errorf(codes.FailedPrecondition, "%s", err.Error())
+	case errors.Is(err, phone.ErrInvalidCode):
+		return status.Errorf(codes.InvalidArgument, "%s", err.Error())
+	case errors.Is(err, phone.ErrTooManyAttempts):
+		return status.Errorf(codes.ResourceExhausted, "%s", err.Error())
+	case errors.Is(err, phone.ErrPhoneAlreadyInUse):
+		return status.Errorf(codes.AlreadyExists, "%s", err.Error())
+	}
+
+	return status.Errorf(codes.Internal, "failed to verify phone: %v", err)
+}
+
+func validateCreateUserRequest(req *pb.CreateUserRequest, policy *validations.PasswordPolicy) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateUsername(req.GetUserName()); err != nil {
+		violations = append(violations, myErr.FieldViolation("userName", err))
+	}
+
+	if err := policy.ValidateFor(req.GetPassword(), req.GetUserName(), req.GetEmail()); err != nil {
+		violations = append(violations, myErr.FieldViolation("password", err))
+	}
+
+	if err := validations.ValidateFullName(req.GetFullName()); err != nil {
+		violations = append(violations, myErr.FieldViolation("fullName", err))
+	}
+
+	if err := validations.ValidateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, myErr.FieldViolation("email", err))
+	}
+
+	return violations
+}
+
+func validateLoginUserRequest(req *pb.LoginUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateUsername(req.GetUserName()); err != nil {
+		violations = append(violations, myErr.FieldViolation("userName", err))
+	}
+
+	// Only new passwords follow the policy, a stricter policy must not lock out existing users
+	if err := validations.ValidateString(req.GetPassword(), 1, validations.MaxPasswordLength); err != nil {
+		violations = append(violations, myErr.FieldViolation("password", err))
+	}
+
+	if err := validations.ValidateDeviceLabel(req.GetDeviceLabel()); err != nil {
+		violations = append(violations, myErr.FieldViolation("deviceLabel", err))
+	}
+
+	return violations
+}
+
+func validateVerifyLoginMfaRequest(req *pb.VerifyLoginMfaRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(req.GetChallengeToken()) == 0 {
+		violations = append(violations, myErr.FieldViolation("challengeToken", fmt.Errorf("challengeToken is required")))
+	}
+
+	if err := validations.ValidateMfaCode(req.GetCode()); err != nil {
+		violations = append(violations, myErr.FieldViolation("code", err))
+	}
+
+	return violations
+}
+
+func validateSendLoginSmsCodeRequest(req *pb.SendLoginSmsCodeRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(req.GetChallengeToken()) == 0 {
+		violations = append(violations, myErr.FieldViolation("challengeToken", fmt.Errorf("challengeToken is required")))
+	}
+
+	return violations
+}
+
+func validateBeginOidcLoginRequest(req *pb.BeginOidcLoginRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateDeviceLabel(req.GetDeviceLabel()); err != nil {
+		violations = append(violations, myErr.FieldViolation("deviceLabel", err))
+	}
+
+	return violations
+}
+
+func validateOidcCallbackRequest(req *pb.OidcCallbackRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(req.GetCode()) == 0 && len(req.GetError()) == 0 {
+		violations = append(violations, myErr.FieldViolation("code", fmt.Errorf("code is required")))
+	}
+
+	if len(req.GetState()) == 0 {
+		violations = append(violations, myErr.FieldViolation("state", fmt.Errorf("state is required")))
+	}
+
+	return violations
+}
+
+func validateVerifyPhoneRequest(req *pb.VerifyPhoneRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidatePhoneCode(req.GetCode()); err != nil {
+		violations = append(violations, myErr.FieldViolation("code", err))
+	}
+
+	return violations
+}
+
+func validateRefreshTokenRequest(req *pb.RefreshTokenRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(req.GetRefreshToken()) == 0 {
+		violations = append(violations, myErr.FieldViolation("refreshToken", fmt.Errorf("refreshToken is required")))
+	}
+
+	return violations
+}
+
+func validateChangePasswordRequest(req *pb.ChangePasswordRequest, policy *validations.PasswordPolicy) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateString(req.GetCurrentPassword(), 1, 100); err != nil {
+		violations = append(violations, myErr.FieldViolation("currentPassword", err))
+	}
+
+	if err := policy.Validate(req.GetNewPassword()); err != nil {
+		violations = append(violations, myErr.FieldViolation("newPassword", err))
+	}
+
+	return violations
+}
+
+func validateForgotPasswordRequest(req *pb.ForgotPasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, myErr.FieldViolation("email", err))
+	}
+
+	return violations
+}
+
+func validateResetPasswordRequest(req *pb.ResetPasswordRequest, policy *validations.PasswordPolicy) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(req.GetToken()) == 0 {
+		violations = append(violations, myErr.FieldViolation("token", fmt.Errorf("token is required")))
+	}
+
+	if err := policy.Validate(req.GetPassword()); err != nil {
+		violations = append(violations, myErr.FieldViolation("password", err))
+	}
+
+	return violations
+}
+
+func validateListLoginEventsRequest(req *pb.ListLoginEventsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidatePage(req.GetPage()); err != nil {
+		violations = append(violations, myErr.FieldViolation("page", err))
+	}
+
+	if err := validations.ValidatePageSize(req.GetSize()); err != nil {
+		violations = append(violations, myErr.FieldViolation("size", err))
+	}
+
+	return violations
+}
+
+func validateUploadKycDocumentRequest(req *pb.UploadKycDocumentRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateKycDocumentKind(req.GetKind()); err != nil {
+		violations = append(violations, myErr.FieldViolation("kind", err))
+	}
+
+	if err := validations.ValidateString(req.GetFileName(), 1, 255); err != nil {
+		violations = append(violations, myErr.FieldViolation("fileName", err))
+	}
+
+	if len(req.GetContentType()) == 0 {
+		violations = append(violations, myErr.FieldViolation("contentType", fmt.Errorf("contentType is required")))
+	}
+
+	return violations
+}
+
+func validateListKycDocumentsRequest(req *pb.ListKycDocumentsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidatePage(req.GetPage()); err != nil {
+		violations = append(violations, myErr.FieldViolation("page", err))
+	}
+
+	if err := validations.ValidatePageSize(req.GetSize()); err != nil {
+		violations = append(violations, myErr.FieldViolation("size", err))
+	}
+
+	return violations
+}
+
+func validateGetDenyLoginRequest(req *pb.GetDenyLoginRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(req.GetToken()) == 0 {
+		violations = append(violations, myErr.FieldViolation("token", fmt.Errorf("token is required")))
+	}
+
+	return violations
+}
+
+func validateDenyLoginRequest(req *pb.DenyLoginRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(req.GetToken()) == 0 {
+		violations = append(violations, myErr.FieldViolation("token", fmt.Errorf("token is required")))
+	}
+
+	return violations
+}
+
+func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateUsername(req.GetUserName()); err != nil {
+		violations = append(violations, myErr.FieldViolation("userName", err))
+	}
+
+	if req.FullName != nil {
+		if err := validations.ValidateFullName(req.GetFullName()); err != nil {
+			violations = append(violations, myErr.FieldViolation("fullName", err))
+		}
+	}
+
+	if req.Email != nil {
+		if err := validations.ValidateEmail(req.GetEmail()); err != nil {
+			violations = append(violations, myErr.FieldViolation("email", err))
+		}
+	}
+
+	if req.DateOfBirth != nil {
+		if _, err := validations.ValidateDateOfBirth(req.GetDateOfBirth()); err != nil {
+			violations = append(violations, myErr.FieldViolation("dateOfBirth", err))
+		}
+	}
+
+	if req.Phone != nil {
+		if err := validations.ValidatePhone(req.GetPhone()); err != nil {
+			violations = append(violations, myErr.FieldViolation("phone", err))
+		}
+	}
+
+	if req.Address != nil {
+		if err := validations.ValidateAddress(req.GetAddress()); err != nil {
+			violations = append(violations, myErr.FieldViolation("address", err))
+		}
+	}
+
+	if req.Nationality != nil {
+		if err := validations.ValidateNationality(req.GetNationality()); err != nil {
+			violations = append(violations, myErr.FieldViolation("nationality", err))
+		}
+	}
+
+	return violations
+}
+
+func validateVerifyUserEmailRequest(req *pb.VerifyUserEmailRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateEmailId(req.GetEmailId()); err != nil {
+		violations = append(violations, myErr.FieldViolation("emailId", err))
+	}
+
+	if err := validations.ValidateSecretCode(req.GetSecretCode()); err != nil {
+		violations = append(violations, myErr.FieldViolation("secretCode", err))
+	}
+
+	return violations
+}
+
+func validateUserLockoutRequest(userName string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateUsername(userName); err != nil {
+		violations = append(violations, myErr.FieldViolation("userName", err))
+	}
+
+	return violations
+}
+
+func validateConfirmTotpRequest(req *pb.ConfirmTotpRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateTotpCode(req.GetCode()); err != nil {
+		violations = append(violations, myErr.FieldViolation("code", err))
+	}
+
+	return violations
+}
+
+func validateStepUpRequest(req *pb.StepUpRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.Password != nil {
+		if err := validations.ValidateString(req.GetPassword(), 1, validations.MaxPasswordLength); err != nil {
+			violations = append(violations, myErr.FieldViolation("password", err))
+		}
+	}
+
+	if req.Code != nil {
+		if err := validations.ValidateMfaCode(req.GetCode()); err != nil {
+			violations = append(violations, myErr.FieldViolation("code", err))
+		}
+	}
+
+	return violations
+}
+
+func validateDisableTotpRequest(req *pb.DisableTotpRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validations.ValidateMfaCode(req.GetCode()); err != nil {
+		violations = append(violations, myErr.FieldViolation("code", err))
 	}
 
 	return violations
@@ -3,7 +3,11 @@ package user
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,18 +15,34 @@ import (
 	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
 	"github.com/ChokeGuy/simple-bank/pb"
+	"github.com/ChokeGuy/simple-bank/pkg/audit"
 	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/ChokeGuy/simple-bank/pkg/loginhistory"
+	"github.com/ChokeGuy/simple-bank/pkg/mfa"
+	"github.com/ChokeGuy/simple-bank/pkg/oidc"
+	"github.com/ChokeGuy/simple-bank/pkg/oidc/oidctest"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/session"
+	"github.com/ChokeGuy/simple-bank/pkg/throttle"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/pkg/token/paseto"
+	"github.com/ChokeGuy/simple-bank/pkg/totp"
 	server "github.com/ChokeGuy/simple-bank/server/grpc"
 	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/ChokeGuy/simple-bank/util/password"
 	"github.com/ChokeGuy/simple-bank/worker"
 	mockwk "github.com/ChokeGuy/simple-bank/worker/mock"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
@@ -60,19 +80,10 @@ func TestCreateUserApi(t *testing.T) {
 					Times(1).
 					Return(db.CreateUserTxResult{User: user}, nil)
 
-				//Send verification email to user
-				taskPayload := &worker.PayloadSendVerifyEmail{
-					UserName: user.Username,
-				}
-
+				// the task is published by the outbox relay, never by the handler
 				taskDistributor.EXPECT().
-					DistributeTaskSendVerifyEmail(
-						gomock.Any(),
-						EqPayloadSendVerifyEmail(taskPayload),
-						gomock.Any(),
-					).
-					Times(1).
-					Return(nil)
+					DistributeTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
 
 			},
 			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
@@ -102,11 +113,8 @@ func TestCreateUserApi(t *testing.T) {
 					Return(db.CreateUserTxResult{}, sql.ErrConnDone)
 
 				taskDistributor.EXPECT().
-					DistributeTaskSendVerifyEmail(
-						gomock.Any(),
-						gomock.Any(),
-						gomock.Any(),
-					).Times(0)
+					DistributeTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
 				require.Error(t, err)
@@ -120,11 +128,8 @@ func TestCreateUserApi(t *testing.T) {
 			body: &pb.CreateUserRequest{},
 			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
 				taskDistributor.EXPECT().
-					DistributeTaskSendVerifyEmail(
-						gomock.Any(),
-						gomock.Any(),
-						gomock.Any(),
-					).Times(0)
+					DistributeTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
 				require.Error(t, err)
@@ -147,7 +152,7 @@ func TestCreateUserApi(t *testing.T) {
 					Return(db.CreateUserTxResult{}, db.ErrUniqueViolation)
 
 				taskDistributor.EXPECT().
-					DistributeTaskSendVerifyEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					DistributeTask(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
@@ -188,9 +193,22 @@ func TestCreateUserApi(t *testing.T) {
 	}
 }
 
+// requireArgon2idHash checks that a password was rehashed with argon2id
+func requireArgon2idHash(t *testing.T, plainPassword string, hashedPassword string) {
+	require.True(t, strings.HasPrefix(hashedPassword, "$argon2id$"))
+	require.NoError(t, password.CheckPassword(plainPassword, hashedPassword))
+}
+
 func TestLoginUserApi(t *testing.T) {
 	user, password := RandomUser(t)
 
+	// Hashes from before argon2id are upgraded on login, the login succeeds even if that fails
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
 	testCases := []struct {
 		name          string
 		body          *pb.LoginUserRequest
@@ -200,8 +218,9 @@ func TestLoginUserApi(t *testing.T) {
 		{
 			name: "OK",
 			body: &pb.LoginUserRequest{
-				UserName: user.Username,
-				Password: password,
+				UserName:    user.Username,
+				Password:    password,
+				DeviceLabel: "Work laptop",
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -218,23 +237,21 @@ func TestLoginUserApi(t *testing.T) {
 					}, nil)
 
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetSessionByUserNameRow{}, db.ErrRecordNotFound)
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), EqCreateSessionTxParams(user.Username, "Work laptop", cfg.MaxSessionsPerUser)).
 					Times(1).
-					Return(db.Session{
-						ID:           uuid.New(),
-						Username:     user.Username,
-						RefreshToken: gomock.Any().String(),
-						UserAgent:    gomock.Any().String(),
-						ClientIp:     gomock.Any().String(),
-						IsBlocked:    false,
-						ExpiresAt:    time.Now().Add(24 * time.Hour),
+					Return(db.CreateSessionTxResult{
+						Session: db.Session{
+							ID:          uuid.New(),
+							Username:    user.Username,
+							DeviceLabel: "Work laptop",
+							ExpiresAt:   time.Now().Add(24 * time.Hour),
+						},
 					}, nil)
-
 			},
 			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
 				require.NoError(t, err)
@@ -248,7 +265,7 @@ func TestLoginUserApi(t *testing.T) {
 			},
 		},
 		{
-			name: "InternalError",
+			name: "RehashBcryptPassword",
 			body: &pb.LoginUserRequest{
 				UserName: user.Username,
 				Password: password,
@@ -257,35 +274,34 @@ func TestLoginUserApi(t *testing.T) {
 				store.EXPECT().
 					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetUserByUserNameRow{
-						Username:          user.Username,
-						FullName:          user.FullName,
-						HashedPassword:    user.HashedPassword,
-						Email:             user.Email,
-						IsEmailVerified:   user.IsEmailVerified,
-						PasswordChangedAt: user.PasswordChangedAt,
-						CreatedAt:         user.CreatedAt,
-					}, nil)
+					Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: string(bcryptHash)}, nil)
+
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						requireArgon2idHash(t, password, arg.HashedPassword.String)
+						return db.User{}, nil
+					})
 
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetSessionByUserNameRow{}, db.ErrRecordNotFound)
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Session{}, sql.ErrConnDone)
+					Return(db.CreateSessionTxResult{Session: db.Session{ID: uuid.New(), Username: user.Username}}, nil)
 			},
 			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
-				require.Error(t, err)
-				st, ok := status.FromError(err)
-				require.True(t, ok)
-				require.Equal(t, codes.Internal, st.Code())
+				require.NoError(t, err)
+				require.NotNil(t, res)
 			},
 		},
 		{
-			name: "GetUserInternalError",
+			name: "RehashFailed",
 			body: &pb.LoginUserRequest{
 				UserName: user.Username,
 				Password: password,
@@ -294,41 +310,34 @@ func TestLoginUserApi(t *testing.T) {
 				store.EXPECT().
 					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetUserByUserNameRow{}, sql.ErrConnDone)
+					Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: string(bcryptHash)}, nil)
 
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
-					Times(0)
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						requireArgon2idHash(t, password, arg.HashedPassword.String)
+						return db.User{}, sql.ErrConnDone
+					})
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
-					Times(0)
-			},
-			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
-				require.Error(t, err)
-				st, ok := status.FromError(err)
-				require.True(t, ok)
-				require.Equal(t, codes.Internal, st.Code())
-			},
-		},
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
 
-		{
-			name: "BadRequest",
-			body: &pb.LoginUserRequest{},
-			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
-					Times(0)
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateSessionTxResult{Session: db.Session{ID: uuid.New(), Username: user.Username}}, nil)
 			},
 			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
-				require.Error(t, err)
-				st, ok := status.FromError(err)
-				require.True(t, ok)
-				require.Equal(t, codes.InvalidArgument, st.Code())
+				require.NoError(t, err)
+				require.NotNil(t, res)
 			},
 		},
 		{
-			name: "UserNotFound",
+			name: "InternalError",
 			body: &pb.LoginUserRequest{
 				UserName: user.Username,
 				Password: password,
@@ -337,25 +346,35 @@ func TestLoginUserApi(t *testing.T) {
 				store.EXPECT().
 					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+					Return(db.GetUserByUserNameRow{
+						Username:          user.Username,
+						FullName:          user.FullName,
+						HashedPassword:    user.HashedPassword,
+						Email:             user.Email,
+						IsEmailVerified:   user.IsEmailVerified,
+						PasswordChangedAt: user.PasswordChangedAt,
+						CreatedAt:         user.CreatedAt,
+					}, nil)
 
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Any()).
-					Times(0)
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
-					Times(0)
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateSessionTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
 				require.Error(t, err)
 				st, ok := status.FromError(err)
 				require.True(t, ok)
-				require.Equal(t, codes.NotFound, st.Code())
+				require.Equal(t, codes.Internal, st.Code())
 			},
 		},
 		{
-			name: "SessionBlocked",
+			name: "GetUserInternalError",
 			body: &pb.LoginUserRequest{
 				UserName: user.Username,
 				Password: password,
@@ -364,33 +383,22 @@ func TestLoginUserApi(t *testing.T) {
 				store.EXPECT().
 					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetUserByUserNameRow{
-						Username:       user.Username,
-						HashedPassword: user.HashedPassword,
-					}, nil)
-
-				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
-					Times(1).
-					Return(db.GetSessionByUserNameRow{
-						ID:        uuid.New(),
-						IsBlocked: true,
-						ExpiresAt: time.Now().Add(time.Hour),
-					}, nil)
+					Return(db.GetUserByUserNameRow{}, sql.ErrConnDone)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
 				require.Error(t, err)
 				st, ok := status.FromError(err)
 				require.True(t, ok)
-				require.Equal(t, codes.PermissionDenied, st.Code())
+				require.Equal(t, codes.Internal, st.Code())
 			},
 		},
+
 		{
-			name: "SessionExpired",
+			name: "ExistingSessionEvicted",
 			body: &pb.LoginUserRequest{
 				UserName: user.Username,
 				Password: password,
@@ -404,30 +412,21 @@ func TestLoginUserApi(t *testing.T) {
 						HashedPassword: user.HashedPassword,
 					}, nil)
 
-				expiredSession := db.GetSessionByUserNameRow{
-					ID:        uuid.New(),
-					IsBlocked: false,
-					ExpiresAt: time.Now().Add(-time.Hour), // Expired 1 hour ago
-				}
-
-				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
-					Times(1).
-					Return(expiredSession, nil)
-
 				store.EXPECT().
-					DeleteSession(gomock.Any(), expiredSession.ID).
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(nil)
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), EqCreateSessionTxParams(user.Username, "", cfg.MaxSessionsPerUser)).
 					Times(1).
-					Return(db.Session{
-						ID:           uuid.New(),
-						Username:     user.Username,
-						RefreshToken: gomock.Any().String(),
-						ExpiresAt:    time.Now().Add(24 * time.Hour),
+					Return(db.CreateSessionTxResult{
+						Session: db.Session{
+							ID:        uuid.New(),
+							Username:  user.Username,
+							ExpiresAt: time.Now().Add(24 * time.Hour),
+						},
+						Evicted: []uuid.UUID{uuid.New()},
 					}, nil)
 			},
 			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
@@ -439,7 +438,41 @@ func TestLoginUserApi(t *testing.T) {
 			},
 		},
 		{
-			name: "DeleteSessionError",
+			name: "DeviceLabelTooLong",
+			body: &pb.LoginUserRequest{
+				UserName:    user.Username,
+				Password:    password,
+				DeviceLabel: util.RandomString(65),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "BadRequest",
+			body: &pb.LoginUserRequest{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "UserNotFound",
 			body: &pb.LoginUserRequest{
 				UserName: user.Username,
 				Password: password,
@@ -448,43 +481,24 @@ func TestLoginUserApi(t *testing.T) {
 				store.EXPECT().
 					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.GetUserByUserNameRow{
-						Username:       user.Username,
-						HashedPassword: user.HashedPassword,
-					}, nil)
-
-				expiredSession := db.GetSessionByUserNameRow{
-					ID:        uuid.New(),
-					IsBlocked: false,
-					ExpiresAt: time.Now().Add(-time.Hour),
-				}
-
-				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
-					Times(1).
-					Return(expiredSession, nil)
-
-				store.EXPECT().
-					DeleteSession(gomock.Any(), expiredSession.ID).
-					Times(1).
-					Return(sql.ErrConnDone)
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
 				require.Error(t, err)
 				st, ok := status.FromError(err)
 				require.True(t, ok)
-				require.Equal(t, codes.Internal, st.Code())
+				require.Equal(t, codes.NotFound, st.Code())
 			},
 		},
 		{
-			name: "SessionAlreadyExists",
+			name: "InvalidPassword",
 			body: &pb.LoginUserRequest{
 				UserName: user.Username,
-				Password: password,
+				Password: util.RandomPassword(),
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -495,33 +509,28 @@ func TestLoginUserApi(t *testing.T) {
 						HashedPassword: user.HashedPassword,
 					}, nil)
 
-				activeSession := db.GetSessionByUserNameRow{
-					ID:        uuid.New(),
-					IsBlocked: false,
-					ExpiresAt: time.Now().Add(time.Hour),
-				}
-
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					CreateLoginEvent(gomock.Any(), EqLoginFailure(user.Username, loginhistory.ReasonInvalidPassword)).
 					Times(1).
-					Return(activeSession, nil)
+					Return(db.LoginEvent{}, nil)
 
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
 				require.Error(t, err)
 				st, ok := status.FromError(err)
 				require.True(t, ok)
-				require.Equal(t, codes.PermissionDenied, st.Code())
+				require.Equal(t, codes.InvalidArgument, st.Code())
 			},
 		},
 		{
-			name: "InvalidPassword",
+			name: "MfaRequired",
 			body: &pb.LoginUserRequest{
-				UserName: user.Username,
-				Password: util.RandomPassword(),
+				UserName:    user.Username,
+				Password:    password,
+				DeviceLabel: "Work laptop",
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -532,19 +541,29 @@ func TestLoginUserApi(t *testing.T) {
 						HashedPassword: user.HashedPassword,
 					}, nil)
 
+				userTotp, _ := RandomUserTotp(t, user.Username, true)
+
 				store.EXPECT().
-					GetSessionByUserName(gomock.Any(), gomock.Any()).
-					Times(0)
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					CreateMfaChallengeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateMfaChallengeTxResult{}, nil)
 
+				// No session is created before the second factor is checked
 				store.EXPECT().
-					CreateSession(gomock.Any(), gomock.Any()).
+					CreateSessionTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
-				require.Error(t, err)
-				st, ok := status.FromError(err)
-				require.True(t, ok)
-				require.Equal(t, codes.InvalidArgument, st.Code())
+				require.NoError(t, err)
+				require.True(t, res.GetMfaRequired())
+				require.NotEmpty(t, res.GetMfaChallengeToken())
+				require.Empty(t, res.GetAccessToken())
+				require.Empty(t, res.GetRefreshToken())
 			},
 		},
 	}
@@ -573,7 +592,8 @@ func TestLoginUserApi(t *testing.T) {
 	}
 }
 
-// Helper function to add authorization metadata to context
+// Helper function to add authorization metadata to context, the token is the one of a user
+// who just logged in with their password unless opts say otherwise
 func addAuthorizationMetadata(
 	ctx context.Context,
 	t *testing.T,
@@ -581,8 +601,10 @@ func addAuthorizationMetadata(
 	username string,
 	role string,
 	duration time.Duration,
+	opts ...token.PayloadOption,
 ) context.Context {
-	token, payload, err := tokenMaker.CreateToken(username, role, duration)
+	opts = append([]token.PayloadOption{token.WithAuth(time.Now(), token.AuthStrengthPassword)}, opts...)
+	token, payload, err := tokenMaker.CreateToken(username, role, uuid.New(), duration, opts...)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -634,9 +656,14 @@ func TestUpdateUserApi(t *testing.T) {
 				}
 
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, KycStatus: kyc.Unverified}, nil)
+
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), EqUpdateUserTxParams(arg)).
 					Times(1).
-					Return(user, nil)
+					Return(db.UpdateUserTxResult{User: user}, nil)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
 				require.NoError(t, err)
@@ -648,61 +675,89 @@ func TestUpdateUserApi(t *testing.T) {
 			},
 		},
 		{
-			name: "NoAuthorization",
+			name: "BankerUpdateIsAudited",
 			body: &pb.UpdateUserRequest{
 				UserName: user.Username,
-				FullName: &user.FullName,
-				Email:    &user.Email,
+				Email:    proto.String("new" + user.Email),
 			},
 			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
-				return context.Background()
+				return addAuthorizationMetadata(
+					context.Background(),
+					t,
+					tokenMaker,
+					"banker",
+					util.BankerRole,
+					time.Minute,
+				)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
-					Times(0)
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserTxParams, _ ...db.TxOption) (db.UpdateUserTxResult, error) {
+						require.NotNil(t, arg.AuditLog)
+						require.Equal(t, "banker", arg.AuditLog.Actor)
+						require.Equal(t, audit.UpdateUser, arg.AuditLog.Action)
+						require.Equal(t, user.Username, arg.AuditLog.Target)
+						require.JSONEq(t, fmt.Sprintf(`{"fields":["email"],"email":"new%s"}`, user.Email), string(arg.AuditLog.Metadata))
+						return db.UpdateUserTxResult{User: user, EmailChanged: true}, nil
+					})
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
-				require.Error(t, err)
-				st, ok := status.FromError(err)
-				require.True(t, ok)
-				require.Equal(t, codes.Unauthenticated, st.Code())
+				require.NoError(t, err)
+				require.Equal(t, user.Username, res.GetUser().UserName)
 			},
 		},
 		{
-			name: "UnauthorizedUser",
+			name: "ProfileChangeResetsKyc",
 			body: &pb.UpdateUserRequest{
-				UserName: user.Username,
-				FullName: &user.FullName,
-				Email:    &user.Email,
+				UserName:    user.Username,
+				DateOfBirth: proto.String("1990-01-02"),
+				Phone:       proto.String("+84901234567"),
 			},
 			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
 				return addAuthorizationMetadata(
 					context.Background(),
 					t,
 					tokenMaker,
-					"unauthorized_user",
+					user.Username,
 					user.Role,
 					time.Minute,
 				)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
-					Times(0)
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Phone: "+84900000000", KycStatus: kyc.Verified}, nil)
+
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserTxParams, _ ...db.TxOption) (db.UpdateUserTxResult, error) {
+						require.Equal(t, "1990-01-02", arg.DateOfBirth.Time.Format(time.DateOnly))
+						require.Equal(t, pgtype.Text{String: "+84901234567", Valid: true}, arg.Phone)
+						require.Equal(t, pgtype.Text{String: kyc.Unverified, Valid: true}, arg.KycStatus)
+						require.NotNil(t, arg.KycReview)
+						require.Equal(t, kyc.Verified, arg.KycReview.FromStatus)
+
+						updated := user
+						updated.KycStatus = kyc.Unverified
+						return db.UpdateUserTxResult{User: updated}, nil
+					})
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
-				require.Error(t, err)
-				st, ok := status.FromError(err)
-				require.True(t, ok)
-				require.Equal(t, codes.PermissionDenied, st.Code())
+				require.NoError(t, err)
+				require.Equal(t, kyc.Unverified, res.GetUser().GetKycStatus())
 			},
 		},
 		{
-			name: "InvalidEmail",
+			name: "InvalidProfile",
 			body: &pb.UpdateUserRequest{
-				UserName: user.Username,
-				Email:    proto.String("invalid-email"),
+				UserName:    user.Username,
+				DateOfBirth: proto.String(time.Now().AddDate(-10, 0, 0).Format(time.DateOnly)),
+				Phone:       proto.String("0901234567"),
+				Nationality: proto.String("XX"),
 			},
 			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
 				return addAuthorizationMetadata(
@@ -716,21 +771,27 @@ func TestUpdateUserApi(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
+					UpdateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
-				require.Error(t, err)
 				st, ok := status.FromError(err)
 				require.True(t, ok)
 				require.Equal(t, codes.InvalidArgument, st.Code())
+
+				var fields []string
+				for _, detail := range st.Details() {
+					for _, violation := range detail.(*errdetails.BadRequest).GetFieldViolations() {
+						fields = append(fields, violation.GetField())
+					}
+				}
+				require.ElementsMatch(t, []string{"dateOfBirth", "phone", "nationality"}, fields)
 			},
 		},
 		{
-			name: "ExpiredToken",
+			name: "UpdateEmailNeedsStepUp",
 			body: &pb.UpdateUserRequest{
 				UserName: user.Username,
-				FullName: &user.FullName,
 				Email:    &user.Email,
 			},
 			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
@@ -740,12 +801,32 @@ func TestUpdateUserApi(t *testing.T) {
 					tokenMaker,
 					user.Username,
 					user.Role,
-					-time.Minute, // Expired token
+					time.Minute,
+					token.WithAuth(time.Now().Add(-time.Hour), token.AuthStrengthPassword),
 				)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: &pb.UpdateUserRequest{
+				UserName: user.Username,
+				FullName: &user.FullName,
+				Email:    &user.Email,
+			},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
@@ -756,7 +837,7 @@ func TestUpdateUserApi(t *testing.T) {
 			},
 		},
 		{
-			name: "UserNotFound",
+			name: "UnauthorizedUser",
 			body: &pb.UpdateUserRequest{
 				UserName: user.Username,
 				FullName: &user.FullName,
@@ -767,28 +848,106 @@ func TestUpdateUserApi(t *testing.T) {
 					context.Background(),
 					t,
 					tokenMaker,
-					user.Username,
+					"unauthorized_user",
+					user.Role,
+					time.Minute,
+				)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "InvalidEmail",
+			body: &pb.UpdateUserRequest{
+				UserName: user.Username,
+				Email:    proto.String("invalid-email"),
+			},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(
+					context.Background(),
+					t,
+					tokenMaker,
+					user.Username,
+					user.Role,
+					time.Minute,
+				)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "ExpiredToken",
+			body: &pb.UpdateUserRequest{
+				UserName: user.Username,
+				FullName: &user.FullName,
+				Email:    &user.Email,
+			},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(
+					context.Background(),
+					t,
+					tokenMaker,
+					user.Username,
+					user.Role,
+					-time.Minute, // Expired token
+				)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: &pb.UpdateUserRequest{
+				UserName: user.Username,
+				FullName: &user.FullName,
+				Email:    &user.Email,
+			},
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(
+					context.Background(),
+					t,
+					tokenMaker,
+					user.Username,
 					user.Role,
 					time.Minute,
 				)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.UpdateUserParams{
-					Username: user.Username,
-					FullName: pgtype.Text{
-						String: user.FullName,
-						Valid:  true,
-					},
-					Email: pgtype.Text{
-						String: user.Email,
-						Valid:  true,
-					},
-				}
-
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
-					Return(db.User{}, db.ErrRecordNotFound)
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
 				require.Error(t, err)
@@ -816,9 +975,14 @@ func TestUpdateUserApi(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, KycStatus: kyc.Unverified}, nil)
+
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.User{}, sql.ErrConnDone)
+					Return(db.UpdateUserTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
 				require.Error(t, err)
@@ -846,7 +1010,7 @@ func TestUpdateUserApi(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
+					UpdateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
@@ -904,7 +1068,7 @@ func TestVerifyUserEmailApi(t *testing.T) {
 				}
 
 				store.EXPECT().
-					VerifyUserEmailTx(gomock.Any(), gomock.Eq(arg)).
+					VerifyUserEmailTx(gomock.Any(), EqVerifyUserEmailTxParams(arg, user)).
 					Times(1).
 					Return(db.VerifyUserEmailTxResult{
 						User: db.User{
@@ -1011,3 +1175,2981 @@ func TestVerifyUserEmailApi(t *testing.T) {
 		})
 	}
 }
+
+func TestLogoutUserApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	sessionID := uuid.New()
+
+	activeSession := db.GetSessionByIdRow{
+		ID:         sessionID,
+		Username:   user.Username,
+		ExpiresAt:  time.Now().Add(time.Hour),
+		LastSeenAt: time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		setupContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.LogoutUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addSessionAuthorizationMetadata(t, tokenMaker, user, sessionID)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(activeSession, nil)
+
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, sessionID.String(), res.GetSessionID())
+			},
+		},
+		{
+			name: "RevokedSession",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addSessionAuthorizationMetadata(t, tokenMaker, user, sessionID)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				revokedSession := activeSession
+				revokedSession.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(revokedSession, nil)
+
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "BlockedSession",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addSessionAuthorizationMetadata(t, tokenMaker, user, sessionID)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				blockedSession := activeSession
+				blockedSession.IsBlocked = true
+
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(blockedSession, nil)
+
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "InternalError",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addSessionAuthorizationMetadata(t, tokenMaker, user, sessionID)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(activeSession, nil)
+
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			server.SessionChecker = session.NewCachedChecker(store, time.Minute)
+			userHandler := NewUserHandler(server)
+
+			ctx := tc.setupContext(t, server.TokenMaker)
+			res, err := userHandler.LogoutUser(ctx, &pb.LogoutUserRequest{})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+// addSessionAuthorizationMetadata authorizes the context with an access token bound to sessionID
+func addSessionAuthorizationMetadata(t *testing.T, tokenMaker token.Maker, user db.User, sessionID uuid.UUID) context.Context {
+	token, _, err := tokenMaker.CreateToken(user.Username, user.Role, sessionID, time.Minute)
+	require.NoError(t, err)
+
+	md := metadata.New(map[string]string{
+		consts.AuthorizationHeader: fmt.Sprintf("%s %s", consts.AuthorizationType, token),
+	})
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestRefreshTokenApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	sessionID := uuid.New()
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	tokenMaker, err := paseto.NewPasetoMaker(cfg.SymetricKey)
+	require.NoError(t, err)
+
+	refreshToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, sessionID, time.Hour, token.WithTokenType(token.TokenTypeRefresh))
+	require.NoError(t, err)
+
+	accessToken, _, err := tokenMaker.CreateToken(user.Username, user.Role, sessionID, time.Hour)
+	require.NoError(t, err)
+
+	activeSession := db.GetSessionByIdRow{
+		ID:           sessionID,
+		Username:     user.Username,
+		RefreshToken: token.HashToken(refreshToken),
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		body          *pb.RefreshTokenRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.RefreshTokenResponse, err error)
+	}{
+		{
+			name: "OK",
+			body: &pb.RefreshTokenRequest{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(activeSession, nil)
+
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), EqRotateRefreshTokenTxParams(sessionID, refreshToken)).
+					Times(1).
+					Return(db.RotateRefreshTokenTxResult{Session: db.Session{ID: sessionID}}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.RefreshTokenResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetAccessToken())
+				require.NotEmpty(t, res.GetRefreshToken())
+				require.NotEqual(t, refreshToken, res.GetRefreshToken())
+
+				// The rotated token must not outlive the session
+				require.False(t, res.GetRefreshTokenExpiresAt().AsTime().After(activeSession.ExpiresAt))
+			},
+		},
+		{
+			name: "IssuedBeforePasswordChange",
+			body: &pb.RefreshTokenRequest{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				changed := activeSession
+				changed.PasswordChangedAt = time.Now().Add(2 * time.Second)
+
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(changed, nil)
+
+				// A token from before the change is rejected, not treated as reused
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.RefreshTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "ReusedRefreshToken",
+			body: &pb.RefreshTokenRequest{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(activeSession, nil)
+
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), EqRotateRefreshTokenTxParams(sessionID, refreshToken)).
+					Times(1).
+					Return(db.RotateRefreshTokenTxResult{Reused: true}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.RefreshTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "RevokedSession",
+			body: &pb.RefreshTokenRequest{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				revokedSession := activeSession
+				revokedSession.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(revokedSession, nil)
+
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.RefreshTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "SessionNotFound",
+			body: &pb.RefreshTokenRequest{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.RefreshTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "AccessToken",
+			body: &pb.RefreshTokenRequest{RefreshToken: accessToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.RefreshTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "InvalidRefreshToken",
+			body: &pb.RefreshTokenRequest{RefreshToken: "invalid_token"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.RefreshTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "BadRequest",
+			body: &pb.RefreshTokenRequest{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.RefreshTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "InternalError",
+			body: &pb.RefreshTokenRequest{RefreshToken: refreshToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(activeSession, nil)
+
+				store.EXPECT().
+					RotateRefreshTokenTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RotateRefreshTokenTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.RefreshTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+
+			res, err := userHandler.RefreshToken(context.Background(), tc.body)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestVerifyLoginMfaApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	userTotp, secret := RandomUserTotp(t, user.Username, true)
+	challengeToken := util.RandomString(43)
+
+	challenge := db.MfaChallenge{
+		ID:          util.RandomInt(1, 1000),
+		Username:    user.Username,
+		TokenHash:   token.HashToken(challengeToken),
+		DeviceLabel: "Work laptop",
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	validCode := func(t *testing.T) string {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+		return code
+	}
+
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: user.Username}
+
+	testCases := []struct {
+		name          string
+		body          func(t *testing.T) *pb.VerifyLoginMfaRequest
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.LoginUserResponse, err error, limiter throttle.Limiter)
+	}{
+		{
+			name: "OK",
+			body: func(t *testing.T) *pb.VerifyLoginMfaRequest {
+				return &pb.VerifyLoginMfaRequest{ChallengeToken: challengeToken, Code: validCode(t)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Eq(db.IncrementMfaChallengeAttemptsParams{ID: challenge.ID, MaxAttempts: mfa.MaxChallengeAttempts})).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+
+				store.EXPECT().
+					ConsumeMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Role: user.Role}, nil)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), EqCreateSessionTxParams(user.Username, "Work laptop", cfg.MaxSessionsPerUser)).
+					Times(1).
+					Return(db.CreateSessionTxResult{Session: db.Session{ID: uuid.New(), Username: user.Username}}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error, limiter throttle.Limiter) {
+				require.NoError(t, err)
+				require.False(t, res.GetMfaRequired())
+				require.NotEmpty(t, res.GetAccessToken())
+				require.NotEmpty(t, res.GetRefreshToken())
+
+				userStatus, err := limiter.Status(context.Background(), userKey)
+				require.NoError(t, err)
+				require.Zero(t, userStatus.Failures)
+			},
+		},
+		{
+			name: "CodeReplayed",
+			body: func(t *testing.T) *pb.VerifyLoginMfaRequest {
+				return &pb.VerifyLoginMfaRequest{ChallengeToken: challengeToken, Code: validCode(t)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Eq(db.IncrementMfaChallengeAttemptsParams{ID: challenge.ID, MaxAttempts: mfa.MaxChallengeAttempts})).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userTotp, nil)
+
+				store.EXPECT().
+					UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), EqLoginFailure(user.Username, loginhistory.ReasonInvalidMfaCode)).
+					Times(1).
+					Return(db.LoginEvent{}, nil)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error, limiter throttle.Limiter) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+
+				// Wrong codes count against the login throttle of the user like wrong passwords
+				userStatus, err := limiter.Status(context.Background(), userKey)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), userStatus.Failures)
+			},
+		},
+		{
+			name: "UserLocked",
+			body: func(t *testing.T) *pb.VerifyLoginMfaRequest {
+				return &pb.VerifyLoginMfaRequest{ChallengeToken: challengeToken, Code: validCode(t)}
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				for i := int64(0); i < cfg.LoginMaxFailures; i++ {
+					require.NoError(t, limiter.Fail(context.Background(), userKey))
+				}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(challenge, nil)
+
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error, limiter throttle.Limiter) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.ResourceExhausted, st.Code())
+			},
+		},
+		{
+			name: "TooManyAttempts",
+			body: func(t *testing.T) *pb.VerifyLoginMfaRequest {
+				return &pb.VerifyLoginMfaRequest{ChallengeToken: challengeToken, Code: validCode(t)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				exhausted := challenge
+				exhausted.Attempts = mfa.MaxChallengeAttempts
+
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+					Times(1).
+					Return(exhausted, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error, limiter throttle.Limiter) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "InvalidArgument",
+			body: func(t *testing.T) *pb.VerifyLoginMfaRequest {
+				return &pb.VerifyLoginMfaRequest{Code: validCode(t)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMfaChallengeByTokenHash(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error, limiter throttle.Limiter) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			if tc.setupLimiter != nil {
+				tc.setupLimiter(t, server.Limiter)
+			}
+
+			userHandler := NewUserHandler(server)
+			res, err := userHandler.VerifyLoginMfa(context.Background(), tc.body(t))
+
+			tc.checkResponse(t, res, err, server.Limiter)
+		})
+	}
+}
+
+func TestTotpApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	pendingTotp, pendingSecret := RandomUserTotp(t, user.Username, false)
+	enabledTotp, enabledSecret := RandomUserTotp(t, user.Username, true)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	codeOf := func(t *testing.T, secret string) string {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+		return code
+	}
+
+	testCases := []struct {
+		name       string
+		call       func(t *testing.T, ctx context.Context, handler *UserHandler) (proto.Message, error)
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "EnrollOK",
+			call: func(t *testing.T, ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				res, err := handler.EnrollTotp(ctx, &pb.EnrollTotpRequest{})
+				if err == nil {
+					require.NotEmpty(t, res.GetSecret())
+					require.Contains(t, res.GetOtpauthUri(), res.GetSecret())
+				}
+				return res, err
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertPendingUserTotp(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pendingTotp, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "EnrollAlreadyEnabled",
+			call: func(t *testing.T, ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				return handler.EnrollTotp(ctx, &pb.EnrollTotpRequest{})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertPendingUserTotp(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			code: codes.AlreadyExists,
+		},
+		{
+			name: "ConfirmOK",
+			call: func(t *testing.T, ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				res, err := handler.ConfirmTotp(ctx, &pb.ConfirmTotpRequest{Code: codeOf(t, pendingSecret)})
+				if err == nil {
+					require.Len(t, res.GetRecoveryCodes(), mfa.RecoveryCodeCount)
+				}
+				return res, err
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pendingTotp, nil)
+
+				store.EXPECT().
+					ConfirmTotpTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConfirmTotpTxResult{UserTotp: enabledTotp}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "ConfirmInvalidArgument",
+			call: func(t *testing.T, ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				return handler.ConfirmTotp(ctx, &pb.ConfirmTotpRequest{Code: "12ab"})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "DisableOK",
+			call: func(t *testing.T, ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				return handler.DisableTotp(ctx, &pb.DisableTotpRequest{Code: codeOf(t, enabledSecret)})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(enabledTotp, nil)
+
+				store.EXPECT().
+					UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+
+				store.EXPECT().
+					DisableTotpTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "DisableNotEnabled",
+			call: func(t *testing.T, ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				return handler.DisableTotp(ctx, &pb.DisableTotpRequest{Code: codeOf(t, pendingSecret)})
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pendingTotp, nil)
+
+				store.EXPECT().
+					DisableTotpTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			code: codes.NotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			ctx := addAuthorizationMetadata(context.Background(), t, server.TokenMaker, user.Username, user.Role, time.Minute)
+
+			_, err := tc.call(t, ctx, userHandler)
+
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			require.Equal(t, tc.code, st.Code())
+		})
+	}
+}
+
+func TestStepUpApi(t *testing.T) {
+	user, password := RandomUser(t)
+	enabledTotp, enabledSecret := RandomUserTotp(t, user.Username, true)
+
+	userRow := db.GetUserByUserNameRow{
+		Username:       user.Username,
+		Role:           user.Role,
+		HashedPassword: user.HashedPassword,
+	}
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	codeOf := func(t *testing.T, secret string) string {
+		code, err := totp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+		return code
+	}
+
+	testCases := []struct {
+		name        string
+		req         func(t *testing.T) *pb.StepUpRequest
+		minStrength string
+		buildStubs  func(store *mockdb.MockStore)
+		strength    string
+		code        codes.Code
+	}{
+		{
+			name: "PasswordOK",
+			req: func(t *testing.T) *pb.StepUpRequest {
+				return &pb.StepUpRequest{Password: &password}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			strength: token.AuthStrengthPassword,
+			code:     codes.OK,
+		},
+		{
+			name: "WrongPassword",
+			req: func(t *testing.T) *pb.StepUpRequest {
+				wrong := "Wrong" + password
+				return &pb.StepUpRequest{Password: &wrong}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "PasswordBelowPolicy",
+			req: func(t *testing.T) *pb.StepUpRequest {
+				return &pb.StepUpRequest{Password: &password}
+			},
+			minStrength: token.AuthStrengthMfa,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+			},
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "MfaCodeOK",
+			req: func(t *testing.T) *pb.StepUpRequest {
+				code := codeOf(t, enabledSecret)
+				return &pb.StepUpRequest{Code: &code}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(2).
+					Return(enabledTotp, nil)
+				store.EXPECT().UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			strength: token.AuthStrengthMfa,
+			code:     codes.OK,
+		},
+		{
+			name: "MfaPasswordOnly",
+			req: func(t *testing.T) *pb.StepUpRequest {
+				return &pb.StepUpRequest{Password: &password}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(enabledTotp, nil)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg := cfg
+			if tc.minStrength != "" {
+				cfg.StepUpMinStrength = tc.minStrength
+			}
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			ctx := addAuthorizationMetadata(context.Background(), t, server.TokenMaker, user.Username, user.Role, time.Minute,
+				token.WithAuth(time.Now().Add(-time.Hour), token.AuthStrengthPassword))
+
+			res, err := userHandler.StepUp(ctx, tc.req(t))
+			require.Equal(t, tc.code, status.Code(err))
+
+			if tc.code == codes.OK {
+				payload, err := server.TokenMaker.VerifyToken(res.GetAccessToken())
+				require.NoError(t, err)
+				require.Equal(t, tc.strength, payload.AuthStrength)
+				require.True(t, payload.AuthenticatedWithin(time.Minute))
+			}
+		})
+	}
+}
+
+func TestForgotPasswordApi(t *testing.T) {
+	user, _ := RandomUser(t)
+
+	// Known and unknown emails both only write the outbox message, the worker looks the user up
+	expectResetEmail := func(store *mockdb.MockStore, email string, err error) {
+		store.EXPECT().
+			GetUserByEmail(gomock.Any(), gomock.Any()).
+			Times(0)
+
+		store.EXPECT().
+			CreateOutboxMessage(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+				require.Equal(t, worker.TaskSendResetPasswordEmail, arg.TaskType)
+				require.Equal(t, worker.AggregatePasswordReset, arg.AggregateType)
+
+				var payload worker.PayloadSendResetPasswordEmail
+				require.NoError(t, json.Unmarshal(arg.Payload, &payload))
+				require.Equal(t, email, payload.Email)
+				require.Equal(t, payload.RequestID.String(), arg.AggregateID)
+
+				return db.OutboxMessage{}, err
+			})
+	}
+
+	emailKey := throttle.Key{Scope: throttle.ForgotPassword, ID: strings.ToLower(user.Email)}
+	unknownEmail := util.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		body          *pb.ForgotPasswordRequest
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ForgotPasswordResponse, err error, limiter throttle.Limiter)
+	}{
+		{
+			name:         "OK",
+			body:         &pb.ForgotPasswordRequest{Email: user.Email},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetEmail(store, user.Email, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.ForgotPasswordResponse, err error, limiter throttle.Limiter) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetMessage())
+
+				// Every request counts so the next one has to wait
+				st, err := limiter.Status(context.Background(), emailKey)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), st.Failures)
+			},
+		},
+		{
+			name:         "UnknownEmail",
+			body:         &pb.ForgotPasswordRequest{Email: unknownEmail},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetEmail(store, unknownEmail, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.ForgotPasswordResponse, err error, limiter throttle.Limiter) {
+				// Unknown emails get the same answer as known ones
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetMessage())
+			},
+		},
+		{
+			name: "EmailThrottled",
+			body: &pb.ForgotPasswordRequest{Email: strings.ToUpper(user.Email)},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				require.NoError(t, limiter.Fail(context.Background(), emailKey))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ForgotPasswordResponse, err error, limiter throttle.Limiter) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.ResourceExhausted, st.Code())
+			},
+		},
+		{
+			name:         "InvalidArgument",
+			body:         &pb.ForgotPasswordRequest{Email: "invalid-email"},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ForgotPasswordResponse, err error, limiter throttle.Limiter) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name:         "InternalError",
+			body:         &pb.ForgotPasswordRequest{Email: user.Email},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetEmail(store, user.Email, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.ForgotPasswordResponse, err error, limiter throttle.Limiter) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+
+				// No email goes out, so the request does not count
+				limit, err := limiter.Status(context.Background(), emailKey)
+				require.NoError(t, err)
+				require.Zero(t, limit.Failures)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			tc.setupLimiter(t, server.Limiter)
+
+			userHandler := NewUserHandler(server)
+			res, err := userHandler.ForgotPassword(context.Background(), tc.body)
+
+			tc.checkResponse(t, res, err, server.Limiter)
+		})
+	}
+}
+
+func TestResetPasswordApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	resetToken := util.RandomString(43)
+	newPassword := util.RandomPassword()
+
+	// The reset is looked up before the transaction to check the password against the user and its history
+	expectResetUser := func(store *mockdb.MockStore, history []string) {
+		store.EXPECT().
+			GetPasswordResetByTokenHash(gomock.Any(), gomock.Eq(token.HashToken(resetToken))).
+			Times(1).
+			Return(db.PasswordReset{Username: user.Username, Email: user.Email}, nil)
+
+		store.EXPECT().
+			GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return(db.GetUserByUserNameRow{Username: user.Username, Email: user.Email, HashedPassword: user.HashedPassword}, nil)
+
+		store.EXPECT().
+			ListPasswordHistory(gomock.Any(), gomock.Eq(db.ListPasswordHistoryParams{Username: user.Username, Limit: 4})).
+			Times(1).
+			Return(history, nil)
+	}
+
+	newPasswordHash, err := password.HashPassword(newPassword)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          *pb.ResetPasswordRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ResetPasswordResponse, err error)
+	}{
+		{
+			name: "OK",
+			body: &pb.ResetPasswordRequest{Token: resetToken, Password: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetUser(store, []string{})
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ResetPasswordTxParams, _ ...db.TxOption) (db.ResetPasswordTxResult, error) {
+						// Only the hash of the reset token may reach the store
+						require.Equal(t, token.HashToken(resetToken), arg.TokenHash)
+						require.NoError(t, password.CheckPassword(newPassword, arg.HashedPassword))
+						require.Equal(t, int32(4), arg.HistoryKeep)
+						return db.ResetPasswordTxResult{User: user, RevokedSessions: []uuid.UUID{uuid.New()}}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.ResetPasswordResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, res)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: &pb.ResetPasswordRequest{Token: resetToken, Password: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPasswordResetByTokenHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordReset{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ResetPasswordResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "TokenUsedMeanwhile",
+			body: &pb.ResetPasswordRequest{Token: resetToken, Password: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetUser(store, []string{})
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.ResetPasswordResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "WeakPassword",
+			body: &pb.ResetPasswordRequest{Token: resetToken, Password: "password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ResetPasswordResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "ReusedPassword",
+			body: &pb.ResetPasswordRequest{Token: resetToken, Password: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetUser(store, []string{newPasswordHash})
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ResetPasswordResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "InternalError",
+			body: &pb.ResetPasswordRequest{Token: resetToken, Password: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				expectResetUser(store, []string{})
+
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.ResetPasswordResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			res, err := userHandler.ResetPassword(context.Background(), tc.body)
+
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestChangePasswordApi(t *testing.T) {
+	user, currentPassword := RandomUser(t)
+	sessionID := uuid.New()
+	newPassword := util.RandomPassword()
+
+	userRow := db.GetUserByUserNameRow{
+		Username:       user.Username,
+		Role:           user.Role,
+		HashedPassword: user.HashedPassword,
+	}
+
+	testCases := []struct {
+		name          string
+		body          *pb.ChangePasswordRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ChangePasswordResponse, err error)
+	}{
+		{
+			name: "RevokeAllSessions",
+			body: &pb.ChangePasswordRequest{CurrentPassword: currentPassword, NewPassword: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					ListPasswordHistory(gomock.Any(), gomock.Eq(db.ListPasswordHistoryParams{Username: user.Username, Limit: 4})).
+					Times(1).
+					Return([]string{}, nil)
+
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams, _ ...db.TxOption) (db.ChangePasswordTxResult, error) {
+						require.NoError(t, password.CheckPassword(newPassword, arg.HashedPassword))
+						require.Equal(t, uuid.Nil, arg.KeepSessionID)
+						return db.ChangePasswordTxResult{User: user, RevokedSessions: []uuid.UUID{sessionID}}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.ChangePasswordResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(1), res.GetRevoked())
+				require.Nil(t, res.GetTokens())
+			},
+		},
+		{
+			name: "KeepCurrentSession",
+			body: &pb.ChangePasswordRequest{CurrentPassword: currentPassword, NewPassword: newPassword, KeepCurrentSession: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{ID: sessionID, Username: user.Username, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now()}, nil)
+
+				store.EXPECT().
+					ListPasswordHistory(gomock.Any(), gomock.Eq(db.ListPasswordHistoryParams{Username: user.Username, Limit: 4})).
+					Times(1).
+					Return([]string{}, nil)
+
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams, _ ...db.TxOption) (db.ChangePasswordTxResult, error) {
+						require.Equal(t, sessionID, arg.KeepSessionID)
+						require.NotEmpty(t, arg.KeepSessionRefreshToken)
+						return db.ChangePasswordTxResult{User: user}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.ChangePasswordResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetTokens().GetAccessToken())
+				require.NotEmpty(t, res.GetTokens().GetRefreshToken())
+			},
+		},
+		{
+			name: "IncorrectCurrentPassword",
+			body: &pb.ChangePasswordRequest{CurrentPassword: util.RandomPassword(), NewPassword: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ChangePasswordResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "WeakNewPassword",
+			body: &pb.ChangePasswordRequest{CurrentPassword: currentPassword, NewPassword: "password"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ChangePasswordResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			ctx := addSessionAuthorizationMetadata(t, server.TokenMaker, user, sessionID)
+
+			res, err := userHandler.ChangePassword(ctx, tc.body)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+// TestLoginUserEvictedSessionApi tests that a session evicted by a login is no longer served from the session cache
+func TestLoginUserEvictedSessionApi(t *testing.T) {
+	user, password := RandomUser(t)
+	evictedID := uuid.New()
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	active := db.GetSessionByIdRow{ID: evictedID, Username: user.Username, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now()}
+	gomock.InOrder(
+		store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(evictedID)).Return(active, nil),
+		store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(evictedID)).Return(db.GetSessionByIdRow{}, db.ErrRecordNotFound),
+	)
+	store.EXPECT().
+		GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: user.HashedPassword}, nil)
+	store.EXPECT().
+		GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(db.UserTotp{}, db.ErrRecordNotFound)
+	store.EXPECT().
+		CreateSessionTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.CreateSessionTxResult{
+			Session: db.Session{ID: uuid.New(), Username: user.Username},
+			Evicted: []uuid.UUID{evictedID},
+		}, nil)
+
+	server := server.NewTestServer(t, store, &cfg, nil)
+	server.SessionChecker = session.NewCachedChecker(store, time.Minute)
+
+	evicted, err := token.NewPayload(user.Username, user.Role, evictedID, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, server.SessionChecker.CheckSession(context.Background(), evicted))
+
+	userHandler := NewUserHandler(server)
+	_, err = userHandler.LoginUser(context.Background(), &pb.LoginUserRequest{UserName: user.Username, Password: password})
+	require.NoError(t, err)
+
+	// The cached answer is dropped, the evicted session is looked up again and rejected
+	require.Error(t, server.SessionChecker.CheckSession(context.Background(), evicted))
+}
+
+// TestLoginUserThrottleApi tests the failed attempt tracking of the LoginUser API handler
+func TestLoginUserThrottleApi(t *testing.T) {
+	user, password := RandomUser(t)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	// The port of the connection is not part of the key
+	peerCtx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4321},
+	})
+
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: user.Username}
+	ipKey := throttle.Key{Scope: throttle.ClientIP, ID: "10.0.0.1"}
+
+	failTimes := func(t *testing.T, limiter throttle.Limiter, key throttle.Key, times int64) {
+		for i := int64(0); i < times; i++ {
+			require.NoError(t, limiter.Fail(context.Background(), key))
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          *pb.LoginUserRequest
+		forwardedFor  string
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, err error, limiter throttle.Limiter)
+	}{
+		{
+			name: "IncorrectPasswordCounted",
+			body: &pb.LoginUserRequest{
+				UserName: user.Username,
+				Password: "Wrong@123",
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, HashedPassword: user.HashedPassword}, nil)
+
+				store.EXPECT().
+					CreateLoginEvent(gomock.Any(), EqLoginFailure(user.Username, loginhistory.ReasonInvalidPassword)).
+					Times(1).
+					Return(db.LoginEvent{}, nil)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+				for _, key := range []throttle.Key{userKey, ipKey} {
+					status, err := limiter.Status(context.Background(), key)
+					require.NoError(t, err)
+					require.Equal(t, int64(1), status.Failures)
+				}
+			},
+		},
+		{
+			name: "UserLocked",
+			body: &pb.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				failTimes(t, limiter, userKey, cfg.LoginMaxFailures)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+			},
+		},
+		{
+			name: "ClientIPLocked",
+			body: &pb.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				failTimes(t, limiter, ipKey, cfg.LoginIPMaxFailures)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+			},
+		},
+		{
+			// No proxy is trusted, a client cannot pick the address it is throttled by
+			name: "ForgedForwardedForStillLocked",
+			body: &pb.LoginUserRequest{
+				UserName: user.Username,
+				Password: password,
+			},
+			forwardedFor: "198.51.100.23",
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				failTimes(t, limiter, ipKey, cfg.LoginIPMaxFailures)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			tc.setupLimiter(t, server.Limiter)
+
+			ctx := peerCtx
+			if tc.forwardedFor != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", tc.forwardedFor))
+			}
+
+			userHandler := NewUserHandler(server)
+			_, err := userHandler.LoginUser(ctx, tc.body)
+
+			tc.checkResponse(t, err, server.Limiter)
+		})
+	}
+}
+
+// TestVerifyUserEmailThrottleApi tests the failed attempt tracking of the VerifyUserEmail API handler
+func TestVerifyUserEmailThrottleApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	verifyEmail := RandomVerifyEmail(t, user)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	// Only the first guess reaches the store, the next one has to wait
+	store.EXPECT().
+		VerifyUserEmailTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.VerifyUserEmailTxResult{}, db.ErrRecordNotFound)
+
+	server := server.NewTestServer(t, store, &cfg, nil)
+	userHandler := NewUserHandler(server)
+
+	for _, code := range []codes.Code{codes.NotFound, codes.ResourceExhausted} {
+		_, err := userHandler.VerifyUserEmail(context.Background(), &pb.VerifyUserEmailRequest{
+			EmailId:    verifyEmail.ID,
+			SecretCode: util.RandomString(32),
+		})
+		require.Equal(t, code, status.Code(err))
+	}
+}
+
+// TestUserLockoutApi tests the banker API handlers to view and lift a lockout
+func TestUserLockoutApi(t *testing.T) {
+	user, _ := RandomUser(t)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	userKey := throttle.Key{Scope: throttle.LoginUser, ID: user.Username}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		call          func(ctx context.Context, handler *UserHandler) (proto.Message, error)
+		checkResponse func(t *testing.T, res proto.Message, err error, limiter throttle.Limiter)
+	}{
+		{
+			name: "GetLocked",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+			},
+			call: func(ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				return handler.GetUserLockout(ctx, &pb.GetUserLockoutRequest{UserName: user.Username})
+			},
+			checkResponse: func(t *testing.T, res proto.Message, err error, limiter throttle.Limiter) {
+				require.NoError(t, err)
+
+				lockout := res.(*pb.GetUserLockoutResponse)
+				require.Equal(t, user.Username, lockout.GetUserName())
+				require.Equal(t, cfg.LoginMaxFailures, lockout.GetFailures())
+				require.True(t, lockout.GetLocked())
+				require.NotNil(t, lockout.GetLockedUntil())
+			},
+		},
+		{
+			name: "Unlock",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+			},
+			call: func(ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				return handler.UnlockUser(ctx, &pb.UnlockUserRequest{UserName: user.Username})
+			},
+			checkResponse: func(t *testing.T, res proto.Message, err error, limiter throttle.Limiter) {
+				require.NoError(t, err)
+				require.NoError(t, limiter.Check(context.Background(), userKey))
+			},
+		},
+		{
+			name: "NotBanker",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			call: func(ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				return handler.UnlockUser(ctx, &pb.UnlockUserRequest{UserName: user.Username})
+			},
+			checkResponse: func(t *testing.T, res proto.Message, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+				require.ErrorIs(t, limiter.Check(context.Background(), userKey), throttle.ErrTooManyAttempts)
+			},
+		},
+		{
+			name: "UserNotFound",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq("nonexistent")).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+			},
+			call: func(ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				return handler.GetUserLockout(ctx, &pb.GetUserLockoutRequest{UserName: "nonexistent"})
+			},
+			checkResponse: func(t *testing.T, res proto.Message, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidUserName",
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			call: func(ctx context.Context, handler *UserHandler) (proto.Message, error) {
+				return handler.UnlockUser(ctx, &pb.UnlockUserRequest{UserName: "invalid-user"})
+			},
+			checkResponse: func(t *testing.T, res proto.Message, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+
+			for i := int64(0); i < cfg.LoginMaxFailures; i++ {
+				require.NoError(t, server.Limiter.Fail(context.Background(), userKey))
+			}
+
+			userHandler := NewUserHandler(server)
+			res, err := tc.call(tc.setupAuth(t, server.TokenMaker), userHandler)
+
+			tc.checkResponse(t, res, err, server.Limiter)
+		})
+	}
+}
+
+// TestResendVerifyEmailApi tests the ResendVerifyEmail API handler
+func TestResendVerifyEmailApi(t *testing.T) {
+	user, _ := RandomUser(t)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	resendKey := throttle.Key{Scope: throttle.ResendVerifyEmail, ID: user.Username}
+
+	testCases := []struct {
+		name          string
+		setupContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, err error, limiter throttle.Limiter)
+	}{
+		{
+			name: "OK",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), EqResendVerifyEmailTxParams(user.Username)).
+					Times(1).
+					Return(db.ResendVerifyEmailTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.NoError(t, err)
+				require.ErrorIs(t, limiter.Check(context.Background(), resendKey), throttle.ErrTooManyAttempts)
+			},
+		},
+		{
+			name: "TooSoon",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				require.NoError(t, limiter.Fail(context.Background(), resendKey))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResendVerifyEmailTxResult{}, db.ErrEmailAlreadyVerified)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+				require.NoError(t, limiter.Check(context.Background(), resendKey))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			tc.setupLimiter(t, server.Limiter)
+
+			userHandler := NewUserHandler(server)
+			_, err := userHandler.ResendVerifyEmail(tc.setupContext(t, server.TokenMaker), &pb.ResendVerifyEmailRequest{})
+
+			tc.checkResponse(t, err, server.Limiter)
+		})
+	}
+}
+
+func TestLoginEventsApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	sessionID := uuid.New()
+
+	event := db.LoginEvent{
+		ID:        util.RandomInt(1, 1000),
+		Username:  user.Username,
+		SessionID: pgtype.UUID{Bytes: sessionID, Valid: true},
+		Success:   true,
+		UserAgent: "Mozilla/5.0",
+		ClientIp:  "203.0.113.7",
+		NewDevice: true,
+		CreatedAt: time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		setupContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		body          *pb.ListLoginEventsRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ListLoginEventsResponse, err error)
+	}{
+		{
+			name: "OK",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			body: &pb.ListLoginEventsRequest{Page: 2, Size: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginEvents(gomock.Any(), gomock.Eq(db.ListLoginEventsParams{Username: user.Username, Limit: 5, Offset: 5})).
+					Times(1).
+					Return([]db.LoginEvent{event}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListLoginEventsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetLoginEvents(), 1)
+				require.Equal(t, sessionID.String(), res.GetLoginEvents()[0].GetSessionId())
+				require.True(t, res.GetLoginEvents()[0].GetNewDevice())
+				require.Nil(t, res.GetLoginEvents()[0].GetDeniedAt())
+			},
+		},
+		{
+			name: "InvalidPage",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			body: &pb.ListLoginEventsRequest{Page: 0, Size: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListLoginEventsResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			body: &pb.ListLoginEventsRequest{Page: 1, Size: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListLoginEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListLoginEventsResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			res, err := userHandler.ListLoginEvents(tc.setupContext(t, server.TokenMaker), tc.body)
+
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestGetDenyLoginApi(t *testing.T) {
+	denyToken := util.RandomString(43)
+	event := db.LoginEvent{
+		ID:        util.RandomInt(1, 1000),
+		Username:  util.RandomOwner(),
+		SessionID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		ClientIp:  "203.0.113.7",
+	}
+
+	testCases := []struct {
+		name          string
+		body          *pb.GetDenyLoginRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.GetDenyLoginResponse, err error)
+	}{
+		{
+			name: "OK",
+			body: &pb.GetDenyLoginRequest{Token: denyToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				// Opening the link only looks the login up, prefetchers cannot deny it
+				store.EXPECT().
+					GetLoginEventByDenyToken(gomock.Any(), gomock.Eq(pgtype.Text{String: token.HashToken(denyToken), Valid: true})).
+					Times(1).
+					Return(event, nil)
+
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetDenyLoginResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, event.ID, res.GetLoginEvent().GetId())
+				require.Equal(t, event.ClientIp, res.GetLoginEvent().GetClientIp())
+			},
+		},
+		{
+			name: "UnknownOrUsedLink",
+			body: &pb.GetDenyLoginRequest{Token: denyToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginEventByDenyToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginEvent{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetDenyLoginResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "MissingToken",
+			body: &pb.GetDenyLoginRequest{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginEventByDenyToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetDenyLoginResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			res, err := userHandler.GetDenyLogin(context.Background(), tc.body)
+
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestDenyLoginApi(t *testing.T) {
+	denyToken := util.RandomString(43)
+	event := db.LoginEvent{
+		ID:        util.RandomInt(1, 1000),
+		Username:  util.RandomOwner(),
+		SessionID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		body          *pb.DenyLoginRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.DenyLoginResponse, err error)
+	}{
+		{
+			name: "OK",
+			body: &pb.DenyLoginRequest{Token: denyToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Eq(token.HashToken(denyToken))).
+					Times(1).
+					Return(db.DenyLoginTxResult{LoginEvent: event, Blocked: true}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.DenyLoginResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, res.GetSessionRevoked())
+			},
+		},
+		{
+			name: "UnknownOrUsedLink",
+			body: &pb.DenyLoginRequest{Token: denyToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DenyLoginTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.DenyLoginResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "MissingToken",
+			body: &pb.DenyLoginRequest{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.DenyLoginResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			body: &pb.DenyLoginRequest{Token: denyToken},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DenyLoginTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DenyLoginTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.DenyLoginResponse, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			res, err := userHandler.DenyLogin(context.Background(), tc.body)
+
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestGetProfileApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	row := db.GetUserByUserNameRow{
+		Username:    user.Username,
+		FullName:    user.FullName,
+		Email:       user.Email,
+		DateOfBirth: pgtype.Date{Time: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+		Phone:       "+84901234567",
+		Address:     "1 Le Loi, District 1",
+		Nationality: "VN",
+		KycStatus:   kyc.Pending,
+	}
+
+	testCases := []struct {
+		name          string
+		setupContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.GetProfileResponse, err error)
+	}{
+		{
+			name: "OK",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetProfileResponse, err error) {
+				require.NoError(t, err)
+				profile := res.GetUser()
+				require.Equal(t, user.Username, profile.GetUserName())
+				require.Equal(t, "1990-01-02", profile.GetDateOfBirth())
+				require.Equal(t, row.Phone, profile.GetPhone())
+				require.Equal(t, row.Address, profile.GetAddress())
+				require.Equal(t, row.Nationality, profile.GetNationality())
+				require.Equal(t, kyc.Pending, profile.GetKycStatus())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetProfileResponse, err error) {
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			res, err := userHandler.GetProfile(tc.setupContext(t, server.TokenMaker), &pb.GetProfileRequest{})
+
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestSubmitKycApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	row := db.GetUserByUserNameRow{
+		Username:    user.Username,
+		DateOfBirth: pgtype.Date{Time: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+		Phone:       "+84901234567",
+		Address:     "1 Le Loi, District 1",
+		Nationality: "VN",
+		KycStatus:   kyc.Unverified,
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.SubmitKycResponse, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+
+				arg := db.UpdateKycStatusTxParams{
+					UpdateUserKycStatusParams: db.UpdateUserKycStatusParams{
+						Username:   user.Username,
+						FromStatus: kyc.Unverified,
+						ToStatus:   kyc.Pending,
+					},
+					Reviewer:        user.Username,
+					AttachDocuments: true,
+				}
+
+				submitted := user
+				submitted.KycStatus = kyc.Pending
+
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.UpdateKycStatusTxResult{User: submitted}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.SubmitKycResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, kyc.Pending, res.GetUser().GetKycStatus())
+			},
+		},
+		{
+			name: "IncompleteProfile",
+			buildStubs: func(store *mockdb.MockStore) {
+				incomplete := row
+				incomplete.Nationality = ""
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(incomplete, nil)
+
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.SubmitKycResponse, err error) {
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := row
+				verified.KycStatus = kyc.Verified
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(verified, nil)
+
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.SubmitKycResponse, err error) {
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+		{
+			name: "NoDocuments",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateKycStatusTxResult{}, db.ErrNoKycDocuments)
+			},
+			checkResponse: func(t *testing.T, res *pb.SubmitKycResponse, err error) {
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+		{
+			name: "StatusChanged",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+
+				store.EXPECT().
+					UpdateKycStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateKycStatusTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.SubmitKycResponse, err error) {
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Aborted, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			ctx := addAuthorizationMetadata(context.Background(), t, server.TokenMaker, user.Username, user.Role, time.Minute)
+			res, err := userHandler.SubmitKyc(ctx, &pb.SubmitKycRequest{})
+
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestUploadKycDocumentApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	row := db.GetUserByUserNameRow{Username: user.Username, KycStatus: kyc.Rejected}
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	upload := &pb.UploadKycDocumentRequest{Kind: kyc.DocumentDrivingLicense, FileName: "licence.png", ContentType: "image/png", Content: png}
+
+	testCases := []struct {
+		name          string
+		req           *pb.UploadKycDocumentRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.UploadKycDocumentResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  upload,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					CreateKycDocument(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateKycDocumentParams) (db.KycDocument, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, kyc.DocumentDrivingLicense, arg.Kind)
+						return db.KycDocument{ID: 1, Username: arg.Username, Kind: arg.Kind, FileName: arg.FileName, Status: kyc.DocumentPending}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.UploadKycDocumentResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "licence.png", res.GetDocument().GetFileName())
+				require.Equal(t, kyc.DocumentPending, res.GetDocument().GetStatus())
+			},
+		},
+		{
+			name: "InvalidKind",
+			req:  &pb.UploadKycDocumentRequest{Kind: "selfie", FileName: "licence.png", ContentType: "image/png", Content: png},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UploadKycDocumentResponse, err error) {
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "ContentMismatch",
+			req:  &pb.UploadKycDocumentRequest{Kind: kyc.DocumentDrivingLicense, FileName: "licence.png", ContentType: "image/png", Content: []byte("%PDF-1.7\n")},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(row, nil)
+				store.EXPECT().
+					CreateKycDocument(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UploadKycDocumentResponse, err error) {
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "Verified",
+			req:  upload,
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := row
+				verified.KycStatus = kyc.Verified
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(verified, nil)
+				store.EXPECT().
+					CreateKycDocument(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UploadKycDocumentResponse, err error) {
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, err := pkg.LoadConfig("../../")
+			require.NoError(t, err)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+			ctx := addAuthorizationMetadata(context.Background(), t, server.TokenMaker, user.Username, user.Role, time.Minute)
+			res, err := userHandler.UploadKycDocument(ctx, tc.req)
+
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestSendPhoneCodeApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	user.Phone = "+84912345678"
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	sendKey := throttle.Key{Scope: throttle.SendPhoneCode, ID: user.Username}
+
+	testCases := []struct {
+		name          string
+		setupContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		setupLimiter  func(t *testing.T, limiter throttle.Limiter)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, err error, limiter throttle.Limiter)
+	}{
+		{
+			name: "OK",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Phone: user.Phone}, nil)
+
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+						require.Equal(t, worker.TaskSendPhoneCode, arg.TaskType)
+						return db.OutboxMessage{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.NoError(t, err)
+				require.ErrorIs(t, limiter.Check(context.Background(), sendKey), throttle.ErrTooManyAttempts)
+			},
+		},
+		{
+			name: "TooSoon",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {
+				require.NoError(t, limiter.Fail(context.Background(), sendKey))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+			},
+		},
+		{
+			name: "NoPhone",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return addAuthorizationMetadata(context.Background(), t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username}, nil)
+
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+				require.NoError(t, limiter.Check(context.Background(), sendKey))
+			},
+		},
+		{
+			name: "Unauthenticated",
+			setupContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			setupLimiter: func(t *testing.T, limiter throttle.Limiter) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, limiter throttle.Limiter) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			tc.setupLimiter(t, server.Limiter)
+
+			userHandler := NewUserHandler(server)
+			_, err := userHandler.SendPhoneCode(tc.setupContext(t, server.TokenMaker), &pb.SendPhoneCodeRequest{})
+
+			tc.checkResponse(t, err, server.Limiter)
+		})
+	}
+}
+
+func TestVerifyPhoneApi(t *testing.T) {
+	user, _ := RandomUser(t)
+	user.Phone = "+84912345678"
+	code := "482913"
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	userRow := db.GetUserByUserNameRow{Username: user.Username, Phone: user.Phone}
+	phoneCode := db.PhoneCode{
+		ID:        util.RandomInt(1, 1000),
+		Username:  user.Username,
+		Phone:     user.Phone,
+		Purpose:   phone.PurposeVerify,
+		CodeHash:  phone.HashCode([]byte(cfg.PhoneCodeKey), code),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	attemptArg := db.IncrementPhoneCodeAttemptsParams{ID: phoneCode.ID, MaxAttempts: phone.MaxCodeAttempts}
+
+	testCases := []struct {
+		name          string
+		req           *pb.VerifyPhoneRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.VerifyPhoneResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.VerifyPhoneRequest{Code: code},
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := user
+				verified.IsPhoneVerified = true
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					GetLatestPhoneCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Eq(db.VerifyPhoneTxParams{CodeID: phoneCode.ID, Username: user.Username, Phone: user.Phone})).
+					Times(1).
+					Return(db.VerifyPhoneTxResult{User: verified}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyPhoneResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, res.GetUser().GetIsPhoneVerified())
+			},
+		},
+		{
+			name: "InvalidCode",
+			req:  &pb.VerifyPhoneRequest{Code: "000000"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(userRow, nil)
+
+				store.EXPECT().
+					GetLatestPhoneCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(phoneCode, nil)
+
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyPhoneResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			req:  &pb.VerifyPhoneRequest{Code: code},
+			buildStubs: func(store *mockdb.MockStore) {
+				verifiedRow := userRow
+				verifiedRow.IsPhoneVerified = true
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(verifiedRow, nil)
+
+				store.EXPECT().
+					GetLatestPhoneCode(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyPhoneResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidArgument",
+			req:  &pb.VerifyPhoneRequest{Code: "12ab56"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyPhoneResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+
+			ctx := addAuthorizationMetadata(context.Background(), t, server.TokenMaker, user.Username, user.Role, time.Minute)
+			res, err := userHandler.VerifyPhone(ctx, tc.req)
+
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+// headerStream keeps the header a handler sets, like the transport of a gRPC server does
+type headerStream struct {
+	header metadata.MD
+}
+
+func (s *headerStream) Method() string {
+	return ""
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *headerStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *headerStream) SetTrailer(md metadata.MD) error {
+	return nil
+}
+
+func TestOidcLoginApi(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	user, _ := RandomUser(t)
+	subject := util.RandomString(12)
+
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	cfg.OidcIssuerUrl = idp.Issuer()
+	cfg.OidcClientID = oidctest.ClientID
+	cfg.OidcClientSecret = oidctest.ClientSecret
+	cfg.OidcRedirectUrl = oidctest.RedirectURL
+
+	testCases := []struct {
+		name          string
+		claims        map[string]any
+		body          func(code, state string) *pb.OidcCallbackRequest
+		withoutCookie bool
+		buildStubs    func(store *mockdb.MockStore, loginState *db.OidcLoginState)
+		checkResponse func(t *testing.T, res *pb.LoginUserResponse, err error)
+	}{
+		{
+			name: "NewUser",
+			claims: map[string]any{
+				"sub":            subject,
+				"email":          user.Email,
+				"email_verified": true,
+				"name":           user.FullName,
+			},
+			body: func(code, state string) *pb.OidcCallbackRequest {
+				return &pb.OidcCallbackRequest{Code: code, State: state}
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ConsumeOidcLoginStateParams) (db.OidcLoginState, error) {
+						require.Equal(t, loginState.StateHash, arg.StateHash)
+						require.Equal(t, loginState.BrowserHash, arg.BrowserHash)
+						return *loginState, nil
+					})
+
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Eq(db.GetUserIdentityParams{Issuer: idp.Issuer(), Subject: subject})).
+					Times(1).
+					Return(db.UserIdentity{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateOidcUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOidcUserTxParams, _ ...db.TxOption) (db.CreateOidcUserTxResult, error) {
+						require.Equal(t, user.FullName, arg.FullName)
+						require.Equal(t, util.DepositorRole, arg.Role)
+						require.True(t, arg.IsEmailVerified)
+
+						newUser := db.User{Username: user.Username, Email: arg.Email, IsEmailVerified: true}
+						// The provider verified the address, no verification email is sent
+						require.NoError(t, arg.AfterCreate(store, newUser))
+						return db.CreateOidcUserTxResult{User: newUser}, nil
+					})
+
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: user.Username, Role: util.DepositorRole, Email: user.Email}, nil)
+
+				store.EXPECT().
+					GetUserTotp(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserTotp{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					CreateSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionTxParams, _ ...db.TxOption) (db.CreateSessionTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, "Work laptop", arg.DeviceLabel)
+						return db.CreateSessionTxResult{Session: db.Session{ID: arg.ID, Username: arg.Username}}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, res.GetMfaRequired())
+				require.NotEmpty(t, res.GetAccessToken())
+				require.Equal(t, user.Username, res.GetUser().GetUserName())
+			},
+		},
+		{
+			name:   "MissingCookie",
+			claims: map[string]any{"sub": subject, "email": user.Email},
+			body: func(code, state string) *pb.OidcCallbackRequest {
+				return &pb.OidcCallbackRequest{Code: code, State: state}
+			},
+			withoutCookie: true,
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				// The callback of a login begun in another browser never reaches the state
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "InvalidState",
+			claims: map[string]any{"sub": subject, "email": user.Email},
+			body: func(code, state string) *pb.OidcCallbackRequest {
+				return &pb.OidcCallbackRequest{Code: code, State: util.RandomString(43)}
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OidcLoginState{}, db.ErrRecordNotFound)
+
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name:   "AccessDenied",
+			claims: map[string]any{"sub": subject},
+			body: func(code, state string) *pb.OidcCallbackRequest {
+				return &pb.OidcCallbackRequest{State: state, Error: "access_denied"}
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name:   "InvalidArgument",
+			claims: map[string]any{"sub": subject},
+			body: func(code, state string) *pb.OidcCallbackRequest {
+				return &pb.OidcCallbackRequest{Code: code}
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState *db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			var loginState db.OidcLoginState
+
+			store.EXPECT().
+				DeleteExpiredOidcLoginStates(gomock.Any()).
+				Times(1).
+				Return(int64(0), nil)
+
+			store.EXPECT().
+				CreateOidcLoginState(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateOidcLoginStateParams) (db.OidcLoginState, error) {
+					loginState = db.OidcLoginState{
+						ID:           1,
+						StateHash:    arg.StateHash,
+						Nonce:        arg.Nonce,
+						CodeVerifier: arg.CodeVerifier,
+						DeviceLabel:  arg.DeviceLabel,
+						ExpiresAt:    arg.ExpiresAt,
+						BrowserHash:  arg.BrowserHash,
+					}
+					return loginState, nil
+				})
+
+			tc.buildStubs(store, &loginState)
+
+			server := server.NewTestServer(t, store, &cfg, nil)
+			userHandler := NewUserHandler(server)
+
+			beginStream := &headerStream{}
+			beginCtx := grpc.NewContextWithServerTransportStream(context.Background(), beginStream)
+
+			begin, err := userHandler.BeginOidcLogin(beginCtx, &pb.BeginOidcLoginRequest{DeviceLabel: "Work laptop"})
+			require.NoError(t, err)
+			require.Equal(t, token.HashToken(begin.GetState()), loginState.StateHash)
+
+			cookies := (&http.Response{Header: http.Header{"Set-Cookie": beginStream.header.Get(SetCookieHeader)}}).Cookies()
+			require.Len(t, cookies, 1)
+			require.Equal(t, oidc.CookieName, cookies[0].Name)
+			require.Equal(t, token.HashToken(cookies[0].Value), loginState.BrowserHash)
+			require.True(t, cookies[0].HttpOnly)
+			require.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+
+			code, err := idp.Authorize(begin.GetAuthorizationUrl(), tc.claims)
+			require.NoError(t, err)
+
+			callbackCtx := grpc.NewContextWithServerTransportStream(context.Background(), &headerStream{})
+			if !tc.withoutCookie {
+				// The gateway forwards the Cookie header of the browser
+				callbackCtx = metadata.NewIncomingContext(callbackCtx, metadata.Pairs(grpcCookieHeader, cookies[0].Name+"="+cookies[0].Value))
+			}
+
+			res, err := userHandler.OidcCallback(callbackCtx, tc.body(code, begin.GetState()))
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestOidcLoginNotConfiguredApi(t *testing.T) {
+	cfg, err := pkg.LoadConfig("../../")
+	require.NoError(t, err)
+
+	cfg.OidcIssuerUrl = ""
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := server.NewTestServer(t, store, &cfg, nil)
+	userHandler := NewUserHandler(server)
+
+	_, err = userHandler.BeginOidcLogin(context.Background(), &pb.BeginOidcLoginRequest{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = userHandler.OidcCallback(context.Background(), &pb.OidcCallbackRequest{Code: "code", State: "state"})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
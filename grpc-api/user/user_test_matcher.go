@@ -1,13 +1,19 @@
 package user
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"reflect"
 
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/pkg/webhook"
 	"github.com/ChokeGuy/simple-bank/util/password"
 	"github.com/ChokeGuy/simple-bank/worker"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 )
 
 type eqCreateUserTxParamsMatcher struct {
@@ -33,9 +39,13 @@ func (e eqCreateUserTxParamsMatcher) Matches(x interface{}) bool {
 		return false
 	}
 
-	err = actualArg.AfterCreate(e.user)
+	// AfterCreate must write the verification email task into the outbox of the transaction
+	outbox := &outboxRecorder{}
+	if err := actualArg.AfterCreate(outbox, e.user); err != nil {
+		return false
+	}
 
-	return err == nil
+	return outbox.hasSendVerifyEmail(e.user.Username)
 }
 
 func (e eqCreateUserTxParamsMatcher) String() string {
@@ -46,23 +56,210 @@ func EqCreateUserTxParams(arg db.CreateUserTxParams, password string, user db.Us
 	return eqCreateUserTxParamsMatcher{arg, password, user}
 }
 
-// Custom matcher for PayloadSendVerifyEmail
-type eqPayloadSendVerifyEmailMatcher struct {
-	expected *worker.PayloadSendVerifyEmail
+type eqVerifyUserEmailTxParamsMatcher struct {
+	arg  db.VerifyUserEmailTxParams
+	user db.User
 }
 
-func (e eqPayloadSendVerifyEmailMatcher) Matches(x interface{}) bool {
-	actual, ok := x.(*worker.PayloadSendVerifyEmail)
+func (e eqVerifyUserEmailTxParamsMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.VerifyUserEmailTxParams)
 	if !ok {
 		return false
 	}
-	return actual.UserName == e.expected.UserName
+
+	if actualArg.EmailId != e.arg.EmailId || actualArg.SecretCode != e.arg.SecretCode {
+		return false
+	}
+
+	// AfterVerify must write the user.verified webhook event into the outbox of the transaction
+	outbox := &outboxRecorder{}
+	if err := actualArg.AfterVerify(outbox, e.user); err != nil {
+		return false
+	}
+
+	return outbox.hasWebhookEvent(e.user.Username, webhook.EventUserVerified)
+}
+
+func (e eqVerifyUserEmailTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches email id %v and secret code %v", e.arg.EmailId, e.arg.SecretCode)
+}
+
+func EqVerifyUserEmailTxParams(arg db.VerifyUserEmailTxParams, user db.User) gomock.Matcher {
+	return eqVerifyUserEmailTxParamsMatcher{arg, user}
+}
+
+type eqUpdateUserTxParamsMatcher struct {
+	arg db.UpdateUserParams
+}
+
+func (e eqUpdateUserTxParamsMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.UpdateUserTxParams)
+	if !ok {
+		return false
+	}
+
+	// Users updating their own profile are not audited
+	if !reflect.DeepEqual(actualArg.UpdateUserParams, e.arg) || actualArg.AuditLog != nil {
+		return false
+	}
+
+	// AfterEmailChange must send a verification email to the new address
+	outbox := &outboxRecorder{}
+	if err := actualArg.AfterEmailChange(outbox, db.User{Username: e.arg.Username}); err != nil {
+		return false
+	}
+
+	return outbox.hasSendVerifyEmail(e.arg.Username)
+}
+
+func (e eqUpdateUserTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches update user params %v", e.arg)
+}
+
+func EqUpdateUserTxParams(arg db.UpdateUserParams) gomock.Matcher {
+	return eqUpdateUserTxParamsMatcher{arg}
+}
+
+type eqResendVerifyEmailTxParamsMatcher struct {
+	username string
+}
+
+func (e eqResendVerifyEmailTxParamsMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.ResendVerifyEmailTxParams)
+	if !ok || actualArg.Username != e.username {
+		return false
+	}
+
+	outbox := &outboxRecorder{}
+	if err := actualArg.AfterInvalidate(outbox, db.GetUserByUserNameRow{Username: e.username}); err != nil {
+		return false
+	}
+
+	return outbox.hasSendVerifyEmail(e.username)
+}
+
+func (e eqResendVerifyEmailTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches resend verify email of %v", e.username)
+}
+
+func EqResendVerifyEmailTxParams(username string) gomock.Matcher {
+	return eqResendVerifyEmailTxParamsMatcher{username}
+}
+
+// outboxRecorder stands in for the transaction Querier and records outbox messages
+type outboxRecorder struct {
+	db.Querier
+	messages []db.CreateOutboxMessageParams
+}
+
+func (r *outboxRecorder) CreateOutboxMessage(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+	r.messages = append(r.messages, arg)
+	return db.OutboxMessage{}, nil
+}
+
+func (r *outboxRecorder) hasSendVerifyEmail(username string) bool {
+	if len(r.messages) != 1 || r.messages[0].TaskType != worker.TaskSendVerifyEmail {
+		return false
+	}
+
+	var payload worker.PayloadSendVerifyEmail
+	if err := json.Unmarshal(r.messages[0].Payload, &payload); err != nil {
+		return false
+	}
+
+	return payload.UserName == username && r.messages[0].AggregateID == username
+}
+
+func (r *outboxRecorder) hasWebhookEvent(owner string, eventType string) bool {
+	if len(r.messages) != 1 || r.messages[0].TaskType != worker.TaskDispatchWebhookEvent {
+		return false
+	}
+
+	var payload worker.PayloadDispatchWebhookEvent
+	if err := json.Unmarshal(r.messages[0].Payload, &payload); err != nil {
+		return false
+	}
+
+	return payload.Owner == owner && payload.Event.Type == eventType
+}
+
+type eqCreateSessionTxParamsMatcher struct {
+	username    string
+	deviceLabel string
+	maxSessions int32
+}
+
+func (e eqCreateSessionTxParamsMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.CreateSessionTxParams)
+	if !ok {
+		return false
+	}
+
+	// The session keeps the SHA-256 hex digest of the refresh token, never the token itself,
+	// and the login is recorded in the transaction creating it
+	return actualArg.Username == e.username &&
+		actualArg.DeviceLabel == e.deviceLabel &&
+		actualArg.MaxSessions == e.maxSessions &&
+		len(actualArg.RefreshToken) == sha256.Size*2 &&
+		!actualArg.IsBlocked &&
+		actualArg.AfterCreate != nil
+}
+
+func (e eqCreateSessionTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches session of %v on device %q limited to %d", e.username, e.deviceLabel, e.maxSessions)
+}
+
+func EqCreateSessionTxParams(username, deviceLabel string, maxSessions int32) gomock.Matcher {
+	return eqCreateSessionTxParamsMatcher{username, deviceLabel, maxSessions}
+}
+
+type eqRotateRefreshTokenTxParamsMatcher struct {
+	sessionID    uuid.UUID
+	refreshToken string
+}
+
+func (e eqRotateRefreshTokenTxParamsMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.RotateRefreshTokenTxParams)
+	if !ok {
+		return false
+	}
+
+	// Only hashes of the presented and the new refresh token may reach the store
+	return actualArg.SessionID == e.sessionID &&
+		actualArg.RefreshToken == token.HashToken(e.refreshToken) &&
+		len(actualArg.NewRefreshToken) == len(actualArg.RefreshToken) &&
+		actualArg.NewRefreshToken != actualArg.RefreshToken
+}
+
+func (e eqRotateRefreshTokenTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches rotation of session %v", e.sessionID)
+}
+
+func EqRotateRefreshTokenTxParams(sessionID uuid.UUID, refreshToken string) gomock.Matcher {
+	return eqRotateRefreshTokenTxParamsMatcher{sessionID, refreshToken}
+}
+
+type eqLoginFailureMatcher struct {
+	username string
+	reason   string
+}
+
+func (e eqLoginFailureMatcher) Matches(x interface{}) bool {
+	actualArg, ok := x.(db.CreateLoginEventParams)
+	if !ok {
+		return false
+	}
+
+	return actualArg.Username == e.username &&
+		!actualArg.Success &&
+		actualArg.FailureReason == e.reason &&
+		!actualArg.SessionID.Valid
 }
 
-func (e eqPayloadSendVerifyEmailMatcher) String() string {
-	return "matches payload send verify email"
+func (e eqLoginFailureMatcher) String() string {
+	return fmt.Sprintf("matches failed login of %v because of %v", e.username, e.reason)
 }
 
-func EqPayloadSendVerifyEmail(expected *worker.PayloadSendVerifyEmail) gomock.Matcher {
-	return eqPayloadSendVerifyEmailMatcher{expected}
+func EqLoginFailure(username, reason string) gomock.Matcher {
+	return eqLoginFailureMatcher{username, reason}
 }
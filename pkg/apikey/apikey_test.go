@@ -0,0 +1,184 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	key, displayPrefix, err := Generate()
+	require.NoError(t, err)
+
+	require.True(t, strings.HasPrefix(key, KeyPrefix))
+	require.True(t, strings.HasPrefix(key, displayPrefix))
+	require.Len(t, displayPrefix, displayLength)
+
+	other, _, err := Generate()
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+}
+
+func TestValidateAllowedIPs(t *testing.T) {
+	require.NoError(t, ValidateAllowedIPs(nil))
+	require.NoError(t, ValidateAllowedIPs([]string{"10.0.0.1", "192.168.0.0/16", "2001:db8::/32"}))
+	require.Error(t, ValidateAllowedIPs([]string{"10.0.0.300"}))
+	require.Error(t, ValidateAllowedIPs([]string{"internal"}))
+}
+
+func TestIPAllowed(t *testing.T) {
+	allowed := []string{"10.0.0.1", "192.168.0.0/16"}
+
+	require.True(t, IPAllowed(nil, "203.0.113.7"))
+	require.True(t, IPAllowed(allowed, "10.0.0.1"))
+	require.True(t, IPAllowed(allowed, "192.168.4.2"))
+	require.False(t, IPAllowed(allowed, "10.0.0.2"))
+	require.False(t, IPAllowed(allowed, ""))
+}
+
+func TestVerifyKey(t *testing.T) {
+	key, _, err := Generate()
+	require.NoError(t, err)
+
+	keyID := uuid.New()
+
+	activeKey := db.GetApiKeyByHashRow{
+		ID:             keyID,
+		ServiceAccount: "batch",
+		Scopes:         []string{"account:read:any"},
+		AllowedIps:     []string{"10.0.0.0/8"},
+		Role:           util.BankerRole,
+	}
+
+	testCases := []struct {
+		name     string
+		key      string
+		clientIP string
+		row      db.GetApiKeyByHashRow
+		err      error
+		check    func(t *testing.T, payload *token.Payload, err error)
+	}{
+		{
+			name:     "OK",
+			key:      key,
+			clientIP: "10.1.2.3",
+			row:      activeKey,
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Equal(t, Principal("batch"), payload.UserName)
+				require.Equal(t, util.BankerRole, payload.Role)
+				require.Equal(t, []string{"account:read:any"}, payload.Scopes)
+				require.Equal(t, keyID.String(), payload.ID)
+				require.Equal(t, uuid.Nil, payload.SessionID)
+			},
+		},
+		{
+			name:     "NoScopes",
+			key:      key,
+			clientIP: "10.1.2.3",
+			row:      db.GetApiKeyByHashRow{ID: keyID, ServiceAccount: "batch", Role: util.BankerRole},
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				// An empty scope list grants nothing rather than the whole role
+				require.NotNil(t, payload.Scopes)
+				require.Empty(t, payload.Scopes)
+			},
+		},
+		{
+			name: "NotFound",
+			key:  key,
+			err:  db.ErrRecordNotFound,
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrKeyNotFound)
+				require.True(t, IsInvalidKey(err))
+			},
+		},
+		{
+			name:     "Revoked",
+			key:      key,
+			clientIP: "10.1.2.3",
+			row: func() db.GetApiKeyByHashRow {
+				row := activeKey
+				row.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+				return row
+			}(),
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrKeyRevoked)
+			},
+		},
+		{
+			name:     "Expired",
+			key:      key,
+			clientIP: "10.1.2.3",
+			row: func() db.GetApiKeyByHashRow {
+				row := activeKey
+				row.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+				return row
+			}(),
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrKeyExpired)
+			},
+		},
+		{
+			name:     "IPNotAllowed",
+			key:      key,
+			clientIP: "203.0.113.7",
+			row:      activeKey,
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrIPNotAllowed)
+			},
+		},
+		{
+			name: "LookupFailed",
+			key:  key,
+			err:  sql.ErrConnDone,
+			check: func(t *testing.T, payload *token.Payload, err error) {
+				require.Error(t, err)
+				// A database failure must not look like a bad key
+				require.False(t, IsInvalidKey(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetApiKeyByHash(gomock.Any(), gomock.Eq(token.HashToken(tc.key))).
+				Times(1).
+				Return(tc.row, tc.err)
+
+			payload, err := NewStoreVerifier(store).VerifyKey(context.Background(), tc.key, tc.clientIP)
+			tc.check(t, payload, err)
+		})
+	}
+}
+
+func TestVerifyKeyWithoutPrefix(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetApiKeyByHash(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	_, err := NewStoreVerifier(store).VerifyKey(context.Background(), "not-a-key", "10.1.2.3")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
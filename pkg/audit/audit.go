@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"encoding/json"
+	"sort"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+)
+
+// Actions recorded in the audit log, reads are logged too since they expose customer data
+const (
+	SearchUsers       = "user.search"
+	ListUserAccounts  = "user.accounts.list"
+	ListUserTransfers = "user.transfers.list"
+	UpdateUser        = "user.update"
+	UpdateUserRole    = "user.role.update"
+	ForceVerifyEmail  = "user.email.reverify"
+	GetUserKyc        = "user.kyc.read"
+	UpdateUserKyc     = "user.kyc.update"
+	ListKycDocuments  = "user.kyc.documents.list"
+	GetKycDocument    = "kyc_document.read"
+	ReviewKycDocument = "kyc_document.review"
+	ListUserSessions  = "user.sessions.list"
+	BlockSession      = "session.block"
+	UnblockSession    = "session.unblock"
+	ListAuditLogs     = "audit.list"
+
+	CreateServiceAccount = "service_account.create"
+	ListServiceAccounts  = "service_account.list"
+	CreateApiKey         = "api_key.create"
+	ListApiKeys          = "api_key.list"
+	RevokeApiKey         = "api_key.revoke"
+)
+
+// Entry builds the audit log of an action, details are stored as a JSON object
+func Entry(actor, action, target, clientIP string, details map[string]any) db.CreateAuditLogParams {
+	metadata, err := json.Marshal(details)
+	// A map of plain values always encodes, keep the log rather than failing the action
+	if err != nil || details == nil {
+		metadata = []byte("{}")
+	}
+
+	return db.CreateAuditLogParams{
+		Actor:    actor,
+		Action:   action,
+		Target:   target,
+		Metadata: metadata,
+		ClientIp: clientIP,
+	}
+}
+
+// ProfileUpdate builds the details of a profile update, the new email is kept since whoever
+// controls it can reset the password of the user
+func ProfileUpdate(arg db.UpdateUserParams) map[string]any {
+	fields := []string{}
+	for name, valid := range map[string]bool{
+		"full_name":     arg.FullName.Valid,
+		"email":         arg.Email.Valid,
+		"date_of_birth": arg.DateOfBirth.Valid,
+		"phone":         arg.Phone.Valid,
+		"address":       arg.Address.Valid,
+		"nationality":   arg.Nationality.Valid,
+	} {
+		if valid {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+
+	details := map[string]any{"fields": fields}
+	if arg.Email.Valid {
+		details["email"] = arg.Email.String
+	}
+
+	return details
+}
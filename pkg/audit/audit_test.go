@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"testing"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntry(t *testing.T) {
+	entry := Entry("banker", UpdateUserRole, "alice", "127.0.0.1", map[string]any{"role": "banker"})
+
+	require.Equal(t, "banker", entry.Actor)
+	require.Equal(t, UpdateUserRole, entry.Action)
+	require.Equal(t, "alice", entry.Target)
+	require.Equal(t, "127.0.0.1", entry.ClientIp)
+	require.JSONEq(t, `{"role":"banker"}`, string(entry.Metadata))
+
+	entry = Entry("banker", SearchUsers, "", "", nil)
+	require.JSONEq(t, `{}`, string(entry.Metadata))
+
+	// Values that cannot be encoded still produce a valid log
+	entry = Entry("banker", SearchUsers, "", "", map[string]any{"bad": func() {}})
+	require.JSONEq(t, `{}`, string(entry.Metadata))
+}
+
+func TestProfileUpdate(t *testing.T) {
+	details := ProfileUpdate(db.UpdateUserParams{
+		Username: "alice",
+		Phone:    pgtype.Text{String: "+15550100", Valid: true},
+		Email:    pgtype.Text{String: "alice@example.com", Valid: true},
+	})
+
+	require.Equal(t, []string{"email", "phone"}, details["fields"])
+	require.Equal(t, "alice@example.com", details["email"])
+
+	details = ProfileUpdate(db.UpdateUserParams{
+		Username: "alice",
+		FullName: pgtype.Text{String: "Alice", Valid: true},
+	})
+
+	require.Equal(t, []string{"full_name"}, details["fields"])
+	require.NotContains(t, details, "email")
+}
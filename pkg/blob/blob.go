@@ -0,0 +1,55 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+)
+
+const (
+	DriverLocal  = "local"
+	DriverS3     = "s3"
+	DriverMemory = "memory"
+)
+
+var (
+	ErrBlobNotFound = errors.New("blob not found")
+	ErrInvalidKey   = errors.New("invalid blob key")
+)
+
+// BlobStore keeps opaque objects, such as uploaded documents, under slash separated keys
+type BlobStore interface {
+	// Put stores data under key, replacing what was stored there
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the data stored under key or ErrBlobNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes the data stored under key, deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBlobStore creates the store selected by BLOB_STORE_DRIVER
+func NewBlobStore(ctx context.Context, config *pkg.Config) (BlobStore, error) {
+	switch config.BlobStoreDriver {
+	case DriverLocal:
+		return NewLocalStore(config.BlobLocalDir), nil
+	case DriverS3:
+		return NewS3Store(ctx, config)
+	case DriverMemory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unsupported blob store driver %q", config.BlobStoreDriver)
+	}
+}
+
+// validateKey rejects keys that could escape the namespace of the store
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package blob
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChokeGuy/simple-bank/pkg/encryption"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// testBlobStore runs the behavior every BlobStore has to share
+func testBlobStore(t *testing.T, store BlobStore) {
+	ctx := context.Background()
+	key := "kyc/" + util.RandomOwner() + "/passport"
+	data := []byte(util.RandomString(64))
+
+	_, err := store.Get(ctx, key)
+	require.ErrorIs(t, err, ErrBlobNotFound)
+
+	require.NoError(t, store.Put(ctx, key, data))
+
+	stored, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, data, stored)
+
+	// Put replaces the blob
+	replaced := []byte(util.RandomString(32))
+	require.NoError(t, store.Put(ctx, key, replaced))
+
+	stored, err = store.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, replaced, stored)
+
+	require.NoError(t, store.Delete(ctx, key))
+	require.NoError(t, store.Delete(ctx, key))
+
+	_, err = store.Get(ctx, key)
+	require.ErrorIs(t, err, ErrBlobNotFound)
+
+	for _, invalid := range []string{"", "/etc/passwd", "../secret", "kyc/../../secret", "kyc//passport"} {
+		require.ErrorIs(t, store.Put(ctx, invalid, data), ErrInvalidKey, invalid)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testBlobStore(t, NewMemoryStore())
+}
+
+func TestLocalStore(t *testing.T) {
+	root := t.TempDir()
+	testBlobStore(t, NewLocalStore(root))
+
+	// Only the blobs end up on disk, temporary files are cleaned up
+	store := NewLocalStore(root)
+	require.NoError(t, store.Put(context.Background(), "a/b", []byte("data")))
+
+	entries, err := os.ReadDir(filepath.Join(root, "a"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "b", entries[0].Name())
+}
+
+func TestEncryptedStore(t *testing.T) {
+	cipher, err := encryption.NewAESCipher(util.RandomString(encryption.KeySize))
+	require.NoError(t, err)
+
+	backend := NewMemoryStore()
+	testBlobStore(t, NewEncryptedStore(backend, cipher))
+
+	ctx := context.Background()
+	data := []byte(util.RandomString(64))
+	require.NoError(t, NewEncryptedStore(backend, cipher).Put(ctx, "doc", data))
+
+	// The backend only holds ciphertext
+	stored, err := backend.Get(ctx, "doc")
+	require.NoError(t, err)
+	require.NotContains(t, string(stored), string(data))
+
+	// A blob sealed with another key cannot be read back
+	other, err := encryption.NewAESCipher(util.RandomString(encryption.KeySize))
+	require.NoError(t, err)
+
+	_, err = NewEncryptedStore(backend, other).Get(ctx, "doc")
+	require.ErrorIs(t, err, encryption.ErrInvalidCiphertext)
+}
@@ -0,0 +1,43 @@
+package blob
+
+import (
+	"context"
+
+	"github.com/ChokeGuy/simple-bank/pkg/encryption"
+)
+
+// EncryptedStore seals every blob before handing it to the wrapped store,
+// so the backend, its backups and its operators only ever see ciphertext
+type EncryptedStore struct {
+	store  BlobStore
+	cipher encryption.Cipher
+}
+
+// NewEncryptedStore wraps store with cipher
+func NewEncryptedStore(store BlobStore, cipher encryption.Cipher) *EncryptedStore {
+	return &EncryptedStore{store: store, cipher: cipher}
+}
+
+func (s *EncryptedStore) Put(ctx context.Context, key string, data []byte) error {
+	ciphertext, err := s.cipher.Encrypt(data)
+
+	if err != nil {
+		return err
+	}
+
+	return s.store.Put(ctx, key, ciphertext)
+}
+
+func (s *EncryptedStore) Get(ctx context.Context, key string) ([]byte, error) {
+	ciphertext, err := s.store.Get(ctx, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cipher.Decrypt(ciphertext)
+}
+
+func (s *EncryptedStore) Delete(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, key)
+}
@@ -0,0 +1,126 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Store keeps the blobs as objects of an S3 bucket
+type S3Store struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Store creates an S3Store for BLOB_S3_BUCKET with the AWS credentials of the config
+func NewS3Store(ctx context.Context, envCfg *pkg.Config) (*S3Store, error) {
+	if envCfg.BlobS3Bucket == "" {
+		return nil, errors.New("BLOB_S3_BUCKET is required by the s3 blob store")
+	}
+
+	cfg, err := config.LoadDefaultConfig(
+		ctx,
+		config.WithRegion(envCfg.AWSRegion),
+		config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(
+				envCfg.AWSAcessKeyID,
+				envCfg.AWSSecretKey,
+				"")),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %v", err)
+	}
+
+	return &S3Store{
+		client: s3.NewFromConfig(cfg),
+		bucket: envCfg.BlobS3Bucket,
+		prefix: envCfg.BlobS3Prefix,
+	}, nil
+}
+
+func (s *S3Store) objectKey(key string) (*string, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	return aws.String(s.prefix + key), nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	objectKey, err := s.objectKey(key)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(s.bucket),
+		Key:                  objectKey,
+		Body:                 bytes.NewReader(data),
+		ContentLength:        aws.Int64(int64(len(data))),
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to put object: %w", err)
+	}
+
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	objectKey, err := s.objectKey(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    objectKey,
+	})
+
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrBlobNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	defer output.Body.Close()
+
+	return io.ReadAll(output.Body)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	objectKey, err := s.objectKey(key)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    objectKey,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+
+	return nil
+}
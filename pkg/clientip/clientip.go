@@ -0,0 +1,130 @@
+// Package clientip finds the address of a client behind the reverse proxies the server trusts
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Resolver picks the client address out of a chain of forwarding hops
+type Resolver struct {
+	proxies []string
+	trusted []netip.Prefix
+}
+
+// NewResolver creates a Resolver trusting the comma separated IPs and CIDR ranges of proxies.
+// With no proxies only the address of the connection is used, forwarding headers are ignored.
+func NewResolver(proxies string) (*Resolver, error) {
+	resolver := &Resolver{}
+
+	for _, proxy := range strings.Split(proxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(proxy)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		resolver.proxies = append(resolver.proxies, proxy)
+		resolver.trusted = append(resolver.trusted, prefix.Masked())
+	}
+
+	return resolver, nil
+}
+
+// Proxies returns the trusted proxies in the form accepted by gin.Engine.SetTrustedProxies
+func (r *Resolver) Proxies() []string {
+	return r.proxies
+}
+
+func (r *Resolver) isTrusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Resolve returns the client address of hops, ordered like X-Forwarded-For with the address of the
+// connection last. Hops are walked from the right, the first one not added by a trusted proxy is the
+// client. Anything left of it could have been written by the client itself and is never looked at.
+func (r *Resolver) Resolve(hops []string) string {
+	var client string
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := stripPort(strings.TrimSpace(hops[i]))
+
+		addr, err := netip.ParseAddr(hop)
+		if err != nil {
+			break
+		}
+
+		client = addr.Unmap().String()
+		if !r.isTrusted(addr) {
+			break
+		}
+	}
+
+	return client
+}
+
+// ForwardedHops splits the values of X-Forwarded-For headers into their hops
+func ForwardedHops(values []string) []string {
+	var hops []string
+
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+
+	return hops
+}
+
+func stripPort(hop string) string {
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		return host
+	}
+
+	return hop
+}
+
+type resolverContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the resolver
+func NewContext(ctx context.Context, r *Resolver) context.Context {
+	return context.WithValue(ctx, resolverContextKey{}, r)
+}
+
+// FromContext returns the resolver of ctx, without one no proxy is trusted
+func FromContext(ctx context.Context) *Resolver {
+	if r, ok := ctx.Value(resolverContextKey{}).(*Resolver); ok {
+		return r
+	}
+
+	return &Resolver{}
+}
+
+// Handler makes the resolver available to the handlers behind next, such as the gRPC gateway
+func (r *Resolver) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(w, req.WithContext(NewContext(req.Context(), r)))
+	})
+}
@@ -0,0 +1,47 @@
+package clientip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	resolver, err := NewResolver(" 10.0.0.0/8, 192.168.1.10 ,")
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.0/8", "192.168.1.10"}, resolver.Proxies())
+
+	testCases := []struct {
+		name string
+		hops []string
+		ip   string
+	}{
+		{"Direct", []string{"203.0.113.7:5555"}, "203.0.113.7"},
+		{"UntrustedPeerIgnoresHeader", []string{"1.1.1.1", "203.0.113.7"}, "203.0.113.7"},
+		{"TrustedProxy", []string{"203.0.113.7", "10.1.2.3"}, "203.0.113.7"},
+		{"ForgedLeftmostHop", []string{"1.1.1.1", "203.0.113.7", "192.168.1.10", "10.1.2.3"}, "203.0.113.7"},
+		{"OnlyTrustedHops", []string{"10.9.9.9", "10.1.2.3"}, "10.9.9.9"},
+		{"GarbageHop", []string{"not-an-ip", "10.1.2.3"}, "10.1.2.3"},
+		{"MappedAddress", []string{"::ffff:203.0.113.7", "10.1.2.3"}, "203.0.113.7"},
+		{"Empty", nil, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.ip, resolver.Resolve(tc.hops))
+		})
+	}
+
+	// Without trusted proxies the connection is the client
+	require.Equal(t, "10.1.2.3", FromContext(context.Background()).Resolve([]string{"203.0.113.7", "10.1.2.3"}))
+	require.Same(t, resolver, FromContext(NewContext(context.Background(), resolver)))
+
+	_, err = NewResolver("10.0.0.0/33")
+	require.Error(t, err)
+}
+
+func TestForwardedHops(t *testing.T) {
+	require.Equal(t, []string{"1.1.1.1", "203.0.113.7", "10.1.2.3"}, ForwardedHops([]string{"1.1.1.1, 203.0.113.7", " 10.1.2.3 "}))
+	require.Empty(t, ForwardedHops(nil))
+}
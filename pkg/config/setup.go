@@ -8,23 +8,91 @@ import (
 
 // Config is the configuration for the application
 type Config struct {
-	ENV                  string        `mapstructure:"ENV"`
-	ApiUrl               string        `mapstructure:"API_URL"`
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"POSTGRES_URL"`
-	MigrationUrl         string        `mapstructure:"MIGRATION_URL"`
-	RedisAddress         string        `mapstructure:"REDIS_ADDRESS"`
-	HttpServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GrpcServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	SymetricKey          string        `mapstructure:"SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	EmailSenderName      string        `mapstructure:"EMAIL_SENDER_NAME"`
-	EmailSenderAddress   string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
-	EmailSenderPassword  string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
-	AWSRegion            string        `mapstructure:"AWS_REGION"`
-	AWSAcessKeyID        string        `mapstructure:"AWS_ACCESS_KEY_ID"`
-	AWSSecretKey         string        `mapstructure:"AWS_SECRET_ACCESS_KEY"`
+	ENV                       string        `mapstructure:"ENV"`
+	ApiUrl                    string        `mapstructure:"API_URL"`
+	DBDriver                  string        `mapstructure:"DB_DRIVER"`
+	DBSource                  string        `mapstructure:"POSTGRES_URL"`
+	MigrationUrl              string        `mapstructure:"MIGRATION_URL"`
+	RedisAddress              string        `mapstructure:"REDIS_ADDRESS"`
+	HttpServerAddress         string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	GrpcServerAddress         string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TrustedProxies            string        `mapstructure:"TRUSTED_PROXIES"`
+	MetricsServerAddress      string        `mapstructure:"METRICS_SERVER_ADDRESS"`
+	SymetricKey               string        `mapstructure:"SYMMETRIC_KEY"`
+	TokenMaker                string        `mapstructure:"TOKEN_MAKER"`
+	PasswordHashAlgorithm     string        `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	Argon2Memory              uint32        `mapstructure:"ARGON2_MEMORY"`
+	Argon2Iterations          uint32        `mapstructure:"ARGON2_ITERATIONS"`
+	Argon2Parallelism         uint8         `mapstructure:"ARGON2_PARALLELISM"`
+	BcryptCost                int           `mapstructure:"BCRYPT_COST"`
+	PasswordMinLength         int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMinClasses        int           `mapstructure:"PASSWORD_MIN_CLASSES"`
+	PasswordMaxRepeated       int           `mapstructure:"PASSWORD_MAX_REPEATED"`
+	PasswordBlocklistSize     int           `mapstructure:"PASSWORD_BLOCKLIST_SIZE"`
+	PasswordHistorySize       int           `mapstructure:"PASSWORD_HISTORY_SIZE"`
+	TokenKeysDir              string        `mapstructure:"TOKEN_KEYS_DIR"`
+	TokenSigningKeyID         string        `mapstructure:"TOKEN_SIGNING_KEY_ID"`
+	AccessTokenDuration       time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration      time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	EmailSenderName           string        `mapstructure:"EMAIL_SENDER_NAME"`
+	EmailSenderAddress        string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
+	EmailSenderPassword       string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
+	AWSRegion                 string        `mapstructure:"AWS_REGION"`
+	AWSAcessKeyID             string        `mapstructure:"AWS_ACCESS_KEY_ID"`
+	AWSSecretKey              string        `mapstructure:"AWS_SECRET_ACCESS_KEY"`
+	OutboxRelayInterval       time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	OutboxBatchSize           int32         `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxRetention           time.Duration `mapstructure:"OUTBOX_RETENTION"`
+	OutboxMaxAttempts         int32         `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	WebhookTimeout            time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookMaxRetry           int32         `mapstructure:"WEBHOOK_MAX_RETRY"`
+	WebhookSecretGrace        time.Duration `mapstructure:"WEBHOOK_SECRET_GRACE"`
+	MaxSessionsPerUser        int32         `mapstructure:"MAX_SESSIONS_PER_USER"`
+	SessionCacheTTL           time.Duration `mapstructure:"SESSION_CACHE_TTL"`
+	TotpEncryptionKey         string        `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	TotpIssuer                string        `mapstructure:"TOTP_ISSUER"`
+	MfaChallengeDuration      time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	LoginMaxFailures          int64         `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginIPMaxFailures        int64         `mapstructure:"LOGIN_IP_MAX_FAILURES"`
+	LoginFailureWindow        time.Duration `mapstructure:"LOGIN_FAILURE_WINDOW"`
+	LoginLockoutDuration      time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	LoginBaseDelay            time.Duration `mapstructure:"LOGIN_BASE_DELAY"`
+	RequireVerifiedEmail      bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL"`
+	VerifyEmailResendMax      int64         `mapstructure:"VERIFY_EMAIL_RESEND_MAX"`
+	VerifyEmailResendInterval time.Duration `mapstructure:"VERIFY_EMAIL_RESEND_INTERVAL"`
+	ResetPasswordUrl          string        `mapstructure:"RESET_PASSWORD_URL"`
+	DenyLoginUrl              string        `mapstructure:"DENY_LOGIN_URL"`
+	StepUpMaxAge              time.Duration `mapstructure:"STEP_UP_MAX_AGE"`
+	StepUpTokenDuration       time.Duration `mapstructure:"STEP_UP_TOKEN_DURATION"`
+	StepUpTransferThresholds  string        `mapstructure:"STEP_UP_TRANSFER_THRESHOLDS"`
+	StepUpMinStrength         string        `mapstructure:"STEP_UP_MIN_STRENGTH"`
+	KycBasicDailyLimits       string        `mapstructure:"KYC_BASIC_DAILY_LIMITS"`
+	KycVerifiedDailyLimits    string        `mapstructure:"KYC_VERIFIED_DAILY_LIMITS"`
+	KycDocumentEncryptionKey  string        `mapstructure:"KYC_DOCUMENT_ENCRYPTION_KEY"`
+	KycDocumentMaxSize        int64         `mapstructure:"KYC_DOCUMENT_MAX_SIZE"`
+	BlobStoreDriver           string        `mapstructure:"BLOB_STORE_DRIVER"`
+	BlobLocalDir              string        `mapstructure:"BLOB_LOCAL_DIR"`
+	BlobS3Bucket              string        `mapstructure:"BLOB_S3_BUCKET"`
+	BlobS3Prefix              string        `mapstructure:"BLOB_S3_PREFIX"`
+	SmsSenderDriver           string        `mapstructure:"SMS_SENDER_DRIVER"`
+	SmsSenderName             string        `mapstructure:"SMS_SENDER_NAME"`
+	SmsConsoleFile            string        `mapstructure:"SMS_CONSOLE_FILE"`
+	SmsHTTPUrl                string        `mapstructure:"SMS_HTTP_URL"`
+	SmsHTTPToken              string        `mapstructure:"SMS_HTTP_TOKEN"`
+	SmsHTTPTimeout            time.Duration `mapstructure:"SMS_HTTP_TIMEOUT"`
+	PhoneCodeDuration         time.Duration `mapstructure:"PHONE_CODE_DURATION"`
+	PhoneCodeSendMax          int64         `mapstructure:"PHONE_CODE_SEND_MAX"`
+	PhoneCodeSendInterval     time.Duration `mapstructure:"PHONE_CODE_SEND_INTERVAL"`
+	PhoneCodeKey              string        `mapstructure:"PHONE_CODE_KEY"`
+	OidcIssuerUrl             string        `mapstructure:"OIDC_ISSUER_URL"`
+	OidcClientID              string        `mapstructure:"OIDC_CLIENT_ID"`
+	OidcClientSecret          string        `mapstructure:"OIDC_CLIENT_SECRET"`
+	OidcRedirectUrl           string        `mapstructure:"OIDC_REDIRECT_URL"`
+	OidcScopes                string        `mapstructure:"OIDC_SCOPES"`
+	OidcRoleClaim             string        `mapstructure:"OIDC_ROLE_CLAIM"`
+	OidcRoleMapping           string        `mapstructure:"OIDC_ROLE_MAPPING"`
+	OidcStateDuration         time.Duration `mapstructure:"OIDC_STATE_DURATION"`
+	OidcHTTPTimeout           time.Duration `mapstructure:"OIDC_HTTP_TIMEOUT"`
 }
 
 // LoadConfig loads the configuration from the file
@@ -37,6 +105,60 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
 
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("METRICS_SERVER_ADDRESS", "127.0.0.1:9090")
+	viper.SetDefault("TOKEN_MAKER", "paseto")
+	viper.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	viper.SetDefault("ARGON2_MEMORY", 64*1024)
+	viper.SetDefault("ARGON2_ITERATIONS", 3)
+	viper.SetDefault("ARGON2_PARALLELISM", 4)
+	viper.SetDefault("BCRYPT_COST", 10)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 8)
+	viper.SetDefault("PASSWORD_MIN_CLASSES", 4)
+	viper.SetDefault("PASSWORD_MAX_REPEATED", 3)
+	viper.SetDefault("PASSWORD_BLOCKLIST_SIZE", 1000)
+	viper.SetDefault("PASSWORD_HISTORY_SIZE", 5)
+	viper.SetDefault("OUTBOX_RELAY_INTERVAL", time.Second)
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_RETENTION", 7*24*time.Hour)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 10)
+	viper.SetDefault("WEBHOOK_TIMEOUT", 10*time.Second)
+	viper.SetDefault("WEBHOOK_MAX_RETRY", 8)
+	viper.SetDefault("WEBHOOK_SECRET_GRACE", 24*time.Hour)
+	viper.SetDefault("MAX_SESSIONS_PER_USER", 5)
+	viper.SetDefault("SESSION_CACHE_TTL", 30*time.Second)
+	viper.SetDefault("TOTP_ISSUER", "Simple Bank")
+	viper.SetDefault("MFA_CHALLENGE_DURATION", 5*time.Minute)
+	viper.SetDefault("LOGIN_MAX_FAILURES", 5)
+	viper.SetDefault("LOGIN_IP_MAX_FAILURES", 50)
+	viper.SetDefault("LOGIN_FAILURE_WINDOW", 15*time.Minute)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+	viper.SetDefault("LOGIN_BASE_DELAY", time.Second)
+	viper.SetDefault("REQUIRE_VERIFIED_EMAIL", true)
+	viper.SetDefault("VERIFY_EMAIL_RESEND_MAX", 5)
+	viper.SetDefault("VERIFY_EMAIL_RESEND_INTERVAL", time.Minute)
+	viper.SetDefault("RESET_PASSWORD_URL", "http://localhost:3000/reset-password")
+	viper.SetDefault("DENY_LOGIN_URL", "http://localhost:3000/deny-login")
+	viper.SetDefault("STEP_UP_MAX_AGE", 5*time.Minute)
+	viper.SetDefault("STEP_UP_TOKEN_DURATION", 5*time.Minute)
+	viper.SetDefault("STEP_UP_TRANSFER_THRESHOLDS", "USD:1000,EUR:1000,CAD:1000,VND:25000000")
+	viper.SetDefault("STEP_UP_MIN_STRENGTH", "pwd")
+	viper.SetDefault("KYC_BASIC_DAILY_LIMITS", "USD:2000,EUR:2000,CAD:2000,VND:50000000")
+	viper.SetDefault("KYC_VERIFIED_DAILY_LIMITS", "USD:50000,EUR:50000,CAD:50000,VND:1250000000")
+	viper.SetDefault("KYC_DOCUMENT_MAX_SIZE", 5*1024*1024)
+	viper.SetDefault("BLOB_STORE_DRIVER", "local")
+	viper.SetDefault("BLOB_LOCAL_DIR", "data/blobs")
+	viper.SetDefault("SMS_SENDER_DRIVER", "console")
+	viper.SetDefault("SMS_SENDER_NAME", "SimpleBank")
+	viper.SetDefault("SMS_HTTP_TIMEOUT", 10*time.Second)
+	viper.SetDefault("PHONE_CODE_DURATION", 10*time.Minute)
+	viper.SetDefault("PHONE_CODE_SEND_MAX", 5)
+	viper.SetDefault("PHONE_CODE_SEND_INTERVAL", time.Minute)
+	viper.SetDefault("OIDC_SCOPES", "openid email profile")
+	viper.SetDefault("OIDC_ROLE_CLAIM", "groups")
+	viper.SetDefault("OIDC_STATE_DURATION", 10*time.Minute)
+	viper.SetDefault("OIDC_HTTP_TIMEOUT", 10*time.Second)
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		return
@@ -0,0 +1,76 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+const KeySize = 32
+
+var (
+	ErrInvalidKeySize    = fmt.Errorf("invalid key size: must be exactly %d characters", KeySize)
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// Cipher encrypts secrets that have to be stored at rest and read back later
+type Cipher interface {
+	// Encrypt seals the plaintext, the nonce is prepended to the returned ciphertext
+	Encrypt(plaintext []byte) ([]byte, error)
+	// Decrypt opens a ciphertext produced by Encrypt
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// AESCipher is an AES-256-GCM Cipher
+type AESCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESCipher creates a new AESCipher
+func NewAESCipher(key string) (Cipher, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKeySize
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &AESCipher{aead: aead}, nil
+}
+
+func (c *AESCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *AESCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+
+	if len(ciphertext) < nonceSize {
+		return nil, ErrInvalidCiphertext
+	}
+
+	plaintext, err := c.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return plaintext, nil
+}
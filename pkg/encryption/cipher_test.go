@@ -0,0 +1,61 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESCipher(t *testing.T) {
+	c, err := NewAESCipher(util.RandomString(KeySize))
+	require.NoError(t, err)
+
+	plaintext := []byte(util.RandomString(20))
+
+	ciphertext, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	require.NotContains(t, string(ciphertext), string(plaintext))
+
+	// Every encryption uses a fresh nonce
+	other, err := c.Encrypt(plaintext)
+	require.NoError(t, err)
+	require.NotEqual(t, ciphertext, other)
+
+	decrypted, err := c.Decrypt(ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+}
+
+func TestAESCipherTampered(t *testing.T) {
+	c, err := NewAESCipher(util.RandomString(KeySize))
+	require.NoError(t, err)
+
+	ciphertext, err := c.Encrypt([]byte(util.RandomString(20)))
+	require.NoError(t, err)
+
+	ciphertext[len(ciphertext)-1] ^= 0xff
+
+	_, err = c.Decrypt(ciphertext)
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = c.Decrypt(ciphertext[:4])
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestAESCipherWrongKey(t *testing.T) {
+	c, err := NewAESCipher(util.RandomString(KeySize))
+	require.NoError(t, err)
+
+	ciphertext, err := c.Encrypt([]byte(util.RandomString(20)))
+	require.NoError(t, err)
+
+	other, err := NewAESCipher(util.RandomString(KeySize))
+	require.NoError(t, err)
+
+	_, err = other.Decrypt(ciphertext)
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = NewAESCipher(util.RandomString(16))
+	require.ErrorIs(t, err, ErrInvalidKeySize)
+}
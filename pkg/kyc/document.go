@@ -0,0 +1,226 @@
+package kyc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/blob"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/encryption"
+	"github.com/google/uuid"
+)
+
+// Kinds of documents users can upload
+const (
+	DocumentPassport       = "passport"
+	DocumentNationalID     = "national_id"
+	DocumentDrivingLicense = "driving_license"
+	DocumentProofOfAddress = "proof_of_address"
+)
+
+// Review statuses of a document, every document starts pending
+const (
+	DocumentPending  = "pending"
+	DocumentApproved = "approved"
+	DocumentRejected = "rejected"
+)
+
+// maxFileNameLength bounds the file name kept for a document
+const maxFileNameLength = 100
+
+// documentTypes lists the accepted content types with the extensions a file of that type may have
+var documentTypes = map[string][]string{
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/png":       {".png"},
+	"application/pdf": {".pdf"},
+}
+
+var (
+	ErrEmptyDocument           = errors.New("document is empty")
+	ErrDocumentTooLarge        = errors.New("document is too large")
+	ErrUnsupportedDocumentType = errors.New("only JPEG, PNG and PDF documents are accepted")
+	// ErrDocumentTypeMismatch is returned when the content of a file is not what its name and declared type claim
+	ErrDocumentTypeMismatch = errors.New("document content does not match its type")
+	// ErrDocumentsLocked is returned when documents are uploaded while a submission is pending or verified
+	ErrDocumentsLocked = errors.New("documents cannot be changed while the KYC is pending or verified")
+	// ErrDocumentNotSubmitted is returned when a banker reviews a document its user did not submit yet
+	ErrDocumentNotSubmitted = errors.New("document was not submitted for review")
+	ErrDocumentCorrupted    = errors.New("document content does not match its checksum")
+)
+
+func IsValidDocumentKind(kind string) bool {
+	switch kind {
+	case DocumentPassport, DocumentNationalID, DocumentDrivingLicense, DocumentProofOfAddress:
+		return true
+	}
+	return false
+}
+
+// IsDocumentDecision reports whether a banker may set a document to status
+func IsDocumentDecision(status string) bool {
+	return status == DocumentApproved || status == DocumentRejected
+}
+
+// CanReviewDocument checks that a banker may decide on a document, only pending documents
+// sent with a submission can be approved or rejected
+func CanReviewDocument(document db.KycDocument) error {
+	if !document.ReviewID.Valid {
+		return ErrDocumentNotSubmitted
+	}
+
+	if document.Status != DocumentPending {
+		return ErrInvalidTransition
+	}
+
+	return nil
+}
+
+// Upload is a document received from a user
+type Upload struct {
+	Kind     string
+	FileName string
+	// ContentType is the type declared by the client, it is checked against the content
+	ContentType string
+	Data        []byte
+}
+
+// Documents keeps the content of the KYC documents in a blob store and their metadata in the database
+type Documents struct {
+	blobs   blob.BlobStore
+	maxSize int64
+}
+
+// NewDocuments creates the blob store of the config, documents are encrypted with KYC_DOCUMENT_ENCRYPTION_KEY before they reach it
+func NewDocuments(ctx context.Context, config *pkg.Config) (*Documents, error) {
+	cipher, err := encryption.NewAESCipher(config.KycDocumentEncryptionKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create KYC document cipher: %w", err)
+	}
+
+	store, err := blob.NewBlobStore(ctx, config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDocumentsWithStore(blob.NewEncryptedStore(store, cipher), config.KycDocumentMaxSize), nil
+}
+
+// NewDocumentsWithStore keeps the documents in blobs as they are given
+func NewDocumentsWithStore(blobs blob.BlobStore, maxSize int64) *Documents {
+	return &Documents{blobs: blobs, maxSize: maxSize}
+}
+
+// MaxSize is the size in bytes of the largest accepted document
+func (d *Documents) MaxSize() int64 {
+	return d.maxSize
+}
+
+// Check validates an upload and returns the content type sniffed from its data.
+// The declared type and the file extension must agree with the content so a renamed file is refused.
+func (d *Documents) Check(upload Upload) (string, error) {
+	if len(upload.Data) == 0 {
+		return "", ErrEmptyDocument
+	}
+
+	if int64(len(upload.Data)) > d.maxSize {
+		return "", ErrDocumentTooLarge
+	}
+
+	declared := strings.ToLower(strings.TrimSpace(strings.Split(upload.ContentType, ";")[0]))
+	extensions, ok := documentTypes[declared]
+
+	if !ok {
+		return "", ErrUnsupportedDocumentType
+	}
+
+	sniffed := strings.Split(http.DetectContentType(upload.Data), ";")[0]
+
+	if sniffed != declared {
+		return "", ErrDocumentTypeMismatch
+	}
+
+	extension := strings.ToLower(path.Ext(upload.FileName))
+
+	for _, allowed := range extensions {
+		if extension == allowed {
+			return sniffed, nil
+		}
+	}
+
+	return "", ErrDocumentTypeMismatch
+}
+
+// Upload stores the document of a user who may still change their documents.
+// The content is stored first, it is removed again when the metadata cannot be saved.
+func (d *Documents) Upload(ctx context.Context, q db.Querier, user db.GetUserByUserNameRow, upload Upload) (db.KycDocument, error) {
+	if user.KycStatus != Unverified && user.KycStatus != Rejected {
+		return db.KycDocument{}, ErrDocumentsLocked
+	}
+
+	contentType, err := d.Check(upload)
+
+	if err != nil {
+		return db.KycDocument{}, err
+	}
+
+	checksum := sha256.Sum256(upload.Data)
+	arg := db.CreateKycDocumentParams{
+		Username:    user.Username,
+		Kind:        upload.Kind,
+		FileName:    cleanFileName(upload.FileName),
+		ContentType: contentType,
+		Size:        int64(len(upload.Data)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+		BlobKey:     fmt.Sprintf("kyc/%s/%s", user.Username, uuid.New()),
+	}
+
+	if err := d.blobs.Put(ctx, arg.BlobKey, upload.Data); err != nil {
+		return db.KycDocument{}, fmt.Errorf("failed to store document: %w", err)
+	}
+
+	document, err := q.CreateKycDocument(ctx, arg)
+
+	if err != nil {
+		d.blobs.Delete(ctx, arg.BlobKey)
+		return db.KycDocument{}, err
+	}
+
+	return document, nil
+}
+
+// Content returns the content of a document, checked against the checksum recorded at upload
+func (d *Documents) Content(ctx context.Context, document db.KycDocument) ([]byte, error) {
+	data, err := d.blobs.Get(ctx, document.BlobKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(data)
+
+	if hex.EncodeToString(checksum[:]) != document.Checksum {
+		return nil, ErrDocumentCorrupted
+	}
+
+	return data, nil
+}
+
+// cleanFileName keeps the base name of a client supplied path, it is only shown to bankers
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	if len(name) > maxFileNameLength {
+		name = name[len(name)-maxFileNameLength:]
+	}
+
+	return name
+}
@@ -0,0 +1,120 @@
+package kyc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/blob"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testPNG = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	testPDF = []byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog >>\nendobj\n")
+)
+
+func TestCheckDocument(t *testing.T) {
+	documents := NewDocumentsWithStore(blob.NewMemoryStore(), 1024)
+
+	testCases := []struct {
+		name   string
+		upload Upload
+		err    error
+	}{
+		{"PNG", Upload{FileName: "passport.PNG", ContentType: "image/png", Data: testPNG}, nil},
+		{"PDF", Upload{FileName: "C:\\scans\\bill.pdf", ContentType: "application/pdf", Data: testPDF}, nil},
+		{"Empty", Upload{FileName: "passport.png", ContentType: "image/png"}, ErrEmptyDocument},
+		{"TooLarge", Upload{FileName: "passport.png", ContentType: "image/png", Data: append(testPNG, make([]byte, 1024)...)}, ErrDocumentTooLarge},
+		{"UnsupportedType", Upload{FileName: "passport.gif", ContentType: "image/gif", Data: []byte("GIF89a")}, ErrUnsupportedDocumentType},
+		{"ContentMismatch", Upload{FileName: "passport.png", ContentType: "image/png", Data: testPDF}, ErrDocumentTypeMismatch},
+		{"RenamedFile", Upload{FileName: "passport.exe", ContentType: "image/png", Data: testPNG}, ErrDocumentTypeMismatch},
+		{"PlainText", Upload{FileName: "passport.pdf", ContentType: "application/pdf", Data: []byte("not a pdf")}, ErrDocumentTypeMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			contentType, err := documents.Check(tc.upload)
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.upload.ContentType, contentType)
+		})
+	}
+}
+
+func TestUploadDocument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	blobs := blob.NewMemoryStore()
+	documents := NewDocumentsWithStore(blobs, 1024)
+	user := completeProfile(Unverified)
+	upload := Upload{Kind: DocumentPassport, FileName: "../../scans/passport.png", ContentType: "image/png", Data: testPNG}
+
+	var blobKey string
+
+	store.EXPECT().
+		CreateKycDocument(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateKycDocumentParams) (db.KycDocument, error) {
+			require.Equal(t, user.Username, arg.Username)
+			require.Equal(t, "passport.png", arg.FileName)
+			require.Equal(t, int64(len(testPNG)), arg.Size)
+			require.True(t, strings.HasPrefix(arg.BlobKey, "kyc/"+user.Username+"/"))
+			blobKey = arg.BlobKey
+			return db.KycDocument{Username: arg.Username, BlobKey: arg.BlobKey, Checksum: arg.Checksum}, nil
+		})
+
+	document, err := documents.Upload(context.Background(), store, user, upload)
+	require.NoError(t, err)
+
+	content, err := documents.Content(context.Background(), document)
+	require.NoError(t, err)
+	require.Equal(t, testPNG, content)
+
+	// A blob swapped behind the back of the database is detected
+	require.NoError(t, blobs.Put(context.Background(), blobKey, testPDF))
+	_, err = documents.Content(context.Background(), document)
+	require.ErrorIs(t, err, ErrDocumentCorrupted)
+
+	// The content is removed again when the metadata cannot be saved
+	store.EXPECT().
+		CreateKycDocument(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateKycDocumentParams) (db.KycDocument, error) {
+			blobKey = arg.BlobKey
+			return db.KycDocument{}, errors.New("connection refused")
+		})
+
+	_, err = documents.Upload(context.Background(), store, user, upload)
+	require.Error(t, err)
+
+	_, err = blobs.Get(context.Background(), blobKey)
+	require.ErrorIs(t, err, blob.ErrBlobNotFound)
+
+	for _, status := range []string{Pending, Verified} {
+		_, err = documents.Upload(context.Background(), store, completeProfile(status), upload)
+		require.ErrorIs(t, err, ErrDocumentsLocked)
+	}
+}
+
+func TestCanReviewDocument(t *testing.T) {
+	document := db.KycDocument{Status: DocumentPending}
+	require.ErrorIs(t, CanReviewDocument(document), ErrDocumentNotSubmitted)
+
+	document.ReviewID.Int64, document.ReviewID.Valid = 1, true
+	require.NoError(t, CanReviewDocument(document))
+
+	document.Status = DocumentApproved
+	require.ErrorIs(t, CanReviewDocument(document), ErrInvalidTransition)
+}
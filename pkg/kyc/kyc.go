@@ -0,0 +1,108 @@
+package kyc
+
+import (
+	"errors"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+)
+
+// KYC statuses of a user, every user starts unverified
+const (
+	Unverified = "unverified"
+	Pending    = "pending"
+	Verified   = "verified"
+	Rejected   = "rejected"
+)
+
+// NoteProfileChanged is the note of the review recorded when a profile change resets the status
+const NoteProfileChanged = "profile changed after submission"
+
+var (
+	// ErrInvalidTransition is returned when the status cannot move from its current value to the requested one
+	ErrInvalidTransition = errors.New("invalid KYC status change")
+	// ErrIncompleteProfile is returned when a profile is submitted for review without all its KYC fields
+	ErrIncompleteProfile = errors.New("date of birth, phone, address and nationality are required for KYC")
+)
+
+// reviewTransitions lists the statuses a banker may move each status to.
+// Sending a pending profile back to unverified asks the user to correct and submit it again.
+var reviewTransitions = map[string][]string{
+	Pending:  {Verified, Rejected, Unverified},
+	Verified: {Rejected},
+	Rejected: {Verified},
+}
+
+// IsValidStatus reports whether status is a KYC status
+func IsValidStatus(status string) bool {
+	switch status {
+	case Unverified, Pending, Verified, Rejected:
+		return true
+	}
+	return false
+}
+
+// CanReview reports whether a banker may move a status from one value to another
+func CanReview(from, to string) bool {
+	for _, status := range reviewTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsProfileComplete reports whether every field needed for a KYC review is filled
+func IsProfileComplete(user db.GetUserByUserNameRow) bool {
+	return user.DateOfBirth.Valid && user.Phone != "" && user.Address != "" && user.Nationality != ""
+}
+
+// NewSubmission returns the status change of a user submitting their profile for review.
+// Only unverified and rejected profiles can be submitted and they must be complete,
+// the documents uploaded since the last submission are sent with it.
+func NewSubmission(user db.GetUserByUserNameRow) (db.UpdateKycStatusTxParams, error) {
+	if user.KycStatus != Unverified && user.KycStatus != Rejected {
+		return db.UpdateKycStatusTxParams{}, ErrInvalidTransition
+	}
+
+	if !IsProfileComplete(user) {
+		return db.UpdateKycStatusTxParams{}, ErrIncompleteProfile
+	}
+
+	return db.UpdateKycStatusTxParams{
+		UpdateUserKycStatusParams: db.UpdateUserKycStatusParams{
+			Username:   user.Username,
+			FromStatus: user.KycStatus,
+			ToStatus:   Pending,
+		},
+		Reviewer:        user.Username,
+		AttachDocuments: true,
+	}, nil
+}
+
+// ResetOnProfileChange makes the update move a pending or verified user back to unverified when it
+// changes a field that was reviewed, the profile then has to be submitted again
+func ResetOnProfileChange(user db.GetUserByUserNameRow, arg *db.UpdateUserTxParams) {
+	if user.KycStatus != Pending && user.KycStatus != Verified {
+		return
+	}
+
+	params := arg.UpdateUserParams
+	changed := (params.FullName.Valid && params.FullName.String != user.FullName) ||
+		(params.DateOfBirth.Valid && (!user.DateOfBirth.Valid || !params.DateOfBirth.Time.Equal(user.DateOfBirth.Time))) ||
+		(params.Phone.Valid && params.Phone.String != user.Phone) ||
+		(params.Address.Valid && params.Address.String != user.Address) ||
+		(params.Nationality.Valid && params.Nationality.String != user.Nationality)
+
+	if !changed {
+		return
+	}
+
+	arg.KycStatus.String, arg.KycStatus.Valid = Unverified, true
+	arg.KycReview = &db.CreateKycReviewParams{
+		Username:   user.Username,
+		FromStatus: user.KycStatus,
+		ToStatus:   Unverified,
+		Reviewer:   user.Username,
+		Note:       NoteProfileChanged,
+	}
+}
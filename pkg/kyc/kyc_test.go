@@ -0,0 +1,91 @@
+package kyc
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func completeProfile(status string) db.GetUserByUserNameRow {
+	return db.GetUserByUserNameRow{
+		Username:    util.RandomOwner(),
+		FullName:    "Nguyen Van A",
+		DateOfBirth: pgtype.Date{Time: time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+		Phone:       "+84901234567",
+		Address:     "1 Le Loi, District 1, Ho Chi Minh City",
+		Nationality: "VN",
+		KycStatus:   status,
+	}
+}
+
+func TestCanReview(t *testing.T) {
+	require.True(t, CanReview(Pending, Verified))
+	require.True(t, CanReview(Pending, Rejected))
+	require.True(t, CanReview(Pending, Unverified))
+	require.True(t, CanReview(Verified, Rejected))
+	require.True(t, CanReview(Rejected, Verified))
+
+	require.False(t, CanReview(Unverified, Verified))
+	require.False(t, CanReview(Verified, Pending))
+	require.False(t, CanReview(Pending, Pending))
+	require.False(t, CanReview(Pending, "approved"))
+}
+
+func TestNewSubmission(t *testing.T) {
+	for _, status := range []string{Unverified, Rejected} {
+		user := completeProfile(status)
+
+		arg, err := NewSubmission(user)
+		require.NoError(t, err)
+		require.Equal(t, user.Username, arg.Username)
+		require.Equal(t, user.Username, arg.Reviewer)
+		require.Equal(t, status, arg.FromStatus)
+		require.Equal(t, Pending, arg.ToStatus)
+		require.True(t, arg.AttachDocuments)
+	}
+
+	for _, status := range []string{Pending, Verified} {
+		_, err := NewSubmission(completeProfile(status))
+		require.ErrorIs(t, err, ErrInvalidTransition)
+	}
+
+	user := completeProfile(Unverified)
+	user.Phone = ""
+	_, err := NewSubmission(user)
+	require.ErrorIs(t, err, ErrIncompleteProfile)
+}
+
+func TestResetOnProfileChange(t *testing.T) {
+	user := completeProfile(Verified)
+
+	arg := db.UpdateUserTxParams{UpdateUserParams: db.UpdateUserParams{
+		Username: user.Username,
+		Phone:    pgtype.Text{String: user.Phone, Valid: true},
+		Email:    pgtype.Text{String: util.RandomEmail(), Valid: true},
+	}}
+	ResetOnProfileChange(user, &arg)
+	require.False(t, arg.KycStatus.Valid)
+	require.Nil(t, arg.KycReview)
+
+	arg.Address = pgtype.Text{String: "2 Nguyen Hue, District 1", Valid: true}
+	ResetOnProfileChange(user, &arg)
+	require.Equal(t, pgtype.Text{String: Unverified, Valid: true}, arg.KycStatus)
+	require.NotNil(t, arg.KycReview)
+	require.Equal(t, Verified, arg.KycReview.FromStatus)
+	require.Equal(t, Unverified, arg.KycReview.ToStatus)
+	require.Equal(t, user.Username, arg.KycReview.Reviewer)
+
+	// Nothing was reviewed yet
+	user = completeProfile(Rejected)
+	arg = db.UpdateUserTxParams{UpdateUserParams: db.UpdateUserParams{
+		Username:    user.Username,
+		DateOfBirth: pgtype.Date{Time: time.Date(1991, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true},
+	}}
+	ResetOnProfileChange(user, &arg)
+	require.False(t, arg.KycStatus.Valid)
+	require.Nil(t, arg.KycReview)
+}
@@ -0,0 +1,93 @@
+package kyc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/util"
+)
+
+// LimitWindow is the period the daily transfer limits apply to
+const LimitWindow = 24 * time.Hour
+
+// ErrTransferLimitExceeded is returned when a transfer would take the user over the daily limit of their KYC level
+var ErrTransferLimitExceeded = errors.New("transfer exceeds the daily limit of your KYC level")
+
+// Policy holds the transfer limits of each KYC level
+type Policy struct {
+	// dailyLimits are the amounts, in minor units per currency, a user may transfer to others within LimitWindow
+	dailyLimits map[string]map[string]int64
+}
+
+// NewPolicy creates the policy of the config. KYC_BASIC_DAILY_LIMITS applies to unverified and pending
+// users, KYC_VERIFIED_DAILY_LIMITS to verified ones, both list amounts in major units per currency such
+// as "USD:2000,VND:50000000". A currency missing from a level is not limited, rejected users cannot
+// transfer to others at all.
+func NewPolicy(config *pkg.Config) (*Policy, error) {
+	basic, err := util.ParseMoneyList(config.KycBasicDailyLimits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid basic KYC daily limit %w", err)
+	}
+
+	verified, err := util.ParseMoneyList(config.KycVerifiedDailyLimits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verified KYC daily limit %w", err)
+	}
+
+	return &Policy{
+		dailyLimits: map[string]map[string]int64{
+			Unverified: basic,
+			Pending:    basic,
+			Verified:   verified,
+		},
+	}, nil
+}
+
+// DailyLimit returns the amount in minor units of currency a user of the status may transfer to
+// others within LimitWindow, ok is false when it is not limited
+func (policy *Policy) DailyLimit(status, currency string) (limit int64, ok bool) {
+	if status == Rejected {
+		return 0, true
+	}
+
+	limit, ok = policy.dailyLimits[status][currency]
+	return limit, ok
+}
+
+// CheckTransfer returns ErrTransferLimitExceeded when sending amount minor units of currency to
+// another user would take owner over their daily limit. It is meant to run in the transfer
+// transaction so that concurrent transfers cannot both fit under the limit.
+func (policy *Policy) CheckTransfer(ctx context.Context, q db.Querier, owner, currency string, amount int64) error {
+	user, err := q.GetUserByUserName(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	limit, ok := policy.DailyLimit(user.KycStatus, currency)
+	if !ok {
+		return nil
+	}
+
+	if amount > limit {
+		return ErrTransferLimitExceeded
+	}
+
+	sent, err := q.SumOutgoingTransfersSince(ctx, db.SumOutgoingTransfersSinceParams{
+		Owner:    owner,
+		Currency: currency,
+		Since:    time.Now().Add(-LimitWindow),
+	})
+	if err != nil {
+		return err
+	}
+
+	if sent > limit-amount {
+		return ErrTransferLimitExceeded
+	}
+
+	return nil
+}
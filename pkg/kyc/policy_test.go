@@ -0,0 +1,107 @@
+package kyc
+
+import (
+	"context"
+	"testing"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPolicy(t *testing.T) *Policy {
+	policy, err := NewPolicy(&pkg.Config{
+		KycBasicDailyLimits:    "USD:2000",
+		KycVerifiedDailyLimits: "USD:50000, vnd:1250000000",
+	})
+	require.NoError(t, err)
+	return policy
+}
+
+func TestNewPolicy(t *testing.T) {
+	policy := newTestPolicy(t)
+
+	testCases := []struct {
+		status   string
+		currency string
+		limit    int64
+		limited  bool
+	}{
+		{Unverified, util.USD, 200_000, true},
+		{Pending, util.USD, 200_000, true},
+		{Unverified, util.VND, 0, false},
+		{Verified, util.USD, 5_000_000, true},
+		{Verified, util.VND, 1_250_000_000, true},
+		{Rejected, util.USD, 0, true},
+		{Rejected, util.EUR, 0, true},
+	}
+
+	for _, tc := range testCases {
+		limit, ok := policy.DailyLimit(tc.status, tc.currency)
+		require.Equal(t, tc.limited, ok, tc.status+" "+tc.currency)
+		require.Equal(t, tc.limit, limit, tc.status+" "+tc.currency)
+	}
+
+	_, err := NewPolicy(&pkg.Config{KycBasicDailyLimits: "USD"})
+	require.Error(t, err)
+
+	_, err = NewPolicy(&pkg.Config{KycVerifiedDailyLimits: "XYZ:10"})
+	require.Error(t, err)
+}
+
+func TestCheckTransfer(t *testing.T) {
+	policy := newTestPolicy(t)
+	owner := util.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		status     string
+		currency   string
+		amount     int64
+		sent       int64
+		checksSent bool
+		err        error
+	}{
+		{name: "UnderLimit", status: Unverified, currency: util.USD, amount: 50_000, sent: 150_000, checksSent: true},
+		{name: "OverLimit", status: Unverified, currency: util.USD, amount: 50_001, sent: 150_000, checksSent: true, err: ErrTransferLimitExceeded},
+		{name: "AmountOverLimit", status: Pending, currency: util.USD, amount: 200_001, err: ErrTransferLimitExceeded},
+		{name: "VerifiedLimit", status: Verified, currency: util.USD, amount: 1_000_000, sent: 150_000, checksSent: true},
+		{name: "NotLimited", status: Unverified, currency: util.EUR, amount: 100_000_000},
+		{name: "Rejected", status: Rejected, currency: util.EUR, amount: 1, err: ErrTransferLimitExceeded},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			store.EXPECT().
+				GetUserByUserName(gomock.Any(), gomock.Eq(owner)).
+				Times(1).
+				Return(db.GetUserByUserNameRow{Username: owner, KycStatus: tc.status}, nil)
+
+			times := 0
+			if tc.checksSent {
+				times = 1
+			}
+			store.EXPECT().
+				SumOutgoingTransfersSince(gomock.Any(), gomock.Any()).
+				Times(times).
+				DoAndReturn(func(_ context.Context, arg db.SumOutgoingTransfersSinceParams) (int64, error) {
+					require.Equal(t, owner, arg.Owner)
+					require.Equal(t, tc.currency, arg.Currency)
+					return tc.sent, nil
+				})
+
+			err := policy.CheckTransfer(context.Background(), store, owner, tc.currency, tc.amount)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package loginhistory
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/worker"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+// Reasons a failed login is recorded with
+const (
+	ReasonInvalidPassword = "invalid_password"
+	ReasonInvalidMfaCode  = "invalid_mfa_code"
+)
+
+// RecordFailure records a failed login of username, unknown users get no record. The login is
+// answered whatever happens here, so a record that cannot be stored is only logged.
+func RecordFailure(ctx context.Context, q db.Querier, username, reason, userAgent, clientIP string) {
+	_, err := q.CreateLoginEvent(ctx, db.CreateLoginEventParams{
+		Username:      username,
+		Success:       false,
+		FailureReason: reason,
+		UserAgent:     userAgent,
+		ClientIp:      clientIP,
+	})
+
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		log.Warn().Err(err).Str("username", username).Msg("cannot record failed login")
+	}
+}
+
+// RecordSuccess records the login that created session, q must be the one of the transaction creating it.
+// A login from a user agent or an address the user never logged in from enqueues a security alert,
+// except for the first login of the user since every device is new then.
+func RecordSuccess(ctx context.Context, q db.Querier, session db.Session) error {
+	source, err := q.GetLoginSource(ctx, db.GetLoginSourceParams{
+		Username:  session.Username,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	newDevice := source.HasLoggedIn && (!source.KnownDevice || !source.KnownIp)
+
+	event, err := q.CreateLoginEvent(ctx, db.CreateLoginEventParams{
+		Username:  session.Username,
+		SessionID: pgtype.UUID{Bytes: session.ID, Valid: true},
+		Success:   true,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		NewDevice: newDevice,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !newDevice {
+		return nil
+	}
+
+	taskPayload := &worker.PayloadSendLoginAlertEmail{
+		UserName:     session.Username,
+		LoginEventID: event.ID,
+	}
+
+	opts := worker.OutboxOptions{
+		MaxRetry: 10,
+		Queue:    worker.QueueCritical,
+	}
+
+	return worker.EnqueueTaskSendLoginAlertEmail(ctx, q, taskPayload, opts)
+}
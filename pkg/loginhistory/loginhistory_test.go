@@ -0,0 +1,146 @@
+package loginhistory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/ChokeGuy/simple-bank/worker"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordSuccess(t *testing.T) {
+	session := db.Session{
+		ID:        uuid.New(),
+		Username:  util.RandomOwner(),
+		UserAgent: "Mozilla/5.0",
+		ClientIp:  "203.0.113.7",
+	}
+
+	testCases := []struct {
+		name      string
+		source    db.GetLoginSourceRow
+		wantAlert bool
+	}{
+		{
+			name:   "FirstLogin",
+			source: db.GetLoginSourceRow{},
+		},
+		{
+			name:   "KnownDeviceAndIp",
+			source: db.GetLoginSourceRow{HasLoggedIn: true, KnownDevice: true, KnownIp: true},
+		},
+		{
+			name:      "NewDevice",
+			source:    db.GetLoginSourceRow{HasLoggedIn: true, KnownIp: true},
+			wantAlert: true,
+		},
+		{
+			name:      "NewIp",
+			source:    db.GetLoginSourceRow{HasLoggedIn: true, KnownDevice: true},
+			wantAlert: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			event := db.LoginEvent{ID: util.RandomInt(1, 1000), Username: session.Username}
+
+			store.EXPECT().
+				GetLoginSource(gomock.Any(), gomock.Eq(db.GetLoginSourceParams{
+					Username:  session.Username,
+					UserAgent: session.UserAgent,
+					ClientIp:  session.ClientIp,
+				})).
+				Times(1).
+				Return(tc.source, nil)
+
+			store.EXPECT().
+				CreateLoginEvent(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateLoginEventParams) (db.LoginEvent, error) {
+					require.True(t, arg.Success)
+					require.Equal(t, session.ID[:], arg.SessionID.Bytes[:])
+					require.Equal(t, tc.wantAlert, arg.NewDevice)
+					return event, nil
+				})
+
+			if tc.wantAlert {
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+						require.Equal(t, worker.TaskSendLoginAlertEmail, arg.TaskType)
+
+						var payload worker.PayloadSendLoginAlertEmail
+						require.NoError(t, json.Unmarshal(arg.Payload, &payload))
+						require.Equal(t, event.ID, payload.LoginEventID)
+						return db.OutboxMessage{}, nil
+					})
+			} else {
+				store.EXPECT().
+					CreateOutboxMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			}
+
+			require.NoError(t, RecordSuccess(context.Background(), store, session))
+		})
+	}
+}
+
+func TestRecordSuccessFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	// The error reaches the transaction so the session is not created without its record
+	store.EXPECT().
+		GetLoginSource(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.GetLoginSourceRow{}, sql.ErrConnDone)
+
+	store.EXPECT().
+		CreateLoginEvent(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	err := RecordSuccess(context.Background(), store, db.Session{Username: util.RandomOwner()})
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestRecordFailure(t *testing.T) {
+	username := util.RandomOwner()
+
+	for _, err := range []error{nil, db.ErrRecordNotFound, sql.ErrConnDone} {
+		ctrl := gomock.NewController(t)
+
+		store := mockdb.NewMockStore(ctrl)
+
+		store.EXPECT().
+			CreateLoginEvent(gomock.Any(), gomock.Eq(db.CreateLoginEventParams{
+				Username:      username,
+				FailureReason: ReasonInvalidPassword,
+				UserAgent:     "curl/8.0",
+				ClientIp:      "198.51.100.2",
+			})).
+			Times(1).
+			Return(db.LoginEvent{}, err)
+
+		// Errors are only logged, the login is answered anyway
+		RecordFailure(context.Background(), store, username, ReasonInvalidPassword, "curl/8.0", "198.51.100.2")
+
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,369 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/encryption"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/pkg/totp"
+)
+
+const (
+	// RecoveryCodeCount is the number of recovery codes issued when TOTP is enabled
+	RecoveryCodeCount = 10
+	// MaxChallengeAttempts is the number of codes a login challenge accepts before it is dropped
+	MaxChallengeAttempts = 5
+
+	recoveryCodeSize      = 10
+	recoveryCodeSeparator = "-"
+	challengeTokenSize    = 32
+)
+
+var (
+	ErrAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrNotEnrolled      = errors.New("two-factor authentication enrolment not found")
+	ErrNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrInvalidCode      = errors.New("invalid two-factor authentication code")
+	ErrInvalidChallenge = errors.New("invalid or expired two-factor authentication challenge")
+	ErrTooManyAttempts  = errors.New("too many invalid two-factor authentication codes")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Enrolment is a pending TOTP secret that still has to be confirmed with a first code
+type Enrolment struct {
+	Secret string
+	URI    string
+}
+
+// Challenge is handed out by a password login when the user has TOTP enabled
+type Challenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// Authenticator manages TOTP enrolment and the second step of a login.
+// TOTP secrets are encrypted with cipher before they reach the store.
+type Authenticator struct {
+	store             db.Store
+	cipher            encryption.Cipher
+	phoneCodes        *phone.Verifier
+	issuer            string
+	challengeDuration time.Duration
+	now               func() time.Time
+}
+
+// NewAuthenticator creates a new Authenticator. When phoneCodes is set a login challenge can also be
+// completed with a code texted to the verified phone of the user, nil turns the SMS channel off.
+func NewAuthenticator(store db.Store, cipher encryption.Cipher, phoneCodes *phone.Verifier, issuer string, challengeDuration time.Duration) *Authenticator {
+	return &Authenticator{
+		store:             store,
+		cipher:            cipher,
+		phoneCodes:        phoneCodes,
+		issuer:            issuer,
+		challengeDuration: challengeDuration,
+		now:               time.Now,
+	}
+}
+
+// Enroll creates a new pending secret for the user, replacing a pending one that was never confirmed
+func (a *Authenticator) Enroll(ctx context.Context, username string) (Enrolment, error) {
+	secret, err := totp.GenerateSecret()
+
+	if err != nil {
+		return Enrolment{}, err
+	}
+
+	ciphertext, err := a.cipher.Encrypt([]byte(secret))
+
+	if err != nil {
+		return Enrolment{}, err
+	}
+
+	_, err = a.store.UpsertPendingUserTotp(ctx, db.UpsertPendingUserTotpParams{
+		Username:         username,
+		SecretCiphertext: ciphertext,
+	})
+
+	if err != nil {
+		// The upsert skips confirmed secrets so an enabled second factor can not be replaced silently
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return Enrolment{}, ErrAlreadyEnabled
+		}
+		return Enrolment{}, err
+	}
+
+	return Enrolment{
+		Secret: secret,
+		URI:    totp.URI(a.issuer, username, secret),
+	}, nil
+}
+
+// Confirm enables the pending secret of the user when code is valid and returns new recovery codes
+func (a *Authenticator) Confirm(ctx context.Context, username, code string) ([]string, error) {
+	userTotp, err := a.store.GetUserTotp(ctx, username)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, err
+	}
+
+	if userTotp.ConfirmedAt.Valid {
+		return nil, ErrAlreadyEnabled
+	}
+
+	secret, err := a.cipher.Decrypt(userTotp.SecretCiphertext)
+
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(string(secret), code, a.now())
+
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = a.store.ConfirmTotpTx(ctx, db.ConfirmTotpTxParams{
+		Username:           username,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, ErrAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable turns off TOTP for the user, code may be a TOTP code or an unused recovery code
+func (a *Authenticator) Disable(ctx context.Context, username, code string) error {
+	if err := a.Verify(ctx, username, code); err != nil {
+		return err
+	}
+
+	return a.store.DisableTotpTx(ctx, username)
+}
+
+// Enabled reports whether the user has a confirmed TOTP secret
+func (a *Authenticator) Enabled(ctx context.Context, username string) (bool, error) {
+	userTotp, err := a.store.GetUserTotp(ctx, username)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return userTotp.ConfirmedAt.Valid, nil
+}
+
+// Verify checks a TOTP code or a recovery code of the user. Each TOTP code and each recovery code is accepted once.
+func (a *Authenticator) Verify(ctx context.Context, username, code string) error {
+	userTotp, err := a.store.GetUserTotp(ctx, username)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return ErrNotEnabled
+		}
+		return err
+	}
+
+	if !userTotp.ConfirmedAt.Valid {
+		return ErrNotEnabled
+	}
+
+	secret, err := a.cipher.Decrypt(userTotp.SecretCiphertext)
+
+	if err != nil {
+		return err
+	}
+
+	if step, ok := totp.Validate(string(secret), code, a.now()); ok {
+		used, err := a.store.UseUserTotpStep(ctx, db.UseUserTotpStepParams{
+			Username:     username,
+			LastUsedStep: step,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		// No row is updated when a code of this or a later step was already accepted
+		if used == 0 {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+
+	_, err = a.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		Username: username,
+		CodeHash: HashRecoveryCode(code),
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return ErrInvalidCode
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CreateChallenge starts the second step of a login, deviceLabel is kept for the session created once it succeeds
+func (a *Authenticator) CreateChallenge(ctx context.Context, username, deviceLabel string) (Challenge, error) {
+	challengeToken, err := token.GenerateOpaqueToken(challengeTokenSize)
+
+	if err != nil {
+		return Challenge{}, err
+	}
+
+	expiresAt := a.now().Add(a.challengeDuration)
+
+	_, err = a.store.CreateMfaChallengeTx(ctx, db.CreateMfaChallengeParams{
+		Username:    username,
+		TokenHash:   token.HashToken(challengeToken),
+		DeviceLabel: deviceLabel,
+		ExpiresAt:   expiresAt,
+	})
+
+	if err != nil {
+		return Challenge{}, err
+	}
+
+	return Challenge{
+		Token:     challengeToken,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// PendingChallenge returns the login challenge of challengeToken while it can still be completed
+func (a *Authenticator) PendingChallenge(ctx context.Context, challengeToken string) (db.MfaChallenge, error) {
+	challenge, err := a.store.GetMfaChallengeByTokenHash(ctx, token.HashToken(challengeToken))
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.MfaChallenge{}, ErrInvalidChallenge
+		}
+		return db.MfaChallenge{}, err
+	}
+
+	if challenge.ConsumedAt.Valid || !a.now().Before(challenge.ExpiresAt) {
+		return db.MfaChallenge{}, ErrInvalidChallenge
+	}
+
+	if challenge.Attempts >= MaxChallengeAttempts {
+		return db.MfaChallenge{}, ErrTooManyAttempts
+	}
+
+	return challenge, nil
+}
+
+// CompleteChallenge checks code against the user of a challenge returned by PendingChallenge and
+// consumes the challenge. The challenge comes with ErrInvalidCode too, so the failure can be told to its user.
+func (a *Authenticator) CompleteChallenge(ctx context.Context, challenge db.MfaChallenge, code string) (db.MfaChallenge, error) {
+	// The attempt is taken before the code is checked, so concurrent requests can not exceed the limit
+	challenge, err := a.store.IncrementMfaChallengeAttempts(ctx, db.IncrementMfaChallengeAttemptsParams{
+		ID:          challenge.ID,
+		MaxAttempts: MaxChallengeAttempts,
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.MfaChallenge{}, ErrTooManyAttempts
+		}
+		return db.MfaChallenge{}, err
+	}
+
+	if err := a.verifyChallengeCode(ctx, challenge.Username, code); err != nil {
+		if errors.Is(err, ErrInvalidCode) {
+			return challenge, err
+		}
+		return db.MfaChallenge{}, err
+	}
+
+	// Consuming fails when a concurrent request completed the same challenge first
+	challenge, err = a.store.ConsumeMfaChallenge(ctx, challenge.ID)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.MfaChallenge{}, ErrInvalidChallenge
+		}
+		return db.MfaChallenge{}, err
+	}
+
+	return challenge, nil
+}
+
+// verifyChallengeCode accepts a TOTP code, a recovery code or a login code texted to the verified phone of the user.
+// SMS codes only complete a login, Verify never accepts them.
+func (a *Authenticator) verifyChallengeCode(ctx context.Context, username, code string) error {
+	err := a.Verify(ctx, username, code)
+
+	if !errors.Is(err, ErrInvalidCode) || a.phoneCodes == nil {
+		return err
+	}
+
+	user, err := a.store.GetUserByUserName(ctx, username)
+
+	if err != nil {
+		return err
+	}
+
+	if !user.IsPhoneVerified {
+		return ErrInvalidCode
+	}
+
+	err = a.phoneCodes.UseLoginCode(ctx, user, code)
+
+	if errors.Is(err, phone.ErrInvalidCode) || errors.Is(err, phone.ErrTooManyAttempts) {
+		return ErrInvalidCode
+	}
+
+	return err
+}
+
+// HashRecoveryCode returns the stored form of a recovery code, dashes, spaces and case are ignored
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer(recoveryCodeSeparator, "", " ", "").Replace(code))
+	return token.HashToken(normalized)
+}
+
+func generateRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeSize)
+
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		encoded := recoveryCodeEncoding.EncodeToString(b)[:recoveryCodeSize]
+		code := strings.ToLower(encoded[:recoveryCodeSize/2] + recoveryCodeSeparator + encoded[recoveryCodeSize/2:])
+
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
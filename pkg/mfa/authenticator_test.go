@@ -0,0 +1,570 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/encryption"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/pkg/totp"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthenticator(t *testing.T, store db.Store) *Authenticator {
+	cipher, err := encryption.NewAESCipher(util.RandomString(encryption.KeySize))
+	require.NoError(t, err)
+
+	return NewAuthenticator(store, cipher, nil, "Simple Bank", time.Minute)
+}
+
+func randomUserTotp(t *testing.T, a *Authenticator, username string, confirmed bool) (db.UserTotp, string) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	ciphertext, err := a.cipher.Encrypt([]byte(secret))
+	require.NoError(t, err)
+
+	return db.UserTotp{
+		Username:         username,
+		SecretCiphertext: ciphertext,
+		ConfirmedAt:      pgtype.Timestamptz{Time: time.Now(), Valid: confirmed},
+		CreatedAt:        time.Now(),
+	}, secret
+}
+
+func TestEnroll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	a := newTestAuthenticator(t, store)
+	username := util.RandomOwner()
+
+	var stored []byte
+
+	store.EXPECT().
+		UpsertPendingUserTotp(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UpsertPendingUserTotpParams) (db.UserTotp, error) {
+			stored = arg.SecretCiphertext
+			return db.UserTotp{Username: arg.Username, SecretCiphertext: arg.SecretCiphertext}, nil
+		})
+
+	enrolment, err := a.Enroll(context.Background(), username)
+	require.NoError(t, err)
+	require.NotEmpty(t, enrolment.Secret)
+	require.Contains(t, enrolment.URI, "otpauth://totp/")
+
+	// Only the encrypted secret reaches the store
+	require.NotContains(t, string(stored), enrolment.Secret)
+
+	secret, err := a.cipher.Decrypt(stored)
+	require.NoError(t, err)
+	require.Equal(t, enrolment.Secret, string(secret))
+}
+
+func TestEnrollAlreadyEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	a := newTestAuthenticator(t, store)
+
+	store.EXPECT().
+		UpsertPendingUserTotp(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.UserTotp{}, db.ErrRecordNotFound)
+
+	_, err := a.Enroll(context.Background(), util.RandomOwner())
+	require.ErrorIs(t, err, ErrAlreadyEnabled)
+}
+
+func TestConfirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	a := newTestAuthenticator(t, store)
+	username := util.RandomOwner()
+	userTotp, secret := randomUserTotp(t, a, username, false)
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+
+	var hashes []string
+
+	store.EXPECT().
+		GetUserTotp(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(userTotp, nil)
+
+	store.EXPECT().
+		ConfirmTotpTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ConfirmTotpTxParams, _ ...db.TxOption) (db.ConfirmTotpTxResult, error) {
+			require.Equal(t, username, arg.Username)
+			require.Equal(t, totp.Step(time.Now()), arg.Step)
+			hashes = arg.RecoveryCodeHashes
+			return db.ConfirmTotpTxResult{}, nil
+		})
+
+	codes, err := a.Confirm(context.Background(), username, code)
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+	require.Len(t, hashes, RecoveryCodeCount)
+
+	for i, code := range codes {
+		require.Equal(t, hashes[i], HashRecoveryCode(code))
+	}
+}
+
+func TestConfirmRejectsCode(t *testing.T) {
+	testCases := []struct {
+		name      string
+		confirmed bool
+		err       error
+		wantErr   error
+	}{
+		{name: "InvalidCode", wantErr: ErrInvalidCode},
+		{name: "AlreadyEnabled", confirmed: true, wantErr: ErrAlreadyEnabled},
+		{name: "NotEnrolled", err: db.ErrRecordNotFound, wantErr: ErrNotEnrolled},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			a := newTestAuthenticator(t, store)
+			username := util.RandomOwner()
+			userTotp, _ := randomUserTotp(t, a, username, tc.confirmed)
+
+			store.EXPECT().
+				GetUserTotp(gomock.Any(), gomock.Eq(username)).
+				Times(1).
+				Return(userTotp, tc.err)
+
+			store.EXPECT().
+				ConfirmTotpTx(gomock.Any(), gomock.Any()).
+				Times(0)
+
+			_, err := a.Confirm(context.Background(), username, "000000")
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	username := util.RandomOwner()
+	recoveryCode := "abcde-fghij"
+
+	testCases := []struct {
+		name       string
+		code       func(secret string) string
+		buildStubs func(store *mockdb.MockStore)
+		wantErr    error
+	}{
+		{
+			name: "TotpCode",
+			code: func(secret string) string {
+				code, _ := totp.GenerateCode(secret, time.Now())
+				return code
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UseUserTotpStep(gomock.Any(), gomock.Eq(db.UseUserTotpStepParams{Username: username, LastUsedStep: totp.Step(time.Now())})).
+					Times(1).
+					Return(int64(1), nil)
+			},
+		},
+		{
+			name: "ReplayedTotpCode",
+			code: func(secret string) string {
+				code, _ := totp.GenerateCode(secret, time.Now())
+				return code
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UseUserTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			wantErr: ErrInvalidCode,
+		},
+		{
+			name: "RecoveryCode",
+			code: func(secret string) string {
+				return "ABCDE FGHIJ"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Eq(db.UseRecoveryCodeParams{Username: username, CodeHash: HashRecoveryCode(recoveryCode)})).
+					Times(1).
+					Return(db.RecoveryCode{}, nil)
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: func(secret string) string {
+				return "not-a-code"
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, db.ErrRecordNotFound)
+			},
+			wantErr: ErrInvalidCode,
+		},
+		{
+			name: "StoreError",
+			code: func(secret string) string {
+				return recoveryCode
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, sql.ErrConnDone)
+			},
+			wantErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			a := newTestAuthenticator(t, store)
+			userTotp, secret := randomUserTotp(t, a, username, true)
+
+			store.EXPECT().
+				GetUserTotp(gomock.Any(), gomock.Eq(username)).
+				Times(1).
+				Return(userTotp, nil)
+
+			tc.buildStubs(store)
+
+			err := a.Verify(context.Background(), username, tc.code(secret))
+
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateChallenge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	a := newTestAuthenticator(t, store)
+	username := util.RandomOwner()
+
+	var stored db.CreateMfaChallengeParams
+
+	store.EXPECT().
+		CreateMfaChallengeTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateMfaChallengeParams, _ ...db.TxOption) (db.CreateMfaChallengeTxResult, error) {
+			stored = arg
+			return db.CreateMfaChallengeTxResult{}, nil
+		})
+
+	challenge, err := a.CreateChallenge(context.Background(), username, "laptop")
+	require.NoError(t, err)
+	require.NotEmpty(t, challenge.Token)
+	require.WithinDuration(t, time.Now().Add(time.Minute), challenge.ExpiresAt, time.Second)
+
+	require.Equal(t, username, stored.Username)
+	require.Equal(t, "laptop", stored.DeviceLabel)
+	require.Equal(t, token.HashToken(challenge.Token), stored.TokenHash)
+}
+
+func TestCompleteChallenge(t *testing.T) {
+	username := util.RandomOwner()
+	challengeToken := util.RandomString(32)
+
+	activeChallenge := db.MfaChallenge{
+		ID:        1,
+		Username:  username,
+		TokenHash: token.HashToken(challengeToken),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	testCases := []struct {
+		name       string
+		challenge  db.MfaChallenge
+		buildStubs func(store *mockdb.MockStore, userTotp db.UserTotp)
+		valid      bool
+		wantErr    error
+	}{
+		{
+			name:      "OK",
+			challenge: activeChallenge,
+			valid:     true,
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Eq(db.IncrementMfaChallengeAttemptsParams{ID: activeChallenge.ID, MaxAttempts: MaxChallengeAttempts})).
+					Times(1).
+					Return(activeChallenge, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(username)).Times(1).Return(userTotp, nil)
+				store.EXPECT().UseUserTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().ConsumeMfaChallenge(gomock.Any(), gomock.Eq(activeChallenge.ID)).Times(1).Return(activeChallenge, nil)
+			},
+		},
+		{
+			name:      "InvalidCode",
+			challenge: activeChallenge,
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(username)).Times(1).Return(userTotp, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RecoveryCode{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Eq(db.IncrementMfaChallengeAttemptsParams{ID: activeChallenge.ID, MaxAttempts: MaxChallengeAttempts})).
+					Times(1).
+					Return(activeChallenge, nil)
+				store.EXPECT().ConsumeMfaChallenge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: ErrInvalidCode,
+		},
+		{
+			name: "TooManyAttempts",
+			challenge: func() db.MfaChallenge {
+				challenge := activeChallenge
+				challenge.Attempts = MaxChallengeAttempts
+				return challenge
+			}(),
+			valid: true,
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: ErrTooManyAttempts,
+		},
+		{
+			name: "Expired",
+			challenge: func() db.MfaChallenge {
+				challenge := activeChallenge
+				challenge.ExpiresAt = time.Now().Add(-time.Second)
+				return challenge
+			}(),
+			valid: true,
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: ErrInvalidChallenge,
+		},
+		{
+			name: "Consumed",
+			challenge: func() db.MfaChallenge {
+				challenge := activeChallenge
+				challenge.ConsumedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+				return challenge
+			}(),
+			valid: true,
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: ErrInvalidChallenge,
+		},
+		{
+			name:      "ConsumedConcurrently",
+			challenge: activeChallenge,
+			valid:     true,
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().
+					IncrementMfaChallengeAttempts(gomock.Any(), gomock.Eq(db.IncrementMfaChallengeAttemptsParams{ID: activeChallenge.ID, MaxAttempts: MaxChallengeAttempts})).
+					Times(1).
+					Return(activeChallenge, nil)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(username)).Times(1).Return(userTotp, nil)
+				store.EXPECT().UseUserTotpStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().ConsumeMfaChallenge(gomock.Any(), gomock.Eq(activeChallenge.ID)).Times(1).Return(db.MfaChallenge{}, db.ErrRecordNotFound)
+			},
+			wantErr: ErrInvalidChallenge,
+		},
+		{
+			// Concurrent requests used up the last attempts after the challenge was read
+			name:      "AttemptsUsedConcurrently",
+			challenge: activeChallenge,
+			valid:     true,
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().IncrementMfaChallengeAttempts(gomock.Any(), gomock.Any()).Times(1).Return(db.MfaChallenge{}, db.ErrRecordNotFound)
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ConsumeMfaChallenge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: ErrTooManyAttempts,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			a := newTestAuthenticator(t, store)
+			userTotp, secret := randomUserTotp(t, a, username, true)
+
+			code := "000000"
+			if tc.valid {
+				code, _ = totp.GenerateCode(secret, time.Now())
+			}
+
+			store.EXPECT().
+				GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(token.HashToken(challengeToken))).
+				Times(1).
+				Return(tc.challenge, nil)
+
+			tc.buildStubs(store, userTotp)
+
+			challenge, err := a.PendingChallenge(context.Background(), challengeToken)
+			if err == nil {
+				challenge, err = a.CompleteChallenge(context.Background(), challenge, code)
+			}
+
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+				require.Equal(t, username, challenge.Username)
+			} else {
+				require.ErrorIs(t, err, tc.wantErr)
+			}
+
+			// A wrong code still names the user of the challenge
+			if tc.wantErr == ErrInvalidCode {
+				require.Equal(t, username, challenge.Username)
+			}
+		})
+	}
+}
+
+func TestCompleteChallengeWithSmsCode(t *testing.T) {
+	username := util.RandomOwner()
+	challengeToken := util.RandomString(32)
+	phoneNumber := "+84912345678"
+	smsCode := "493017"
+	phoneCodeKey := util.RandomString(phone.KeySize)
+
+	challenge := db.MfaChallenge{
+		ID:        1,
+		Username:  username,
+		TokenHash: token.HashToken(challengeToken),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	phoneCode := db.PhoneCode{
+		ID:        7,
+		Username:  username,
+		Phone:     phoneNumber,
+		Purpose:   phone.PurposeLogin,
+		CodeHash:  phone.HashCode([]byte(phoneCodeKey), smsCode),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	attemptArg := db.IncrementPhoneCodeAttemptsParams{ID: phoneCode.ID, MaxAttempts: phone.MaxCodeAttempts}
+
+	testCases := []struct {
+		name       string
+		code       string
+		buildStubs func(store *mockdb.MockStore, userTotp db.UserTotp)
+		wantErr    error
+	}{
+		{
+			name: "OK",
+			code: smsCode,
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(username)).Times(1).Return(userTotp, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RecoveryCode{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: username, Phone: phoneNumber, IsPhoneVerified: true}, nil)
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Any()).Times(1).Return(phoneCode, nil)
+				store.EXPECT().IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).Times(1).Return(phoneCode, nil)
+				store.EXPECT().UsePhoneCode(gomock.Any(), gomock.Eq(phoneCode.ID)).Times(1).Return(phoneCode, nil)
+				store.EXPECT().ConsumeMfaChallenge(gomock.Any(), gomock.Eq(challenge.ID)).Times(1).Return(challenge, nil)
+			},
+		},
+		{
+			name: "WrongSmsCode",
+			code: "111111",
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(username)).Times(1).Return(userTotp, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RecoveryCode{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: username, Phone: phoneNumber, IsPhoneVerified: true}, nil)
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Any()).Times(1).Return(phoneCode, nil)
+				store.EXPECT().IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).Times(1).Return(phoneCode, nil)
+				store.EXPECT().ConsumeMfaChallenge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: ErrInvalidCode,
+		},
+		{
+			name: "PhoneNotVerified",
+			code: smsCode,
+			buildStubs: func(store *mockdb.MockStore, userTotp db.UserTotp) {
+				store.EXPECT().GetUserTotp(gomock.Any(), gomock.Eq(username)).Times(1).Return(userTotp, nil)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.RecoveryCode{}, db.ErrRecordNotFound)
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq(username)).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: username, Phone: phoneNumber}, nil)
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: ErrInvalidCode,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			cipher, err := encryption.NewAESCipher(util.RandomString(encryption.KeySize))
+			require.NoError(t, err)
+
+			phoneCodes, err := phone.NewVerifier(store, phoneCodeKey, time.Minute)
+			require.NoError(t, err)
+
+			a := NewAuthenticator(store, cipher, phoneCodes, "Simple Bank", time.Minute)
+			userTotp, _ := randomUserTotp(t, a, username, true)
+
+			store.EXPECT().
+				GetMfaChallengeByTokenHash(gomock.Any(), gomock.Eq(challenge.TokenHash)).
+				Times(1).
+				Return(challenge, nil)
+			store.EXPECT().IncrementMfaChallengeAttempts(gomock.Any(), gomock.Any()).Times(1).Return(challenge, nil)
+
+			tc.buildStubs(store, userTotp)
+
+			pending, err := a.PendingChallenge(context.Background(), challengeToken)
+			require.NoError(t, err)
+
+			_, err = a.CompleteChallenge(context.Background(), pending, tc.code)
+
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -8,19 +8,24 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ChokeGuy/simple-bank/pkg/apikey"
 	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
+	"github.com/ChokeGuy/simple-bank/pkg/session"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
 const (
 	AuthHeaderKey  = "authorization"
 	AuthTypeBearer = "bearer"
+	AuthTypeApiKey = "apikey"
 	AuthPayloadKey = "auth_payload"
 )
 
-// AddAuthorization adds an authorization header to the request for testing
+// AddAuthorization adds an authorization header to the request for testing, the token is the
+// one of a user who just logged in with their password unless opts say otherwise
 func AddAuthorization(
 	t *testing.T,
 	request *http.Request,
@@ -29,8 +34,10 @@ func AddAuthorization(
 	username string,
 	role string,
 	duration time.Duration,
+	opts ...token.PayloadOption,
 ) {
-	token, payload, err := tokenMaker.CreateToken(username, role, duration)
+	opts = append([]token.PayloadOption{token.WithAuth(time.Now(), token.AuthStrengthPassword)}, opts...)
+	token, payload, err := tokenMaker.CreateToken(username, role, uuid.New(), duration, opts...)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -38,8 +45,9 @@ func AddAuthorization(
 	request.Header.Set(AuthHeaderKey, authHeader)
 }
 
-// AuthMiddleWare is a gin middleware for authentication
-func AuthMiddleWare(tokenMaker token.Maker) gin.HandlerFunc {
+// AuthMiddleWare is a gin middleware for authentication, it accepts the access token of a user or the API key
+// of a service account. It also rejects tokens of revoked or blocked sessions.
+func AuthMiddleWare(tokenMaker token.Maker, sessionChecker session.Checker, apiKeys apikey.Verifier) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader(AuthHeaderKey)
 
@@ -58,22 +66,52 @@ func AuthMiddleWare(tokenMaker token.Maker) gin.HandlerFunc {
 
 		authType := strings.ToLower(fields[0])
 
-		if authType != AuthTypeBearer {
-			err := errors.New("unsupported authorization type")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, err.Error()))
-			return
-		}
+		switch authType {
+		case AuthTypeBearer:
+			accessToken := fields[1]
+
+			payload, err := tokenMaker.VerifyToken(accessToken)
 
-		accessToken := fields[1]
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, err.Error()))
+				return
+			}
 
-		payload, err := tokenMaker.VerifyToken(accessToken)
+			// Refresh tokens live as long as the session, they are only good for getting new tokens
+			if err := payload.CheckType(token.TokenTypeAccess); err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, err.Error()))
+				return
+			}
 
-		if err != nil {
+			if err := sessionChecker.CheckSession(ctx, payload); err != nil {
+				if session.IsInvalidSession(err) {
+					ctx.AbortWithStatusJSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, err.Error()))
+					return
+				}
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+				return
+			}
+
+			ctx.Set(AuthPayloadKey, payload)
+		case AuthTypeApiKey:
+			payload, err := apiKeys.VerifyKey(ctx, fields[1], ctx.ClientIP())
+
+			if err != nil {
+				if apikey.IsInvalidKey(err) {
+					ctx.AbortWithStatusJSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, err.Error()))
+					return
+				}
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+				return
+			}
+
+			ctx.Set(AuthPayloadKey, payload)
+		default:
+			err := errors.New("unsupported authorization type")
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, err.Error()))
 			return
 		}
 
-		ctx.Set(AuthPayloadKey, payload)
 		ctx.Next()
 	}
 }
@@ -1,17 +1,35 @@
 package auth
 
 import (
+	"context"
+	"database/sql"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/ChokeGuy/simple-bank/consts"
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/apikey"
+	"github.com/ChokeGuy/simple-bank/pkg/clientip"
 	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/session"
 	"github.com/ChokeGuy/simple-bank/pkg/token"
 	sv "github.com/ChokeGuy/simple-bank/server/http"
 	"github.com/ChokeGuy/simple-bank/util"
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 func TestAuthMiddleware(t *testing.T) {
@@ -64,6 +82,16 @@ func TestAuthMiddleware(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, response.Code)
 			},
 		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				AddAuthorization(t, request, tokenMaker, AuthTypeBearer, "user", util.DepositorRole, time.Minute,
+					token.WithTokenType(token.TokenTypeRefresh))
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -77,7 +105,7 @@ func TestAuthMiddleware(t *testing.T) {
 			authPath := "/auth"
 			server.Router.GET(
 				authPath,
-				AuthMiddleWare(server.TokenMaker),
+				AuthMiddleWare(server.TokenMaker, server.SessionChecker, server.ApiKeyVerifier),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -93,3 +121,769 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddlewareSession(t *testing.T) {
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ActiveSession",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{ID: sessionID, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now()}, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, response.Code)
+			},
+		},
+		{
+			name: "RevokedSession",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{
+						ID:        sessionID,
+						ExpiresAt: time.Now().Add(time.Hour),
+						RevokedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{ID: sessionID, IsBlocked: true, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
+		{
+			name: "PasswordChangedAfterIssue",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{
+						ID:                sessionID,
+						ExpiresAt:         time.Now().Add(time.Hour),
+						LastSeenAt:        time.Now(),
+						PasswordChangedAt: time.Now().Add(2 * time.Second),
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
+		{
+			name: "LookupError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionByIdRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, response.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, _ := pkg.LoadConfig("../../..")
+
+			server := sv.NewTestServer(t, store, &cfg, nil)
+
+			authPath := "/auth"
+			server.Router.GET(
+				authPath,
+				AuthMiddleWare(server.TokenMaker, session.NewCachedChecker(store, time.Minute), server.ApiKeyVerifier),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			accessToken, _, err := server.TokenMaker.CreateToken("user", util.DepositorRole, sessionID, time.Minute)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(AuthHeaderKey, AuthTypeBearer+" "+accessToken)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	testCases := []struct {
+		name          string
+		enabled       bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, response *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "Verified",
+			enabled: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq("user")).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: "user", IsEmailVerified: true}, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, response.Code)
+			},
+		},
+		{
+			name:    "NotVerified",
+			enabled: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq("user")).
+					Times(1).
+					Return(db.GetUserByUserNameRow{Username: "user"}, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, response.Code)
+			},
+		},
+		{
+			name:    "Disabled",
+			enabled: false,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, response.Code)
+			},
+		},
+		{
+			name:    "UserNotFound",
+			enabled: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq("user")).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
+		{
+			name:    "InternalError",
+			enabled: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUserName(gomock.Any(), gomock.Eq("user")).
+					Times(1).
+					Return(db.GetUserByUserNameRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, response.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, _ := pkg.LoadConfig("../../..")
+
+			server := sv.NewTestServer(t, store, &cfg, nil)
+
+			verifiedPath := "/verified"
+			server.Router.GET(
+				verifiedPath,
+				AuthMiddleWare(server.TokenMaker, server.SessionChecker, server.ApiKeyVerifier),
+				RequireVerifiedEmail(store, tc.enabled),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, verifiedPath, nil)
+			require.NoError(t, err)
+
+			AddAuthorization(t, request, server.TokenMaker, AuthTypeBearer, "user", util.DepositorRole, time.Minute)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	testCases := []struct {
+		name          string
+		role          string
+		checkResponse func(t *testing.T, response *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Granted",
+			role: util.BankerRole,
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, response.Code)
+			},
+		},
+		{
+			name: "Denied",
+			role: util.DepositorRole,
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, response.Code)
+			},
+		},
+		{
+			name: "UnknownRole",
+			role: "unknown",
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, response.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, _ := pkg.LoadConfig("../../..")
+
+			server := sv.NewTestServer(t, nil, &cfg, nil)
+
+			permissionPath := "/permission"
+			server.Router.GET(
+				permissionPath,
+				AuthMiddleWare(server.TokenMaker, server.SessionChecker, server.ApiKeyVerifier),
+				RequirePermission(rbac.AccountReadAny),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, permissionPath, nil)
+			require.NoError(t, err)
+
+			AddAuthorization(t, request, server.TokenMaker, AuthTypeBearer, "user", tc.role, time.Minute)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUnaryAuthInterceptor(t *testing.T) {
+	cfg, _ := pkg.LoadConfig("../../..")
+	server := sv.NewTestServer(t, nil, &cfg, nil)
+
+	const (
+		protectedMethod = "/pb.SimpleBank/Protected"
+		publicMethod    = "/pb.SimpleBank/Public"
+	)
+
+	interceptor := UnaryAuthInterceptor(server.TokenMaker, server.SessionChecker, server.ApiKeyVerifier, map[string]rbac.Permission{
+		protectedMethod: rbac.UserLockoutRead,
+	})
+
+	newContext := func(role string, opts ...token.PayloadOption) context.Context {
+		accessToken, _, err := server.TokenMaker.CreateToken("user", role, uuid.New(), time.Minute, opts...)
+		require.NoError(t, err)
+
+		md := metadata.MD{consts.AuthorizationHeader: []string{consts.AuthorizationType + " " + accessToken}}
+		return metadata.NewIncomingContext(context.Background(), md)
+	}
+
+	testCases := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		code   codes.Code
+	}{
+		{
+			name:   "Granted",
+			ctx:    newContext(util.BankerRole),
+			method: protectedMethod,
+			code:   codes.OK,
+		},
+		{
+			name:   "Denied",
+			ctx:    newContext(util.DepositorRole),
+			method: protectedMethod,
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "RefreshToken",
+			ctx:    newContext(util.BankerRole, token.WithTokenType(token.TokenTypeRefresh)),
+			method: protectedMethod,
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "NoAuthorization",
+			ctx:    context.Background(),
+			method: protectedMethod,
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "PublicMethod",
+			ctx:    context.Background(),
+			method: publicMethod,
+			code:   codes.OK,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tc.method == protectedMethod {
+					// Handlers reuse the payload of the interceptor, the token is not verified again
+					payload, err := AuthorizeGrpc(ctx, nil, nil, nil, rbac.UserLockoutRead)
+					require.NoError(t, err)
+					require.Equal(t, "user", payload.UserName)
+				}
+				return nil, nil
+			}
+
+			_, err := interceptor(tc.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
+
+func TestAuthMiddlewareApiKey(t *testing.T) {
+	key, _, err := apikey.Generate()
+	require.NoError(t, err)
+
+	activeKey := db.GetApiKeyByHashRow{
+		ID:             uuid.New(),
+		ServiceAccount: "batch",
+		Scopes:         []string{string(rbac.AccountReadAny)},
+		AllowedIps:     []string{"10.0.0.0/8"},
+		Role:           util.BankerRole,
+	}
+
+	testCases := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   string
+		trustedProxies string
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(t *testing.T, response *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			remoteAddr: "10.1.2.3:5000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetApiKeyByHash(gomock.Any(), gomock.Eq(token.HashToken(key))).
+					Times(1).
+					Return(activeKey, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, response.Code)
+			},
+		},
+		{
+			name:       "ScopeMissing",
+			remoteAddr: "10.1.2.3:5000",
+			buildStubs: func(store *mockdb.MockStore) {
+				row := activeKey
+				row.Scopes = []string{string(rbac.TransferReadAny)}
+				store.EXPECT().
+					GetApiKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(row, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, response.Code)
+			},
+		},
+		{
+			name:       "IPNotAllowed",
+			remoteAddr: "203.0.113.7:5000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetApiKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(activeKey, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
+		{
+			name:         "ForgedForwardedFor",
+			remoteAddr:   "203.0.113.7:5000",
+			forwardedFor: "10.1.2.3",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetApiKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(activeKey, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
+		{
+			name:           "TrustedProxy",
+			remoteAddr:     "192.168.0.5:5000",
+			forwardedFor:   "10.1.2.3",
+			trustedProxies: "192.168.0.0/16",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetApiKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(activeKey, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, response.Code)
+			},
+		},
+		{
+			name:           "ForgedBehindTrustedProxy",
+			remoteAddr:     "192.168.0.5:5000",
+			forwardedFor:   "10.1.2.3, 203.0.113.7",
+			trustedProxies: "192.168.0.0/16",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetApiKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(activeKey, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
+		{
+			name:       "RevokedKey",
+			remoteAddr: "10.1.2.3:5000",
+			buildStubs: func(store *mockdb.MockStore) {
+				row := activeKey
+				row.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					GetApiKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(row, nil)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
+		{
+			name:       "LookupFailed",
+			remoteAddr: "10.1.2.3:5000",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetApiKeyByHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetApiKeyByHashRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, response.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			cfg, _ := pkg.LoadConfig("../../..")
+			cfg.TrustedProxies = tc.trustedProxies
+			server := sv.NewTestServer(t, store, &cfg, nil)
+
+			authPath := "/auth"
+			server.Router.GET(
+				authPath,
+				AuthMiddleWare(server.TokenMaker, server.SessionChecker, server.ApiKeyVerifier),
+				RequirePermission(rbac.AccountReadAny),
+				func(ctx *gin.Context) {
+					payload := ctx.MustGet(AuthPayloadKey).(*token.Payload)
+					require.Equal(t, apikey.Principal("batch"), payload.UserName)
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			request.RemoteAddr = tc.remoteAddr
+			request.Header.Set(AuthHeaderKey, "ApiKey "+key)
+			if tc.forwardedFor != "" {
+				request.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			}
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthorizeGrpcApiKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key, _, err := apikey.Generate()
+	require.NoError(t, err)
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetApiKeyByHash(gomock.Any(), gomock.Eq(token.HashToken(key))).
+		Times(2).
+		Return(db.GetApiKeyByHashRow{
+			ID:             uuid.New(),
+			ServiceAccount: "batch",
+			Scopes:         []string{string(rbac.UserLockoutRead)},
+			Role:           util.BankerRole,
+		}, nil)
+
+	verifier := apikey.NewStoreVerifier(store)
+
+	md := metadata.MD{consts.AuthorizationHeader: []string{consts.AuthorizationTypeApiKey + " " + key}}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	payload, err := AuthorizeGrpc(ctx, nil, nil, verifier, rbac.UserLockoutRead)
+	require.NoError(t, err)
+	require.Equal(t, apikey.Principal("batch"), payload.UserName)
+
+	// The banker role would allow it but the key is not scoped for it
+	_, err = AuthorizeGrpc(ctx, nil, nil, verifier, rbac.UserLockoutReset)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestGrpcClientIP(t *testing.T) {
+	resolver, err := clientip.NewResolver("192.168.0.0/16")
+	require.NoError(t, err)
+
+	withPeer := func(ctx context.Context, ip string) context.Context {
+		return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 4321}})
+	}
+	withForwardedFor := func(ctx context.Context, forwardedFor string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(xForwardedForHeader, forwardedFor))
+	}
+
+	testCases := []struct {
+		name string
+		ctx  context.Context
+		ip   string
+	}{
+		{
+			name: "Peer",
+			ctx:  withPeer(context.Background(), "203.0.113.7"),
+			ip:   "203.0.113.7",
+		},
+		{
+			name: "ForgedHeaderOfPeer",
+			ctx:  withPeer(withForwardedFor(context.Background(), "10.1.2.3"), "203.0.113.7"),
+			ip:   "203.0.113.7",
+		},
+		{
+			name: "GatewayRequest",
+			ctx:  withForwardedFor(context.Background(), "203.0.113.7"),
+			ip:   "203.0.113.7",
+		},
+		{
+			// The gateway appends its remote address to the header sent by the client
+			name: "ForgedHeaderThroughGateway",
+			ctx:  withForwardedFor(context.Background(), "10.1.2.3, 203.0.113.7"),
+			ip:   "203.0.113.7",
+		},
+		{
+			name: "GatewayBehindTrustedProxy",
+			ctx:  clientip.NewContext(withForwardedFor(context.Background(), "10.1.2.3, 203.0.113.7, 192.168.0.5"), resolver),
+			ip:   "203.0.113.7",
+		},
+		{
+			name: "UntrustedProxy",
+			ctx:  withForwardedFor(context.Background(), "203.0.113.7, 192.168.0.5"),
+			ip:   "192.168.0.5",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.ip, GrpcClientIP(tc.ctx))
+		})
+	}
+}
+
+func TestRequireStepUp(t *testing.T) {
+	testCases := []struct {
+		name          string
+		opts          []token.PayloadOption
+		checkResponse func(t *testing.T, response *httptest.ResponseRecorder)
+	}{
+		{
+			name: "RecentLogin",
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, response.Code)
+			},
+		},
+		{
+			name: "RecentStepUp",
+			opts: []token.PayloadOption{token.WithAuth(time.Now().Add(-time.Minute), token.AuthStrengthMfa)},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, response.Code)
+			},
+		},
+		{
+			name: "StaleAuthentication",
+			opts: []token.PayloadOption{token.WithAuth(time.Now().Add(-time.Hour), token.AuthStrengthPassword)},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+				require.Contains(t, response.Header().Get("WWW-Authenticate"), `error="insufficient_user_authentication"`)
+			},
+		},
+		{
+			name: "NoAuthTime",
+			opts: []token.PayloadOption{func(payload *token.Payload) { payload.AuthTime = nil }},
+			checkResponse: func(t *testing.T, response *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, response.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, _ := pkg.LoadConfig("../../..")
+
+			server := sv.NewTestServer(t, nil, &cfg, nil)
+
+			stepUpPath := "/step-up"
+			server.Router.GET(
+				stepUpPath,
+				AuthMiddleWare(server.TokenMaker, server.SessionChecker, server.ApiKeyVerifier),
+				RequireStepUp(server.StepUpPolicy),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, stepUpPath, nil)
+			require.NoError(t, err)
+
+			AddAuthorization(t, request, server.TokenMaker, AuthTypeBearer, "user", util.DepositorRole, time.Minute, tc.opts...)
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUnaryStepUpInterceptor(t *testing.T) {
+	cfg, _ := pkg.LoadConfig("../../..")
+	server := sv.NewTestServer(t, nil, &cfg, nil)
+
+	const (
+		sensitiveMethod = "/pb.SimpleBank/Sensitive"
+		otherMethod     = "/pb.SimpleBank/Other"
+	)
+
+	permissions := map[string]rbac.Permission{
+		sensitiveMethod: rbac.UserUpdate,
+		otherMethod:     rbac.UserUpdate,
+	}
+
+	interceptors := []grpc.UnaryServerInterceptor{
+		UnaryAuthInterceptor(server.TokenMaker, server.SessionChecker, server.ApiKeyVerifier, permissions),
+		UnaryStepUpInterceptor(server.StepUpPolicy, map[string]bool{sensitiveMethod: true}),
+	}
+
+	newContext := func(authTime time.Time) context.Context {
+		accessToken, _, err := server.TokenMaker.CreateToken("user", util.DepositorRole, uuid.New(), time.Minute,
+			token.WithAuth(authTime, token.AuthStrengthPassword))
+		require.NoError(t, err)
+
+		md := metadata.MD{consts.AuthorizationHeader: []string{consts.AuthorizationType + " " + accessToken}}
+		return metadata.NewIncomingContext(context.Background(), md)
+	}
+
+	testCases := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		code   codes.Code
+	}{
+		{
+			name:   "RecentAuthentication",
+			ctx:    newContext(time.Now()),
+			method: sensitiveMethod,
+			code:   codes.OK,
+		},
+		{
+			name:   "StaleAuthentication",
+			ctx:    newContext(time.Now().Add(-time.Hour)),
+			method: sensitiveMethod,
+			code:   codes.Unauthenticated,
+		},
+		{
+			name:   "OtherMethod",
+			ctx:    newContext(time.Now().Add(-time.Hour)),
+			method: otherMethod,
+			code:   codes.OK,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			}
+
+			// Chained like grpc.ChainUnaryInterceptor does
+			chained := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptors[1](ctx, req, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			}
+
+			_, err := interceptors[0](tc.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, chained)
+			require.Equal(t, tc.code, status.Code(err))
+		})
+	}
+}
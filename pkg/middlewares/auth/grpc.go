@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ChokeGuy/simple-bank/consts"
+	"github.com/ChokeGuy/simple-bank/pkg/apikey"
+	"github.com/ChokeGuy/simple-bank/pkg/clientip"
+	myErr "github.com/ChokeGuy/simple-bank/pkg/errors"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/session"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type payloadContextKey struct{}
+
+const xForwardedForHeader = "x-forwarded-for"
+
+// GrpcClientIP returns the IP of the client without the port of its connection. Requests of the
+// gateway have no peer, the gateway appends the address of its own connection to x-forwarded-for
+// instead. The hops are resolved with the trusted proxies of the context.
+func GrpcClientIP(ctx context.Context) string {
+	var hops []string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		hops = clientip.ForwardedHops(md.Get(xForwardedForHeader))
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		hops = append(hops, p.Addr.String())
+	}
+
+	return clientip.FromContext(ctx).Resolve(hops)
+}
+
+// UnaryClientIPInterceptor makes the trusted proxies available to GrpcClientIP
+func UnaryClientIPInterceptor(resolver *clientip.Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(clientip.NewContext(ctx, resolver), req)
+	}
+}
+
+// AuthenticateGrpc verifies the bearer token of the incoming metadata and the session it belongs to,
+// or the API key of a service account
+func AuthenticateGrpc(
+	ctx context.Context,
+	tokenMaker token.Maker,
+	sessionChecker session.Checker,
+	apiKeys apikey.Verifier,
+) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+
+	if !ok {
+		return nil, errors.New("metadata not provided")
+	}
+
+	values := md.Get(consts.AuthorizationHeader)
+	if len(values) == 0 {
+		return nil, errors.New("missing authorization header")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) != 2 {
+		return nil, errors.New("invalid authorization header format")
+	}
+
+	authType := strings.ToLower(fields[0])
+
+	if authType == consts.AuthorizationTypeApiKey {
+		payload, err := apiKeys.VerifyKey(ctx, fields[1], GrpcClientIP(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("invalid api key: %w", err)
+		}
+		return payload, nil
+	}
+
+	if authType != consts.AuthorizationType {
+		return nil, errors.New("unsupported authorization type")
+	}
+
+	payload, err := tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, errors.New("invalid access token")
+	}
+
+	// Refresh tokens live as long as the session, they are only good for getting new tokens
+	if err := payload.CheckType(token.TokenTypeAccess); err != nil {
+		return nil, fmt.Errorf("invalid access token: %w", err)
+	}
+
+	if err := sessionChecker.CheckSession(ctx, payload); err != nil {
+		return nil, fmt.Errorf("invalid session: %w", err)
+	}
+
+	return payload, nil
+}
+
+// AuthorizeGrpc returns the payload of the caller when its role is granted permission, errors are gRPC statuses.
+// It reuses the payload set by UnaryAuthInterceptor, handlers served by the gateway are authenticated here instead.
+func AuthorizeGrpc(
+	ctx context.Context,
+	tokenMaker token.Maker,
+	sessionChecker session.Checker,
+	apiKeys apikey.Verifier,
+	permission rbac.Permission,
+) (*token.Payload, error) {
+	payload, ok := ctx.Value(payloadContextKey{}).(*token.Payload)
+
+	if !ok {
+		var err error
+		payload, err = AuthenticateGrpc(ctx, tokenMaker, sessionChecker, apiKeys)
+
+		if err != nil {
+			return nil, myErr.UnAuthorizedError(err)
+		}
+	}
+
+	if err := rbac.Authorize(payload, permission); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return payload, nil
+}
+
+// UnaryAuthInterceptor enforces the permission of every method listed in permissions, other methods stay public
+func UnaryAuthInterceptor(
+	tokenMaker token.Maker,
+	sessionChecker session.Checker,
+	apiKeys apikey.Verifier,
+	permissions map[string]rbac.Permission,
+) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		permission, ok := permissions[info.FullMethod]
+
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		payload, err := AuthorizeGrpc(ctx, tokenMaker, sessionChecker, apiKeys, permission)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(context.WithValue(ctx, payloadContextKey{}, payload), req)
+	}
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"net/http"
+
+	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission is a gin middleware that rejects users whose role is not granted permission.
+// It must run after AuthMiddleWare.
+func RequirePermission(permission rbac.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(AuthPayloadKey).(*token.Payload)
+
+		if err := rbac.Authorize(payload, permission); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, res.ErrorResponse(http.StatusForbidden, err.Error()))
+			return
+		}
+
+		ctx.Next()
+	}
+}
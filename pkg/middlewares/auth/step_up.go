@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ChokeGuy/simple-bank/pkg/apikey"
+	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
+	"github.com/ChokeGuy/simple-bank/pkg/rbac"
+	"github.com/ChokeGuy/simple-bank/pkg/session"
+	"github.com/ChokeGuy/simple-bank/pkg/stepup"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AbortStepUpRequired rejects the request with the challenge of RFC 9470 so clients know to call
+// the step-up endpoint and retry with the elevated token
+func AbortStepUpRequired(ctx *gin.Context, policy *stepup.Policy) {
+	ctx.Header("WWW-Authenticate", fmt.Sprintf(
+		`Bearer error="insufficient_user_authentication", error_description="%s", acr_values="%s", max_age=%d`,
+		stepup.ErrStepUpRequired, policy.MinStrength, int(policy.MaxAge.Seconds()),
+	))
+	ctx.AbortWithStatusJSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, stepup.ErrStepUpRequired.Error()))
+}
+
+// RequireStepUp is a gin middleware that rejects tokens of users who did not prove their identity
+// within the max age of policy, or with a weaker proof than its minimum strength. It must run after
+// AuthMiddleWare, API keys never pass it.
+func RequireStepUp(policy *stepup.Policy) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(AuthPayloadKey).(*token.Payload)
+
+		if err := policy.Check(payload); err != nil {
+			AbortStepUpRequired(ctx, policy)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// StepUpGrpc returns an Unauthenticated status unless the caller proved their identity within the max
+// age of policy with at least its minimum strength
+func StepUpGrpc(payload *token.Payload, policy *stepup.Policy) error {
+	if err := policy.Check(payload); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+// AuthorizeStepUpGrpc is AuthorizeGrpc for methods that also need a recent step-up
+func AuthorizeStepUpGrpc(
+	ctx context.Context,
+	tokenMaker token.Maker,
+	sessionChecker session.Checker,
+	apiKeys apikey.Verifier,
+	permission rbac.Permission,
+	policy *stepup.Policy,
+) (*token.Payload, error) {
+	payload, err := AuthorizeGrpc(ctx, tokenMaker, sessionChecker, apiKeys, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := StepUpGrpc(payload, policy); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// UnaryStepUpInterceptor enforces a recent step-up of the minimum strength of policy for every
+// method listed in methods. It must
+// be chained after UnaryAuthInterceptor, which also has to list these methods.
+func UnaryStepUpInterceptor(policy *stepup.Policy, methods map[string]bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !methods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		payload, ok := ctx.Value(payloadContextKey{}).(*token.Payload)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing authentication")
+		}
+
+		if err := StepUpGrpc(payload, policy); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
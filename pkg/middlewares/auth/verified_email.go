@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	res "github.com/ChokeGuy/simple-bank/pkg/http_response"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail is a gin middleware that rejects users whose email is not verified yet.
+// It must run after AuthMiddleWare, it lets every request through when enabled is false.
+func RequireVerifiedEmail(store db.Store, enabled bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !enabled {
+			ctx.Next()
+			return
+		}
+
+		payload := ctx.MustGet(AuthPayloadKey).(*token.Payload)
+
+		user, err := store.GetUserByUserName(ctx, payload.UserName)
+
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, res.ErrorResponse(http.StatusUnauthorized, "user not found"))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, res.ErrorResponse(http.StatusInternalServerError, err.Error()))
+			return
+		}
+
+		if !user.IsEmailVerified {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, res.ErrorResponse(http.StatusForbidden, "email address must be verified first"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,364 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/ChokeGuy/simple-bank/util/password"
+)
+
+const (
+	// CookieName is the cookie binding a login to the browser that began it
+	CookieName = "oidc_login"
+	// CookiePath covers the login and the callback routes
+	CookiePath = "/auth/oidc"
+
+	stateSize    = 32
+	nonceSize    = 32
+	browserSize  = 32
+	passwordSize = 32
+
+	// maxUsernameLength leaves room for the suffix added when a derived username is taken
+	maxUsernameLength    = 20
+	minUsernameLength    = 3
+	maxUsernameAttempts  = 5
+	fallbackUsernameBase = "user"
+)
+
+var (
+	ErrInvalidState  = errors.New("invalid or expired SSO login state")
+	ErrEmailRequired = errors.New("identity provider did not share an email address")
+	ErrEmailInUse    = errors.New("an account with this email already exists, sign in with its password")
+)
+
+// AuthRequest is where the user is sent to sign in at the provider
+type AuthRequest struct {
+	URL   string
+	State string
+	// Browser goes into the login cookie, the callback is refused without it
+	Browser   string
+	ExpiresAt time.Time
+}
+
+// Identity is the user signed in by a completed SSO login
+type Identity struct {
+	User        db.GetUserByUserNameRow
+	DeviceLabel string
+	// Created is set when the user was provisioned by this login
+	Created bool
+}
+
+// Login runs authorization code logins with PKCE against a Provider. Accounts of the provider are
+// linked to users by issuer and subject, users are created on their first login.
+type Login struct {
+	store         db.Store
+	provider      *Provider
+	hasher        password.PasswordHasher
+	roles         RoleMapping
+	stateDuration time.Duration
+	secureCookie  bool
+	now           func() time.Time
+}
+
+// NewLogin creates a new Login for the provider configured by the OIDC settings
+func NewLogin(config *pkg.Config, store db.Store, hasher password.PasswordHasher) (*Login, error) {
+	provider, err := NewProvider(
+		config.OidcIssuerUrl,
+		config.OidcClientID,
+		config.OidcClientSecret,
+		config.OidcRedirectUrl,
+		strings.Fields(config.OidcScopes),
+		config.OidcHTTPTimeout,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := ParseRoleMapping(config.OidcRoleClaim, config.OidcRoleMapping)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Login{
+		store:         store,
+		provider:      provider,
+		hasher:        hasher,
+		roles:         roles,
+		stateDuration: config.OidcStateDuration,
+		secureCookie:  strings.HasPrefix(config.OidcRedirectUrl, "https://"),
+		now:           time.Now,
+	}, nil
+}
+
+// Begin starts a login, the state, nonce and code verifier are kept until the callback. The login
+// is bound to the browser with the cookie of the request, see Cookie.
+func (l *Login) Begin(ctx context.Context, deviceLabel string) (AuthRequest, error) {
+	// Abandoned logins are cleaned up by the next one
+	if _, err := l.store.DeleteExpiredOidcLoginStates(ctx); err != nil {
+		return AuthRequest{}, err
+	}
+
+	state, err := token.GenerateOpaqueToken(stateSize)
+
+	if err != nil {
+		return AuthRequest{}, err
+	}
+
+	nonce, err := token.GenerateOpaqueToken(nonceSize)
+
+	if err != nil {
+		return AuthRequest{}, err
+	}
+
+	browser, err := token.GenerateOpaqueToken(browserSize)
+
+	if err != nil {
+		return AuthRequest{}, err
+	}
+
+	codeVerifier, err := NewCodeVerifier()
+
+	if err != nil {
+		return AuthRequest{}, err
+	}
+
+	authURL, err := l.provider.AuthCodeURL(ctx, state, nonce, CodeChallenge(codeVerifier))
+
+	if err != nil {
+		return AuthRequest{}, err
+	}
+
+	expiresAt := l.now().Add(l.stateDuration)
+
+	_, err = l.store.CreateOidcLoginState(ctx, db.CreateOidcLoginStateParams{
+		StateHash:    token.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		DeviceLabel:  deviceLabel,
+		ExpiresAt:    expiresAt,
+		BrowserHash:  token.HashToken(browser),
+	})
+
+	if err != nil {
+		return AuthRequest{}, err
+	}
+
+	return AuthRequest{
+		URL:       authURL,
+		State:     state,
+		Browser:   browser,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Cookie returns the login cookie of authRequest. Scripts cannot read it and the browser still sends
+// it on the top-level redirect back from the provider, but not on requests started by other sites.
+func (l *Login) Cookie(authRequest AuthRequest) *http.Cookie {
+	return &http.Cookie{
+		Name:     CookieName,
+		Value:    authRequest.Browser,
+		Path:     CookiePath,
+		Expires:  authRequest.ExpiresAt,
+		HttpOnly: true,
+		Secure:   l.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// ExpiredCookie removes the login cookie once the callback used it
+func (l *Login) ExpiredCookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     CookieName,
+		Path:     CookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   l.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Complete redeems the code of the callback and returns the linked user. browser is the value of the
+// login cookie, a callback without the cookie of the browser that began the login is refused so a
+// login of an attacker cannot be completed in the browser of a victim. A user is created when the
+// account of the provider was never seen, afterCreate then runs in its transaction when set.
+// An email already used by a local user is refused, accounts are never linked by email alone.
+func (l *Login) Complete(ctx context.Context, state, browser, code string, afterCreate func(q db.Querier, user db.User) error) (Identity, error) {
+	if browser == "" {
+		return Identity{}, ErrInvalidState
+	}
+
+	// The state is consumed first so a callback can never be replayed
+	loginState, err := l.store.ConsumeOidcLoginState(ctx, db.ConsumeOidcLoginStateParams{
+		StateHash:   token.HashToken(state),
+		BrowserHash: token.HashToken(browser),
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return Identity{}, ErrInvalidState
+		}
+		return Identity{}, err
+	}
+
+	if !l.now().Before(loginState.ExpiresAt) {
+		return Identity{}, ErrInvalidState
+	}
+
+	rawIDToken, err := l.provider.Exchange(ctx, code, loginState.CodeVerifier)
+
+	if err != nil {
+		return Identity{}, err
+	}
+
+	claims, err := l.provider.VerifyIDToken(ctx, rawIDToken, loginState.Nonce)
+
+	if err != nil {
+		return Identity{}, err
+	}
+
+	identity := Identity{DeviceLabel: loginState.DeviceLabel}
+
+	linked, err := l.store.GetUserIdentity(ctx, db.GetUserIdentityParams{
+		Issuer:  l.provider.Issuer(),
+		Subject: claims.Subject,
+	})
+
+	switch {
+	case err == nil:
+		err = l.store.TouchUserIdentity(ctx, db.TouchUserIdentityParams{
+			ID:    linked.ID,
+			Email: claims.Email,
+		})
+
+		if err != nil {
+			return Identity{}, err
+		}
+
+		identity.User, err = l.store.GetUserByUserName(ctx, linked.Username)
+	case errors.Is(err, db.ErrRecordNotFound):
+		identity.User, identity.Created, err = l.provision(ctx, claims, afterCreate)
+	}
+
+	if err != nil {
+		return Identity{}, err
+	}
+
+	return identity, nil
+}
+
+// provision creates the user of a first login, a taken username gets a random suffix
+func (l *Login) provision(ctx context.Context, claims *Claims, afterCreate func(q db.Querier, user db.User) error) (db.GetUserByUserNameRow, bool, error) {
+	if claims.Email == "" {
+		return db.GetUserByUserNameRow{}, false, ErrEmailRequired
+	}
+
+	// SSO users have no password until they reset one, nothing hashes to a random secret
+	secret, err := token.GenerateOpaqueToken(passwordSize)
+
+	if err != nil {
+		return db.GetUserByUserNameRow{}, false, err
+	}
+
+	hashedPassword, err := l.hasher.Hash(secret)
+
+	if err != nil {
+		return db.GetUserByUserNameRow{}, false, err
+	}
+
+	base := usernameFromClaims(claims)
+	fullName := claims.Name
+	if fullName == "" {
+		fullName = base
+	}
+
+	for attempt := 0; attempt < maxUsernameAttempts; attempt++ {
+		username := base
+		if attempt > 0 {
+			username = fmt.Sprintf("%s%d", base, util.RandomInt(1000, 9999))
+		}
+
+		result, err := l.store.CreateOidcUserTx(ctx, db.CreateOidcUserTxParams{
+			CreateUserParams: db.CreateUserParams{
+				Username:       username,
+				HashedPassword: hashedPassword,
+				FullName:       fullName,
+				Email:          claims.Email,
+			},
+			Role:            l.roles.Role(claims.Raw),
+			IsEmailVerified: claims.EmailVerified,
+			Issuer:          l.provider.Issuer(),
+			Subject:         claims.Subject,
+			AfterCreate:     afterCreate,
+		})
+
+		if err == nil {
+			user, err := l.store.GetUserByUserName(ctx, result.User.Username)
+			return user, true, err
+		}
+
+		if db.ErrorCode(err) != db.UniqueViolation {
+			return db.GetUserByUserNameRow{}, false, err
+		}
+
+		switch db.ConstraintName(err) {
+		case db.UsernameConstraint:
+			continue
+		case db.UserEmailConstraint:
+			return db.GetUserByUserNameRow{}, false, ErrEmailInUse
+		case db.UserIdentityConstraint:
+			// A concurrent login of the same account created the user first
+			linked, err := l.store.GetUserIdentity(ctx, db.GetUserIdentityParams{
+				Issuer:  l.provider.Issuer(),
+				Subject: claims.Subject,
+			})
+
+			if err != nil {
+				return db.GetUserByUserNameRow{}, false, err
+			}
+
+			user, err := l.store.GetUserByUserName(ctx, linked.Username)
+			return user, false, err
+		default:
+			return db.GetUserByUserNameRow{}, false, err
+		}
+	}
+
+	return db.GetUserByUserNameRow{}, false, fmt.Errorf("no free username found for %q", base)
+}
+
+// usernameFromClaims derives a username of lowercase letters and digits from the preferred
+// username of the provider or the local part of the email
+func usernameFromClaims(claims *Claims) string {
+	source := claims.PreferredUsername
+	if source == "" {
+		source = claims.Email
+	}
+	// Some providers use the email as preferred username
+	source, _, _ = strings.Cut(source, "@")
+
+	var username strings.Builder
+	for _, r := range strings.ToLower(source) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			username.WriteRune(r)
+		}
+		if username.Len() == maxUsernameLength {
+			break
+		}
+	}
+
+	if username.Len() < minUsernameLength {
+		return fallbackUsernameBase + username.String()
+	}
+
+	return username.String()
+}
@@ -0,0 +1,304 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/oidc/oidctest"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/ChokeGuy/simple-bank/util/password"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestLogin(t *testing.T, store db.Store, idp *oidctest.Server) *Login {
+	hasher, err := password.NewHasher(password.Bcrypt, password.Argon2Params{}, bcrypt.MinCost)
+	require.NoError(t, err)
+
+	login, err := NewLogin(&pkg.Config{
+		OidcIssuerUrl:     idp.Issuer(),
+		OidcClientID:      oidctest.ClientID,
+		OidcClientSecret:  oidctest.ClientSecret,
+		OidcRedirectUrl:   oidctest.RedirectURL,
+		OidcScopes:        "openid email profile",
+		OidcRoleClaim:     "groups",
+		OidcRoleMapping:   "bank-tellers:banker",
+		OidcStateDuration: time.Minute,
+		OidcHTTPTimeout:   time.Second,
+	}, store, hasher)
+	require.NoError(t, err)
+
+	return login
+}
+
+// beginTestLogin starts a login and returns the state row it stored
+func beginTestLogin(t *testing.T, login *Login, store *mockdb.MockStore) (AuthRequest, db.OidcLoginState) {
+	var loginState db.OidcLoginState
+
+	store.EXPECT().DeleteExpiredOidcLoginStates(gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().CreateOidcLoginState(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateOidcLoginStateParams) (db.OidcLoginState, error) {
+			loginState = db.OidcLoginState{
+				ID:           1,
+				StateHash:    arg.StateHash,
+				Nonce:        arg.Nonce,
+				CodeVerifier: arg.CodeVerifier,
+				DeviceLabel:  arg.DeviceLabel,
+				ExpiresAt:    arg.ExpiresAt,
+				BrowserHash:  arg.BrowserHash,
+			}
+			return loginState, nil
+		})
+
+	authRequest, err := login.Begin(context.Background(), "laptop")
+	require.NoError(t, err)
+	require.Equal(t, token.HashToken(authRequest.State), loginState.StateHash)
+	require.Equal(t, token.HashToken(authRequest.Browser), loginState.BrowserHash)
+	require.WithinDuration(t, time.Now().Add(time.Minute), authRequest.ExpiresAt, time.Second)
+
+	return authRequest, loginState
+}
+
+func TestLoginComplete(t *testing.T) {
+	idp := oidctest.NewServer(t)
+
+	subject := util.RandomString(12)
+	user := db.GetUserByUserNameRow{
+		Username: "jdoe",
+		Role:     util.BankerRole,
+		Email:    "jdoe@example.com",
+	}
+
+	testCases := []struct {
+		name       string
+		claims     map[string]any
+		buildStubs func(store *mockdb.MockStore, loginState db.OidcLoginState)
+		check      func(t *testing.T, identity Identity, err error)
+	}{
+		{
+			name:   "LinkedUser",
+			claims: map[string]any{"sub": subject, "email": user.Email},
+			buildStubs: func(store *mockdb.MockStore, loginState db.OidcLoginState) {
+				store.EXPECT().
+					ConsumeOidcLoginState(gomock.Any(), gomock.Eq(db.ConsumeOidcLoginStateParams{StateHash: loginState.StateHash, BrowserHash: loginState.BrowserHash})).
+					Times(1).
+					Return(loginState, nil)
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Eq(db.GetUserIdentityParams{Issuer: idp.Issuer(), Subject: subject})).
+					Times(1).Return(db.UserIdentity{ID: 7, Username: user.Username}, nil)
+				store.EXPECT().TouchUserIdentity(gomock.Any(), gomock.Eq(db.TouchUserIdentityParams{ID: 7, Email: user.Email})).Times(1).Return(nil)
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateOidcUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, identity Identity, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user, identity.User)
+				require.Equal(t, "laptop", identity.DeviceLabel)
+				require.False(t, identity.Created)
+			},
+		},
+		{
+			name: "NewUser",
+			claims: map[string]any{
+				"sub":                subject,
+				"email":              user.Email,
+				"email_verified":     true,
+				"name":               "John Doe",
+				"preferred_username": "J.Doe@corp.example.com",
+				"groups":             []string{"everyone", "bank-tellers"},
+			},
+			buildStubs: func(store *mockdb.MockStore, loginState db.OidcLoginState) {
+				store.EXPECT().ConsumeOidcLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(db.UserIdentity{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateOidcUserTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateOidcUserTxParams, _ ...db.TxOption) (db.CreateOidcUserTxResult, error) {
+						require.Equal(t, "jdoe", arg.Username)
+						require.Equal(t, "John Doe", arg.FullName)
+						require.Equal(t, user.Email, arg.Email)
+						require.NotEmpty(t, arg.HashedPassword)
+						require.Equal(t, util.BankerRole, arg.Role)
+						require.True(t, arg.IsEmailVerified)
+						require.Equal(t, idp.Issuer(), arg.Issuer)
+						require.Equal(t, subject, arg.Subject)
+
+						return db.CreateOidcUserTxResult{User: db.User{Username: arg.Username}}, nil
+					})
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			check: func(t *testing.T, identity Identity, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user, identity.User)
+				require.True(t, identity.Created)
+			},
+		},
+		{
+			name:   "UsernameTaken",
+			claims: map[string]any{"sub": subject, "email": user.Email},
+			buildStubs: func(store *mockdb.MockStore, loginState db.OidcLoginState) {
+				store.EXPECT().ConsumeOidcLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(db.UserIdentity{}, db.ErrRecordNotFound)
+
+				var usernames []string
+				store.EXPECT().CreateOidcUserTx(gomock.Any(), gomock.Any()).Times(2).
+					DoAndReturn(func(_ context.Context, arg db.CreateOidcUserTxParams, _ ...db.TxOption) (db.CreateOidcUserTxResult, error) {
+						// Unmapped users are depositors
+						require.Equal(t, util.DepositorRole, arg.Role)
+
+						usernames = append(usernames, arg.Username)
+						if len(usernames) == 1 {
+							return db.CreateOidcUserTxResult{}, &pgconn.PgError{Code: db.UniqueViolation, ConstraintName: db.UsernameConstraint}
+						}
+
+						require.Regexp(t, `^jdoe\d{4}$`, arg.Username)
+						return db.CreateOidcUserTxResult{User: db.User{Username: arg.Username}}, nil
+					})
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+			},
+			check: func(t *testing.T, identity Identity, err error) {
+				require.NoError(t, err)
+				require.True(t, identity.Created)
+			},
+		},
+		{
+			name:   "EmailInUse",
+			claims: map[string]any{"sub": subject, "email": user.Email},
+			buildStubs: func(store *mockdb.MockStore, loginState db.OidcLoginState) {
+				store.EXPECT().ConsumeOidcLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(db.UserIdentity{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateOidcUserTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.CreateOidcUserTxResult{}, &pgconn.PgError{Code: db.UniqueViolation, ConstraintName: db.UserEmailConstraint})
+				store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, identity Identity, err error) {
+				require.ErrorIs(t, err, ErrEmailInUse)
+			},
+		},
+		{
+			name:   "NoEmail",
+			claims: map[string]any{"sub": subject},
+			buildStubs: func(store *mockdb.MockStore, loginState db.OidcLoginState) {
+				store.EXPECT().ConsumeOidcLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(1).Return(db.UserIdentity{}, db.ErrRecordNotFound)
+				store.EXPECT().CreateOidcUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, identity Identity, err error) {
+				require.ErrorIs(t, err, ErrEmailRequired)
+			},
+		},
+		{
+			name:   "StateConsumed",
+			claims: map[string]any{"sub": subject},
+			buildStubs: func(store *mockdb.MockStore, loginState db.OidcLoginState) {
+				store.EXPECT().ConsumeOidcLoginState(gomock.Any(), gomock.Any()).Times(1).Return(db.OidcLoginState{}, db.ErrRecordNotFound)
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, identity Identity, err error) {
+				require.ErrorIs(t, err, ErrInvalidState)
+			},
+		},
+		{
+			name:   "StateExpired",
+			claims: map[string]any{"sub": subject},
+			buildStubs: func(store *mockdb.MockStore, loginState db.OidcLoginState) {
+				loginState.ExpiresAt = time.Now().Add(-time.Second)
+				store.EXPECT().ConsumeOidcLoginState(gomock.Any(), gomock.Any()).Times(1).Return(loginState, nil)
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, identity Identity, err error) {
+				require.ErrorIs(t, err, ErrInvalidState)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			login := newTestLogin(t, store, idp)
+
+			authRequest, loginState := beginTestLogin(t, login, store)
+
+			code, err := idp.Authorize(authRequest.URL, tc.claims)
+			require.NoError(t, err)
+
+			tc.buildStubs(store, loginState)
+
+			identity, err := login.Complete(context.Background(), authRequest.State, authRequest.Browser, code, nil)
+			tc.check(t, identity, err)
+		})
+	}
+}
+
+func TestLoginCompleteWithoutCookie(t *testing.T) {
+	idp := oidctest.NewServer(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	login := newTestLogin(t, store, idp)
+
+	authRequest, _ := beginTestLogin(t, login, store)
+
+	code, err := idp.Authorize(authRequest.URL, map[string]any{"sub": util.RandomString(12)})
+	require.NoError(t, err)
+
+	// A victim sent to the callback of a login begun by an attacker has no cookie for it
+	store.EXPECT().ConsumeOidcLoginState(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err = login.Complete(context.Background(), authRequest.State, "", code, nil)
+	require.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestLoginCookie(t *testing.T) {
+	idp := oidctest.NewServer(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	login := newTestLogin(t, mockdb.NewMockStore(ctrl), idp)
+	authRequest := AuthRequest{Browser: util.RandomString(43), ExpiresAt: time.Now().Add(time.Minute)}
+
+	cookie := login.Cookie(authRequest)
+	require.Equal(t, CookieName, cookie.Name)
+	require.Equal(t, authRequest.Browser, cookie.Value)
+	require.Equal(t, CookiePath, cookie.Path)
+	require.True(t, cookie.HttpOnly)
+	require.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	// The redirect URL of the test provider is plain http
+	require.False(t, cookie.Secure)
+
+	expired := login.ExpiredCookie()
+	require.Equal(t, CookieName, expired.Name)
+	require.Empty(t, expired.Value)
+	require.Negative(t, expired.MaxAge)
+}
+
+func TestUsernameFromClaims(t *testing.T) {
+	testCases := []struct {
+		claims   Claims
+		username string
+	}{
+		{Claims{PreferredUsername: "jdoe"}, "jdoe"},
+		{Claims{PreferredUsername: "J.Doe@corp.example.com", Email: "other@example.com"}, "jdoe"},
+		{Claims{Email: "Jane_Roe+bank@example.com"}, "janeroebank"},
+		{Claims{PreferredUsername: "a-very-long-preferred-username"}, "averylongpreferredus"},
+		{Claims{PreferredUsername: "李"}, fallbackUsernameBase},
+		{Claims{Email: "x@example.com"}, fallbackUsernameBase + "x"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.username, usernameFromClaims(&tc.claims))
+	}
+}
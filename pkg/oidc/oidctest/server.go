@@ -0,0 +1,239 @@
+// Package oidctest provides a local OpenID Connect provider for tests
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// ClientID and ClientSecret are the credentials of the only client the provider knows
+	ClientID     = "simple-bank"
+	ClientSecret = "simple-bank-secret"
+	// RedirectURL is the callback registered for the client
+	RedirectURL = "http://localhost:3000/auth/oidc/callback"
+
+	idTokenDuration = 5 * time.Minute
+)
+
+type grant struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	claims        jwt.MapClaims
+}
+
+// Server is an OpenID Connect provider serving discovery, the key set and the token endpoint.
+// Signing in at the authorization endpoint is done by Authorize instead of a browser.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	key      *rsa.PrivateKey
+	kid      string
+	keyCount int
+	grants   map[string]grant
+}
+
+// NewServer starts a new Server, it is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	s := &Server{grants: make(map[string]grant)}
+	s.RotateKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("POST /token", s.token)
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Issuer returns the issuer identifier of the provider
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// RotateKey replaces the signing key, tokens of the previous key no longer verify
+func (s *Server) RotateKey(t testing.TB) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate signing key: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.key = key
+	s.keyCount++
+	s.kid = fmt.Sprintf("test-key-%d", s.keyCount)
+}
+
+// Authorize signs a user in the way the authorization endpoint would. It checks the authorization
+// URL made by the client and returns a code redeemable once for an ID token carrying claims.
+func (s *Server) Authorize(authURL string, claims map[string]any) (string, error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	switch {
+	case query.Get("response_type") != "code":
+		return "", errors.New("response_type must be code")
+	case query.Get("client_id") != ClientID:
+		return "", errors.New("unknown client_id")
+	case query.Get("redirect_uri") != RedirectURL:
+		return "", errors.New("unregistered redirect_uri")
+	case query.Get("state") == "" || query.Get("nonce") == "":
+		return "", errors.New("state and nonce are required")
+	case query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "":
+		return "", errors.New("an S256 code_challenge is required")
+	}
+
+	code, err := token.GenerateOpaqueToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.grants[code] = grant{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		claims:        claims,
+	}
+
+	return code, nil
+}
+
+// SignIDToken returns an ID token for the client signed with the current key, claims override the defaults
+func (s *Server) SignIDToken(claims map[string]any) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.signIDToken(claims)
+}
+
+func (s *Server) signIDToken(claims map[string]any) string {
+	now := time.Now()
+	idClaims := jwt.MapClaims{
+		"iss": s.URL,
+		"aud": ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(idTokenDuration).Unix(),
+	}
+	for name, value := range claims {
+		idClaims[name] = value
+	}
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodRS256, idClaims)
+	jwtToken.Header["kid"] = s.kid
+
+	signed, err := jwtToken.SignedString(s.key)
+	if err != nil {
+		panic(err)
+	}
+
+	return signed
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                           s.URL,
+		"authorization_endpoint":           s.URL + "/authorize",
+		"token_endpoint":                   s.URL + "/token",
+		"jwks_uri":                         s.URL + "/jwks",
+		"response_types_supported":         []string{"code"},
+		"code_challenge_methods_supported": []string{"S256"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, token.JWKS{Keys: []token.JWK{{
+		Kty: "RSA",
+		Use: "sig",
+		Kid: s.kid,
+		Alg: token.AlgorithmRS256,
+		N:   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+	}}})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != ClientID || clientSecret != ClientSecret {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	if r.PostFormValue("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code := r.PostFormValue("code")
+	g, ok := s.grants[code]
+	// Codes are redeemed once, a failed attempt burns them too
+	delete(s.grants, code)
+
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	switch {
+	case !ok:
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	case r.PostFormValue("redirect_uri") != g.redirectURI:
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != g.codeChallenge:
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	claims := jwt.MapClaims{"nonce": g.nonce}
+	for name, value := range g.claims {
+		claims[name] = value
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access-" + code,
+		"token_type":   "Bearer",
+		"expires_in":   int(idTokenDuration.Seconds()),
+		"id_token":     s.signIDToken(claims),
+	})
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
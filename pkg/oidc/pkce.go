@@ -0,0 +1,27 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+)
+
+const (
+	// CodeChallengeMethod is the only PKCE method sent to providers, plain challenges are never used
+	CodeChallengeMethod = "S256"
+
+	// codeVerifierSize gives a 43 characters verifier, the shortest RFC 7636 allows
+	codeVerifierSize = 32
+)
+
+// NewCodeVerifier returns a random PKCE code verifier
+func NewCodeVerifier() (string, error) {
+	return token.GenerateOpaqueToken(codeVerifierSize)
+}
+
+// CodeChallenge returns the S256 challenge of a code verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,422 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// DiscoveryPath is where a provider publishes its metadata, relative to the issuer
+	DiscoveryPath = "/.well-known/openid-configuration"
+
+	// maxResponseBody caps what is read from the provider
+	maxResponseBody = 1 << 20
+	// maxErrorBody is how much of a failed provider response ends up in the error
+	maxErrorBody = 256
+	// jwksMinRefresh keeps tokens with unknown key IDs from refetching the key set on every login
+	jwksMinRefresh = time.Minute
+	// clockSkew is the leeway given to the expiry of ID tokens
+	clockSkew = time.Minute
+)
+
+var (
+	ErrProvider       = errors.New("identity provider request failed")
+	ErrInvalidIDToken = errors.New("invalid ID token")
+)
+
+// idTokenAlgorithms are the signing algorithms accepted for ID tokens
+var idTokenAlgorithms = []string{token.AlgorithmRS256, token.AlgorithmEdDSA}
+
+// Claims are the claims of a verified ID token used to link and provision users
+type Claims struct {
+	Issuer            string `json:"iss"`
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Nonce             string `json:"nonce"`
+	AuthorizedParty   string `json:"azp"`
+	// Raw holds every claim of the token, the role claim is read from it
+	Raw map[string]any `json:"-"`
+}
+
+type providerMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JwksURI               string   `json:"jwks_uri"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported"`
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Provider is an OpenID Connect provider used by a confidential client. Its endpoints are discovered
+// from the issuer on first use, so the server starts while the provider is unreachable.
+type Provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	client       *http.Client
+	now          func() time.Time
+
+	mu        sync.Mutex
+	metadata  *providerMetadata
+	keys      map[string]crypto.PublicKey
+	keysFetch time.Time
+}
+
+// NewProvider creates a new Provider, the openid scope is added to scopes when it is missing
+func NewProvider(issuer, clientID, clientSecret, redirectURL string, scopes []string, timeout time.Duration) (*Provider, error) {
+	switch {
+	case issuer == "":
+		return nil, errors.New("OIDC_ISSUER_URL is required by the oidc provider")
+	case clientID == "":
+		return nil, errors.New("OIDC_CLIENT_ID is required by the oidc provider")
+	case redirectURL == "":
+		return nil, errors.New("OIDC_REDIRECT_URL is required by the oidc provider")
+	}
+
+	hasOpenID := false
+	for _, scope := range scopes {
+		hasOpenID = hasOpenID || scope == "openid"
+	}
+	if !hasOpenID {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	return &Provider{
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+		client:       &http.Client{Timeout: timeout},
+		now:          time.Now,
+	}, nil
+}
+
+// Issuer returns the issuer identifier of the provider
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// AuthCodeURL returns the URL of the provider the user signs in at, codeChallenge is the S256 PKCE challenge
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint: %v", ErrProvider, err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.clientID)
+	query.Set("redirect_uri", p.redirectURL)
+	query.Set("scope", strings.Join(p.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", CodeChallengeMethod)
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange redeems an authorization code at the token endpoint and returns the raw ID token
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"code_verifier": {codeVerifier},
+		"client_id":     {p.clientID},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to create token request: %v", ErrProvider, err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		// client_secret_basic, the credentials are form encoded before they are put in the header
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	defer resp.Body.Close()
+
+	var body tokenResponse
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBody)).Decode(&body)
+
+	if resp.StatusCode != http.StatusOK {
+		if body.Error != "" {
+			return "", fmt.Errorf("%w: token endpoint responded with %s: %s", ErrProvider, body.Error, body.ErrorDescription)
+		}
+		return "", fmt.Errorf("%w: token endpoint responded with status %d", ErrProvider, resp.StatusCode)
+	}
+
+	if decodeErr != nil {
+		return "", fmt.Errorf("%w: invalid token response: %v", ErrProvider, decodeErr)
+	}
+
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: token response has no id_token", ErrProvider)
+	}
+
+	return body.IDToken, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	mapClaims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(rawIDToken, mapClaims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods(idTokenAlgorithms),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(p.now),
+	)
+
+	if err != nil {
+		if errors.Is(err, ErrProvider) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	raw, err := json.Marshal(mapClaims)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	claims := &Claims{Raw: mapClaims}
+
+	if err := json.Unmarshal(raw, claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	audience, _ := mapClaims.GetAudience()
+
+	switch {
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	case len(audience) > 1 && claims.AuthorizedParty != p.clientID:
+		return nil, fmt.Errorf("%w: token was not issued to this client", ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
+
+// discover fetches the provider metadata once, a failed fetch is retried by the next call
+func (p *Provider) discover(ctx context.Context) (*providerMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata providerMetadata
+	if err := p.getJSON(ctx, p.issuer+DiscoveryPath, &metadata); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.TrimSuffix(metadata.Issuer, "/") != p.issuer:
+		return nil, fmt.Errorf("%w: discovery document is for issuer %q", ErrProvider, metadata.Issuer)
+	case metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JwksURI == "":
+		return nil, fmt.Errorf("%w: discovery document is missing endpoints", ErrProvider)
+	}
+
+	if len(metadata.CodeChallengeMethods) > 0 {
+		supported := false
+		for _, method := range metadata.CodeChallengeMethods {
+			supported = supported || method == CodeChallengeMethod
+		}
+		if !supported {
+			return nil, fmt.Errorf("%w: provider does not support %s PKCE", ErrProvider, CodeChallengeMethod)
+		}
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// key returns the verification key of kid, the key set is refetched once the provider rotated its keys
+func (p *Provider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	metadata, err := p.discover(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	if !p.keysFetch.IsZero() && p.now().Sub(p.keysFetch) < jwksMinRefresh {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidIDToken, kid)
+	}
+
+	var set token.JWKS
+	if err := p.getJSON(ctx, metadata.JwksURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		// Keys of unsupported types are skipped, the provider may publish them for other clients
+		if key, err := parseJWK(jwk); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	p.keys = keys
+	p.keysFetch = p.now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidIDToken, kid)
+}
+
+// lookupKey finds kid in the cached key set, a token without kid matches a set of a single key
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return fmt.Errorf("%w: failed to create request: %v", ErrProvider, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("%w: %s responded with status %d: %s", ErrProvider, url, resp.StatusCode, body)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBody)).Decode(v); err != nil {
+		return fmt.Errorf("%w: invalid response from %s: %v", ErrProvider, url, err)
+	}
+
+	return nil
+}
+
+// parseJWK decodes the RSA and Ed25519 keys published by token.Keyring.JWKS and most providers
+func parseJWK(jwk token.JWK) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+
+		if err != nil {
+			return nil, err
+		}
+
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
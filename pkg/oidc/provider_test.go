@@ -0,0 +1,220 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/pkg/oidc/oidctest"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T, idp *oidctest.Server) *Provider {
+	provider, err := NewProvider(idp.Issuer(), oidctest.ClientID, oidctest.ClientSecret, oidctest.RedirectURL, []string{"email", "profile"}, time.Second)
+	require.NoError(t, err)
+
+	return provider
+}
+
+func TestProviderAuthCodeURL(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	provider := newTestProvider(t, idp)
+
+	verifier, err := NewCodeVerifier()
+	require.NoError(t, err)
+	require.Len(t, verifier, 43)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", CodeChallenge(verifier))
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, idp.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+
+	query := u.Query()
+	require.Equal(t, "openid email profile", query.Get("scope"))
+	require.Equal(t, "state", query.Get("state"))
+	require.Equal(t, "nonce", query.Get("nonce"))
+	require.Equal(t, CodeChallenge(verifier), query.Get("code_challenge"))
+	require.Equal(t, CodeChallengeMethod, query.Get("code_challenge_method"))
+	require.Equal(t, oidctest.RedirectURL, query.Get("redirect_uri"))
+}
+
+func TestProviderExchange(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	provider := newTestProvider(t, idp)
+
+	authorize := func(t *testing.T, verifier string) string {
+		authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", CodeChallenge(verifier))
+		require.NoError(t, err)
+
+		code, err := idp.Authorize(authURL, map[string]any{"sub": "subject"})
+		require.NoError(t, err)
+
+		return code
+	}
+
+	testCases := []struct {
+		name  string
+		check func(t *testing.T)
+	}{
+		{
+			name: "OK",
+			check: func(t *testing.T) {
+				verifier, err := NewCodeVerifier()
+				require.NoError(t, err)
+
+				rawIDToken, err := provider.Exchange(context.Background(), authorize(t, verifier), verifier)
+				require.NoError(t, err)
+
+				claims, err := provider.VerifyIDToken(context.Background(), rawIDToken, "nonce")
+				require.NoError(t, err)
+				require.Equal(t, "subject", claims.Subject)
+				require.Equal(t, idp.Issuer(), claims.Issuer)
+			},
+		},
+		{
+			name: "WrongCodeVerifier",
+			check: func(t *testing.T) {
+				verifier, err := NewCodeVerifier()
+				require.NoError(t, err)
+
+				other, err := NewCodeVerifier()
+				require.NoError(t, err)
+
+				_, err = provider.Exchange(context.Background(), authorize(t, verifier), other)
+				require.ErrorIs(t, err, ErrProvider)
+			},
+		},
+		{
+			name: "CodeReplayed",
+			check: func(t *testing.T) {
+				verifier, err := NewCodeVerifier()
+				require.NoError(t, err)
+
+				code := authorize(t, verifier)
+
+				_, err = provider.Exchange(context.Background(), code, verifier)
+				require.NoError(t, err)
+
+				_, err = provider.Exchange(context.Background(), code, verifier)
+				require.ErrorIs(t, err, ErrProvider)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.check(t)
+		})
+	}
+}
+
+func TestProviderVerifyIDToken(t *testing.T) {
+	idp := oidctest.NewServer(t)
+
+	testCases := []struct {
+		name   string
+		claims map[string]any
+		nonce  string
+		err    error
+	}{
+		{
+			name:   "OK",
+			claims: map[string]any{"sub": "subject", "nonce": "nonce", "email": "user@example.com", "email_verified": true},
+			nonce:  "nonce",
+		},
+		{
+			name:   "WrongNonce",
+			claims: map[string]any{"sub": "subject", "nonce": "other"},
+			nonce:  "nonce",
+			err:    ErrInvalidIDToken,
+		},
+		{
+			name:   "WrongAudience",
+			claims: map[string]any{"sub": "subject", "nonce": "nonce", "aud": "other-client"},
+			nonce:  "nonce",
+			err:    ErrInvalidIDToken,
+		},
+		{
+			name:   "WrongAuthorizedParty",
+			claims: map[string]any{"sub": "subject", "nonce": "nonce", "aud": []string{oidctest.ClientID, "other-client"}, "azp": "other-client"},
+			nonce:  "nonce",
+			err:    ErrInvalidIDToken,
+		},
+		{
+			name:   "WrongIssuer",
+			claims: map[string]any{"sub": "subject", "nonce": "nonce", "iss": "https://idp.example.com"},
+			nonce:  "nonce",
+			err:    ErrInvalidIDToken,
+		},
+		{
+			name:   "Expired",
+			claims: map[string]any{"sub": "subject", "nonce": "nonce", "exp": time.Now().Add(-time.Hour).Unix()},
+			nonce:  "nonce",
+			err:    ErrInvalidIDToken,
+		},
+		{
+			name:   "NoSubject",
+			claims: map[string]any{"nonce": "nonce"},
+			nonce:  "nonce",
+			err:    ErrInvalidIDToken,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			provider := newTestProvider(t, idp)
+
+			claims, err := provider.VerifyIDToken(context.Background(), idp.SignIDToken(tc.claims), tc.nonce)
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "subject", claims.Subject)
+			require.Equal(t, "user@example.com", claims.Email)
+			require.True(t, claims.EmailVerified)
+		})
+	}
+}
+
+func TestProviderKeyRotation(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	provider := newTestProvider(t, idp)
+
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	_, err := provider.VerifyIDToken(context.Background(), idp.SignIDToken(map[string]any{"sub": "subject"}), "")
+	require.NoError(t, err)
+
+	idp.RotateKey(t)
+	rawIDToken := idp.SignIDToken(map[string]any{"sub": "subject"})
+
+	// The key set was fetched a moment ago, an unknown key does not refetch it yet
+	_, err = provider.VerifyIDToken(context.Background(), rawIDToken, "")
+	require.ErrorIs(t, err, ErrInvalidIDToken)
+
+	now = now.Add(jwksMinRefresh)
+
+	_, err = provider.VerifyIDToken(context.Background(), rawIDToken, "")
+	require.NoError(t, err)
+}
+
+func TestProviderDiscoveryFailure(t *testing.T) {
+	idp := oidctest.NewServer(t)
+
+	provider, err := NewProvider(idp.Issuer()+"/unknown", oidctest.ClientID, oidctest.ClientSecret, oidctest.RedirectURL, nil, time.Second)
+	require.NoError(t, err)
+
+	_, err = provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	require.ErrorIs(t, err, ErrProvider)
+}
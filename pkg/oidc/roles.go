@@ -0,0 +1,86 @@
+package oidc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ChokeGuy/simple-bank/util"
+)
+
+type roleRule struct {
+	value string
+	role  string
+}
+
+// RoleMapping maps values of a claim of the provider, such as groups, to the role of new users
+type RoleMapping struct {
+	claim string
+	rules []roleRule
+}
+
+// ParseRoleMapping parses a mapping such as "bank-tellers:banker,staff:depositor" for claim.
+// Rules are tried in order, so the most privileged ones should be listed first.
+func ParseRoleMapping(claim string, mapping string) (RoleMapping, error) {
+	roles := RoleMapping{claim: claim}
+
+	for _, entry := range strings.Split(mapping, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		// Values such as URIs may contain colons, the role is after the last one
+		i := strings.LastIndex(entry, ":")
+		if i <= 0 {
+			return RoleMapping{}, fmt.Errorf("invalid role mapping %q, expected value:role", entry)
+		}
+
+		value, role := strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+		if !util.IsSupportedRole(role) {
+			return RoleMapping{}, fmt.Errorf("unsupported role %q in role mapping", role)
+		}
+
+		roles.rules = append(roles.rules, roleRule{value: value, role: role})
+	}
+
+	if len(roles.rules) > 0 && claim == "" {
+		return RoleMapping{}, fmt.Errorf("OIDC_ROLE_CLAIM is required by the role mapping")
+	}
+
+	return roles, nil
+}
+
+// Role returns the role of the first rule matching the claims, unmapped users are depositors
+func (m RoleMapping) Role(claims map[string]any) string {
+	values := claimValues(claims[m.claim])
+
+	for _, rule := range m.rules {
+		for _, value := range values {
+			if value == rule.value {
+				return rule.role
+			}
+		}
+	}
+
+	return util.DepositorRole
+}
+
+// claimValues accepts a claim holding a single string or a list of strings
+func claimValues(claim any) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,32 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoleMapping(t *testing.T) {
+	roles, err := ParseRoleMapping("groups", " bank-tellers:banker , urn:corp:staff:depositor,")
+	require.NoError(t, err)
+
+	require.Equal(t, util.BankerRole, roles.Role(map[string]any{"groups": []any{"staff", "bank-tellers"}}))
+	require.Equal(t, util.BankerRole, roles.Role(map[string]any{"groups": "bank-tellers"}))
+	require.Equal(t, util.DepositorRole, roles.Role(map[string]any{"groups": []any{"urn:corp:staff"}}))
+	require.Equal(t, util.DepositorRole, roles.Role(map[string]any{"groups": []any{"unknown", 1}}))
+	require.Equal(t, util.DepositorRole, roles.Role(map[string]any{}))
+
+	empty, err := ParseRoleMapping("", "")
+	require.NoError(t, err)
+	require.Equal(t, util.DepositorRole, empty.Role(map[string]any{"groups": "bank-tellers"}))
+
+	_, err = ParseRoleMapping("groups", "bank-tellers")
+	require.Error(t, err)
+
+	_, err = ParseRoleMapping("groups", "bank-tellers:admin")
+	require.Error(t, err)
+
+	_, err = ParseRoleMapping("", "bank-tellers:banker")
+	require.Error(t, err)
+}
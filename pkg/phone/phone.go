@@ -0,0 +1,237 @@
+package phone
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+)
+
+const (
+	// PurposeVerify codes prove that the user owns the phone number of their profile
+	PurposeVerify = "verify_phone"
+	// PurposeLogin codes are the second factor of a login challenge, sent to a verified number only
+	PurposeLogin = "login"
+
+	// CodeLength is the number of digits of a code
+	CodeLength = 6
+	// MaxCodeAttempts is the number of guesses after which a code is dropped
+	MaxCodeAttempts = 5
+	// KeySize is the length of the key the codes are hashed with
+	KeySize = 32
+)
+
+var (
+	ErrNoPhone            = errors.New("no phone number was added to the profile")
+	ErrAlreadyVerified    = errors.New("phone number is already verified")
+	ErrNotVerified        = errors.New("phone number is not verified")
+	ErrInvalidCode        = errors.New("invalid or expired phone code")
+	ErrTooManyAttempts    = errors.New("too many invalid phone codes, request a new code")
+	ErrPhoneAlreadyInUse  = errors.New("phone number is verified by another user")
+	ErrUnsupportedPurpose = errors.New("unsupported phone code purpose")
+	ErrInvalidKeySize     = fmt.Errorf("phone code key must be exactly %d characters", KeySize)
+)
+
+var codeMax = big.NewInt(1_000_000)
+
+// GenerateCode returns a random code of CodeLength digits
+func GenerateCode() (string, error) {
+	n, err := rand.Int(rand.Reader, codeMax)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", CodeLength, n.Int64()), nil
+}
+
+// HashCode returns the stored form of a code. A code has only a million values, so it is keyed with
+// a server secret, a leaked table alone does not give the codes away.
+func HashCode(key []byte, code string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsValidPurpose reports whether codes may be sent for purpose
+func IsValidPurpose(purpose string) bool {
+	return purpose == PurposeVerify || purpose == PurposeLogin
+}
+
+// CanReceive checks that a code of purpose may be sent to the phone number of the user
+func CanReceive(user db.GetUserByUserNameRow, purpose string) error {
+	switch {
+	case !IsValidPurpose(purpose):
+		return ErrUnsupportedPurpose
+	case user.Phone == "":
+		return ErrNoPhone
+	case purpose == PurposeVerify && user.IsPhoneVerified:
+		return ErrAlreadyVerified
+	case purpose == PurposeLogin && !user.IsPhoneVerified:
+		return ErrNotVerified
+	}
+
+	return nil
+}
+
+// Verifier issues and checks the one-time codes sent by SMS
+type Verifier struct {
+	store        db.Store
+	key          []byte
+	codeDuration time.Duration
+	now          func() time.Time
+}
+
+// NewVerifier creates a new Verifier, codes are hashed with key and valid for codeDuration
+func NewVerifier(store db.Store, key string, codeDuration time.Duration) (*Verifier, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKeySize
+	}
+
+	return &Verifier{
+		store:        store,
+		key:          []byte(key),
+		codeDuration: codeDuration,
+		now:          time.Now,
+	}, nil
+}
+
+// NewCode replaces the pending code of the user and purpose with a new one bound to phoneNumber.
+// Only the hash is stored, the plain code has to be sent to the user.
+func (v *Verifier) NewCode(ctx context.Context, username, phoneNumber, purpose string) (string, error) {
+	if !IsValidPurpose(purpose) {
+		return "", ErrUnsupportedPurpose
+	}
+
+	code, err := GenerateCode()
+
+	if err != nil {
+		return "", err
+	}
+
+	err = v.store.InvalidatePhoneCodes(ctx, db.InvalidatePhoneCodesParams{
+		Username: username,
+		Purpose:  purpose,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	_, err = v.store.CreatePhoneCode(ctx, db.CreatePhoneCodeParams{
+		Username:  username,
+		Phone:     phoneNumber,
+		Purpose:   purpose,
+		CodeHash:  HashCode(v.key, code),
+		ExpiresAt: v.now().Add(v.codeDuration),
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// VerifyPhone marks the phone number of the user as verified when code is the latest code sent to it
+func (v *Verifier) VerifyPhone(ctx context.Context, user db.GetUserByUserNameRow, code string) (db.User, error) {
+	if err := CanReceive(user, PurposeVerify); err != nil {
+		return db.User{}, err
+	}
+
+	phoneCode, err := v.check(ctx, user.Username, user.Phone, PurposeVerify, code)
+
+	if err != nil {
+		return db.User{}, err
+	}
+
+	result, err := v.store.VerifyPhoneTx(ctx, db.VerifyPhoneTxParams{
+		CodeID:   phoneCode.ID,
+		Username: user.Username,
+		Phone:    user.Phone,
+	})
+
+	if err != nil {
+		// The code was used concurrently or the number changed since it was read
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.User{}, ErrInvalidCode
+		}
+		if db.ErrorCode(err) == db.UniqueViolation && db.ConstraintName(err) == db.VerifiedPhoneConstraint {
+			return db.User{}, ErrPhoneAlreadyInUse
+		}
+		return db.User{}, err
+	}
+
+	return result.User, nil
+}
+
+// UseLoginCode consumes the latest login code of the user when code matches it
+func (v *Verifier) UseLoginCode(ctx context.Context, user db.GetUserByUserNameRow, code string) error {
+	if err := CanReceive(user, PurposeLogin); err != nil {
+		return err
+	}
+
+	phoneCode, err := v.check(ctx, user.Username, user.Phone, PurposeLogin, code)
+
+	if err != nil {
+		return err
+	}
+
+	// Consuming fails when a concurrent request used the same code first
+	if _, err := v.store.UsePhoneCode(ctx, phoneCode.ID); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return ErrInvalidCode
+		}
+		return err
+	}
+
+	return nil
+}
+
+// check returns the latest code of the user and purpose when it is still valid for phoneNumber and matches code.
+// Every guess takes one of the MaxCodeAttempts of the code before it is compared, so concurrent guesses
+// cannot get past the limit.
+func (v *Verifier) check(ctx context.Context, username, phoneNumber, purpose, code string) (db.PhoneCode, error) {
+	phoneCode, err := v.store.GetLatestPhoneCode(ctx, db.GetLatestPhoneCodeParams{
+		Username: username,
+		Purpose:  purpose,
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.PhoneCode{}, ErrInvalidCode
+		}
+		return db.PhoneCode{}, err
+	}
+
+	if phoneCode.UsedAt.Valid || !v.now().Before(phoneCode.ExpiresAt) || phoneCode.Phone != phoneNumber {
+		return db.PhoneCode{}, ErrInvalidCode
+	}
+
+	phoneCode, err = v.store.IncrementPhoneCodeAttempts(ctx, db.IncrementPhoneCodeAttemptsParams{
+		ID:          phoneCode.ID,
+		MaxAttempts: MaxCodeAttempts,
+	})
+
+	if err != nil {
+		// No row comes back once the attempts of the code are used up
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return db.PhoneCode{}, ErrTooManyAttempts
+		}
+		return db.PhoneCode{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(HashCode(v.key, code)), []byte(phoneCode.CodeHash)) != 1 {
+		return db.PhoneCode{}, ErrInvalidCode
+	}
+
+	return phoneCode, nil
+}
@@ -0,0 +1,240 @@
+package phone
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "0123456789abcdefghijklmnopqrstuv"
+
+func newTestVerifier(t *testing.T, store db.Store) *Verifier {
+	verifier, err := NewVerifier(store, testKey, time.Minute)
+	require.NoError(t, err)
+	return verifier
+}
+
+func TestNewVerifier(t *testing.T) {
+	_, err := NewVerifier(nil, "short", time.Minute)
+	require.ErrorIs(t, err, ErrInvalidKeySize)
+}
+
+func TestHashCode(t *testing.T) {
+	hash := HashCode([]byte(testKey), "123456")
+	require.Len(t, hash, 64)
+	require.Equal(t, hash, HashCode([]byte(testKey), "123456"))
+
+	// Without the key the hash cannot be found by hashing all million codes
+	require.NotEqual(t, hash, HashCode([]byte("vutsrqponmlkjihgfedcba9876543210"), "123456"))
+}
+
+func TestGenerateCode(t *testing.T) {
+	seen := map[string]bool{}
+
+	for i := 0; i < 20; i++ {
+		code, err := GenerateCode()
+		require.NoError(t, err)
+		require.Regexp(t, `^\d{6}$`, code)
+		seen[code] = true
+	}
+
+	require.Greater(t, len(seen), 1)
+}
+
+func TestCanReceive(t *testing.T) {
+	unverified := db.GetUserByUserNameRow{Username: util.RandomOwner(), Phone: "+84901234567"}
+	verified := unverified
+	verified.IsPhoneVerified = true
+
+	require.NoError(t, CanReceive(unverified, PurposeVerify))
+	require.NoError(t, CanReceive(verified, PurposeLogin))
+	require.ErrorIs(t, CanReceive(db.GetUserByUserNameRow{}, PurposeVerify), ErrNoPhone)
+	require.ErrorIs(t, CanReceive(verified, PurposeVerify), ErrAlreadyVerified)
+	require.ErrorIs(t, CanReceive(unverified, PurposeLogin), ErrNotVerified)
+	require.ErrorIs(t, CanReceive(verified, "marketing"), ErrUnsupportedPurpose)
+}
+
+func TestVerifyPhone(t *testing.T) {
+	user := db.GetUserByUserNameRow{Username: util.RandomOwner(), Phone: "+84901234567"}
+	latest := db.PhoneCode{
+		ID:        7,
+		Username:  user.Username,
+		Phone:     user.Phone,
+		Purpose:   PurposeVerify,
+		CodeHash:  HashCode([]byte(testKey), "123456"),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+	latestArg := db.GetLatestPhoneCodeParams{Username: user.Username, Purpose: PurposeVerify}
+	attemptArg := db.IncrementPhoneCodeAttemptsParams{ID: latest.ID, MaxAttempts: MaxCodeAttempts}
+	attempted := latest
+	attempted.Attempts = 1
+
+	testCases := []struct {
+		name       string
+		code       string
+		buildStubs func(store *mockdb.MockStore)
+		err        error
+	}{
+		{
+			name: "OK",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Eq(latestArg)).Times(1).Return(latest, nil)
+				store.EXPECT().IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).Times(1).Return(attempted, nil)
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Eq(db.VerifyPhoneTxParams{CodeID: latest.ID, Username: user.Username, Phone: user.Phone})).
+					Times(1).
+					Return(db.VerifyPhoneTxResult{User: db.User{Username: user.Username, Phone: user.Phone, IsPhoneVerified: true}}, nil)
+			},
+		},
+		{
+			name: "WrongCode",
+			code: "654321",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Eq(latestArg)).Times(1).Return(latest, nil)
+				store.EXPECT().IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).Times(1).Return(attempted, nil)
+				store.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			err: ErrInvalidCode,
+		},
+		{
+			name: "NoCode",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Any()).Times(1).Return(db.PhoneCode{}, db.ErrRecordNotFound)
+			},
+			err: ErrInvalidCode,
+		},
+		{
+			name: "Expired",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := latest
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Any()).Times(1).Return(expired, nil)
+				store.EXPECT().IncrementPhoneCodeAttempts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			err: ErrInvalidCode,
+		},
+		{
+			name: "Used",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				used := latest
+				used.UsedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Any()).Times(1).Return(used, nil)
+				store.EXPECT().IncrementPhoneCodeAttempts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			err: ErrInvalidCode,
+		},
+		{
+			name: "SentToOldNumber",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				old := latest
+				old.Phone = "+84907654321"
+
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Any()).Times(1).Return(old, nil)
+				store.EXPECT().IncrementPhoneCodeAttempts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			err: ErrInvalidCode,
+		},
+		{
+			name: "TooManyAttempts",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				// The attempts were taken by concurrent guesses since the code was read
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Any()).Times(1).Return(latest, nil)
+				store.EXPECT().
+					IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).
+					Times(1).
+					Return(db.PhoneCode{}, db.ErrRecordNotFound)
+				store.EXPECT().VerifyPhoneTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			err: ErrTooManyAttempts,
+		},
+		{
+			name: "VerifiedByAnotherUser",
+			code: "123456",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLatestPhoneCode(gomock.Any(), gomock.Any()).Times(1).Return(latest, nil)
+				store.EXPECT().IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(attemptArg)).Times(1).Return(attempted, nil)
+				store.EXPECT().
+					VerifyPhoneTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.VerifyPhoneTxResult{}, &pgconn.PgError{Code: db.UniqueViolation, ConstraintName: db.VerifiedPhoneConstraint})
+			},
+			err: ErrPhoneAlreadyInUse,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			user, err := newTestVerifier(t, store).VerifyPhone(context.Background(), user, tc.code)
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, user.IsPhoneVerified)
+		})
+	}
+}
+
+func TestUseLoginCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	verifier := newTestVerifier(t, store)
+	user := db.GetUserByUserNameRow{Username: util.RandomOwner(), Phone: "+84901234567", IsPhoneVerified: true}
+	latest := db.PhoneCode{
+		ID:        3,
+		Username:  user.Username,
+		Phone:     user.Phone,
+		Purpose:   PurposeLogin,
+		CodeHash:  HashCode([]byte(testKey), "000042"),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	store.EXPECT().
+		GetLatestPhoneCode(gomock.Any(), gomock.Eq(db.GetLatestPhoneCodeParams{Username: user.Username, Purpose: PurposeLogin})).
+		Times(2).
+		Return(latest, nil)
+	store.EXPECT().
+		IncrementPhoneCodeAttempts(gomock.Any(), gomock.Eq(db.IncrementPhoneCodeAttemptsParams{ID: latest.ID, MaxAttempts: MaxCodeAttempts})).
+		Times(2).
+		Return(latest, nil)
+	store.EXPECT().UsePhoneCode(gomock.Any(), gomock.Eq(latest.ID)).Times(1).Return(latest, nil)
+	require.NoError(t, verifier.UseLoginCode(context.Background(), user, "000042"))
+
+	// The code was used by a concurrent request in the meantime
+	store.EXPECT().UsePhoneCode(gomock.Any(), gomock.Eq(latest.ID)).Times(1).Return(db.PhoneCode{}, db.ErrRecordNotFound)
+	require.ErrorIs(t, verifier.UseLoginCode(context.Background(), user, "000042"), ErrInvalidCode)
+
+	// Login codes are never sent to an unverified number
+	user.IsPhoneVerified = false
+	require.ErrorIs(t, verifier.UseLoginCode(context.Background(), user, "000042"), ErrNotVerified)
+}
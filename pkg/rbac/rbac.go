@@ -0,0 +1,117 @@
+package rbac
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Permission is an action on a kind of resource, the :any suffix extends it to resources of other users
+type Permission string
+
+const (
+	AccountCreate        Permission = "account:create"
+	AccountRead          Permission = "account:read"
+	AccountReadAny       Permission = "account:read:any"
+	AccountDelete        Permission = "account:delete"
+	TransferCreate       Permission = "transfer:create"
+	TransferRead         Permission = "transfer:read"
+	TransferReadAny      Permission = "transfer:read:any"
+	TransferApprove      Permission = "transfer:approve"
+	UserRead             Permission = "user:read"
+	UserReadAny          Permission = "user:read:any"
+	UserUpdate           Permission = "user:update"
+	UserUpdateAny        Permission = "user:update:any"
+	UserRoleUpdate       Permission = "user:role:update"
+	UserLockoutRead      Permission = "user:lockout:read"
+	UserLockoutReset     Permission = "user:lockout:reset"
+	SessionManage        Permission = "session:manage"
+	SessionBlock         Permission = "session:block"
+	KycReview            Permission = "kyc:review"
+	MfaManage            Permission = "mfa:manage"
+	WebhookManage        Permission = "webhook:manage"
+	AuditLogRead         Permission = "audit:read"
+	ServiceAccountManage Permission = "service_account:manage"
+)
+
+// depositorPermissions let a customer manage their own profile and money
+var depositorPermissions = []Permission{
+	AccountCreate,
+	AccountRead,
+	AccountDelete,
+	TransferCreate,
+	TransferRead,
+	UserRead,
+	UserUpdate,
+	SessionManage,
+	MfaManage,
+	WebhookManage,
+}
+
+// rolePermissions is the policy table shared by the HTTP and the gRPC API
+var rolePermissions = map[string][]Permission{
+	util.DepositorRole: depositorPermissions,
+	util.BankerRole: append([]Permission{
+		AccountReadAny,
+		TransferReadAny,
+		TransferApprove,
+		UserReadAny,
+		UserUpdateAny,
+		UserRoleUpdate,
+		UserLockoutRead,
+		UserLockoutReset,
+		SessionBlock,
+		KycReview,
+		AuditLogRead,
+		ServiceAccountManage,
+	}, depositorPermissions...),
+}
+
+// Can reports whether role is granted permission, unknown roles have no permission
+func Can(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allows reports whether payload is granted permission, the scopes of an API key only narrow its role
+func allows(payload *token.Payload, permission Permission) bool {
+	if !Can(payload.Role, permission) {
+		return false
+	}
+
+	return payload.Scopes == nil || slices.Contains(payload.Scopes, string(permission))
+}
+
+// Authorize returns ErrPermissionDenied when payload is not granted permission
+func Authorize(payload *token.Payload, permission Permission) error {
+	if !allows(payload, permission) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// CanAccess reports whether payload may act on a resource of owner.
+// Owners always can, other users need one of anyPermissions, so no permission means owners only.
+func CanAccess(payload *token.Payload, owner string, anyPermissions ...Permission) bool {
+	if payload.UserName == owner {
+		return true
+	}
+
+	for _, permission := range anyPermissions {
+		if allows(payload, permission) {
+			return true
+		}
+	}
+
+	return false
+}
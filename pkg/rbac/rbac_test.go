@@ -0,0 +1,64 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCan(t *testing.T) {
+	require.True(t, Can(util.DepositorRole, AccountRead))
+	require.False(t, Can(util.DepositorRole, AccountReadAny))
+	require.False(t, Can(util.DepositorRole, UserLockoutReset))
+	require.False(t, Can(util.DepositorRole, KycReview))
+
+	// Bankers keep every permission of a depositor
+	for _, permission := range depositorPermissions {
+		require.True(t, Can(util.BankerRole, permission))
+	}
+	require.True(t, Can(util.BankerRole, AccountReadAny))
+	require.True(t, Can(util.BankerRole, TransferApprove))
+	require.True(t, Can(util.BankerRole, KycReview))
+
+	require.False(t, Can("unknown", AccountRead))
+	require.False(t, Can("", AccountRead))
+}
+
+func TestAuthorize(t *testing.T) {
+	depositor := &token.Payload{UserName: "alice", Role: util.DepositorRole}
+	banker := &token.Payload{UserName: "bob", Role: util.BankerRole}
+
+	require.NoError(t, Authorize(depositor, TransferCreate))
+	require.ErrorIs(t, Authorize(depositor, UserLockoutRead), ErrPermissionDenied)
+	require.NoError(t, Authorize(banker, UserLockoutRead))
+
+	// Scopes of an API key narrow its role and never extend it
+	scoped := &token.Payload{UserName: "svc:batch", Role: util.BankerRole, Scopes: []string{string(AccountReadAny), string(UserRoleUpdate)}}
+	require.NoError(t, Authorize(scoped, AccountReadAny))
+	require.ErrorIs(t, Authorize(scoped, AuditLogRead), ErrPermissionDenied)
+
+	overScoped := &token.Payload{UserName: "svc:batch", Role: util.DepositorRole, Scopes: []string{string(UserRoleUpdate)}}
+	require.ErrorIs(t, Authorize(overScoped, UserRoleUpdate), ErrPermissionDenied)
+
+	unscoped := &token.Payload{UserName: "svc:batch", Role: util.BankerRole, Scopes: []string{}}
+	require.ErrorIs(t, Authorize(unscoped, AccountRead), ErrPermissionDenied)
+}
+
+func TestCanAccess(t *testing.T) {
+	depositor := &token.Payload{UserName: "alice", Role: util.DepositorRole}
+	banker := &token.Payload{UserName: "bob", Role: util.BankerRole}
+
+	require.True(t, CanAccess(depositor, "alice"))
+	require.True(t, CanAccess(depositor, "alice", AccountReadAny))
+	require.False(t, CanAccess(depositor, "carol", AccountReadAny))
+
+	require.True(t, CanAccess(banker, "carol", AccountReadAny))
+	// Without an any permission only the owner has access
+	require.False(t, CanAccess(banker, "carol"))
+
+	scoped := &token.Payload{UserName: "svc:batch", Role: util.BankerRole, Scopes: []string{string(TransferReadAny)}}
+	require.True(t, CanAccess(scoped, "carol", TransferReadAny))
+	require.False(t, CanAccess(scoped, "carol", AccountReadAny))
+}
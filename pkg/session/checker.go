@@ -0,0 +1,202 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxCachedSessions bounds the cache, once it is reached an entry is evicted for each new one
+	maxCachedSessions = 10000
+	// lastSeenInterval is how stale last_seen_at of a session may get before a check writes it again
+	lastSeenInterval = 5 * time.Minute
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session is revoked")
+	ErrSessionBlocked  = errors.New("session is blocked")
+	ErrSessionExpired  = errors.New("session expired")
+	ErrPasswordChanged = errors.New("token was issued before the last password change")
+)
+
+// IsInvalidSession reports whether err means the session can no longer be used, as opposed to a lookup failure
+func IsInvalidSession(err error) bool {
+	return errors.Is(err, ErrSessionNotFound) ||
+		errors.Is(err, ErrSessionRevoked) ||
+		errors.Is(err, ErrSessionBlocked) ||
+		errors.Is(err, ErrSessionExpired) ||
+		errors.Is(err, ErrPasswordChanged)
+}
+
+// Checker decides whether the login session behind an access token is still usable
+type Checker interface {
+	// CheckSession returns an error when the session of the token is unknown, revoked, blocked or expired,
+	// or when the token was issued before the password of the user was changed
+	CheckSession(ctx context.Context, payload *token.Payload) error
+	// Invalidate drops the cached state of a session so the next check reads it again
+	Invalidate(sessionIDs ...uuid.UUID)
+}
+
+type cacheEntry struct {
+	err               error
+	passwordChangedAt time.Time
+	expiresAt         time.Time
+}
+
+// CachedChecker checks sessions against the store and remembers the outcome for a short time.
+// A revocation made on another instance is therefore seen after at most ttl. The lookups also keep
+// last_seen_at of active sessions up to date within lastSeenInterval.
+type CachedChecker struct {
+	store      db.Store
+	ttl        time.Duration
+	now        func() time.Time
+	maxEntries int
+	mu         sync.RWMutex
+	entries    map[uuid.UUID]cacheEntry
+	// nextSweep is when a full cache may be scanned for expired entries again
+	nextSweep time.Time
+}
+
+// NewCachedChecker creates a checker that caches each session lookup for ttl
+func NewCachedChecker(store db.Store, ttl time.Duration) *CachedChecker {
+	return &CachedChecker{
+		store:      store,
+		ttl:        ttl,
+		now:        time.Now,
+		maxEntries: maxCachedSessions,
+		entries:    make(map[uuid.UUID]cacheEntry),
+	}
+}
+
+func (c *CachedChecker) CheckSession(ctx context.Context, payload *token.Payload) error {
+	sessionID := payload.SessionID
+
+	if sessionID == uuid.Nil {
+		return ErrSessionNotFound
+	}
+
+	now := c.now()
+
+	c.mu.RLock()
+	entry, ok := c.entries[sessionID]
+	c.mu.RUnlock()
+
+	if !ok || !now.Before(entry.expiresAt) {
+		session, err := c.store.GetSessionById(ctx, sessionID)
+
+		if err != nil {
+			if errors.Is(err, db.ErrRecordNotFound) {
+				c.remember(sessionID, cacheEntry{err: ErrSessionNotFound, expiresAt: now.Add(c.ttl)})
+				return ErrSessionNotFound
+			}
+			// Lookup failures are not cached so a database hiccup does not lock users out for ttl
+			return err
+		}
+
+		entry = cacheEntry{
+			err:               sessionError(session, now),
+			passwordChangedAt: session.PasswordChangedAt,
+			expiresAt:         now.Add(c.ttl),
+		}
+
+		// An active session must be looked up again once it expires
+		if entry.err == nil && session.ExpiresAt.Before(entry.expiresAt) {
+			entry.expiresAt = session.ExpiresAt
+		}
+
+		// Only lookups write, so a busy session is touched at most once per ttl and instance
+		if entry.err == nil && now.Sub(session.LastSeenAt) >= lastSeenInterval {
+			if err := c.store.TouchSession(ctx, sessionID); err != nil {
+				log.Warn().Err(err).Str("session_id", sessionID.String()).Msg("cannot update last seen of session")
+			}
+		}
+
+		c.remember(sessionID, entry)
+	}
+
+	if entry.err != nil {
+		return entry.err
+	}
+
+	if payload.IssuedBefore(entry.passwordChangedAt) {
+		return ErrPasswordChanged
+	}
+
+	return nil
+}
+
+func (c *CachedChecker) Invalidate(sessionIDs ...uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, sessionID := range sessionIDs {
+		delete(c.entries, sessionID)
+	}
+}
+
+func (c *CachedChecker) remember(sessionID uuid.UUID, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[sessionID]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict()
+	}
+
+	c.entries[sessionID] = entry
+}
+
+// evict makes room for one entry, the caller holds the lock. Expired entries are swept at most
+// once per ttl, without any left an arbitrary entry goes and is only looked up again.
+func (c *CachedChecker) evict() {
+	now := c.now()
+
+	if !now.Before(c.nextSweep) {
+		c.nextSweep = now.Add(c.ttl)
+
+		for id, cached := range c.entries {
+			if !now.Before(cached.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+
+		if len(c.entries) < c.maxEntries {
+			return
+		}
+	}
+
+	// Iterating a map starts at a random entry
+	for id := range c.entries {
+		delete(c.entries, id)
+		return
+	}
+}
+
+func sessionError(session db.GetSessionByIdRow, now time.Time) error {
+	switch {
+	case session.RevokedAt.Valid:
+		return ErrSessionRevoked
+	case session.IsBlocked:
+		return ErrSessionBlocked
+	case !now.Before(session.ExpiresAt):
+		return ErrSessionExpired
+	}
+
+	return nil
+}
+
+// AllowAllChecker accepts every session, it is used by test servers that do not exercise revocation
+type AllowAllChecker struct{}
+
+func (AllowAllChecker) CheckSession(ctx context.Context, payload *token.Payload) error {
+	return nil
+}
+
+func (AllowAllChecker) Invalidate(sessionIDs ...uuid.UUID) {}
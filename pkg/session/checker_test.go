@@ -0,0 +1,277 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func newPayload(t *testing.T, sessionID uuid.UUID) *token.Payload {
+	payload, err := token.NewPayload("user", "depositor", sessionID, time.Minute)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestCachedCheckerCachesResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	sessionID := uuid.New()
+
+	store.EXPECT().
+		GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+		Times(1).
+		Return(db.GetSessionByIdRow{ID: sessionID, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now()}, nil)
+
+	checker := NewCachedChecker(store, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, checker.CheckSession(context.Background(), newPayload(t, sessionID)))
+	}
+}
+
+func TestCachedCheckerRejectsSession(t *testing.T) {
+	testCases := []struct {
+		name    string
+		session db.GetSessionByIdRow
+		err     error
+		wantErr error
+	}{
+		{
+			name:    "Revoked",
+			session: db.GetSessionByIdRow{ExpiresAt: time.Now().Add(time.Hour), RevokedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}},
+			wantErr: ErrSessionRevoked,
+		},
+		{
+			name:    "Blocked",
+			session: db.GetSessionByIdRow{ExpiresAt: time.Now().Add(time.Hour), IsBlocked: true},
+			wantErr: ErrSessionBlocked,
+		},
+		{
+			name:    "Expired",
+			session: db.GetSessionByIdRow{ExpiresAt: time.Now().Add(-time.Minute)},
+			wantErr: ErrSessionExpired,
+		},
+		{
+			name:    "NotFound",
+			err:     db.ErrRecordNotFound,
+			wantErr: ErrSessionNotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			sessionID := uuid.New()
+
+			store.EXPECT().
+				GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+				Times(1).
+				Return(tc.session, tc.err)
+
+			checker := NewCachedChecker(store, time.Minute)
+
+			err := checker.CheckSession(context.Background(), newPayload(t, sessionID))
+			require.ErrorIs(t, err, tc.wantErr)
+			require.True(t, IsInvalidSession(err))
+		})
+	}
+}
+
+func TestCachedCheckerDoesNotCacheLookupErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	sessionID := uuid.New()
+
+	gomock.InOrder(
+		store.EXPECT().
+			GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+			Return(db.GetSessionByIdRow{}, sql.ErrConnDone),
+		store.EXPECT().
+			GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+			Return(db.GetSessionByIdRow{ID: sessionID, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now()}, nil),
+	)
+
+	checker := NewCachedChecker(store, time.Minute)
+
+	err := checker.CheckSession(context.Background(), newPayload(t, sessionID))
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.False(t, IsInvalidSession(err))
+
+	require.NoError(t, checker.CheckSession(context.Background(), newPayload(t, sessionID)))
+}
+
+func TestCachedCheckerInvalidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	sessionID := uuid.New()
+	active := db.GetSessionByIdRow{ID: sessionID, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now()}
+	revoked := active
+	revoked.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	gomock.InOrder(
+		store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(sessionID)).Return(active, nil),
+		store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(sessionID)).Return(revoked, nil),
+	)
+
+	checker := NewCachedChecker(store, time.Minute)
+	require.NoError(t, checker.CheckSession(context.Background(), newPayload(t, sessionID)))
+
+	checker.Invalidate(sessionID)
+	require.ErrorIs(t, checker.CheckSession(context.Background(), newPayload(t, sessionID)), ErrSessionRevoked)
+}
+
+func TestCachedCheckerExpiresEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	sessionID := uuid.New()
+	now := time.Now()
+
+	store.EXPECT().
+		GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+		Times(2).
+		Return(db.GetSessionByIdRow{ID: sessionID, ExpiresAt: now.Add(time.Hour), LastSeenAt: time.Now()}, nil)
+
+	checker := NewCachedChecker(store, time.Minute)
+	checker.now = func() time.Time { return now }
+	require.NoError(t, checker.CheckSession(context.Background(), newPayload(t, sessionID)))
+
+	checker.now = func() time.Time { return now.Add(2 * time.Minute) }
+	require.NoError(t, checker.CheckSession(context.Background(), newPayload(t, sessionID)))
+}
+
+func TestCachedCheckerRejectsUnboundToken(t *testing.T) {
+	checker := NewCachedChecker(nil, time.Minute)
+	require.ErrorIs(t, checker.CheckSession(context.Background(), newPayload(t, uuid.Nil)), ErrSessionNotFound)
+}
+
+func TestCachedCheckerRejectsTokenIssuedBeforePasswordChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	sessionID := uuid.New()
+
+	// An old token is checked before the password change, a new token after it
+	oldPayload := newPayload(t, sessionID)
+	oldPayload.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	freshPayload := newPayload(t, sessionID)
+
+	store.EXPECT().
+		GetSessionById(gomock.Any(), gomock.Eq(sessionID)).
+		Times(1).
+		Return(db.GetSessionByIdRow{
+			ID:                sessionID,
+			ExpiresAt:         time.Now().Add(time.Hour),
+			LastSeenAt:        time.Now(),
+			PasswordChangedAt: time.Now(),
+		}, nil)
+
+	checker := NewCachedChecker(store, time.Minute)
+
+	err := checker.CheckSession(context.Background(), oldPayload)
+	require.ErrorIs(t, err, ErrPasswordChanged)
+	require.True(t, IsInvalidSession(err))
+
+	// The cached session must not reject a token issued with the new password
+	require.NoError(t, checker.CheckSession(context.Background(), freshPayload))
+}
+
+func TestCachedCheckerEvictsWhenFull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	now := time.Now()
+
+	store.EXPECT().
+		GetSessionById(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, sessionID uuid.UUID) (db.GetSessionByIdRow, error) {
+			return db.GetSessionByIdRow{ID: sessionID, ExpiresAt: now.Add(time.Hour), LastSeenAt: time.Now()}, nil
+		})
+
+	checker := NewCachedChecker(store, time.Minute)
+	checker.now = func() time.Time { return now }
+	checker.maxEntries = 3
+
+	// None of the entries has expired, each new session still finds room
+	for i := 0; i < 10; i++ {
+		require.NoError(t, checker.CheckSession(context.Background(), newPayload(t, uuid.New())))
+		require.LessOrEqual(t, len(checker.entries), checker.maxEntries)
+	}
+
+	// The sweep already ran, the next one waits for ttl
+	require.Equal(t, now.Add(time.Minute), checker.nextSweep)
+
+	// Expired entries are swept before any live entry is evicted
+	checker.now = func() time.Time { return now.Add(2 * time.Minute) }
+	sessionID := uuid.New()
+	require.NoError(t, checker.CheckSession(context.Background(), newPayload(t, sessionID)))
+	require.Len(t, checker.entries, 1)
+	require.Contains(t, checker.entries, sessionID)
+}
+
+func TestCachedCheckerTouchesSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	staleID := uuid.New()
+	recentID := uuid.New()
+	revokedID := uuid.New()
+
+	store.EXPECT().
+		GetSessionById(gomock.Any(), gomock.Eq(staleID)).
+		Times(1).
+		Return(db.GetSessionByIdRow{ID: staleID, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now().Add(-time.Hour)}, nil)
+	store.EXPECT().
+		GetSessionById(gomock.Any(), gomock.Eq(recentID)).
+		Times(1).
+		Return(db.GetSessionByIdRow{ID: recentID, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now()}, nil)
+	store.EXPECT().
+		GetSessionById(gomock.Any(), gomock.Eq(revokedID)).
+		Times(1).
+		Return(db.GetSessionByIdRow{
+			ID:        revokedID,
+			ExpiresAt: time.Now().Add(time.Hour),
+			RevokedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		}, nil)
+
+	// Only the active session seen long ago is written, once whatever the number of checks
+	store.EXPECT().
+		TouchSession(gomock.Any(), gomock.Eq(staleID)).
+		Times(1).
+		Return(sql.ErrConnDone)
+
+	checker := NewCachedChecker(store, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		// A failed write does not refuse the session
+		require.NoError(t, checker.CheckSession(context.Background(), newPayload(t, staleID)))
+		require.NoError(t, checker.CheckSession(context.Background(), newPayload(t, recentID)))
+		require.ErrorIs(t, checker.CheckSession(context.Background(), newPayload(t, revokedID)), ErrSessionRevoked)
+	}
+}
@@ -0,0 +1,52 @@
+package sms
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ConsoleSender writes text messages to stdout or appends them to a file instead of sending them,
+// it is meant for local development where codes are read from the terminal or the file
+type ConsoleSender struct {
+	mu   sync.Mutex
+	name string
+	path string
+}
+
+// NewConsoleSender creates a new ConsoleSender, messages go to stdout when path is empty
+func NewConsoleSender(name string, path string) Sender {
+	return &ConsoleSender{
+		name: name,
+		path: path,
+	}
+}
+
+// SendSMS writes the message as a single line
+func (console *ConsoleSender) SendSMS(payload Payload) error {
+	console.mu.Lock()
+	defer console.mu.Unlock()
+
+	var out io.Writer = os.Stdout
+
+	if console.path != "" {
+		file, err := os.OpenFile(console.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+
+		if err != nil {
+			return fmt.Errorf("failed to open sms file: %v", err)
+		}
+		defer file.Close()
+
+		out = file
+	}
+
+	_, err := fmt.Fprintf(out, "%s SMS from=%s to=%s %q\n", time.Now().UTC().Format(time.RFC3339), console.name, payload.To, payload.Content)
+
+	if err != nil {
+		return fmt.Errorf("failed to write sms: %v", err)
+	}
+
+	return nil
+}
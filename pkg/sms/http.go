@@ -0,0 +1,80 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxErrorBody is how much of a failed provider response ends up in the error
+const maxErrorBody = 256
+
+// HTTPSender posts text messages as JSON to the API of an SMS provider
+type HTTPSender struct {
+	name   string
+	url    string
+	token  string
+	client *http.Client
+}
+
+type httpMessage struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+// NewHTTPSender creates a new HTTPSender, token is sent as a bearer token when it is set
+func NewHTTPSender(name string, url string, token string, timeout time.Duration) (Sender, error) {
+	if url == "" {
+		return nil, errors.New("SMS_HTTP_URL is required by the http sms sender")
+	}
+
+	return &HTTPSender{
+		name:   name,
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// SendSMS sends the message, any response other than 2xx is a failure
+func (sender *HTTPSender) SendSMS(payload Payload) error {
+	body, err := json.Marshal(httpMessage{
+		From: sender.name,
+		To:   payload.To,
+		Text: payload.Content,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to marshal sms: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sender.url, bytes.NewReader(body))
+
+	if err != nil {
+		return fmt.Errorf("failed to create sms request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if sender.token != "" {
+		req.Header.Set("Authorization", "Bearer "+sender.token)
+	}
+
+	resp, err := sender.client.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("failed to send sms: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("sms provider responded with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
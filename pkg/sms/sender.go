@@ -0,0 +1,36 @@
+package sms
+
+import (
+	"fmt"
+
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+)
+
+const (
+	DriverConsole = "console"
+	DriverHTTP    = "http"
+)
+
+// Payload is a text message to a single phone number
+type Payload struct {
+	// To is an E.164 phone number
+	To      string
+	Content string
+}
+
+// Sender is an interface to send text messages
+type Sender interface {
+	SendSMS(payload Payload) error
+}
+
+// NewSender creates the sender selected by SMS_SENDER_DRIVER
+func NewSender(config *pkg.Config) (Sender, error) {
+	switch config.SmsSenderDriver {
+	case DriverConsole:
+		return NewConsoleSender(config.SmsSenderName, config.SmsConsoleFile), nil
+	case DriverHTTP:
+		return NewHTTPSender(config.SmsSenderName, config.SmsHTTPUrl, config.SmsHTTPToken, config.SmsHTTPTimeout)
+	default:
+		return nil, fmt.Errorf("unsupported sms sender driver %q", config.SmsSenderDriver)
+	}
+}
@@ -0,0 +1,71 @@
+package sms
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleSender(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sms.log")
+	sender := NewConsoleSender("SimpleBank", path)
+
+	require.NoError(t, sender.SendSMS(Payload{To: "+84901234567", Content: "Your code is 123456"}))
+	require.NoError(t, sender.SendSMS(Payload{To: "+84907654321", Content: "Your code is 654321"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "to=+84901234567")
+	require.Contains(t, lines[0], "Your code is 123456")
+	require.Contains(t, lines[1], "to=+84907654321")
+}
+
+func TestHTTPSender(t *testing.T) {
+	var received httpMessage
+
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		if received.To == "+10000000000" {
+			http.Error(w, "invalid destination", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer provider.Close()
+
+	sender, err := NewHTTPSender("SimpleBank", provider.URL, "secret", time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, sender.SendSMS(Payload{To: "+84901234567", Content: "Your code is 123456"}))
+	require.Equal(t, httpMessage{From: "SimpleBank", To: "+84901234567", Text: "Your code is 123456"}, received)
+
+	err = sender.SendSMS(Payload{To: "+10000000000", Content: "Your code is 123456"})
+	require.ErrorContains(t, err, "status 400")
+	require.ErrorContains(t, err, "invalid destination")
+}
+
+func TestNewSender(t *testing.T) {
+	sender, err := NewSender(&pkg.Config{SmsSenderDriver: DriverConsole})
+	require.NoError(t, err)
+	require.IsType(t, &ConsoleSender{}, sender)
+
+	_, err = NewSender(&pkg.Config{SmsSenderDriver: DriverHTTP})
+	require.Error(t, err)
+
+	_, err = NewSender(&pkg.Config{SmsSenderDriver: "pigeon"})
+	require.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package stepup
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+)
+
+// ErrStepUpRequired is returned when an operation needs a more recent or a stronger proof of
+// identity than the token carries
+var ErrStepUpRequired = errors.New("step-up authentication required")
+
+// strengthRanks orders the strengths of tokens, an SSO login counts as a password since what the
+// provider checked is unknown. Tokens without a strength rank below all of them.
+var strengthRanks = map[string]int{
+	token.AuthStrengthPassword: 1,
+	token.AuthStrengthSso:      1,
+	token.AuthStrengthMfa:      2,
+}
+
+// Policy tells which operations need a recent proof of identity and how recent it must be
+type Policy struct {
+	// MaxAge is how long after proving their identity a user may run sensitive operations
+	MaxAge time.Duration
+	// TokenDuration is the lifetime of the elevated access token issued by a step-up
+	TokenDuration time.Duration
+	// MinStrength is the weakest proof of identity sensitive operations accept, one of the
+	// AuthStrength constants of token
+	MinStrength string
+	// transferThresholds are the amounts, in minor units, from which a transfer needs a step-up
+	transferThresholds map[string]int64
+}
+
+// NewPolicy creates the policy of the config, STEP_UP_TRANSFER_THRESHOLDS lists amounts in major
+// units per currency such as "USD:1000,VND:25000000". Transfers in a currency missing there never
+// need a step-up. STEP_UP_MIN_STRENGTH defaults to a password.
+func NewPolicy(config *pkg.Config) (*Policy, error) {
+	thresholds, err := util.ParseMoneyList(config.StepUpTransferThresholds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid step-up transfer threshold %w", err)
+	}
+
+	minStrength := config.StepUpMinStrength
+	if minStrength == "" {
+		minStrength = token.AuthStrengthPassword
+	}
+
+	if _, ok := strengthRanks[minStrength]; !ok {
+		return nil, fmt.Errorf("invalid step-up minimum strength %q", minStrength)
+	}
+
+	policy := &Policy{
+		MaxAge:             config.StepUpMaxAge,
+		TokenDuration:      config.StepUpTokenDuration,
+		MinStrength:        minStrength,
+		transferThresholds: thresholds,
+	}
+
+	return policy, nil
+}
+
+// Check returns ErrStepUpRequired unless the user proved their identity within MaxAge with at
+// least MinStrength
+func (policy *Policy) Check(payload *token.Payload) error {
+	if !payload.AuthenticatedWithin(policy.MaxAge) || !policy.Satisfies(payload.AuthStrength) {
+		return ErrStepUpRequired
+	}
+	return nil
+}
+
+// Satisfies reports whether a proof of identity of strength is at least MinStrength
+func (policy *Policy) Satisfies(strength string) bool {
+	return strengthRanks[strength] >= strengthRanks[policy.MinStrength]
+}
+
+// TransferRequiresStepUp reports whether a transfer of amount minor units of currency is a large one
+func (policy *Policy) TransferRequiresStepUp(amount int64, currency string) bool {
+	threshold, ok := policy.transferThresholds[currency]
+	return ok && amount >= threshold
+}
@@ -0,0 +1,78 @@
+package stepup
+
+import (
+	"testing"
+	"time"
+
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPolicy(t *testing.T) {
+	policy, err := NewPolicy(&pkg.Config{
+		StepUpMaxAge:             5 * time.Minute,
+		StepUpTokenDuration:      time.Minute,
+		StepUpTransferThresholds: "USD:1000, vnd:25000000,",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, policy.MaxAge)
+	require.Equal(t, time.Minute, policy.TokenDuration)
+
+	// Thresholds are in major units, amounts in minor units
+	require.False(t, policy.TransferRequiresStepUp(99_999, util.USD))
+	require.True(t, policy.TransferRequiresStepUp(100_000, util.USD))
+	require.False(t, policy.TransferRequiresStepUp(24_999_999, util.VND))
+	require.True(t, policy.TransferRequiresStepUp(25_000_000, util.VND))
+	require.False(t, policy.TransferRequiresStepUp(1_000_000_000, util.EUR))
+
+	// A password is enough unless configured otherwise
+	require.Equal(t, token.AuthStrengthPassword, policy.MinStrength)
+
+	for _, thresholds := range []string{"USD", "USD:ten", "XYZ:1000", "USD:10.001"} {
+		_, err := NewPolicy(&pkg.Config{StepUpTransferThresholds: thresholds})
+		require.Error(t, err, thresholds)
+	}
+
+	_, err = NewPolicy(&pkg.Config{StepUpMinStrength: "otp"})
+	require.Error(t, err)
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy, err := NewPolicy(&pkg.Config{StepUpMaxAge: 5 * time.Minute})
+	require.NoError(t, err)
+
+	newPayload := func(opts ...token.PayloadOption) *token.Payload {
+		payload, err := token.NewPayload(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute, opts...)
+		require.NoError(t, err)
+		return payload
+	}
+
+	require.NoError(t, policy.Check(newPayload(token.WithAuth(time.Now(), token.AuthStrengthPassword))))
+	require.NoError(t, policy.Check(newPayload(token.WithAuth(time.Now().Add(-4*time.Minute), token.AuthStrengthMfa))))
+	require.ErrorIs(t, policy.Check(newPayload(token.WithAuth(time.Now().Add(-6*time.Minute), token.AuthStrengthMfa))), ErrStepUpRequired)
+	// Tokens from before auth_time existed and API keys carry none
+	require.ErrorIs(t, policy.Check(newPayload()), ErrStepUpRequired)
+}
+
+func TestPolicyCheckMinStrength(t *testing.T) {
+	policy, err := NewPolicy(&pkg.Config{StepUpMaxAge: 5 * time.Minute, StepUpMinStrength: token.AuthStrengthMfa})
+	require.NoError(t, err)
+
+	newPayload := func(strength string) *token.Payload {
+		payload, err := token.NewPayload(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute, token.WithAuth(time.Now(), strength))
+		require.NoError(t, err)
+		return payload
+	}
+
+	require.NoError(t, policy.Check(newPayload(token.AuthStrengthMfa)))
+	// A recent proof of identity is not enough when it is weaker than the policy
+	require.ErrorIs(t, policy.Check(newPayload(token.AuthStrengthPassword)), ErrStepUpRequired)
+	require.ErrorIs(t, policy.Check(newPayload(token.AuthStrengthSso)), ErrStepUpRequired)
+	require.ErrorIs(t, policy.Check(newPayload("")), ErrStepUpRequired)
+
+	require.True(t, policy.Satisfies(token.AuthStrengthMfa))
+	require.False(t, policy.Satisfies(token.AuthStrengthPassword))
+}
@@ -0,0 +1,190 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+)
+
+var ErrTooManyAttempts = errors.New("too many failed attempts")
+
+// BlockedError is returned by Check while a key has to wait before its next attempt
+type BlockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *BlockedError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// Scope groups the keys that share a policy
+type Scope string
+
+const (
+	LoginUser   Scope = "login_user"
+	VerifyEmail Scope = "verify_email"
+	// ResendVerifyEmail counts every resend of the verification email of a user, not only failures
+	ResendVerifyEmail Scope = "resend_verify_email"
+	VerifyPhone       Scope = "verify_phone"
+	// ForgotPassword counts every reset email asked for an address, whether or not it belongs to a user
+	ForgotPassword Scope = "forgot_password"
+	// SendPhoneCode counts every code texted to a user, whatever its purpose
+	SendPhoneCode Scope = "send_phone_code"
+	// ClientIP is shared by every guarded endpoint so an address has a single budget
+	ClientIP Scope = "client_ip"
+)
+
+// Key identifies what failed attempts are counted for, such as a username or a client IP
+type Key struct {
+	Scope Scope
+	ID    string
+}
+
+func (k Key) String() string {
+	return string(k.Scope) + ":" + k.ID
+}
+
+// Policy tells how failures of a scope are punished
+type Policy struct {
+	// MaxFailures is the number of failures within Window after which the key is locked
+	MaxFailures int64
+	// Window is how long a failure is remembered
+	Window time.Duration
+	// Lockout is how long the key is locked once MaxFailures is reached
+	Lockout time.Duration
+	// BaseDelay is the wait after the first failure, it doubles with every further failure
+	BaseDelay time.Duration
+}
+
+// blockFor returns how long a key has to wait after its n-th failure
+func (p Policy) blockFor(failures int64) time.Duration {
+	if p.MaxFailures > 0 && failures >= p.MaxFailures {
+		return p.Lockout
+	}
+
+	if p.BaseDelay <= 0 || failures < 1 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := int64(1); i < failures && delay < p.Lockout; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.Lockout)
+}
+
+// locked reports whether failures are enough to lock the key rather than just delay it
+func (p Policy) locked(failures int64) bool {
+	return p.MaxFailures > 0 && failures >= p.MaxFailures
+}
+
+// Policies maps each scope to its policy, keys of a scope without policy are never blocked
+type Policies map[Scope]Policy
+
+// NewPolicies builds the policies of login, email and phone verification from the config.
+// Client IPs get a higher threshold and no progressive delay since many users can share one.
+func NewPolicies(config *pkg.Config) Policies {
+	account := Policy{
+		MaxFailures: config.LoginMaxFailures,
+		Window:      config.LoginFailureWindow,
+		Lockout:     config.LoginLockoutDuration,
+		BaseDelay:   config.LoginBaseDelay,
+	}
+
+	return Policies{
+		LoginUser:   account,
+		VerifyEmail: account,
+		VerifyPhone: account,
+		// Resends are spaced out with a growing interval and capped within an hour
+		ResendVerifyEmail: {
+			MaxFailures: config.VerifyEmailResendMax,
+			Window:      time.Hour,
+			Lockout:     time.Hour,
+			BaseDelay:   config.VerifyEmailResendInterval,
+		},
+		// Reset emails are spaced out and capped like resends of the verification email
+		ForgotPassword: {
+			MaxFailures: config.VerifyEmailResendMax,
+			Window:      time.Hour,
+			Lockout:     time.Hour,
+			BaseDelay:   config.VerifyEmailResendInterval,
+		},
+		// Every text costs money, so codes are spaced out and capped the same way
+		SendPhoneCode: {
+			MaxFailures: config.PhoneCodeSendMax,
+			Window:      time.Hour,
+			Lockout:     time.Hour,
+			BaseDelay:   config.PhoneCodeSendInterval,
+		},
+		ClientIP: {
+			MaxFailures: config.LoginIPMaxFailures,
+			Window:      config.LoginFailureWindow,
+			Lockout:     config.LoginLockoutDuration,
+		},
+	}
+}
+
+// Status is the current state of a key
+type Status struct {
+	Failures   int64
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+// Limiter tracks failed attempts and blocks keys with too many of them
+type Limiter interface {
+	// Check returns a *BlockedError when one of the keys has to wait before its next attempt
+	Check(ctx context.Context, keys ...Key) error
+	// Fail records a failed attempt for each key and blocks the keys according to their policy
+	Fail(ctx context.Context, keys ...Key) error
+	// Reserve checks the keys and records an attempt for each of them as one atomic step, so that
+	// parallel attempts cannot all pass the check before any of them fails.
+	// It returns a *BlockedError and records nothing when one of the keys has to wait.
+	// An attempt that turns out not to be a failure is given back with Release or Reset.
+	Reserve(ctx context.Context, keys ...Key) error
+	// Release gives back an attempt recorded by Reserve, the block it set is lifted unless the key is locked
+	Release(ctx context.Context, keys ...Key) error
+	// Reset forgets the failures of the keys and unblocks them
+	Reset(ctx context.Context, keys ...Key) error
+	// Status returns the failures of a key and how long it is still blocked
+	Status(ctx context.Context, key Key) (Status, error)
+}
+
+// Attempt is an attempt reserved on some keys, it stays counted only when it is marked as failed
+type Attempt struct {
+	limiter Limiter
+	keys    []Key
+	failed  bool
+}
+
+// Reserve reserves an attempt on the keys with Limiter.Reserve.
+// Callers defer Release and call Fail once the attempt turned out to be a failure.
+func Reserve(ctx context.Context, limiter Limiter, keys ...Key) (*Attempt, error) {
+	if err := limiter.Reserve(ctx, keys...); err != nil {
+		return nil, err
+	}
+
+	return &Attempt{limiter: limiter, keys: keys}, nil
+}
+
+// Fail keeps the attempt counted as a failure
+func (a *Attempt) Fail() {
+	a.failed = true
+}
+
+// Release gives the attempt back unless it failed, keys reset in the meantime are left alone
+func (a *Attempt) Release(ctx context.Context) error {
+	if a.failed {
+		return nil
+	}
+
+	return a.limiter.Release(ctx, a.keys...)
+}
@@ -0,0 +1,191 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxMemoryKeys bounds the memory limiter, forgotten keys are swept once it is reached
+const maxMemoryKeys = 10000
+
+type memoryEntry struct {
+	failures     int64
+	forgetAt     time.Time
+	blockedUntil time.Time
+}
+
+// MemoryLimiter keeps the counters in process memory.
+// It is meant for tests and single instance setups, use RedisLimiter when several instances serve requests.
+type MemoryLimiter struct {
+	policies Policies
+	now      func() time.Time
+	mu       sync.Mutex
+	entries  map[string]*memoryEntry
+}
+
+// NewMemoryLimiter creates a limiter that keeps its counters in memory
+func NewMemoryLimiter(policies Policies) *MemoryLimiter {
+	return &MemoryLimiter{
+		policies: policies,
+		now:      time.Now,
+		entries:  make(map[string]*memoryEntry),
+	}
+}
+
+func (l *MemoryLimiter) Check(ctx context.Context, keys ...Key) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var retryAfter time.Duration
+
+	for _, key := range keys {
+		if entry := l.entry(key, now); entry != nil {
+			retryAfter = max(retryAfter, entry.blockedUntil.Sub(now))
+		}
+	}
+
+	if retryAfter > 0 {
+		return &BlockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (l *MemoryLimiter) Fail(ctx context.Context, keys ...Key) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fail(l.now(), keys)
+	return nil
+}
+
+func (l *MemoryLimiter) Reserve(ctx context.Context, keys ...Key) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	var retryAfter time.Duration
+	for _, key := range keys {
+		if entry := l.entry(key, now); entry != nil {
+			retryAfter = max(retryAfter, entry.blockedUntil.Sub(now))
+		}
+	}
+
+	if retryAfter > 0 {
+		return &BlockedError{RetryAfter: retryAfter}
+	}
+
+	l.fail(now, keys)
+	return nil
+}
+
+func (l *MemoryLimiter) Release(ctx context.Context, keys ...Key) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	for _, key := range keys {
+		entry := l.entry(key, now)
+		if entry == nil {
+			continue
+		}
+
+		entry.failures--
+		if entry.failures <= 0 {
+			delete(l.entries, key.String())
+			continue
+		}
+
+		if !l.policies[key.Scope].locked(entry.failures) {
+			entry.blockedUntil = time.Time{}
+		}
+	}
+
+	return nil
+}
+
+// fail records a failed attempt for each key, l.mu must be held
+func (l *MemoryLimiter) fail(now time.Time, keys []Key) {
+
+	for _, key := range keys {
+		policy, ok := l.policies[key.Scope]
+		if !ok {
+			continue
+		}
+
+		entry := l.entry(key, now)
+		if entry == nil {
+			if len(l.entries) >= maxMemoryKeys {
+				l.sweep(now)
+			}
+
+			entry = &memoryEntry{forgetAt: now.Add(policy.Window)}
+			l.entries[key.String()] = entry
+		}
+
+		entry.failures++
+
+		if block := policy.blockFor(entry.failures); block > 0 {
+			entry.blockedUntil = now.Add(block)
+			// A lockout longer than the window must not be forgotten before it ends
+			if entry.blockedUntil.After(entry.forgetAt) {
+				entry.forgetAt = entry.blockedUntil
+			}
+		}
+	}
+}
+
+func (l *MemoryLimiter) Reset(ctx context.Context, keys ...Key) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		delete(l.entries, key.String())
+	}
+
+	return nil
+}
+
+func (l *MemoryLimiter) Status(ctx context.Context, key Key) (Status, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	entry := l.entry(key, now)
+
+	if entry == nil {
+		return Status{}, nil
+	}
+
+	status := Status{Failures: entry.failures}
+
+	if now.Before(entry.blockedUntil) {
+		status.RetryAfter = entry.blockedUntil.Sub(now)
+		status.Locked = l.policies[key.Scope].locked(entry.failures)
+	}
+
+	return status, nil
+}
+
+// entry returns the entry of key, or nil when there is none or it is forgotten
+func (l *MemoryLimiter) entry(key Key, now time.Time) *memoryEntry {
+	entry, ok := l.entries[key.String()]
+
+	if !ok || !now.Before(entry.forgetAt) {
+		return nil
+	}
+
+	return entry
+}
+
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for k, entry := range l.entries {
+		if !now.Before(entry.forgetAt) {
+			delete(l.entries, k)
+		}
+	}
+}
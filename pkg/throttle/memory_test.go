@@ -0,0 +1,177 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicies = Policies{
+	LoginUser: {
+		MaxFailures: 3,
+		Window:      time.Hour,
+		Lockout:     10 * time.Minute,
+		BaseDelay:   time.Second,
+	},
+	ClientIP: {
+		MaxFailures: 10,
+		Window:      time.Hour,
+		Lockout:     10 * time.Minute,
+	},
+}
+
+func newTestLimiter() (*MemoryLimiter, *time.Time) {
+	now := time.Now()
+	limiter := NewMemoryLimiter(testPolicies)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestPolicyBlockFor(t *testing.T) {
+	policy := testPolicies[LoginUser]
+
+	require.Zero(t, policy.blockFor(0))
+	require.Equal(t, time.Second, policy.blockFor(1))
+	require.Equal(t, 2*time.Second, policy.blockFor(2))
+	require.Equal(t, policy.Lockout, policy.blockFor(3))
+	require.Equal(t, policy.Lockout, policy.blockFor(100))
+
+	// Without base delay only the lockout blocks
+	require.Zero(t, testPolicies[ClientIP].blockFor(9))
+
+	// The delay never exceeds the lockout
+	require.Equal(t, time.Minute, Policy{Lockout: time.Minute, BaseDelay: time.Second}.blockFor(40))
+}
+
+func TestMemoryLimiterDelaysAndLocks(t *testing.T) {
+	limiter, now := newTestLimiter()
+	ctx := context.Background()
+	user := Key{Scope: LoginUser, ID: "alice"}
+	ip := Key{Scope: ClientIP, ID: "127.0.0.1"}
+
+	require.NoError(t, limiter.Check(ctx, user, ip))
+	require.NoError(t, limiter.Fail(ctx, user, ip))
+
+	// The first failure only delays the next attempt
+	err := limiter.Check(ctx, user, ip)
+	require.ErrorIs(t, err, ErrTooManyAttempts)
+
+	var blocked *BlockedError
+	require.ErrorAs(t, err, &blocked)
+	require.Equal(t, time.Second, blocked.RetryAfter)
+
+	*now = now.Add(time.Second)
+	require.NoError(t, limiter.Check(ctx, user, ip))
+
+	// The address alone is not blocked yet
+	require.NoError(t, limiter.Check(ctx, ip))
+
+	require.NoError(t, limiter.Fail(ctx, user))
+	require.NoError(t, limiter.Fail(ctx, user))
+
+	status, err := limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), status.Failures)
+	require.True(t, status.Locked)
+	require.Equal(t, 10*time.Minute, status.RetryAfter)
+
+	*now = now.Add(10 * time.Minute)
+	require.NoError(t, limiter.Check(ctx, user))
+
+	status, err = limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.False(t, status.Locked)
+}
+
+func TestMemoryLimiterReset(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	ctx := context.Background()
+	user := Key{Scope: LoginUser, ID: "alice"}
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Fail(ctx, user))
+	}
+	require.ErrorIs(t, limiter.Check(ctx, user), ErrTooManyAttempts)
+
+	require.NoError(t, limiter.Reset(ctx, user))
+	require.NoError(t, limiter.Check(ctx, user))
+
+	status, err := limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Zero(t, status.Failures)
+}
+
+func TestMemoryLimiterForgetsFailures(t *testing.T) {
+	limiter, now := newTestLimiter()
+	ctx := context.Background()
+	user := Key{Scope: LoginUser, ID: "alice"}
+
+	require.NoError(t, limiter.Fail(ctx, user))
+	require.NoError(t, limiter.Fail(ctx, user))
+
+	*now = now.Add(time.Hour)
+
+	status, err := limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Zero(t, status.Failures)
+
+	// The window starts again so one more failure does not lock the user
+	require.NoError(t, limiter.Fail(ctx, user))
+
+	status, err = limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status.Failures)
+	require.False(t, status.Locked)
+}
+
+func TestMemoryLimiterIgnoresUnknownScope(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	ctx := context.Background()
+	key := Key{Scope: VerifyEmail, ID: "1"}
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, limiter.Fail(ctx, key))
+	}
+
+	require.NoError(t, limiter.Check(ctx, key))
+}
+
+func TestMemoryLimiterReserve(t *testing.T) {
+	limiter, now := newTestLimiter()
+	ctx := context.Background()
+
+	user := Key{Scope: LoginUser, ID: "alice"}
+	ip := Key{Scope: ClientIP, ID: "127.0.0.1"}
+
+	// The attempt is counted as soon as it is reserved, a parallel one has to wait
+	require.NoError(t, limiter.Reserve(ctx, user, ip))
+	require.ErrorIs(t, limiter.Reserve(ctx, user, ip), ErrTooManyAttempts)
+
+	status, err := limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status.Failures)
+
+	// Giving the attempt back lifts its delay
+	require.NoError(t, limiter.Release(ctx, user, ip))
+	require.NoError(t, limiter.Check(ctx, user, ip))
+
+	status, err = limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Zero(t, status.Failures)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Reserve(ctx, user))
+		*now = now.Add(time.Minute)
+	}
+
+	// A key that stays over the limit stays locked when an attempt is given back
+	require.NoError(t, limiter.Fail(ctx, user))
+	require.NoError(t, limiter.Release(ctx, user))
+	require.ErrorIs(t, limiter.Reserve(ctx, user), ErrTooManyAttempts)
+
+	status, err = limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.True(t, status.Locked)
+}
@@ -0,0 +1,265 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "throttle:"
+
+// failScript counts a failure, the window of a key starts with its first failure
+var failScript = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+if failures == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return failures
+`)
+
+// blockScript blocks a key and keeps its counter at least as long as the block
+var blockScript = redis.NewScript(`
+redis.call('SET', KEYS[2], '1', 'PX', ARGV[1])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[1]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// reserveScript fails when one of the keys is blocked, otherwise it counts an attempt for every key
+// and blocks them like Policy.blockFor. KEYS holds the counter and block key of each key,
+// ARGV holds the window, max failures, lockout and base delay of each key in milliseconds.
+var reserveScript = redis.NewScript(`
+local retry_after = 0
+for i = 2, #KEYS, 2 do
+	retry_after = math.max(retry_after, redis.call('PTTL', KEYS[i]))
+end
+if retry_after > 0 then
+	return retry_after
+end
+
+for i = 1, #KEYS, 2 do
+	local arg = (i - 1) * 2
+	local window = tonumber(ARGV[arg + 1])
+	local max_failures = tonumber(ARGV[arg + 2])
+	local lockout = tonumber(ARGV[arg + 3])
+	local base_delay = tonumber(ARGV[arg + 4])
+
+	local failures = redis.call('INCR', KEYS[i])
+	if failures == 1 then
+		redis.call('PEXPIRE', KEYS[i], window)
+	end
+
+	local block = 0
+	if max_failures > 0 and failures >= max_failures then
+		block = lockout
+	elseif base_delay > 0 then
+		block = base_delay
+		local n = 1
+		while n < failures and block < lockout do
+			block = block * 2
+			n = n + 1
+		end
+		block = math.min(block, lockout)
+	end
+
+	if block > 0 then
+		redis.call('SET', KEYS[i + 1], '1', 'PX', block)
+		if redis.call('PTTL', KEYS[i]) < block then
+			redis.call('PEXPIRE', KEYS[i], block)
+		end
+	end
+end
+return 0
+`)
+
+// releaseScript takes back an attempt and lifts the block of a key that is not locked
+var releaseScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+local failures = redis.call('DECR', KEYS[1])
+if failures <= 0 then
+	redis.call('DEL', KEYS[1], KEYS[2])
+	return 0
+end
+local max_failures = tonumber(ARGV[1])
+if max_failures <= 0 or failures < max_failures then
+	redis.call('DEL', KEYS[2])
+end
+return failures
+`)
+
+// RedisLimiter keeps the counters in Redis so every instance of the service sees the same failures
+type RedisLimiter struct {
+	client   redis.UniversalClient
+	policies Policies
+}
+
+// NewRedisLimiter creates a limiter that keeps its counters in Redis
+func NewRedisLimiter(client redis.UniversalClient, policies Policies) *RedisLimiter {
+	return &RedisLimiter{
+		client:   client,
+		policies: policies,
+	}
+}
+
+func (l *RedisLimiter) Check(ctx context.Context, keys ...Key) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pipe := l.client.Pipeline()
+	cmds := make([]*redis.DurationCmd, len(keys))
+
+	for i, key := range keys {
+		cmds[i] = pipe.PTTL(ctx, blockedKey(key))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("cannot check failed attempts: %w", err)
+	}
+
+	var retryAfter time.Duration
+	for _, cmd := range cmds {
+		retryAfter = max(retryAfter, cmd.Val())
+	}
+
+	if retryAfter > 0 {
+		return &BlockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (l *RedisLimiter) Fail(ctx context.Context, keys ...Key) error {
+	for _, key := range keys {
+		policy, ok := l.policies[key.Scope]
+		if !ok {
+			continue
+		}
+
+		counter := failuresKey(key)
+
+		failures, err := failScript.Run(ctx, l.client, []string{counter}, policy.Window.Milliseconds()).Int64()
+		if err != nil {
+			return fmt.Errorf("cannot record failed attempt: %w", err)
+		}
+
+		block := policy.blockFor(failures)
+		if block <= 0 {
+			continue
+		}
+
+		err = blockScript.Run(ctx, l.client, []string{counter, blockedKey(key)}, block.Milliseconds()).Err()
+		if err != nil {
+			return fmt.Errorf("cannot block key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l *RedisLimiter) Reserve(ctx context.Context, keys ...Key) error {
+	redisKeys := make([]string, 0, 2*len(keys))
+	args := make([]any, 0, 4*len(keys))
+
+	// Keys without policy are never blocked, they are left out like in Fail
+	for _, key := range keys {
+		policy, ok := l.policies[key.Scope]
+		if !ok {
+			continue
+		}
+
+		redisKeys = append(redisKeys, failuresKey(key), blockedKey(key))
+		args = append(args,
+			policy.Window.Milliseconds(),
+			policy.MaxFailures,
+			policy.Lockout.Milliseconds(),
+			policy.BaseDelay.Milliseconds(),
+		)
+	}
+
+	if len(redisKeys) == 0 {
+		return nil
+	}
+
+	retryAfter, err := reserveScript.Run(ctx, l.client, redisKeys, args...).Int64()
+	if err != nil {
+		return fmt.Errorf("cannot reserve attempt: %w", err)
+	}
+
+	if retryAfter > 0 {
+		return &BlockedError{RetryAfter: time.Duration(retryAfter) * time.Millisecond}
+	}
+
+	return nil
+}
+
+func (l *RedisLimiter) Release(ctx context.Context, keys ...Key) error {
+	for _, key := range keys {
+		policy, ok := l.policies[key.Scope]
+		if !ok {
+			continue
+		}
+
+		err := releaseScript.Run(ctx, l.client, []string{failuresKey(key), blockedKey(key)}, policy.MaxFailures).Err()
+		if err != nil {
+			return fmt.Errorf("cannot release attempt: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l *RedisLimiter) Reset(ctx context.Context, keys ...Key) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	redisKeys := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		redisKeys = append(redisKeys, failuresKey(key), blockedKey(key))
+	}
+
+	if err := l.client.Del(ctx, redisKeys...).Err(); err != nil {
+		return fmt.Errorf("cannot reset failed attempts: %w", err)
+	}
+
+	return nil
+}
+
+func (l *RedisLimiter) Status(ctx context.Context, key Key) (Status, error) {
+	pipe := l.client.Pipeline()
+	failuresCmd := pipe.Get(ctx, failuresKey(key))
+	blockedCmd := pipe.PTTL(ctx, blockedKey(key))
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return Status{}, fmt.Errorf("cannot get failed attempts: %w", err)
+	}
+
+	failures, err := failuresCmd.Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return Status{}, fmt.Errorf("cannot parse failed attempts: %w", err)
+	}
+
+	status := Status{Failures: failures}
+
+	if retryAfter := blockedCmd.Val(); retryAfter > 0 {
+		status.RetryAfter = retryAfter
+		status.Locked = l.policies[key.Scope].locked(failures)
+	}
+
+	return status, nil
+}
+
+func failuresKey(key Key) string {
+	return redisKeyPrefix + key.String() + ":failures"
+}
+
+func blockedKey(key Key) string {
+	return redisKeyPrefix + key.String() + ":blocked"
+}
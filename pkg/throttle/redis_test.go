@@ -0,0 +1,156 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisLimiter(t *testing.T) (*RedisLimiter, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisLimiter(client, testPolicies), server
+}
+
+func TestRedisLimiterDelaysAndLocks(t *testing.T) {
+	limiter, server := newTestRedisLimiter(t)
+	ctx := context.Background()
+
+	user := Key{Scope: LoginUser, ID: "alice"}
+	ip := Key{Scope: ClientIP, ID: "127.0.0.1"}
+
+	require.NoError(t, limiter.Check(ctx, user, ip))
+	require.NoError(t, limiter.Fail(ctx, user, ip))
+
+	// The first failure only delays the next attempt
+	err := limiter.Check(ctx, user, ip)
+	require.ErrorIs(t, err, ErrTooManyAttempts)
+
+	var blocked *BlockedError
+	require.ErrorAs(t, err, &blocked)
+	require.Equal(t, time.Second, blocked.RetryAfter)
+
+	server.FastForward(time.Second)
+	require.NoError(t, limiter.Check(ctx, user, ip))
+
+	require.NoError(t, limiter.Fail(ctx, user))
+	require.NoError(t, limiter.Fail(ctx, user))
+
+	status, err := limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), status.Failures)
+	require.True(t, status.Locked)
+	require.Equal(t, 10*time.Minute, status.RetryAfter)
+
+	// The address alone is not blocked yet
+	require.NoError(t, limiter.Check(ctx, ip))
+
+	server.FastForward(10 * time.Minute)
+	require.NoError(t, limiter.Check(ctx, user))
+
+	require.NoError(t, limiter.Reset(ctx, user, ip))
+
+	status, err = limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, Status{}, status)
+}
+
+func TestRedisLimiterReserve(t *testing.T) {
+	limiter, server := newTestRedisLimiter(t)
+	ctx := context.Background()
+
+	user := Key{Scope: LoginUser, ID: "alice"}
+	ip := Key{Scope: ClientIP, ID: "127.0.0.1"}
+
+	// The attempt is counted as soon as it is reserved, a parallel one has to wait
+	require.NoError(t, limiter.Reserve(ctx, user, ip))
+
+	err := limiter.Reserve(ctx, user, ip)
+	var blocked *BlockedError
+	require.ErrorAs(t, err, &blocked)
+	require.Equal(t, time.Second, blocked.RetryAfter)
+
+	status, err := limiter.Status(ctx, ip)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status.Failures)
+
+	// Giving the attempt back lifts its delay
+	require.NoError(t, limiter.Release(ctx, user, ip))
+	require.NoError(t, limiter.Check(ctx, user, ip))
+
+	status, err = limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Zero(t, status.Failures)
+
+	// The delay doubles with every reserved attempt until the lockout
+	for i, delay := range []time.Duration{time.Second, 2 * time.Second} {
+		require.NoError(t, limiter.Reserve(ctx, user), i)
+
+		status, err = limiter.Status(ctx, user)
+		require.NoError(t, err)
+		require.Equal(t, delay, status.RetryAfter)
+
+		server.FastForward(delay)
+	}
+
+	require.NoError(t, limiter.Reserve(ctx, user))
+
+	status, err = limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.True(t, status.Locked)
+	require.Equal(t, 10*time.Minute, status.RetryAfter)
+
+	// A key that stays over the limit stays locked when an attempt is given back
+	require.NoError(t, limiter.Fail(ctx, user))
+	require.NoError(t, limiter.Release(ctx, user))
+	require.ErrorIs(t, limiter.Reserve(ctx, user), ErrTooManyAttempts)
+
+	// Keys without policy are never counted
+	other := Key{Scope: VerifyEmail, ID: "alice"}
+	require.NoError(t, limiter.Reserve(ctx, other))
+	require.NoError(t, limiter.Reserve(ctx, other))
+}
+
+func TestRedisLimiterConcurrentReserve(t *testing.T) {
+	limiter, _ := newTestRedisLimiter(t)
+	ctx := context.Background()
+
+	user := Key{Scope: LoginUser, ID: "alice"}
+
+	n := 20
+	var wg sync.WaitGroup
+	results := make(chan error, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- limiter.Reserve(ctx, user)
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	// Only one guess of a burst gets through the progressive delay
+	passed := 0
+	for err := range results {
+		if err == nil {
+			passed++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTooManyAttempts)
+	}
+	require.Equal(t, 1, passed)
+
+	status, err := limiter.Status(ctx, user)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status.Failures)
+}
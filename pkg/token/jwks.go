@@ -0,0 +1,73 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+)
+
+// JWK is the public half of a Key in the JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySet is implemented by makers that sign with a Keyring and can publish its public keys
+type KeySet interface {
+	JWKS() JWKS
+}
+
+// JWKS returns the public keys of the keyring, retired keys are kept so their tokens still verify
+func (ring *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(ring.ids))}
+
+	for _, key := range ring.Keys() {
+		jwk := JWK{Use: "sig", Kid: key.ID, Alg: key.Algorithm}
+
+		switch pub := key.PublicKey.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+// JWKSHandler serves the verification keys of maker, symmetric makers publish an empty set
+func JWKSHandler(maker Maker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		set := JWKS{Keys: []JWK{}}
+		if keySet, ok := maker.(KeySet); ok {
+			set = keySet.JWKS()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		// Verifiers may cache the set for a while, a new key is published one deploy before it signs
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(set)
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	tk "github.com/ChokeGuy/simple-bank/pkg/token"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // JWTMaker is a JSON Web Token maker
@@ -13,9 +14,9 @@ type JWTMaker struct {
 	secretKey string
 }
 
-// CreateToken creates a new token for a specific username and duration.
-func (maker *JWTMaker) CreateToken(username string, role string, duration time.Duration) (string, *tk.Payload, error) {
-	payload, err := tk.NewPayload(username, role, duration)
+// CreateToken creates a new token for a specific username, login session and duration.
+func (maker *JWTMaker) CreateToken(username string, role string, sessionID uuid.UUID, duration time.Duration, opts ...tk.PayloadOption) (string, *tk.Payload, error) {
+	payload, err := tk.NewPayload(username, role, sessionID, duration, opts...)
 
 	if err != nil {
 		return "", payload, err
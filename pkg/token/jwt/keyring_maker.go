@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"errors"
+	"time"
+
+	tk "github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// KeyringMaker is a JSON Web Token maker signing with the asymmetric key of a keyring,
+// EdDSA for Ed25519 keys and RS256 for RSA keys. Tokens name their key in the kid header.
+type KeyringMaker struct {
+	keyring *tk.Keyring
+}
+
+// NewKeyringMaker create a new KeyringMaker
+func NewKeyringMaker(keyring *tk.Keyring) (tk.Maker, error) {
+	return &KeyringMaker{keyring: keyring}, nil
+}
+
+// CreateToken creates a new token for a specific username, login session and duration.
+func (maker *KeyringMaker) CreateToken(username string, role string, sessionID uuid.UUID, duration time.Duration, opts ...tk.PayloadOption) (string, *tk.Payload, error) {
+	payload, err := tk.NewPayload(username, role, sessionID, duration, opts...)
+
+	if err != nil {
+		return "", payload, err
+	}
+
+	key := maker.keyring.SigningKey()
+
+	jwtToken := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), payload)
+	jwtToken.Header["kid"] = key.ID
+
+	token, err := jwtToken.SignedString(key.PrivateKey)
+	return token, payload, err
+}
+
+// VerifyToken check if the token is valid or not.
+func (maker *KeyringMaker) VerifyToken(token string) (*tk.Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, tk.ErrInvalidToken
+		}
+
+		key, err := maker.keyring.Key(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		// A key only verifies the algorithm it was made for, so an RSA public key is never taken as an HMAC secret
+		if token.Method.Alg() != key.Algorithm {
+			return nil, tk.ErrInvalidToken
+		}
+
+		return key.PublicKey, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &tk.Payload{}, keyFunc,
+		jwt.WithValidMethods([]string{tk.AlgorithmEdDSA, tk.AlgorithmRS256}))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, jwt.ErrTokenExpired
+		}
+		return nil, tk.ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*tk.Payload)
+	if !ok {
+		return nil, tk.ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+// JWKS publishes the keys tokens of this maker are verified with
+func (maker *KeyringMaker) JWKS() tk.JWKS {
+	return maker.keyring.JWKS()
+}
@@ -0,0 +1,140 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	tk "github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeyringMaker(t *testing.T, id string, privateKey any) (tk.Maker, *tk.Key) {
+	key, err := tk.NewKey(id, privateKey)
+	require.NoError(t, err)
+
+	keyring, err := tk.NewKeyring(id, key)
+	require.NoError(t, err)
+
+	maker, err := NewKeyringMaker(keyring)
+	require.NoError(t, err)
+	return maker, key
+}
+
+func TestKeyringMaker(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		privateKey any
+		alg        string
+	}{
+		"EdDSA": {edKey, tk.AlgorithmEdDSA},
+		"RS256": {rsaKey, tk.AlgorithmRS256},
+	} {
+		t.Run(name, func(t *testing.T) {
+			maker, _ := newKeyringMaker(t, "key-1", tc.privateKey)
+
+			username := util.RandomOwner()
+			sessionID := uuid.New()
+
+			token, payload, err := maker.CreateToken(username, util.DepositorRole, sessionID, time.Minute)
+			require.NoError(t, err)
+			require.NotEmpty(t, payload)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &tk.Payload{})
+			require.NoError(t, err)
+			require.Equal(t, "key-1", parsed.Header["kid"])
+			require.Equal(t, tc.alg, parsed.Header["alg"])
+
+			payload, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, username, payload.UserName)
+			require.Equal(t, sessionID, payload.SessionID)
+		})
+	}
+}
+
+func TestExpiredKeyringToken(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	maker, _ := newKeyringMaker(t, "key-1", edKey)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, jwt.ErrTokenExpired.Error())
+	require.Nil(t, payload)
+}
+
+func TestKeyringMakerRotation(t *testing.T) {
+	_, oldPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, newPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	oldMaker, oldKey := newKeyringMaker(t, "key-1", oldPrivateKey)
+	oldToken, _, err := oldMaker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	newKey, err := tk.NewKey("key-2", newPrivateKey)
+	require.NoError(t, err)
+
+	keyring, err := tk.NewKeyring("key-2", newKey, oldKey)
+	require.NoError(t, err)
+	rotatedMaker, err := NewKeyringMaker(keyring)
+	require.NoError(t, err)
+
+	_, err = rotatedMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, _, err := rotatedMaker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	_, err = oldMaker.VerifyToken(newToken)
+	require.ErrorIs(t, err, tk.ErrInvalidToken)
+}
+
+func TestInvalidKeyringToken(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	maker, key := newKeyringMaker(t, "key-1", edKey)
+	payload, err := tk.NewPayload(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	sign := func(method jwt.SigningMethod, kid any, signingKey any) string {
+		jwtToken := jwt.NewWithClaims(method, payload)
+		if kid != nil {
+			jwtToken.Header["kid"] = kid
+		}
+
+		token, err := jwtToken.SignedString(signingKey)
+		require.NoError(t, err)
+		return token
+	}
+
+	for name, token := range map[string]string{
+		"NoKid":      sign(jwt.SigningMethodEdDSA, nil, edKey),
+		"UnknownKid": sign(jwt.SigningMethodEdDSA, "key-9", edKey),
+		"AlgNone":    sign(jwt.SigningMethodNone, "key-1", jwt.UnsafeAllowNoneSignatureType),
+		// The public key must not be accepted as an HMAC secret
+		"HmacWithPublicKey": sign(jwt.SigningMethodHS256, "key-1", []byte(key.PublicKey.(ed25519.PublicKey))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			payload, err := maker.VerifyToken(token)
+			require.ErrorIs(t, err, tk.ErrInvalidToken)
+			require.Nil(t, payload)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Algorithms of the asymmetric keys, named like the JWS "alg" header
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+)
+
+// MinRSAKeyBits is the smallest RSA modulus accepted for signing tokens
+const MinRSAKeyBits = 2048
+
+var (
+	ErrUnknownKeyID       = errors.New("unknown token key id")
+	ErrNoSigningKey       = errors.New("signing key has no private key")
+	ErrUnsupportedKey     = errors.New("unsupported token key: must be Ed25519 or RSA")
+	ErrUnsupportedKeySize = fmt.Errorf("invalid RSA key size: must be at least %d bits", MinRSAKeyBits)
+)
+
+// Key is one asymmetric key of a Keyring. Retired keys may have no private key, they only verify.
+type Key struct {
+	ID         string
+	Algorithm  string
+	PublicKey  crypto.PublicKey
+	PrivateKey crypto.Signer
+}
+
+// NewKey wraps an Ed25519 or RSA key, private keys also provide their public key
+func NewKey(id string, key any) (*Key, error) {
+	if id == "" {
+		return nil, errors.New("token key id must not be empty")
+	}
+
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Algorithm: AlgorithmEdDSA, PublicKey: k.Public(), PrivateKey: k}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Algorithm: AlgorithmEdDSA, PublicKey: k}, nil
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < MinRSAKeyBits {
+			return nil, ErrUnsupportedKeySize
+		}
+		return &Key{ID: id, Algorithm: AlgorithmRS256, PublicKey: &k.PublicKey, PrivateKey: k}, nil
+	case *rsa.PublicKey:
+		if k.N.BitLen() < MinRSAKeyBits {
+			return nil, ErrUnsupportedKeySize
+		}
+		return &Key{ID: id, Algorithm: AlgorithmRS256, PublicKey: k}, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// ParseKeyPEM parses a PKCS #8 private key or a PKIX public key
+func ParseKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("token key %s: no PEM block found", id)
+	}
+
+	var (
+		key any
+		err error
+	)
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("token key %s: unsupported PEM block %q", id, block.Type)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("token key %s: %w", id, err)
+	}
+
+	return NewKey(id, key)
+}
+
+// Keyring holds the key that signs new tokens and every key whose tokens are still accepted.
+//
+// Keys are rotated without downtime in three deploys: add the next key so every instance
+// verifies and publishes it, make it the signing key, then drop the old key (or keep only its
+// public half) once the last token it signed has expired.
+type Keyring struct {
+	signing *Key
+	keys    map[string]*Key
+	ids     []string
+}
+
+// NewKeyring creates a keyring signing with the key signingKeyID, the other keys only verify
+func NewKeyring(signingKeyID string, keys ...*Key) (*Keyring, error) {
+	ring := &Keyring{keys: make(map[string]*Key, len(keys))}
+
+	for _, key := range keys {
+		if _, ok := ring.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate token key id %s", key.ID)
+		}
+		ring.keys[key.ID] = key
+		ring.ids = append(ring.ids, key.ID)
+	}
+	sort.Strings(ring.ids)
+
+	signing, ok := ring.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q: %w", signingKeyID, ErrUnknownKeyID)
+	}
+
+	if signing.PrivateKey == nil {
+		return nil, fmt.Errorf("signing key %q: %w", signingKeyID, ErrNoSigningKey)
+	}
+
+	ring.signing = signing
+	return ring, nil
+}
+
+// LoadKeyring reads every <kid>.pem file of dir, the file name without extension is the key id
+func LoadKeyring(dir string, signingKeyID string) (*Keyring, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := ParseKeyPEM(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeyring(signingKeyID, keys...)
+}
+
+// SigningKey returns the key new tokens are signed with
+func (ring *Keyring) SigningKey() *Key {
+	return ring.signing
+}
+
+// Key returns the key with the given id, active or retired
+func (ring *Keyring) Key(id string) (*Key, error) {
+	key, ok := ring.keys[id]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return key, nil
+}
+
+// Keys returns every key of the keyring ordered by id
+func (ring *Keyring) Keys() []*Key {
+	keys := make([]*Key, 0, len(ring.ids))
+	for _, id := range ring.ids {
+		keys = append(keys, ring.keys[id])
+	}
+	return keys
+}
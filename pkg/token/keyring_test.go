@@ -0,0 +1,121 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyPEM(t *testing.T, dir string, id string, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, id+".pem"), data, 0o600))
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	writeKeyPEM(t, dir, "key-2", "PRIVATE KEY", der)
+
+	retiredKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err = x509.MarshalPKIXPublicKey(&retiredKey.PublicKey)
+	require.NoError(t, err)
+	writeKeyPEM(t, dir, "key-1", "PUBLIC KEY", der)
+
+	keyring, err := LoadKeyring(dir, "key-2")
+	require.NoError(t, err)
+
+	require.Equal(t, "key-2", keyring.SigningKey().ID)
+	require.Equal(t, AlgorithmEdDSA, keyring.SigningKey().Algorithm)
+	require.Len(t, keyring.Keys(), 2)
+
+	retired, err := keyring.Key("key-1")
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmRS256, retired.Algorithm)
+	require.Nil(t, retired.PrivateKey)
+
+	_, err = keyring.Key("key-3")
+	require.ErrorIs(t, err, ErrUnknownKeyID)
+
+	// A retired key without its private half can not sign
+	_, err = LoadKeyring(dir, "key-1")
+	require.ErrorIs(t, err, ErrNoSigningKey)
+
+	_, err = LoadKeyring(dir, "key-3")
+	require.ErrorIs(t, err, ErrUnknownKeyID)
+
+	set := keyring.JWKS()
+	require.Len(t, set.Keys, 2)
+
+	require.Equal(t, JWK{Kty: "RSA", Use: "sig", Kid: "key-1", Alg: AlgorithmRS256, N: set.Keys[0].N, E: "AQAB"}, set.Keys[0])
+	require.Equal(t, JWK{
+		Kty: "OKP",
+		Use: "sig",
+		Kid: "key-2",
+		Alg: AlgorithmEdDSA,
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(publicKey),
+	}, set.Keys[1])
+}
+
+func TestNewKeyRejectsWeakRSA(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	_, err = NewKey("key-1", privateKey)
+	require.ErrorIs(t, err, ErrUnsupportedKeySize)
+
+	_, err = NewKey("key-1", []byte("secret"))
+	require.ErrorIs(t, err, ErrUnsupportedKey)
+}
+
+type keySetMaker struct {
+	Maker
+	keyring *Keyring
+}
+
+func (maker keySetMaker) JWKS() JWKS {
+	return maker.keyring.JWKS()
+}
+
+func TestJWKSHandler(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := NewKey("key-1", privateKey)
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring("key-1", key)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	JWKSHandler(keySetMaker{keyring: keyring}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var set JWKS
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 1)
+	require.Equal(t, "key-1", set.Keys[0].Kid)
+
+	// Symmetric makers have no key to publish
+	recorder = httptest.NewRecorder()
+	JWKSHandler(nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.JSONEq(t, `{"keys":[]}`, recorder.Body.String())
+}
@@ -1,11 +1,15 @@
 package token
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Maker is an interface that defines the methods a token maker type must provide
 type Maker interface {
-	// CreateToken generates a new token for a specific username and duration
-	CreateToken(username string, role string, duration time.Duration) (string, *Payload, error)
+	// CreateToken generates a new token for a specific username, login session and duration
+	CreateToken(username string, role string, sessionID uuid.UUID, duration time.Duration, opts ...PayloadOption) (string, *Payload, error)
 	// VerifyToken checks if the token is valid or not
 	VerifyToken(token string) (*Payload, error)
 }
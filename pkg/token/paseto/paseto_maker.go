@@ -5,6 +5,7 @@ import (
 
 	tk "github.com/ChokeGuy/simple-bank/pkg/token"
 	"github.com/aead/chacha20poly1305"
+	"github.com/google/uuid"
 	"github.com/o1egl/paseto"
 )
 
@@ -27,9 +28,9 @@ func NewPasetoMaker(symmetricKey string) (tk.Maker, error) {
 	return maker, nil
 }
 
-// CreateToken creates a new token for a specific username and duration.
-func (maker *PasetoMaker) CreateToken(username string, role string, duration time.Duration) (string, *tk.Payload, error) {
-	payload, err := tk.NewPayload(username, role, duration)
+// CreateToken creates a new token for a specific username, login session and duration.
+func (maker *PasetoMaker) CreateToken(username string, role string, sessionID uuid.UUID, duration time.Duration, opts ...tk.PayloadOption) (string, *tk.Payload, error) {
+	payload, err := tk.NewPayload(username, role, sessionID, duration, opts...)
 
 	if err != nil {
 		return "", payload, err
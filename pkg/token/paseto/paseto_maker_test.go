@@ -4,8 +4,10 @@ import (
 	"testing"
 	"time"
 
+	tk "github.com/ChokeGuy/simple-bank/pkg/token"
 	"github.com/ChokeGuy/simple-bank/util"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,12 +17,13 @@ func TestPasetoMaker(t *testing.T) {
 
 	username := util.RandomOwner()
 	role := util.DepositorRole
+	sessionID := uuid.New()
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, role, duration)
+	token, payload, err := maker.CreateToken(username, role, sessionID, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -30,19 +33,73 @@ func TestPasetoMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.UserName)
+	require.Equal(t, sessionID, payload.SessionID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt.Time, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiresAt.Time, time.Second)
 }
 
+func TestPasetoMakerAuthClaims(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	authTime := time.Now().Add(-time.Minute)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute, tk.WithAuth(authTime, tk.AuthStrengthMfa))
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.WithinDuration(t, authTime, payload.AuthTime.Time, time.Second)
+	require.Equal(t, tk.AuthStrengthMfa, payload.AuthStrength)
+
+	// A refresh keeps the authentication of the token it replaces
+	token, _, err = maker.CreateToken(payload.UserName, payload.Role, payload.SessionID, time.Minute, tk.WithAuthOf(payload))
+	require.NoError(t, err)
+
+	refreshed, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, payload.AuthTime.Unix(), refreshed.AuthTime.Unix())
+	require.Equal(t, payload.AuthStrength, refreshed.AuthStrength)
+	require.True(t, refreshed.AuthenticatedWithin(2*time.Minute))
+	require.False(t, refreshed.AuthenticatedWithin(30*time.Second))
+}
+
+func TestPasetoMakerTokenType(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NoError(t, payload.CheckType(tk.TokenTypeAccess))
+	require.ErrorIs(t, payload.CheckType(tk.TokenTypeRefresh), tk.ErrWrongTokenType)
+
+	token, _, err = maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute, tk.WithTokenType(tk.TokenTypeRefresh))
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NoError(t, payload.CheckType(tk.TokenTypeRefresh))
+	require.ErrorIs(t, payload.CheckType(tk.TokenTypeAccess), tk.ErrWrongTokenType)
+
+	// Tokens issued before the claim existed are neither
+	payload.TokenType = ""
+	require.Error(t, payload.CheckType(tk.TokenTypeAccess))
+	require.Error(t, payload.CheckType(tk.TokenTypeRefresh))
+}
+
 func TestExpirePasetoToken(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
 	username := util.RandomOwner()
 	role := util.DepositorRole
+	sessionID := uuid.New()
 	duration := -time.Minute
 
-	token, payload, err := maker.CreateToken(username, role, duration)
+	token, payload, err := maker.CreateToken(username, role, sessionID, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
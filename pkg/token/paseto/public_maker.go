@@ -0,0 +1,145 @@
+package paseto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tk "github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/google/uuid"
+)
+
+// publicHeader is the header of PASETO v4.public tokens
+const publicHeader = "v4.public."
+
+// publicFooter is the footer of the tokens, it names the key that signed them
+type publicFooter struct {
+	Kid string `json:"kid"`
+}
+
+// PasetoPublicMaker is a PASETO v4.public Token maker, tokens are signed with the Ed25519 signing
+// key of a keyring so services holding only the public keys can verify them but not mint them
+type PasetoPublicMaker struct {
+	keyring *tk.Keyring
+}
+
+// NewPasetoPublicMaker create a new PasetoPublicMaker
+func NewPasetoPublicMaker(keyring *tk.Keyring) (tk.Maker, error) {
+	if keyring.SigningKey().Algorithm != tk.AlgorithmEdDSA {
+		return nil, fmt.Errorf("PASETO v4.public needs an Ed25519 signing key: %w", tk.ErrUnsupportedKey)
+	}
+
+	return &PasetoPublicMaker{keyring: keyring}, nil
+}
+
+// CreateToken creates a new token for a specific username, login session and duration.
+func (maker *PasetoPublicMaker) CreateToken(username string, role string, sessionID uuid.UUID, duration time.Duration, opts ...tk.PayloadOption) (string, *tk.Payload, error) {
+	payload, err := tk.NewPayload(username, role, sessionID, duration, opts...)
+
+	if err != nil {
+		return "", payload, err
+	}
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", payload, err
+	}
+
+	key := maker.keyring.SigningKey()
+	footer, err := json.Marshal(publicFooter{Kid: key.ID})
+	if err != nil {
+		return "", payload, err
+	}
+
+	signature := ed25519.Sign(key.PrivateKey.(ed25519.PrivateKey), preAuthEncode([]byte(publicHeader), message, footer, nil))
+
+	token := publicHeader +
+		base64.RawURLEncoding.EncodeToString(append(message, signature...)) + "." +
+		base64.RawURLEncoding.EncodeToString(footer)
+	return token, payload, nil
+}
+
+// VerifyToken check if the token is valid or not.
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*tk.Payload, error) {
+	if !strings.HasPrefix(token, publicHeader) {
+		return nil, tk.ErrInvalidToken
+	}
+
+	parts := strings.Split(strings.TrimPrefix(token, publicHeader), ".")
+	if len(parts) != 2 {
+		return nil, tk.ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return nil, tk.ErrInvalidToken
+	}
+
+	footer, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, tk.ErrInvalidToken
+	}
+
+	var f publicFooter
+	if err := json.Unmarshal(footer, &f); err != nil {
+		return nil, tk.ErrInvalidToken
+	}
+
+	key, err := maker.keyring.Key(f.Kid)
+	if err != nil {
+		return nil, tk.ErrInvalidToken
+	}
+
+	publicKey, ok := key.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, tk.ErrInvalidToken
+	}
+
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(publicKey, preAuthEncode([]byte(publicHeader), message, footer, nil), signature) {
+		return nil, tk.ErrInvalidToken
+	}
+
+	payload := &tk.Payload{}
+	if err := json.Unmarshal(message, payload); err != nil {
+		return nil, tk.ErrInvalidToken
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// JWKS publishes the keys tokens of this maker are verified with
+func (maker *PasetoPublicMaker) JWKS() tk.JWKS {
+	return maker.keyring.JWKS()
+}
+
+// preAuthEncode is the PASETO pre-authentication encoding, every piece is prefixed by its
+// length so no two lists of pieces encode to the same bytes
+func preAuthEncode(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+
+	writeLength := func(n int) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(n)&^(1<<63))
+		buf.Write(b[:])
+	}
+
+	writeLength(len(pieces))
+	for _, piece := range pieces {
+		writeLength(len(piece))
+		buf.Write(piece)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,177 @@
+package paseto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	tk "github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newEd25519Key(t *testing.T, id string) *tk.Key {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := tk.NewKey(id, privateKey)
+	require.NoError(t, err)
+	return key
+}
+
+func TestPasetoPublicMaker(t *testing.T) {
+	keyring, err := tk.NewKeyring("key-1", newEd25519Key(t, "key-1"))
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	sessionID := uuid.New()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, util.DepositorRole, sessionID, duration)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.UserName)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt.Time, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiresAt.Time, time.Second)
+}
+
+func TestExpirePasetoPublicToken(t *testing.T) {
+	keyring, err := tk.NewKeyring("key-1", newEd25519Key(t, "key-1"))
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, jwt.ErrTokenExpired.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicKeyRotation(t *testing.T) {
+	oldKey := newEd25519Key(t, "key-1")
+	newKey := newEd25519Key(t, "key-2")
+
+	oldRing, err := tk.NewKeyring("key-1", oldKey)
+	require.NoError(t, err)
+	oldMaker, err := NewPasetoPublicMaker(oldRing)
+	require.NoError(t, err)
+
+	oldToken, _, err := oldMaker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	// The old key is retired, only its public half is kept to verify the tokens it signed
+	retired, err := tk.NewKey("key-1", oldKey.PublicKey)
+	require.NoError(t, err)
+
+	rotatedRing, err := tk.NewKeyring("key-2", newKey, retired)
+	require.NoError(t, err)
+	rotatedMaker, err := NewPasetoPublicMaker(rotatedRing)
+	require.NoError(t, err)
+
+	_, err = rotatedMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, _, err := rotatedMaker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	_, err = rotatedMaker.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	// Instances still on the old keyring do not know key-2 yet
+	_, err = oldMaker.VerifyToken(newToken)
+	require.ErrorIs(t, err, tk.ErrInvalidToken)
+}
+
+func TestInvalidPasetoPublicToken(t *testing.T) {
+	keyring, err := tk.NewKeyring("key-1", newEd25519Key(t, "key-1"))
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	// A key with the same id but another secret must not verify the token
+	otherRing, err := tk.NewKeyring("key-1", newEd25519Key(t, "key-1"))
+	require.NoError(t, err)
+	otherMaker, err := NewPasetoPublicMaker(otherRing)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	tamperedFooter := strings.Join(append(parts[:3:3], base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"key-9"}`))), ".")
+
+	symmetricMaker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+	localToken, _, err := symmetricMaker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		maker tk.Maker
+		token string
+	}{
+		"OtherKey":      {otherMaker, token},
+		"UnknownKid":    {maker, tamperedFooter},
+		"LocalToken":    {maker, localToken},
+		"Truncated":     {maker, token[:len(token)/2]},
+		"MissingFooter": {maker, strings.Join(parts[:3], ".")},
+		"Malformed":     {maker, "abc"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			payload, err := tc.maker.VerifyToken(tc.token)
+			require.ErrorIs(t, err, tk.ErrInvalidToken)
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestNewPasetoPublicMakerRequiresEd25519(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	key, err := tk.NewKey("rsa-1", privateKey)
+	require.NoError(t, err)
+
+	keyring, err := tk.NewKeyring("rsa-1", key)
+	require.NoError(t, err)
+
+	_, err = NewPasetoPublicMaker(keyring)
+	require.ErrorIs(t, err, tk.ErrUnsupportedKey)
+}
+
+// TestPasetoV4PublicVector checks the signature against the 4-S-1 vector of the PASETO specification
+func TestPasetoV4PublicVector(t *testing.T) {
+	secretKey, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+
+	message := []byte(`{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`)
+	signature := ed25519.Sign(ed25519.PrivateKey(secretKey), preAuthEncode([]byte(publicHeader), message, nil, nil))
+
+	require.Equal(t,
+		"v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA",
+		publicHeader+base64.RawURLEncoding.EncodeToString(append(message, signature...)),
+	)
+}
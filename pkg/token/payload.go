@@ -17,33 +17,90 @@ const (
 // ErrInvalidToken is returned when the token is invalid
 var (
 	ErrInvalidToken         = errors.New("invalid token")
+	ErrWrongTokenType       = errors.New("wrong token type")
 	ErrInvalidJWTKeySize    = fmt.Errorf("invalid key size: must be at least %d characters", MinSecretKeySize)
 	ErrInvalidPasetoKeySize = fmt.Errorf("invalid key size: must be at least %d characters", chacha20poly1305.KeySize)
 )
 
 // Payload is the payload data of the token
 type Payload struct {
-	UserName string `json:"userName"`
-	Role     string `json:"role"`
+	UserName  string    `json:"userName"`
+	Role      string    `json:"role"`
+	SessionID uuid.UUID `json:"sessionId"`
+	// Scopes narrow the permissions of the role for API keys, tokens of users have none and keep the whole role
+	Scopes []string `json:"scopes,omitempty"`
+	// AuthTime is when the user last proved their identity, a refresh keeps it and a step-up renews it
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	// AuthStrength is how the user proved their identity at AuthTime, one of the AuthStrength constants
+	AuthStrength string `json:"acr,omitempty"`
+	// TokenType tells access tokens from refresh tokens, one of the TokenType constants
+	TokenType string `json:"tokenType"`
 	jwt.RegisteredClaims
 }
 
+// Types of the tokens issued to users, only access tokens authenticate requests and only
+// refresh tokens can be exchanged for new tokens
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// Strengths of the proof of identity a token was issued for
+const (
+	AuthStrengthPassword = "pwd"
+	AuthStrengthMfa      = "mfa"
+	// AuthStrengthSso tokens were issued after a login at the corporate identity provider
+	AuthStrengthSso = "sso"
+)
+
+// PayloadOption sets optional claims of a new Payload
+type PayloadOption func(*Payload)
+
+// WithTokenType sets the type of the token, new payloads are access tokens
+func WithTokenType(tokenType string) PayloadOption {
+	return func(payload *Payload) {
+		payload.TokenType = tokenType
+	}
+}
+
+// WithAuth sets when and how the user proved their identity
+func WithAuth(authTime time.Time, strength string) PayloadOption {
+	return func(payload *Payload) {
+		payload.AuthTime = jwt.NewNumericDate(authTime)
+		payload.AuthStrength = strength
+	}
+}
+
+// WithAuthOf carries the authentication claims of another token over, e.g. on a refresh
+func WithAuthOf(other *Payload) PayloadOption {
+	return func(payload *Payload) {
+		payload.AuthTime = other.AuthTime
+		payload.AuthStrength = other.AuthStrength
+	}
+}
+
 // NewPayload creates a new Payload instance
-func NewPayload(username string, role string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, sessionID uuid.UUID, duration time.Duration, opts ...PayloadOption) (*Payload, error) {
 	tokenId, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
 	}
 
 	payload := &Payload{
-		UserName: username,
-		Role:     role,
+		UserName:  username,
+		Role:      role,
+		SessionID: sessionID,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ID:        tokenId.String(),
 		},
 	}
+
+	for _, opt := range opts {
+		opt(payload)
+	}
 	return payload, nil
 }
 
@@ -53,3 +110,30 @@ func (payload *Payload) Valid() error {
 	}
 	return nil
 }
+
+// CheckType returns ErrWrongTokenType unless the token is of tokenType. Tokens issued before
+// types existed have none and are rejected as either type.
+func (payload *Payload) CheckType(tokenType string) error {
+	if payload.TokenType != tokenType {
+		return ErrWrongTokenType
+	}
+	return nil
+}
+
+// IssuedBefore reports whether the token was issued before t. The issue time is kept with
+// second precision, so t is truncated too and a token of the same second is not rejected.
+func (payload *Payload) IssuedBefore(t time.Time) bool {
+	if payload.IssuedAt == nil {
+		return true
+	}
+	return payload.IssuedAt.Time.Before(t.Truncate(time.Second))
+}
+
+// AuthenticatedWithin reports whether the user proved their identity less than maxAge ago.
+// Tokens without auth_time, like the payloads of API keys, never are.
+func (payload *Payload) AuthenticatedWithin(maxAge time.Duration) bool {
+	if payload.AuthTime == nil {
+		return false
+	}
+	return time.Since(payload.AuthTime.Time) <= maxAge
+}
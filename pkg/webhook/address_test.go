@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	public := []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111", "::ffff:8.8.8.8"}
+	for _, addr := range public {
+		require.True(t, IsPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+
+	forbidden := []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "255.255.255.255", "224.0.0.1",
+		"::1", "::", "fd00::1", "fe80::1", "::ffff:127.0.0.1", "::ffff:169.254.169.254", "64:ff9b::a00:1",
+	}
+	for _, addr := range forbidden {
+		require.False(t, IsPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	require.NoError(t, DialControl("tcp", "8.8.8.8:443", nil))
+	require.ErrorIs(t, DialControl("tcp", "127.0.0.1:443", nil), ErrForbiddenAddress)
+	require.ErrorIs(t, DialControl("tcp6", "[::1]:443", nil), ErrForbiddenAddress)
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/google/uuid"
+)
+
+const (
+	EventTransferCompleted = "transfer.completed"
+	EventUserVerified      = "user.verified"
+)
+
+// EventTypes lists the events an endpoint can subscribe to
+var EventTypes = []string{
+	EventTransferCompleted,
+	EventUserVerified,
+}
+
+func IsSupportedEventType(eventType string) bool {
+	return slices.Contains(EventTypes, eventType)
+}
+
+// Event is the body of every webhook request
+type Event struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewEvent creates an event with a fresh ID, receivers use the ID to drop duplicate deliveries
+func NewEvent(eventType string, data any) (Event, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      jsonData,
+	}, nil
+}
+
+// TransferCompletedData is the data of a transfer.completed event
+type TransferCompletedData struct {
+	TransferID        uuid.UUID  `json:"transferId"`
+	FromAccountNumber string     `json:"fromAccountNumber"`
+	ToAccountNumber   string     `json:"toAccountNumber"`
+	Amount            util.Money `json:"amount"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+// UserVerifiedData is the data of a user.verified event
+type UserVerifiedData struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, secretPrefix))
+
+	payload := []byte(`{"type":"transfer.completed"}`)
+	now := time.Now()
+
+	header := Sign(payload, now, secret)
+	require.NoError(t, Verify(header, payload, secret, 5*time.Minute, now))
+
+	// a tampered body must not verify
+	require.ErrorIs(t, Verify(header, []byte(`{"type":"user.verified"}`), secret, 5*time.Minute, now), ErrSignatureMismatch)
+
+	// neither must a different secret
+	otherSecret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.ErrorIs(t, Verify(header, payload, otherSecret, 5*time.Minute, now), ErrSignatureMismatch)
+
+	// old requests are rejected so they cannot be replayed
+	require.ErrorIs(t, Verify(header, payload, secret, 5*time.Minute, now.Add(10*time.Minute)), ErrTimestampOutOfRange)
+}
+
+func TestSignWithRotatedSecrets(t *testing.T) {
+	oldSecret, err := GenerateSecret()
+	require.NoError(t, err)
+	newSecret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	payload := []byte(`{}`)
+	now := time.Now()
+
+	header := Sign(payload, now, newSecret, oldSecret)
+	require.Equal(t, 3, len(strings.Split(header, ",")))
+
+	require.NoError(t, Verify(header, payload, oldSecret, time.Minute, now))
+	require.NoError(t, Verify(header, payload, newSecret, time.Minute, now))
+}
+
+func TestVerifyInvalidHeader(t *testing.T) {
+	for _, header := range []string{"", "t=abc,v1=00", "v1=00", "t=1700000000", "garbage"} {
+		require.ErrorIs(t, Verify(header, []byte(`{}`), "secret", time.Minute, time.Unix(1700000000, 0)), ErrInvalidSignatureHeader, header)
+	}
+}
@@ -2,11 +2,25 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"testing"
 
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/apikey"
+	"github.com/ChokeGuy/simple-bank/pkg/blob"
+	"github.com/ChokeGuy/simple-bank/pkg/clientip"
 	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/encryption"
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/ChokeGuy/simple-bank/pkg/mfa"
+	"github.com/ChokeGuy/simple-bank/pkg/oidc"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/session"
+	"github.com/ChokeGuy/simple-bank/pkg/stepup"
+	"github.com/ChokeGuy/simple-bank/pkg/throttle"
+	"github.com/ChokeGuy/simple-bank/util/password"
+	"github.com/ChokeGuy/simple-bank/validations"
 	"github.com/ChokeGuy/simple-bank/worker"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
@@ -21,6 +35,17 @@ type Server struct {
 	Config          *pkg.Config
 	Store           db.Store
 	TokenMaker      token.Maker
+	SessionChecker  session.Checker
+	ApiKeyVerifier  apikey.Verifier
+	PasswordHasher  password.PasswordHasher
+	PasswordPolicy  *validations.PasswordPolicy
+	MFA             *mfa.Authenticator
+	PhoneCodes      *phone.Verifier
+	OIDC            *oidc.Login
+	StepUpPolicy    *stepup.Policy
+	KycDocuments    *kyc.Documents
+	Limiter         throttle.Limiter
+	ClientIP        *clientip.Resolver
 	TaskDistributor worker.TaskDistributor
 	GrpcServer      *grpc.Server
 	Listener        net.Listener
@@ -32,11 +57,75 @@ func NewServer(
 	config *pkg.Config,
 	tokenMaker token.Maker,
 	taskDistributor worker.TaskDistributor,
+	limiter throttle.Limiter,
 ) (*Server, error) {
 
+	cipher, err := encryption.NewAESCipher(config.TotpEncryptionKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create totp cipher: %w", err)
+	}
+
+	hasher, err := password.NewHasher(config.PasswordHashAlgorithm, password.Argon2Params{
+		Memory:      config.Argon2Memory,
+		Iterations:  config.Argon2Iterations,
+		Parallelism: config.Argon2Parallelism,
+		SaltLength:  password.DefaultArgon2Params.SaltLength,
+		KeyLength:   password.DefaultArgon2Params.KeyLength,
+	}, config.BcryptCost)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+
+	stepUp, err := stepup.NewPolicy(config)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create step-up policy: %w", err)
+	}
+
+	kycDocuments, err := kyc.NewDocuments(context.Background(), config)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create KYC document store: %w", err)
+	}
+
+	clientIP, err := clientip.NewResolver(config.TrustedProxies)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse trusted proxies: %w", err)
+	}
+
+	phoneCodes, err := phone.NewVerifier(store, config.PhoneCodeKey, config.PhoneCodeDuration)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create phone code verifier: %w", err)
+	}
+
+	// SSO login stays off until an issuer is configured
+	var oidcLogin *oidc.Login
+	if config.OidcIssuerUrl != "" {
+		oidcLogin, err = oidc.NewLogin(config, store, hasher)
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot create oidc login: %w", err)
+		}
+	}
+
 	server := &Server{
 		Store:           store,
 		TokenMaker:      tokenMaker,
+		SessionChecker:  session.NewCachedChecker(store, config.SessionCacheTTL),
+		ApiKeyVerifier:  apikey.NewStoreVerifier(store),
+		PasswordHasher:  hasher,
+		PasswordPolicy:  validations.NewPasswordPolicy(config),
+		MFA:             mfa.NewAuthenticator(store, cipher, phoneCodes, config.TotpIssuer, config.MfaChallengeDuration),
+		PhoneCodes:      phoneCodes,
+		OIDC:            oidcLogin,
+		StepUpPolicy:    stepUp,
+		KycDocuments:    kycDocuments,
+		Limiter:         limiter,
+		ClientIP:        clientIP,
 		Config:          config,
 		TaskDistributor: taskDistributor,
 	}
@@ -49,9 +138,11 @@ func NewTestServer(t *testing.T, store db.Store, cf *pkg.Config, taskDistributor
 	tokenMaker, err := paseto.NewPasetoMaker(cf.SymetricKey)
 	require.NoError(t, err)
 
-	server, err := NewServer(store, cf, tokenMaker, taskDistributor)
+	server, err := NewServer(store, cf, tokenMaker, taskDistributor, throttle.NewMemoryLimiter(throttle.NewPolicies(cf)))
 	require.NoError(t, err)
 
+	server.SessionChecker = session.AllowAllChecker{}
+	server.KycDocuments = kyc.NewDocumentsWithStore(blob.NewMemoryStore(), cf.KycDocumentMaxSize)
 	return server
 }
 
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAccountNumber(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		accountNumber, err := GenerateAccountNumber()
+		require.NoError(t, err)
+		require.Len(t, accountNumber, AccountNumberLength)
+		require.NoError(t, ValidateAccountNumber(accountNumber))
+	}
+}
+
+func TestValidateAccountNumber(t *testing.T) {
+	accountNumber := RandomAccountNumber()
+	require.NoError(t, ValidateAccountNumber(accountNumber))
+
+	require.ErrorIs(t, ValidateAccountNumber(accountNumber[1:]), ErrAccountNumberLength)
+	require.ErrorIs(t, ValidateAccountNumber("12345678901a"), ErrAccountNumberFormat)
+
+	// Every single digit typo must be caught
+	for i := range accountNumber {
+		typo := []byte(accountNumber)
+		typo[i] = '0' + (typo[i]-'0'+1)%10
+		require.ErrorIs(t, ValidateAccountNumber(string(typo)), ErrAccountNumberChecksum)
+	}
+
+	// Every swap of two different adjacent digits must be caught
+	for i := 0; i < len(accountNumber)-1; i++ {
+		if accountNumber[i] == accountNumber[i+1] {
+			continue
+		}
+		swapped := []byte(accountNumber)
+		swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+		require.ErrorIs(t, ValidateAccountNumber(string(swapped)), ErrAccountNumberChecksum)
+	}
+}
+
+func TestNormalizeAccountNumber(t *testing.T) {
+	require.Equal(t, "123456789012", NormalizeAccountNumber(" 1234-5678 9012 "))
+}
@@ -1,5 +1,10 @@
 package util
 
+import (
+	"sort"
+	"sync"
+)
+
 // Enum values for Currency
 const (
 	USD = "USD"
@@ -8,11 +13,81 @@ const (
 	VND = "VND"
 )
 
+// Currency describes an ISO 4217 currency, Exponent is the number of minor unit digits
+type Currency struct {
+	Code     string
+	Exponent int32
+	Symbol   string
+	Enabled  bool
+}
+
+// DefaultCurrencies are used until the registry is loaded from the database
+var DefaultCurrencies = []Currency{
+	{Code: USD, Exponent: 2, Symbol: "$", Enabled: true},
+	{Code: EUR, Exponent: 2, Symbol: "€", Enabled: true},
+	{Code: CAD, Exponent: 2, Symbol: "CA$", Enabled: true},
+	{Code: VND, Exponent: 0, Symbol: "₫", Enabled: true},
+}
+
+// CurrencyRegistry holds the currencies known to the bank, it is safe for concurrent use
+type CurrencyRegistry struct {
+	mu         sync.RWMutex
+	currencies map[string]Currency
+}
+
+// Currencies is the registry used by validators, Money and the API responses
+var Currencies = NewCurrencyRegistry(DefaultCurrencies...)
+
+func NewCurrencyRegistry(currencies ...Currency) *CurrencyRegistry {
+	registry := &CurrencyRegistry{}
+	registry.Replace(currencies)
+	return registry
+}
+
+// Replace swaps the content of the registry, e.g. after loading it from the database
+func (r *CurrencyRegistry) Replace(currencies []Currency) {
+	m := make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		m[currency.Code] = currency
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.currencies = m
+}
+
+// Lookup returns the currency with the given code, enabled or not
+func (r *CurrencyRegistry) Lookup(code string) (Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	currency, ok := r.currencies[code]
+	return currency, ok
+}
+
+// IsSupported checks if the currency is known and enabled
+func (r *CurrencyRegistry) IsSupported(code string) bool {
+	currency, ok := r.Lookup(code)
+	return ok && currency.Enabled
+}
+
+// Enabled returns the codes of all enabled currencies in alphabetical order
+func (r *CurrencyRegistry) Enabled() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codes := make([]string, 0, len(r.currencies))
+	for code, currency := range r.currencies {
+		if currency.Enabled {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
 // IsSupportedCurrency checks if the currency is supported
 func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, CAD, VND:
-		return true
-	}
-	return false
+	return Currencies.IsSupported(currency)
 }
@@ -0,0 +1,205 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrMoneyOverflow    = errors.New("amount is out of range")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrAmountPrecision  = errors.New("amount has more decimal places than the currency allows")
+)
+
+// Money is an amount in the minor units of its currency, e.g. cents for USD
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// NewMoney creates a Money value for a currency known to the registry
+func NewMoney(amount int64, currency string) (Money, error) {
+	if _, ok := Currencies.Lookup(currency); !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// ParseMoney parses a decimal string such as "1,234.56" into minor units
+func ParseMoney(value, currency string) (Money, error) {
+	c, ok := Currencies.Lookup(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, fraction, hasFraction := strings.Cut(value, ".")
+	if whole == "" || (hasFraction && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+
+	if len(fraction) > int(c.Exponent) {
+		return Money{}, fmt.Errorf("%w: %s allows %d", ErrAmountPrecision, c.Code, c.Exponent)
+	}
+
+	fraction += strings.Repeat("0", int(c.Exponent)-len(fraction))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: c.Code}, nil
+}
+
+// ParseMoneyList parses amounts in major units per currency such as "USD:1000,VND:25000000"
+// into minor units keyed by currency code
+func ParseMoneyList(value string) (map[string]int64, error) {
+	amounts := make(map[string]int64)
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		currency, amount, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q, want CURRENCY:AMOUNT", ErrInvalidAmount, item)
+		}
+
+		money, err := ParseMoney(amount, strings.ToUpper(strings.TrimSpace(currency)))
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", item, err)
+		}
+
+		amounts[money.Currency] = money.Amount
+	}
+
+	return amounts, nil
+}
+
+// Add returns m + other, both must share the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other, both must share the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// String formats the amount with the currency symbol and digit grouping, e.g. "-$1,234.56"
+func (m Money) String() string {
+	c, ok := Currencies.Lookup(m.Currency)
+	if !ok {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+
+	// go through uint64 so that math.MinInt64 can be negated
+	abs := uint64(m.Amount)
+	if m.Amount < 0 {
+		abs = -abs
+	}
+
+	digits := strconv.FormatUint(abs, 10)
+	exp := int(c.Exponent)
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+
+	whole, fraction := digits[:len(digits)-exp], digits[len(digits)-exp:]
+
+	formatted := groupThousands(whole)
+	if exp > 0 {
+		formatted += "." + fraction
+	}
+
+	return sign + c.Symbol + formatted
+}
+
+// MarshalJSON adds the formatted amount so that clients do not need the currency registry
+func (m Money) MarshalJSON() ([]byte, error) {
+	type money Money
+
+	return json.Marshal(struct {
+		money
+		Formatted string `json:"formatted"`
+	}{money(m), m.String()})
+}
+
+// FormatAmount formats an amount in minor units of the given currency
+func FormatAmount(amount int64, currency string) string {
+	return Money{Amount: amount, Currency: currency}.String()
+}
+
+func groupThousands(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var sb strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		sb.WriteString(digits[:head])
+	}
+
+	for i := head; i < len(digits); i += 3 {
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(digits[i : i+3])
+	}
+
+	return sb.String()
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
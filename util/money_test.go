@@ -0,0 +1,120 @@
+package util
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyRegistry(t *testing.T) {
+	registry := NewCurrencyRegistry(
+		Currency{Code: USD, Exponent: 2, Symbol: "$", Enabled: true},
+		Currency{Code: "JPY", Exponent: 0, Symbol: "¥", Enabled: false},
+	)
+
+	require.True(t, registry.IsSupported(USD))
+	require.False(t, registry.IsSupported("JPY"))
+	require.False(t, registry.IsSupported(EUR))
+	require.Equal(t, []string{USD}, registry.Enabled())
+
+	currency, ok := registry.Lookup("JPY")
+	require.True(t, ok)
+	require.Equal(t, int32(0), currency.Exponent)
+
+	registry.Replace([]Currency{{Code: EUR, Exponent: 2, Symbol: "€", Enabled: true}})
+	require.False(t, registry.IsSupported(USD))
+	require.True(t, registry.IsSupported(EUR))
+}
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		value    string
+		currency string
+		amount   int64
+		err      error
+	}{
+		{"12.34", USD, 1234, nil},
+		{"1,234.5", EUR, 123450, nil},
+		{"-7", CAD, -700, nil},
+		{"15000", VND, 15000, nil},
+		{"0.001", USD, 0, ErrAmountPrecision},
+		{"1.5", VND, 0, ErrAmountPrecision},
+		{"abc", USD, 0, ErrInvalidAmount},
+		{"1.", USD, 0, ErrInvalidAmount},
+		{"", USD, 0, ErrInvalidAmount},
+		{"99999999999999999999", VND, 0, ErrMoneyOverflow},
+		{"1", "XYZ", 0, ErrUnknownCurrency},
+	}
+
+	for _, tc := range testCases {
+		money, err := ParseMoney(tc.value, tc.currency)
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, tc.value)
+			continue
+		}
+		require.NoError(t, err, tc.value)
+		require.Equal(t, tc.amount, money.Amount)
+		require.Equal(t, tc.currency, money.Currency)
+	}
+}
+
+func TestParseMoneyList(t *testing.T) {
+	amounts, err := ParseMoneyList("USD:1000, vnd:25000000,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{USD: 100_000, VND: 25_000_000}, amounts)
+
+	amounts, err = ParseMoneyList("")
+	require.NoError(t, err)
+	require.Empty(t, amounts)
+
+	for _, value := range []string{"USD", "USD:ten", "XYZ:1000", "USD:10.001"} {
+		_, err := ParseMoneyList(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := Money{Amount: 1000, Currency: USD}
+	b := Money{Amount: 250, Currency: USD}
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	require.Equal(t, int64(1250), sum.Amount)
+
+	diff, err := b.Sub(a)
+	require.NoError(t, err)
+	require.Equal(t, int64(-750), diff.Amount)
+	require.True(t, diff.IsNegative())
+
+	_, err = a.Add(Money{Amount: 1, Currency: EUR})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = Money{Amount: math.MaxInt64, Currency: USD}.Add(Money{Amount: 1, Currency: USD})
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = Money{Amount: 0, Currency: USD}.Sub(Money{Amount: math.MinInt64, Currency: USD})
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+}
+
+func TestMoneyString(t *testing.T) {
+	require.Equal(t, "$0.05", Money{Amount: 5, Currency: USD}.String())
+	require.Equal(t, "$1,234.56", Money{Amount: 123456, Currency: USD}.String())
+	require.Equal(t, "-€10.00", Money{Amount: -1000, Currency: EUR}.String())
+	require.Equal(t, "₫1,500,000", Money{Amount: 1500000, Currency: VND}.String())
+	require.Equal(t, "-$92,233,720,368,547,758.08", Money{Amount: math.MinInt64, Currency: USD}.String())
+	require.Equal(t, "42 XYZ", Money{Amount: 42, Currency: "XYZ"}.String())
+}
+
+func TestMoneyJSON(t *testing.T) {
+	money := Money{Amount: 123456, Currency: USD}
+
+	data, err := json.Marshal(money)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":123456,"currency":"USD","formatted":"$1,234.56"}`, string(data))
+
+	var decoded Money
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, money, decoded)
+}
@@ -1,21 +1,212 @@
 package password
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword returns the bcrypt hash of the password
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", fmt.Errorf("error hashing password: %w", err)
+// Algorithms a PasswordHasher can hash new passwords with
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+// argon2idPrefix starts every argon2id hash in the PHC string format
+const argon2idPrefix = "$argon2id$"
+
+var (
+	// ErrMismatchedHashAndPassword is returned when the password does not match the hash, whatever its algorithm
+	ErrMismatchedHashAndPassword = bcrypt.ErrMismatchedHashAndPassword
+	ErrUnknownHashFormat         = errors.New("unknown password hash format")
+	ErrUnknownAlgorithm          = errors.New("unknown password hash algorithm")
+)
+
+// Argon2Params are the cost parameters of argon2id, Memory is in KiB
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follow the second recommended option of RFC 9106
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// PasswordHasher hashes new passwords with one algorithm and checks hashes of every supported one
+type PasswordHasher interface {
+	// Hash returns the encoded hash of the password
+	Hash(password string) (string, error)
+	// Check returns ErrMismatchedHashAndPassword if the password does not match the hash
+	Check(password string, hashedPassword string) error
+	// NeedsRehash reports whether the hash was made with another algorithm or weaker parameters
+	NeedsRehash(hashedPassword string) bool
+}
+
+// Hasher is a PasswordHasher recognising the algorithm of a hash by its encoded prefix
+type Hasher struct {
+	algorithm  string
+	argon2     Argon2Params
+	bcryptCost int
+}
+
+// NewHasher creates a Hasher hashing new passwords with algorithm
+func NewHasher(algorithm string, argon2Params Argon2Params, bcryptCost int) (*Hasher, error) {
+	switch algorithm {
+	case Argon2id:
+		if argon2Params.Memory == 0 || argon2Params.Iterations == 0 || argon2Params.Parallelism == 0 ||
+			argon2Params.SaltLength < 8 || argon2Params.KeyLength < 16 {
+			return nil, fmt.Errorf("invalid argon2id parameters: %+v", argon2Params)
+		}
+	case Bcrypt:
+		if bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid bcrypt cost: %d", bcryptCost)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, algorithm)
 	}
-	return string(hashedPassword), nil
+
+	return &Hasher{algorithm: algorithm, argon2: argon2Params, bcryptCost: bcryptCost}, nil
+}
+
+// defaultHasher backs HashPassword and CheckPassword
+var defaultHasher = &Hasher{algorithm: Argon2id, argon2: DefaultArgon2Params, bcryptCost: bcrypt.DefaultCost}
+
+// HashPassword returns the argon2id hash of the password with the default parameters
+func HashPassword(password string) (string, error) {
+	return defaultHasher.Hash(password)
 }
 
 // CheckPassword checks if the provided password is correct or not
 func CheckPassword(password, hashedPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	return defaultHasher.Check(password, hashedPassword)
+}
+
+func (hasher *Hasher) Hash(password string) (string, error) {
+	if hasher.algorithm == Bcrypt {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), hasher.bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("error hashing password: %w", err)
+		}
+		return string(hashedPassword), nil
+	}
+
+	params := hasher.argon2
+	salt := make([]byte, params.SaltLength)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error hashing password: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (hasher *Hasher) Check(password string, hashedPassword string) error {
+	if isBcrypt(hashedPassword) {
+		return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	}
+
+	params, salt, key, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+
+	return nil
+}
+
+func (hasher *Hasher) NeedsRehash(hashedPassword string) bool {
+	if hasher.algorithm == Bcrypt {
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		return err != nil || cost < hasher.bcryptCost
+	}
+
+	params, _, _, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return true
+	}
+
+	want := hasher.argon2
+	return params.Memory < want.Memory || params.Iterations < want.Iterations ||
+		params.Parallelism != want.Parallelism || params.SaltLength < want.SaltLength || params.KeyLength < want.KeyLength
+}
+
+// Reused reports whether the password matches one of the hashes, hashes of an unknown format never match
+func Reused(hasher PasswordHasher, password string, hashedPasswords []string) bool {
+	for _, hashedPassword := range hashedPasswords {
+		if hasher.Check(password, hashedPassword) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isBcrypt reports whether the hash has one of the bcrypt version prefixes
+func isBcrypt(hashedPassword string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(hashedPassword, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeArgon2id parses $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+func decodeArgon2id(hashedPassword string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	if !strings.HasPrefix(hashedPassword, argon2idPrefix) {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
 }
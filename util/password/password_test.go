@@ -1,6 +1,8 @@
 package password
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/ChokeGuy/simple-bank/util"
@@ -29,3 +31,127 @@ func TestPassword(t *testing.T) {
 
 	require.NotEqual(t, hashedPassword1, hashedPassword2)
 }
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher, err := NewHasher(Argon2id, DefaultArgon2Params, bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	password := util.RandomPassword()
+
+	hashedPassword, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=65536,t=3,p=4$"))
+
+	require.NoError(t, hasher.Check(password, hashedPassword))
+	require.ErrorIs(t, hasher.Check(util.RandomPassword(), hashedPassword), ErrMismatchedHashAndPassword)
+	require.False(t, hasher.NeedsRehash(hashedPassword))
+}
+
+func TestCheckBcryptHash(t *testing.T) {
+	hasher, err := NewHasher(Argon2id, DefaultArgon2Params, bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	password := util.RandomPassword()
+
+	// Hashes stored before argon2id still verify and are upgraded on the next login
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	require.NoError(t, hasher.Check(password, string(hashedPassword)))
+	require.ErrorIs(t, hasher.Check(util.RandomPassword(), string(hashedPassword)), ErrMismatchedHashAndPassword)
+	require.True(t, hasher.NeedsRehash(string(hashedPassword)))
+}
+
+func TestNeedsRehash(t *testing.T) {
+	weak := Argon2Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+	weakHasher, err := NewHasher(Argon2id, weak, bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	hashedPassword, err := weakHasher.Hash(util.RandomPassword())
+	require.NoError(t, err)
+
+	hasher, err := NewHasher(Argon2id, DefaultArgon2Params, bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	require.False(t, weakHasher.NeedsRehash(hashedPassword))
+	require.True(t, hasher.NeedsRehash(hashedPassword))
+
+	// Going back to bcrypt rehashes argon2id hashes and bcrypt hashes of a lower cost
+	bcryptHasher, err := NewHasher(Bcrypt, Argon2Params{}, bcrypt.MinCost+1)
+	require.NoError(t, err)
+
+	require.True(t, bcryptHasher.NeedsRehash(hashedPassword))
+
+	lowCost, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.True(t, bcryptHasher.NeedsRehash(string(lowCost)))
+
+	sameCost, err := bcryptHasher.Hash("secret")
+	require.NoError(t, err)
+	require.False(t, bcryptHasher.NeedsRehash(sameCost))
+	require.NoError(t, hasher.Check("secret", sameCost))
+}
+
+func TestCheckUnknownHash(t *testing.T) {
+	for _, hashedPassword := range []string{
+		"",
+		"plain-text",
+		"$argon2i$v=19$m=65536,t=3,p=4$c2FsdA$a2V5",
+		"$argon2id$v=16$m=65536,t=3,p=4$c2FsdA$a2V5",
+		"$argon2id$v=19$m=65536$c2FsdA$a2V5",
+		"$argon2id$v=19$m=65536,t=3,p=4$!!$a2V5",
+	} {
+		require.ErrorIs(t, CheckPassword("secret", hashedPassword), ErrUnknownHashFormat, hashedPassword)
+	}
+}
+
+func TestNewHasher(t *testing.T) {
+	_, err := NewHasher("scrypt", DefaultArgon2Params, bcrypt.DefaultCost)
+	require.ErrorIs(t, err, ErrUnknownAlgorithm)
+
+	_, err = NewHasher(Argon2id, Argon2Params{Memory: 1024}, bcrypt.DefaultCost)
+	require.Error(t, err)
+
+	_, err = NewHasher(Bcrypt, Argon2Params{}, bcrypt.MaxCost+1)
+	require.Error(t, err)
+}
+
+// BenchmarkArgon2id helps pick ARGON2_MEMORY and ARGON2_ITERATIONS, a login should stay well under a second:
+//
+//	go test -run '^$' -bench . -benchmem ./util/password
+func BenchmarkArgon2id(b *testing.B) {
+	for _, memory := range []uint32{19 * 1024, 46 * 1024, 64 * 1024} {
+		for _, iterations := range []uint32{1, 2, 3} {
+			params := DefaultArgon2Params
+			params.Memory = memory
+			params.Iterations = iterations
+
+			hasher, err := NewHasher(Argon2id, params, bcrypt.DefaultCost)
+			require.NoError(b, err)
+
+			b.Run(fmt.Sprintf("m=%dMiB,t=%d,p=%d", memory/1024, iterations, params.Parallelism), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := hasher.Hash("correct horse battery staple"); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkBcrypt(b *testing.B) {
+	for _, cost := range []int{bcrypt.DefaultCost, 11, 12} {
+		hasher, err := NewHasher(Bcrypt, Argon2Params{}, cost)
+		require.NoError(b, err)
+
+		b.Run(fmt.Sprintf("cost=%d", cost), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := hasher.Hash("correct horse battery staple"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -41,12 +41,12 @@ func RandomPassword() string {
 
 	rand.Seed(time.Now().UnixNano())
 
-	password := make([]byte, 6)
+	password := make([]byte, 12)
 	password[0] = upperLetters[rand.Intn(len(upperLetters))]
 	password[1] = lowerLetters[rand.Intn(len(lowerLetters))]
 	password[2] = numbers[rand.Intn(len(numbers))]
 	password[3] = specialChars[rand.Intn(len(specialChars))]
-	for i := 4; i < 6; i++ {
+	for i := 4; i < len(password); i++ {
 		password[i] = allChars[rand.Intn(len(allChars))]
 	}
 
@@ -68,6 +68,11 @@ func RandomCurrency() string {
 	return currencies[rand.Intn(n)]
 }
 
+func RandomAccountNumber() string {
+	body := fmt.Sprintf("%0*d", AccountNumberBodyLength, rand.Int63n(10_000_000_000))
+	return body + accountNumberCheckDigits(body)
+}
+
 func RandomOwner() string {
 	return RandomString(6)
 }
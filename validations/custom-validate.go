@@ -4,15 +4,14 @@ import (
 	"fmt"
 	"net/mail"
 	"regexp"
+
+	"github.com/ChokeGuy/simple-bank/util"
 )
 
 var (
 	isValidUsername = regexp.MustCompile(`^[a-zA-Z0-9_]+$`).MatchString
 	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
-	hasUpper        = regexp.MustCompile(`[A-Z]`).MatchString
-	hasLower        = regexp.MustCompile(`[a-z]`).MatchString
-	hasNumber       = regexp.MustCompile(`[0-9]`).MatchString
-	hasSpecial      = regexp.MustCompile(`[!@#~$%^&*()_+|<>?:{}]`).MatchString
+	isValidTotpCode = regexp.MustCompile(`^[0-9]{6}$`).MatchString
 )
 
 func ValidateString(value string, minLength, maxLength int) error {
@@ -34,30 +33,6 @@ func ValidateUsername(username string) error {
 	return nil
 }
 
-func ValidatePassword(password string) error {
-	if err := ValidateString(password, 6, 100); err != nil {
-		return fmt.Errorf("password length must be at least 6 characters")
-	}
-
-	if !hasUpper(password) {
-		return fmt.Errorf("password must contain at least one uppercase letter")
-	}
-
-	if !hasLower(password) {
-		return fmt.Errorf("password must contain at least one lowercase letter")
-	}
-
-	if !hasNumber(password) {
-		return fmt.Errorf("password must contain at least one number")
-	}
-
-	if !hasSpecial(password) {
-		return fmt.Errorf("password must contain at least one special character (!@#~$%%^&*()_+|<>?:{})")
-	}
-
-	return nil
-}
-
 func ValidateEmail(email string) error {
 	if err := ValidateString(email, 3, 200); err != nil {
 		return err
@@ -93,3 +68,45 @@ func ValidateSecretCode(secretCode string) error {
 	}
 	return nil
 }
+
+func ValidateDeviceLabel(deviceLabel string) error {
+	return ValidateString(deviceLabel, 0, 64)
+}
+
+func ValidateTotpCode(code string) error {
+	if !isValidTotpCode(code) {
+		return fmt.Errorf("code must be 6 digits")
+	}
+	return nil
+}
+
+// ValidatePhoneCode accepts the digits of a code sent by SMS
+func ValidatePhoneCode(code string) error {
+	return ValidateTotpCode(code)
+}
+
+// ValidateMfaCode accepts a TOTP code or a recovery code
+func ValidateMfaCode(code string) error {
+	return ValidateString(code, 6, 32)
+}
+
+func ValidateRole(role string) error {
+	if !util.IsSupportedRole(role) {
+		return fmt.Errorf("unsupported role")
+	}
+	return nil
+}
+
+func ValidatePage(page int32) error {
+	if page < 1 {
+		return fmt.Errorf("page must be at least 1")
+	}
+	return nil
+}
+
+func ValidatePageSize(size int32) error {
+	if size < 5 || size > 50 {
+		return fmt.Errorf("size must be between 5 and 50")
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package validations
+
+import (
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidMoney accepts a positive amount of a supported currency
+var ValidMoney validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if money, ok := fieldLevel.Field().Interface().(util.Money); ok {
+		return money.Amount > 0 && util.IsSupportedCurrency(money.Currency)
+	}
+
+	return false
+}
@@ -1,20 +1,178 @@
 package validations
 
 import (
+	"bufio"
+	_ "embed"
+	"fmt"
 	"regexp"
+	"strings"
 
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
 	"github.com/go-playground/validator/v10"
 )
 
-// Password validation function
-var ValidPassword validator.Func = func(fieldLevel validator.FieldLevel) bool {
-	password := fieldLevel.Field().String()
-	var (
-		hasMinLen  = len(password) >= 6
-		hasUpper   = regexp.MustCompile(`[A-Z]`).MatchString(password)
-		hasLower   = regexp.MustCompile(`[a-z]`).MatchString(password)
-		hasNumber  = regexp.MustCompile(`[0-9]`).MatchString(password)
-		hasSpecial = regexp.MustCompile(`[!@#~$%^&*()_+|<>?:{}]`).MatchString(password)
-	)
-	return hasMinLen && hasUpper && hasLower && hasNumber && hasSpecial
+// MaxPasswordLength keeps hashing of a request bounded whatever the policy
+const MaxPasswordLength = 100
+
+// commonPasswords are breached passwords, most common first
+//
+//go:embed common-passwords.txt
+var commonPasswords string
+
+// specialChars are the characters counted as the special character class
+const specialChars = "!@#~$%^&*()_+|<>?:{}"
+
+var (
+	hasUpper   = regexp.MustCompile(`[A-Z]`).MatchString
+	hasLower   = regexp.MustCompile(`[a-z]`).MatchString
+	hasNumber  = regexp.MustCompile(`[0-9]`).MatchString
+	hasSpecial = regexp.MustCompile(`[` + regexp.QuoteMeta(specialChars) + `]`).MatchString
+)
+
+// leetReplacer undoes the usual letter substitutions so P@ssw0rd is caught as password
+var leetReplacer = strings.NewReplacer("@", "a", "4", "a", "3", "e", "1", "i", "!", "i", "0", "o", "$", "s", "5", "s", "7", "t")
+
+// PasswordPolicy is the one set of rules new passwords are checked against, by the gin
+// binding and the gRPC validators alike. Logins are never checked so a stricter policy
+// does not lock out users whose password predates it.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters
+	MinLength int
+	// MinClasses is how many of uppercase, lowercase, number and special characters are required
+	MinClasses int
+	// MaxRepeated is the longest run of one character, 0 allows any
+	MaxRepeated int
+	// HistorySize is how many of the last passwords, the current one included, can not be reused
+	HistorySize int
+	blocklist   map[string]struct{}
+}
+
+// NewPasswordPolicy creates the policy of the config with the top PASSWORD_BLOCKLIST_SIZE common passwords
+func NewPasswordPolicy(config *pkg.Config) *PasswordPolicy {
+	policy := &PasswordPolicy{
+		MinLength:   config.PasswordMinLength,
+		MinClasses:  config.PasswordMinClasses,
+		MaxRepeated: config.PasswordMaxRepeated,
+		HistorySize: config.PasswordHistorySize,
+		blocklist:   make(map[string]struct{}),
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(commonPasswords))
+	for len(policy.blocklist) < config.PasswordBlocklistSize && scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			policy.blocklist[line] = struct{}{}
+		}
+	}
+
+	return policy
+}
+
+// Validate checks the rules that need nothing but the password
+func (policy *PasswordPolicy) Validate(password string) error {
+	length := len([]rune(password))
+
+	if length < policy.MinLength || len(password) > MaxPasswordLength {
+		return fmt.Errorf("password length must be between %d and %d characters", policy.MinLength, MaxPasswordLength)
+	}
+
+	var missing []string
+	for _, class := range []struct {
+		name    string
+		matches func(string) bool
+	}{
+		{"an uppercase letter", hasUpper},
+		{"a lowercase letter", hasLower},
+		{"a number", hasNumber},
+		{"a special character (" + specialChars + ")", hasSpecial},
+	} {
+		if !class.matches(password) {
+			missing = append(missing, class.name)
+		}
+	}
+
+	if present := 4 - len(missing); present < policy.MinClasses {
+		if policy.MinClasses >= 4 {
+			return fmt.Errorf("password must contain at least %s", missing[0])
+		}
+		return fmt.Errorf("password must contain %d of: uppercase letters, lowercase letters, numbers, special characters", policy.MinClasses)
+	}
+
+	if policy.MaxRepeated > 0 && longestRun(password) > policy.MaxRepeated {
+		return fmt.Errorf("password must not repeat a character more than %d times in a row", policy.MaxRepeated)
+	}
+
+	if policy.isCommon(password) {
+		return fmt.Errorf("password is too common, choose a less predictable one")
+	}
+
+	return nil
+}
+
+// ValidateFor also rejects a password containing the username or the email of its user
+func (policy *PasswordPolicy) ValidateFor(password string, username string, email string) error {
+	if err := policy.Validate(password); err != nil {
+		return err
+	}
+
+	lower := strings.ToLower(password)
+	localPart, _, _ := strings.Cut(strings.ToLower(email), "@")
+
+	for _, identifier := range []string{strings.ToLower(username), localPart} {
+		// Very short names would reject too many unrelated passwords
+		if len(identifier) >= 3 && strings.Contains(lower, identifier) {
+			return fmt.Errorf("password must not contain your username or email")
+		}
+	}
+
+	return nil
+}
+
+// HistoryKeep is how many replaced hashes are kept, the current password is the last of HistorySize
+func (policy *PasswordPolicy) HistoryKeep() int32 {
+	return int32(max(policy.HistorySize-1, 0))
+}
+
+// BindingFunc returns the gin binding of the policy, registered as the "password" tag
+func (policy *PasswordPolicy) BindingFunc() validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		return policy.Validate(fieldLevel.Field().String()) == nil
+	}
+}
+
+// isCommon reports whether the password, or its core without the digits and symbols
+// usually added around it, is one of the blocked common passwords
+func (policy *PasswordPolicy) isCommon(password string) bool {
+	if len(policy.blocklist) == 0 {
+		return false
+	}
+
+	lower := strings.ToLower(password)
+	core := leetReplacer.Replace(strings.Trim(lower, "0123456789"+specialChars))
+
+	for _, candidate := range []string{lower, leetReplacer.Replace(lower), core} {
+		if _, ok := policy.blocklist[candidate]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// longestRun returns the length of the longest run of one repeated character
+func longestRun(password string) int {
+	longest, run := 0, 0
+	var previous rune
+
+	for i, r := range password {
+		if i > 0 && r == previous {
+			run++
+		} else {
+			run = 1
+		}
+
+		previous = r
+		longest = max(longest, run)
+	}
+
+	return longest
 }
@@ -0,0 +1,81 @@
+package validations
+
+import (
+	"testing"
+
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPolicy() *PasswordPolicy {
+	return NewPasswordPolicy(&pkg.Config{
+		PasswordMinLength:     8,
+		PasswordMinClasses:    4,
+		PasswordMaxRepeated:   3,
+		PasswordBlocklistSize: 1000,
+		PasswordHistorySize:   5,
+	})
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := newTestPolicy()
+
+	for password, ok := range map[string]bool{
+		"Xy7!kq9#Lm":   true,
+		"Xy7!kq":       false, // too short
+		"xy7!kq9#lm":   false, // no uppercase
+		"XY7!KQ9#LM":   false, // no lowercase
+		"Xyz!kqw#Lm":   false, // no number
+		"Xy7kq9Lmzz":   false, // no special character
+		"Xy7!kqqqq9#L": false, // four times the same character
+		"Xy7!kqqq9#Lm": true,
+		"Password1!":   false, // common password with the usual suffix
+		"P@ssw0rd2024": false, // common password with letter substitutions
+		"!Qwerty123":   false,
+	} {
+		err := policy.Validate(password)
+		if ok {
+			require.NoError(t, err, password)
+		} else {
+			require.Error(t, err, password)
+		}
+	}
+}
+
+func TestPasswordPolicyMinClasses(t *testing.T) {
+	policy := newTestPolicy()
+	policy.MinClasses = 3
+
+	require.NoError(t, policy.Validate("Xy7kq9Lmzw"))
+	require.Error(t, policy.Validate("xy7kq9lmzw"))
+}
+
+func TestPasswordPolicyBlocklistSize(t *testing.T) {
+	policy := NewPasswordPolicy(&pkg.Config{PasswordMinLength: 8, PasswordMinClasses: 4, PasswordBlocklistSize: 2})
+
+	// Only the first two entries of the bundled list are blocked
+	require.Error(t, policy.Validate("Password1!"))
+	require.NoError(t, policy.Validate("Sunshine1!"))
+
+	policy = NewPasswordPolicy(&pkg.Config{PasswordMinLength: 8, PasswordMinClasses: 4})
+	require.NoError(t, policy.Validate("Password1!"))
+}
+
+func TestPasswordPolicyValidateFor(t *testing.T) {
+	policy := newTestPolicy()
+
+	require.NoError(t, policy.ValidateFor("Xy7!kq9#Lm", "johndoe", "jane@example.com"))
+	require.Error(t, policy.ValidateFor("Xy7!JohnDoe", "johndoe", "jane@example.com"))
+	require.Error(t, policy.ValidateFor("Xy7!Jane.Smith", "johndoe", "jane.smith@example.com"))
+
+	// Identifiers shorter than three characters are not checked
+	require.NoError(t, policy.ValidateFor("Xy7!kq9#Lm", "xy", "kq@example.com"))
+}
+
+func TestPasswordPolicyHistoryKeep(t *testing.T) {
+	policy := newTestPolicy()
+	require.Equal(t, int32(4), policy.HistoryKeep())
+
+	policy.HistorySize = 0
+	require.Equal(t, int32(0), policy.HistoryKeep())
+}
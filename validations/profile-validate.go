@@ -0,0 +1,111 @@
+package validations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ChokeGuy/simple-bank/pkg/kyc"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	minAge = 18
+	maxAge = 120
+)
+
+// profileValidator checks the fields go-playground already knows, the gin binding uses the same tags
+var profileValidator = validator.New()
+
+// ValidateDateOfBirth parses a YYYY-MM-DD date of birth of someone old enough to hold an account
+func ValidateDateOfBirth(value string) (time.Time, error) {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("dateOfBirth must be a date formatted as YYYY-MM-DD")
+	}
+
+	now := time.Now().UTC()
+	if date.After(now.AddDate(-minAge, 0, 0)) {
+		return time.Time{}, fmt.Errorf("account holders must be at least %d years old", minAge)
+	}
+
+	if date.Before(now.AddDate(-maxAge, 0, 0)) {
+		return time.Time{}, fmt.Errorf("dateOfBirth is too far in the past")
+	}
+
+	return date, nil
+}
+
+// ValidatePhone only accepts E.164 numbers such as +84901234567
+func ValidatePhone(phone string) error {
+	if err := profileValidator.Var(phone, "e164"); err != nil {
+		return fmt.Errorf("phone must be an E.164 number such as +84901234567")
+	}
+	return nil
+}
+
+func ValidateAddress(address string) error {
+	return ValidateString(address, 5, 200)
+}
+
+// ValidateNationality only accepts ISO 3166-1 alpha-2 country codes such as VN
+func ValidateNationality(nationality string) error {
+	if err := profileValidator.Var(nationality, "iso3166_1_alpha2"); err != nil {
+		return fmt.Errorf("nationality must be an ISO 3166-1 alpha-2 country code")
+	}
+	return nil
+}
+
+func ValidateKycStatus(status string) error {
+	if !kyc.IsValidStatus(status) {
+		return fmt.Errorf("unsupported KYC status")
+	}
+	return nil
+}
+
+func ValidateKycDocumentKind(kind string) error {
+	if !kyc.IsValidDocumentKind(kind) {
+		return fmt.Errorf("kind must be one of passport, national_id, driving_license or proof_of_address")
+	}
+	return nil
+}
+
+// ValidateKycDocumentDecision only accepts the statuses a banker may give a document
+func ValidateKycDocumentDecision(status string) error {
+	if !kyc.IsDocumentDecision(status) {
+		return fmt.Errorf("status must be approved or rejected")
+	}
+	return nil
+}
+
+var ValidDateOfBirth validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if value, ok := fieldLevel.Field().Interface().(string); ok {
+		_, err := ValidateDateOfBirth(value)
+		return err == nil
+	}
+
+	return false
+}
+
+var ValidKycStatus validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if status, ok := fieldLevel.Field().Interface().(string); ok {
+		return kyc.IsValidStatus(status)
+	}
+
+	return false
+}
+
+var ValidKycDocumentKind validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if kind, ok := fieldLevel.Field().Interface().(string); ok {
+		return kyc.IsValidDocumentKind(kind)
+	}
+
+	return false
+}
+
+var ValidKycDocumentDecision validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if status, ok := fieldLevel.Field().Interface().(string); ok {
+		return kyc.IsDocumentDecision(status)
+	}
+
+	return false
+}
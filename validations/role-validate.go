@@ -0,0 +1,14 @@
+package validations
+
+import (
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/go-playground/validator/v10"
+)
+
+var ValidRole validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if role, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedRole(role)
+	}
+
+	return false
+}
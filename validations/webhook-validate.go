@@ -0,0 +1,42 @@
+package validations
+
+import (
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/ChokeGuy/simple-bank/pkg/webhook"
+	"github.com/go-playground/validator/v10"
+)
+
+var ValidWebhookEvent validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if eventType, ok := fieldLevel.Field().Interface().(string); ok {
+		return webhook.IsSupportedEventType(eventType)
+	}
+
+	return false
+}
+
+// ValidWebhookUrl only accepts absolute https URLs. A literal IP must be public, hostnames are
+// checked against the address they resolve to when a delivery is sent.
+var ValidWebhookUrl validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	rawUrl, ok := fieldLevel.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return false
+	}
+
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		return webhook.IsPublicAddr(addr)
+	}
+
+	return !strings.EqualFold(u.Hostname(), "localhost")
+}
@@ -2,14 +2,20 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
 )
 
+// TaskDistributor publishes tasks to the queue. Handlers must not call it directly,
+// tasks are written to the outbox and published by the OutboxRelay.
 type TaskDistributor interface {
-	DistributeTaskSendVerifyEmail(
+	DistributeTask(
 		ctx context.Context,
-		payload *PayloadSendVerifyEmail,
+		taskType string,
+		payload []byte,
 		opts ...asynq.Option,
 	) error
 }
@@ -25,3 +31,29 @@ func NewRedisTaskDistributor(redisOpt asynq.RedisClientOpt) TaskDistributor {
 		client: client,
 	}
 }
+
+func (distributor *RedisTaskDistributor) DistributeTask(
+	ctx context.Context,
+	taskType string,
+	payload []byte,
+	opts ...asynq.Option,
+) error {
+	task := asynq.NewTask(taskType, payload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+
+	if err != nil {
+		// the task was already published by a previous relay run that failed to commit
+		if errors.Is(err, asynq.ErrTaskIDConflict) || errors.Is(err, asynq.ErrDuplicateTask) {
+			return nil
+		}
+		return fmt.Errorf("fail to enqueue task: %v", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("enqueued task")
+
+	return nil
+}
@@ -8,7 +8,6 @@ import (
 	context "context"
 	reflect "reflect"
 
-	worker "github.com/ChokeGuy/simple-bank/worker"
 	gomock "github.com/golang/mock/gomock"
 	asynq "github.com/hibiken/asynq"
 )
@@ -36,21 +35,21 @@ func (m *MockTaskDistributor) EXPECT() *MockTaskDistributorMockRecorder {
 	return m.recorder
 }
 
-// DistributeTaskSendVerifyEmail mocks base method.
-func (m *MockTaskDistributor) DistributeTaskSendVerifyEmail(arg0 context.Context, arg1 *worker.PayloadSendVerifyEmail, arg2 ...asynq.Option) error {
+// DistributeTask mocks base method.
+func (m *MockTaskDistributor) DistributeTask(arg0 context.Context, arg1 string, arg2 []byte, arg3 ...asynq.Option) error {
 	m.ctrl.T.Helper()
-	varargs := []interface{}{arg0, arg1}
-	for _, a := range arg2 {
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "DistributeTaskSendVerifyEmail", varargs...)
+	ret := m.ctrl.Call(m, "DistributeTask", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DistributeTaskSendVerifyEmail indicates an expected call of DistributeTaskSendVerifyEmail.
-func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendVerifyEmail(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+// DistributeTask indicates an expected call of DistributeTask.
+func (mr *MockTaskDistributorMockRecorder) DistributeTask(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]interface{}{arg0, arg1}, arg2...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendVerifyEmail", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendVerifyEmail), varargs...)
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTask", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTask), varargs...)
 }
@@ -0,0 +1,182 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+const defaultMaxRetry = 25
+
+// OutboxOptions are turned into asynq options when the message is published
+type OutboxOptions struct {
+	Queue     string
+	MaxRetry  int32
+	ProcessIn time.Duration
+}
+
+// EnqueueTask writes a task into the outbox. Pass the Querier of the transaction that
+// changes the aggregate, so the task is only published if that transaction commits.
+func EnqueueTask(
+	ctx context.Context,
+	q db.Querier,
+	aggregateType string,
+	aggregateID string,
+	taskType string,
+	payload any,
+	opts OutboxOptions,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("fail to marshal payload: %v", err)
+	}
+
+	if opts.Queue == "" {
+		opts.Queue = QueueDefault
+	}
+
+	if opts.MaxRetry == 0 {
+		opts.MaxRetry = defaultMaxRetry
+	}
+
+	_, err = q.CreateOutboxMessage(ctx, db.CreateOutboxMessageParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		TaskType:      taskType,
+		Payload:       jsonPayload,
+		Queue:         opts.Queue,
+		MaxRetry:      opts.MaxRetry,
+		ProcessAt:     time.Now().Add(opts.ProcessIn),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to write outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// OutboxRelay publishes outbox messages to the task queue, delivery is at least once
+type OutboxRelay struct {
+	store       db.Store
+	distributor TaskDistributor
+	interval    time.Duration
+	batchSize   int32
+	maxAttempts int32
+	retention   time.Duration
+}
+
+func NewOutboxRelay(store db.Store, distributor TaskDistributor, cfg pkg.Config) *OutboxRelay {
+	return &OutboxRelay{
+		store:       store,
+		distributor: distributor,
+		interval:    cfg.OutboxRelayInterval,
+		batchSize:   cfg.OutboxBatchSize,
+		maxAttempts: cfg.OutboxMaxAttempts,
+		retention:   cfg.OutboxRetention,
+	}
+}
+
+// publish sends a single message, the outbox ID doubles as asynq task ID to drop duplicates
+func (relay *OutboxRelay) publish(ctx context.Context, message db.OutboxMessage) error {
+	return relay.distributor.DistributeTask(
+		ctx,
+		message.TaskType,
+		message.Payload,
+		asynq.TaskID(fmt.Sprintf("outbox:%d", message.ID)),
+		asynq.Queue(message.Queue),
+		asynq.MaxRetry(int(message.MaxRetry)),
+		asynq.ProcessAt(message.ProcessAt),
+	)
+}
+
+// RelayOnce publishes one batch of pending messages
+func (relay *OutboxRelay) RelayOnce(ctx context.Context) (db.RelayOutboxTxResult, error) {
+	return relay.store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+		BatchSize:   relay.batchSize,
+		MaxAttempts: relay.maxAttempts,
+		Publish: func(message db.OutboxMessage) error {
+			return relay.publish(ctx, message)
+		},
+	})
+}
+
+// Cleanup deletes messages that were published longer than the retention period ago
+func (relay *OutboxRelay) Cleanup(ctx context.Context) (int64, error) {
+	return relay.store.DeleteProcessedOutboxMessages(ctx, time.Now().Add(-relay.retention))
+}
+
+func (relay *OutboxRelay) run(ctx context.Context) {
+	relayTicker := time.NewTicker(relay.interval)
+	defer relayTicker.Stop()
+
+	cleanupTicker := time.NewTicker(time.Hour)
+	defer cleanupTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-relayTicker.C:
+			// drain the backlog before waiting for the next tick
+			for {
+				result, err := relay.RelayOnce(ctx)
+				if err != nil {
+					log.Error().Err(err).Msg("fail to relay outbox messages")
+					break
+				}
+
+				if result.Failed > 0 {
+					log.Warn().Int("failed", result.Failed).Msg("some outbox messages could not be published")
+				}
+
+				// Nothing retries these anymore, the task is lost until an operator replays it
+				for _, message := range result.DeadLettered {
+					log.Error().
+						Int64("id", message.ID).
+						Str("task_type", message.TaskType).
+						Str("aggregate", message.AggregateType+":"+message.AggregateID).
+						Int32("attempts", message.Attempts).
+						Str("last_error", message.LastError.String).
+						Msg("outbox message failed for good")
+				}
+
+				if result.Published < int(relay.batchSize) || result.Failed > 0 {
+					break
+				}
+			}
+		case <-cleanupTicker.C:
+			deleted, err := relay.Cleanup(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("fail to clean up outbox messages")
+				continue
+			}
+			log.Info().Int64("deleted", deleted).Msg("cleaned up outbox messages")
+		}
+	}
+}
+
+// RunOutboxRelay run the outbox relay until the context is cancelled
+func RunOutboxRelay(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	cfg pkg.Config,
+	store db.Store,
+	distributor TaskDistributor,
+) {
+	relay := NewOutboxRelay(store, distributor, cfg)
+
+	log.Info().Msg("start outbox relay")
+	waitGroup.Go(func() error {
+		relay.run(ctx)
+
+		log.Info().Msg("outbox relay shutdown complete")
+		return nil
+	})
+}
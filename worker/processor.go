@@ -2,10 +2,15 @@ package worker
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
 	"github.com/ChokeGuy/simple-bank/pkg/email"
 	"github.com/ChokeGuy/simple-bank/pkg/logger"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/sms"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/sync/errgroup"
@@ -20,15 +25,31 @@ type TaskProcessor interface {
 	start() error
 	shutdown()
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendResetPasswordEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendLoginAlertEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendPhoneCode(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDispatchWebhookEvent(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
-	server *asynq.Server
-	store  db.Store
-	mailer email.EmailSender
+	server        *asynq.Server
+	store         db.Store
+	mailer        email.EmailSender
+	smsSender     sms.Sender
+	phoneCodes    *phone.Verifier
+	config        pkg.Config
+	webhookClient *http.Client
 }
 
-func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer email.EmailSender) TaskProcessor {
+func NewRedisTaskProcessor(
+	redisOpt asynq.RedisClientOpt,
+	store db.Store,
+	mailer email.EmailSender,
+	smsSender sms.Sender,
+	phoneCodes *phone.Verifier,
+	cfg pkg.Config,
+) TaskProcessor {
 	server := asynq.NewServer(
 		redisOpt,
 		asynq.Config{
@@ -43,21 +64,40 @@ func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer
 					Bytes("task_payload", task.Payload()).
 					Msg("process task failed")
 			}),
-			Logger: logger.TaskLogger(),
+			RetryDelayFunc: retryDelay,
+			Logger:         logger.TaskLogger(),
 		},
 	)
 
 	return &RedisTaskProcessor{
-		server: server,
-		store:  store,
-		mailer: mailer,
+		server:        server,
+		store:         store,
+		mailer:        mailer,
+		smsSender:     smsSender,
+		phoneCodes:    phoneCodes,
+		config:        cfg,
+		webhookClient: NewWebhookClient(cfg.WebhookTimeout),
+	}
+}
+
+// retryDelay gives webhook deliveries their own backoff, receivers may be down for hours
+func retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if task.Type() == TaskDeliverWebhook {
+		return webhookRetryDelay(n)
 	}
+
+	return asynq.DefaultRetryDelayFunc(n, err, task)
 }
 
 func (processor *RedisTaskProcessor) start() error {
 	mux := asynq.NewServeMux()
 
 	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskSendResetPasswordEmail, processor.ProcessTaskSendResetPasswordEmail)
+	mux.HandleFunc(TaskSendLoginAlertEmail, processor.ProcessTaskSendLoginAlertEmail)
+	mux.HandleFunc(TaskSendPhoneCode, processor.ProcessTaskSendPhoneCode)
+	mux.HandleFunc(TaskDispatchWebhookEvent, processor.ProcessTaskDispatchWebhookEvent)
+	mux.HandleFunc(TaskDeliverWebhook, processor.ProcessTaskDeliverWebhook)
 
 	return processor.server.Start(mux)
 }
@@ -72,13 +112,27 @@ func RunTaskProcessor(
 	waitGroup *errgroup.Group,
 	redisOpt asynq.RedisClientOpt,
 	store db.Store,
+	cfg pkg.Config,
 ) {
 	mailer, err := email.NewSesEmailSender()
 
 	if err != nil {
 		log.Fatal().Msgf("cannot create email sender: %v", err)
 	}
-	taskProcessor := NewRedisTaskProcessor(redisOpt, store, mailer)
+
+	smsSender, err := sms.NewSender(&cfg)
+
+	if err != nil {
+		log.Fatal().Msgf("cannot create sms sender: %v", err)
+	}
+
+	phoneCodes, err := phone.NewVerifier(store, cfg.PhoneCodeKey, cfg.PhoneCodeDuration)
+
+	if err != nil {
+		log.Fatal().Msgf("cannot create phone code verifier: %v", err)
+	}
+
+	taskProcessor := NewRedisTaskProcessor(redisOpt, store, mailer, smsSender, phoneCodes, cfg)
 
 	log.Info().Msg("start task processor")
 	if err := taskProcessor.start(); err != nil {
@@ -0,0 +1,218 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/webhook"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskDeliverWebhook = "task:deliver_webhook"
+)
+
+// AggregateWebhookDelivery groups the outbox messages of a single delivery
+const AggregateWebhookDelivery = "webhook_delivery"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+const (
+	webhookBaseRetryDelay = 30 * time.Second
+	webhookMaxRetryDelay  = 6 * time.Hour
+	// the response body is never stored, only this much is drained so the connection can be reused
+	maxWebhookResponseBody = 1024
+)
+
+type PayloadDeliverWebhook struct {
+	DeliveryID int64 `json:"deliveryId"`
+}
+
+// EnqueueTaskDeliverWebhook writes the task into the outbox of the transaction behind q
+func EnqueueTaskDeliverWebhook(
+	ctx context.Context,
+	q db.Querier,
+	payload *PayloadDeliverWebhook,
+	opts OutboxOptions,
+) error {
+	aggregateID := strconv.FormatInt(payload.DeliveryID, 10)
+	return EnqueueTask(ctx, q, AggregateWebhookDelivery, aggregateID, TaskDeliverWebhook, payload, opts)
+}
+
+// NewWebhookClient returns the HTTP client used for deliveries, redirects are reported as failures.
+// Connections to addresses outside the public internet are refused when they are dialed.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	return newWebhookClient(timeout, webhook.DialControl)
+}
+
+func newWebhookClient(timeout time.Duration, control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: control,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		// no proxy from the environment, it would dial on our behalf and skip the address check
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// webhookRetryDelay backs off exponentially from 30 seconds up to 6 hours, plus up to 10% jitter
+func webhookRetryDelay(n int) time.Duration {
+	delay := webhookMaxRetryDelay
+	if n < 20 {
+		delay = min(webhookBaseRetryDelay<<n, webhookMaxRetryDelay)
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/10+1))
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadDeliverWebhook
+
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("fail to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	delivery, err := processor.store.GetWebhookDelivery(ctx, payload.DeliveryID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			// the endpoint was deleted together with its deliveries
+			return fmt.Errorf("webhook delivery not found: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("fail to get webhook delivery: %w", err)
+	}
+
+	if delivery.Status == WebhookDeliverySucceeded {
+		return nil
+	}
+
+	endpoint, err := processor.store.GetWebhookEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		return fmt.Errorf("fail to get webhook endpoint: %w", err)
+	}
+
+	arg := db.UpdateWebhookDeliveryAttemptParams{
+		ID:     delivery.ID,
+		Status: WebhookDeliverySucceeded,
+	}
+
+	var sendErr error
+	if !endpoint.IsActive {
+		sendErr = fmt.Errorf("webhook endpoint is disabled: %w", asynq.SkipRetry)
+	} else {
+		var statusCode int
+		statusCode, sendErr = processor.sendWebhook(ctx, endpoint, delivery)
+
+		if statusCode != 0 {
+			arg.ResponseStatus = pgtype.Int4{Int32: int32(statusCode), Valid: true}
+		}
+	}
+
+	if sendErr == nil {
+		arg.DeliveredAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	} else {
+		arg.Status = WebhookDeliveryPending
+		if errors.Is(sendErr, asynq.SkipRetry) || isLastAttempt(ctx) {
+			arg.Status = WebhookDeliveryFailed
+		}
+		arg.LastError = pgtype.Text{String: sendErr.Error(), Valid: true}
+	}
+
+	if _, err := processor.store.UpdateWebhookDeliveryAttempt(ctx, arg); err != nil {
+		return fmt.Errorf("fail to update webhook delivery: %w", err)
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("fail to deliver webhook: %w", sendErr)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Int64("delivery_id", delivery.ID).
+		Str("event_type", delivery.EventType).
+		Msg("processed task")
+
+	return nil
+}
+
+// sendWebhook posts the event to the endpoint, any response other than 2xx is an error
+func (processor *RedisTaskProcessor) sendWebhook(
+	ctx context.Context,
+	endpoint db.WebhookEndpoint,
+	delivery db.WebhookDelivery,
+) (int, error) {
+	now := time.Now()
+
+	// receivers that still verify with the previous secret keep working until it expires
+	secrets := []string{endpoint.Secret}
+	if endpoint.PreviousSecret.Valid && endpoint.PreviousSecretExpiresAt.Time.After(now) {
+		secrets = append(secrets, endpoint.PreviousSecret.String)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.EventHeader, delivery.EventType)
+	req.Header.Set(webhook.DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(delivery.Payload, now, secrets...))
+
+	resp, err := processor.webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// isLastAttempt reports whether asynq will not retry the running task again
+func isLastAttempt(ctx context.Context) bool {
+	retried, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return true
+	}
+
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	if !ok {
+		return true
+	}
+
+	return retried >= maxRetry
+}
@@ -0,0 +1,328 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/webhook"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProcessor delivers to the loopback receivers of the tests, the address check is left out
+func newTestProcessor(store db.Store) *RedisTaskProcessor {
+	return &RedisTaskProcessor{
+		store:         store,
+		config:        pkg.Config{WebhookMaxRetry: 3},
+		webhookClient: newWebhookClient(5*time.Second, nil),
+	}
+}
+
+func newDeliverWebhookTask(t *testing.T, deliveryID int64) *asynq.Task {
+	payload, err := json.Marshal(PayloadDeliverWebhook{DeliveryID: deliveryID})
+	require.NoError(t, err)
+
+	return asynq.NewTask(TaskDeliverWebhook, payload)
+}
+
+func randomWebhookDelivery(t *testing.T, endpoint db.WebhookEndpoint) db.WebhookDelivery {
+	event, err := webhook.NewEvent(webhook.EventUserVerified, webhook.UserVerifiedData{
+		Username: endpoint.Owner,
+		Email:    util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	return db.WebhookDelivery{
+		ID:         util.RandomInt(1, 1000),
+		EndpointID: endpoint.ID,
+		EventID:    event.ID,
+		EventType:  event.Type,
+		Payload:    payload,
+		Status:     WebhookDeliveryPending,
+	}
+}
+
+// receiver is an httptest server that verifies signatures like a partner would
+type receiver struct {
+	*httptest.Server
+	status   int
+	secret   string
+	requests int
+	verified bool
+	headers  http.Header
+}
+
+func newReceiver(t *testing.T, status int, secret string) *receiver {
+	r := &receiver{status: status, secret: secret}
+
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		r.requests++
+		r.headers = req.Header.Clone()
+		r.verified = webhook.Verify(req.Header.Get(webhook.SignatureHeader), body, r.secret, 5*time.Minute, time.Now()) == nil
+
+		w.WriteHeader(r.status)
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func TestProcessTaskDeliverWebhook(t *testing.T) {
+	secret, err := webhook.GenerateSecret()
+	require.NoError(t, err)
+
+	rcv := newReceiver(t, http.StatusOK, secret)
+
+	endpoint := db.WebhookEndpoint{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Url:      rcv.URL,
+		Secret:   secret,
+		IsActive: true,
+	}
+	delivery := randomWebhookDelivery(t, endpoint)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+	store.EXPECT().
+		UpdateWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UpdateWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			require.Equal(t, delivery.ID, arg.ID)
+			require.Equal(t, WebhookDeliverySucceeded, arg.Status)
+			require.Equal(t, int32(http.StatusOK), arg.ResponseStatus.Int32)
+			require.False(t, arg.ResponseBody.Valid)
+			require.True(t, arg.DeliveredAt.Valid)
+			return delivery, nil
+		})
+
+	err = newTestProcessor(store).ProcessTaskDeliverWebhook(context.Background(), newDeliverWebhookTask(t, delivery.ID))
+	require.NoError(t, err)
+
+	require.Equal(t, 1, rcv.requests)
+	require.True(t, rcv.verified)
+	require.Equal(t, webhook.EventUserVerified, rcv.headers.Get(webhook.EventHeader))
+	require.Equal(t, strconv.FormatInt(delivery.ID, 10), rcv.headers.Get(webhook.DeliveryHeader))
+}
+
+func TestProcessTaskDeliverWebhookRotatedSecret(t *testing.T) {
+	oldSecret, err := webhook.GenerateSecret()
+	require.NoError(t, err)
+	newSecret, err := webhook.GenerateSecret()
+	require.NoError(t, err)
+
+	// the partner has not switched to the new secret yet
+	rcv := newReceiver(t, http.StatusOK, oldSecret)
+
+	endpoint := db.WebhookEndpoint{
+		ID:                      util.RandomInt(1, 1000),
+		Owner:                   util.RandomOwner(),
+		Url:                     rcv.URL,
+		Secret:                  newSecret,
+		PreviousSecret:          pgtype.Text{String: oldSecret, Valid: true},
+		PreviousSecretExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		IsActive:                true,
+	}
+	delivery := randomWebhookDelivery(t, endpoint)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+	store.EXPECT().UpdateWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1).Return(delivery, nil)
+
+	err = newTestProcessor(store).ProcessTaskDeliverWebhook(context.Background(), newDeliverWebhookTask(t, delivery.ID))
+	require.NoError(t, err)
+	require.True(t, rcv.verified)
+
+	// once the grace period is over only the new secret is used
+	endpoint.PreviousSecretExpiresAt.Time = time.Now().Add(-time.Minute)
+
+	store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+	store.EXPECT().UpdateWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1).Return(delivery, nil)
+
+	err = newTestProcessor(store).ProcessTaskDeliverWebhook(context.Background(), newDeliverWebhookTask(t, delivery.ID))
+	require.NoError(t, err)
+	require.False(t, rcv.verified)
+}
+
+func TestProcessTaskDeliverWebhookReceiverError(t *testing.T) {
+	secret, err := webhook.GenerateSecret()
+	require.NoError(t, err)
+
+	rcv := newReceiver(t, http.StatusInternalServerError, secret)
+
+	endpoint := db.WebhookEndpoint{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Url:      rcv.URL,
+		Secret:   secret,
+		IsActive: true,
+	}
+	delivery := randomWebhookDelivery(t, endpoint)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+	store.EXPECT().
+		UpdateWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UpdateWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			// outside of asynq there are no retries left, so the delivery is failed right away
+			require.Equal(t, WebhookDeliveryFailed, arg.Status)
+			require.Equal(t, int32(http.StatusInternalServerError), arg.ResponseStatus.Int32)
+			require.Contains(t, arg.LastError.String, "500")
+			require.False(t, arg.DeliveredAt.Valid)
+			return delivery, nil
+		})
+
+	err = newTestProcessor(store).ProcessTaskDeliverWebhook(context.Background(), newDeliverWebhookTask(t, delivery.ID))
+	require.Error(t, err)
+	require.Equal(t, 1, rcv.requests)
+}
+
+func TestProcessTaskDeliverWebhookPrivateAddress(t *testing.T) {
+	secret, err := webhook.GenerateSecret()
+	require.NoError(t, err)
+
+	rcv := newReceiver(t, http.StatusOK, secret)
+
+	endpoint := db.WebhookEndpoint{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Url:      rcv.URL,
+		Secret:   secret,
+		IsActive: true,
+	}
+	delivery := randomWebhookDelivery(t, endpoint)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(1).Return(endpoint, nil)
+	store.EXPECT().
+		UpdateWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.UpdateWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			require.False(t, arg.ResponseStatus.Valid)
+			require.Contains(t, arg.LastError.String, webhook.ErrForbiddenAddress.Error())
+			return delivery, nil
+		})
+
+	processor := newTestProcessor(store)
+	processor.webhookClient = NewWebhookClient(5 * time.Second)
+
+	err = processor.ProcessTaskDeliverWebhook(context.Background(), newDeliverWebhookTask(t, delivery.ID))
+	require.ErrorIs(t, err, webhook.ErrForbiddenAddress)
+	require.Zero(t, rcv.requests)
+}
+
+func TestProcessTaskDeliverWebhookAlreadySucceeded(t *testing.T) {
+	endpoint := db.WebhookEndpoint{ID: util.RandomInt(1, 1000), Owner: util.RandomOwner()}
+	delivery := randomWebhookDelivery(t, endpoint)
+	delivery.Status = WebhookDeliverySucceeded
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().UpdateWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Times(0)
+
+	err := newTestProcessor(store).ProcessTaskDeliverWebhook(context.Background(), newDeliverWebhookTask(t, delivery.ID))
+	require.NoError(t, err)
+}
+
+func TestProcessTaskDispatchWebhookEvent(t *testing.T) {
+	owner := util.RandomOwner()
+	endpoints := []db.WebhookEndpoint{
+		{ID: 1, Owner: owner, IsActive: true},
+		{ID: 2, Owner: owner, IsActive: true},
+	}
+
+	event, err := webhook.NewEvent(webhook.EventTransferCompleted, webhook.TransferCompletedData{TransferID: uuid.New()})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(PayloadDispatchWebhookEvent{Owner: owner, Event: event})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		ListWebhookEndpointsForEvent(gomock.Any(), gomock.Eq(db.ListWebhookEndpointsForEventParams{
+			Owner:     owner,
+			EventType: webhook.EventTransferCompleted,
+		})).
+		Times(1).
+		Return(endpoints, nil)
+
+	var endpointIDs []int64
+	store.EXPECT().
+		CreateWebhookDeliveryTx(gomock.Any(), gomock.Any()).
+		Times(len(endpoints)).
+		DoAndReturn(func(ctx context.Context, arg db.CreateWebhookDeliveryTxParams, _ ...db.TxOption) (db.CreateWebhookDeliveryTxResult, error) {
+			require.Equal(t, event.ID, arg.EventID)
+			endpointIDs = append(endpointIDs, arg.EndpointID)
+
+			outbox := &outboxRecorder{}
+			delivery := db.WebhookDelivery{ID: arg.EndpointID * 10, EventID: arg.EventID}
+			require.NoError(t, arg.AfterCreate(outbox, delivery))
+
+			require.Len(t, outbox.messages, 1)
+			require.Equal(t, TaskDeliverWebhook, outbox.messages[0].TaskType)
+			require.Equal(t, int32(3), outbox.messages[0].MaxRetry)
+			require.Equal(t, strconv.FormatInt(delivery.ID, 10), outbox.messages[0].AggregateID)
+
+			return db.CreateWebhookDeliveryTxResult{Delivery: delivery, Created: true}, nil
+		})
+
+	err = newTestProcessor(store).ProcessTaskDispatchWebhookEvent(context.Background(), asynq.NewTask(TaskDispatchWebhookEvent, payload))
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2}, endpointIDs)
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	require.GreaterOrEqual(t, webhookRetryDelay(0), webhookBaseRetryDelay)
+	require.GreaterOrEqual(t, webhookRetryDelay(3), 8*webhookBaseRetryDelay)
+	require.LessOrEqual(t, webhookRetryDelay(100), webhookMaxRetryDelay+webhookMaxRetryDelay/10)
+}
+
+// outboxRecorder stands in for the transaction Querier and records outbox messages
+type outboxRecorder struct {
+	db.Querier
+	messages []db.CreateOutboxMessageParams
+}
+
+func (r *outboxRecorder) CreateOutboxMessage(_ context.Context, arg db.CreateOutboxMessageParams) (db.OutboxMessage, error) {
+	r.messages = append(r.messages, arg)
+	return db.OutboxMessage{}, nil
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/webhook"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskDispatchWebhookEvent = "task:dispatch_webhook_event"
+)
+
+type PayloadDispatchWebhookEvent struct {
+	Owner string        `json:"owner"`
+	Event webhook.Event `json:"event"`
+}
+
+// EnqueueWebhookEvent writes an event of owner into the outbox of the transaction behind q,
+// it is fanned out to the subscribed endpoints once the transaction commits
+func EnqueueWebhookEvent(ctx context.Context, q db.Querier, owner string, eventType string, data any) error {
+	event, err := webhook.NewEvent(eventType, data)
+	if err != nil {
+		return fmt.Errorf("fail to create webhook event: %w", err)
+	}
+
+	payload := &PayloadDispatchWebhookEvent{
+		Owner: owner,
+		Event: event,
+	}
+
+	return EnqueueTask(ctx, q, AggregateUser, owner, TaskDispatchWebhookEvent, payload, OutboxOptions{})
+}
+
+// EnqueueTransferCompletedEvents notifies the owners of both accounts of a transfer
+func EnqueueTransferCompletedEvents(ctx context.Context, q db.Querier, result db.TransferTxResult) error {
+	data := webhook.TransferCompletedData{
+		TransferID:        result.Transfer.PublicID,
+		FromAccountNumber: result.FromAccount.AccountNumber,
+		ToAccountNumber:   result.ToAccount.AccountNumber,
+		Amount:            util.Money{Amount: result.Transfer.Amount, Currency: result.FromAccount.Currency},
+		CreatedAt:         result.Transfer.CreatedAt,
+	}
+
+	if err := EnqueueWebhookEvent(ctx, q, result.FromAccount.Owner, webhook.EventTransferCompleted, data); err != nil {
+		return err
+	}
+
+	if result.ToAccount.Owner == result.FromAccount.Owner {
+		return nil
+	}
+
+	return EnqueueWebhookEvent(ctx, q, result.ToAccount.Owner, webhook.EventTransferCompleted, data)
+}
+
+// EnqueueUserVerifiedEvent notifies the user that their email address is verified
+func EnqueueUserVerifiedEvent(ctx context.Context, q db.Querier, user db.User) error {
+	data := webhook.UserVerifiedData{
+		Username: user.Username,
+		Email:    user.Email,
+	}
+
+	return EnqueueWebhookEvent(ctx, q, user.Username, webhook.EventUserVerified, data)
+}
+
+// ProcessTaskDispatchWebhookEvent creates one delivery per subscribed endpoint, retries never duplicate a delivery
+func (processor *RedisTaskProcessor) ProcessTaskDispatchWebhookEvent(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadDispatchWebhookEvent
+
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("fail to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	endpoints, err := processor.store.ListWebhookEndpointsForEvent(ctx, db.ListWebhookEndpointsForEventParams{
+		Owner:     payload.Owner,
+		EventType: payload.Event.Type,
+	})
+	if err != nil {
+		return fmt.Errorf("fail to list webhook endpoints: %w", err)
+	}
+
+	body, err := json.Marshal(payload.Event)
+	if err != nil {
+		return fmt.Errorf("fail to marshal webhook event: %w", asynq.SkipRetry)
+	}
+
+	for _, endpoint := range endpoints {
+		_, err := processor.store.CreateWebhookDeliveryTx(ctx, db.CreateWebhookDeliveryTxParams{
+			CreateWebhookDeliveryParams: db.CreateWebhookDeliveryParams{
+				EndpointID: endpoint.ID,
+				EventID:    payload.Event.ID,
+				EventType:  payload.Event.Type,
+				Payload:    body,
+			},
+			AfterCreate: func(q db.Querier, delivery db.WebhookDelivery) error {
+				return EnqueueTaskDeliverWebhook(ctx, q, &PayloadDeliverWebhook{DeliveryID: delivery.ID}, OutboxOptions{
+					MaxRetry: processor.config.WebhookMaxRetry,
+				})
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("fail to create webhook delivery: %w", err)
+		}
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("event_type", payload.Event.Type).
+		Str("event_id", payload.Event.ID.String()).
+		Int("endpoints", len(endpoints)).
+		Msg("processed task")
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/email"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskSendLoginAlertEmail = "task:send_login_alert_email"
+
+	denyTokenSize = 32
+)
+
+type PayloadSendLoginAlertEmail struct {
+	UserName     string `json:"username"`
+	LoginEventID int64  `json:"login_event_id"`
+}
+
+// EnqueueTaskSendLoginAlertEmail writes the task into the outbox of the transaction behind q
+func EnqueueTaskSendLoginAlertEmail(
+	ctx context.Context,
+	q db.Querier,
+	payload *PayloadSendLoginAlertEmail,
+	opts OutboxOptions,
+) error {
+	return EnqueueTask(ctx, q, AggregateUser, payload.UserName, TaskSendLoginAlertEmail, payload, opts)
+}
+
+// ProcessTaskSendLoginAlertEmail warns the user of a login from a new device. The email holds a
+// "this wasn't me" link whose token is only stored hashed, a retry replaces the token of the previous try.
+func (processor *RedisTaskProcessor) ProcessTaskSendLoginAlertEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendLoginAlertEmail
+
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("fail to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	event, err := processor.store.GetLoginEvent(ctx, payload.LoginEventID)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("login event not found: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("fail to get login event: %w", err)
+	}
+
+	// The user already denied the login through an earlier email
+	if event.DeniedAt.Valid {
+		return nil
+	}
+
+	user, err := processor.store.GetUserByUserName(ctx, event.Username)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("user not found: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("fail to get user: %w", err)
+	}
+
+	denyToken, err := token.GenerateOpaqueToken(denyTokenSize)
+
+	if err != nil {
+		return fmt.Errorf("fail to generate deny token: %w", err)
+	}
+
+	err = processor.store.SetLoginEventDenyToken(ctx, db.SetLoginEventDenyTokenParams{
+		ID:            event.ID,
+		DenyTokenHash: pgtype.Text{String: token.HashToken(denyToken), Valid: true},
+	})
+
+	if err != nil {
+		return fmt.Errorf("fail to store deny token: %w", err)
+	}
+
+	// Opening the link changes nothing, the page of the client posts the token to /auth/deny-login once the user confirms
+	denyUrl := fmt.Sprintf("%s?token=%s", processor.config.DenyLoginUrl, url.QueryEscape(denyToken))
+
+	emailPayload := email.EmailPayload{
+		Subject: "New sign-in to your Simple Bank account",
+		Content: fmt.Sprintf(`Hello %s, <br/>
+		Your account was signed in to from a new device on %s.<br/>
+		Device: %s<br/>
+		IP address: %s<br/>
+		If this was you, you can ignore this email.<br/>
+		If it was not, please <a href="%s">click here</a> to sign the device out and then change your password.<br/>
+		`, user.Username, event.CreatedAt.UTC().Format("2006-01-02 15:04 MST"),
+			html.EscapeString(event.UserAgent), html.EscapeString(event.ClientIp), denyUrl),
+		To: []string{user.Email},
+	}
+
+	if err := processor.mailer.SendEmail(emailPayload); err != nil {
+		return fmt.Errorf("fail to send email: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("email", user.Email).
+		Int64("login_event_id", event.ID).
+		Msg("processed task")
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/sms"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskSendPhoneCode = "task:send_phone_code"
+)
+
+type PayloadSendPhoneCode struct {
+	UserName string `json:"username"`
+	// Phone is the number the code was requested for, the task is dropped when the user changed it since
+	Phone   string `json:"phone"`
+	Purpose string `json:"purpose"`
+}
+
+// EnqueueTaskSendPhoneCode writes the task into the outbox behind q
+func EnqueueTaskSendPhoneCode(
+	ctx context.Context,
+	q db.Querier,
+	payload *PayloadSendPhoneCode,
+	opts OutboxOptions,
+) error {
+	return EnqueueTask(ctx, q, AggregateUser, payload.UserName, TaskSendPhoneCode, payload, opts)
+}
+
+// ProcessTaskSendPhoneCode creates a one-time code and texts it to the user. Only the
+// hash of the code is stored, the plain code exists in the message alone.
+func (processor *RedisTaskProcessor) ProcessTaskSendPhoneCode(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendPhoneCode
+
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("fail to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUserByUserName(ctx, payload.UserName)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("user not found: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("fail to get user: %w", err)
+	}
+
+	if user.Phone != payload.Phone {
+		return fmt.Errorf("phone number changed since the code was requested: %w", asynq.SkipRetry)
+	}
+
+	if err := phone.CanReceive(user, payload.Purpose); err != nil {
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+
+	code, err := processor.phoneCodes.NewCode(ctx, user.Username, user.Phone, payload.Purpose)
+
+	if err != nil {
+		return fmt.Errorf("fail to create phone code: %w", err)
+	}
+
+	content := fmt.Sprintf("%s is your Simple Bank verification code. It expires in %s.", code, processor.config.PhoneCodeDuration)
+	if payload.Purpose == phone.PurposeLogin {
+		content = fmt.Sprintf("%s is your Simple Bank login code. Never share it, our staff will not ask for it.", code)
+	}
+
+	if err := processor.smsSender.SendSMS(sms.Payload{To: user.Phone, Content: content}); err != nil {
+		return fmt.Errorf("fail to send sms: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("purpose", payload.Purpose).
+		Str("username", user.Username).
+		Msg("processed task")
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/phone"
+	"github.com/ChokeGuy/simple-bank/pkg/sms"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSmsSender keeps the messages instead of sending them
+type recordingSmsSender struct {
+	sent []sms.Payload
+}
+
+func (sender *recordingSmsSender) SendSMS(payload sms.Payload) error {
+	sender.sent = append(sender.sent, payload)
+	return nil
+}
+
+func newTestVerifier(t *testing.T, store db.Store, cfg pkg.Config) *phone.Verifier {
+	verifier, err := phone.NewVerifier(store, cfg.PhoneCodeKey, cfg.PhoneCodeDuration)
+	require.NoError(t, err)
+	return verifier
+}
+
+func newPhoneCodeTask(t *testing.T, payload PayloadSendPhoneCode) *asynq.Task {
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	return asynq.NewTask(TaskSendPhoneCode, data)
+}
+
+func TestProcessTaskSendPhoneCode(t *testing.T) {
+	user := db.GetUserByUserNameRow{Username: util.RandomOwner(), Phone: "+84901234567"}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	sender := &recordingSmsSender{}
+
+	processor := newTestProcessor(store)
+	processor.config = pkg.Config{PhoneCodeDuration: 10 * time.Minute, PhoneCodeKey: util.RandomString(phone.KeySize)}
+	processor.phoneCodes = newTestVerifier(t, store, processor.config)
+	processor.smsSender = sender
+
+	var codeHash string
+
+	store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().
+		InvalidatePhoneCodes(gomock.Any(), gomock.Eq(db.InvalidatePhoneCodesParams{Username: user.Username, Purpose: phone.PurposeVerify})).
+		Times(1).
+		Return(nil)
+	store.EXPECT().
+		CreatePhoneCode(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreatePhoneCodeParams) (db.PhoneCode, error) {
+			require.Equal(t, user.Phone, arg.Phone)
+			require.Equal(t, phone.PurposeVerify, arg.Purpose)
+			require.WithinDuration(t, time.Now().Add(10*time.Minute), arg.ExpiresAt, time.Second)
+			codeHash = arg.CodeHash
+			return db.PhoneCode{ID: 1, Username: arg.Username, Phone: arg.Phone, Purpose: arg.Purpose, CodeHash: arg.CodeHash}, nil
+		})
+
+	task := newPhoneCodeTask(t, PayloadSendPhoneCode{UserName: user.Username, Phone: user.Phone, Purpose: phone.PurposeVerify})
+	require.NoError(t, processor.ProcessTaskSendPhoneCode(context.Background(), task))
+
+	require.Len(t, sender.sent, 1)
+	require.Equal(t, user.Phone, sender.sent[0].To)
+
+	// Only the hash of the texted code is stored
+	code := regexp.MustCompile(`\d{6}`).FindString(sender.sent[0].Content)
+	require.Equal(t, phone.HashCode([]byte(processor.config.PhoneCodeKey), code), codeHash)
+}
+
+func TestProcessTaskSendPhoneCodeSkipped(t *testing.T) {
+	user := db.GetUserByUserNameRow{Username: util.RandomOwner(), Phone: "+84901234567"}
+
+	testCases := []struct {
+		name    string
+		payload PayloadSendPhoneCode
+	}{
+		{
+			name:    "PhoneChanged",
+			payload: PayloadSendPhoneCode{UserName: user.Username, Phone: "+84907654321", Purpose: phone.PurposeVerify},
+		},
+		{
+			name:    "LoginToUnverifiedPhone",
+			payload: PayloadSendPhoneCode{UserName: user.Username, Phone: user.Phone, Purpose: phone.PurposeLogin},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			sender := &recordingSmsSender{}
+
+			processor := newTestProcessor(store)
+			processor.phoneCodes = newTestVerifier(t, store, pkg.Config{PhoneCodeDuration: time.Minute, PhoneCodeKey: util.RandomString(phone.KeySize)})
+			processor.smsSender = sender
+
+			store.EXPECT().GetUserByUserName(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			store.EXPECT().CreatePhoneCode(gomock.Any(), gomock.Any()).Times(0)
+
+			err := processor.ProcessTaskSendPhoneCode(context.Background(), newPhoneCodeTask(t, tc.payload))
+			require.ErrorIs(t, err, asynq.SkipRetry)
+			require.Empty(t, sender.sent)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	"github.com/ChokeGuy/simple-bank/pkg/email"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	TaskSendResetPasswordEmail = "task:send_reset_password_email"
+
+	resetTokenSize = 32
+)
+
+// AggregatePasswordReset groups the outbox messages of a single forgot password request
+const AggregatePasswordReset = "password_reset"
+
+// PayloadSendResetPasswordEmail is written for every forgot password request, whether or not the
+// email belongs to a user, so the request takes the same time in both cases
+type PayloadSendResetPasswordEmail struct {
+	Email string `json:"email"`
+	// RequestID identifies the request, retries of the task replace the token of the request instead of adding one
+	RequestID uuid.UUID `json:"requestId"`
+}
+
+// EnqueueTaskSendResetPasswordEmail writes the task into the outbox behind q
+func EnqueueTaskSendResetPasswordEmail(
+	ctx context.Context,
+	q db.Querier,
+	payload *PayloadSendResetPasswordEmail,
+	opts OutboxOptions,
+) error {
+	return EnqueueTask(ctx, q, AggregatePasswordReset, payload.RequestID.String(), TaskSendResetPasswordEmail, payload, opts)
+}
+
+// ProcessTaskSendResetPasswordEmail creates a reset token and mails it to the user of the email, an
+// unknown email is dropped. Only the hash of the token is stored, the plain token exists in the email alone.
+func (processor *RedisTaskProcessor) ProcessTaskSendResetPasswordEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendResetPasswordEmail
+
+	err := json.Unmarshal(task.Payload(), &payload)
+	if err != nil {
+		return fmt.Errorf("fail to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUserByEmail(ctx, payload.Email)
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			log.Info().
+				Str("type", task.Type()).
+				Msg("skipped task, the email belongs to no user")
+			return nil
+		}
+		return fmt.Errorf("fail to get user: %w", err)
+	}
+
+	resetToken, err := token.GenerateOpaqueToken(resetTokenSize)
+
+	if err != nil {
+		return fmt.Errorf("fail to generate reset token: %w", err)
+	}
+
+	_, err = processor.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		TokenHash: token.HashToken(resetToken),
+		RequestID: payload.RequestID,
+	})
+
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return fmt.Errorf("password reset already used: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("fail to create password reset: %w", err)
+	}
+
+	// The page of the client posts the token to /auth/reset-password along with the new password
+	resetUrl := fmt.Sprintf("%s?token=%s", processor.config.ResetPasswordUrl, url.QueryEscape(resetToken))
+
+	emailPayload := email.EmailPayload{
+		Subject: "Reset your Simple Bank password",
+		Content: fmt.Sprintf(`Hello %s, <br/>
+		We received a request to reset the password of your account.<br/>
+		Please <a href="%s">click here</a> to choose a new password. The link expires in 15 minutes and can be used once.<br/>
+		If you did not ask for a new password you can ignore this email.<br/>
+		`, user.Username, resetUrl),
+		To: []string{user.Email},
+	}
+
+	if err := processor.mailer.SendEmail(emailPayload); err != nil {
+		return fmt.Errorf("fail to send email: %w", err)
+	}
+
+	log.Info().
+		Str("type", task.Type()).
+		Str("email", user.Email).
+		Msg("processed task")
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+
+	mockdb "github.com/ChokeGuy/simple-bank/db/mock"
+	db "github.com/ChokeGuy/simple-bank/db/sqlc"
+	pkg "github.com/ChokeGuy/simple-bank/pkg/config"
+	"github.com/ChokeGuy/simple-bank/pkg/email"
+	"github.com/ChokeGuy/simple-bank/pkg/token"
+	"github.com/ChokeGuy/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEmailSender keeps the emails instead of sending them
+type recordingEmailSender struct {
+	sent []email.EmailPayload
+}
+
+func (sender *recordingEmailSender) SendEmail(payload email.EmailPayload) error {
+	sender.sent = append(sender.sent, payload)
+	return nil
+}
+
+func newResetPasswordEmailTask(t *testing.T, payload PayloadSendResetPasswordEmail) *asynq.Task {
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	return asynq.NewTask(TaskSendResetPasswordEmail, data)
+}
+
+func newResetPasswordProcessor(store db.Store, sender email.EmailSender) *RedisTaskProcessor {
+	processor := newTestProcessor(store)
+	processor.config = pkg.Config{ResetPasswordUrl: "https://bank.example/reset-password"}
+	processor.mailer = sender
+	return processor
+}
+
+func TestProcessTaskSendResetPasswordEmail(t *testing.T) {
+	user := db.GetUserByEmailRow{Username: util.RandomOwner(), Email: util.RandomEmail()}
+	requestID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	sender := &recordingEmailSender{}
+	processor := newResetPasswordProcessor(store, sender)
+
+	var tokenHashes []string
+
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(2).Return(user, nil)
+	store.EXPECT().
+		CreatePasswordReset(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ context.Context, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+			require.Equal(t, user.Username, arg.Username)
+			require.Equal(t, requestID, arg.RequestID)
+			tokenHashes = append(tokenHashes, arg.TokenHash)
+			return db.PasswordReset{Username: arg.Username, TokenHash: arg.TokenHash, RequestID: arg.RequestID}, nil
+		})
+
+	// A retry runs the same task again, both runs write the row of the same request
+	task := newResetPasswordEmailTask(t, PayloadSendResetPasswordEmail{Email: user.Email, RequestID: requestID})
+	require.NoError(t, processor.ProcessTaskSendResetPasswordEmail(context.Background(), task))
+	require.NoError(t, processor.ProcessTaskSendResetPasswordEmail(context.Background(), task))
+
+	require.Len(t, sender.sent, 2)
+
+	// The link goes to the page of the client and only the hash of its token is stored
+	link := regexp.MustCompile(`href="([^"]+)"`).FindStringSubmatch(sender.sent[1].Content)
+	require.Len(t, link, 2)
+
+	resetUrl, err := url.Parse(link[1])
+	require.NoError(t, err)
+	require.Equal(t, "bank.example", resetUrl.Host)
+	require.Equal(t, "/reset-password", resetUrl.Path)
+	require.Equal(t, token.HashToken(resetUrl.Query().Get("token")), tokenHashes[1])
+}
+
+func TestProcessTaskSendResetPasswordEmailUnknownEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	sender := &recordingEmailSender{}
+	processor := newResetPasswordProcessor(store, sender)
+
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).Times(1).Return(db.GetUserByEmailRow{}, db.ErrRecordNotFound)
+	store.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any()).Times(0)
+
+	task := newResetPasswordEmailTask(t, PayloadSendResetPasswordEmail{Email: util.RandomEmail(), RequestID: uuid.New()})
+	require.NoError(t, processor.ProcessTaskSendResetPasswordEmail(context.Background(), task))
+	require.Empty(t, sender.sent)
+}
+
+func TestProcessTaskSendResetPasswordEmailAlreadyUsed(t *testing.T) {
+	user := db.GetUserByEmailRow{Username: util.RandomOwner(), Email: util.RandomEmail()}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	sender := &recordingEmailSender{}
+	processor := newResetPasswordProcessor(store, sender)
+
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).Times(1).Return(user, nil)
+	store.EXPECT().
+		CreatePasswordReset(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.PasswordReset{}, db.ErrRecordNotFound)
+
+	// A late retry must not replace a token that was already used
+	task := newResetPasswordEmailTask(t, PayloadSendResetPasswordEmail{Email: user.Email, RequestID: uuid.New()})
+	err := processor.ProcessTaskSendResetPasswordEmail(context.Background(), task)
+	require.True(t, errors.Is(err, asynq.SkipRetry))
+	require.Empty(t, sender.sent)
+}
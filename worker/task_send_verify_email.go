@@ -17,35 +17,21 @@ const (
 	TaskSendVerifyEmail = "task:send_verify_email"
 )
 
+// AggregateUser groups outbox messages of a user so they are published in order
+const AggregateUser = "user"
+
 type PayloadSendVerifyEmail struct {
 	UserName string `json:"username"`
 }
 
-func (distributor *RedisTaskDistributor) DistributeTaskSendVerifyEmail(
+// EnqueueTaskSendVerifyEmail writes the task into the outbox of the transaction behind q
+func EnqueueTaskSendVerifyEmail(
 	ctx context.Context,
+	q db.Querier,
 	payload *PayloadSendVerifyEmail,
-	opts ...asynq.Option,
+	opts OutboxOptions,
 ) error {
-
-	jsonPayload, err := json.Marshal(payload)
-
-	if err != nil {
-		return fmt.Errorf("fail to marshal payload: %v", err)
-	}
-	task := asynq.NewTask(TaskSendVerifyEmail, jsonPayload, opts...)
-	info, err := distributor.client.EnqueueContext(ctx, task)
-
-	if err != nil {
-		return fmt.Errorf("fail to enqueue task: %v", err)
-	}
-
-	log.Info().
-		Str("type", task.Type()).
-		Str("queue", info.Queue).
-		Int("max_retry", info.MaxRetry).
-		Msg("enqueued task")
-
-	return nil
+	return EnqueueTask(ctx, q, AggregateUser, payload.UserName, TaskSendVerifyEmail, payload, opts)
 }
 
 func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error {